@@ -10,6 +10,7 @@ import (
 	"backend/internal/workers"
 	"backend/pkg/cloudapi"
 	"backend/pkg/database"
+	"backend/pkg/payment"
 	"backend/pkg/printer"
 	"context"
 	"embed"
@@ -218,6 +219,24 @@ func main() {
 	deviceRepo := repositories.NewDeviceRepository(sqlDB)
 	printerRepo := repositories.NewPrinterRepository(sqlDB)
 	customerRepo := repositories.NewCustomerRepository(sqlDB)
+	priceListRepo := repositories.NewPriceListRepository(sqlDB)
+	promotionRepo := repositories.NewPromotionRepository(sqlDB)
+	taxRepo := repositories.NewTaxRepository(sqlDB)
+	paymentIntentRepo := repositories.NewPaymentIntentRepository(sqlDB)
+	paymentMethodRepo := repositories.NewPaymentMethodRepository(sqlDB)
+	tipRepo := repositories.NewTipRepository(sqlDB)
+	businessDayRepo := repositories.NewBusinessDayRepository(sqlDB)
+	terminalRepo := repositories.NewTerminalRepository(sqlDB)
+	cashDrawerRepo := repositories.NewCashDrawerRepository(sqlDB)
+	depositRepo := repositories.NewDepositRepository(sqlDB)
+	giftCardRepo := repositories.NewGiftCardRepository(sqlDB)
+	orderCheckRepo := repositories.NewOrderCheckRepository(sqlDB)
+	reservationRepo := repositories.NewReservationRepository(sqlDB)
+	floorPlanRepo := repositories.NewFloorPlanRepository(sqlDB)
+	orderTypeRepo := repositories.NewOrderTypeRepository(sqlDB)
+	guestOrderRepo := repositories.NewGuestOrderRepository(sqlDB)
+	tableSessionRepo := repositories.NewTableSessionRepository(sqlDB)
+	tableRequestRepo := repositories.NewTableRequestRepository(sqlDB)
 
 	// Load sync configuration from database (priority), fallback to env
 	var cloudClient *cloudapi.Client
@@ -267,18 +286,91 @@ func main() {
 	tableService := services.NewTableService(tableRepo)
 	printerService := services.NewPrinterService(printerRepo)
 	customerService := services.NewCustomerService(customerRepo)
+	priceListService := services.NewPriceListService(priceListRepo)
+	promotionService := services.NewPromotionService(promotionRepo, syncRepo)
+	taxService := services.NewTaxService(taxRepo, syncRepo)
+	paymentMethodService := services.NewPaymentMethodService(paymentMethodRepo)
+	tipService := services.NewTipService(tipRepo)
+	businessDayService := services.NewBusinessDayService(businessDayRepo)
+	terminalService := services.NewTerminalService(terminalRepo)
+	cashDrawerService := services.NewCashDrawerService(cashDrawerRepo)
+	depositService := services.NewDepositService(depositRepo)
+	giftCardService := services.NewGiftCardService(giftCardRepo)
+	orderCheckService := services.NewOrderCheckService(orderCheckRepo)
+	reservationService := services.NewReservationService(reservationRepo, repositories.ReservationSettings{
+		HoldBefore:      time.Duration(cfg.ReservationHoldMin) * time.Minute,
+		NoShowGrace:     time.Duration(cfg.ReservationNoShowMin) * time.Minute,
+		DefaultDuration: time.Duration(cfg.ReservationDurationMin) * time.Minute,
+	})
+	floorPlanService := services.NewFloorPlanService(floorPlanRepo)
+	orderTypeService := services.NewOrderTypeService(orderTypeRepo)
+	guestOrderSecret := cfg.GuestOrderSecret
+	if guestOrderSecret == "" {
+		guestOrderSecret = cfg.JWTSecret
+	}
+	tableSessionService := services.NewTableSessionService(tableSessionRepo)
+	tableRequestService := services.NewTableRequestService(tableRequestRepo)
+	guestOrderService := services.NewGuestOrderService(guestOrderRepo, orderRepo, repositories.GuestOrderSettings{
+		Secret:             guestOrderSecret,
+		RequireApproval:    cfg.GuestOrderRequireApproval,
+		MaxPendingPerTable: cfg.GuestOrderMaxPending,
+	})
+
+	// Payment provider - sandbox hanya untuk uji lokal dan harus diaktifkan eksplisit
+	paymentRegistry := payment.NewRegistry()
+	if cfg.MidtransServerKey != "" {
+		paymentRegistry.Register(payment.NewMidtransQRISProvider(cfg.MidtransServerKey, cfg.MidtransBaseURL))
+	}
+	if cfg.PaymentSandboxEnabled {
+		if cfg.PaymentSandboxSecret == "" {
+			log.Fatal("PAYMENT_SANDBOX_ENABLED=true requires PAYMENT_SANDBOX_SECRET")
+		}
+		paymentRegistry.Register(payment.NewSandboxProvider(cfg.PaymentSandboxSecret))
+		log.Println("⚠️  Payment sandbox enabled - do not use in production")
+	}
+	defaultProvider := cfg.PaymentProvider
+	if defaultProvider == "" && cfg.MidtransServerKey != "" {
+		defaultProvider = "midtrans"
+	}
+	if defaultProvider != "" {
+		if err := paymentRegistry.SetDefault(defaultProvider); err != nil {
+			log.Printf("Payment provider %s not available, dynamic QRIS disabled", defaultProvider)
+		}
+	}
+	paymentService := services.NewPaymentService(paymentIntentRepo, paymentRegistry, time.Duration(cfg.PaymentIntentTTLMin)*time.Minute)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(sqlDB)
-	productHandler := handlers.NewProductHandler(productService)
-	categoryHandler := handlers.NewCategoryHandler(categoryService)
-	transactionHandler := handlers.NewTransactionHandler(transactionService, queries, sqlDB)
+	authHandler := handlers.NewAuthHandler(sqlDB, syncRepo)
+	productHandler := handlers.NewProductHandler(productService, syncRepo)
+	categoryHandler := handlers.NewCategoryHandler(categoryService, syncRepo)
+	transactionHandler := handlers.NewTransactionHandler(transactionService, queries, sqlDB, handlers.ShiftCloseSettings{
+		BlindClose:        cfg.ShiftBlindClose,
+		VarianceThreshold: cfg.ShiftVarianceThreshold,
+	})
 	socketBroadcaster := &socketBroadcaster{server: socketServer}
 	orderHandler := handlers.NewOrderHandler(orderService, transactionService, customerService, queries, sqlDB, socketBroadcaster)
-	tableHandler := handlers.NewTableHandler(tableService, queries)
+	tableHandler := handlers.NewTableHandler(tableService, queries, syncRepo, socketBroadcaster)
 	printerHandler := handlers.NewPrinterHandler(printerService, syncRepo)
-	printHandler := handlers.NewPrintHandler(sqlDB)
+	printHandler := handlers.NewPrintHandler(sqlDB, socketBroadcaster)
 	customerHandler := handlers.NewCustomerHandler(customerService, orderService)
+	priceListHandler := handlers.NewPriceListHandler(priceListService)
+	promotionHandler := handlers.NewPromotionHandler(promotionService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService, orderHandler)
+	paymentMethodHandler := handlers.NewPaymentMethodHandler(paymentMethodService)
+	tipHandler := handlers.NewTipHandler(tipService)
+	businessDayHandler := handlers.NewBusinessDayHandler(businessDayService, queries)
+	terminalHandler := handlers.NewTerminalHandler(terminalService)
+	cashDrawerHandler := handlers.NewCashDrawerHandler(cashDrawerService, sqlDB, queries)
+	depositHandler := handlers.NewDepositHandler(depositService, sqlDB, queries)
+	giftCardHandler := handlers.NewGiftCardHandler(giftCardService, sqlDB)
+	orderCheckHandler := handlers.NewOrderCheckHandler(orderCheckService)
+	reservationHandler := handlers.NewReservationHandler(reservationService, socketBroadcaster)
+	floorPlanHandler := handlers.NewFloorPlanHandler(floorPlanService, socketBroadcaster)
+	orderTypeHandler := handlers.NewOrderTypeHandler(orderTypeService)
+	guestOrderHandler := handlers.NewGuestOrderHandler(guestOrderService, socketBroadcaster, cfg.GuestOrderBaseURL)
+	tableSessionHandler := handlers.NewTableSessionHandler(tableSessionService)
+	tableRequestHandler := handlers.NewTableRequestHandler(tableRequestService, guestOrderService, socketBroadcaster)
 
 	// Config handler - always available for managing sync config
 	configHandler := handlers.NewConfigHandler(syncRepo)
@@ -348,6 +440,10 @@ func main() {
 	go printWorker.Start(ctx)
 	log.Println("🖨️  Print worker started")
 
+	// Reservation worker - tandai meja reserved menjelang slot dan lepas setelah no-show
+	reservationWorker := workers.NewReservationWorker(reservationService, socketBroadcaster)
+	go reservationWorker.Start(ctx)
+
 	// Routes
 	api := e.Group("/api/v1")
 
@@ -376,6 +472,11 @@ func main() {
 	protected.POST("/orders", orderHandler.HandleCreateOrder, authmw.WaiterOrAdmin())
 	protected.GET("/orders", orderHandler.HandleListOrders)
 	protected.POST("/orders/merge", orderHandler.HandleMergeTables, authmw.WaiterOrAdmin())
+	protected.POST("/orders/:id/transfer", orderHandler.HandleTransferOrder, authmw.WaiterOrAdmin())
+	protected.POST("/orders/:id/move-items", orderHandler.HandleMoveOrderItems, authmw.WaiterOrAdmin())
+	protected.POST("/orders/:id/unmerge", orderHandler.HandleUnmergeOrder, authmw.WaiterOrAdmin())
+	protected.POST("/orders/:id/fire", orderHandler.HandleFireCourse, authmw.WaiterOrAdmin())
+	protected.POST("/orders/:id/hold", orderHandler.HandleHoldCourse, authmw.WaiterOrAdmin())
 	protected.POST("/orders/:id/items", orderHandler.HandleAddItemsToOrder, authmw.WaiterOrAdmin())
 	protected.POST("/orders/table/:table_id/items", orderHandler.HandleAddItemsToOrderByTable, authmw.WaiterOrAdmin())
 	// Kitchen/Bar can update item status
@@ -386,8 +487,35 @@ func main() {
 	protected.POST("/orders/:id/payment", orderHandler.HandleProcessPayment, authmw.CashierOrAdmin())
 	protected.POST("/orders/:id/discount", orderHandler.HandleApplyDiscount, authmw.CashierOrAdmin())
 	protected.POST("/orders/:id/compliment", orderHandler.HandleApplyCompliment, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/voucher", orderHandler.HandleApplyVoucher, authmw.CashierOrAdmin())
+	protected.DELETE("/orders/:id/voucher/:code", orderHandler.HandleRemoveVoucher, authmw.CashierOrAdmin())
 	protected.POST("/orders/:id/split-payment", orderHandler.HandleSplitBillPayment, authmw.CashierOrAdmin())
+
+	// Split check routes - tagihan terpisah per kursi/item/nominal, dibayar per check
+	protected.PUT("/orders/items/:id/seat", orderCheckHandler.UpdateItemSeat, authmw.WaiterOrAdmin())
+	protected.GET("/orders/:id/checks", orderCheckHandler.ListChecks, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks", orderCheckHandler.CreateCheck, authmw.CashierOrAdmin())
+	protected.DELETE("/orders/:id/checks", orderCheckHandler.ClearChecks, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks/seats", orderCheckHandler.SplitBySeat, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks/even", orderCheckHandler.SplitEven, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks/amounts", orderCheckHandler.SplitByAmounts, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks/move", orderCheckHandler.MoveCheckItem, authmw.CashierOrAdmin())
+	protected.DELETE("/orders/:id/checks/:check_id", orderCheckHandler.DeleteCheck, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks/:check_id/print", orderHandler.HandlePrintCheck, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/checks/:check_id/payment", orderHandler.HandlePayCheck, authmw.CashierOrAdmin())
+	// Pembayaran lewat payment provider (QRIS dinamis)
+	protected.GET("/payments/providers", paymentHandler.HandleListProviders, authmw.CashierOrAdmin())
+	protected.POST("/orders/:id/payment-intents", paymentHandler.HandleCreateIntent, authmw.CashierOrAdmin())
+	protected.GET("/orders/:id/payment-intents", paymentHandler.HandleListOrderIntents, authmw.CashierOrAdmin())
+	protected.GET("/payment-intents/:id", paymentHandler.HandleGetIntent, authmw.CashierOrAdmin())
+	protected.POST("/payment-intents/:id/cancel", paymentHandler.HandleCancelIntent, authmw.CashierOrAdmin())
+	if cfg.PaymentSandboxEnabled {
+		protected.POST("/payment-intents/:id/simulate", paymentHandler.HandleSimulateSandbox, authmw.AdminOnly())
+	}
 	protected.POST("/orders/:id/void", orderHandler.HandleVoidOrder, authmw.CashierManagerOrAdmin())
+	protected.POST("/orders/:id/refunds", orderHandler.HandleRefundOrder, authmw.CashierManagerOrAdmin())
+	protected.GET("/orders/:id/refunds", orderHandler.HandleGetOrderRefunds, authmw.CashierManagerOrAdmin())
+	protected.GET("/orders/:id/tips", tipHandler.GetOrderTips, authmw.CashierManagerOrAdmin())
 	protected.GET("/orders/voided", orderHandler.HandleGetVoidedOrders, authmw.CashierManagerOrAdmin())
 	// Manager/Admin/Cashier can view analytics
 	protected.GET("/orders/analytics", orderHandler.HandleGetOrderAnalytics, authmw.CashierManagerOrAdmin())
@@ -411,6 +539,57 @@ func main() {
 	protected.PUT("/categories/:id", categoryHandler.UpdateCategory, authmw.ManagerOrAdmin())
 	protected.DELETE("/categories/:id", categoryHandler.DeleteCategory, authmw.AdminOnly())
 
+	// Price list routes - Admin/Manager manage menu versions, all can preview live prices
+	protected.GET("/price-lists", priceListHandler.ListPriceLists, authmw.ManagerOrAdmin())
+	protected.GET("/price-lists/preview", priceListHandler.PreviewPrices)
+	protected.GET("/price-lists/:id", priceListHandler.GetPriceList, authmw.ManagerOrAdmin())
+	protected.POST("/price-lists", priceListHandler.CreatePriceList, authmw.ManagerOrAdmin())
+	protected.PUT("/price-lists/:id", priceListHandler.UpdatePriceList, authmw.ManagerOrAdmin())
+	protected.DELETE("/price-lists/:id", priceListHandler.DeletePriceList, authmw.ManagerOrAdmin())
+
+	// Promotion & voucher routes
+	protected.GET("/promotions", promotionHandler.ListPromotions, authmw.ManagerOrAdmin())
+	protected.GET("/promotions/:id", promotionHandler.GetPromotion, authmw.ManagerOrAdmin())
+	protected.POST("/promotions", promotionHandler.CreatePromotion, authmw.ManagerOrAdmin())
+	protected.PUT("/promotions/:id", promotionHandler.UpdatePromotion, authmw.ManagerOrAdmin())
+	protected.DELETE("/promotions/:id", promotionHandler.DeletePromotion, authmw.ManagerOrAdmin())
+	protected.GET("/vouchers", promotionHandler.ListVouchers, authmw.ManagerOrAdmin())
+	protected.POST("/vouchers", promotionHandler.CreateVoucher, authmw.ManagerOrAdmin())
+	protected.DELETE("/vouchers/:id", promotionHandler.DeleteVoucher, authmw.ManagerOrAdmin())
+
+	// Tax routes - tarif pajak & laporan pajak bulanan
+	protected.GET("/taxes", taxHandler.ListTaxRates, authmw.ManagerOrAdmin())
+	protected.GET("/taxes/report", taxHandler.GetTaxReport, authmw.ManagerOrAdmin())
+	protected.GET("/taxes/:id", taxHandler.GetTaxRate, authmw.ManagerOrAdmin())
+	protected.POST("/taxes", taxHandler.CreateTaxRate, authmw.ManagerOrAdmin())
+	protected.PUT("/taxes/:id", taxHandler.UpdateTaxRate, authmw.ManagerOrAdmin())
+	protected.DELETE("/taxes/:id", taxHandler.DeleteTaxRate, authmw.ManagerOrAdmin())
+
+	// Payment method routes - daftar untuk semua user, kelola oleh manager/admin
+	protected.GET("/payment-methods", paymentMethodHandler.ListPaymentMethods)
+	protected.GET("/payment-methods/:id", paymentMethodHandler.GetPaymentMethod)
+	protected.POST("/payment-methods", paymentMethodHandler.CreatePaymentMethod, authmw.ManagerOrAdmin())
+	protected.PUT("/payment-methods/:id", paymentMethodHandler.UpdatePaymentMethod, authmw.ManagerOrAdmin())
+	protected.DELETE("/payment-methods/:id", paymentMethodHandler.DeletePaymentMethod, authmw.ManagerOrAdmin())
+
+	// Cashier terminal routes - daftar untuk semua user, kelola oleh manager/admin
+	protected.GET("/terminals", terminalHandler.ListTerminals)
+	protected.GET("/terminals/:id", terminalHandler.GetTerminal)
+	protected.POST("/terminals", terminalHandler.CreateTerminal, authmw.ManagerOrAdmin())
+	protected.PUT("/terminals/:id", terminalHandler.UpdateTerminal, authmw.ManagerOrAdmin())
+	protected.DELETE("/terminals/:id", terminalHandler.DeleteTerminal, authmw.ManagerOrAdmin())
+
+	// Tip routes - rekap tip per staff/shift untuk payout
+	protected.GET("/tips/report", tipHandler.GetTipReport, authmw.ManagerOrAdmin())
+
+	// Business day routes - X report (snapshot) dan Z report (tutup hari bisnis)
+	protected.GET("/reports/x", businessDayHandler.GetXReport, authmw.CashierManagerOrAdmin())
+	protected.POST("/reports/x/print", businessDayHandler.PrintXReport, authmw.CashierManagerOrAdmin())
+	protected.POST("/reports/z", businessDayHandler.CloseDay, authmw.ManagerOrAdmin())
+	protected.GET("/reports/z", businessDayHandler.ListZReports, authmw.ManagerOrAdmin())
+	protected.GET("/reports/z/:id", businessDayHandler.GetZReport, authmw.ManagerOrAdmin())
+	protected.POST("/reports/z/:id/print", businessDayHandler.ReprintZReport, authmw.ManagerOrAdmin())
+
 	// Printer routes - Admin only
 	protected.POST("/printers", printerHandler.CreatePrinter, authmw.AdminOnly())
 	protected.GET("/printers", printerHandler.GetAllPrinters)
@@ -419,6 +598,8 @@ func main() {
 	protected.DELETE("/printers/:id", printerHandler.DeletePrinter, authmw.AdminOnly())
 	protected.PATCH("/printers/:id/toggle", printerHandler.TogglePrinter, authmw.AdminOnly())
 	protected.POST("/printers/:id/test", printerHandler.TestPrintHandler, authmw.AdminOnly())
+	protected.GET("/printers/:id/drawer", cashDrawerHandler.GetDrawerSettings)
+	protected.PUT("/printers/:id/drawer", cashDrawerHandler.UpdateDrawerSettings, authmw.AdminOnly())
 
 	// Print routes - Cashier/Admin can print
 	protected.POST("/print/order", printHandler.HandlePrintOrder, authmw.CashierOrAdmin())
@@ -435,12 +616,105 @@ func main() {
 	protected.GET("/tables/available", tableHandler.GetAvailableTables)
 	protected.GET("/tables/occupied", tableHandler.GetOccupiedTables)
 	protected.PUT("/tables/:id", tableHandler.UpdateTable, authmw.ManagerOrAdmin())
-	protected.PUT("/tables/status/:number", tableHandler.UpdateTableStatus, authmw.WaiterManagerOrAdmin())
+	protected.PUT("/tables/status/:number", tableHandler.UpdateTableStatus)
+	protected.GET("/tables/status-rules", tableHandler.GetTableStatusRules)
+	protected.PUT("/tables/status-rules", tableHandler.UpdateTableStatusRules, authmw.ManagerOrAdmin())
 	protected.DELETE("/tables/:id", tableHandler.DeleteTable, authmw.AdminOnly())
 
+	// Floor plan routes
+	protected.GET("/floor-plan", floorPlanHandler.GetFloorPlan)
+	protected.PUT("/floor-plan/layout", floorPlanHandler.SaveLayout, authmw.ManagerOrAdmin())
+	protected.GET("/floor-areas", floorPlanHandler.ListAreas)
+	protected.POST("/floor-areas", floorPlanHandler.CreateArea, authmw.ManagerOrAdmin())
+	protected.GET("/floor-areas/:id", floorPlanHandler.GetArea)
+	protected.PUT("/floor-areas/:id", floorPlanHandler.UpdateArea, authmw.ManagerOrAdmin())
+	protected.DELETE("/floor-areas/:id", floorPlanHandler.DeleteArea, authmw.ManagerOrAdmin())
+	protected.PUT("/floor-areas/:id/charges", floorPlanHandler.SetAreaChargeRules, authmw.ManagerOrAdmin())
+	protected.GET("/floor-sections", floorPlanHandler.ListSections)
+	protected.POST("/floor-sections", floorPlanHandler.CreateSection, authmw.ManagerOrAdmin())
+	protected.GET("/floor-sections/mine", floorPlanHandler.ListMyTables)
+	protected.GET("/floor-sections/:id", floorPlanHandler.GetSection)
+	protected.PUT("/floor-sections/:id", floorPlanHandler.UpdateSection, authmw.ManagerOrAdmin())
+	protected.DELETE("/floor-sections/:id", floorPlanHandler.DeleteSection, authmw.ManagerOrAdmin())
+	protected.PUT("/floor-sections/:id/waiters", floorPlanHandler.SetSectionWaiters, authmw.ManagerOrAdmin())
+	protected.GET("/table-combinations", floorPlanHandler.ListCombinations)
+	protected.POST("/table-combinations", floorPlanHandler.CreateCombination, authmw.ManagerOrAdmin())
+	protected.PUT("/table-combinations/:id", floorPlanHandler.UpdateCombination, authmw.ManagerOrAdmin())
+	protected.DELETE("/table-combinations/:id", floorPlanHandler.DeleteCombination, authmw.ManagerOrAdmin())
+
+	// Tipe order: dine-in, takeaway, delivery, pickup
+	protected.GET("/order-types", orderTypeHandler.ListOrderTypes)
+	protected.PUT("/order-types/:type", orderTypeHandler.UpdateOrderType, authmw.ManagerOrAdmin())
+	protected.PUT("/order-types/:type/charges", orderTypeHandler.SetChargeRules, authmw.ManagerOrAdmin())
+	protected.GET("/orders/queue", orderTypeHandler.ListQueue)
+
+	// Pesan mandiri tamu lewat QR meja - publik, dibatasi token meja dan rate limit per IP
+	guestGroup := api.Group("/guest/:token", authmw.RateLimitPerMinute(cfg.GuestOrderRateLimit))
+	guestGroup.GET("/menu", guestOrderHandler.GetGuestMenu)
+	guestGroup.POST("/orders", guestOrderHandler.SubmitGuestOrder)
+	guestGroup.GET("/orders/:id", guestOrderHandler.GetGuestOrderStatus)
+	guestGroup.POST("/requests", tableRequestHandler.CreateGuestTableRequest)
+	guestGroup.GET("/requests/:id", tableRequestHandler.GetGuestTableRequest)
+	protected.GET("/tables/:id/qr", guestOrderHandler.GenerateTableQRCode, authmw.ManagerOrAdmin())
+	protected.GET("/guest-orders", guestOrderHandler.ListGuestOrders, authmw.WaiterManagerOrAdmin())
+	protected.POST("/guest-orders/:id/approve", guestOrderHandler.ApproveGuestOrder, authmw.WaiterManagerOrAdmin())
+	protected.POST("/guest-orders/:id/reject", guestOrderHandler.RejectGuestOrder, authmw.WaiterManagerOrAdmin())
+
+	// Sesi meja: timer live untuk waiter/kasir, log & analitik turn time untuk manager
+	protected.GET("/tables/timers", tableSessionHandler.GetTableTimers)
+	protected.GET("/table-sessions", tableSessionHandler.ListTableSessions, authmw.ManagerOrAdmin())
+	protected.GET("/table-sessions/analytics", tableSessionHandler.GetTableAnalytics, authmw.ManagerOrAdmin())
+	protected.GET("/table-sessions/:id", tableSessionHandler.GetTableSession, authmw.ManagerOrAdmin())
+
+	// Permintaan meja (minta bill, panggil pelayan, minta air) dan waktu respon staf
+	protected.POST("/table-requests", tableRequestHandler.CreateTableRequest)
+	protected.GET("/table-requests", tableRequestHandler.ListTableRequests)
+	protected.GET("/table-requests/analytics", tableRequestHandler.GetTableRequestAnalytics, authmw.ManagerOrAdmin())
+	protected.POST("/table-requests/:id/acknowledge", tableRequestHandler.AcknowledgeTableRequest)
+	protected.POST("/table-requests/:id/resolve", tableRequestHandler.ResolveTableRequest)
+
+	// Reservation & waitlist routes
+	protected.GET("/reservations", reservationHandler.ListReservations)
+	protected.POST("/reservations", reservationHandler.CreateReservation, authmw.WaiterManagerOrAdmin())
+	protected.GET("/reservations/availability", reservationHandler.GetAvailability)
+	protected.GET("/reservations/:id", reservationHandler.GetReservation)
+	protected.PUT("/reservations/:id", reservationHandler.UpdateReservation, authmw.WaiterManagerOrAdmin())
+	protected.POST("/reservations/:id/assign", reservationHandler.AssignTable, authmw.WaiterManagerOrAdmin())
+	protected.POST("/reservations/:id/seat", reservationHandler.SeatReservation, authmw.WaiterManagerOrAdmin())
+	protected.POST("/reservations/:id/cancel", reservationHandler.CancelReservation, authmw.WaiterManagerOrAdmin())
+	protected.GET("/waitlist", reservationHandler.ListWaitlist)
+	protected.POST("/waitlist", reservationHandler.AddToWaitlist, authmw.WaiterManagerOrAdmin())
+	protected.POST("/waitlist/:id/notify", reservationHandler.NotifyWaitlist, authmw.WaiterManagerOrAdmin())
+	protected.POST("/waitlist/:id/seat", reservationHandler.SeatWaitlist, authmw.WaiterManagerOrAdmin())
+	protected.POST("/waitlist/:id/leave", reservationHandler.LeaveWaitlist, authmw.WaiterManagerOrAdmin())
+
+	protected.GET("/customers", customerHandler.ListCustomers, authmw.WaiterManagerOrAdmin())
+	protected.POST("/customers", customerHandler.CreateCustomer, authmw.WaiterManagerOrAdmin())
 	protected.GET("/customers/phone/:phone", customerHandler.GetCustomerByPhone, authmw.WaiterOrAdmin())
 	protected.GET("/customers/top", customerHandler.GetTopCustomers, authmw.ManagerOrAdmin())
+	protected.GET("/customers/:id", customerHandler.GetCustomer, authmw.WaiterManagerOrAdmin())
+	protected.PUT("/customers/:id", customerHandler.UpdateCustomer, authmw.WaiterManagerOrAdmin())
+	protected.DELETE("/customers/:id", customerHandler.DeleteCustomer, authmw.ManagerOrAdmin())
+	protected.POST("/customers/:id/merge", customerHandler.MergeCustomers, authmw.ManagerOrAdmin())
 	protected.GET("/customers/:id/orders", customerHandler.GetCustomerOrders, authmw.WaiterOrAdmin())
+	protected.GET("/customers/:id/deposits", depositHandler.ListCustomerDeposits, authmw.CashierManagerOrAdmin())
+	protected.GET("/customers/:id/gift-card-ledger", giftCardHandler.GetCustomerGiftCardLedger, authmw.CashierManagerOrAdmin())
+
+	// Deposit routes - DP pelanggan/order, dipakai otomatis saat order dilunasi
+	protected.GET("/deposits", depositHandler.ListDeposits, authmw.CashierManagerOrAdmin())
+	protected.POST("/deposits", depositHandler.CreateDeposit, authmw.CashierManagerOrAdmin())
+	protected.GET("/deposits/:id", depositHandler.GetDeposit, authmw.CashierManagerOrAdmin())
+	protected.POST("/deposits/:id/attach", depositHandler.AttachDeposit, authmw.CashierManagerOrAdmin())
+	protected.POST("/deposits/:id/refund", depositHandler.RefundDeposit, authmw.CashierManagerOrAdmin())
+
+	// Gift card routes - kartu/dompet saldo, ditukar lewat metode pembayaran gift_card
+	protected.GET("/gift-cards", giftCardHandler.ListGiftCards, authmw.CashierManagerOrAdmin())
+	protected.POST("/gift-cards", giftCardHandler.IssueGiftCard, authmw.CashierManagerOrAdmin())
+	protected.GET("/gift-cards/liability", giftCardHandler.GetGiftCardLiability, authmw.ManagerOrAdmin())
+	protected.GET("/gift-cards/code/:code", giftCardHandler.GetGiftCardByCode, authmw.CashierManagerOrAdmin())
+	protected.GET("/gift-cards/:id", giftCardHandler.GetGiftCard, authmw.CashierManagerOrAdmin())
+	protected.POST("/gift-cards/:id/topup", giftCardHandler.TopUpGiftCard, authmw.CashierManagerOrAdmin())
+	protected.PATCH("/gift-cards/:id/status", giftCardHandler.UpdateGiftCardStatus, authmw.ManagerOrAdmin())
 
 	// Transaction routes - Cashier/Admin can create, all can read
 	protected.POST("/transactions", transactionHandler.CreateTransaction, authmw.CashierOrAdmin())
@@ -456,6 +730,8 @@ func main() {
 	cashierShiftGroup.POST("/shifts/handover", transactionHandler.HandoverCashierShift)
 	cashierShiftGroup.POST("/shifts/movements", transactionHandler.CreateCashMovement)
 	cashierShiftGroup.GET("/users", transactionHandler.ListCashierUsers)
+	cashierShiftGroup.POST("/drawer/open", cashDrawerHandler.OpenCashDrawer)
+	protected.GET("/cashier/drawer/events", cashDrawerHandler.GetCashDrawerEvents, authmw.ManagerOrAdmin())
 
 	// Config routes - Admin or Manager
 	configGroup := protected.Group("/config", authmw.ManagerOrAdmin())
@@ -511,6 +787,9 @@ func main() {
 		log.Println("Cloud webhook endpoints registered")
 	}
 
+	// Payment provider callback - Public (verified by provider signature)
+	api.POST("/payments/callback/:provider", paymentHandler.HandleCallback)
+
 	e.Any("/socket.io", echo.WrapHandler(socketServer))
 	e.Any("/socket.io/", echo.WrapHandler(socketServer))
 	e.Any("/socket.io/*", echo.WrapHandler(socketServer))
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"log"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -21,12 +22,56 @@ type Config struct {
 	WebhookSecret   string
 	SyncEnabled     bool
 	SyncIntervalMin int
+
+	// Payment Provider Configuration
+	PaymentProvider       string
+	PaymentIntentTTLMin   int
+	PaymentSandboxEnabled bool
+	PaymentSandboxSecret  string
+	MidtransServerKey     string
+	MidtransBaseURL       string
+
+	// Cashier Shift Close Configuration
+	ShiftBlindClose        bool
+	ShiftVarianceThreshold float64
+
+	// Reservation Configuration
+	ReservationHoldMin     int
+	ReservationNoShowMin   int
+	ReservationDurationMin int
+
+	// Guest QR Ordering Configuration
+	GuestOrderSecret          string
+	GuestOrderBaseURL         string
+	GuestOrderRequireApproval bool
+	GuestOrderRateLimit       int
+	GuestOrderMaxPending      int
 }
 
 func LoadConfig() *Config {
 	loadDotEnv(".env")
 	syncEnabled, _ := strconv.ParseBool(getEnv("SYNC_ENABLED", "false"))
 	syncInterval, _ := strconv.Atoi(getEnv("SYNC_INTERVAL_MINUTES", "5"))
+	intentTTL, _ := strconv.Atoi(getEnv("PAYMENT_INTENT_TTL_MINUTES", "15"))
+	sandboxEnabled, _ := strconv.ParseBool(getEnv("PAYMENT_SANDBOX_ENABLED", "false"))
+	// Nilai tidak valid tetap blind close agar salah ketik tidak membuka ekspektasi kas ke kasir
+	blindClose, err := strconv.ParseBool(getEnv("SHIFT_BLIND_CLOSE", "false"))
+	if err != nil {
+		log.Printf("SHIFT_BLIND_CLOSE tidak valid (%v), blind close tetap aktif", err)
+		blindClose = true
+	}
+	varianceThreshold, _ := strconv.ParseFloat(getEnv("SHIFT_VARIANCE_THRESHOLD", "10000"), 64)
+	reservationHold, _ := strconv.Atoi(getEnv("RESERVATION_HOLD_MINUTES", "30"))
+	reservationNoShow, _ := strconv.Atoi(getEnv("RESERVATION_NO_SHOW_MINUTES", "15"))
+	reservationDuration, _ := strconv.Atoi(getEnv("RESERVATION_DURATION_MINUTES", "90"))
+	// Nilai tidak valid tetap wajib approval agar order QR tidak langsung masuk dapur
+	guestApproval, err := strconv.ParseBool(getEnv("GUEST_ORDER_REQUIRE_APPROVAL", "true"))
+	if err != nil {
+		log.Printf("GUEST_ORDER_REQUIRE_APPROVAL tidak valid (%v), approval order tamu tetap aktif", err)
+		guestApproval = true
+	}
+	guestRateLimit, _ := strconv.Atoi(getEnv("GUEST_ORDER_RATE_LIMIT_PER_MINUTE", "30"))
+	guestMaxPending, _ := strconv.Atoi(getEnv("GUEST_ORDER_MAX_PENDING_PER_TABLE", "5"))
 	dbPath := os.Getenv("DB_PATH")
 	if dbPath == "" {
 		dbPath = defaultDBPath()
@@ -46,6 +91,30 @@ func LoadConfig() *Config {
 		WebhookSecret:   getEnv("WEBHOOK_SECRET", ""),
 		SyncEnabled:     syncEnabled,
 		SyncIntervalMin: syncInterval,
+
+		// Payment Provider
+		PaymentProvider:       getEnv("PAYMENT_PROVIDER", ""),
+		PaymentIntentTTLMin:   intentTTL,
+		PaymentSandboxEnabled: sandboxEnabled,
+		PaymentSandboxSecret:  getEnv("PAYMENT_SANDBOX_SECRET", ""),
+		MidtransServerKey:     getEnv("MIDTRANS_SERVER_KEY", ""),
+		MidtransBaseURL:       getEnv("MIDTRANS_BASE_URL", ""),
+
+		// Cashier Shift Close
+		ShiftBlindClose:        blindClose,
+		ShiftVarianceThreshold: varianceThreshold,
+
+		// Reservation
+		ReservationHoldMin:     reservationHold,
+		ReservationNoShowMin:   reservationNoShow,
+		ReservationDurationMin: reservationDuration,
+
+		// Guest QR Ordering
+		GuestOrderSecret:          getEnv("GUEST_ORDER_SECRET", ""),
+		GuestOrderBaseURL:         getEnv("GUEST_ORDER_BASE_URL", ""),
+		GuestOrderRequireApproval: guestApproval,
+		GuestOrderRateLimit:       guestRateLimit,
+		GuestOrderMaxPending:      guestMaxPending,
 	}
 }
 
@@ -135,6 +135,7 @@ type Transaction struct {
 	CancelReason    sql.NullString `json:"cancel_reason"`
 	CreatedAt       time.Time      `json:"created_at"`
 	UpdatedAt       time.Time      `json:"updated_at"`
+	ShiftID         sql.NullString `json:"shift_id"`
 }
 
 type TransactionItem struct {
@@ -129,7 +129,7 @@ func (q *Queries) GetAvailableTables(ctx context.Context) ([]Table, error) {
 
 const getOccupiedTables = `-- name: GetOccupiedTables :many
 SELECT id, table_number, capacity, status, created_at, updated_at FROM tables
-WHERE status = 'occupied'
+WHERE status IN ('seated', 'ordered', 'bill_requested', 'paid')
 ORDER BY table_number
 `
 
@@ -23,19 +23,20 @@ func (q *Queries) CountTransactions(ctx context.Context) (int64, error) {
 }
 
 const createTransaction = `-- name: CreateTransaction :one
-INSERT INTO transactions (id, order_id, total_amount, payment_method, status, transaction_date, created_by, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-RETURNING id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at
+INSERT INTO transactions (id, order_id, total_amount, payment_method, status, transaction_date, created_by, shift_id, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+RETURNING id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at, shift_id
 `
 
 type CreateTransactionParams struct {
-	ID              string    `json:"id"`
-	OrderID         string    `json:"order_id"`
-	TotalAmount     float64   `json:"total_amount"`
-	PaymentMethod   string    `json:"payment_method"`
-	Status          string    `json:"status"`
-	TransactionDate time.Time `json:"transaction_date"`
-	CreatedBy       string    `json:"created_by"`
+	ID              string         `json:"id"`
+	OrderID         string         `json:"order_id"`
+	TotalAmount     float64        `json:"total_amount"`
+	PaymentMethod   string         `json:"payment_method"`
+	Status          string         `json:"status"`
+	TransactionDate time.Time      `json:"transaction_date"`
+	CreatedBy       string         `json:"created_by"`
+	ShiftID         sql.NullString `json:"shift_id"`
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -47,6 +48,7 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.Status,
 		arg.TransactionDate,
 		arg.CreatedBy,
+		arg.ShiftID,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -62,6 +64,7 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.CancelReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ShiftID,
 	)
 	return i, err
 }
@@ -70,7 +73,7 @@ const cancelTransaction = `-- name: CancelTransaction :one
 UPDATE transactions
 SET status = ?, cancelled_at = ?, cancelled_by = ?, cancel_reason = ?, updated_at = ?
 WHERE id = ?
-RETURNING id, order_id, total_amount, payment_method, status, transaction_date, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at
+RETURNING id, order_id, total_amount, payment_method, status, transaction_date, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at, shift_id
 `
 
 type CancelTransactionParams struct {
@@ -104,6 +107,7 @@ func (q *Queries) CancelTransaction(ctx context.Context, arg CancelTransactionPa
 		&i.CancelReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ShiftID,
 	)
 	return i, err
 }
@@ -142,7 +146,7 @@ func (q *Queries) CreateTransactionItem(ctx context.Context, arg CreateTransacti
 }
 
 const getTransaction = `-- name: GetTransaction :one
-SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at FROM transactions
+SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at, shift_id FROM transactions
 WHERE id = ? LIMIT 1
 `
 
@@ -162,6 +166,7 @@ func (q *Queries) GetTransaction(ctx context.Context, id string) (Transaction, e
 		&i.CancelReason,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ShiftID,
 	)
 	return i, err
 }
@@ -213,7 +218,7 @@ func (q *Queries) ListTransactionItems(ctx context.Context, transactionID string
 }
 
 const listTransactions = `-- name: ListTransactions :many
-SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at FROM transactions
+SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at, shift_id FROM transactions
 ORDER BY transaction_date DESC
 `
 
@@ -239,6 +244,7 @@ func (q *Queries) ListTransactions(ctx context.Context) ([]Transaction, error) {
 			&i.CancelReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ShiftID,
 		); err != nil {
 			return nil, err
 		}
@@ -254,7 +260,7 @@ func (q *Queries) ListTransactions(ctx context.Context) ([]Transaction, error) {
 }
 
 const listTransactionsByDateRange = `-- name: ListTransactionsByDateRange :many
-SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at FROM transactions
+SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at, shift_id FROM transactions
 WHERE transaction_date BETWEEN ? AND ?
 ORDER BY transaction_date DESC
 `
@@ -286,6 +292,7 @@ func (q *Queries) ListTransactionsByDateRange(ctx context.Context, arg ListTrans
 			&i.CancelReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ShiftID,
 		); err != nil {
 			return nil, err
 		}
@@ -301,7 +308,7 @@ func (q *Queries) ListTransactionsByDateRange(ctx context.Context, arg ListTrans
 }
 
 const listTransactionsPaginated = `-- name: ListTransactionsPaginated :many
-SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at FROM transactions
+SELECT id, order_id, total_amount, payment_method, status, transaction_date, created_by, cancelled_at, cancelled_by, cancel_reason, created_at, updated_at, shift_id FROM transactions
 ORDER BY transaction_date DESC
 LIMIT ? OFFSET ?
 `
@@ -333,6 +340,7 @@ func (q *Queries) ListTransactionsPaginated(ctx context.Context, arg ListTransac
 			&i.CancelReason,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ShiftID,
 		); err != nil {
 			return nil, err
 		}
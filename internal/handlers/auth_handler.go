@@ -3,8 +3,11 @@ package handlers
 import (
 	"backend/internal/db"
 	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"context"
 	"crypto/rand"
 	"database/sql"
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v5"
@@ -13,15 +16,28 @@ import (
 )
 
 type AuthHandler struct {
-	queries *db.Queries
+	queries  *db.Queries
+	syncRepo repositories.SyncRepository
 }
 
-func NewAuthHandler(dbConn *sql.DB) *AuthHandler {
+func NewAuthHandler(dbConn *sql.DB, syncRepo repositories.SyncRepository) *AuthHandler {
 	return &AuthHandler{
-		queries: db.New(dbConn),
+		queries:  db.New(dbConn),
+		syncRepo: syncRepo,
 	}
 }
 
+// rejectHQManagedUser menolak perubahan akun yang dikelola HQ
+func (h *AuthHandler) rejectHQManagedUser(c *echo.Context, userID string) (bool, error) {
+	if err := checkManagedByHQ(c, h.syncRepo, "user", userID); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return true, ErrorResponse(c, http.StatusForbidden, "Akun dikelola oleh HQ, hanya PIN yang dapat diubah di outlet")
+		}
+		return true, InternalErrorResponse(c, "Gagal cek data HQ")
+	}
+	return false, nil
+}
+
 type RegisterRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
@@ -396,6 +412,12 @@ func (h *AuthHandler) HandleUpdateUser(c *echo.Context) error {
 		return BadRequestResponse(c, "Tidak ada data yang diubah")
 	}
 
+	if req.Username != "" || req.FullName != "" || req.Role != "" || req.IsActive != nil {
+		if rejected, err := h.rejectHQManagedUser(c, userID); rejected {
+			return err
+		}
+	}
+
 	_, err = h.queries.GetUserByID((*c).Request().Context(), userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -518,6 +540,10 @@ func (h *AuthHandler) HandleUpdateUserRole(c *echo.Context) error {
 		return BadRequestResponse(c, "Role tidak valid. Role yang tersedia: admin, waiter, kitchen, bar, cashier, manager")
 	}
 
+	if rejected, err := h.rejectHQManagedUser(c, userID); rejected {
+		return err
+	}
+
 	// Check if user exists
 	_, err = h.queries.GetUserByID(c.Request().Context(), userID)
 	if err != nil {
@@ -562,6 +588,10 @@ func (h *AuthHandler) HandleDeactivateUser(c *echo.Context) error {
 		return BadRequestResponse(c, "Tidak dapat menonaktifkan akun diri sendiri")
 	}
 
+	if rejected, err := h.rejectHQManagedUser(c, userID); rejected {
+		return err
+	}
+
 	// Check if user exists
 	_, err = h.queries.GetUserByID((*c).Request().Context(), userID)
 	if err != nil {
@@ -586,6 +616,10 @@ func (h *AuthHandler) HandleActivateUser(c *echo.Context) error {
 		return BadRequestResponse(c, "User ID tidak valid")
 	}
 
+	if rejected, err := h.rejectHQManagedUser(c, userID); rejected {
+		return err
+	}
+
 	// Check if user exists
 	_, err := h.queries.GetUserByID((*c).Request().Context(), userID)
 	if err != nil {
@@ -618,6 +652,10 @@ func (h *AuthHandler) HandleDeleteUser(c *echo.Context) error {
 		return BadRequestResponse(c, "Tidak dapat menonaktifkan akun diri sendiri")
 	}
 
+	if rejected, err := h.rejectHQManagedUser(c, userID); rejected {
+		return err
+	}
+
 	_, err = h.queries.GetUserByID((*c).Request().Context(), userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -632,3 +670,17 @@ func (h *AuthHandler) HandleDeleteUser(c *echo.Context) error {
 
 	return SuccessResponse(c, "User berhasil dinonaktifkan", nil)
 }
+
+// verifyManagerPIN mencari manager aktif pemilik PIN; errInvalidManagerPIN jika tidak ada yang cocok
+func verifyManagerPIN(ctx context.Context, q *db.Queries, pin string) (*db.User, error) {
+	managers, err := q.ListActiveManagers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, manager := range managers {
+		if bcrypt.CompareHashAndPassword([]byte(manager.PasswordHash), []byte(pin)) == nil {
+			return &manager, nil
+		}
+	}
+	return nil, errInvalidManagerPIN
+}
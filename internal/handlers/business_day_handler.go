@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/workers"
+	"backend/pkg/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// BusinessDayHandler menangani X report (snapshot berjalan, tidak mereset) dan
+// Z report (tutup hari bisnis dengan nomor Z berurutan)
+type BusinessDayHandler struct {
+	businessDayService services.BusinessDayService
+	queries            *db.Queries
+}
+
+func NewBusinessDayHandler(businessDayService services.BusinessDayService, queries *db.Queries) *BusinessDayHandler {
+	return &BusinessDayHandler{
+		businessDayService: businessDayService,
+		queries:            queries,
+	}
+}
+
+type CloseBusinessDayRequest struct {
+	BusinessDate string `json:"business_date"`
+	Print        *bool  `json:"print"`
+}
+
+// GetXReport - ringkasan penjualan sejak Z report terakhir tanpa menutup hari
+func (h *BusinessDayHandler) GetXReport(c *echo.Context) error {
+	report, err := h.businessDayService.GetXReport((*c).Request().Context())
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil X report: "+err.Error())
+	}
+	return SuccessResponse(c, "X report berhasil diambil", report)
+}
+
+// PrintXReport - cetak X report ke printer kasir
+func (h *BusinessDayHandler) PrintXReport(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	report, err := h.businessDayService.GetXReport(ctx)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil X report: "+err.Error())
+	}
+	if !h.enqueueSalesReport(ctx, report, claims.UserID, false) {
+		return BadRequestResponse(c, "Printer kasir belum dikonfigurasi")
+	}
+	return SuccessResponse(c, "X report dikirim ke printer", report)
+}
+
+// CloseDay - buat Z report dan tutup hari bisnis. Semua order harus lunas dan
+// semua shift kasir sudah ditutup; data hari yang ditutup tidak bisa diubah lagi.
+func (h *BusinessDayHandler) CloseDay(c *echo.Context) error {
+	var req CloseBusinessDayRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	ctx := (*c).Request().Context()
+	zReport, err := h.businessDayService.CloseDay(ctx, req.BusinessDate, claims.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrInvalidBusinessDate),
+			errors.Is(err, repositories.ErrBusinessDayClosed),
+			errors.Is(err, repositories.ErrBusinessDayPendingOrders),
+			errors.Is(err, repositories.ErrBusinessDayOpenShift):
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal menutup hari bisnis: "+err.Error())
+	}
+
+	printed := false
+	if req.Print == nil || *req.Print {
+		printed = h.enqueueSalesReport(ctx, &zReport.Report, claims.UserID, false)
+	}
+	return CreatedResponse(c, fmt.Sprintf("Hari bisnis %s ditutup (Z #%d)", zReport.BusinessDate, zReport.ZNumber), map[string]interface{}{
+		"z_report": zReport,
+		"printed":  printed,
+	})
+}
+
+// ListZReports - riwayat Z report terbaru lebih dulu
+func (h *BusinessDayHandler) ListZReports(c *echo.Context) error {
+	params := GetPaginationParams(c)
+	reports, total, err := h.businessDayService.ListZReports((*c).Request().Context(), int64(params.PageSize), int64(params.Offset))
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil Z report: "+err.Error())
+	}
+	pagination := CalculatePagination(params.Page, params.PageSize, total)
+	return PaginatedSuccessResponse(c, "Z report berhasil diambil", reports, pagination)
+}
+
+// GetZReport - detail Z report beserta ringkasan yang dibekukan
+func (h *BusinessDayHandler) GetZReport(c *echo.Context) error {
+	zReport, err := h.businessDayService.GetZReport((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrZReportNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal mengambil Z report: "+err.Error())
+	}
+	return SuccessResponse(c, "Z report berhasil diambil", zReport)
+}
+
+// ReprintZReport - cetak ulang Z report dari ringkasan yang tersimpan
+func (h *BusinessDayHandler) ReprintZReport(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	zReport, err := h.businessDayService.GetZReport(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrZReportNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal mengambil Z report: "+err.Error())
+	}
+	if !h.enqueueSalesReport(ctx, &zReport.Report, claims.UserID, true) {
+		return BadRequestResponse(c, "Printer kasir belum dikonfigurasi")
+	}
+	return SuccessResponse(c, "Z report dikirim ke printer", zReport)
+}
+
+func (h *BusinessDayHandler) enqueueSalesReport(ctx context.Context, report *repositories.SalesReport, printedBy string, reprint bool) bool {
+	printerID, ok := h.getReceiptPrinterID(ctx)
+	if !ok {
+		return false
+	}
+
+	printedByName := ""
+	if user, err := h.queries.GetUserByID(ctx, printedBy); err == nil {
+		printedByName = user.FullName
+	}
+
+	toInt := func(amount float64) int {
+		return int(math.Round(amount))
+	}
+	toLines := func(lines []repositories.SalesReportLine) []workers.ReceiptCharge {
+		items := make([]workers.ReceiptCharge, 0, len(lines))
+		for _, line := range lines {
+			items = append(items, workers.ReceiptCharge{Name: line.Name, Amount: toInt(line.Amount)})
+		}
+		return items
+	}
+
+	taxes := make([]workers.ReceiptTax, 0, len(report.TaxLines))
+	for _, line := range report.TaxLines {
+		taxes = append(taxes, workers.ReceiptTax{
+			Name:        line.Name,
+			Rate:        line.Rate,
+			Amount:      toInt(line.TaxAmount),
+			IsInclusive: line.IsInclusive,
+		})
+	}
+	payments := make([]workers.ShiftPaymentData, 0, len(report.PaymentMethods))
+	for _, line := range report.PaymentMethods {
+		payments = append(payments, workers.ShiftPaymentData{Name: line.Name, Amount: toInt(line.Net)})
+	}
+	shifts := make([]workers.SalesReportShiftData, 0, len(report.Shifts))
+	for _, shift := range report.Shifts {
+		item := workers.SalesReportShiftData{CashierName: shift.CashierName}
+		if shift.CashVariance != nil {
+			item.Counted = true
+			item.Variance = toInt(*shift.CashVariance)
+		}
+		shifts = append(shifts, item)
+	}
+
+	receiptNumber := "X-" + time.Now().Format("20060102150405")
+	if report.ReportType == repositories.ReportTypeZ {
+		receiptNumber = fmt.Sprintf("Z-%04d", report.ZNumber)
+	}
+	payload := workers.PrintJobData{
+		ReceiptNumber: receiptNumber,
+		DateTime:      time.Now(),
+		CashierName:   printedByName,
+		SalesReport: &workers.SalesReportData{
+			ReportType:     report.ReportType,
+			ZNumber:        int(report.ZNumber),
+			BusinessDate:   report.BusinessDate,
+			PeriodStart:    report.PeriodStart,
+			PeriodEnd:      report.PeriodEnd,
+			PrintedBy:      printedByName,
+			IsReprint:      reprint,
+			OrdersCount:    int(report.OrdersCount),
+			Pax:            int(report.Pax),
+			GrossSales:     toInt(report.GrossSales),
+			Discounts:      toInt(report.Discounts),
+			Charges:        toInt(report.Charges),
+			Taxes:          toInt(report.Taxes),
+			NetSales:       toInt(report.NetSales),
+			RefundsCount:   int(report.RefundsCount),
+			Refunds:        toInt(report.Refunds),
+			VoidsCount:     int(report.VoidsCount),
+			Voids:          toInt(report.Voids),
+			CancelledCount: int(report.CancelledCount),
+			Cancelled:      toInt(report.Cancelled),
+			Tips:           toInt(report.Tips),
+			PaymentsTotal:  toInt(report.PaymentsTotal),
+			CashVariance:   toInt(report.CashVariance),
+			DiscountLines:  toLines(report.DiscountLines),
+			ChargeLines:    toLines(report.ChargeLines),
+			TaxLines:       taxes,
+			Payments:       payments,
+			Shifts:         shifts,
+		},
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+	_, err = h.queries.CreatePrintJob(ctx, db.CreatePrintJobParams{
+		ID:        utils.GenerateULID(),
+		PrinterID: printerID,
+		Data:      string(payloadJSON),
+	})
+	return err == nil
+}
+
+func (h *BusinessDayHandler) getReceiptPrinterID(ctx context.Context) (string, bool) {
+	strukPrinters, err := h.queries.ListPrintersByType(ctx, "struk")
+	if err == nil && len(strukPrinters) > 0 {
+		return strukPrinters[0].ID, true
+	}
+
+	cashierPrinters, err := h.queries.ListPrintersByType(ctx, "cashier")
+	if err == nil && len(cashierPrinters) > 0 {
+		return cashierPrinters[0].ID, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/workers"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// CashDrawerHandler menangani pengaturan laci kas per printer, buka laci manual
+// dan log audit pembukaan laci
+type CashDrawerHandler struct {
+	cashDrawerService services.CashDrawerService
+	db                *sql.DB
+	queries           *db.Queries
+}
+
+func NewCashDrawerHandler(cashDrawerService services.CashDrawerService, sqlDB *sql.DB, queries *db.Queries) *CashDrawerHandler {
+	return &CashDrawerHandler{
+		cashDrawerService: cashDrawerService,
+		db:                sqlDB,
+		queries:           queries,
+	}
+}
+
+type DrawerSettingsRequest struct {
+	Enabled bool `json:"enabled"`
+	Pin     int  `json:"pin"`
+	OnMs    int  `json:"on_ms"`
+	OffMs   int  `json:"off_ms"`
+}
+
+type OpenCashDrawerRequest struct {
+	Reason string `json:"reason"`
+}
+
+// kickCashDrawer mencatat pembukaan laci lalu mengantrikan pulsa ESC p ke printer laci
+func kickCashDrawer(ctx context.Context, q db.DBTX, queries *db.Queries, event *repositories.CashDrawerEvent) error {
+	if err := repositories.OpenCashDrawer(ctx, q, event); err != nil {
+		return err
+	}
+
+	payloadJSON, err := json.Marshal(workers.PrintJobData{
+		ReceiptNumber: "DRAWER-" + event.ID,
+		DateTime:      time.Now(),
+		IsDrawerKick:  true,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = queries.CreatePrintJob(ctx, db.CreatePrintJobParams{
+		ID:        utils.GenerateULID(),
+		PrinterID: *event.PrinterID,
+		Data:      string(payloadJSON),
+	})
+	return err
+}
+
+// kickCashDrawerForShift membuka laci otomatis setelah transaksi tunai. Outlet tanpa
+// laci kas cukup dilewati; kegagalan lain tidak membatalkan transaksi.
+func kickCashDrawerForShift(ctx context.Context, q db.DBTX, queries *db.Queries, source, referenceID, shiftID, userID string) {
+	event := &repositories.CashDrawerEvent{
+		ShiftID:  &shiftID,
+		Source:   source,
+		OpenedBy: userID,
+	}
+	if referenceID != "" {
+		event.ReferenceID = &referenceID
+	}
+	_ = kickCashDrawer(ctx, q, queries, event)
+}
+
+// OpenCashDrawer - buka laci manual (tanpa transaksi), alasan wajib diisi untuk audit
+func (h *CashDrawerHandler) OpenCashDrawer(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req OpenCashDrawerRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return BadRequestResponse(c, "Alasan buka laci wajib diisi")
+	}
+
+	ctx := (*c).Request().Context()
+	terminal, shiftID, err := resolveTerminalShift(c, h.db)
+	if err != nil && err != sql.ErrNoRows {
+		return respondShiftError(c, err)
+	}
+
+	event := &repositories.CashDrawerEvent{
+		TerminalID: &terminal.ID,
+		ShiftID:    &shiftID,
+		Source:     repositories.DrawerSourceManual,
+		Reason:     req.Reason,
+		OpenedBy:   claims.UserID,
+	}
+	if err := kickCashDrawer(ctx, h.db, h.queries, event); err != nil {
+		if errors.Is(err, repositories.ErrDrawerNotConfigured) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuka laci kas: "+err.Error())
+	}
+	return SuccessResponse(c, "Laci kas dibuka", event)
+}
+
+// GetCashDrawerEvents - log pembukaan laci per shift_id, atau start_date/end_date.
+// Filter tambahan: terminal_id dan source.
+func (h *CashDrawerHandler) GetCashDrawerEvents(c *echo.Context) error {
+	filter := repositories.CashDrawerEventFilter{
+		ShiftID:    c.QueryParam("shift_id"),
+		TerminalID: c.QueryParam("terminal_id"),
+		Source:     c.QueryParam("source"),
+	}
+	if filter.ShiftID == "" {
+		startDate, endDate, err := parseDateRangeWithLimit(c.QueryParam("start_date"), c.QueryParam("end_date"), 3)
+		if err != nil {
+			return BadRequestResponse(c, err.Error())
+		}
+		filter.StartDate = startDate
+		filter.EndDate = endDate
+	}
+
+	events, err := h.cashDrawerService.ListEvents((*c).Request().Context(), filter)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil log laci kas: "+err.Error())
+	}
+	return SuccessResponse(c, "Log laci kas berhasil diambil", events)
+}
+
+func (h *CashDrawerHandler) GetDrawerSettings(c *echo.Context) error {
+	settings, err := h.cashDrawerService.GetSettings((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrDrawerPrinterNotFound) {
+			return NotFoundResponse(c, "Printer tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil pengaturan laci kas: "+err.Error())
+	}
+	return SuccessResponse(c, "Pengaturan laci kas berhasil diambil", settings)
+}
+
+func (h *CashDrawerHandler) UpdateDrawerSettings(c *echo.Context) error {
+	var req DrawerSettingsRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	// Default pulsa umum laci kas: pin 2, on 100ms, off 200ms
+	if req.Pin == 0 {
+		req.Pin = 2
+	}
+	if req.OnMs == 0 {
+		req.OnMs = 100
+	}
+	if req.OffMs == 0 {
+		req.OffMs = 200
+	}
+
+	settings := &repositories.DrawerSettings{
+		PrinterID: c.Param("id"),
+		Enabled:   req.Enabled,
+		Pin:       req.Pin,
+		OnMs:      req.OnMs,
+		OffMs:     req.OffMs,
+	}
+	if err := h.cashDrawerService.UpdateSettings((*c).Request().Context(), settings); err != nil {
+		if errors.Is(err, repositories.ErrDrawerPrinterNotFound) {
+			return NotFoundResponse(c, "Printer tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidDrawerSettings) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal menyimpan pengaturan laci kas: "+err.Error())
+	}
+	return SuccessResponse(c, "Pengaturan laci kas berhasil disimpan", settings)
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"backend/internal/db"
+	"backend/internal/repositories"
 	"backend/internal/services"
 	"database/sql"
+	"errors"
+	"net/http"
 	"time"
 
 	"github.com/labstack/echo/v5"
@@ -11,11 +14,13 @@ import (
 
 type CategoryHandler struct {
 	categoryService services.CategoryService
+	syncRepo        repositories.SyncRepository
 }
 
-func NewCategoryHandler(categoryService services.CategoryService) *CategoryHandler {
+func NewCategoryHandler(categoryService services.CategoryService, syncRepo repositories.SyncRepository) *CategoryHandler {
 	return &CategoryHandler{
 		categoryService: categoryService,
+		syncRepo:        syncRepo,
 	}
 }
 
@@ -234,6 +239,14 @@ func (h *CategoryHandler) UpdateCategory(c *echo.Context) error {
 		return BadRequestResponse(c, "Body request tidak valid")
 	}
 
+	// Nama dan routing printer kategori HQ mengikuti menu pusat
+	if err := checkManagedByHQ(c, h.syncRepo, "category", id); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return ErrorResponse(c, http.StatusForbidden, "Kategori dikelola oleh HQ dan tidak dapat diubah")
+		}
+		return InternalErrorResponse(c, "Gagal cek data HQ: "+err.Error())
+	}
+
 	if err := h.categoryService.UpdateCategory((*c).Request().Context(), id, req.Name, req.Description, req.PrinterID); err != nil {
 		return InternalErrorResponse(c, "Gagal update kategori: "+err.Error())
 	}
@@ -245,6 +258,13 @@ func (h *CategoryHandler) UpdateCategory(c *echo.Context) error {
 func (h *CategoryHandler) DeleteCategory(c *echo.Context) error {
 	id := c.Param("id")
 
+	if err := checkManagedByHQ(c, h.syncRepo, "category", id); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return ErrorResponse(c, http.StatusForbidden, "Kategori dikelola oleh HQ dan tidak dapat dihapus")
+		}
+		return InternalErrorResponse(c, "Gagal cek data HQ: "+err.Error())
+	}
+
 	if err := h.categoryService.DeleteCategory((*c).Request().Context(), id); err != nil {
 		return InternalErrorResponse(c, "Gagal menghapus kategori: "+err.Error())
 	}
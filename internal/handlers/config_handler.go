@@ -6,6 +6,7 @@ import (
 	"backend/internal/workers"
 	"backend/pkg/utils"
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -384,6 +385,17 @@ func (h *ConfigHandler) UpdateAdditionalCharge(c *echo.Context) error {
 		})
 	}
 
+	if err := checkManagedByHQ(c, h.syncRepo, "additional_charge", strconv.FormatInt(id, 10)); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Additional charge is managed by HQ",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to check HQ managed flag: " + err.Error(),
+		})
+	}
+
 	if req.Name == "" {
 		return c.JSON(http.StatusBadRequest, map[string]string{
 			"error": "name is required",
@@ -452,6 +464,17 @@ func (h *ConfigHandler) DeleteAdditionalCharge(c *echo.Context) error {
 		})
 	}
 
+	if err := checkManagedByHQ(c, h.syncRepo, "additional_charge", strconv.FormatInt(id, 10)); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Additional charge is managed by HQ",
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Failed to check HQ managed flag: " + err.Error(),
+		})
+	}
+
 	if err := h.syncRepo.DeleteAdditionalCharge((*c).Request().Context(), id); err != nil {
 		if err == sql.ErrNoRows {
 			return c.JSON(http.StatusNotFound, map[string]string{
@@ -1,9 +1,10 @@
 package handlers
 
 import (
-	"backend/internal/db"
+	"backend/internal/repositories"
 	"backend/internal/services"
 	"database/sql"
+	"errors"
 	"strconv"
 	"time"
 
@@ -22,12 +23,6 @@ func NewCustomerHandler(customerService services.CustomerService, orderService s
 	}
 }
 
-type CustomerResponse struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Phone string `json:"phone"`
-}
-
 type TopCustomerResponse struct {
 	ID          string  `json:"id"`
 	Name        string  `json:"name"`
@@ -36,11 +31,42 @@ type TopCustomerResponse struct {
 	TotalSpent  float64 `json:"total_spent"`
 }
 
-func toCustomerResponse(customer *db.Customer) CustomerResponse {
-	return CustomerResponse{
-		ID:    customer.ID,
-		Name:  customer.Name,
-		Phone: customer.Phone,
+type CustomerProfileRequest struct {
+	Name         string   `json:"name"`
+	Phone        string   `json:"phone"`
+	Email        string   `json:"email"`
+	Birthday     string   `json:"birthday"`
+	Tags         []string `json:"tags"`
+	Allergies    string   `json:"allergies"`
+	DietaryNotes string   `json:"dietary_notes"`
+}
+
+type MergeCustomersRequest struct {
+	DuplicateIDs []string `json:"duplicate_ids"`
+}
+
+func (r CustomerProfileRequest) toInput() repositories.CustomerInput {
+	return repositories.CustomerInput{
+		Name:         r.Name,
+		Phone:        r.Phone,
+		Email:        r.Email,
+		Birthday:     r.Birthday,
+		Tags:         r.Tags,
+		Allergies:    r.Allergies,
+		DietaryNotes: r.DietaryNotes,
+	}
+}
+
+func respondCustomerError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrCustomerNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrCustomerPhoneExists), errors.Is(err, repositories.ErrCustomerInUse):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidCustomer):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
 	}
 }
 
@@ -58,7 +84,96 @@ func (h *CustomerHandler) GetCustomerByPhone(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil data pelanggan: "+err.Error())
 	}
 
-	return SuccessResponse(c, "Pelanggan ditemukan", toCustomerResponse(customer))
+	// Profil lengkap supaya alergi dan menu favorit terlihat saat mencatat pesanan
+	profile, err := h.customerService.GetProfile((*c).Request().Context(), customer.ID)
+	if err != nil {
+		return respondCustomerError(c, err, "Gagal mengambil data pelanggan")
+	}
+	return SuccessResponse(c, "Pelanggan ditemukan", profile)
+}
+
+// ListCustomers - cari pelanggan (?search, ?tag, ?birthday_month, ?sort=name|recent|visits|spent)
+func (h *CustomerHandler) ListCustomers(c *echo.Context) error {
+	params := GetPaginationParams(c)
+	birthdayMonth := 0
+	if value := c.QueryParam("birthday_month"); value != "" {
+		month, err := strconv.Atoi(value)
+		if err != nil {
+			return BadRequestResponse(c, "birthday_month harus berupa angka 1-12")
+		}
+		birthdayMonth = month
+	}
+
+	customers, total, err := h.customerService.ListProfiles((*c).Request().Context(), repositories.CustomerFilter{
+		Search:        c.QueryParam("search"),
+		Tag:           c.QueryParam("tag"),
+		BirthdayMonth: birthdayMonth,
+		Sort:          c.QueryParam("sort"),
+		Limit:         params.PageSize,
+		Offset:        params.Offset,
+	})
+	if err != nil {
+		return respondCustomerError(c, err, "Gagal mengambil data pelanggan")
+	}
+	return PaginatedSuccessResponse(c, "Data pelanggan berhasil diambil", customers, CalculatePagination(params.Page, params.PageSize, total))
+}
+
+func (h *CustomerHandler) CreateCustomer(c *echo.Context) error {
+	var req CustomerProfileRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	customer, err := h.customerService.CreateProfile((*c).Request().Context(), req.toInput())
+	if err != nil {
+		return respondCustomerError(c, err, "Gagal menyimpan data pelanggan")
+	}
+	return CreatedResponse(c, "Pelanggan berhasil ditambahkan", customer)
+}
+
+// GetCustomer - profil pelanggan beserta statistik lifetime dan menu terakhir
+func (h *CustomerHandler) GetCustomer(c *echo.Context) error {
+	customer, err := h.customerService.GetProfile((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondCustomerError(c, err, "Gagal mengambil data pelanggan")
+	}
+	return SuccessResponse(c, "Pelanggan ditemukan", customer)
+}
+
+func (h *CustomerHandler) UpdateCustomer(c *echo.Context) error {
+	var req CustomerProfileRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	customer, err := h.customerService.UpdateProfile((*c).Request().Context(), c.Param("id"), req.toInput())
+	if err != nil {
+		return respondCustomerError(c, err, "Gagal memperbarui data pelanggan")
+	}
+	return SuccessResponse(c, "Pelanggan berhasil diperbarui", customer)
+}
+
+// DeleteCustomer - hanya pelanggan tanpa riwayat; duplikat digabung lewat merge
+func (h *CustomerHandler) DeleteCustomer(c *echo.Context) error {
+	if err := h.customerService.DeleteCustomer((*c).Request().Context(), c.Param("id")); err != nil {
+		return respondCustomerError(c, err, "Gagal menghapus pelanggan")
+	}
+	return SuccessResponse(c, "Pelanggan berhasil dihapus", nil)
+}
+
+// MergeCustomers - gabungkan pelanggan duplikat ke profil :id (order, deposit, gift card,
+// reservasi dan statistik ikut pindah), lalu hapus duplikatnya
+func (h *CustomerHandler) MergeCustomers(c *echo.Context) error {
+	var req MergeCustomersRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	result, err := h.customerService.MergeCustomers((*c).Request().Context(), c.Param("id"), req.DuplicateIDs)
+	if err != nil {
+		return respondCustomerError(c, err, "Gagal menggabungkan pelanggan")
+	}
+	return SuccessResponse(c, "Pelanggan berhasil digabungkan", result)
 }
 
 func (h *CustomerHandler) GetTopCustomers(c *echo.Context) error {
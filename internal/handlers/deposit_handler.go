@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+)
+
+// DepositHandler menangani deposit/DP pelanggan: terima, tautkan ke order dan refund.
+// Pemakaian deposit terjadi otomatis saat order dilunasi (lihat HandleProcessPayment).
+type DepositHandler struct {
+	depositService services.DepositService
+	db             *sql.DB
+	queries        *db.Queries
+}
+
+func NewDepositHandler(depositService services.DepositService, sqlDB *sql.DB, queries *db.Queries) *DepositHandler {
+	return &DepositHandler{
+		depositService: depositService,
+		db:             sqlDB,
+		queries:        queries,
+	}
+}
+
+type CreateDepositRequest struct {
+	CustomerID      string  `json:"customer_id"`
+	OrderID         string  `json:"order_id"`
+	ReservationID   string  `json:"reservation_id"`
+	Amount          float64 `json:"amount"`
+	PaymentMethod   string  `json:"payment_method"`
+	ReferenceNumber string  `json:"reference_number"`
+	Note            string  `json:"note"`
+}
+
+type AttachDepositRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+type RefundDepositRequest struct {
+	ManagerPIN    string `json:"manager_pin"`
+	Reason        string `json:"reason"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+// depositShiftID menentukan shift pencatat deposit. Metode yang masuk laci wajib
+// diterima/dikembalikan di shift terbuka; metode non-tunai boleh di luar shift.
+func (h *DepositHandler) depositShiftID(c *echo.Context, method *repositories.PaymentMethod) (string, error) {
+	if !method.CountsToDrawer {
+		return optionalTerminalShiftID(c, h.db), nil
+	}
+	_, shiftID, err := resolveTerminalShift(c, h.db)
+	return shiftID, err
+}
+
+// resolveDepositMethod memvalidasi metode penerimaan/refund deposit; saldo deposit
+// sendiri tidak bisa dipakai untuk membayar deposit
+func (h *DepositHandler) resolveDepositMethod(c *echo.Context, code string) (*repositories.PaymentMethod, error) {
+	if code == repositories.DepositPaymentMethod {
+		return nil, repositories.ErrPaymentMethodNotFound
+	}
+	return repositories.GetActivePaymentMethod((*c).Request().Context(), h.db, code)
+}
+
+func respondDepositError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrDepositNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidDeposit), errors.Is(err, repositories.ErrDepositNotHeld):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// CreateDeposit - terima deposit untuk pelanggan (customer_id), order (order_id) atau reservasi (reservation_id)
+func (h *DepositHandler) CreateDeposit(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req CreateDepositRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	method, err := h.resolveDepositMethod(c, req.PaymentMethod)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+			return BadRequestResponse(c, "Metode pembayaran deposit tidak valid")
+		}
+		return InternalErrorResponse(c, "Gagal memeriksa metode pembayaran")
+	}
+	if method.RequiresReference && strings.TrimSpace(req.ReferenceNumber) == "" {
+		return BadRequestResponse(c, "reference_number wajib diisi untuk "+method.Name)
+	}
+	shiftID, err := h.depositShiftID(c, method)
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+
+	deposit := &repositories.Deposit{
+		CustomerID:      &req.CustomerID,
+		OrderID:         &req.OrderID,
+		ReservationID:   &req.ReservationID,
+		Amount:          req.Amount,
+		PaymentMethod:   method.Code,
+		ReferenceNumber: req.ReferenceNumber,
+		Note:            req.Note,
+		CreatedBy:       claims.UserID,
+	}
+	if shiftID != "" {
+		deposit.ShiftID = &shiftID
+	}
+	if err := h.depositService.CreateDeposit((*c).Request().Context(), deposit); err != nil {
+		return respondDepositError(c, err, "Gagal menyimpan deposit")
+	}
+	return CreatedResponse(c, "Deposit berhasil diterima", deposit)
+}
+
+// ListDeposits - filter customer_id, order_id, reservation_id dan status (held/applied/refunded)
+func (h *DepositHandler) ListDeposits(c *echo.Context) error {
+	filter := repositories.DepositFilter{
+		CustomerID:    c.QueryParam("customer_id"),
+		OrderID:       c.QueryParam("order_id"),
+		ReservationID: c.QueryParam("reservation_id"),
+		Status:        c.QueryParam("status"),
+	}
+	deposits, err := h.depositService.ListDeposits((*c).Request().Context(), filter)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil deposit: "+err.Error())
+	}
+	return SuccessResponse(c, "Deposit berhasil diambil", deposits)
+}
+
+// ListCustomerDeposits - deposit milik satu pelanggan
+func (h *DepositHandler) ListCustomerDeposits(c *echo.Context) error {
+	filter := repositories.DepositFilter{
+		CustomerID: c.Param("id"),
+		Status:     c.QueryParam("status"),
+	}
+	deposits, err := h.depositService.ListDeposits((*c).Request().Context(), filter)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil deposit: "+err.Error())
+	}
+	return SuccessResponse(c, "Deposit berhasil diambil", deposits)
+}
+
+// GetDeposit - detail deposit beserta jejak audit
+func (h *DepositHandler) GetDeposit(c *echo.Context) error {
+	deposit, err := h.depositService.GetDeposit((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondDepositError(c, err, "Gagal mengambil deposit")
+	}
+	return SuccessResponse(c, "Deposit berhasil diambil", deposit)
+}
+
+// AttachDeposit - tautkan deposit pelanggan ke order yang akan dilunasi
+func (h *DepositHandler) AttachDeposit(c *echo.Context) error {
+	var req AttachDepositRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if strings.TrimSpace(req.OrderID) == "" {
+		return BadRequestResponse(c, "order_id wajib diisi")
+	}
+
+	ctx := (*c).Request().Context()
+	if err := h.depositService.AttachDeposit(ctx, c.Param("id"), req.OrderID); err != nil {
+		return respondDepositError(c, err, "Gagal menautkan deposit")
+	}
+	deposit, err := h.depositService.GetDeposit(ctx, c.Param("id"))
+	if err != nil {
+		return respondDepositError(c, err, "Gagal mengambil deposit")
+	}
+	return SuccessResponse(c, "Deposit berhasil ditautkan ke order", deposit)
+}
+
+// RefundDeposit - kembalikan sisa saldo deposit, wajib PIN manager dan alasan
+func (h *DepositHandler) RefundDeposit(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req RefundDepositRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return BadRequestResponse(c, "Alasan refund deposit wajib diisi")
+	}
+	if len(req.ManagerPIN) != 4 {
+		return BadRequestResponse(c, "PIN harus tepat 4 digit")
+	}
+	for _, char := range req.ManagerPIN {
+		if char < '0' || char > '9' {
+			return BadRequestResponse(c, "PIN harus berupa angka")
+		}
+	}
+
+	ctx := (*c).Request().Context()
+	manager, err := verifyManagerPIN(ctx, h.queries, req.ManagerPIN)
+	if errors.Is(err, errInvalidManagerPIN) {
+		return UnauthorizedResponse(c, err.Error())
+	}
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil data manager")
+	}
+	managerID := manager.ID
+
+	deposit, err := h.depositService.GetDeposit(ctx, c.Param("id"))
+	if err != nil {
+		return respondDepositError(c, err, "Gagal mengambil deposit")
+	}
+	methodCode := req.PaymentMethod
+	if methodCode == "" {
+		methodCode = deposit.PaymentMethod
+	}
+	method, err := h.resolveDepositMethod(c, methodCode)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+			return BadRequestResponse(c, "Metode refund deposit tidak valid")
+		}
+		return InternalErrorResponse(c, "Gagal memeriksa metode pembayaran")
+	}
+	shiftID, err := h.depositShiftID(c, method)
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+
+	if err := h.depositService.RefundDeposit(ctx, repositories.DepositRefundInput{
+		DepositID:     deposit.ID,
+		PaymentMethod: method.Code,
+		Reason:        req.Reason,
+		ApprovedBy:    managerID,
+		CreatedBy:     claims.UserID,
+		ShiftID:       shiftID,
+	}); err != nil {
+		return respondDepositError(c, err, "Gagal refund deposit")
+	}
+
+	deposit, err = h.depositService.GetDeposit(ctx, deposit.ID)
+	if err != nil {
+		return respondDepositError(c, err, "Gagal mengambil deposit")
+	}
+	return SuccessResponse(c, "Deposit berhasil direfund", deposit)
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+
+	"github.com/labstack/echo/v5"
+)
+
+// FloorPlanHandler mengatur denah lantai: area, letak meja untuk editor, section pelayan
+// dan gabungan meja. Aturan service charge per area dipakai saat total order dihitung.
+type FloorPlanHandler struct {
+	floorPlanService services.FloorPlanService
+	realtime         RealtimeBroadcaster
+}
+
+func NewFloorPlanHandler(floorPlanService services.FloorPlanService, realtime RealtimeBroadcaster) *FloorPlanHandler {
+	return &FloorPlanHandler{
+		floorPlanService: floorPlanService,
+		realtime:         realtime,
+	}
+}
+
+type SaveLayoutRequest struct {
+	Tables []repositories.TableLayoutInput `json:"tables"`
+}
+
+type AreaChargeRulesRequest struct {
+	Rules []repositories.AreaChargeRule `json:"rules"`
+}
+
+type SectionWaitersRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
+func respondFloorPlanError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrFloorAreaNotFound), errors.Is(err, repositories.ErrFloorSectionNotFound),
+		errors.Is(err, repositories.ErrTableCombinationNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrFloorPlanDuplicate):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidFloorPlan):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// emitFloorPlan mengabarkan perubahan denah agar tampilan meja di perangkat lain ikut diperbarui
+func (h *FloorPlanHandler) emitFloorPlan(kind, id string) {
+	if h.realtime == nil {
+		return
+	}
+	h.realtime.Emit("floor_plan_updated", map[string]interface{}{
+		"type": kind,
+		"id":   id,
+	})
+}
+
+// GetFloorPlan - denah lengkap: meja per area, section pelayan dan gabungan meja
+func (h *FloorPlanHandler) GetFloorPlan(c *echo.Context) error {
+	plan, err := h.floorPlanService.GetFloorPlan((*c).Request().Context())
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil denah")
+	}
+	return SuccessResponse(c, "Denah berhasil diambil", plan)
+}
+
+// SaveLayout - simpan posisi, bentuk, ukuran, area dan section meja dari editor denah
+func (h *FloorPlanHandler) SaveLayout(c *echo.Context) error {
+	var req SaveLayoutRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	plan, err := h.floorPlanService.SaveLayout((*c).Request().Context(), req.Tables)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal menyimpan denah")
+	}
+	h.emitFloorPlan("layout", "")
+	return SuccessResponse(c, "Denah berhasil disimpan", plan)
+}
+
+func (h *FloorPlanHandler) ListAreas(c *echo.Context) error {
+	areas, err := h.floorPlanService.ListAreas((*c).Request().Context())
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil area")
+	}
+	return SuccessResponse(c, "Area berhasil diambil", areas)
+}
+
+func (h *FloorPlanHandler) GetArea(c *echo.Context) error {
+	area, err := h.floorPlanService.GetArea((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil area")
+	}
+	return SuccessResponse(c, "Area berhasil diambil", area)
+}
+
+func (h *FloorPlanHandler) CreateArea(c *echo.Context) error {
+	var req repositories.FloorAreaInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	area, err := h.floorPlanService.CreateArea((*c).Request().Context(), req)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal membuat area")
+	}
+	h.emitFloorPlan("area", area.ID)
+	return CreatedResponse(c, "Area berhasil dibuat", area)
+}
+
+func (h *FloorPlanHandler) UpdateArea(c *echo.Context) error {
+	var req repositories.FloorAreaInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	area, err := h.floorPlanService.UpdateArea((*c).Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengubah area")
+	}
+	h.emitFloorPlan("area", area.ID)
+	return SuccessResponse(c, "Area berhasil diubah", area)
+}
+
+// DeleteArea - hapus area; meja dan section di dalamnya menjadi tanpa area
+func (h *FloorPlanHandler) DeleteArea(c *echo.Context) error {
+	id := c.Param("id")
+	if err := h.floorPlanService.DeleteArea((*c).Request().Context(), id); err != nil {
+		return respondFloorPlanError(c, err, "Gagal menghapus area")
+	}
+	h.emitFloorPlan("area", id)
+	return SuccessResponse(c, "Area berhasil dihapus", nil)
+}
+
+// SetAreaChargeRules - atur service charge per area: bebaskan (is_exempt) atau ganti tarif global.
+// Berlaku untuk order di meja area tersebut saat total order dihitung ulang.
+func (h *FloorPlanHandler) SetAreaChargeRules(c *echo.Context) error {
+	var req AreaChargeRulesRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	area, err := h.floorPlanService.SetAreaChargeRules((*c).Request().Context(), c.Param("id"), req.Rules)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal menyimpan aturan biaya area")
+	}
+	h.emitFloorPlan("area", area.ID)
+	return SuccessResponse(c, "Aturan biaya area berhasil disimpan", area)
+}
+
+func (h *FloorPlanHandler) ListSections(c *echo.Context) error {
+	sections, err := h.floorPlanService.ListSections((*c).Request().Context())
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil section")
+	}
+	return SuccessResponse(c, "Section berhasil diambil", sections)
+}
+
+func (h *FloorPlanHandler) GetSection(c *echo.Context) error {
+	section, err := h.floorPlanService.GetSection((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil section")
+	}
+	return SuccessResponse(c, "Section berhasil diambil", section)
+}
+
+func (h *FloorPlanHandler) CreateSection(c *echo.Context) error {
+	var req repositories.FloorSectionInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	section, err := h.floorPlanService.CreateSection((*c).Request().Context(), req)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal membuat section")
+	}
+	h.emitFloorPlan("section", section.ID)
+	return CreatedResponse(c, "Section berhasil dibuat", section)
+}
+
+// UpdateSection - ubah section; table_ids dikirim = mengganti seluruh meja section
+func (h *FloorPlanHandler) UpdateSection(c *echo.Context) error {
+	var req repositories.FloorSectionInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	section, err := h.floorPlanService.UpdateSection((*c).Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengubah section")
+	}
+	h.emitFloorPlan("section", section.ID)
+	return SuccessResponse(c, "Section berhasil diubah", section)
+}
+
+func (h *FloorPlanHandler) DeleteSection(c *echo.Context) error {
+	id := c.Param("id")
+	if err := h.floorPlanService.DeleteSection((*c).Request().Context(), id); err != nil {
+		return respondFloorPlanError(c, err, "Gagal menghapus section")
+	}
+	h.emitFloorPlan("section", id)
+	return SuccessResponse(c, "Section berhasil dihapus", nil)
+}
+
+// SetSectionWaiters - tugaskan pelayan ke section (menggantikan penugasan sebelumnya)
+func (h *FloorPlanHandler) SetSectionWaiters(c *echo.Context) error {
+	var req SectionWaitersRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	section, err := h.floorPlanService.SetSectionWaiters((*c).Request().Context(), c.Param("id"), req.UserIDs)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal menyimpan pelayan section")
+	}
+	h.emitFloorPlan("section", section.ID)
+	return SuccessResponse(c, "Pelayan section berhasil disimpan", section)
+}
+
+// ListMyTables - meja di section yang ditugaskan ke pelayan yang sedang login
+func (h *FloorPlanHandler) ListMyTables(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	tables, err := h.floorPlanService.ListWaiterTables((*c).Request().Context(), claims.UserID)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil meja section")
+	}
+	return SuccessResponse(c, "Meja section berhasil diambil", tables)
+}
+
+func (h *FloorPlanHandler) ListCombinations(c *echo.Context) error {
+	combinations, err := h.floorPlanService.ListCombinations((*c).Request().Context())
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengambil gabungan meja")
+	}
+	return SuccessResponse(c, "Gabungan meja berhasil diambil", combinations)
+}
+
+// CreateCombination - daftarkan set meja yang bisa disatukan; dipakai lewat /orders/merge combination_id
+func (h *FloorPlanHandler) CreateCombination(c *echo.Context) error {
+	var req repositories.TableCombinationInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	combination, err := h.floorPlanService.CreateCombination((*c).Request().Context(), req)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal membuat gabungan meja")
+	}
+	h.emitFloorPlan("combination", combination.ID)
+	return CreatedResponse(c, "Gabungan meja berhasil dibuat", combination)
+}
+
+func (h *FloorPlanHandler) UpdateCombination(c *echo.Context) error {
+	var req repositories.TableCombinationInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	combination, err := h.floorPlanService.UpdateCombination((*c).Request().Context(), c.Param("id"), req)
+	if err != nil {
+		return respondFloorPlanError(c, err, "Gagal mengubah gabungan meja")
+	}
+	h.emitFloorPlan("combination", combination.ID)
+	return SuccessResponse(c, "Gabungan meja berhasil diubah", combination)
+}
+
+func (h *FloorPlanHandler) DeleteCombination(c *echo.Context) error {
+	id := c.Param("id")
+	if err := h.floorPlanService.DeleteCombination((*c).Request().Context(), id); err != nil {
+		return respondFloorPlanError(c, err, "Gagal menghapus gabungan meja")
+	}
+	h.emitFloorPlan("combination", id)
+	return SuccessResponse(c, "Gabungan meja berhasil dihapus", nil)
+}
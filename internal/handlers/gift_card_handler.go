@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"database/sql"
+	"errors"
+
+	"github.com/labstack/echo/v5"
+)
+
+// GiftCardHandler menangani penjualan, top up, cek saldo dan laporan kewajiban
+// gift card. Penukaran dilakukan sebagai metode pembayaran gift_card saat bayar order.
+type GiftCardHandler struct {
+	giftCardService services.GiftCardService
+	db              *sql.DB
+}
+
+func NewGiftCardHandler(giftCardService services.GiftCardService, sqlDB *sql.DB) *GiftCardHandler {
+	return &GiftCardHandler{
+		giftCardService: giftCardService,
+		db:              sqlDB,
+	}
+}
+
+type GiftCardLoadRequest struct {
+	Code          string  `json:"code"`
+	CustomerID    string  `json:"customer_id"`
+	Amount        float64 `json:"amount"`
+	PaymentMethod string  `json:"payment_method"`
+	Note          string  `json:"note"`
+}
+
+type GiftCardStatusRequest struct {
+	Status string `json:"status"`
+}
+
+func respondGiftCardError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrGiftCardNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrGiftCardCodeExists):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidGiftCard), errors.Is(err, repositories.ErrGiftCardDisabled),
+		errors.Is(err, repositories.ErrGiftCardInsufficient):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// loadInput memvalidasi metode pembayaran penjualan/top up. Seperti deposit, metode
+// yang masuk laci wajib diterima di shift terbuka agar kas laci tetap cocok.
+func (h *GiftCardHandler) loadInput(c *echo.Context, req GiftCardLoadRequest, userID string) (repositories.GiftCardLoadInput, error) {
+	input := repositories.GiftCardLoadInput{
+		Code:          req.Code,
+		CustomerID:    req.CustomerID,
+		Amount:        req.Amount,
+		PaymentMethod: req.PaymentMethod,
+		Note:          req.Note,
+		CreatedBy:     userID,
+	}
+	if req.PaymentMethod == repositories.GiftCardPaymentMethod || req.PaymentMethod == repositories.DepositPaymentMethod {
+		return input, repositories.ErrPaymentMethodNotFound
+	}
+	method, err := repositories.GetActivePaymentMethod((*c).Request().Context(), h.db, req.PaymentMethod)
+	if err != nil {
+		return input, err
+	}
+	if !method.CountsToDrawer {
+		input.ShiftID = optionalTerminalShiftID(c, h.db)
+		return input, nil
+	}
+	_, input.ShiftID, err = resolveTerminalShift(c, h.db)
+	return input, err
+}
+
+func (h *GiftCardHandler) respondLoadError(c *echo.Context, err error) error {
+	if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+		return BadRequestResponse(c, "Metode pembayaran gift card tidak valid")
+	}
+	return respondShiftError(c, err)
+}
+
+// IssueGiftCard - jual gift card baru; kode dibuat otomatis jika kosong
+func (h *GiftCardHandler) IssueGiftCard(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req GiftCardLoadRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	input, err := h.loadInput(c, req, claims.UserID)
+	if err != nil {
+		return h.respondLoadError(c, err)
+	}
+
+	card, err := h.giftCardService.IssueGiftCard((*c).Request().Context(), input)
+	if err != nil {
+		return respondGiftCardError(c, err, "Gagal menerbitkan gift card")
+	}
+	return CreatedResponse(c, "Gift card berhasil diterbitkan", card)
+}
+
+// TopUpGiftCard - tambah saldo gift card/dompet pelanggan
+func (h *GiftCardHandler) TopUpGiftCard(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req GiftCardLoadRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	input, err := h.loadInput(c, req, claims.UserID)
+	if err != nil {
+		return h.respondLoadError(c, err)
+	}
+	input.GiftCardID = c.Param("id")
+
+	card, err := h.giftCardService.TopUpGiftCard((*c).Request().Context(), input)
+	if err != nil {
+		return respondGiftCardError(c, err, "Gagal top up gift card")
+	}
+	return SuccessResponse(c, "Top up gift card berhasil", card)
+}
+
+// ListGiftCards - filter customer_id, status dan search (kode/nama/telepon)
+func (h *GiftCardHandler) ListGiftCards(c *echo.Context) error {
+	cards, err := h.giftCardService.ListGiftCards((*c).Request().Context(), repositories.GiftCardFilter{
+		CustomerID: c.QueryParam("customer_id"),
+		Status:     c.QueryParam("status"),
+		Search:     c.QueryParam("search"),
+	})
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil gift card: "+err.Error())
+	}
+	return SuccessResponse(c, "Gift card berhasil diambil", cards)
+}
+
+// GetGiftCard - detail gift card beserta riwayat saldo
+func (h *GiftCardHandler) GetGiftCard(c *echo.Context) error {
+	card, err := h.giftCardService.GetGiftCard((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondGiftCardError(c, err, "Gagal mengambil gift card")
+	}
+	return SuccessResponse(c, "Gift card berhasil diambil", card)
+}
+
+// GetGiftCardByCode - cek saldo kartu sebelum dipakai bayar
+func (h *GiftCardHandler) GetGiftCardByCode(c *echo.Context) error {
+	card, err := h.giftCardService.GetGiftCardByCode((*c).Request().Context(), c.Param("code"))
+	if err != nil {
+		return respondGiftCardError(c, err, "Gagal mengambil gift card")
+	}
+	return SuccessResponse(c, "Gift card berhasil diambil", card)
+}
+
+// GetCustomerGiftCardLedger - riwayat saldo semua gift card/dompet milik pelanggan
+func (h *GiftCardHandler) GetCustomerGiftCardLedger(c *echo.Context) error {
+	entries, err := h.giftCardService.ListLedger((*c).Request().Context(), repositories.GiftCardLedgerFilter{
+		CustomerID: c.Param("id"),
+	})
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil riwayat gift card: "+err.Error())
+	}
+	return SuccessResponse(c, "Riwayat gift card berhasil diambil", entries)
+}
+
+// UpdateGiftCardStatus - blokir kartu hilang (disabled) atau aktifkan kembali
+func (h *GiftCardHandler) UpdateGiftCardStatus(c *echo.Context) error {
+	var req GiftCardStatusRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	ctx := (*c).Request().Context()
+	if err := h.giftCardService.SetGiftCardStatus(ctx, c.Param("id"), req.Status); err != nil {
+		return respondGiftCardError(c, err, "Gagal mengubah status gift card")
+	}
+	card, err := h.giftCardService.GetGiftCard(ctx, c.Param("id"))
+	if err != nil {
+		return respondGiftCardError(c, err, "Gagal mengambil gift card")
+	}
+	return SuccessResponse(c, "Status gift card berhasil diubah", card)
+}
+
+// GetGiftCardLiability - saldo gift card yang belum ditukar (kewajiban)
+func (h *GiftCardHandler) GetGiftCardLiability(c *echo.Context) error {
+	liability, err := h.giftCardService.GetLiability((*c).Request().Context())
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal menghitung kewajiban gift card: "+err.Error())
+	}
+	return SuccessResponse(c, "Kewajiban gift card berhasil diambil", liability)
+}
@@ -0,0 +1,255 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v5"
+	"github.com/skip2/go-qrcode"
+)
+
+// GuestOrderHandler melayani pesan mandiri tamu lewat QR meja. Endpoint tamu publik
+// (tanpa login) dan dibatasi token QR bertanda tangan per meja.
+type GuestOrderHandler struct {
+	guestOrderService services.GuestOrderService
+	realtime          RealtimeBroadcaster
+	baseURL           string
+}
+
+func NewGuestOrderHandler(guestOrderService services.GuestOrderService, realtime RealtimeBroadcaster, baseURL string) *GuestOrderHandler {
+	return &GuestOrderHandler{
+		guestOrderService: guestOrderService,
+		realtime:          realtime,
+		baseURL:           strings.TrimRight(baseURL, "/"),
+	}
+}
+
+type GuestOrderRequestBody struct {
+	CustomerName string                             `json:"customer_name"`
+	Pax          int64                              `json:"pax"`
+	Note         string                             `json:"note"`
+	Items        []repositories.GuestOrderItemInput `json:"items"`
+}
+
+type RejectGuestOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+func respondGuestOrderError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrInvalidGuestToken):
+		return UnauthorizedResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrGuestOrderNotFound), errors.Is(err, repositories.ErrGuestTableNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrGuestOrderNotPending):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrGuestOrderLimit):
+		return ErrorResponse(c, http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, repositories.ErrInvalidGuestOrder), errors.Is(err, repositories.ErrInvalidCourse):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// toGuestOrderResponse hanya menampilkan data yang perlu dilihat tamu
+func toGuestOrderResponse(request *repositories.GuestOrderRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"id":            request.ID,
+		"table_number":  request.TableNumber,
+		"status":        request.Status,
+		"reject_reason": request.RejectReason,
+		"items":         request.Items,
+		"total":         request.Total,
+		"created_at":    request.CreatedAt,
+	}
+}
+
+// emitGuestOrder mengabarkan pesanan tamu; jika sudah diteruskan, order meja ikut dikabarkan
+func (h *GuestOrderHandler) emitGuestOrder(event string, request *repositories.GuestOrderRequest, orderCreated bool) {
+	if h.realtime == nil {
+		return
+	}
+	h.realtime.Emit(event, map[string]interface{}{
+		"request_id":   request.ID,
+		"table_number": request.TableNumber,
+		"status":       request.Status,
+	})
+	if request.OrderID == nil {
+		return
+	}
+	if orderCreated {
+		h.realtime.Emit("order_created", map[string]interface{}{
+			"order_id":     *request.OrderID,
+			"table_number": request.TableNumber,
+			"order_type":   repositories.OrderTypeDineIn,
+		})
+		h.realtime.Emit("table_status_updated", map[string]interface{}{
+			"table_numbers": []string{request.TableNumber},
+		})
+		return
+	}
+	h.realtime.Emit("order_items_updated", map[string]interface{}{
+		"order_id": *request.OrderID,
+	})
+}
+
+// GenerateTableQRCode - QR pesan mandiri untuk dicetak dan ditempel di meja.
+// ?rotate=true membatalkan QR lama meja; ?format=png mengembalikan gambar langsung.
+func (h *GuestOrderHandler) GenerateTableQRCode(c *echo.Context) error {
+	rotate, _ := strconv.ParseBool(c.QueryParam("rotate"))
+	table, token, err := h.guestOrderService.TableToken((*c).Request().Context(), c.Param("id"), rotate)
+	if err != nil {
+		return respondGuestOrderError(c, err, "Gagal membuat token meja")
+	}
+
+	size := 256
+	if raw := c.QueryParam("size"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 128 || parsed > 1024 {
+			return BadRequestResponse(c, "size harus 128 sampai 1024")
+		}
+		size = parsed
+	}
+
+	baseURL := h.baseURL
+	if baseURL == "" {
+		baseURL = "http://" + (*c).Request().Host
+	}
+	orderURL := baseURL + "/guest/" + token
+
+	qrCode, err := qrcode.Encode(orderURL, qrcode.Medium, size)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal membuat QR code")
+	}
+	if c.QueryParam("format") == "png" {
+		return (*c).Blob(http.StatusOK, "image/png", qrCode)
+	}
+
+	return SuccessResponse(c, "QR meja berhasil dibuat", map[string]interface{}{
+		"table_id":     table.ID,
+		"table_number": table.TableNumber,
+		"token":        token,
+		"order_url":    orderURL,
+		"qr_code":      base64.StdEncoding.EncodeToString(qrCode),
+		"rotated":      rotate,
+	})
+}
+
+// GetGuestMenu - menu untuk tamu yang memindai QR meja (publik)
+func (h *GuestOrderHandler) GetGuestMenu(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+	table, err := h.guestOrderService.ResolveTable(ctx, c.Param("token"))
+	if err != nil {
+		return respondGuestOrderError(c, err, "Gagal membaca QR meja")
+	}
+	menu, err := h.guestOrderService.GetMenu(ctx)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil menu: "+err.Error())
+	}
+	return SuccessResponse(c, "Menu berhasil diambil", map[string]interface{}{
+		"table_number":      table.TableNumber,
+		"requires_approval": h.guestOrderService.RequiresApproval(),
+		"categories":        menu,
+	})
+}
+
+// SubmitGuestOrder - tamu mengirim pesanan dari QR meja (publik)
+func (h *GuestOrderHandler) SubmitGuestOrder(c *echo.Context) error {
+	var req GuestOrderRequestBody
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if len(req.Items) == 0 {
+		return BadRequestResponse(c, "items tidak boleh kosong")
+	}
+
+	request, created, err := h.guestOrderService.SubmitOrder((*c).Request().Context(), c.Param("token"), repositories.GuestOrderInput{
+		CustomerName: req.CustomerName,
+		Pax:          req.Pax,
+		Note:         req.Note,
+		ClientIP:     c.RealIP(),
+		Items:        req.Items,
+	})
+	if err != nil {
+		return respondGuestOrderError(c, err, "Gagal mengirim pesanan")
+	}
+
+	h.emitGuestOrder("guest_order_requested", request, created)
+	message := "Pesanan dikirim ke dapur"
+	if request.Status == repositories.GuestOrderStatusPending {
+		message = "Pesanan diterima, menunggu konfirmasi pelayan"
+	}
+	return CreatedResponse(c, message, toGuestOrderResponse(request))
+}
+
+// GetGuestOrderStatus - tamu memantau status pesanannya (publik)
+func (h *GuestOrderHandler) GetGuestOrderStatus(c *echo.Context) error {
+	request, err := h.guestOrderService.GetGuestOrder((*c).Request().Context(), c.Param("token"), c.Param("id"))
+	if err != nil {
+		return respondGuestOrderError(c, err, "Gagal mengambil pesanan")
+	}
+	return SuccessResponse(c, "Pesanan berhasil diambil", toGuestOrderResponse(request))
+}
+
+// ListGuestOrders - daftar pesanan tamu; ?status=pending untuk antrian persetujuan waiter
+func (h *GuestOrderHandler) ListGuestOrders(c *echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "", repositories.GuestOrderStatusPending, repositories.GuestOrderStatusApproved, repositories.GuestOrderStatusRejected:
+	default:
+		return BadRequestResponse(c, "status harus pending, approved atau rejected")
+	}
+
+	requests, err := h.guestOrderService.ListGuestOrders((*c).Request().Context(), repositories.GuestOrderFilter{
+		Status:      status,
+		TableNumber: c.QueryParam("table_number"),
+	})
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil pesanan tamu: "+err.Error())
+	}
+	return SuccessResponse(c, "Pesanan tamu berhasil diambil", requests)
+}
+
+// ApproveGuestOrder - waiter menyetujui pesanan tamu; item masuk ke order meja dan dicetak
+func (h *GuestOrderHandler) ApproveGuestOrder(c *echo.Context) error {
+	approvedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		approvedBy = claims.UserID
+	}
+
+	request, created, err := h.guestOrderService.ApproveGuestOrder((*c).Request().Context(), c.Param("id"), approvedBy)
+	if err != nil {
+		return respondGuestOrderError(c, err, "Gagal menyetujui pesanan tamu")
+	}
+
+	h.emitGuestOrder("guest_order_updated", request, created)
+	return SuccessResponse(c, "Pesanan tamu disetujui dan dikirim ke dapur", request)
+}
+
+// RejectGuestOrder - waiter menolak pesanan tamu
+func (h *GuestOrderHandler) RejectGuestOrder(c *echo.Context) error {
+	var req RejectGuestOrderRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	rejectedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		rejectedBy = claims.UserID
+	}
+
+	request, err := h.guestOrderService.RejectGuestOrder((*c).Request().Context(), c.Param("id"), rejectedBy, req.Reason)
+	if err != nil {
+		return respondGuestOrderError(c, err, "Gagal menolak pesanan tamu")
+	}
+
+	h.emitGuestOrder("guest_order_updated", request, false)
+	return SuccessResponse(c, "Pesanan tamu ditolak", request)
+}
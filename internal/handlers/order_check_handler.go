@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/internal/workers"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/labstack/echo/v5"
+)
+
+// OrderCheckHandler mengatur split check order: bagi per kursi, geser item antar check,
+// bagi rata dan per nominal. Pembayaran dan cetak bill per check ada di OrderHandler.
+type OrderCheckHandler struct {
+	orderCheckService services.OrderCheckService
+}
+
+func NewOrderCheckHandler(orderCheckService services.OrderCheckService) *OrderCheckHandler {
+	return &OrderCheckHandler{
+		orderCheckService: orderCheckService,
+	}
+}
+
+type SplitEvenRequest struct {
+	Count int `json:"count"`
+}
+
+type SplitByAmountsRequest struct {
+	Amounts []float64 `json:"amounts"`
+}
+
+type ItemSeatRequest struct {
+	SeatNumber *int64 `json:"seat_number"`
+}
+
+func respondOrderCheckError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrOrderCheckNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrOrderCheckPaid):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidOrderCheck), errors.Is(err, repositories.ErrOrderChecksIncomplete):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// ListChecks - check order beserta nominal tiap check dan item yang belum masuk check
+func (h *OrderCheckHandler) ListChecks(c *echo.Context) error {
+	checks, err := h.orderCheckService.ListChecks((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal mengambil split check")
+	}
+	return SuccessResponse(c, "Split check berhasil diambil", checks)
+}
+
+// CreateCheck - tambah check per item (opsional langsung berisi item)
+func (h *OrderCheckHandler) CreateCheck(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req repositories.OrderCheckInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	checks, err := h.orderCheckService.CreateCheck((*c).Request().Context(), c.Param("id"), req, claims.UserID)
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal membuat check")
+	}
+	return CreatedResponse(c, "Check berhasil dibuat", checks)
+}
+
+// SplitBySeat - satu check per nomor kursi item
+func (h *OrderCheckHandler) SplitBySeat(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	checks, err := h.orderCheckService.SplitBySeat((*c).Request().Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal split per kursi")
+	}
+	return SuccessResponse(c, "Order berhasil di-split per kursi", checks)
+}
+
+// SplitEven - bagi rata sisa tagihan ke N check
+func (h *OrderCheckHandler) SplitEven(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req SplitEvenRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	checks, err := h.orderCheckService.SplitEven((*c).Request().Context(), c.Param("id"), req.Count, claims.UserID)
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal bagi rata tagihan")
+	}
+	return SuccessResponse(c, "Tagihan berhasil dibagi rata", checks)
+}
+
+// SplitByAmounts - check per nominal, sisa tagihan menjadi check terakhir
+func (h *OrderCheckHandler) SplitByAmounts(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req SplitByAmountsRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	checks, err := h.orderCheckService.SplitByAmounts((*c).Request().Context(), c.Param("id"), req.Amounts, claims.UserID)
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal split per nominal")
+	}
+	return SuccessResponse(c, "Tagihan berhasil di-split per nominal", checks)
+}
+
+// MoveCheckItem - geser qty item antar check (check kosong = belum masuk check)
+func (h *OrderCheckHandler) MoveCheckItem(c *echo.Context) error {
+	var req repositories.MoveCheckItemInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	req.OrderID = c.Param("id")
+
+	checks, err := h.orderCheckService.MoveItem((*c).Request().Context(), req)
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal memindahkan item")
+	}
+	return SuccessResponse(c, "Item berhasil dipindahkan", checks)
+}
+
+// DeleteCheck - hapus check yang belum dibayar
+func (h *OrderCheckHandler) DeleteCheck(c *echo.Context) error {
+	checks, err := h.orderCheckService.DeleteCheck((*c).Request().Context(), c.Param("id"), c.Param("check_id"))
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal menghapus check")
+	}
+	return SuccessResponse(c, "Check berhasil dihapus", checks)
+}
+
+// ClearChecks - batalkan split, check yang sudah dibayar tetap tersimpan
+func (h *OrderCheckHandler) ClearChecks(c *echo.Context) error {
+	checks, err := h.orderCheckService.ClearChecks((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal membatalkan split check")
+	}
+	return SuccessResponse(c, "Split check berhasil dibatalkan", checks)
+}
+
+// UpdateItemSeat - atur nomor kursi item (null = item bersama)
+func (h *OrderCheckHandler) UpdateItemSeat(c *echo.Context) error {
+	var req ItemSeatRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if err := h.orderCheckService.SetItemSeat((*c).Request().Context(), c.Param("id"), req.SeatNumber); err != nil {
+		return respondOrderCheckError(c, err, "Gagal mengatur nomor kursi")
+	}
+	return SuccessResponse(c, "Nomor kursi item berhasil diatur", map[string]interface{}{
+		"item_id":     c.Param("id"),
+		"seat_number": req.SeatNumber,
+	})
+}
+
+// ensureNoOpenChecks menolak pembayaran order utuh/split biasa saat order sudah di-split per check
+func (h *OrderHandler) ensureNoOpenChecks(ctx context.Context, orderID string) error {
+	hasChecks, err := repositories.HasOpenOrderChecks(ctx, h.db, orderID)
+	if err != nil {
+		return err
+	}
+	if hasChecks {
+		return repositories.ErrOrderHasChecks
+	}
+	return nil
+}
+
+// loadOrderCheck mengambil satu check beserta status kelengkapan split order
+func (h *OrderHandler) loadOrderCheck(ctx context.Context, orderID, checkID string) (*repositories.OrderChecks, *repositories.OrderCheck, error) {
+	checks, err := repositories.LoadOrderChecks(ctx, h.db, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range checks.Checks {
+		if checks.Checks[i].ID == checkID {
+			return checks, &checks.Checks[i], nil
+		}
+	}
+	return nil, nil, repositories.ErrOrderCheckNotFound
+}
+
+// buildCheckReceiptItems menyusun baris struk dari isi check per item (kosong untuk check nominal)
+func buildCheckReceiptItems(items []repositories.OrderCheckItem) ([]workers.ReceiptItem, int) {
+	receiptItems := make([]workers.ReceiptItem, 0, len(items))
+	subtotal := 0
+	for _, item := range items {
+		price := int(math.Round(item.Price))
+		total := price * int(item.Qty)
+		receiptItems = append(receiptItems, workers.ReceiptItem{
+			Name:     item.ProductName,
+			Quantity: int(item.Qty),
+			Price:    price,
+			Total:    total,
+		})
+		subtotal += total
+	}
+	return receiptItems, subtotal
+}
+
+func checkReceiptNumber(orderID string, check *repositories.OrderCheck) string {
+	return fmt.Sprintf("TRX-%s-C%d", orderID, check.CheckNumber)
+}
+
+// HandlePayCheck - bayar satu split check; order lunas saat semua check dibayar
+func (h *OrderHandler) HandlePayCheck(c *echo.Context) error {
+	orderID := c.Param("id")
+
+	var req splitPaymentRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	checks, check, err := h.loadOrderCheck((*c).Request().Context(), orderID, c.Param("check_id"))
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal mengambil split check")
+	}
+	if check.Status != repositories.OrderCheckStatusOpen {
+		return ConflictResponse(c, repositories.ErrOrderCheckPaid.Error())
+	}
+	if !checks.Complete {
+		return BadRequestResponse(c, repositories.ErrOrderChecksIncomplete.Error())
+	}
+
+	// Nominal dan isi pembayaran mengikuti check, bukan body request
+	req.Amount = 0
+	req.Items = nil
+	return h.processSplitPayment(c, orderID, req, check)
+}
+
+// HandlePrintCheck - cetak bill satu split check
+func (h *OrderHandler) HandlePrintCheck(c *echo.Context) error {
+	orderID := c.Param("id")
+	ctx := (*c).Request().Context()
+
+	_, check, err := h.loadOrderCheck(ctx, orderID, c.Param("check_id"))
+	if err != nil {
+		return respondOrderCheckError(c, err, "Gagal mengambil split check")
+	}
+	order, items, err := h.service.GetOrderDetails(ctx, orderID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil detail order: "+err.Error())
+	}
+
+	receiptItems, subtotal := buildCheckReceiptItems(check.Items)
+	taxRatio := 1.0
+	if len(receiptItems) == 0 {
+		receiptItems, subtotal = buildReceiptItems(items)
+	} else {
+		orderSubtotal := 0.0
+		for _, item := range items {
+			orderSubtotal += item.Price * float64(item.Qty)
+		}
+		if orderSubtotal > 0 {
+			taxRatio = float64(subtotal) / orderSubtotal
+		}
+	}
+	total := check.Due
+	if check.Status == repositories.OrderCheckStatusPaid {
+		total = check.PaidAmount
+	}
+
+	data := h.splitReceiptData(ctx, order, receiptItems, subtotal, int(math.Round(total)), taxRatio)
+	data.ReceiptNumber = checkReceiptNumber(order.ID, check)
+	data.IsBill = true
+	if !h.enqueueReceiptJob(ctx, data) {
+		return NotFoundResponse(c, "Printer kasir tidak ditemukan")
+	}
+	markBillRequested(ctx, h.db, h.realtime, orderID)
+	return SuccessResponse(c, "Bill check berhasil dikirim ke printer", map[string]interface{}{
+		"order_id": orderID,
+		"check_id": check.ID,
+		"total":    total,
+	})
+}
@@ -11,11 +11,13 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type OrderHandler struct {
@@ -36,6 +38,18 @@ type splitBillItem struct {
 	Qty    int64  `json:"qty"`
 }
 
+type splitPaymentRequest struct {
+	Amount          float64         `json:"amount"`
+	PaidAmount      float64         `json:"paid_amount"`
+	PaymentMethod   string          `json:"payment_method"`
+	ReferenceNumber string          `json:"reference_number"`
+	TipAmount       float64         `json:"tip_amount"`
+	TipRecipient    string          `json:"tip_recipient"`
+	GiftCardCode    string          `json:"gift_card_code"`
+	Note            string          `json:"note,omitempty"`
+	Items           []splitBillItem `json:"items,omitempty"`
+}
+
 func NewOrderHandler(service services.OrderService, transactionService services.TransactionService, customerService services.CustomerService, queries *db.Queries, dbConn *sql.DB, realtime RealtimeBroadcaster) *OrderHandler {
 	return &OrderHandler{
 		service:            service,
@@ -54,25 +68,25 @@ func (h *OrderHandler) emitEvent(event string, payload map[string]interface{}) {
 	h.realtime.Emit(event, payload)
 }
 
-func (h *OrderHandler) ensureOpenCashierShift(ctx context.Context) error {
-	row := h.db.QueryRowContext(ctx, `
-		SELECT id
-		FROM cashier_shifts
-		WHERE status = 'open'
-		ORDER BY opened_at DESC
-		LIMIT 1
-	`)
-	var shiftID string
-	return row.Scan(&shiftID)
+// getOpenCashierShiftID mengembalikan shift yang terbuka di terminal kasir request.
+// Pembayaran dan refund dicatat ke shift ini sehingga ringkasan dihitung per laci.
+func (h *OrderHandler) getOpenCashierShiftID(c *echo.Context) (string, error) {
+	_, shiftID, err := resolveTerminalShift(c, h.db)
+	return shiftID, err
 }
 
 type CreateOrderRequest struct {
 	TableNumber   string                        `json:"table_number"`
 	CustomerName  string                        `json:"customer_name,omitempty"`
 	CustomerPhone string                        `json:"customer_phone,omitempty"`
+	CustomerID    string                        `json:"customer_id,omitempty"`
 	Pax           int64                         `json:"pax"`
 	Items         []repositories.OrderItemInput `json:"items"`
 	PrinterID     string                        `json:"printer_id,omitempty"`
+	// dine_in (default), takeaway, delivery atau pickup
+	OrderType       string `json:"order_type,omitempty"`
+	PickupAt        string `json:"pickup_at,omitempty"`
+	DeliveryAddress string `json:"delivery_address,omitempty"`
 }
 
 type CreateOrderResponse struct {
@@ -132,6 +146,49 @@ func toOrderResponse(order *db.Order, waiterName string, mergedFromTableNumber s
 	}
 }
 
+// withOrderChannel menambahkan tipe order, nomor antrian dan data pengambilan/pengantaran
+// ke response order (kolom di luar query sqlc orders)
+func (h *OrderHandler) withOrderChannel(ctx context.Context, response map[string]interface{}) map[string]interface{} {
+	orderID, _ := response["id"].(string)
+	channel, err := repositories.GetOrderChannel(ctx, h.db, orderID)
+	if err != nil {
+		channel = &repositories.OrderChannel{OrderType: repositories.OrderTypeDineIn}
+	}
+	response["order_type"] = channel.OrderType
+	response["queue_number"] = channel.QueueNumber
+	response["queue_label"] = channel.QueueLabel
+	response["pickup_at"] = channel.PickupAt
+	response["delivery_address"] = channel.DeliveryAddress
+	return response
+}
+
+// OrderItemResponse adalah item order beserta course dan status hold/fire-nya
+type OrderItemResponse struct {
+	db.OrderItem
+	Course  string     `json:"course"`
+	IsHeld  bool       `json:"is_held"`
+	FiredAt *time.Time `json:"fired_at"`
+}
+
+// withItemCourses menambahkan course dan marker hold/fire ke item order (kolom di luar query sqlc)
+func (h *OrderHandler) withItemCourses(ctx context.Context, orderID string, items []db.OrderItem) []OrderItemResponse {
+	courses, err := repositories.LoadOrderItemCourses(ctx, h.db, orderID)
+	if err != nil {
+		log.Printf("Failed to load item courses for %s: %v", orderID, err)
+	}
+	result := make([]OrderItemResponse, 0, len(items))
+	for _, item := range items {
+		course := courses[item.ID]
+		result = append(result, OrderItemResponse{
+			OrderItem: item,
+			Course:    course.Course,
+			IsHeld:    course.IsHeld,
+			FiredAt:   course.FiredAt,
+		})
+	}
+	return result
+}
+
 func remainingAmount(order *db.Order) float64 {
 	remaining := order.TotalAmount - order.PaidAmount
 	if remaining < 0 {
@@ -175,15 +232,25 @@ func (h *OrderHandler) HandleCreateOrder(c *echo.Context) error {
 		return BadRequestResponse(c, "Body request tidak valid")
 	}
 
-	// Validate request
-	if req.TableNumber == "" {
+	// Validate request; meja dan pax hanya wajib untuk dine-in
+	isDineIn := req.OrderType == "" || req.OrderType == repositories.OrderTypeDineIn
+	if isDineIn && req.TableNumber == "" {
 		return BadRequestResponse(c, "table_number wajib diisi")
 	}
 
-	if req.Pax <= 0 {
+	if req.Pax < 0 || (isDineIn && req.Pax == 0) {
 		return BadRequestResponse(c, "pax harus lebih dari 0")
 	}
 
+	var pickupAt *time.Time
+	if req.PickupAt != "" {
+		parsed, err := parsePriceListTime(req.PickupAt)
+		if err != nil {
+			return BadRequestResponse(c, "Format pickup_at tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+		}
+		pickupAt = &parsed
+	}
+
 	if len(req.Items) == 0 {
 		return BadRequestResponse(c, "items tidak boleh kosong")
 	}
@@ -199,8 +266,20 @@ func (h *OrderHandler) HandleCreateOrder(c *echo.Context) error {
 		req.Items[i] = item
 	}
 
+	// customer_id menautkan profil yang sudah ada dan didahulukan dari customer_phone
 	customerID := ""
-	if req.CustomerPhone != "" {
+	if req.CustomerID != "" {
+		customer, err := h.customerService.GetCustomerByID((*c).Request().Context(), req.CustomerID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return NotFoundResponse(c, "Pelanggan tidak ditemukan")
+			}
+			return InternalErrorResponse(c, "Gagal mengambil data pelanggan: "+err.Error())
+		}
+		req.CustomerName = customer.Name
+		req.CustomerPhone = customer.Phone
+		customerID = customer.ID
+	} else if req.CustomerPhone != "" {
 		customer, err := h.customerService.GetCustomerByPhone((*c).Request().Context(), req.CustomerPhone)
 		if err != nil {
 			if err == sql.ErrNoRows {
@@ -230,26 +309,60 @@ func (h *OrderHandler) HandleCreateOrder(c *echo.Context) error {
 
 	// Create order with items atomically
 	orderID, err := h.service.CreateOrder((*c).Request().Context(), repositories.OrderInput{
-		TableNumber:   req.TableNumber,
-		CustomerName:  req.CustomerName,
-		CustomerPhone: req.CustomerPhone,
-		CustomerID:    customerID,
-		Pax:           req.Pax,
-		Items:         req.Items,
-		PrinterID:     req.PrinterID,
-		CreatedBy:     createdBy,
+		TableNumber:     req.TableNumber,
+		CustomerName:    req.CustomerName,
+		CustomerPhone:   req.CustomerPhone,
+		CustomerID:      customerID,
+		Pax:             req.Pax,
+		Items:           req.Items,
+		PrinterID:       req.PrinterID,
+		CreatedBy:       createdBy,
+		OrderType:       req.OrderType,
+		PickupAt:        pickupAt,
+		DeliveryAddress: req.DeliveryAddress,
 	})
 	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidOrderType) || errors.Is(err, repositories.ErrOrderTypeNotFound) ||
+			errors.Is(err, repositories.ErrInvalidCourse) {
+			return BadRequestResponse(c, err.Error())
+		}
 		return InternalErrorResponse(c, "Gagal membuat order: "+err.Error())
 	}
 
+	// Tamu reservasi yang sudah duduk di meja ini otomatis ditautkan ke order barunya
+	if isDineIn {
+		if reservationID, err := repositories.AttachSeatedReservation((*c).Request().Context(), h.db, req.TableNumber, orderID); err != nil {
+			log.Printf("Failed to attach reservation to order %s: %v", orderID, err)
+		} else if reservationID != "" {
+			h.emitEvent("reservation_updated", map[string]interface{}{
+				"reservation_id": reservationID,
+				"status":         repositories.ReservationStatusSeated,
+				"order_id":       orderID,
+			})
+		}
+	}
+
+	channel, err := repositories.GetOrderChannel((*c).Request().Context(), h.db, orderID)
+	if err != nil {
+		channel = &repositories.OrderChannel{OrderType: repositories.OrderTypeDineIn}
+	}
+	tableNumber := req.TableNumber
+	if channel.QueueLabel != "" {
+		tableNumber = channel.QueueLabel
+	}
+
 	// Return success immediately (print jobs are in queue)
 	h.emitEvent("order_created", map[string]interface{}{
 		"order_id":     orderID,
-		"table_number": req.TableNumber,
+		"table_number": tableNumber,
+		"order_type":   channel.OrderType,
+		"queue_label":  channel.QueueLabel,
 	})
-	return SuccessResponse(c, "Order berhasil dibuat, print jobs dalam antrian", map[string]string{
-		"order_id": orderID,
+	return SuccessResponse(c, "Order berhasil dibuat, print jobs dalam antrian", map[string]interface{}{
+		"order_id":     orderID,
+		"order_type":   channel.OrderType,
+		"queue_number": channel.QueueNumber,
+		"queue_label":  channel.QueueLabel,
 	})
 }
 
@@ -279,6 +392,9 @@ func (h *OrderHandler) HandleAddItemsToOrder(c *echo.Context) error {
 		if err == sql.ErrNoRows {
 			return NotFoundResponse(c, "Order tidak ditemukan")
 		}
+		if errors.Is(err, repositories.ErrInvalidCourse) {
+			return BadRequestResponse(c, err.Error())
+		}
 		return InternalErrorResponse(c, "Gagal menambah item order: "+err.Error())
 	}
 
@@ -319,6 +435,9 @@ func (h *OrderHandler) HandleAddItemsToOrderByTable(c *echo.Context) error {
 	}
 
 	if err := h.service.AddItemsToOrder((*c).Request().Context(), order.ID, req.Items); err != nil {
+		if errors.Is(err, repositories.ErrInvalidCourse) {
+			return BadRequestResponse(c, err.Error())
+		}
 		return InternalErrorResponse(c, "Gagal menambah item order: "+err.Error())
 	}
 
@@ -355,6 +474,12 @@ func (h *OrderHandler) HandleUpdateOrderItemStatus(c *echo.Context) error {
 	}
 
 	if err := h.service.UpdateOrderItemStatus((*c).Request().Context(), itemID, req.Status); err != nil {
+		if errors.Is(err, repositories.ErrOrderItemNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		if errors.Is(err, repositories.ErrOrderItemHeld) {
+			return ConflictResponse(c, err.Error())
+		}
 		return InternalErrorResponse(c, "Gagal update status item: "+err.Error())
 	}
 
@@ -407,28 +532,40 @@ func (h *OrderHandler) HandleUpdateOrderItemQty(c *echo.Context) error {
 func (h *OrderHandler) HandleProcessPayment(c *echo.Context) error {
 	orderID := c.Param("id")
 	var req struct {
-		PaymentMethod string  `json:"payment_method"`
-		PaidAmount    float64 `json:"paid_amount"`
+		PaymentMethod   string  `json:"payment_method"`
+		PaidAmount      float64 `json:"paid_amount"`
+		ReferenceNumber string  `json:"reference_number"`
+		TipAmount       float64 `json:"tip_amount"`
+		TipRecipient    string  `json:"tip_recipient"`
+		GiftCardCode    string  `json:"gift_card_code"`
 	}
 
 	if err := (*c).Bind(&req); err != nil {
 		return BadRequestResponse(c, "Body request tidak valid")
 	}
 
-	validMethods := map[string]bool{
-		"cash": true, "card": true, "qris": true, "transfer": true,
-	}
-	if !validMethods[req.PaymentMethod] {
-		return BadRequestResponse(c, "payment_method tidak valid. Valid: cash, card, qris, transfer")
-	}
-
 	ctx := (*c).Request().Context()
+	if err := h.ensureNoOpenChecks(ctx, orderID); err != nil {
+		if errors.Is(err, repositories.ErrOrderHasChecks) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal memeriksa split check")
+	}
 
-	if err := h.ensureOpenCashierShift(ctx); err != nil {
-		if err == sql.ErrNoRows {
-			return BadRequestResponse(c, "Shift kasir belum dibuka")
+	method, err := h.resolvePaymentMethod(ctx, req.PaymentMethod, req.ReferenceNumber)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) || errors.Is(err, repositories.ErrInvalidPaymentMethod) {
+			return BadRequestResponse(c, err.Error())
 		}
-		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+		return InternalErrorResponse(c, "Gagal memeriksa metode pembayaran")
+	}
+	if method.Code == repositories.GiftCardPaymentMethod && strings.TrimSpace(req.GiftCardCode) == "" {
+		return BadRequestResponse(c, "gift_card_code wajib diisi untuk pembayaran gift card")
+	}
+
+	shiftID, err := h.getOpenCashierShiftID(c)
+	if err != nil {
+		return respondShiftError(c, err)
 	}
 
 	claims, err := middleware.GetUserFromContext(c)
@@ -445,77 +582,56 @@ func (h *OrderHandler) HandleProcessPayment(c *echo.Context) error {
 	if remaining <= 0 {
 		return BadRequestResponse(c, "Tagihan sudah lunas")
 	}
-	paidAmount := req.PaidAmount
-	if paidAmount <= 0 {
-		paidAmount = remaining
-	}
-	if paidAmount < remaining {
-		return BadRequestResponse(c, "Jumlah bayar kurang dari total tagihan")
-	}
-	changeAmount := paidAmount - remaining
-
-	// Update payment status
-	if err := h.service.ProcessPayment(ctx, orderID); err != nil {
-		return InternalErrorResponse(c, "Gagal proses pembayaran: "+err.Error())
-	}
 
-	// Update order status to served
-	if err := h.service.UpdateOrderStatus(ctx, orderID, "served"); err != nil {
-		return InternalErrorResponse(c, "Gagal update status order: "+err.Error())
-	}
-
-	updatedTables := map[string]bool{
-		order.TableNumber: true,
-	}
-	if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-		Status:      "available",
-		TableNumber: order.TableNumber,
-	}); err != nil {
-		return InternalErrorResponse(c, "Gagal update status meja: "+err.Error())
+	// Saldo deposit order/pelanggan dipakai lebih dulu sebagai baris pembayaran deposit;
+	// metode yang dipilih kasir hanya menagih sisanya. Deposit baru dipotong di transaksi
+	// pelunasan setelah semua validasi lolos.
+	depositAmount, err := h.previewOrderDeposit(ctx, orderID, remaining)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal memeriksa deposit: "+err.Error())
 	}
+	remaining = math.Max(remaining-depositAmount, 0)
 
-	mergedOrders, err := h.queries.GetMergedOrders(ctx, sql.NullString{
-		String: order.ID,
-		Valid:  true,
-	})
+	tip, err := h.buildPaymentTip(ctx, order, req.TipAmount, req.TipRecipient, claims.UserID, shiftID)
 	if err != nil {
-		return InternalErrorResponse(c, "Gagal mengambil order hasil gabungan: "+err.Error())
-	}
-	for _, mergedOrder := range mergedOrders {
-		if mergedOrder.TableNumber == "" {
-			continue
-		}
-		if updatedTables[mergedOrder.TableNumber] {
-			continue
-		}
-		if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-			Status:      "available",
-			TableNumber: mergedOrder.TableNumber,
-		}); err != nil {
-			return InternalErrorResponse(c, "Gagal update status meja gabungan: "+err.Error())
+		if errors.Is(err, repositories.ErrInvalidTip) {
+			return BadRequestResponse(c, err.Error())
 		}
-		updatedTables[mergedOrder.TableNumber] = true
+		return InternalErrorResponse(c, "Gagal menyiapkan tip: "+err.Error())
 	}
-
-	tableNumbers := make([]string, 0, len(updatedTables))
-	for tableNumber := range updatedTables {
-		tableNumbers = append(tableNumbers, tableNumber)
+	tipAmount := 0.0
+	if tip != nil {
+		tipAmount = tip.Amount
 	}
 
-	_, err = h.transactionService.CreateTransactionForOrder(
-		ctx,
-		orderID,
-		remaining,
-		req.PaymentMethod,
-		claims.UserID,
-	)
-	if err != nil {
-		return InternalErrorResponse(c, "Gagal mencatat transaksi: "+err.Error())
+	// Tip dibayar bersama tagihan; kembalian hanya untuk metode tunai
+	paidAmount := req.PaidAmount
+	if paidAmount <= 0 || method.MethodType != "cash" {
+		paidAmount = remaining + tipAmount
 	}
-
-	paidOrder, paidItems, err := h.service.GetOrderDetails(ctx, orderID)
+	if paidAmount < remaining+tipAmount {
+		return BadRequestResponse(c, "Jumlah bayar kurang dari total tagihan")
+	}
+	changeAmount := paidAmount - remaining - tipAmount
+
+	// Saldo gift card dipotong di transaksi pelunasan yang sama
+	paidOrder, settlement, err := h.completeOrderPayment(ctx, repositories.OrderSettlement{
+		OrderID:         orderID,
+		PaymentMethod:   method.Code,
+		ReferenceNumber: req.ReferenceNumber,
+		Amount:          remaining,
+		DepositAmount:   depositAmount,
+		GiftCardCode:    req.GiftCardCode,
+		Tip:             tip,
+		CashierID:       claims.UserID,
+		ShiftID:         shiftID,
+	}, paidAmount, changeAmount)
 	if err != nil {
-		return InternalErrorResponse(c, "Gagal mengambil detail order setelah pembayaran: "+err.Error())
+		return respondSettlementError(c, err)
+	}
+	// Laci hanya dibuka jika ada uang tunai yang diterima
+	if method.MethodType == "cash" && remaining+tipAmount > 0 {
+		kickCashDrawerForShift(ctx, h.db, h.queries, repositories.DrawerSourcePayment, orderID, shiftID, claims.UserID)
 	}
 
 	payments, err := h.service.GetOrderPayments(ctx, orderID)
@@ -523,15 +639,6 @@ func (h *OrderHandler) HandleProcessPayment(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil riwayat pembayaran: "+err.Error())
 	}
 
-	h.enqueueFullPaymentReceipt(ctx, paidOrder, paidItems, req.PaymentMethod, paidAmount, changeAmount)
-
-	h.emitEvent("payment_completed", map[string]interface{}{
-		"order_id":      orderID,
-		"table_numbers": tableNumbers,
-	})
-	h.emitEvent("table_status_updated", map[string]interface{}{
-		"table_numbers": tableNumbers,
-	})
 	return SuccessResponse(c, "Pembayaran berhasil diproses", map[string]interface{}{
 		"order_id":       orderID,
 		"total_amount":   remainingAmount(paidOrder),
@@ -540,9 +647,135 @@ func (h *OrderHandler) HandleProcessPayment(c *echo.Context) error {
 		"paid_amount":    paidOrder.PaidAmount,
 		"payment_status": paidOrder.PaymentStatus,
 		"payments":       payments,
+		"tip_amount":     tipAmount,
+		"change_amount":  changeAmount,
+		"deposit_amount": settlement.DepositApplied,
 	})
 }
 
+// recordTablesPaid mencatat tahap lunas ke sesi meja. Sesi baru ditutup saat meja
+// selesai dibersihkan (dirty → available).
+func (h *OrderHandler) recordTablesPaid(ctx context.Context, orderID string, tableNumbers []string) {
+	for _, tableNumber := range tableNumbers {
+		if err := repositories.RecordTableSessionEvent(ctx, h.db, tableNumber, repositories.TableSessionPaid, orderID); err != nil {
+			log.Printf("Failed to record paid table session for %s: %v", tableNumber, err)
+		}
+	}
+}
+
+// completeOrderPayment melunasi sisa tagihan order lewat satu transaksi database (deposit,
+// status bayar, meja termasuk meja gabungan, transaksi dan tip), lalu mencatat sesi meja,
+// cetak struk dan broadcast event. Dipakai oleh pembayaran kasir maupun settlement
+// otomatis dari payment provider.
+func (h *OrderHandler) completeOrderPayment(ctx context.Context, input repositories.OrderSettlement, paidAmount, changeAmount float64) (*db.Order, *repositories.OrderSettlementResult, error) {
+	settlement, err := h.service.SettleOrder(ctx, input)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal proses pembayaran: %w", err)
+	}
+	h.recordTablesPaid(ctx, input.OrderID, settlement.TableNumbers)
+
+	paidOrder, paidItems, err := h.service.GetOrderDetails(ctx, input.OrderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal mengambil detail order setelah pembayaran: %w", err)
+	}
+
+	tipAmount := 0.0
+	if input.Tip != nil {
+		tipAmount = input.Tip.Amount
+	}
+	h.enqueueFullPaymentReceipt(ctx, paidOrder, paidItems, input.PaymentMethod, paidAmount, tipAmount, changeAmount)
+
+	h.emitEvent("payment_completed", map[string]interface{}{
+		"order_id":      input.OrderID,
+		"table_numbers": settlement.TableNumbers,
+	})
+	h.emitEvent("table_status_updated", map[string]interface{}{
+		"table_numbers": settlement.TableNumbers,
+	})
+	return paidOrder, settlement, nil
+}
+
+// previewOrderDeposit menghitung deposit yang akan dipakai untuk sisa tagihan tanpa
+// memotong saldo; pemotongan terjadi di transaksi pelunasan (SettleOrder)
+func (h *OrderHandler) previewOrderDeposit(ctx context.Context, orderID string, remaining float64) (float64, error) {
+	held, err := repositories.HeldDepositBalance(ctx, h.db, orderID)
+	if err != nil {
+		return 0, err
+	}
+	return math.Min(held, math.Max(math.Round(remaining), 0)), nil
+}
+
+func respondSettlementError(c *echo.Context, err error) error {
+	switch {
+	case errors.Is(err, repositories.ErrOrderAlreadyPaid):
+		return BadRequestResponse(c, "Tagihan sudah lunas")
+	case errors.Is(err, repositories.ErrDepositChanged):
+		return ConflictResponse(c, repositories.ErrDepositChanged.Error())
+	case errors.Is(err, repositories.ErrInvalidTip):
+		return BadRequestResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrGiftCardNotFound), errors.Is(err, repositories.ErrInvalidGiftCard),
+		errors.Is(err, repositories.ErrGiftCardDisabled), errors.Is(err, repositories.ErrGiftCardInsufficient):
+		return respondGiftCardError(c, err, "Gagal memakai gift card")
+	default:
+		return InternalErrorResponse(c, err.Error())
+	}
+}
+
+// resolvePaymentMethod memvalidasi metode pembayaran terhadap tabel payment_methods
+// dan memastikan nomor referensi diisi untuk metode yang mewajibkannya.
+func (h *OrderHandler) resolvePaymentMethod(ctx context.Context, code, referenceNumber string) (*repositories.PaymentMethod, error) {
+	if code == repositories.DepositPaymentMethod {
+		return nil, fmt.Errorf("%w: deposit dipakai otomatis saat order dilunasi", repositories.ErrInvalidPaymentMethod)
+	}
+	method, err := repositories.GetActivePaymentMethod(ctx, h.db, code)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+			return nil, fmt.Errorf("%w: %s", repositories.ErrPaymentMethodNotFound, code)
+		}
+		return nil, err
+	}
+	if method.RequiresReference && strings.TrimSpace(referenceNumber) == "" {
+		return nil, fmt.Errorf("%w: reference_number wajib diisi untuk %s", repositories.ErrInvalidPaymentMethod, method.Name)
+	}
+	return method, nil
+}
+
+// buildPaymentTip menyiapkan tip dari request pembayaran. Tip waiter diatribusikan
+// ke pembuat order (orders.created_by); order tanpa waiter harus memakai tip pool.
+func (h *OrderHandler) buildPaymentTip(ctx context.Context, order *db.Order, amount float64, recipient, cashierID, shiftID string) (*repositories.PaymentTip, error) {
+	if amount == 0 {
+		return nil, nil
+	}
+	if amount < 0 {
+		return nil, fmt.Errorf("%w: tip tidak boleh negatif", repositories.ErrInvalidTip)
+	}
+
+	recipient = strings.TrimSpace(recipient)
+	if recipient == "" {
+		recipient = repositories.TipRecipientWaiter
+	}
+	tip := &repositories.PaymentTip{
+		OrderID:       order.ID,
+		Amount:        math.Round(amount),
+		RecipientType: recipient,
+		CreatedBy:     cashierID,
+	}
+	switch recipient {
+	case repositories.TipRecipientWaiter:
+		if !order.CreatedBy.Valid || order.CreatedBy.String == "" {
+			return nil, fmt.Errorf("%w: order tidak memiliki waiter, gunakan tip pool", repositories.ErrInvalidTip)
+		}
+		staffID := order.CreatedBy.String
+		tip.StaffID = &staffID
+	case repositories.TipRecipientPool:
+	default:
+		return nil, fmt.Errorf("%w: tip_recipient harus waiter atau pool", repositories.ErrInvalidTip)
+	}
+
+	tip.ShiftID = &shiftID
+	return tip, nil
+}
+
 func (h *OrderHandler) HandleApplyDiscount(c *echo.Context) error {
 	orderID := c.Param("id")
 
@@ -561,11 +794,8 @@ func (h *OrderHandler) HandleApplyDiscount(c *echo.Context) error {
 	}
 
 	ctx := (*c).Request().Context()
-	if err := h.ensureOpenCashierShift(ctx); err != nil {
-		if err == sql.ErrNoRows {
-			return BadRequestResponse(c, "Shift kasir belum dibuka")
-		}
-		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+	if _, err := h.getOpenCashierShiftID(c); err != nil {
+		return respondShiftError(c, err)
 	}
 
 	if err := h.service.ApplyOrderDiscount(ctx, orderID, req.ChargeType, req.Value); err != nil {
@@ -578,14 +808,69 @@ func (h *OrderHandler) HandleApplyDiscount(c *echo.Context) error {
 	return SuccessResponse(c, "Diskon berhasil diterapkan", nil)
 }
 
-func (h *OrderHandler) HandleApplyCompliment(c *echo.Context) error {
+// HandleApplyVoucher memasang kode voucher promosi ke order
+func (h *OrderHandler) HandleApplyVoucher(c *echo.Context) error {
 	orderID := c.Param("id")
+
+	var req struct {
+		Code string `json:"code"`
+	}
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if strings.TrimSpace(req.Code) == "" {
+		return BadRequestResponse(c, "Kode voucher wajib diisi")
+	}
+
+	appliedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		appliedBy = claims.UserID
+	}
+
 	ctx := (*c).Request().Context()
-	if err := h.ensureOpenCashierShift(ctx); err != nil {
-		if err == sql.ErrNoRows {
-			return BadRequestResponse(c, "Shift kasir belum dibuka")
+	if err := h.service.ApplyVoucher(ctx, orderID, req.Code, appliedBy); err != nil {
+		if errors.Is(err, repositories.ErrVoucherNotFound) {
+			return NotFoundResponse(c, err.Error())
 		}
-		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+		return BadRequestResponse(c, err.Error())
+	}
+
+	promotions, err := h.service.GetOrderPromotions(ctx, orderID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil promosi order: "+err.Error())
+	}
+
+	h.emitEvent("order_items_updated", map[string]interface{}{
+		"order_id": orderID,
+	})
+	return SuccessResponse(c, "Voucher berhasil diterapkan", map[string]interface{}{
+		"promotions": promotions,
+	})
+}
+
+// HandleRemoveVoucher melepas voucher dari order
+func (h *OrderHandler) HandleRemoveVoucher(c *echo.Context) error {
+	orderID := c.Param("id")
+	ctx := (*c).Request().Context()
+	if err := h.service.RemoveVoucher(ctx, orderID, c.Param("code")); err != nil {
+		if errors.Is(err, repositories.ErrVoucherNotFound) {
+			return NotFoundResponse(c, "Voucher tidak ditemukan pada order")
+		}
+		return BadRequestResponse(c, err.Error())
+	}
+
+	h.emitEvent("order_items_updated", map[string]interface{}{
+		"order_id": orderID,
+	})
+	return SuccessResponse(c, "Voucher berhasil dilepas", nil)
+}
+
+func (h *OrderHandler) HandleApplyCompliment(c *echo.Context) error {
+	orderID := c.Param("id")
+	ctx := (*c).Request().Context()
+	shiftID, err := h.getOpenCashierShiftID(c)
+	if err != nil {
+		return respondShiftError(c, err)
 	}
 
 	claims, err := middleware.GetUserFromContext(c)
@@ -615,10 +900,7 @@ func (h *OrderHandler) HandleApplyCompliment(c *echo.Context) error {
 	updatedTables := map[string]bool{
 		order.TableNumber: true,
 	}
-	if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-		Status:      "available",
-		TableNumber: order.TableNumber,
-	}); err != nil {
+	if _, err := repositories.SettleTable(ctx, h.db, order.TableNumber, repositories.TableStatusPaid); err != nil {
 		return InternalErrorResponse(c, "Gagal update status meja: "+err.Error())
 	}
 
@@ -636,10 +918,7 @@ func (h *OrderHandler) HandleApplyCompliment(c *echo.Context) error {
 		if updatedTables[mergedOrder.TableNumber] {
 			continue
 		}
-		if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-			Status:      "available",
-			TableNumber: mergedOrder.TableNumber,
-		}); err != nil {
+		if _, err := repositories.SettleTable(ctx, h.db, mergedOrder.TableNumber, repositories.TableStatusPaid); err != nil {
 			return InternalErrorResponse(c, "Gagal update status meja gabungan: "+err.Error())
 		}
 		updatedTables[mergedOrder.TableNumber] = true
@@ -649,8 +928,9 @@ func (h *OrderHandler) HandleApplyCompliment(c *echo.Context) error {
 	for tableNumber := range updatedTables {
 		tableNumbers = append(tableNumbers, tableNumber)
 	}
+	h.recordTablesPaid(ctx, order.ID, tableNumbers)
 
-	_, err = h.transactionService.CreateTransactionForOrder(ctx, orderID, 0, "cash", claims.UserID)
+	_, err = h.transactionService.CreateTransactionForOrder(ctx, orderID, 0, "cash", claims.UserID, shiftID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mencatat transaksi: "+err.Error())
 	}
@@ -668,7 +948,7 @@ func (h *OrderHandler) HandleApplyCompliment(c *echo.Context) error {
 	return SuccessResponse(c, "Order berhasil dikompliment", nil)
 }
 
-func (h *OrderHandler) enqueueFullPaymentReceipt(ctx context.Context, order *db.Order, items []db.OrderItem, paymentMethod string, paidAmount float64, changeAmount float64) {
+func (h *OrderHandler) enqueueFullPaymentReceipt(ctx context.Context, order *db.Order, items []db.OrderItem, paymentMethod string, paidAmount float64, tipAmount float64, changeAmount float64) {
 	printerID, ok := h.getReceiptPrinterID(ctx)
 	if !ok {
 		return
@@ -692,6 +972,7 @@ func (h *OrderHandler) enqueueFullPaymentReceipt(ctx context.Context, order *db.
 	if order.CustomerName.Valid {
 		customerName = order.CustomerName.String
 	}
+	taxes, taxTotal := h.getOrderTaxLines(ctx, order.ID)
 
 	payload := workers.PrintJobData{
 		OrderID:                order.ID,
@@ -704,13 +985,16 @@ func (h *OrderHandler) enqueueFullPaymentReceipt(ctx context.Context, order *db.
 		Subtotal:               subtotal,
 		AdditionalChargesTotal: h.getAdditionalChargesTotal(ctx, order.ID),
 		AdditionalCharges:      h.getAdditionalChargesBreakdown(ctx, order.ID),
-		Tax:                    0,
+		Tax:                    taxTotal,
+		Taxes:                  taxes,
 		Total:                  int(math.Round(order.TotalAmount)),
 		PaymentMethod:          paymentMethod,
 		PaidAmount:             int(math.Round(paidAmount)),
+		TipAmount:              int(math.Round(tipAmount)),
 		ChangeAmount:           int(math.Round(changeAmount)),
 		DateTime:               time.Now(),
 	}
+	attachOrderChannel(ctx, h.db, &payload)
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -755,6 +1039,7 @@ func (h *OrderHandler) enqueueComplimentReceipt(ctx context.Context, order *db.O
 		ChangeAmount:           0,
 		DateTime:               time.Now(),
 	}
+	attachOrderChannel(ctx, h.db, &payload)
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -788,31 +1073,29 @@ func (h *OrderHandler) HandleVoidOrder(c *echo.Context) error {
 		}
 	}
 
-	managers, err := h.queries.ListActiveManagers((*c).Request().Context())
+	manager, err := verifyManagerPIN((*c).Request().Context(), h.queries, req.ManagerPIN)
+	if errors.Is(err, errInvalidManagerPIN) {
+		return UnauthorizedResponse(c, err.Error())
+	}
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data manager")
 	}
-
-	var managerID string
-	for _, manager := range managers {
-		if err := bcrypt.CompareHashAndPassword([]byte(manager.PasswordHash), []byte(req.ManagerPIN)); err == nil {
-			managerID = manager.ID
-			break
-		}
-	}
-	if managerID == "" {
-		return UnauthorizedResponse(c, "PIN manager salah")
-	}
+	managerID := manager.ID
 
 	order, _, err := h.service.GetOrderDetails((*c).Request().Context(), orderID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil detail order: "+err.Error())
 	}
 
-	if err := h.service.VoidOrder((*c).Request().Context(), orderID, managerID, req.Reason); err != nil {
+	// Void dari terminal kasir tercatat di shift terminal tersebut; void manager di luar shift tetap boleh
+	voidShiftID := optionalTerminalShiftID(c, h.db)
+	if err := h.service.VoidOrder((*c).Request().Context(), orderID, managerID, req.Reason, voidShiftID); err != nil {
 		if errors.Is(err, repositories.ErrOrderAlreadyPaid) {
 			return BadRequestResponse(c, "Order sudah dibayar")
 		}
+		if repositories.IsBusinessDayClosed(err) {
+			return BadRequestResponse(c, repositories.ErrBusinessDayClosed.Error())
+		}
 		if errors.Is(err, repositories.ErrOrderVoided) {
 			return BadRequestResponse(c, "Order sudah di-void")
 		}
@@ -826,10 +1109,7 @@ func (h *OrderHandler) HandleVoidOrder(c *echo.Context) error {
 	updatedTables := map[string]bool{
 		order.TableNumber: true,
 	}
-	if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-		Status:      "available",
-		TableNumber: order.TableNumber,
-	}); err != nil {
+	if _, err := repositories.SettleTable(ctx, h.db, order.TableNumber, repositories.TableStatusDirty); err != nil {
 		return InternalErrorResponse(c, "Gagal update status meja: "+err.Error())
 	}
 
@@ -840,37 +1120,199 @@ func (h *OrderHandler) HandleVoidOrder(c *echo.Context) error {
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil order hasil gabungan: "+err.Error())
 	}
-	for _, mergedOrder := range mergedOrders {
-		if mergedOrder.TableNumber == "" {
-			continue
-		}
-		if updatedTables[mergedOrder.TableNumber] {
-			continue
-		}
-		if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-			Status:      "available",
-			TableNumber: mergedOrder.TableNumber,
-		}); err != nil {
-			return InternalErrorResponse(c, "Gagal update status meja gabungan: "+err.Error())
-		}
-		updatedTables[mergedOrder.TableNumber] = true
+	for _, mergedOrder := range mergedOrders {
+		if mergedOrder.TableNumber == "" {
+			continue
+		}
+		if updatedTables[mergedOrder.TableNumber] {
+			continue
+		}
+		if _, err := repositories.SettleTable(ctx, h.db, mergedOrder.TableNumber, repositories.TableStatusDirty); err != nil {
+			return InternalErrorResponse(c, "Gagal update status meja gabungan: "+err.Error())
+		}
+		updatedTables[mergedOrder.TableNumber] = true
+	}
+
+	tableNumbers := make([]string, 0, len(updatedTables))
+	for tableNumber := range updatedTables {
+		tableNumbers = append(tableNumbers, tableNumber)
+	}
+
+	h.emitEvent("order_voided", map[string]interface{}{
+		"order_id":      orderID,
+		"table_numbers": tableNumbers,
+	})
+	h.emitEvent("table_status_updated", map[string]interface{}{
+		"table_numbers": tableNumbers,
+	})
+	return SuccessResponse(c, "Order berhasil di-void", map[string]interface{}{
+		"order_id":      orderID,
+		"table_numbers": tableNumbers,
+	})
+}
+
+// HandleRefundOrder - refund sebagian/penuh order yang sudah lunas, wajib PIN manager
+func (h *OrderHandler) HandleRefundOrder(c *echo.Context) error {
+	orderID := c.Param("id")
+	var req struct {
+		ManagerPIN   string                         `json:"manager_pin"`
+		Reason       string                         `json:"reason"`
+		RefundMethod string                         `json:"refund_method"`
+		Amount       float64                        `json:"amount"`
+		PaymentID    string                         `json:"payment_id"`
+		Restock      *bool                          `json:"restock"`
+		Items        []repositories.RefundItemInput `json:"items"`
+	}
+
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	if len(req.ManagerPIN) != 4 {
+		return BadRequestResponse(c, "PIN harus tepat 4 digit")
+	}
+	for _, char := range req.ManagerPIN {
+		if char < '0' || char > '9' {
+			return BadRequestResponse(c, "PIN harus berupa angka")
+		}
+	}
+
+	ctx := (*c).Request().Context()
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	manager, err := verifyManagerPIN(ctx, h.queries, req.ManagerPIN)
+	if errors.Is(err, errInvalidManagerPIN) {
+		return UnauthorizedResponse(c, err.Error())
+	}
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil data manager")
+	}
+	managerID := manager.ID
+	managerName := manager.FullName
+
+	// Refund tunai diambil dari laci kasir, jadi shift harus terbuka agar ekspektasi kas ikut berkurang
+	shiftID, err := h.getOpenCashierShiftID(c)
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+
+	restock := true
+	if req.Restock != nil {
+		restock = *req.Restock
+	}
+
+	refundID, err := h.service.RefundOrder(ctx, repositories.RefundInput{
+		OrderID:           orderID,
+		OriginalPaymentID: req.PaymentID,
+		RefundMethod:      req.RefundMethod,
+		Amount:            req.Amount,
+		Reason:            req.Reason,
+		Items:             req.Items,
+		Restock:           restock,
+		ApprovedBy:        managerID,
+		CreatedBy:         claims.UserID,
+		ShiftID:           shiftID,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NotFoundResponse(c, "Order tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrOrderNotPaid) {
+			return BadRequestResponse(c, "Refund hanya untuk order yang sudah lunas")
+		}
+		if errors.Is(err, repositories.ErrOrderVoided) {
+			return BadRequestResponse(c, "Order sudah di-void")
+		}
+		if errors.Is(err, repositories.ErrRefundExceedsPaid) {
+			return BadRequestResponse(c, "Nominal refund melebihi sisa pembayaran")
+		}
+		if errors.Is(err, repositories.ErrOrderItemNotFound) {
+			return BadRequestResponse(c, "Item refund tidak ditemukan di order")
+		}
+		if errors.Is(err, repositories.ErrInvalidRefund) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal memproses refund: "+err.Error())
+	}
+
+	refunds, err := h.service.GetOrderRefunds(ctx, orderID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil data refund: "+err.Error())
+	}
+	var refund *repositories.Refund
+	for i := range refunds {
+		if refunds[i].ID == refundID {
+			refund = &refunds[i]
+			break
+		}
+	}
+	if refund == nil {
+		return InternalErrorResponse(c, "Gagal mengambil data refund")
+	}
+
+	order, _, err := h.service.GetOrderDetails(ctx, orderID)
+	if err == nil {
+		h.enqueueRefundReceipt(ctx, order, refund, managerName)
+	}
+
+	h.emitEvent("order_refunded", map[string]interface{}{
+		"order_id":  orderID,
+		"refund_id": refundID,
+		"amount":    refund.Amount,
+	})
+	return CreatedResponse(c, "Refund berhasil diproses", refund)
+}
+
+func (h *OrderHandler) HandleGetOrderRefunds(c *echo.Context) error {
+	refunds, err := h.service.GetOrderRefunds((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil data refund: "+err.Error())
+	}
+	return SuccessResponse(c, "Data refund berhasil diambil", refunds)
+}
+
+func (h *OrderHandler) enqueueRefundReceipt(ctx context.Context, order *db.Order, refund *repositories.Refund, managerName string) {
+	printerID, ok := h.getReceiptPrinterID(ctx)
+	if !ok {
+		return
+	}
+
+	receiptItems := make([]workers.ReceiptItem, 0, len(refund.Items))
+	for _, item := range refund.Items {
+		receiptItems = append(receiptItems, workers.ReceiptItem{
+			Name:     item.ProductName,
+			Quantity: int(item.Qty),
+			Price:    int(math.Round(item.Price)),
+			Total:    int(math.Round(item.Amount)),
+		})
+	}
+
+	payload := workers.PrintJobData{
+		OrderID:         order.ID,
+		ReceiptNumber:   "RFD-" + refund.ID,
+		TableNumber:     order.TableNumber,
+		CashierName:     refund.CreatedByName,
+		ApprovedBy:      managerName,
+		Items:           receiptItems,
+		Total:           int(math.Round(refund.Amount)),
+		PaymentMethod:   refund.RefundMethod,
+		RefundReason:    refund.Reason,
+		IsRefundReceipt: true,
+		DateTime:        time.Now(),
 	}
 
-	tableNumbers := make([]string, 0, len(updatedTables))
-	for tableNumber := range updatedTables {
-		tableNumbers = append(tableNumbers, tableNumber)
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return
 	}
 
-	h.emitEvent("order_voided", map[string]interface{}{
-		"order_id":      orderID,
-		"table_numbers": tableNumbers,
-	})
-	h.emitEvent("table_status_updated", map[string]interface{}{
-		"table_numbers": tableNumbers,
-	})
-	return SuccessResponse(c, "Order berhasil di-void", map[string]interface{}{
-		"order_id":      orderID,
-		"table_numbers": tableNumbers,
+	_, _ = h.queries.CreatePrintJob(ctx, db.CreatePrintJobParams{
+		ID:        utils.GenerateULID(),
+		PrinterID: printerID,
+		Data:      string(payloadJSON),
 	})
 }
 
@@ -897,10 +1339,10 @@ func (h *OrderHandler) HandleListOrders(c *echo.Context) error {
 		waiterName := h.getWaiterName(ctx, &order)
 		mergedFromTableNumber := h.getMergedFromTableNumber(ctx, &order)
 		if isCashier {
-			responses = append(responses, toOrderResponseForCashier(&order, waiterName, mergedFromTableNumber))
+			responses = append(responses, h.withOrderChannel(ctx, toOrderResponseForCashier(&order, waiterName, mergedFromTableNumber)))
 			continue
 		}
-		responses = append(responses, toOrderResponse(&order, waiterName, mergedFromTableNumber))
+		responses = append(responses, h.withOrderChannel(ctx, toOrderResponse(&order, waiterName, mergedFromTableNumber)))
 	}
 
 	pagination := CalculatePagination(params.Page, params.PageSize, total)
@@ -922,8 +1364,21 @@ func (h *OrderHandler) HandleGetOrderDetails(c *echo.Context) error {
 	}
 
 	adjustments := h.getManualAdjustments((*c).Request().Context(), orderID)
+	promotions, err := h.service.GetOrderPromotions((*c).Request().Context(), orderID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil promosi order: "+err.Error())
+	}
 	additionalChargesTotal := h.getAdditionalChargesTotal((*c).Request().Context(), orderID)
 	additionalCharges := h.getAdditionalChargesBreakdown((*c).Request().Context(), orderID)
+	taxes, taxTotal := h.getOrderTaxLines((*c).Request().Context(), orderID)
+	refunds, err := h.service.GetOrderRefunds((*c).Request().Context(), orderID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil data refund: "+err.Error())
+	}
+	refundedTotal := 0.0
+	for _, refund := range refunds {
+		refundedTotal += refund.Amount
+	}
 
 	waiterName := h.getWaiterName((*c).Request().Context(), order)
 	mergedFromTableNumber := h.getMergedFromTableNumber((*c).Request().Context(), order)
@@ -931,13 +1386,19 @@ func (h *OrderHandler) HandleGetOrderDetails(c *echo.Context) error {
 	if claims, err := middleware.GetUserFromContext(c); err == nil && claims.Role == "cashier" {
 		orderResponse = toOrderResponseForCashier(order, waiterName, mergedFromTableNumber)
 	}
+	orderResponse = h.withOrderChannel((*c).Request().Context(), orderResponse)
 	return SuccessResponse(c, "Detail order berhasil diambil", map[string]interface{}{
 		"order":                    orderResponse,
-		"items":                    items,
+		"items":                    h.withItemCourses((*c).Request().Context(), orderID, items),
 		"payments":                 payments,
 		"adjustments":              adjustments,
+		"promotions":               promotions,
 		"additional_charges_total": additionalChargesTotal,
 		"additional_charges":       additionalCharges,
+		"taxes":                    taxes,
+		"tax_total":                taxTotal,
+		"refunds":                  refunds,
+		"refunded_total":           refundedTotal,
 	})
 }
 
@@ -1046,18 +1507,25 @@ func (h *OrderHandler) HandleGetDisplayOrders(c *echo.Context) error {
 			return InternalErrorResponse(c, "Gagal mengambil item order: "+err.Error())
 		}
 
-		filteredItems := items
-		if destination != "" {
-			selectedItems := make([]db.OrderItem, 0, len(items))
-			for _, item := range items {
-				if item.Destination == destination {
-					selectedItems = append(selectedItems, item)
+		// Item yang ditahan belum masuk antrean dapur, hanya course-nya yang ditampilkan
+		filteredItems := make([]OrderItemResponse, 0, len(items))
+		heldCourses := []string{}
+		seenHeld := map[string]bool{}
+		for _, item := range h.withItemCourses(ctx, order.ID, items) {
+			if destination != "" && item.Destination != destination {
+				continue
+			}
+			if item.IsHeld {
+				if !seenHeld[item.Course] {
+					seenHeld[item.Course] = true
+					heldCourses = append(heldCourses, item.Course)
 				}
+				continue
 			}
-			filteredItems = selectedItems
+			filteredItems = append(filteredItems, item)
 		}
 
-		if len(filteredItems) == 0 {
+		if len(filteredItems) == 0 && len(heldCourses) == 0 {
 			continue
 		}
 
@@ -1065,8 +1533,9 @@ func (h *OrderHandler) HandleGetDisplayOrders(c *echo.Context) error {
 		waiterName := h.getWaiterName(ctx, &currentOrder)
 		mergedFromTableNumber := h.getMergedFromTableNumber(ctx, &currentOrder)
 		displayOrders = append(displayOrders, map[string]interface{}{
-			"order": toOrderResponse(&currentOrder, waiterName, mergedFromTableNumber),
-			"items": filteredItems,
+			"order":        h.withOrderChannel(ctx, toOrderResponse(&currentOrder, waiterName, mergedFromTableNumber)),
+			"items":        filteredItems,
+			"held_courses": heldCourses,
 		})
 	}
 
@@ -1188,6 +1657,16 @@ func (h *OrderHandler) HandleGetOrderAnalytics(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil ringkasan biaya tambahan: "+err.Error())
 	}
 
+	promotionsTotal, promotionsBreakdown, err := h.service.GetPromotionSummary((*c).Request().Context(), startDate, endDate)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil ringkasan promosi: "+err.Error())
+	}
+
+	refundsTotal, refundsCount, refundsBreakdown, err := h.service.GetRefundSummary((*c).Request().Context(), startDate, endDate)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil ringkasan refund: "+err.Error())
+	}
+
 	voidTotal, err := h.service.GetVoidedTotalByDateRange((*c).Request().Context(), startDate, endDate)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil total void: "+err.Error())
@@ -1275,6 +1754,12 @@ func (h *OrderHandler) HandleGetOrderAnalytics(c *echo.Context) error {
 			"unpaid_revenue_change_pct": unpaidRevenueChange,
 			"additional_charges_total":  additionalChargesTotal,
 			"additional_charges_items":  additionalChargesBreakdown,
+			"promotions_total":          promotionsTotal,
+			"promotions_items":          promotionsBreakdown,
+			"refunds_total":             refundsTotal,
+			"refunds_count":             refundsCount,
+			"refunds_items":             refundsBreakdown,
+			"net_paid_revenue":          paidRevenue - refundsTotal,
 			"void_total":                voidTotal,
 			"cancelled_total":           cancelledTotal,
 			"products_sold":             productsSold,
@@ -1330,33 +1815,41 @@ func (h *OrderHandler) HandleGetRevenueChart(c *echo.Context) error {
 func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 	orderID := c.Param("id")
 
-	var req struct {
-		Amount        float64         `json:"amount"`
-		PaidAmount    float64         `json:"paid_amount"`
-		PaymentMethod string          `json:"payment_method"`
-		Note          string          `json:"note,omitempty"`
-		Items         []splitBillItem `json:"items,omitempty"`
-	}
-
+	var req splitPaymentRequest
 	if err := (*c).Bind(&req); err != nil {
 		return BadRequestResponse(c, "Body request tidak valid")
 	}
 
-	// Validate payment method
-	validMethods := map[string]bool{
-		"cash": true, "card": true, "qris": true, "transfer": true,
-	}
-	if !validMethods[req.PaymentMethod] {
-		return BadRequestResponse(c, "payment_method tidak valid. Valid: cash, card, qris, transfer")
+	ctx := (*c).Request().Context()
+	if err := h.ensureNoOpenChecks(ctx, orderID); err != nil {
+		if errors.Is(err, repositories.ErrOrderHasChecks) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal memeriksa split check")
 	}
+	return h.processSplitPayment(c, orderID, req, nil)
+}
 
+// processSplitPayment mencatat pembayaran parsial per item/nominal (check nil) atau
+// pelunasan satu split check
+func (h *OrderHandler) processSplitPayment(c *echo.Context, orderID string, req splitPaymentRequest, check *repositories.OrderCheck) error {
 	ctx := (*c).Request().Context()
 
-	if err := h.ensureOpenCashierShift(ctx); err != nil {
-		if err == sql.ErrNoRows {
-			return BadRequestResponse(c, "Shift kasir belum dibuka")
+	// Validate payment method
+	method, err := h.resolvePaymentMethod(ctx, req.PaymentMethod, req.ReferenceNumber)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) || errors.Is(err, repositories.ErrInvalidPaymentMethod) {
+			return BadRequestResponse(c, err.Error())
 		}
-		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+		return InternalErrorResponse(c, "Gagal memeriksa metode pembayaran")
+	}
+	if method.Code == repositories.GiftCardPaymentMethod && strings.TrimSpace(req.GiftCardCode) == "" {
+		return BadRequestResponse(c, "gift_card_code wajib diisi untuk pembayaran gift card")
+	}
+
+	shiftID, err := h.getOpenCashierShiftID(c)
+	if err != nil {
+		return respondShiftError(c, err)
 	}
 
 	// Get user from context
@@ -1370,11 +1863,17 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil detail order: "+err.Error())
 	}
 
-	orderSubtotal := 0.0
-	for _, item := range itemsSnapshot {
-		orderSubtotal += item.Price * float64(item.Qty)
+	// Item yang sudah dibayar lewat split dihapus dari order, jadi subtotal asli dihitung
+	// dari total order dikurangi biaya tambahan dan pajak eksklusif yang tersimpan
+	orderChargesTotal := h.getOrderChargesTotal(ctx, orderID)
+	_, orderTaxTotal := h.getOrderTaxLines(ctx, orderID)
+	orderSubtotal := orderSnapshot.TotalAmount - orderChargesTotal - float64(orderTaxTotal)
+	if orderSubtotal <= 0 {
+		orderSubtotal = 0
+		for _, item := range itemsSnapshot {
+			orderSubtotal += item.Price * float64(item.Qty)
+		}
 	}
-	manualAdjustmentsTotal := h.getManualAdjustmentsTotal(ctx, orderID)
 
 	remaining := orderSnapshot.TotalAmount - orderSnapshot.PaidAmount
 	if remaining <= 0 {
@@ -1389,15 +1888,20 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 		if err != nil {
 			return BadRequestResponse(c, err.Error())
 		}
-		splitAdditionalCharges, err := h.calculateActiveAdditionalChargesAmount(ctx, float64(splitSubtotal))
-		if err != nil {
-			return InternalErrorResponse(c, "Gagal menghitung biaya tambahan split bill: "+err.Error())
-		}
-		manualShare := 0.0
-		if manualAdjustmentsTotal != 0 && orderSubtotal > 0 {
-			manualShare = manualAdjustmentsTotal * (float64(splitSubtotal) / orderSubtotal)
+		// Biaya tambahan dan pajak dibagi proporsional dari yang tersimpan di order sehingga
+		// pembebasan tipe order, aturan area, biaya kemasan dan promo ikut terbawa
+		chargeShare := 0.0
+		taxShare := 0.0
+		if orderSubtotal > 0 {
+			ratio := float64(splitSubtotal) / orderSubtotal
+			chargeShare = orderChargesTotal * ratio
+			taxShare = float64(orderTaxTotal) * ratio
 		}
-		orderPaymentAmount = float64(splitSubtotal) + splitAdditionalCharges + manualShare
+		orderPaymentAmount = float64(splitSubtotal) + chargeShare + taxShare
+	}
+	if check != nil {
+		orderPaymentAmount = check.Due
+		splitReceiptItems, splitSubtotal = buildCheckReceiptItems(check.Items)
 	}
 
 	if orderPaymentAmount <= 0 {
@@ -1408,22 +1912,34 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 	if orderPaymentAmount > remaining {
 		return BadRequestResponse(c, "Jumlah pembayaran melebihi sisa tagihan")
 	}
+	tip, err := h.buildPaymentTip(ctx, orderSnapshot, req.TipAmount, req.TipRecipient, claims.UserID, shiftID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrInvalidTip) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal menyiapkan tip: "+err.Error())
+	}
+	tipAmount := 0.0
+	if tip != nil {
+		tipAmount = tip.Amount
+	}
+
 	receiptPaidAmount := req.PaidAmount
-	if req.PaymentMethod != "cash" {
-		receiptPaidAmount = orderPaymentAmount
+	if method.MethodType != "cash" {
+		receiptPaidAmount = orderPaymentAmount + tipAmount
 	} else {
 		if len(req.Items) > 0 && receiptPaidAmount > 0 && math.Abs(receiptPaidAmount-req.Amount) < 0.000001 {
-			receiptPaidAmount = orderPaymentAmount
+			receiptPaidAmount = orderPaymentAmount + tipAmount
 		}
 		if receiptPaidAmount <= 0 {
-			receiptPaidAmount = orderPaymentAmount
+			receiptPaidAmount = orderPaymentAmount + tipAmount
 		}
 		receiptPaidAmount = math.Round(receiptPaidAmount)
-		if receiptPaidAmount < orderPaymentAmount {
+		if receiptPaidAmount < orderPaymentAmount+tipAmount {
 			return BadRequestResponse(c, "Jumlah bayar kurang dari total split")
 		}
 	}
-	changeAmount := receiptPaidAmount - orderPaymentAmount
+	changeAmount := receiptPaidAmount - orderPaymentAmount - tipAmount
 
 	repoItems := make([]repositories.SplitBillItem, 0, len(req.Items))
 	for _, item := range req.Items {
@@ -1433,22 +1949,27 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 		})
 	}
 
-	// Process split payment
-	err = h.service.SplitBillPayment(ctx, orderID, orderPaymentAmount, req.PaymentMethod, req.Note, claims.UserID, repoItems)
-	if err != nil {
-		return InternalErrorResponse(c, "Gagal proses pembayaran: "+err.Error())
+	input := repositories.SplitPayment{
+		OrderID:         orderID,
+		PaymentMethod:   req.PaymentMethod,
+		ReferenceNumber: req.ReferenceNumber,
+		Note:            req.Note,
+		Amount:          orderPaymentAmount,
+		Items:           repoItems,
+		GiftCardCode:    req.GiftCardCode,
+		Tip:             tip,
+		CashierID:       claims.UserID,
+		ShiftID:         shiftID,
 	}
-
-	_, err = h.transactionService.CreateTransaction(
-		ctx,
-		orderID,
-		orderPaymentAmount,
-		req.PaymentMethod,
-		nil,
-		claims.UserID,
-	)
+	if check != nil {
+		input.CheckID = check.ID
+	}
+	result, err := h.service.SplitBillPayment(ctx, input)
 	if err != nil {
-		return InternalErrorResponse(c, "Gagal mencatat transaksi: "+err.Error())
+		if errors.Is(err, repositories.ErrOrderCheckPaid) {
+			return respondOrderCheckError(c, err, "Gagal memproses check")
+		}
+		return respondSettlementError(c, fmt.Errorf("gagal proses pembayaran: %w", err))
 	}
 
 	// Get updated order and payments
@@ -1464,48 +1985,9 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 	}
 
 	tableNumbers := []string{}
-	if order.PaymentStatus == "paid" {
-		if err := h.service.UpdateOrderStatus(ctx, orderID, "served"); err != nil {
-			return InternalErrorResponse(c, "Gagal update status order: "+err.Error())
-		}
-
-		updatedTables := map[string]bool{
-			order.TableNumber: true,
-		}
-		if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-			Status:      "available",
-			TableNumber: order.TableNumber,
-		}); err != nil {
-			return InternalErrorResponse(c, "Gagal update status meja: "+err.Error())
-		}
-
-		mergedOrders, err := h.queries.GetMergedOrders(ctx, sql.NullString{
-			String: order.ID,
-			Valid:  true,
-		})
-		if err != nil {
-			return InternalErrorResponse(c, "Gagal mengambil order hasil gabungan: "+err.Error())
-		}
-		for _, mergedOrder := range mergedOrders {
-			if mergedOrder.TableNumber == "" {
-				continue
-			}
-			if updatedTables[mergedOrder.TableNumber] {
-				continue
-			}
-			if err := h.queries.UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-				Status:      "available",
-				TableNumber: mergedOrder.TableNumber,
-			}); err != nil {
-				return InternalErrorResponse(c, "Gagal update status meja gabungan: "+err.Error())
-			}
-			updatedTables[mergedOrder.TableNumber] = true
-		}
-
-		tableNumbers = make([]string, 0, len(updatedTables))
-		for tableNumber := range updatedTables {
-			tableNumbers = append(tableNumbers, tableNumber)
-		}
+	if result.PaymentStatus == "paid" {
+		tableNumbers = result.TableNumbers
+		h.recordTablesPaid(ctx, order.ID, tableNumbers)
 
 		h.emitEvent("table_status_updated", map[string]interface{}{
 			"table_numbers": tableNumbers,
@@ -1515,18 +1997,28 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 	receiptItems := splitReceiptItems
 	receiptSubtotal := splitSubtotal
 	receiptTotal := int(math.Round(latestPaymentAmount))
+	taxRatio := 1.0
 	if len(receiptItems) == 0 {
 		receiptItems, receiptSubtotal = buildReceiptItems(items)
+	} else if orderSubtotal > 0 {
+		taxRatio = float64(splitSubtotal) / orderSubtotal
 	}
 
-	h.enqueueSplitPaymentReceipt(ctx, order, receiptItems, receiptSubtotal, receiptTotal, req.PaymentMethod, receiptPaidAmount, changeAmount)
+	receiptData := h.splitReceiptData(ctx, order, receiptItems, receiptSubtotal, receiptTotal, taxRatio)
+	if check != nil {
+		receiptData.ReceiptNumber = checkReceiptNumber(order.ID, check)
+	}
+	h.enqueueSplitPaymentReceipt(ctx, receiptData, req.PaymentMethod, receiptPaidAmount, tipAmount, changeAmount)
+	if method.MethodType == "cash" {
+		kickCashDrawerForShift(ctx, h.db, h.queries, repositories.DrawerSourceSplitPayment, orderID, shiftID, claims.UserID)
+	}
 
 	h.emitEvent("payment_completed", map[string]interface{}{
 		"order_id":       orderID,
 		"payment_status": order.PaymentStatus,
 		"table_numbers":  tableNumbers,
 	})
-	return SuccessResponse(c, "Pembayaran berhasil dicatat", map[string]interface{}{
+	response := map[string]interface{}{
 		"order_id":       orderID,
 		"total_amount":   remainingAmount(order),
 		"remaining":      remainingAmount(order),
@@ -1534,7 +2026,14 @@ func (h *OrderHandler) HandleSplitBillPayment(c *echo.Context) error {
 		"paid_amount":    order.PaidAmount,
 		"payment_status": order.PaymentStatus,
 		"payments":       payments,
-	})
+		"tip_amount":     tipAmount,
+		"change_amount":  changeAmount,
+	}
+	if check != nil {
+		response["check_id"] = check.ID
+		response["check_amount"] = orderPaymentAmount
+	}
+	return SuccessResponse(c, "Pembayaran berhasil dicatat", response)
 }
 
 func buildReceiptItems(items []db.OrderItem) ([]workers.ReceiptItem, int) {
@@ -1599,6 +2098,38 @@ func (h *OrderHandler) getAdditionalChargesBreakdown(ctx context.Context, orderI
 	return breakdowns
 }
 
+// getOrderTaxLines mengambil baris pajak order beserta total pajak eksklusif (yang menambah tagihan)
+func (h *OrderHandler) getOrderTaxLines(ctx context.Context, orderID string) ([]workers.ReceiptTax, int) {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT name, rate, is_inclusive, tax_amount
+		FROM order_taxes
+		WHERE order_id = ?
+		ORDER BY id
+	`, orderID)
+	if err != nil {
+		return nil, 0
+	}
+	defer rows.Close()
+
+	taxes := []workers.ReceiptTax{}
+	exclusiveTotal := 0
+	for rows.Next() {
+		var tax workers.ReceiptTax
+		var isInclusive int64
+		var amount float64
+		if err := rows.Scan(&tax.Name, &tax.Rate, &isInclusive, &amount); err != nil {
+			return taxes, exclusiveTotal
+		}
+		tax.IsInclusive = isInclusive == 1
+		tax.Amount = int(math.Round(amount))
+		if !tax.IsInclusive {
+			exclusiveTotal += tax.Amount
+		}
+		taxes = append(taxes, tax)
+	}
+	return taxes, exclusiveTotal
+}
+
 type ManualAdjustment struct {
 	Name          string  `json:"name"`
 	ChargeType    string  `json:"charge_type"`
@@ -1612,6 +2143,8 @@ func (h *OrderHandler) getManualAdjustments(ctx context.Context, orderID string)
 		FROM order_additional_charges
 		WHERE order_id = ?
 		  AND charge_id IS NULL
+		  AND promotion_id IS NULL
+		  AND is_packaging = 0
 		ORDER BY created_at
 	`, orderID)
 	if err != nil {
@@ -1630,12 +2163,13 @@ func (h *OrderHandler) getManualAdjustments(ctx context.Context, orderID string)
 	return adjustments
 }
 
-func (h *OrderHandler) getManualAdjustmentsTotal(ctx context.Context, orderID string) float64 {
+// getOrderChargesTotal menjumlahkan seluruh baris order_additional_charges (biaya tambahan,
+// promo, biaya kemasan dan penyesuaian manual) seperti yang dihitung ke total order
+func (h *OrderHandler) getOrderChargesTotal(ctx context.Context, orderID string) float64 {
 	row := h.db.QueryRowContext(ctx, `
 		SELECT COALESCE(SUM(applied_amount), 0)
 		FROM order_additional_charges
 		WHERE order_id = ?
-		  AND charge_id IS NULL
 	`, orderID)
 	var total float64
 	if err := row.Scan(&total); err != nil {
@@ -1644,29 +2178,6 @@ func (h *OrderHandler) getManualAdjustmentsTotal(ctx context.Context, orderID st
 	return total
 }
 
-func (h *OrderHandler) calculateActiveAdditionalChargesAmount(ctx context.Context, subtotal float64) (float64, error) {
-	if subtotal <= 0 {
-		return 0, nil
-	}
-
-	var total float64
-	err := h.db.QueryRowContext(ctx, `
-		SELECT COALESCE(SUM(
-			CASE
-				WHEN charge_type = 'percentage' THEN (? * value / 100.0)
-				ELSE value
-			END
-		), 0)
-		FROM additional_charges
-		WHERE is_active = 1
-	`, subtotal).Scan(&total)
-	if err != nil {
-		return 0, err
-	}
-
-	return total, nil
-}
-
 func buildSplitReceiptItems(orderItems []db.OrderItem, selected []splitBillItem) ([]workers.ReceiptItem, int, error) {
 	qtyByID := make(map[string]int64, len(selected))
 	for _, item := range selected {
@@ -1707,17 +2218,23 @@ func buildSplitReceiptItems(orderItems []db.OrderItem, selected []splitBillItem)
 	return receiptItems, subtotal, nil
 }
 
-func (h *OrderHandler) enqueueSplitPaymentReceipt(ctx context.Context, order *db.Order, receiptItems []workers.ReceiptItem, subtotal int, total int, paymentMethod string, paidAmount float64, changeAmount float64) {
-	printerID, ok := h.getReceiptPrinterID(ctx)
-	if !ok {
-		return
-	}
-
+// splitReceiptData menyiapkan struk/bill untuk sebagian tagihan (split bill atau split check)
+func (h *OrderHandler) splitReceiptData(ctx context.Context, order *db.Order, receiptItems []workers.ReceiptItem, subtotal int, total int, taxRatio float64) workers.PrintJobData {
 	customerName := ""
 	if order.CustomerName.Valid {
 		customerName = order.CustomerName.String
 	}
 
+	// Pajak split bill mengikuti porsi subtotal item yang dibayar
+	taxes, _ := h.getOrderTaxLines(ctx, order.ID)
+	taxTotal := 0
+	for i := range taxes {
+		taxes[i].Amount = int(math.Round(float64(taxes[i].Amount) * taxRatio))
+		if !taxes[i].IsInclusive {
+			taxTotal += taxes[i].Amount
+		}
+	}
+
 	payload := workers.PrintJobData{
 		OrderID:                order.ID,
 		ReceiptNumber:          "TRX-" + order.ID,
@@ -1729,25 +2246,52 @@ func (h *OrderHandler) enqueueSplitPaymentReceipt(ctx context.Context, order *db
 		Subtotal:               subtotal,
 		AdditionalChargesTotal: h.getAdditionalChargesTotal(ctx, order.ID),
 		AdditionalCharges:      h.getAdditionalChargesBreakdown(ctx, order.ID),
-		Tax:                    0,
+		Tax:                    taxTotal,
+		Taxes:                  taxes,
 		Total:                  total,
-		PaymentMethod:          paymentMethod,
-		PaidAmount:             int(math.Round(paidAmount)),
-		ChangeAmount:           int(math.Round(changeAmount)),
 		DateTime:               time.Now(),
-		IsSplitPayment:         true,
 	}
+	attachOrderChannel(ctx, h.db, &payload)
+	return payload
+}
 
-	payloadJSON, err := json.Marshal(payload)
+// attachOrderChannel menyertakan tipe order dan nomor antrian agar dicetak besar di struk
+func attachOrderChannel(ctx context.Context, q db.DBTX, payload *workers.PrintJobData) {
+	channel, err := repositories.GetOrderChannel(ctx, q, payload.OrderID)
 	if err != nil {
 		return
 	}
+	payload.OrderType = channel.OrderType
+	payload.QueueLabel = channel.QueueLabel
+}
 
-	_, _ = h.queries.CreatePrintJob(ctx, db.CreatePrintJobParams{
+func (h *OrderHandler) enqueueSplitPaymentReceipt(ctx context.Context, payload workers.PrintJobData, paymentMethod string, paidAmount float64, tipAmount float64, changeAmount float64) {
+	payload.PaymentMethod = paymentMethod
+	payload.PaidAmount = int(math.Round(paidAmount))
+	payload.TipAmount = int(math.Round(tipAmount))
+	payload.ChangeAmount = int(math.Round(changeAmount))
+	payload.IsSplitPayment = true
+	h.enqueueReceiptJob(ctx, payload)
+}
+
+// enqueueReceiptJob mengirim struk ke printer kasir; false jika printer tidak tersedia
+func (h *OrderHandler) enqueueReceiptJob(ctx context.Context, payload workers.PrintJobData) bool {
+	printerID, ok := h.getReceiptPrinterID(ctx)
+	if !ok {
+		return false
+	}
+
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return false
+	}
+
+	_, err = h.queries.CreatePrintJob(ctx, db.CreatePrintJobParams{
 		ID:        utils.GenerateULID(),
 		PrinterID: printerID,
 		Data:      string(payloadJSON),
 	})
+	return err == nil
 }
 
 func (h *OrderHandler) getReceiptPrinterID(ctx context.Context) (string, bool) {
@@ -1769,6 +2313,7 @@ func (h *OrderHandler) HandleMergeTables(c *echo.Context) error {
 	var req struct {
 		SourceOrderIDs    []string `json:"source_order_ids"`
 		TargetTableNumber string   `json:"target_table_number"`
+		CombinationID     string   `json:"combination_id"`
 	}
 
 	if err := (*c).Bind(&req); err != nil {
@@ -1779,16 +2324,53 @@ func (h *OrderHandler) HandleMergeTables(c *echo.Context) error {
 		return BadRequestResponse(c, "Minimal 2 order untuk digabung")
 	}
 
+	// Gabungan meja dari denah: order harus di meja anggota, target default meja utama
+	var combination *repositories.TableCombination
+	if req.CombinationID != "" {
+		var err error
+		combination, err = repositories.GetTableCombination((*c).Request().Context(), h.db, req.CombinationID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrTableCombinationNotFound) {
+				return NotFoundResponse(c, err.Error())
+			}
+			return InternalErrorResponse(c, "Gagal mengambil gabungan meja: "+err.Error())
+		}
+		if err := repositories.CheckCombinationOrders((*c).Request().Context(), h.db, combination, req.SourceOrderIDs); err != nil {
+			if errors.Is(err, repositories.ErrInvalidFloorPlan) {
+				return BadRequestResponse(c, err.Error())
+			}
+			return InternalErrorResponse(c, "Gagal memeriksa gabungan meja: "+err.Error())
+		}
+		if req.TargetTableNumber == "" {
+			req.TargetTableNumber = combination.PrimaryTableNumber
+		}
+	}
+
 	if req.TargetTableNumber == "" {
 		return BadRequestResponse(c, "target_table_number wajib diisi")
 	}
 
+	mergedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		mergedBy = claims.UserID
+	}
+
 	// Merge tables
-	newOrderID, err := h.service.MergeTables((*c).Request().Context(), req.SourceOrderIDs, req.TargetTableNumber)
+	newOrderID, err := h.service.MergeTables((*c).Request().Context(), req.SourceOrderIDs, req.TargetTableNumber, mergedBy)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal menggabung meja: "+err.Error())
 	}
 
+	if combination != nil {
+		if err := repositories.OccupyTableCombination((*c).Request().Context(), h.db, combination); err != nil {
+			log.Printf("Failed to occupy combination tables for %s: %v", newOrderID, err)
+		} else {
+			h.emitEvent("table_status_updated", map[string]interface{}{
+				"table_numbers": combination.TableNumbers,
+			})
+		}
+	}
+
 	// Get merged order details
 	order, items, err := h.service.GetOrderDetails((*c).Request().Context(), newOrderID)
 	if err != nil {
@@ -1810,6 +2392,182 @@ func (h *OrderHandler) HandleMergeTables(c *echo.Context) error {
 	})
 }
 
+type CourseActionRequest struct {
+	Course  string   `json:"course"`
+	ItemIDs []string `json:"item_ids"`
+}
+
+func respondCourseError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return NotFoundResponse(c, "Order tidak ditemukan")
+	case errors.Is(err, repositories.ErrNoHeldItems), errors.Is(err, repositories.ErrNoHoldableItems):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrOrderAlreadyPaid), errors.Is(err, repositories.ErrOrderVoided):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidCourse):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// HandleFireCourse - Waiter/Admin melepas item yang ditahan (per course atau item_ids) ke dapur
+func (h *OrderHandler) HandleFireCourse(c *echo.Context) error {
+	var req CourseActionRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	orderID := c.Param("id")
+	items, err := h.service.FireOrderItems((*c).Request().Context(), orderID, req.Course, req.ItemIDs)
+	if err != nil {
+		return respondCourseError(c, err, "Gagal fire course")
+	}
+
+	h.emitEvent("course_fired", map[string]interface{}{
+		"order_id": orderID,
+		"course":   req.Course,
+		"items":    items,
+	})
+	h.emitEvent("order_items_updated", map[string]interface{}{
+		"order_id": orderID,
+	})
+	return SuccessResponse(c, "Course berhasil di-fire", items)
+}
+
+// HandleHoldCourse - Waiter/Admin menahan item pending (per course atau item_ids) sampai di-fire
+func (h *OrderHandler) HandleHoldCourse(c *echo.Context) error {
+	var req CourseActionRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	orderID := c.Param("id")
+	items, err := h.service.HoldOrderItems((*c).Request().Context(), orderID, req.Course, req.ItemIDs)
+	if err != nil {
+		return respondCourseError(c, err, "Gagal menahan course")
+	}
+
+	h.emitEvent("course_held", map[string]interface{}{
+		"order_id": orderID,
+		"course":   req.Course,
+		"items":    items,
+	})
+	h.emitEvent("order_items_updated", map[string]interface{}{
+		"order_id": orderID,
+	})
+	return SuccessResponse(c, "Course berhasil ditahan", items)
+}
+
+func respondTableChangeError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrOrderItemNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrTableOccupied), errors.Is(err, repositories.ErrOrderAlreadyPaid),
+		errors.Is(err, repositories.ErrOrderHasChecks):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidTableChange), errors.Is(err, repositories.ErrOrderVoided),
+		errors.Is(err, repositories.ErrInvalidItemQty):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// HandleTransferOrder - Waiter/Admin memindah order ke meja lain yang kosong
+func (h *OrderHandler) HandleTransferOrder(c *echo.Context) error {
+	var req struct {
+		TargetTableNumber string `json:"target_table_number"`
+	}
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	movedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		movedBy = claims.UserID
+	}
+
+	change, err := h.service.TransferOrder((*c).Request().Context(), c.Param("id"), req.TargetTableNumber, movedBy)
+	if err != nil {
+		return respondTableChangeError(c, err, "Gagal memindah meja")
+	}
+
+	h.emitEvent("order_transferred", map[string]interface{}{
+		"order_id":          change.ToOrderID,
+		"from_table_number": change.FromTableNumber,
+		"to_table_number":   change.ToTableNumber,
+	})
+	h.emitEvent("table_status_updated", map[string]interface{}{
+		"table_numbers": []string{change.FromTableNumber, change.ToTableNumber},
+	})
+	return SuccessResponse(c, "Order berhasil dipindah meja", change)
+}
+
+// HandleMoveOrderItems - Waiter/Admin memindah sebagian item ke order lain yang masih berjalan
+func (h *OrderHandler) HandleMoveOrderItems(c *echo.Context) error {
+	var req struct {
+		TargetOrderID string                       `json:"target_order_id"`
+		Items         []repositories.MoveItemInput `json:"items"`
+	}
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if req.TargetOrderID == "" {
+		return BadRequestResponse(c, "target_order_id wajib diisi")
+	}
+
+	movedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		movedBy = claims.UserID
+	}
+
+	change, err := h.service.MoveOrderItems((*c).Request().Context(), c.Param("id"), req.TargetOrderID, req.Items, movedBy)
+	if err != nil {
+		return respondTableChangeError(c, err, "Gagal memindah item")
+	}
+
+	h.emitEvent("order_items_moved", map[string]interface{}{
+		"from_order_id":     change.FromOrderID,
+		"to_order_id":       change.ToOrderID,
+		"from_table_number": change.FromTableNumber,
+		"to_table_number":   change.ToTableNumber,
+	})
+	for _, orderID := range []string{change.FromOrderID, change.ToOrderID} {
+		h.emitEvent("order_items_updated", map[string]interface{}{
+			"order_id": orderID,
+		})
+	}
+	return SuccessResponse(c, "Item berhasil dipindah", change)
+}
+
+// HandleUnmergeOrder - Waiter/Admin memisah order hasil gabung meja kembali ke order asalnya
+func (h *OrderHandler) HandleUnmergeOrder(c *echo.Context) error {
+	movedBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		movedBy = claims.UserID
+	}
+
+	result, err := h.service.UnmergeOrder((*c).Request().Context(), c.Param("id"), movedBy)
+	if err != nil {
+		return respondTableChangeError(c, err, "Gagal memisah meja")
+	}
+
+	tableNumbers := append([]string{}, result.FreedTables...)
+	for _, change := range result.Changes {
+		tableNumbers = append(tableNumbers, change.ToTableNumber)
+	}
+	h.emitEvent("orders_unmerged", map[string]interface{}{
+		"merged_order_id": result.MergedOrderID,
+		"restored_orders": result.RestoredOrders,
+	})
+	h.emitEvent("table_status_updated", map[string]interface{}{
+		"table_numbers": tableNumbers,
+	})
+	return SuccessResponse(c, "Meja berhasil dipisah", result)
+}
+
 // HandleGetOrderByTable - Get active order for a specific table
 func (h *OrderHandler) HandleGetOrderByTable(c *echo.Context) error {
 	tableID := c.Param("table_id")
@@ -1830,8 +2588,9 @@ func (h *OrderHandler) HandleGetOrderByTable(c *echo.Context) error {
 	if claims, err := middleware.GetUserFromContext(c); err == nil && claims.Role == "cashier" {
 		orderResponse = toOrderResponseForCashier(order, waiterName, mergedFromTableNumber)
 	}
+	orderResponse = h.withOrderChannel((*c).Request().Context(), orderResponse)
 	return SuccessResponse(c, "Detail order berhasil diambil", map[string]interface{}{
 		"order": orderResponse,
-		"items": items,
+		"items": h.withItemCourses((*c).Request().Context(), order.ID, items),
 	})
 }
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+
+	"github.com/labstack/echo/v5"
+)
+
+// OrderTypeHandler mengatur tipe order (dine-in, takeaway, delivery, pickup): biaya
+// kemasan, prefix antrian, aturan biaya tambahan per tipe dan papan antrian
+type OrderTypeHandler struct {
+	orderTypeService services.OrderTypeService
+}
+
+func NewOrderTypeHandler(orderTypeService services.OrderTypeService) *OrderTypeHandler {
+	return &OrderTypeHandler{orderTypeService: orderTypeService}
+}
+
+func respondOrderTypeError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrOrderTypeNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidOrderType):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+func (h *OrderTypeHandler) ListOrderTypes(c *echo.Context) error {
+	settings, err := h.orderTypeService.ListOrderTypes((*c).Request().Context())
+	if err != nil {
+		return respondOrderTypeError(c, err, "Gagal mengambil tipe order")
+	}
+	return SuccessResponse(c, "Tipe order berhasil diambil", settings)
+}
+
+// UpdateOrderType - ubah status aktif, biaya kemasan dan prefix antrian tipe order
+func (h *OrderTypeHandler) UpdateOrderType(c *echo.Context) error {
+	var req repositories.OrderTypeSettingInput
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	setting, err := h.orderTypeService.UpdateOrderType((*c).Request().Context(), c.Param("type"), req)
+	if err != nil {
+		return respondOrderTypeError(c, err, "Gagal mengubah tipe order")
+	}
+	return SuccessResponse(c, "Tipe order berhasil diubah", setting)
+}
+
+// SetChargeRules - atur biaya tambahan per tipe order, mis. takeaway bebas service charge
+func (h *OrderTypeHandler) SetChargeRules(c *echo.Context) error {
+	var req AreaChargeRulesRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	setting, err := h.orderTypeService.SetChargeRules((*c).Request().Context(), c.Param("type"), req.Rules)
+	if err != nil {
+		return respondOrderTypeError(c, err, "Gagal menyimpan aturan biaya tipe order")
+	}
+	return SuccessResponse(c, "Aturan biaya tipe order berhasil disimpan", setting)
+}
+
+// ListQueue - papan antrian order tanpa meja hari ini (all=true termasuk yang sudah selesai)
+func (h *OrderTypeHandler) ListQueue(c *echo.Context) error {
+	orders, err := h.orderTypeService.ListQueue((*c).Request().Context(), c.QueryParam("order_type"), c.QueryParam("all") != "true")
+	if err != nil {
+		return respondOrderTypeError(c, err, "Gagal mengambil antrian order")
+	}
+	return SuccessResponse(c, "Antrian order berhasil diambil", orders)
+}
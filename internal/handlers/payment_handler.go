@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"backend/pkg/payment"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"net/http"
+
+	"github.com/labstack/echo/v5"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// PaymentHandler menangani intent pembayaran lewat payment provider
+// (QRIS dinamis) dan settlement otomatis order dari webhook/poll
+type PaymentHandler struct {
+	paymentService services.PaymentService
+	orders         *OrderHandler
+}
+
+func NewPaymentHandler(paymentService services.PaymentService, orders *OrderHandler) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+		orders:         orders,
+	}
+}
+
+// HandleListProviders - daftar provider yang aktif
+func (h *PaymentHandler) HandleListProviders(c *echo.Context) error {
+	return SuccessResponse(c, "Payment provider berhasil diambil", map[string]interface{}{
+		"providers": h.paymentService.ListProviders(),
+		"default":   h.paymentService.DefaultProvider(),
+	})
+}
+
+// HandleCreateIntent - buat QRIS dinamis sebesar sisa tagihan order
+func (h *PaymentHandler) HandleCreateIntent(c *echo.Context) error {
+	orderID := c.Param("id")
+	var req struct {
+		Provider string `json:"provider"`
+	}
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	ctx := (*c).Request().Context()
+	if err := h.orders.ensureNoOpenChecks(ctx, orderID); err != nil {
+		if errors.Is(err, repositories.ErrOrderHasChecks) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal memeriksa split check")
+	}
+	shiftID, err := h.orders.getOpenCashierShiftID(c)
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	order, _, err := h.orders.service.GetOrderDetails(ctx, orderID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return NotFoundResponse(c, "Order tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil detail order: "+err.Error())
+	}
+	if h.isOrderVoided(ctx, orderID) {
+		return BadRequestResponse(c, repositories.ErrOrderVoided.Error())
+	}
+	remaining := math.Round(order.TotalAmount - order.PaidAmount)
+	if remaining <= 0 {
+		return BadRequestResponse(c, "Tagihan sudah lunas")
+	}
+
+	// QRIS hanya sebesar sisa tagihan setelah deposit; deposit baru dipotong saat intent
+	// lunas. Tagihan yang tertutup penuh oleh deposit diselesaikan lewat pembayaran kasir biasa.
+	depositAmount, err := h.orders.previewOrderDeposit(ctx, orderID, remaining)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal memeriksa deposit: "+err.Error())
+	}
+	if depositAmount >= remaining {
+		return BadRequestResponse(c, "Tagihan sudah tertutup deposit, proses pembayaran lewat kasir")
+	}
+	remaining -= depositAmount
+
+	intent, err := h.paymentService.CreateIntent(ctx, orderID, remaining, req.Provider, claims.UserID, shiftID)
+	if err != nil {
+		if errors.Is(err, payment.ErrProviderNotFound) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat QRIS: "+err.Error())
+	}
+
+	h.orders.emitEvent("payment_intent_created", map[string]interface{}{
+		"order_id":  orderID,
+		"intent_id": intent.ID,
+	})
+	return CreatedResponse(c, "QRIS berhasil dibuat", intentResponse(intent))
+}
+
+// HandleListOrderIntents - riwayat intent pembayaran sebuah order
+func (h *PaymentHandler) HandleListOrderIntents(c *echo.Context) error {
+	intents, err := h.paymentService.ListOrderIntents((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil intent pembayaran: "+err.Error())
+	}
+	return SuccessResponse(c, "Intent pembayaran berhasil diambil", intents)
+}
+
+// HandleGetIntent - poll status intent ke provider; order otomatis lunas jika sudah dibayar
+func (h *PaymentHandler) HandleGetIntent(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+	intent, settled, err := h.paymentService.RefreshIntent(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentIntentNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal cek status pembayaran: "+err.Error())
+	}
+	if settled {
+		if err := h.settleIntent(ctx, intent); err != nil {
+			return InternalErrorResponse(c, "Pembayaran diterima tetapi order gagal dilunasi: "+err.Error())
+		}
+	}
+	return SuccessResponse(c, "Status pembayaran berhasil diambil", intentResponse(intent))
+}
+
+// HandleCancelIntent - batalkan QRIS yang belum dibayar
+func (h *PaymentHandler) HandleCancelIntent(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+	intent, err := h.paymentService.CancelIntent(ctx, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentIntentNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		if errors.Is(err, services.ErrPaymentIntentNotPending) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membatalkan QRIS: "+err.Error())
+	}
+
+	h.orders.emitEvent("payment_intent_updated", map[string]interface{}{
+		"order_id":  intent.OrderID,
+		"intent_id": intent.ID,
+		"status":    intent.Status,
+	})
+	return SuccessResponse(c, "QRIS berhasil dibatalkan", intentResponse(intent))
+}
+
+// HandleSimulateSandbox - lunasi intent sandbox untuk uji lokal (status default paid)
+func (h *PaymentHandler) HandleSimulateSandbox(c *echo.Context) error {
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	ctx := (*c).Request().Context()
+	intent, settled, err := h.paymentService.SimulateSandbox(ctx, c.Param("id"), req.Status)
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentIntentNotFound) || errors.Is(err, payment.ErrIntentNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		return BadRequestResponse(c, err.Error())
+	}
+	if settled {
+		if err := h.settleIntent(ctx, intent); err != nil {
+			return InternalErrorResponse(c, "Pembayaran diterima tetapi order gagal dilunasi: "+err.Error())
+		}
+	}
+	return SuccessResponse(c, "Simulasi pembayaran berhasil", intentResponse(intent))
+}
+
+// HandleCallback - webhook publik dari payment provider. Signature diverifikasi
+// oleh provider masing-masing; callback ganda tidak melunasi order dua kali.
+func (h *PaymentHandler) HandleCallback(c *echo.Context) error {
+	body, err := io.ReadAll((*c).Request().Body)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Failed to read request body",
+		})
+	}
+
+	ctx := (*c).Request().Context()
+	providerName := c.Param("provider")
+	intent, settled, err := h.paymentService.HandleCallback(ctx, providerName, body, (*c).Request().Header)
+	if err != nil {
+		log.Printf("Payment callback %s rejected: %v", providerName, err)
+		switch {
+		case errors.Is(err, payment.ErrInvalidSignature):
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid signature"})
+		case errors.Is(err, payment.ErrProviderNotFound), errors.Is(err, repositories.ErrPaymentIntentNotFound):
+			return c.JSON(http.StatusNotFound, map[string]string{"error": err.Error()})
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+	}
+
+	if settled {
+		if err := h.settleIntent(ctx, intent); err != nil {
+			log.Printf("Payment intent %s paid but settlement failed: %v", intent.ID, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "Failed to settle order",
+			})
+		}
+	} else {
+		h.orders.emitEvent("payment_intent_updated", map[string]interface{}{
+			"order_id":  intent.OrderID,
+			"intent_id": intent.ID,
+			"status":    intent.Status,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"success": true,
+		"status":  intent.Status,
+	})
+}
+
+// settleIntent mencatat pembayaran intent yang sudah paid ke order. Intent ditandai
+// settled di transaksi pelunasan yang sama, jadi pelunasan yang gagal diulang oleh
+// callback berikutnya. Jika tagihan bertambah setelah QR dibuat, nominal intent dicatat
+// sebagai pembayaran parsial; jika order sudah lunas, intent ditandai untuk refund.
+func (h *PaymentHandler) settleIntent(ctx context.Context, intent *repositories.PaymentIntent) error {
+	order, _, err := h.orders.service.GetOrderDetails(ctx, intent.OrderID)
+	if err != nil {
+		return err
+	}
+
+	remaining := math.Round(order.TotalAmount - order.PaidAmount)
+	if remaining <= 0 || order.PaymentStatus == "paid" {
+		return h.flagIntentRefund(ctx, intent)
+	}
+
+	shiftID := repositories.SettlementShiftID(ctx, h.orders.db, intent.ShiftID)
+	depositAmount, err := h.orders.previewOrderDeposit(ctx, order.ID, remaining)
+	if err != nil {
+		return err
+	}
+	if intent.Amount >= remaining-depositAmount {
+		_, _, err = h.orders.completeOrderPayment(ctx, repositories.OrderSettlement{
+			OrderID:         order.ID,
+			PaymentMethod:   intent.PaymentMethod,
+			ReferenceNumber: intent.ProviderRef,
+			Amount:          math.Max(order.TotalAmount-order.PaidAmount-depositAmount, 0),
+			DepositAmount:   depositAmount,
+			CashierID:       intent.CreatedBy,
+			ShiftID:         shiftID,
+			PaymentIntentID: intent.ID,
+		}, intent.Amount, 0)
+		return h.intentSettlementResult(ctx, intent, err)
+	}
+
+	result, err := h.orders.service.SplitBillPayment(ctx, repositories.SplitPayment{
+		OrderID:         order.ID,
+		PaymentMethod:   intent.PaymentMethod,
+		ReferenceNumber: intent.ProviderRef,
+		Note:            "QRIS " + intent.ID,
+		Amount:          intent.Amount,
+		CashierID:       intent.CreatedBy,
+		ShiftID:         shiftID,
+		PaymentIntentID: intent.ID,
+	})
+	if err != nil {
+		return h.intentSettlementResult(ctx, intent, err)
+	}
+	h.orders.emitEvent("payment_completed", map[string]interface{}{
+		"order_id":       order.ID,
+		"payment_status": result.PaymentStatus,
+		"table_numbers":  []string{},
+	})
+	return nil
+}
+
+// intentSettlementResult menerjemahkan kegagalan pelunasan intent: intent yang sudah
+// dicatat callback lain bukan error, order yang keburu lunas ditandai untuk refund
+func (h *PaymentHandler) intentSettlementResult(ctx context.Context, intent *repositories.PaymentIntent, err error) error {
+	switch {
+	case err == nil, errors.Is(err, repositories.ErrPaymentIntentSettled):
+		return nil
+	case errors.Is(err, repositories.ErrOrderAlreadyPaid):
+		return h.flagIntentRefund(ctx, intent)
+	default:
+		return err
+	}
+}
+
+// flagIntentRefund mencatat intent paid untuk order yang sudah lunas agar dana
+// pelanggan dikembalikan, bukan diabaikan
+func (h *PaymentHandler) flagIntentRefund(ctx context.Context, intent *repositories.PaymentIntent) error {
+	flagged, err := h.paymentService.MarkRefundRequired(ctx, intent.ID)
+	if err != nil {
+		return err
+	}
+	if !flagged {
+		return nil
+	}
+	log.Printf("Payment intent %s paid but order %s is already settled; refund required", intent.ID, intent.OrderID)
+	h.orders.emitEvent("payment_intent_refund_required", map[string]interface{}{
+		"order_id":  intent.OrderID,
+		"intent_id": intent.ID,
+		"amount":    intent.Amount,
+	})
+	return nil
+}
+
+func (h *PaymentHandler) isOrderVoided(ctx context.Context, orderID string) bool {
+	var voidedAt sql.NullTime
+	if err := h.orders.db.QueryRowContext(ctx, "SELECT voided_at FROM orders WHERE id = ?", orderID).Scan(&voidedAt); err != nil {
+		return false
+	}
+	return voidedAt.Valid
+}
+
+// intentResponse menambahkan gambar QR (PNG base64) untuk ditampilkan di mobile cashier
+func intentResponse(intent *repositories.PaymentIntent) map[string]interface{} {
+	qrImage := ""
+	if intent.Status == string(payment.StatusPending) && intent.QRString != "" {
+		if png, err := qrcode.Encode(intent.QRString, qrcode.Medium, 256); err == nil {
+			qrImage = base64.StdEncoding.EncodeToString(png)
+		}
+	}
+	return map[string]interface{}{
+		"intent":   intent,
+		"qr_image": qrImage,
+	}
+}
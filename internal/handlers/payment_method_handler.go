@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+
+	"github.com/labstack/echo/v5"
+)
+
+type PaymentMethodHandler struct {
+	paymentMethodService services.PaymentMethodService
+}
+
+func NewPaymentMethodHandler(paymentMethodService services.PaymentMethodService) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		paymentMethodService: paymentMethodService,
+	}
+}
+
+type PaymentMethodRequest struct {
+	Code              string `json:"code"`
+	Name              string `json:"name"`
+	MethodType        string `json:"method_type"`
+	RequiresReference bool   `json:"requires_reference"`
+	CountsToDrawer    bool   `json:"counts_to_drawer"`
+	IsActive          *bool  `json:"is_active"`
+	SortOrder         int64  `json:"sort_order"`
+}
+
+func (req PaymentMethodRequest) toPaymentMethod() *repositories.PaymentMethod {
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	return &repositories.PaymentMethod{
+		Code:              req.Code,
+		Name:              req.Name,
+		MethodType:        req.MethodType,
+		RequiresReference: req.RequiresReference,
+		CountsToDrawer:    req.CountsToDrawer,
+		IsActive:          isActive,
+		SortOrder:         req.SortOrder,
+	}
+}
+
+// ListPaymentMethods - ?active=true untuk daftar metode yang bisa dipilih kasir
+func (h *PaymentMethodHandler) ListPaymentMethods(c *echo.Context) error {
+	activeOnly := c.QueryParam("active") == "true" || c.QueryParam("active") == "1"
+	methods, err := h.paymentMethodService.ListPaymentMethods((*c).Request().Context(), activeOnly)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil metode pembayaran: "+err.Error())
+	}
+	return SuccessResponse(c, "Data metode pembayaran berhasil diambil", methods)
+}
+
+func (h *PaymentMethodHandler) GetPaymentMethod(c *echo.Context) error {
+	method, err := h.paymentMethodService.GetPaymentMethod((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+			return NotFoundResponse(c, "Metode pembayaran tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil metode pembayaran: "+err.Error())
+	}
+	return SuccessResponse(c, "Metode pembayaran berhasil diambil", method)
+}
+
+func (h *PaymentMethodHandler) CreatePaymentMethod(c *echo.Context) error {
+	var req PaymentMethodRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	method := req.toPaymentMethod()
+	if err := h.paymentMethodService.CreatePaymentMethod((*c).Request().Context(), method); err != nil {
+		if errors.Is(err, repositories.ErrInvalidPaymentMethod) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat metode pembayaran: "+err.Error())
+	}
+
+	created, err := h.paymentMethodService.GetPaymentMethod((*c).Request().Context(), method.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil metode pembayaran: "+err.Error())
+	}
+	return CreatedResponse(c, "Metode pembayaran berhasil dibuat", created)
+}
+
+func (h *PaymentMethodHandler) UpdatePaymentMethod(c *echo.Context) error {
+	var req PaymentMethodRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	method := req.toPaymentMethod()
+	method.ID = c.Param("id")
+	if err := h.paymentMethodService.UpdatePaymentMethod((*c).Request().Context(), method); err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+			return NotFoundResponse(c, "Metode pembayaran tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidPaymentMethod) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal update metode pembayaran: "+err.Error())
+	}
+
+	updated, err := h.paymentMethodService.GetPaymentMethod((*c).Request().Context(), method.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil metode pembayaran: "+err.Error())
+	}
+	return SuccessResponse(c, "Metode pembayaran berhasil diupdate", updated)
+}
+
+func (h *PaymentMethodHandler) DeletePaymentMethod(c *echo.Context) error {
+	if err := h.paymentMethodService.DeletePaymentMethod((*c).Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repositories.ErrPaymentMethodNotFound) {
+			return NotFoundResponse(c, "Metode pembayaran tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrPaymentMethodInUse) || errors.Is(err, repositories.ErrPaymentMethodSystem) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal menghapus metode pembayaran: "+err.Error())
+	}
+	return SuccessResponse(c, "Metode pembayaran berhasil dihapus", nil)
+}
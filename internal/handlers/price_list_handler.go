@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+type PriceListHandler struct {
+	priceListService services.PriceListService
+}
+
+func NewPriceListHandler(priceListService services.PriceListService) *PriceListHandler {
+	return &PriceListHandler{
+		priceListService: priceListService,
+	}
+}
+
+type PriceListItemRequest struct {
+	ProductID string  `json:"product_id"`
+	DayPart   string  `json:"day_part"`
+	StartTime string  `json:"start_time"`
+	EndTime   string  `json:"end_time"`
+	Price     float64 `json:"price"`
+}
+
+type PriceListRequest struct {
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	EffectiveFrom string                 `json:"effective_from"`
+	IsActive      *bool                  `json:"is_active"`
+	Items         []PriceListItemRequest `json:"items"`
+}
+
+// parsePriceListTime menerima RFC3339 atau "YYYY-MM-DD HH:MM" dalam zona waktu outlet
+func parsePriceListTime(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, nil
+	}
+	return time.ParseInLocation("2006-01-02 15:04", value, time.Local)
+}
+
+func (req PriceListRequest) toPriceList() (*repositories.PriceList, error) {
+	if req.Name == "" {
+		return nil, errors.New("name wajib diisi")
+	}
+	if req.EffectiveFrom == "" {
+		return nil, errors.New("effective_from wajib diisi")
+	}
+	effectiveFrom, err := parsePriceListTime(req.EffectiveFrom)
+	if err != nil {
+		return nil, errors.New("format effective_from tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+	}
+	if len(req.Items) == 0 {
+		return nil, errors.New("items tidak boleh kosong")
+	}
+
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	items := make([]repositories.PriceListItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, repositories.PriceListItem{
+			ProductID: item.ProductID,
+			DayPart:   item.DayPart,
+			StartTime: item.StartTime,
+			EndTime:   item.EndTime,
+			Price:     item.Price,
+		})
+	}
+
+	return &repositories.PriceList{
+		Name:          req.Name,
+		Description:   req.Description,
+		EffectiveFrom: effectiveFrom,
+		IsActive:      isActive,
+		Items:         items,
+	}, nil
+}
+
+func (h *PriceListHandler) ListPriceLists(c *echo.Context) error {
+	lists, err := h.priceListService.ListPriceLists((*c).Request().Context())
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil price list: "+err.Error())
+	}
+	return SuccessResponse(c, "Data price list berhasil diambil", lists)
+}
+
+func (h *PriceListHandler) GetPriceList(c *echo.Context) error {
+	list, err := h.priceListService.GetPriceList((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrPriceListNotFound) {
+			return NotFoundResponse(c, "Price list tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil price list: "+err.Error())
+	}
+	return SuccessResponse(c, "Price list berhasil diambil", list)
+}
+
+func (h *PriceListHandler) CreatePriceList(c *echo.Context) error {
+	var req PriceListRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	list, err := req.toPriceList()
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		list.CreatedBy = claims.UserID
+	}
+
+	if err := h.priceListService.CreatePriceList((*c).Request().Context(), list); err != nil {
+		if errors.Is(err, repositories.ErrInvalidDayPartWindow) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat price list: "+err.Error())
+	}
+
+	created, err := h.priceListService.GetPriceList((*c).Request().Context(), list.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil price list: "+err.Error())
+	}
+	return CreatedResponse(c, "Price list berhasil dibuat", created)
+}
+
+func (h *PriceListHandler) UpdatePriceList(c *echo.Context) error {
+	var req PriceListRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	list, err := req.toPriceList()
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+	list.ID = c.Param("id")
+
+	if err := h.priceListService.UpdatePriceList((*c).Request().Context(), list); err != nil {
+		if errors.Is(err, repositories.ErrPriceListNotFound) {
+			return NotFoundResponse(c, "Price list tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidDayPartWindow) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal update price list: "+err.Error())
+	}
+
+	updated, err := h.priceListService.GetPriceList((*c).Request().Context(), list.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil price list: "+err.Error())
+	}
+	return SuccessResponse(c, "Price list berhasil diupdate", updated)
+}
+
+func (h *PriceListHandler) DeletePriceList(c *echo.Context) error {
+	if err := h.priceListService.DeletePriceList((*c).Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repositories.ErrPriceListNotFound) {
+			return NotFoundResponse(c, "Price list tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal menghapus price list: "+err.Error())
+	}
+	return SuccessResponse(c, "Price list berhasil dihapus", nil)
+}
+
+// PreviewPrices menampilkan harga yang berlaku pada waktu tertentu (?at=), default sekarang
+func (h *PriceListHandler) PreviewPrices(c *echo.Context) error {
+	at := time.Now()
+	if value := c.QueryParam("at"); value != "" {
+		parsed, err := parsePriceListTime(value)
+		if err != nil {
+			return BadRequestResponse(c, "Format at tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+		}
+		at = parsed
+	}
+
+	prices, err := h.priceListService.PreviewPrices((*c).Request().Context(), at)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal menghitung harga: "+err.Error())
+	}
+
+	return SuccessResponse(c, "Preview harga berhasil diambil", map[string]interface{}{
+		"at":     at.In(time.Local).Format(time.RFC3339),
+		"prices": prices,
+	})
+}
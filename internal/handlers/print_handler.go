@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log"
 	"math"
 	"net/http"
 	"time"
 
+	"backend/internal/repositories"
 	"backend/internal/workers"
 
 	"github.com/labstack/echo/v5"
@@ -17,12 +20,32 @@ import (
 
 // PrintHandler handles print-related operations
 type PrintHandler struct {
-	db *sql.DB
+	db       *sql.DB
+	realtime RealtimeBroadcaster
 }
 
 // NewPrintHandler creates a new print handler
-func NewPrintHandler(db *sql.DB) *PrintHandler {
-	return &PrintHandler{db: db}
+func NewPrintHandler(db *sql.DB, realtime RealtimeBroadcaster) *PrintHandler {
+	return &PrintHandler{db: db, realtime: realtime}
+}
+
+// markBillRequested records the printed bill on the table session and moves the
+// order's table to bill_requested, broadcasting the change to other devices.
+func markBillRequested(ctx context.Context, dbConn *sql.DB, realtime RealtimeBroadcaster, orderID string) {
+	if err := repositories.RecordOrderTableSessionEvent(ctx, dbConn, orderID, repositories.TableSessionBillPrinted); err != nil {
+		log.Printf("Failed to record bill printed for order %s: %v", orderID, err)
+	}
+	tableNumber, changed, err := repositories.AdvanceOrderTable(ctx, dbConn, orderID, repositories.TableStatusBillRequested)
+	if err != nil {
+		log.Printf("Failed to update table status for order %s: %v", orderID, err)
+		return
+	}
+	if changed && realtime != nil {
+		realtime.Emit("table_status_updated", map[string]interface{}{
+			"table_numbers": []string{tableNumber},
+			"status":        repositories.TableStatusBillRequested,
+		})
+	}
 }
 
 // PrintOrderRequest represents a manual print request
@@ -178,6 +201,7 @@ func (h *PrintHandler) HandlePrintBill(c *echo.Context) error {
 	orderData.PaidAmount = 0
 	orderData.ChangeAmount = 0
 	orderData.IsBill = true
+	h.attachActiveQRIS(orderID, orderData)
 
 	dataJSON, _ := json.Marshal(orderData)
 
@@ -194,12 +218,37 @@ func (h *PrintHandler) HandlePrintBill(c *echo.Context) error {
 		})
 	}
 
+	markBillRequested((*c).Request().Context(), h.db, h.realtime, orderID)
+
 	return (*c).JSON(http.StatusOK, APIResponse{
 		Success: true,
 		Message: "Bill added to queue successfully",
 	})
 }
 
+// attachActiveQRIS menyertakan QRIS dinamis dari intent yang masih aktif agar ikut tercetak di bill
+func (h *PrintHandler) attachActiveQRIS(orderID string, data *workers.PrintJobData) {
+	var qrString string
+	var expiresAt sql.NullTime
+	err := h.db.QueryRow(`
+		SELECT COALESCE(qr_string, ''), expires_at
+		FROM payment_intents
+		WHERE order_id = ? AND status = 'pending'
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, orderID).Scan(&qrString, &expiresAt)
+	if err != nil || qrString == "" {
+		return
+	}
+	if expiresAt.Valid {
+		if time.Now().After(expiresAt.Time) {
+			return
+		}
+		data.QRISExpiresAt = &expiresAt.Time
+	}
+	data.QRISPayload = qrString
+}
+
 func (h *PrintHandler) HandleRetryPrintQueue(c *echo.Context) error {
 	queueID := (*c).Param("id")
 	if queueID == "" {
@@ -383,6 +432,9 @@ func getPrintContentType(data workers.PrintJobData, printerType string) string {
 	if data.IsCashOutReceipt {
 		return "Kas Keluar"
 	}
+	if data.IsRefundReceipt {
+		return "Refund"
+	}
 	if data.IsBill {
 		return "Bill"
 	}
@@ -429,7 +481,7 @@ func getPrintContentSummary(data workers.PrintJobData, printerType string) strin
 		}
 	}
 	if !data.IsHandover && !data.IsCloseShift && !data.IsCashInReceipt && !data.IsCashOutReceipt {
-		if data.Total > 0 && (contentType == "Struk" || contentType == "Bill" || contentType == "Split Bill") {
+		if data.Total > 0 && (contentType == "Struk" || contentType == "Bill" || contentType == "Split Bill" || contentType == "Refund") {
 			parts = append(parts, "Total Rp "+formatAmount(data.Total))
 		}
 		if (printerType == "kitchen" || printerType == "bar") && len(data.Items) > 0 {
@@ -556,14 +608,46 @@ func (h *PrintHandler) fetchOrderData(orderID string) (*workers.PrintJobData, er
 	data.Subtotal = subtotal
 	data.AdditionalChargesTotal = h.getAdditionalChargesTotal(orderID)
 	data.AdditionalCharges = h.getAdditionalChargesBreakdown(orderID)
-	data.Tax = 0
+	data.Taxes, data.Tax = h.getOrderTaxLines(orderID)
 	data.Total = int(math.Round(totalAmount))
 	data.PaidAmount = int(math.Round(paidAmount))
 	data.ChangeAmount = data.PaidAmount - data.Total
+	attachOrderChannel(context.Background(), h.db, &data)
 
 	return &data, nil
 }
 
+func (h *PrintHandler) getOrderTaxLines(orderID string) ([]workers.ReceiptTax, int) {
+	rows, err := h.db.Query(`
+		SELECT name, rate, is_inclusive, tax_amount
+		FROM order_taxes
+		WHERE order_id = ?
+		ORDER BY id
+	`, orderID)
+	if err != nil {
+		return nil, 0
+	}
+	defer rows.Close()
+
+	taxes := []workers.ReceiptTax{}
+	exclusiveTotal := 0
+	for rows.Next() {
+		var tax workers.ReceiptTax
+		var isInclusive int64
+		var amount float64
+		if err := rows.Scan(&tax.Name, &tax.Rate, &isInclusive, &amount); err != nil {
+			return taxes, exclusiveTotal
+		}
+		tax.IsInclusive = isInclusive == 1
+		tax.Amount = int(math.Round(amount))
+		if !tax.IsInclusive {
+			exclusiveTotal += tax.Amount
+		}
+		taxes = append(taxes, tax)
+	}
+	return taxes, exclusiveTotal
+}
+
 func (h *PrintHandler) getAdditionalChargesTotal(orderID string) int {
 	row := h.db.QueryRow(`
 		SELECT COALESCE(SUM(oac.applied_amount), 0)
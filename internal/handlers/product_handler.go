@@ -2,19 +2,24 @@ package handlers
 
 import (
 	"backend/internal/db"
+	"backend/internal/repositories"
 	"backend/internal/services"
 	"database/sql"
+	"errors"
+	"net/http"
 
 	"github.com/labstack/echo/v5"
 )
 
 type ProductHandler struct {
 	productService services.ProductService
+	syncRepo       repositories.SyncRepository
 }
 
-func NewProductHandler(productService services.ProductService) *ProductHandler {
+func NewProductHandler(productService services.ProductService, syncRepo repositories.SyncRepository) *ProductHandler {
 	return &ProductHandler{
 		productService: productService,
+		syncRepo:       syncRepo,
 	}
 }
 
@@ -170,9 +175,28 @@ func (h *ProductHandler) UpdateProduct(c *echo.Context) error {
 		return BadRequestResponse(c, "Kategori wajib dipilih")
 	}
 
+	// Code akan di-generate otomatis dari nama, jadi pass empty string
+	// kecuali produk dikelola HQ yang kodenya harus tetap sama dengan pusat
+	code := ""
+	if err := checkManagedByHQ(c, h.syncRepo, "product", id); err != nil {
+		if !errors.Is(err, repositories.ErrManagedByHQ) {
+			return InternalErrorResponse(c, "Gagal cek data HQ: "+err.Error())
+		}
+		current, err := h.productService.GetProductByID((*c).Request().Context(), id)
+		if err != nil {
+			return InternalErrorResponse(c, "Gagal get product: "+err.Error())
+		}
+		// Hanya stok yang boleh diubah di outlet
+		existing := toProductResponse(*current)
+		if req.Name != existing.Name || req.Description != existing.Description ||
+			req.Price != existing.Price || req.CategoryID != existing.CategoryID {
+			return ErrorResponse(c, http.StatusForbidden, "Produk dikelola oleh HQ, hanya stok yang dapat diubah")
+		}
+		code = existing.Code
+	}
+
 	catID := &req.CategoryID
-	// Code akan di-generate otomatis dari nama, jadi selalu pass empty string
-	if err := h.productService.UpdateProduct((*c).Request().Context(), id, req.Name, "", req.Description, req.Price, req.Stock, catID); err != nil {
+	if err := h.productService.UpdateProduct((*c).Request().Context(), id, req.Name, code, req.Description, req.Price, req.Stock, catID); err != nil {
 		return InternalErrorResponse(c, "Gagal update produk: "+err.Error())
 	}
 
@@ -187,6 +211,13 @@ func (h *ProductHandler) UpdateProduct(c *echo.Context) error {
 func (h *ProductHandler) DeleteProduct(c *echo.Context) error {
 	id := c.Param("id")
 
+	if err := checkManagedByHQ(c, h.syncRepo, "product", id); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return ErrorResponse(c, http.StatusForbidden, "Produk dikelola oleh HQ dan tidak dapat dihapus")
+		}
+		return InternalErrorResponse(c, "Gagal cek data HQ: "+err.Error())
+	}
+
 	if err := h.productService.DeleteProduct((*c).Request().Context(), id); err != nil {
 		return InternalErrorResponse(c, "Gagal menghapus produk: "+err.Error())
 	}
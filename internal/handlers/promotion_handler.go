@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+type PromotionHandler struct {
+	promotionService services.PromotionService
+}
+
+func NewPromotionHandler(promotionService services.PromotionService) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+	}
+}
+
+type PromotionRequest struct {
+	Name            string   `json:"name"`
+	PromoType       string   `json:"promo_type"`
+	Scope           string   `json:"scope"`
+	TargetIDs       []string `json:"target_ids"`
+	Value           float64  `json:"value"`
+	BuyQty          int64    `json:"buy_qty"`
+	GetQty          int64    `json:"get_qty"`
+	MinSpend        float64  `json:"min_spend"`
+	StartAt         string   `json:"start_at"`
+	EndAt           string   `json:"end_at"`
+	DaysOfWeek      string   `json:"days_of_week"`
+	StartTime       string   `json:"start_time"`
+	EndTime         string   `json:"end_time"`
+	RequiresVoucher bool     `json:"requires_voucher"`
+	Priority        int64    `json:"priority"`
+	IsActive        *bool    `json:"is_active"`
+}
+
+type VoucherRequest struct {
+	Code        string `json:"code"`
+	PromotionID string `json:"promotion_id"`
+	UsageLimit  int64  `json:"usage_limit"`
+	ExpiresAt   string `json:"expires_at"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// parseOptionalPromotionTime mengembalikan nil untuk string kosong
+func parseOptionalPromotionTime(field, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := parsePriceListTime(value)
+	if err != nil {
+		return nil, errors.New("format " + field + " tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+	}
+	return &parsed, nil
+}
+
+func (req PromotionRequest) toPromotion() (*repositories.Promotion, error) {
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, errors.New("name wajib diisi")
+	}
+	startAt, err := parseOptionalPromotionTime("start_at", req.StartAt)
+	if err != nil {
+		return nil, err
+	}
+	endAt, err := parseOptionalPromotionTime("end_at", req.EndAt)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := req.Scope
+	if scope == "" {
+		scope = "order"
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	return &repositories.Promotion{
+		Name:            strings.TrimSpace(req.Name),
+		PromoType:       req.PromoType,
+		Scope:           scope,
+		TargetIDs:       req.TargetIDs,
+		Value:           req.Value,
+		BuyQty:          req.BuyQty,
+		GetQty:          req.GetQty,
+		MinSpend:        req.MinSpend,
+		StartAt:         startAt,
+		EndAt:           endAt,
+		DaysOfWeek:      strings.ReplaceAll(req.DaysOfWeek, " ", ""),
+		StartTime:       req.StartTime,
+		EndTime:         req.EndTime,
+		RequiresVoucher: req.RequiresVoucher,
+		Priority:        req.Priority,
+		IsActive:        isActive,
+	}, nil
+}
+
+func (h *PromotionHandler) ListPromotions(c *echo.Context) error {
+	promos, err := h.promotionService.ListPromotions((*c).Request().Context())
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil promosi: "+err.Error())
+	}
+	return SuccessResponse(c, "Data promosi berhasil diambil", promos)
+}
+
+func (h *PromotionHandler) GetPromotion(c *echo.Context) error {
+	promo, err := h.promotionService.GetPromotion((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrPromotionNotFound) {
+			return NotFoundResponse(c, "Promosi tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil promosi: "+err.Error())
+	}
+	return SuccessResponse(c, "Promosi berhasil diambil", promo)
+}
+
+func (h *PromotionHandler) CreatePromotion(c *echo.Context) error {
+	var req PromotionRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	promo, err := req.toPromotion()
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+
+	if err := h.promotionService.CreatePromotion((*c).Request().Context(), promo); err != nil {
+		if errors.Is(err, repositories.ErrInvalidPromotion) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat promosi: "+err.Error())
+	}
+
+	created, err := h.promotionService.GetPromotion((*c).Request().Context(), promo.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil promosi: "+err.Error())
+	}
+	return CreatedResponse(c, "Promosi berhasil dibuat", created)
+}
+
+func (h *PromotionHandler) UpdatePromotion(c *echo.Context) error {
+	var req PromotionRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	promo, err := req.toPromotion()
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+	promo.ID = c.Param("id")
+
+	if err := h.promotionService.UpdatePromotion((*c).Request().Context(), promo); err != nil {
+		if errors.Is(err, repositories.ErrPromotionNotFound) {
+			return NotFoundResponse(c, "Promosi tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidPromotion) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal update promosi: "+err.Error())
+	}
+
+	updated, err := h.promotionService.GetPromotion((*c).Request().Context(), promo.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil promosi: "+err.Error())
+	}
+	return SuccessResponse(c, "Promosi berhasil diupdate", updated)
+}
+
+func (h *PromotionHandler) DeletePromotion(c *echo.Context) error {
+	if err := h.promotionService.DeletePromotion((*c).Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repositories.ErrPromotionNotFound) {
+			return NotFoundResponse(c, "Promosi tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal menghapus promosi: "+err.Error())
+	}
+	return SuccessResponse(c, "Promosi berhasil dihapus", nil)
+}
+
+// ListVouchers menampilkan voucher beserta pemakaiannya, opsional filter ?promotion_id=
+func (h *PromotionHandler) ListVouchers(c *echo.Context) error {
+	vouchers, err := h.promotionService.ListVouchers((*c).Request().Context(), c.QueryParam("promotion_id"))
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil voucher: "+err.Error())
+	}
+	return SuccessResponse(c, "Data voucher berhasil diambil", vouchers)
+}
+
+func (h *PromotionHandler) CreateVoucher(c *echo.Context) error {
+	var req VoucherRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if req.PromotionID == "" {
+		return BadRequestResponse(c, "promotion_id wajib diisi")
+	}
+	expiresAt, err := parseOptionalPromotionTime("expires_at", req.ExpiresAt)
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	voucher := &repositories.Voucher{
+		Code:        req.Code,
+		PromotionID: req.PromotionID,
+		UsageLimit:  req.UsageLimit,
+		ExpiresAt:   expiresAt,
+		IsActive:    isActive,
+	}
+	if err := h.promotionService.CreateVoucher((*c).Request().Context(), voucher); err != nil {
+		if errors.Is(err, repositories.ErrPromotionNotFound) {
+			return NotFoundResponse(c, "Promosi tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidPromotion) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat voucher: "+err.Error())
+	}
+	return CreatedResponse(c, "Voucher berhasil dibuat", voucher)
+}
+
+func (h *PromotionHandler) DeleteVoucher(c *echo.Context) error {
+	if err := h.promotionService.DeleteVoucher((*c).Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repositories.ErrVoucherNotFound) {
+			return NotFoundResponse(c, "Voucher tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal menghapus voucher: "+err.Error())
+	}
+	return SuccessResponse(c, "Voucher berhasil dihapus", nil)
+}
@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// ReservationHandler mengatur reservasi meja dan daftar tunggu tamu walk-in.
+// Transisi otomatis status meja (reserved/no-show) dijalankan ReservationWorker.
+type ReservationHandler struct {
+	reservationService services.ReservationService
+	realtime           RealtimeBroadcaster
+}
+
+func NewReservationHandler(reservationService services.ReservationService, realtime RealtimeBroadcaster) *ReservationHandler {
+	return &ReservationHandler{
+		reservationService: reservationService,
+		realtime:           realtime,
+	}
+}
+
+type CreateReservationRequest struct {
+	CustomerID      string `json:"customer_id"`
+	CustomerName    string `json:"customer_name"`
+	CustomerPhone   string `json:"customer_phone"`
+	Pax             int64  `json:"pax"`
+	ReservedAt      string `json:"reserved_at"`
+	DurationMinutes int64  `json:"duration_minutes"`
+	TableID         string `json:"table_id"`
+	Note            string `json:"note"`
+}
+
+type UpdateReservationRequest struct {
+	Pax             *int64  `json:"pax"`
+	ReservedAt      *string `json:"reserved_at"`
+	DurationMinutes *int64  `json:"duration_minutes"`
+	TableID         *string `json:"table_id"`
+	Note            *string `json:"note"`
+}
+
+type AssignReservationTableRequest struct {
+	TableID string `json:"table_id"`
+}
+
+type SeatRequest struct {
+	TableID string `json:"table_id"`
+	OrderID string `json:"order_id"`
+}
+
+type CancelReservationRequest struct {
+	Reason string `json:"reason"`
+}
+
+type WaitlistRequest struct {
+	CustomerName      string `json:"customer_name"`
+	CustomerPhone     string `json:"customer_phone"`
+	Pax               int64  `json:"pax"`
+	QuotedWaitMinutes *int64 `json:"quoted_wait_minutes"`
+	Note              string `json:"note"`
+}
+
+func respondReservationError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrReservationNotFound), errors.Is(err, repositories.ErrWaitlistEntryNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrReservationConflict), errors.Is(err, repositories.ErrReservationClosed),
+		errors.Is(err, repositories.ErrWaitlistEntryClosed):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidReservation), errors.Is(err, repositories.ErrInvalidWaitlistEntry):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// emitTables mengabarkan perubahan reservasi dan status meja yang terlibat
+func (h *ReservationHandler) emitTables(event string, payload map[string]interface{}, tableNumbers ...string) {
+	if h.realtime == nil {
+		return
+	}
+	h.realtime.Emit(event, payload)
+
+	numbers := []string{}
+	for _, number := range tableNumbers {
+		if number != "" {
+			numbers = append(numbers, number)
+		}
+	}
+	if len(numbers) > 0 {
+		h.realtime.Emit("table_status_updated", map[string]interface{}{
+			"table_numbers": numbers,
+		})
+	}
+}
+
+func (h *ReservationHandler) emitReservation(reservation *repositories.Reservation, previousTable string) {
+	h.emitTables("reservation_updated", map[string]interface{}{
+		"reservation_id": reservation.ID,
+		"status":         reservation.Status,
+		"table_number":   reservation.TableNumber,
+	}, previousTable, reservation.TableNumber)
+}
+
+// previousTableNumber mengambil meja reservasi sebelum diubah agar meja lama ikut dikabarkan
+func (h *ReservationHandler) previousTableNumber(c *echo.Context, id string) string {
+	reservation, err := h.reservationService.GetReservation((*c).Request().Context(), id)
+	if err != nil {
+		return ""
+	}
+	return reservation.TableNumber
+}
+
+// CreateReservation - buat reservasi; pelanggan dicari per nomor HP dan didaftarkan jika belum ada
+func (h *ReservationHandler) CreateReservation(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req CreateReservationRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+	if req.ReservedAt == "" {
+		return BadRequestResponse(c, "reserved_at wajib diisi")
+	}
+	reservedAt, err := parsePriceListTime(req.ReservedAt)
+	if err != nil {
+		return BadRequestResponse(c, "Format reserved_at tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+	}
+
+	reservation, err := h.reservationService.CreateReservation((*c).Request().Context(), repositories.ReservationInput{
+		CustomerID:      req.CustomerID,
+		CustomerName:    req.CustomerName,
+		CustomerPhone:   req.CustomerPhone,
+		Pax:             req.Pax,
+		ReservedAt:      reservedAt,
+		DurationMinutes: req.DurationMinutes,
+		TableID:         req.TableID,
+		Note:            req.Note,
+		CreatedBy:       claims.UserID,
+	})
+	if err != nil {
+		return respondReservationError(c, err, "Gagal membuat reservasi")
+	}
+	h.emitReservation(reservation, "")
+	return CreatedResponse(c, "Reservasi berhasil dibuat", reservation)
+}
+
+// ListReservations - daftar reservasi per tanggal (date=YYYY-MM-DD, default hari ini)
+func (h *ReservationHandler) ListReservations(c *echo.Context) error {
+	filter := repositories.ReservationFilter{
+		Status:     c.QueryParam("status"),
+		TableID:    c.QueryParam("table_id"),
+		CustomerID: c.QueryParam("customer_id"),
+	}
+	if c.QueryParam("all") != "true" {
+		day := time.Now()
+		if dateStr := c.QueryParam("date"); dateStr != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+			if err != nil {
+				return BadRequestResponse(c, "Format date tidak valid, gunakan YYYY-MM-DD")
+			}
+			day = parsed
+		}
+		filter.From = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+		filter.To = filter.From.AddDate(0, 0, 1)
+	}
+
+	reservations, err := h.reservationService.ListReservations((*c).Request().Context(), filter)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mengambil reservasi")
+	}
+	return SuccessResponse(c, "Reservasi berhasil diambil", reservations)
+}
+
+// GetReservation - detail reservasi beserta saldo deposit yang ditahan
+func (h *ReservationHandler) GetReservation(c *echo.Context) error {
+	reservation, err := h.reservationService.GetReservation((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mengambil reservasi")
+	}
+	return SuccessResponse(c, "Reservasi berhasil diambil", reservation)
+}
+
+// UpdateReservation - ubah pax, slot, durasi, meja atau catatan reservasi yang masih booked
+func (h *ReservationHandler) UpdateReservation(c *echo.Context) error {
+	var req UpdateReservationRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	input := repositories.ReservationUpdate{
+		Pax:             req.Pax,
+		DurationMinutes: req.DurationMinutes,
+		TableID:         req.TableID,
+		Note:            req.Note,
+	}
+	if req.ReservedAt != nil {
+		reservedAt, err := parsePriceListTime(*req.ReservedAt)
+		if err != nil {
+			return BadRequestResponse(c, "Format reserved_at tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+		}
+		input.ReservedAt = &reservedAt
+	}
+
+	previousTable := h.previousTableNumber(c, c.Param("id"))
+	reservation, err := h.reservationService.UpdateReservation((*c).Request().Context(), c.Param("id"), input)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mengubah reservasi")
+	}
+	h.emitReservation(reservation, previousTable)
+	return SuccessResponse(c, "Reservasi berhasil diubah", reservation)
+}
+
+// AssignTable - tugaskan meja ke reservasi; table_id kosong = pilih meja terbaik otomatis
+func (h *ReservationHandler) AssignTable(c *echo.Context) error {
+	var req AssignReservationTableRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	previousTable := h.previousTableNumber(c, c.Param("id"))
+	reservation, err := h.reservationService.AssignTable((*c).Request().Context(), c.Param("id"), req.TableID)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal menugaskan meja")
+	}
+	h.emitReservation(reservation, previousTable)
+	return SuccessResponse(c, "Meja reservasi berhasil ditugaskan", reservation)
+}
+
+// SeatReservation - tamu reservasi datang; order_id opsional langsung ditautkan
+func (h *ReservationHandler) SeatReservation(c *echo.Context) error {
+	var req SeatRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	previousTable := h.previousTableNumber(c, c.Param("id"))
+	reservation, err := h.reservationService.SeatReservation((*c).Request().Context(), c.Param("id"), req.TableID, req.OrderID)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mendudukkan tamu reservasi")
+	}
+	h.emitReservation(reservation, previousTable)
+	return SuccessResponse(c, "Tamu reservasi berhasil didudukkan", reservation)
+}
+
+// CancelReservation - batalkan reservasi; deposit reservasi tetap bisa direfund
+func (h *ReservationHandler) CancelReservation(c *echo.Context) error {
+	var req CancelReservationRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	reservation, err := h.reservationService.CancelReservation((*c).Request().Context(), c.Param("id"), req.Reason)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal membatalkan reservasi")
+	}
+	h.emitReservation(reservation, "")
+	return SuccessResponse(c, "Reservasi berhasil dibatalkan", reservation)
+}
+
+// GetAvailability - meja yang muat dan bebas pada slot (at, pax, duration_minutes)
+func (h *ReservationHandler) GetAvailability(c *echo.Context) error {
+	atStr := c.QueryParam("at")
+	if atStr == "" {
+		return BadRequestResponse(c, "at wajib diisi")
+	}
+	at, err := parsePriceListTime(atStr)
+	if err != nil {
+		return BadRequestResponse(c, "Format at tidak valid, gunakan RFC3339 atau YYYY-MM-DD HH:MM")
+	}
+	pax, err := strconv.ParseInt(c.QueryParam("pax"), 10, 64)
+	if err != nil {
+		return BadRequestResponse(c, "pax wajib diisi dengan angka")
+	}
+	var duration int64
+	if durationStr := c.QueryParam("duration_minutes"); durationStr != "" {
+		if duration, err = strconv.ParseInt(durationStr, 10, 64); err != nil {
+			return BadRequestResponse(c, "duration_minutes harus berupa angka")
+		}
+	}
+
+	tables, err := h.reservationService.GetAvailability((*c).Request().Context(), at, duration, pax)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal memeriksa ketersediaan meja")
+	}
+	return SuccessResponse(c, "Ketersediaan meja berhasil diambil", tables)
+}
+
+// AddToWaitlist - daftarkan tamu walk-in beserta estimasi waktu tunggu
+func (h *ReservationHandler) AddToWaitlist(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	var req WaitlistRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	entry, err := h.reservationService.AddToWaitlist((*c).Request().Context(), repositories.WaitlistInput{
+		CustomerName:      req.CustomerName,
+		CustomerPhone:     req.CustomerPhone,
+		Pax:               req.Pax,
+		QuotedWaitMinutes: req.QuotedWaitMinutes,
+		Note:              req.Note,
+		CreatedBy:         claims.UserID,
+	})
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mendaftarkan antrian")
+	}
+	h.emitTables("waitlist_updated", map[string]interface{}{
+		"waitlist_id": entry.ID,
+		"status":      entry.Status,
+	})
+	return CreatedResponse(c, "Tamu berhasil masuk daftar tunggu", entry)
+}
+
+// ListWaitlist - antrian aktif (all=true untuk termasuk yang sudah duduk/pergi)
+func (h *ReservationHandler) ListWaitlist(c *echo.Context) error {
+	entries, err := h.reservationService.ListWaitlist((*c).Request().Context(), c.QueryParam("all") != "true")
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mengambil daftar tunggu")
+	}
+	return SuccessResponse(c, "Daftar tunggu berhasil diambil", entries)
+}
+
+// NotifyWaitlist - panggil tamu antrian karena meja siap
+func (h *ReservationHandler) NotifyWaitlist(c *echo.Context) error {
+	entry, err := h.reservationService.NotifyWaitlist((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondReservationError(c, err, "Gagal memanggil tamu antrian")
+	}
+	h.emitTables("waitlist_updated", map[string]interface{}{
+		"waitlist_id": entry.ID,
+		"status":      entry.Status,
+	})
+	return SuccessResponse(c, "Tamu antrian berhasil dipanggil", entry)
+}
+
+// SeatWaitlist - dudukkan tamu antrian di meja (table_id) atau meja order (order_id)
+func (h *ReservationHandler) SeatWaitlist(c *echo.Context) error {
+	var req SeatRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	entry, err := h.reservationService.SeatWaitlist((*c).Request().Context(), c.Param("id"), req.TableID, req.OrderID)
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mendudukkan tamu antrian")
+	}
+	h.emitTables("waitlist_updated", map[string]interface{}{
+		"waitlist_id":  entry.ID,
+		"status":       entry.Status,
+		"table_number": entry.TableNumber,
+	}, entry.TableNumber)
+	return SuccessResponse(c, "Tamu antrian berhasil didudukkan", entry)
+}
+
+// LeaveWaitlist - tamu batal menunggu
+func (h *ReservationHandler) LeaveWaitlist(c *echo.Context) error {
+	entry, err := h.reservationService.LeaveWaitlist((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return respondReservationError(c, err, "Gagal mengeluarkan tamu dari antrian")
+	}
+	h.emitTables("waitlist_updated", map[string]interface{}{
+		"waitlist_id": entry.ID,
+		"status":      entry.Status,
+	})
+	return SuccessResponse(c, "Tamu berhasil keluar dari daftar tunggu", entry)
+}
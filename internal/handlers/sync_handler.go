@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"backend/internal/repositories"
 	"backend/internal/services"
 	"net/http"
 	"strconv"
@@ -107,3 +108,18 @@ func (h *SyncHandler) RetrySync(c *echo.Context) error {
 		"message": "Sync retry triggered",
 	})
 }
+
+// checkManagedByHQ mengembalikan repositories.ErrManagedByHQ jika entitas dikelola HQ
+func checkManagedByHQ(c *echo.Context, syncRepo repositories.SyncRepository, entityType, entityID string) error {
+	if syncRepo == nil {
+		return nil
+	}
+	managed, err := syncRepo.IsManagedByHQ((*c).Request().Context(), entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if managed {
+		return repositories.ErrManagedByHQ
+	}
+	return nil
+}
@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"backend/internal/db"
+	"backend/internal/middleware"
+	"backend/internal/repositories"
 	"backend/internal/services"
+	"errors"
 	"math"
 	"net/http"
 	"strings"
@@ -12,17 +15,36 @@ import (
 )
 
 type TableHandler struct {
-	service services.TableService
-	queries *db.Queries
+	service  services.TableService
+	queries  *db.Queries
+	syncRepo repositories.SyncRepository
+	realtime RealtimeBroadcaster
 }
 
-func NewTableHandler(service services.TableService, queries *db.Queries) *TableHandler {
+func NewTableHandler(service services.TableService, queries *db.Queries, syncRepo repositories.SyncRepository, realtime RealtimeBroadcaster) *TableHandler {
 	return &TableHandler{
-		service: service,
-		queries: queries,
+		service:  service,
+		queries:  queries,
+		syncRepo: syncRepo,
+		realtime: realtime,
 	}
 }
 
+// rejectHQManagedTable mengunci layout meja yang dikelola HQ (status tetap lokal)
+func (h *TableHandler) rejectHQManagedTable(c *echo.Context, id string) (bool, error) {
+	if err := checkManagedByHQ(c, h.syncRepo, "table", id); err != nil {
+		if errors.Is(err, repositories.ErrManagedByHQ) {
+			return true, c.JSON(http.StatusForbidden, map[string]string{
+				"error": "Meja dikelola oleh HQ dan tidak dapat diubah",
+			})
+		}
+		return true, c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "Gagal cek data HQ: " + err.Error(),
+		})
+	}
+	return false, nil
+}
+
 type CreateTableRequest struct {
 	TableNumber string `json:"table_number"`
 	Capacity    int64  `json:"capacity"`
@@ -37,6 +59,50 @@ type UpdateTableStatusRequest struct {
 	Status string `json:"status"`
 }
 
+type UpdateTableStatusRulesRequest struct {
+	Transitions []repositories.TableStatusTransition `json:"transitions"`
+}
+
+func respondTableStatusError(c *echo.Context, err error) error {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, repositories.ErrTableNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, repositories.ErrInvalidTableStatus):
+		status = http.StatusBadRequest
+	case errors.Is(err, repositories.ErrTableTransitionForbidden):
+		status = http.StatusForbidden
+	case errors.Is(err, repositories.ErrTableTransitionNotAllowed), errors.Is(err, repositories.ErrTableHasOpenOrders):
+		status = http.StatusConflict
+	default:
+		return c.JSON(status, map[string]string{
+			"error": "Gagal update status meja: " + err.Error(),
+		})
+	}
+	return c.JSON(status, map[string]string{
+		"error": err.Error(),
+	})
+}
+
+// emitTableStatus mengabarkan perubahan status meja; table_ready dikirim saat meja
+// selesai dibersihkan supaya host tahu meja benar-benar siap dipakai
+func (h *TableHandler) emitTableStatus(change *repositories.TableStatusChange, changedBy string) {
+	if h.realtime == nil {
+		return
+	}
+	h.realtime.Emit("table_status_updated", map[string]interface{}{
+		"table_numbers":   []string{change.TableNumber},
+		"status":          change.To,
+		"previous_status": change.From,
+		"changed_by":      changedBy,
+	})
+	if change.To == repositories.TableStatusAvailable && change.From == repositories.TableStatusDirty {
+		h.realtime.Emit("table_ready", map[string]interface{}{
+			"table_number": change.TableNumber,
+		})
+	}
+}
+
 func (h *TableHandler) CreateTable(c *echo.Context) error {
 	var req CreateTableRequest
 	if err := (*c).Bind(&req); err != nil {
@@ -93,12 +159,12 @@ func (h *TableHandler) GetAllTables(c *echo.Context) error {
 		})
 	}
 
-	// Apply filters
+	// Apply filters; status=occupied berarti semua meja yang masih ditempati tamu
 	filtered := allTables
 	if status != "" {
 		var temp []db.Table
 		for _, t := range filtered {
-			if t.Status == status {
+			if t.Status == status || (status == repositories.TableStatusOccupied && repositories.IsTableInUse(t.Status)) {
 				temp = append(temp, t)
 			}
 		}
@@ -230,22 +296,19 @@ func (h *TableHandler) GetAllTables(c *echo.Context) error {
 		// If error (no active order), ActiveOrder stays nil
 	}
 
-	// Calculate stats
+	// Calculate stats; occupied = semua meja yang masih ditempati tamu
 	stats := map[string]int64{
-		"total":     total,
-		"available": 0,
-		"occupied":  0,
-		"reserved":  0,
+		"total":    total,
+		"occupied": 0,
+	}
+	for _, status := range repositories.TableStatuses {
+		stats[status] = 0
 	}
 
 	for _, t := range filtered {
-		switch t.Status {
-		case "available":
-			stats["available"]++
-		case "occupied":
+		stats[t.Status]++
+		if repositories.IsTableInUse(t.Status) {
 			stats["occupied"]++
-		case "reserved":
-			stats["reserved"]++
 		}
 	}
 
@@ -307,6 +370,10 @@ func (h *TableHandler) UpdateTable(c *echo.Context) error {
 		})
 	}
 
+	if rejected, err := h.rejectHQManagedTable(c, id); rejected {
+		return err
+	}
+
 	if err := h.service.UpdateTable((*c).Request().Context(), id, req.TableNumber, req.Capacity); err != nil {
 		// Check if it's a unique constraint error
 		if strings.Contains(err.Error(), "UNIQUE constraint failed") || strings.Contains(err.Error(), "table_number") {
@@ -324,6 +391,8 @@ func (h *TableHandler) UpdateTable(c *echo.Context) error {
 	})
 }
 
+// UpdateTableStatus - pindah status meja manual sesuai aturan transisi per role.
+// Status lama "occupied" tetap diterima sebagai tanda tamu mulai duduk.
 func (h *TableHandler) UpdateTableStatus(c *echo.Context) error {
 	tableNumber := c.Param("number")
 
@@ -333,33 +402,79 @@ func (h *TableHandler) UpdateTableStatus(c *echo.Context) error {
 			"error": "Body request tidak valid",
 		})
 	}
-
-	validStatuses := map[string]bool{
-		"available": true,
-		"occupied":  true,
-		"reserved":  true,
+	if req.Status == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "status wajib diisi",
+		})
 	}
 
-	if !validStatuses[req.Status] {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "status harus: available, occupied, atau reserved",
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return c.JSON(http.StatusUnauthorized, map[string]string{
+			"error": "User tidak terautentikasi",
 		})
 	}
 
-	if err := h.service.UpdateTableStatus((*c).Request().Context(), tableNumber, req.Status); err != nil {
+	change, err := h.service.UpdateTableStatus((*c).Request().Context(), tableNumber, req.Status, claims.Role)
+	if err != nil {
+		return respondTableStatusError(c, err)
+	}
+	if change.Changed {
+		h.emitTableStatus(change, claims.UserID)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message": "Status meja berhasil diupdate",
+		"data":    change,
+	})
+}
+
+// GetTableStatusRules - daftar status meja dan transisi yang diizinkan per role
+func (h *TableHandler) GetTableStatusRules(c *echo.Context) error {
+	transitions, err := h.service.GetStatusTransitions((*c).Request().Context())
+	if err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Gagal update status meja: " + err.Error(),
+			"error": "Gagal mengambil aturan status meja: " + err.Error(),
 		})
 	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"statuses":    repositories.TableStatuses,
+		"transitions": transitions,
+	})
+}
 
-	return c.JSON(http.StatusOK, map[string]string{
-		"message": "Status meja berhasil diupdate",
+// UpdateTableStatusRules - ganti seluruh aturan transisi status meja
+func (h *TableHandler) UpdateTableStatusRules(c *echo.Context) error {
+	var req UpdateTableStatusRulesRequest
+	if err := (*c).Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "Body request tidak valid",
+		})
+	}
+	if len(req.Transitions) == 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "transitions tidak boleh kosong",
+		})
+	}
+
+	transitions, err := h.service.UpdateStatusTransitions((*c).Request().Context(), req.Transitions)
+	if err != nil {
+		return respondTableStatusError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"message":     "Aturan status meja berhasil diupdate",
+		"statuses":    repositories.TableStatuses,
+		"transitions": transitions,
 	})
 }
 
 func (h *TableHandler) DeleteTable(c *echo.Context) error {
 	id := c.Param("id")
 
+	if rejected, err := h.rejectHQManagedTable(c, id); rejected {
+		return err
+	}
+
 	if err := h.service.DeleteTable((*c).Request().Context(), id); err != nil {
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "Gagal hapus meja: " + err.Error(),
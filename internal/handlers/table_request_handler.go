@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+	"strconv"
+
+	"github.com/labstack/echo/v5"
+)
+
+// TableRequestHandler melayani permintaan dari meja (minta bill, panggil pelayan, minta air)
+// dari tamu lewat QR meja maupun dari staf/perangkat meja
+type TableRequestHandler struct {
+	tableRequestService services.TableRequestService
+	guestOrderService   services.GuestOrderService
+	realtime            RealtimeBroadcaster
+}
+
+func NewTableRequestHandler(tableRequestService services.TableRequestService, guestOrderService services.GuestOrderService, realtime RealtimeBroadcaster) *TableRequestHandler {
+	return &TableRequestHandler{
+		tableRequestService: tableRequestService,
+		guestOrderService:   guestOrderService,
+		realtime:            realtime,
+	}
+}
+
+type GuestTableRequestBody struct {
+	Type string `json:"type"`
+	Note string `json:"note"`
+}
+
+type CreateTableRequestBody struct {
+	TableNumber string `json:"table_number"`
+	Type        string `json:"type"`
+	Note        string `json:"note"`
+}
+
+func respondTableRequestError(c *echo.Context, err error, fallback string) error {
+	switch {
+	case errors.Is(err, repositories.ErrInvalidGuestToken):
+		return UnauthorizedResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrTableRequestNotFound), errors.Is(err, repositories.ErrTableNotFound):
+		return NotFoundResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrTableRequestResolved):
+		return ConflictResponse(c, err.Error())
+	case errors.Is(err, repositories.ErrInvalidTableRequest):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, fallback+": "+err.Error())
+	}
+}
+
+// toGuestTableRequestResponse hanya menampilkan data yang perlu dilihat tamu
+func toGuestTableRequestResponse(request *repositories.TableRequest) map[string]interface{} {
+	return map[string]interface{}{
+		"id":              request.ID,
+		"table_number":    request.TableNumber,
+		"request_type":    request.RequestType,
+		"status":          request.Status,
+		"created_at":      request.CreatedAt,
+		"acknowledged_at": request.AcknowledgedAt,
+		"resolved_at":     request.ResolvedAt,
+	}
+}
+
+// emitTableRequest mengabarkan permintaan meja ke semua perangkat; waiter menyaring
+// berdasarkan section_id/waiter_ids (kosong = meja tanpa section, untuk semua waiter)
+func (h *TableRequestHandler) emitTableRequest(event string, request *repositories.TableRequest) {
+	if h.realtime == nil {
+		return
+	}
+	h.realtime.Emit(event, map[string]interface{}{
+		"request_id":   request.ID,
+		"table_number": request.TableNumber,
+		"request_type": request.RequestType,
+		"source":       request.Source,
+		"status":       request.Status,
+		"section_id":   request.SectionID,
+		"section_name": request.SectionName,
+		"waiter_ids":   request.WaiterIDs,
+		"created_at":   request.CreatedAt,
+	})
+	if event == "table_request_created" && request.RequestType == repositories.TableRequestBill {
+		h.realtime.Emit("table_status_updated", map[string]interface{}{
+			"table_numbers": []string{request.TableNumber},
+		})
+	}
+}
+
+// CreateGuestTableRequest - tamu memanggil pelayan/minta bill/minta air dari QR meja (publik)
+func (h *TableRequestHandler) CreateGuestTableRequest(c *echo.Context) error {
+	var req GuestTableRequestBody
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	ctx := (*c).Request().Context()
+	table, err := h.guestOrderService.ResolveTable(ctx, c.Param("token"))
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal membaca QR meja")
+	}
+
+	request, created, err := h.tableRequestService.CreateRequest(ctx, repositories.TableRequestInput{
+		TableNumber: table.TableNumber,
+		RequestType: req.Type,
+		Source:      repositories.TableRequestSourceGuest,
+		Note:        req.Note,
+		ClientIP:    c.RealIP(),
+	})
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal mengirim permintaan")
+	}
+
+	if !created {
+		return SuccessResponse(c, "Permintaan sebelumnya masih diproses pelayan", toGuestTableRequestResponse(request))
+	}
+	h.emitTableRequest("table_request_created", request)
+	return CreatedResponse(c, "Permintaan dikirim ke pelayan", toGuestTableRequestResponse(request))
+}
+
+// GetGuestTableRequest - tamu memantau permintaannya (publik)
+func (h *TableRequestHandler) GetGuestTableRequest(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+	table, err := h.guestOrderService.ResolveTable(ctx, c.Param("token"))
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal membaca QR meja")
+	}
+	request, err := h.tableRequestService.GetRequest(ctx, c.Param("id"))
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal mengambil permintaan")
+	}
+	// Tamu hanya boleh melihat permintaan dari mejanya sendiri
+	if request.TableNumber != table.TableNumber {
+		return NotFoundResponse(c, repositories.ErrTableRequestNotFound.Error())
+	}
+	return SuccessResponse(c, "Permintaan berhasil diambil", toGuestTableRequestResponse(request))
+}
+
+// CreateTableRequest - staf atau perangkat meja mencatat permintaan untuk meja tertentu
+func (h *TableRequestHandler) CreateTableRequest(c *echo.Context) error {
+	var req CreateTableRequestBody
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	createdBy := ""
+	if claims, err := middleware.GetUserFromContext(c); err == nil {
+		createdBy = claims.UserID
+	}
+
+	request, created, err := h.tableRequestService.CreateRequest((*c).Request().Context(), repositories.TableRequestInput{
+		TableNumber: req.TableNumber,
+		RequestType: req.Type,
+		Source:      repositories.TableRequestSourceStaff,
+		Note:        req.Note,
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal membuat permintaan meja")
+	}
+
+	if !created {
+		return SuccessResponse(c, "Permintaan yang sama masih terbuka", request)
+	}
+	h.emitTableRequest("table_request_created", request)
+	return CreatedResponse(c, "Permintaan meja berhasil dibuat", request)
+}
+
+// ListTableRequests - antrian permintaan meja, terlama di atas.
+// ?mine=true hanya menampilkan meja di section pelayan yang login (dan meja tanpa section).
+func (h *TableRequestHandler) ListTableRequests(c *echo.Context) error {
+	filter := repositories.TableRequestFilter{
+		Status:      c.QueryParam("status"),
+		TableNumber: c.QueryParam("table_number"),
+		SectionID:   c.QueryParam("section_id"),
+	}
+	if mine, _ := strconv.ParseBool(c.QueryParam("mine")); mine {
+		claims, err := middleware.GetUserFromContext(c)
+		if err != nil {
+			return UnauthorizedResponse(c, "User tidak terautentikasi")
+		}
+		filter.WaiterID = claims.UserID
+	}
+
+	requests, err := h.tableRequestService.ListRequests((*c).Request().Context(), filter)
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal mengambil permintaan meja")
+	}
+	return SuccessResponse(c, "Permintaan meja berhasil diambil", requests)
+}
+
+// AcknowledgeTableRequest - staf menandai permintaan sedang ditangani
+func (h *TableRequestHandler) AcknowledgeTableRequest(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	request, err := h.tableRequestService.AcknowledgeRequest((*c).Request().Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal menanggapi permintaan meja")
+	}
+	h.emitTableRequest("table_request_updated", request)
+	return SuccessResponse(c, "Permintaan meja sedang ditangani", request)
+}
+
+// ResolveTableRequest - staf menandai permintaan selesai
+func (h *TableRequestHandler) ResolveTableRequest(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
+	request, err := h.tableRequestService.ResolveRequest((*c).Request().Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		return respondTableRequestError(c, err, "Gagal menyelesaikan permintaan meja")
+	}
+	h.emitTableRequest("table_request_updated", request)
+	return SuccessResponse(c, "Permintaan meja selesai", request)
+}
+
+// GetTableRequestAnalytics - waktu respon permintaan meja per jenis dan per staf (default 7 hari)
+func (h *TableRequestHandler) GetTableRequestAnalytics(c *echo.Context) error {
+	from, to, err := parseSessionDateRange(c, 7)
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+
+	analytics, err := h.tableRequestService.GetAnalytics((*c).Request().Context(), from, to)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil analitik permintaan meja: "+err.Error())
+	}
+	return SuccessResponse(c, "Analitik permintaan meja berhasil diambil", analytics)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+// TableSessionHandler menampilkan log sesi meja, timer meja aktif dan analitik turn time
+type TableSessionHandler struct {
+	tableSessionService services.TableSessionService
+}
+
+func NewTableSessionHandler(tableSessionService services.TableSessionService) *TableSessionHandler {
+	return &TableSessionHandler{tableSessionService: tableSessionService}
+}
+
+// parseSessionDateRange membaca start_date/end_date (YYYY-MM-DD, waktu lokal).
+// end_date inklusif; default defaultDays hari terakhir sampai hari ini.
+func parseSessionDateRange(c *echo.Context, defaultDays int) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if raw := c.QueryParam("end_date"); raw != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("format end_date tidak valid, gunakan YYYY-MM-DD")
+		}
+		end = parsed
+	}
+	start := end.AddDate(0, 0, -(defaultDays - 1))
+	if raw := c.QueryParam("start_date"); raw != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("format start_date tidak valid, gunakan YYYY-MM-DD")
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, errors.New("start_date tidak boleh setelah end_date")
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+// GetTableTimers - timer meja yang sedang terisi untuk layar waiter/kasir
+func (h *TableSessionHandler) GetTableTimers(c *echo.Context) error {
+	timers, err := h.tableSessionService.GetTimers((*c).Request().Context())
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil timer meja: "+err.Error())
+	}
+	return SuccessResponse(c, "Timer meja berhasil diambil", timers)
+}
+
+// ListTableSessions - log sesi meja per rentang tanggal duduk (default hari ini)
+func (h *TableSessionHandler) ListTableSessions(c *echo.Context) error {
+	status := c.QueryParam("status")
+	switch status {
+	case "", repositories.TableSessionOpen, repositories.TableSessionClosed:
+	default:
+		return BadRequestResponse(c, "status harus open atau closed")
+	}
+
+	from, to, err := parseSessionDateRange(c, 1)
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+
+	sessions, err := h.tableSessionService.ListSessions((*c).Request().Context(), repositories.TableSessionFilter{
+		From:        from,
+		To:          to,
+		TableNumber: c.QueryParam("table_number"),
+		Status:      status,
+	})
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil sesi meja: "+err.Error())
+	}
+	return SuccessResponse(c, "Sesi meja berhasil diambil", sessions)
+}
+
+// GetTableSession - detail sesi meja beserta riwayat event
+func (h *TableSessionHandler) GetTableSession(c *echo.Context) error {
+	session, err := h.tableSessionService.GetSession((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrTableSessionNotFound) {
+			return NotFoundResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal mengambil sesi meja: "+err.Error())
+	}
+	return SuccessResponse(c, "Sesi meja berhasil diambil", session)
+}
+
+// GetTableAnalytics - turn time, dwell time per pax/area dan covers per meja per hari (default 7 hari)
+func (h *TableSessionHandler) GetTableAnalytics(c *echo.Context) error {
+	from, to, err := parseSessionDateRange(c, 7)
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+
+	analytics, err := h.tableSessionService.GetAnalytics((*c).Request().Context(), from, to)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil analitik meja: "+err.Error())
+	}
+	return SuccessResponse(c, "Analitik meja berhasil diambil", analytics)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"errors"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+type TaxHandler struct {
+	taxService services.TaxService
+}
+
+func NewTaxHandler(taxService services.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+type TaxRateRequest struct {
+	Name                 string   `json:"name"`
+	Rate                 float64  `json:"rate"`
+	IsInclusive          bool     `json:"is_inclusive"`
+	IncludeServiceCharge *bool    `json:"include_service_charge"`
+	IsDefault            bool     `json:"is_default"`
+	IsActive             *bool    `json:"is_active"`
+	SortOrder            int64    `json:"sort_order"`
+	CategoryIDs          []string `json:"category_ids"`
+	ProductIDs           []string `json:"product_ids"`
+}
+
+func (req TaxRateRequest) toTaxRate() *repositories.TaxRate {
+	includeService := true
+	if req.IncludeServiceCharge != nil {
+		includeService = *req.IncludeServiceCharge
+	}
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	return &repositories.TaxRate{
+		Name:                 req.Name,
+		Rate:                 req.Rate,
+		IsInclusive:          req.IsInclusive,
+		IncludeServiceCharge: includeService,
+		IsDefault:            req.IsDefault,
+		IsActive:             isActive,
+		SortOrder:            req.SortOrder,
+		CategoryIDs:          req.CategoryIDs,
+		ProductIDs:           req.ProductIDs,
+	}
+}
+
+func (h *TaxHandler) ListTaxRates(c *echo.Context) error {
+	rates, err := h.taxService.ListTaxRates((*c).Request().Context())
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil tarif pajak: "+err.Error())
+	}
+	return SuccessResponse(c, "Data tarif pajak berhasil diambil", rates)
+}
+
+func (h *TaxHandler) GetTaxRate(c *echo.Context) error {
+	rate, err := h.taxService.GetTaxRate((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrTaxRateNotFound) {
+			return NotFoundResponse(c, "Tarif pajak tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil tarif pajak: "+err.Error())
+	}
+	return SuccessResponse(c, "Tarif pajak berhasil diambil", rate)
+}
+
+func (h *TaxHandler) CreateTaxRate(c *echo.Context) error {
+	var req TaxRateRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	rate := req.toTaxRate()
+	if err := h.taxService.CreateTaxRate((*c).Request().Context(), rate); err != nil {
+		if errors.Is(err, repositories.ErrInvalidTaxRate) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat tarif pajak: "+err.Error())
+	}
+
+	created, err := h.taxService.GetTaxRate((*c).Request().Context(), rate.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil tarif pajak: "+err.Error())
+	}
+	return CreatedResponse(c, "Tarif pajak berhasil dibuat", created)
+}
+
+func (h *TaxHandler) UpdateTaxRate(c *echo.Context) error {
+	var req TaxRateRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	rate := req.toTaxRate()
+	rate.ID = c.Param("id")
+	if err := h.taxService.UpdateTaxRate((*c).Request().Context(), rate); err != nil {
+		if errors.Is(err, repositories.ErrTaxRateNotFound) {
+			return NotFoundResponse(c, "Tarif pajak tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidTaxRate) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal update tarif pajak: "+err.Error())
+	}
+
+	updated, err := h.taxService.GetTaxRate((*c).Request().Context(), rate.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil tarif pajak: "+err.Error())
+	}
+	return SuccessResponse(c, "Tarif pajak berhasil diupdate", updated)
+}
+
+func (h *TaxHandler) DeleteTaxRate(c *echo.Context) error {
+	if err := h.taxService.DeleteTaxRate((*c).Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repositories.ErrTaxRateNotFound) {
+			return NotFoundResponse(c, "Tarif pajak tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal menghapus tarif pajak: "+err.Error())
+	}
+	return SuccessResponse(c, "Tarif pajak berhasil dihapus", nil)
+}
+
+// GetTaxReport merekap pajak untuk pelaporan bulanan (?month=YYYY-MM) atau rentang tanggal
+func (h *TaxHandler) GetTaxReport(c *echo.Context) error {
+	var startDate, endDate time.Time
+	if month := c.QueryParam("month"); month != "" {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			return BadRequestResponse(c, "Format month tidak valid, gunakan YYYY-MM")
+		}
+		startDate = parsed
+		endDate = parsed.AddDate(0, 1, 0).Add(-time.Second)
+	} else {
+		var err error
+		startDate, endDate, err = parseDateRangeWithLimit(c.QueryParam("start_date"), c.QueryParam("end_date"), 3)
+		if err != nil {
+			return BadRequestResponse(c, err.Error())
+		}
+	}
+
+	lines, days, err := h.taxService.GetTaxReport((*c).Request().Context(), startDate, endDate)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil laporan pajak: "+err.Error())
+	}
+
+	var taxableTotal, taxTotal float64
+	for _, line := range lines {
+		taxableTotal += line.TaxableAmount
+		taxTotal += line.TaxAmount
+	}
+
+	return SuccessResponse(c, "Laporan pajak berhasil diambil", map[string]interface{}{
+		"start_date":    startDate.Format("2006-01-02"),
+		"end_date":      endDate.Format("2006-01-02"),
+		"taxable_total": taxableTotal,
+		"tax_total":     taxTotal,
+		"rates":         lines,
+		"daily":         days,
+	})
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"backend/internal/db"
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/labstack/echo/v5"
+)
+
+type TerminalHandler struct {
+	terminalService services.TerminalService
+}
+
+func NewTerminalHandler(terminalService services.TerminalService) *TerminalHandler {
+	return &TerminalHandler{
+		terminalService: terminalService,
+	}
+}
+
+type TerminalRequest struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	DeviceID  *string `json:"device_id"`
+	PrinterID *string `json:"printer_id"`
+	IsActive  *bool   `json:"is_active"`
+}
+
+func (req TerminalRequest) toTerminal() *repositories.Terminal {
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	return &repositories.Terminal{
+		Code:      req.Code,
+		Name:      req.Name,
+		DeviceID:  req.DeviceID,
+		PrinterID: req.PrinterID,
+		IsActive:  isActive,
+	}
+}
+
+// terminalRef mengambil terminal kasir dari header X-Terminal-ID atau query terminal_id
+func terminalRef(c *echo.Context) string {
+	if ref := (*c).Request().Header.Get(repositories.TerminalHeader); ref != "" {
+		return ref
+	}
+	return c.QueryParam("terminal_id")
+}
+
+// resolveTerminalShift mencari terminal request beserta shift yang terbuka di sana.
+// Error sql.ErrNoRows berarti shift terminal belum dibuka.
+func resolveTerminalShift(c *echo.Context, q db.DBTX) (*repositories.Terminal, string, error) {
+	ctx := (*c).Request().Context()
+	terminal, err := repositories.ResolveTerminal(ctx, q, terminalRef(c))
+	if err != nil {
+		return nil, "", err
+	}
+	shiftID, err := repositories.GetOpenShiftID(ctx, q, terminal.ID)
+	return terminal, shiftID, err
+}
+
+// optionalTerminalShiftID dipakai untuk aksi yang boleh dilakukan di luar shift
+// (mis. void oleh manager dari back office); kosong jika tidak ada shift terbuka.
+func optionalTerminalShiftID(c *echo.Context, q db.DBTX) string {
+	_, shiftID, err := resolveTerminalShift(c, q)
+	if err != nil {
+		return ""
+	}
+	return shiftID
+}
+
+// respondShiftError memetakan error terminal/shift kasir ke response
+func respondShiftError(c *echo.Context, err error) error {
+	switch {
+	case err == sql.ErrNoRows:
+		return BadRequestResponse(c, "Shift kasir belum dibuka")
+	case errors.Is(err, repositories.ErrTerminalRequired), errors.Is(err, repositories.ErrTerminalNotFound):
+		return BadRequestResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+	}
+}
+
+// terminalPrinterID mengembalikan printer struk milik terminal (jika diatur)
+func terminalPrinterID(ctx context.Context, q db.DBTX, terminalID string) (string, bool) {
+	var printerID sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT printer_id FROM cashier_terminals WHERE id = ?", terminalID).Scan(&printerID)
+	if err != nil || !printerID.Valid {
+		return "", false
+	}
+	return printerID.String, true
+}
+
+// ListTerminals - ?active=true untuk daftar terminal yang bisa dipilih kasir
+func (h *TerminalHandler) ListTerminals(c *echo.Context) error {
+	activeOnly := c.QueryParam("active") == "true" || c.QueryParam("active") == "1"
+	terminals, err := h.terminalService.ListTerminals((*c).Request().Context(), activeOnly)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil terminal kasir: "+err.Error())
+	}
+	return SuccessResponse(c, "Data terminal kasir berhasil diambil", terminals)
+}
+
+func (h *TerminalHandler) GetTerminal(c *echo.Context) error {
+	terminal, err := h.terminalService.GetTerminal((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, repositories.ErrTerminalNotFound) {
+			return NotFoundResponse(c, "Terminal kasir tidak ditemukan")
+		}
+		return InternalErrorResponse(c, "Gagal mengambil terminal kasir: "+err.Error())
+	}
+	return SuccessResponse(c, "Terminal kasir berhasil diambil", terminal)
+}
+
+func (h *TerminalHandler) CreateTerminal(c *echo.Context) error {
+	var req TerminalRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	terminal := req.toTerminal()
+	if err := h.terminalService.CreateTerminal((*c).Request().Context(), terminal); err != nil {
+		if errors.Is(err, repositories.ErrInvalidTerminal) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal membuat terminal kasir: "+err.Error())
+	}
+
+	created, err := h.terminalService.GetTerminal((*c).Request().Context(), terminal.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil terminal kasir: "+err.Error())
+	}
+	return CreatedResponse(c, "Terminal kasir berhasil dibuat", created)
+}
+
+func (h *TerminalHandler) UpdateTerminal(c *echo.Context) error {
+	var req TerminalRequest
+	if err := (*c).Bind(&req); err != nil {
+		return BadRequestResponse(c, "Body request tidak valid")
+	}
+
+	terminal := req.toTerminal()
+	terminal.ID = c.Param("id")
+	if err := h.terminalService.UpdateTerminal((*c).Request().Context(), terminal); err != nil {
+		if errors.Is(err, repositories.ErrTerminalNotFound) {
+			return NotFoundResponse(c, "Terminal kasir tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrInvalidTerminal) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal update terminal kasir: "+err.Error())
+	}
+
+	updated, err := h.terminalService.GetTerminal((*c).Request().Context(), terminal.ID)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil terminal kasir: "+err.Error())
+	}
+	return SuccessResponse(c, "Terminal kasir berhasil diupdate", updated)
+}
+
+func (h *TerminalHandler) DeleteTerminal(c *echo.Context) error {
+	if err := h.terminalService.DeleteTerminal((*c).Request().Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repositories.ErrTerminalNotFound) {
+			return NotFoundResponse(c, "Terminal kasir tidak ditemukan")
+		}
+		if errors.Is(err, repositories.ErrTerminalInUse) {
+			return BadRequestResponse(c, err.Error())
+		}
+		return InternalErrorResponse(c, "Gagal menghapus terminal kasir: "+err.Error())
+	}
+	return SuccessResponse(c, "Terminal kasir berhasil dihapus", nil)
+}
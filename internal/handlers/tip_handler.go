@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"time"
+
+	"github.com/labstack/echo/v5"
+)
+
+type TipHandler struct {
+	tipService services.TipService
+}
+
+func NewTipHandler(tipService services.TipService) *TipHandler {
+	return &TipHandler{
+		tipService: tipService,
+	}
+}
+
+// GetOrderTips - tip yang tercatat pada sebuah order
+func (h *TipHandler) GetOrderTips(c *echo.Context) error {
+	tips, err := h.tipService.GetOrderTips((*c).Request().Context(), c.Param("id"))
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil data tip: "+err.Error())
+	}
+	return SuccessResponse(c, "Data tip berhasil diambil", tips)
+}
+
+// GetTipReport - rekap tip per staff dan per shift untuk payout.
+// Gunakan shift_id untuk rekap satu shift, atau start_date/end_date.
+func (h *TipHandler) GetTipReport(c *echo.Context) error {
+	ctx := (*c).Request().Context()
+
+	if shiftID := c.QueryParam("shift_id"); shiftID != "" {
+		staff, err := h.tipService.GetStaffReport(ctx, time.Time{}, time.Time{}, shiftID)
+		if err != nil {
+			return InternalErrorResponse(c, "Gagal mengambil laporan tip: "+err.Error())
+		}
+		return SuccessResponse(c, "Laporan tip berhasil diambil", map[string]interface{}{
+			"shift_id":  shiftID,
+			"tip_total": sumTipStaff(staff),
+			"staff":     staff,
+		})
+	}
+
+	startDate, endDate, err := parseDateRangeWithLimit(c.QueryParam("start_date"), c.QueryParam("end_date"), 3)
+	if err != nil {
+		return BadRequestResponse(c, err.Error())
+	}
+	staff, err := h.tipService.GetStaffReport(ctx, startDate, endDate, "")
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil laporan tip: "+err.Error())
+	}
+	shifts, err := h.tipService.GetShiftReport(ctx, startDate, endDate)
+	if err != nil {
+		return InternalErrorResponse(c, "Gagal mengambil laporan tip: "+err.Error())
+	}
+
+	return SuccessResponse(c, "Laporan tip berhasil diambil", map[string]interface{}{
+		"start_date": startDate.Format("2006-01-02"),
+		"end_date":   endDate.Format("2006-01-02"),
+		"tip_total":  sumTipStaff(staff),
+		"staff":      staff,
+		"shifts":     shifts,
+	})
+}
+
+func sumTipStaff(lines []repositories.TipStaffSummary) float64 {
+	total := 0.0
+	for _, line := range lines {
+		total += line.Amount
+	}
+	return total
+}
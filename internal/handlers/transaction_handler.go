@@ -11,8 +11,11 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
 	"math"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
@@ -24,13 +27,22 @@ type TransactionHandler struct {
 	transactionService services.TransactionService
 	queries            *db.Queries
 	db                 *sql.DB
+	shiftSettings      ShiftCloseSettings
 }
 
-func NewTransactionHandler(transactionService services.TransactionService, queries *db.Queries, sqlDB *sql.DB) *TransactionHandler {
+// ShiftCloseSettings mengatur tutup shift: blind close menyembunyikan ekspektasi kas
+// dari kasir, selisih kas di atas VarianceThreshold wajib disetujui manager.
+type ShiftCloseSettings struct {
+	BlindClose        bool
+	VarianceThreshold float64
+}
+
+func NewTransactionHandler(transactionService services.TransactionService, queries *db.Queries, sqlDB *sql.DB, shiftSettings ShiftCloseSettings) *TransactionHandler {
 	return &TransactionHandler{
 		transactionService: transactionService,
 		queries:            queries,
 		db:                 sqlDB,
+		shiftSettings:      shiftSettings,
 	}
 }
 
@@ -45,23 +57,70 @@ type OpenCashierShiftRequest struct {
 	OpeningCash *float64 `json:"opening_cash"`
 }
 
+// CashDenominationCount adalah jumlah lembar/keping untuk satu pecahan uang
+type CashDenominationCount struct {
+	Denomination int     `json:"denomination"`
+	Qty          int     `json:"qty"`
+	Amount       float64 `json:"amount"`
+}
+
 type CloseCashierShiftRequest struct {
-	ClosingCash     float64 `json:"closing_cash"`
-	ClosingCard     float64 `json:"closing_card"`
-	ClosingQris     float64 `json:"closing_qris"`
-	ClosingTransfer float64 `json:"closing_transfer"`
+	ClosingCash     float64                 `json:"closing_cash"`
+	ClosingCard     float64                 `json:"closing_card"`
+	ClosingQris     float64                 `json:"closing_qris"`
+	ClosingTransfer float64                 `json:"closing_transfer"`
+	Denominations   []CashDenominationCount `json:"denominations"`
+	ManagerPIN      string                  `json:"manager_pin"`
+	VarianceNote    string                  `json:"variance_note"`
 }
 
 type HandoverCashierShiftRequest struct {
-	NextCashierID     string  `json:"next_cashier_id"`
-	CurrentCashierPIN string  `json:"current_cashier_pin"`
-	NextCashierPIN    string  `json:"next_cashier_pin"`
-	ClosingCash       float64 `json:"closing_cash"`
-	ClosingCard       float64 `json:"closing_card"`
-	ClosingQris       float64 `json:"closing_qris"`
-	ClosingTransfer   float64 `json:"closing_transfer"`
+	NextCashierID     string                  `json:"next_cashier_id"`
+	CurrentCashierPIN string                  `json:"current_cashier_pin"`
+	NextCashierPIN    string                  `json:"next_cashier_pin"`
+	ClosingCash       float64                 `json:"closing_cash"`
+	ClosingCard       float64                 `json:"closing_card"`
+	ClosingQris       float64                 `json:"closing_qris"`
+	ClosingTransfer   float64                 `json:"closing_transfer"`
+	Denominations     []CashDenominationCount `json:"denominations"`
+	ManagerPIN        string                  `json:"manager_pin"`
+	VarianceNote      string                  `json:"variance_note"`
+}
+
+// shiftCashCount adalah hasil hitung kas fisik dibanding ekspektasi laci
+type shiftCashCount struct {
+	ExpectedCash     float64                 `json:"expected_cash"`
+	CountedCash      float64                 `json:"counted_cash"`
+	Variance         float64                 `json:"variance"`
+	BlindClose       bool                    `json:"blind_close"`
+	Denominations    []CashDenominationCount `json:"denominations"`
+	ApprovedBy       *string                 `json:"approved_by"`
+	ApprovedByName   string                  `json:"approved_by_name,omitempty"`
+	VarianceNote     string                  `json:"variance_note,omitempty"`
+	RequiresApproval bool                    `json:"requires_approval"`
+}
+
+// blindCashCount adalah hasil hitung kas yang boleh dilihat kasir saat blind close:
+// tanpa ekspektasi dan selisih agar kasir tidak bisa menyesuaikan hitungan
+type blindCashCount struct {
+	CountedCash      float64                 `json:"counted_cash"`
+	BlindClose       bool                    `json:"blind_close"`
+	Denominations    []CashDenominationCount `json:"denominations"`
+	RequiresApproval bool                    `json:"requires_approval"`
 }
 
+var (
+	cashDenominations = map[int]bool{
+		100000: true, 50000: true, 20000: true, 10000: true, 5000: true,
+		2000: true, 1000: true, 500: true, 200: true, 100: true,
+	}
+
+	errCashCountRequired        = errors.New("hitung uang tunai per pecahan wajib diisi")
+	errInvalidCashCount         = errors.New("hitungan uang tunai tidak valid")
+	errVarianceApprovalRequired = errors.New("selisih kas melebihi batas, perlu persetujuan manager")
+	errInvalidManagerPIN        = errors.New("PIN manager salah")
+)
+
 type CreateCashMovementRequest struct {
 	Type   string  `json:"type"`
 	Name   string  `json:"name"`
@@ -69,12 +128,35 @@ type CreateCashMovementRequest struct {
 	Amount float64 `json:"amount"`
 }
 
+// shiftMethodSummary adalah total penjualan shift per metode pembayaran
+type shiftMethodSummary struct {
+	Code           string  `json:"code"`
+	Name           string  `json:"name"`
+	MethodType     string  `json:"method_type"`
+	CountsToDrawer bool    `json:"counts_to_drawer"`
+	Amount         float64 `json:"amount"`
+}
+
+// shiftPaymentSummary menyimpan total per tipe metode (cash/card/qris/transfer)
+// untuk kompatibilitas kolom closing_*, rincian per metode, total yang masuk laci
+// dan total tip.
 type shiftPaymentSummary struct {
-	Cash     float64 `json:"cash"`
-	Card     float64 `json:"card"`
-	Qris     float64 `json:"qris"`
-	Transfer float64 `json:"transfer"`
-	Total    float64 `json:"total"`
+	Cash     float64              `json:"cash"`
+	Card     float64              `json:"card"`
+	Qris     float64              `json:"qris"`
+	Transfer float64              `json:"transfer"`
+	Total    float64              `json:"total"`
+	Drawer   float64              `json:"drawer"`
+	Methods  []shiftMethodSummary `json:"methods"`
+	// Tip dicatat terpisah dari penjualan; tip tunai tetap ada di laci sampai dibayarkan
+	Tips       float64 `json:"tips"`
+	TipsDrawer float64 `json:"tips_drawer"`
+	// Deposit adalah kewajiban, bukan penjualan; DepositsDrawer = deposit masuk laci - refund dari laci
+	Deposits       repositories.DepositShiftSummary `json:"deposits"`
+	DepositsDrawer float64                          `json:"deposits_drawer"`
+	// Penjualan/top up gift card juga kewajiban; GiftCardsDrawer = penjualan yang masuk laci
+	GiftCards       repositories.GiftCardShiftSummary `json:"gift_cards"`
+	GiftCardsDrawer float64                           `json:"gift_cards_drawer"`
 }
 
 type cashierShiftRow struct {
@@ -98,6 +180,8 @@ type cashierShiftRow struct {
 	OpenedByName    sql.NullString
 	ClosedByName    sql.NullString
 	HandoverToName  sql.NullString
+	TerminalID      sql.NullString
+	TerminalName    sql.NullString
 }
 
 const cashierShiftSelect = `
@@ -121,11 +205,14 @@ const cashierShiftSelect = `
 		cs.updated_at,
 		u.full_name,
 		u2.full_name,
-		u3.full_name
+		u3.full_name,
+		cs.terminal_id,
+		ct.name
 	FROM cashier_shifts cs
 	LEFT JOIN users u ON cs.opened_by = u.id
 	LEFT JOIN users u2 ON cs.closed_by = u2.id
 	LEFT JOIN users u3 ON cs.handover_to = u3.id
+	LEFT JOIN cashier_terminals ct ON cs.terminal_id = ct.id
 `
 
 func cashierShiftToResponse(row *cashierShiftRow) map[string]interface{} {
@@ -215,6 +302,18 @@ func cashierShiftToResponse(row *cashierShiftRow) map[string]interface{} {
 			}
 			return nil
 		}(),
+		"terminal_id": func() interface{} {
+			if row.TerminalID.Valid {
+				return row.TerminalID.String
+			}
+			return nil
+		}(),
+		"terminal_name": func() interface{} {
+			if row.TerminalName.Valid {
+				return row.TerminalName.String
+			}
+			return nil
+		}(),
 	}
 	return response
 }
@@ -230,7 +329,8 @@ func (h *TransactionHandler) CreateTransaction(c *echo.Context) error {
 		return UnauthorizedResponse(c, "User tidak terautentikasi")
 	}
 
-	transaction, err := h.transactionService.CreateTransaction((*c).Request().Context(), req.OrderID, req.TotalAmount, req.PaymentMethod, req.Items, claims.UserID)
+	shiftID := optionalTerminalShiftID(c, h.db)
+	transaction, err := h.transactionService.CreateTransaction((*c).Request().Context(), req.OrderID, req.TotalAmount, req.PaymentMethod, req.Items, claims.UserID, shiftID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal membuat transaksi: "+err.Error())
 	}
@@ -302,26 +402,36 @@ func (h *TransactionHandler) GetTransactionsByDateRange(c *echo.Context) error {
 }
 
 func (h *TransactionHandler) GetCashierShiftState(c *echo.Context) error {
+	claims, err := middleware.GetUserFromContext(c)
+	if err != nil {
+		return UnauthorizedResponse(c, "User tidak terautentikasi")
+	}
+
 	ctx := (*c).Request().Context()
+	terminal, err := repositories.ResolveTerminal(ctx, h.db, terminalRef(c))
+	if err != nil {
+		return respondShiftError(c, err)
+	}
 	state := map[string]interface{}{
+		"terminal":          terminal,
 		"open_shift":        nil,
 		"last_closed_shift": nil,
 	}
 
-	openShift, err := h.getOpenCashierShift(ctx)
+	openShift, err := h.getOpenCashierShift(ctx, terminal.ID)
 	if err != nil && err != sql.ErrNoRows {
 		return InternalErrorResponse(c, "Gagal mengambil data shift kasir")
 	}
 	if err == nil {
-		summary, err := h.getShiftPaymentSummary(ctx, openShift.OpenedAt, time.Now(), openShift.OpenedBy)
+		summary, err := h.getShiftPaymentSummary(ctx, openShift.ID)
 		if err != nil {
 			return InternalErrorResponse(c, "Gagal menghitung ringkasan penjualan")
 		}
-		voidSummary, err := h.getShiftVoidSummary(ctx, openShift.OpenedAt, time.Now(), openShift.OpenedBy)
+		voidSummary, err := h.getShiftVoidSummary(ctx, openShift.ID)
 		if err != nil {
 			return InternalErrorResponse(c, "Gagal mengambil data VOID")
 		}
-		cancelSummary, err := h.getShiftCancelledSummary(ctx, openShift.OpenedAt, time.Now())
+		cancelSummary, err := h.getShiftCancelledSummary(ctx, openShift.ID)
 		if err != nil {
 			return InternalErrorResponse(c, "Gagal mengambil data pembatalan transaksi")
 		}
@@ -334,24 +444,28 @@ func (h *TransactionHandler) GetCashierShiftState(c *echo.Context) error {
 			return InternalErrorResponse(c, "Gagal mengambil data uang masuk/keluar")
 		}
 		openShiftResponse["cash_movements"] = cashMovements
+		openShiftResponse["blind_close"] = h.shiftSettings.BlindClose
+		openShiftResponse["variance_threshold"] = h.shiftSettings.VarianceThreshold
+		openShiftResponse["expected_cash"] = openShift.OpeningCash + summary.Drawer + summary.TipsDrawer + summary.DepositsDrawer + summary.GiftCardsDrawer + cashMovements.TotalIn - cashMovements.TotalOut
+		// Blind close: kasir tidak boleh melihat ekspektasi kas sebelum menghitung laci
+		if h.shiftSettings.BlindClose && !canSeeExpectedCash(claims.Role) {
+			openShiftResponse["sales_summary"] = nil
+			openShiftResponse["expected_cash"] = nil
+		}
 		state["open_shift"] = openShiftResponse
 	}
 
-	lastClosed, err := h.getLastClosedCashierShift(ctx)
+	lastClosed, err := h.getLastClosedCashierShift(ctx, terminal.ID)
 	if err != nil && err != sql.ErrNoRows {
 		return InternalErrorResponse(c, "Gagal mengambil data shift kasir")
 	}
 	if err == nil {
 		lastClosedResponse := cashierShiftToResponse(lastClosed)
-		endTime := time.Now()
-		if lastClosed.ClosedAt.Valid {
-			endTime = lastClosed.ClosedAt.Time
-		}
-		voidSummary, err := h.getShiftVoidSummary(ctx, lastClosed.OpenedAt, endTime, lastClosed.OpenedBy)
+		voidSummary, err := h.getShiftVoidSummary(ctx, lastClosed.ID)
 		if err != nil {
 			return InternalErrorResponse(c, "Gagal mengambil data VOID")
 		}
-		cancelSummary, err := h.getShiftCancelledSummary(ctx, lastClosed.OpenedAt, endTime)
+		cancelSummary, err := h.getShiftCancelledSummary(ctx, lastClosed.ID)
 		if err != nil {
 			return InternalErrorResponse(c, "Gagal mengambil data pembatalan transaksi")
 		}
@@ -359,7 +473,17 @@ func (h *TransactionHandler) GetCashierShiftState(c *echo.Context) error {
 		if err != nil {
 			return InternalErrorResponse(c, "Gagal mengambil data uang masuk/keluar")
 		}
+		closingPayments, err := h.getShiftClosingPayments(ctx, lastClosed.ID)
+		if err != nil {
+			return InternalErrorResponse(c, "Gagal mengambil ringkasan metode pembayaran")
+		}
+		cashCount, err := h.getShiftCashCount(ctx, lastClosed.ID)
+		if err != nil {
+			return InternalErrorResponse(c, "Gagal mengambil data hitung kas")
+		}
 		lastClosedResponse["cash_movements"] = cashMovements
+		lastClosedResponse["closing_payments"] = closingPayments
+		lastClosedResponse["cash_count"] = cashCount
 		lastClosedResponse["void_summary"] = voidSummary
 		lastClosedResponse["cancelled_summary"] = cancelSummary
 		state["last_closed_shift"] = lastClosedResponse
@@ -384,20 +508,32 @@ func (h *TransactionHandler) OpenCashierShift(c *echo.Context) error {
 	}
 
 	ctx := (*c).Request().Context()
-	existingShift, err := h.getOpenCashierShift(ctx)
+	terminal, err := repositories.ResolveTerminal(ctx, h.db, terminalRef(c))
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+	existingShift, err := h.getOpenCashierShift(ctx, terminal.ID)
 	if err == nil && existingShift != nil {
-		return ErrorResponse(c, http.StatusConflict, "Shift kasir masih terbuka")
+		return ErrorResponse(c, http.StatusConflict, "Shift kasir masih terbuka di terminal "+terminal.Name)
 	}
 	if err != nil && err != sql.ErrNoRows {
 		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
 	}
+	// Satu kasir hanya memegang satu laci dalam satu waktu
+	otherShift, err := h.getCashierShiftByQuery(ctx, cashierShiftSelect+" WHERE cs.status = 'open' AND cs.opened_by = ? LIMIT 1", claims.UserID)
+	if err == nil {
+		return ErrorResponse(c, http.StatusConflict, "Kasir masih memiliki shift terbuka di terminal "+otherShift.TerminalName.String)
+	}
+	if err != sql.ErrNoRows {
+		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+	}
 
 	openingCash := 0.0
 	if req.OpeningCash != nil {
 		openingCash = *req.OpeningCash
 	}
 	var previousShiftID interface{}
-	lastClosed, err := h.getLastClosedCashierShift(ctx)
+	lastClosed, err := h.getLastClosedCashierShift(ctx, terminal.ID)
 	if err == nil {
 		previousShiftID = lastClosed.ID
 		if req.OpeningCash == nil && lastClosed.CarryOverCash.Valid {
@@ -416,11 +552,12 @@ func (h *TransactionHandler) OpenCashierShift(c *echo.Context) error {
 			status,
 			opened_at,
 			previous_shift_id,
+			terminal_id,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, 'open', CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`, shiftID, claims.UserID, openingCash, previousShiftID)
+		VALUES (?, ?, ?, 'open', CURRENT_TIMESTAMP, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, shiftID, claims.UserID, openingCash, previousShiftID, terminal.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal membuka shift kasir")
 	}
@@ -445,34 +582,44 @@ func (h *TransactionHandler) CloseCashierShift(c *echo.Context) error {
 	}
 
 	ctx := (*c).Request().Context()
-	openShift, err := h.getOpenCashierShift(ctx)
+	terminal, err := repositories.ResolveTerminal(ctx, h.db, terminalRef(c))
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+	openShift, err := h.getOpenCashierShift(ctx, terminal.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return BadRequestResponse(c, "Tidak ada shift kasir yang terbuka")
 		}
 		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
 	}
-	pendingCount, err := h.getPendingOrdersCount(ctx)
+	// Order yang belum dibayar bisa diselesaikan di terminal lain; hanya shift
+	// terakhir yang terbuka yang wajib menunggu semua order lunas
+	otherOpen, err := h.countOtherOpenShifts(ctx, openShift.ID)
 	if err != nil {
-		return InternalErrorResponse(c, "Gagal memeriksa transaksi pending")
+		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
 	}
-	if pendingCount > 0 {
-		return BadRequestResponse(c, "Masih ada transaksi yang belum dibayar. Selesaikan transaksi sebelum tutup shift")
+	if otherOpen == 0 {
+		pendingCount, err := h.getPendingOrdersCount(ctx)
+		if err != nil {
+			return InternalErrorResponse(c, "Gagal memeriksa transaksi pending")
+		}
+		if pendingCount > 0 {
+			return BadRequestResponse(c, "Masih ada transaksi yang belum dibayar. Selesaikan transaksi sebelum tutup shift")
+		}
 	}
 
-	now := time.Now()
-	startTime := openShift.OpenedAt
-	summary, err := h.getShiftPaymentSummary(ctx, startTime, now, openShift.OpenedBy)
+	summary, err := h.getShiftPaymentSummary(ctx, openShift.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal menghitung ringkasan penjualan")
 	}
 
-	voidSummary, err := h.getShiftVoidSummary(ctx, startTime, now, openShift.OpenedBy)
+	voidSummary, err := h.getShiftVoidSummary(ctx, openShift.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data VOID")
 	}
 
-	cancelSummary, err := h.getShiftCancelledSummary(ctx, startTime, now)
+	cancelSummary, err := h.getShiftCancelledSummary(ctx, openShift.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data pembatalan transaksi")
 	}
@@ -481,7 +628,16 @@ func (h *TransactionHandler) CloseCashierShift(c *echo.Context) error {
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data uang masuk/keluar")
 	}
-	carryOverCash := openShift.OpeningCash + summary.Cash + cashMovements.TotalIn - cashMovements.TotalOut
+	carryOverCash := openShift.OpeningCash + summary.Drawer + summary.TipsDrawer + summary.DepositsDrawer + summary.GiftCardsDrawer + cashMovements.TotalIn - cashMovements.TotalOut
+
+	cashCount, err := h.countShiftCash(ctx, carryOverCash, req.Denominations, req.ClosingCash, req.ManagerPIN, req.VarianceNote)
+	if err != nil {
+		return respondCashCountError(c, cashCount, err, claims.Role)
+	}
+	if cashCount != nil {
+		// Kas yang dibawa ke shift berikutnya adalah uang fisik hasil hitung
+		carryOverCash = cashCount.CountedCash
+	}
 
 	tx, err := h.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -513,6 +669,15 @@ func (h *TransactionHandler) CloseCashierShift(c *echo.Context) error {
 		return BadRequestResponse(c, "Shift kasir sudah ditutup")
 	}
 
+	if err := insertShiftPayments(ctx, tx, openShift.ID, summary); err != nil {
+		_ = tx.Rollback()
+		return InternalErrorResponse(c, "Gagal menyimpan ringkasan metode pembayaran")
+	}
+	if err := saveShiftCashCount(ctx, tx, openShift.ID, cashCount); err != nil {
+		_ = tx.Rollback()
+		return InternalErrorResponse(c, "Gagal menyimpan hitung kas")
+	}
+
 	if err := tx.Commit(); err != nil {
 		return InternalErrorResponse(c, "Gagal menyimpan tutup shift kasir")
 	}
@@ -522,10 +687,12 @@ func (h *TransactionHandler) CloseCashierShift(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil data shift kasir")
 	}
 
-	h.enqueueCloseShiftReceipt(ctx, openShift, summary, voidSummary, cancelSummary, openShift.ID, cashMovements.CashIn, cashMovements.CashOut)
+	h.enqueueCloseShiftReceipt(ctx, openShift, summary, voidSummary, cancelSummary, cashCount, openShift.ID, cashMovements.CashIn, cashMovements.CashOut)
 
 	shiftResponse := cashierShiftToResponse(shift)
 	shiftResponse["cash_movements"] = cashMovements
+	shiftResponse["closing_payments"] = summary.Methods
+	shiftResponse["cash_count"] = cashCount
 
 	return SuccessResponse(c, "Shift kasir ditutup", shiftResponse)
 }
@@ -571,7 +738,11 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 		return BadRequestResponse(c, "Kasir tujuan tidak valid")
 	}
 
-	openShift, err := h.getOpenCashierShift(ctx)
+	terminal, err := repositories.ResolveTerminal(ctx, h.db, terminalRef(c))
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+	openShift, err := h.getOpenCashierShift(ctx, terminal.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return BadRequestResponse(c, "Tidak ada shift kasir yang terbuka")
@@ -585,6 +756,11 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 	if pendingCount > 0 {
 		return BadRequestResponse(c, "Masih ada transaksi yang belum dibayar. Selesaikan transaksi sebelum serah terima")
 	}
+	if nextShift, err := h.getCashierShiftByQuery(ctx, cashierShiftSelect+" WHERE cs.status = 'open' AND cs.opened_by = ? LIMIT 1", req.NextCashierID); err == nil {
+		return BadRequestResponse(c, "Kasir tujuan masih memiliki shift terbuka di terminal "+nextShift.TerminalName.String)
+	} else if err != sql.ErrNoRows {
+		return InternalErrorResponse(c, "Gagal memeriksa shift kasir")
+	}
 	currentCashierID := openShift.OpenedBy
 	if claims.Role == "admin" || claims.Role == "manager" {
 		currentCashierID = claims.UserID
@@ -612,19 +788,17 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 		return UnauthorizedResponse(c, "PIN kasir tujuan salah")
 	}
 
-	now := time.Now()
-	startTime := openShift.OpenedAt
-	summary, err := h.getShiftPaymentSummary(ctx, startTime, now, openShift.OpenedBy)
+	summary, err := h.getShiftPaymentSummary(ctx, openShift.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal menghitung ringkasan penjualan")
 	}
 
-	voidSummary, err := h.getShiftVoidSummary(ctx, startTime, now, openShift.OpenedBy)
+	voidSummary, err := h.getShiftVoidSummary(ctx, openShift.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data VOID")
 	}
 
-	cancelSummary, err := h.getShiftCancelledSummary(ctx, startTime, now)
+	cancelSummary, err := h.getShiftCancelledSummary(ctx, openShift.ID)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data pembatalan transaksi")
 	}
@@ -634,13 +808,21 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil data uang masuk/keluar")
 	}
 
+	carryOverCash := openShift.OpeningCash + summary.Drawer + summary.TipsDrawer + summary.DepositsDrawer + summary.GiftCardsDrawer + cashMovements.TotalIn - cashMovements.TotalOut
+	cashCount, err := h.countShiftCash(ctx, carryOverCash, req.Denominations, req.ClosingCash, req.ManagerPIN, req.VarianceNote)
+	if err != nil {
+		return respondCashCountError(c, cashCount, err, claims.Role)
+	}
+	if cashCount != nil {
+		carryOverCash = cashCount.CountedCash
+	}
+
 	tx, err := h.db.BeginTx(ctx, nil)
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal memulai proses serah terima")
 	}
 
 	shiftID := utils.GenerateULID()
-	carryOverCash := openShift.OpeningCash + summary.Cash + cashMovements.TotalIn - cashMovements.TotalOut
 
 	_, err = tx.ExecContext(ctx, `
 		UPDATE cashier_shifts
@@ -662,6 +844,15 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal menutup shift kasir")
 	}
 
+	if err := insertShiftPayments(ctx, tx, openShift.ID, summary); err != nil {
+		_ = tx.Rollback()
+		return InternalErrorResponse(c, "Gagal menyimpan ringkasan metode pembayaran")
+	}
+	if err := saveShiftCashCount(ctx, tx, openShift.ID, cashCount); err != nil {
+		_ = tx.Rollback()
+		return InternalErrorResponse(c, "Gagal menyimpan hitung kas")
+	}
+
 	_, err = tx.ExecContext(ctx, `
 		INSERT INTO cashier_shifts (
 			id,
@@ -670,11 +861,12 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 			status,
 			opened_at,
 			previous_shift_id,
+			terminal_id,
 			created_at,
 			updated_at
 		)
-		VALUES (?, ?, ?, 'open', CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-	`, shiftID, req.NextCashierID, carryOverCash, openShift.ID)
+		VALUES (?, ?, ?, 'open', CURRENT_TIMESTAMP, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, shiftID, req.NextCashierID, carryOverCash, openShift.ID, terminal.ID)
 	if err != nil {
 		_ = tx.Rollback()
 		return InternalErrorResponse(c, "Gagal membuka shift kasir baru")
@@ -689,7 +881,7 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 		return InternalErrorResponse(c, "Gagal mengambil data shift kasir")
 	}
 
-	h.enqueueHandoverReceipt(ctx, openShift, nextUser, summary, voidSummary, cancelSummary, shiftID, cashMovements.CashIn, cashMovements.CashOut)
+	h.enqueueHandoverReceipt(ctx, openShift, nextUser, summary, voidSummary, cancelSummary, cashCount, shiftID, cashMovements.CashIn, cashMovements.CashOut)
 
 	token, err := middleware.GenerateToken(&nextUser)
 	if err != nil {
@@ -706,7 +898,7 @@ func (h *TransactionHandler) HandoverCashierShift(c *echo.Context) error {
 }
 
 func (h *TransactionHandler) CreateCashMovement(c *echo.Context) error {
-	_, err := middleware.GetUserFromContext(c)
+	claims, err := middleware.GetUserFromContext(c)
 	if err != nil {
 		return UnauthorizedResponse(c, "User tidak terautentikasi")
 	}
@@ -732,7 +924,11 @@ func (h *TransactionHandler) CreateCashMovement(c *echo.Context) error {
 	}
 
 	ctx := (*c).Request().Context()
-	openShift, err := h.getOpenCashierShift(ctx)
+	terminal, err := repositories.ResolveTerminal(ctx, h.db, terminalRef(c))
+	if err != nil {
+		return respondShiftError(c, err)
+	}
+	openShift, err := h.getOpenCashierShift(ctx, terminal.ID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return BadRequestResponse(c, "Tidak ada shift kasir yang terbuka")
@@ -759,8 +955,10 @@ func (h *TransactionHandler) CreateCashMovement(c *echo.Context) error {
 
 	if req.Type == "in" {
 		h.enqueueCashInReceipt(ctx, openShift, movementID, req.Name, req.Amount)
+		kickCashDrawerForShift(ctx, h.db, h.queries, repositories.DrawerSourceCashIn, movementID, openShift.ID, claims.UserID)
 	} else {
 		h.enqueueCashOutReceipt(ctx, openShift, movementID, req.Name, req.Note, req.Amount)
+		kickCashDrawerForShift(ctx, h.db, h.queries, repositories.DrawerSourceCashOut, movementID, openShift.ID, claims.UserID)
 	}
 
 	cashMovements, err := h.getShiftCashMovements(ctx, openShift.ID)
@@ -805,8 +1003,8 @@ func (h *TransactionHandler) ListCashierUsers(c *echo.Context) error {
 	return SuccessResponse(c, "Daftar kasir berhasil diambil", users)
 }
 
-func (h *TransactionHandler) enqueueHandoverReceipt(ctx context.Context, openShift *cashierShiftRow, nextUser db.User, summary shiftPaymentSummary, voidSummary shiftVoidSummary, cancelSummary shiftCancelledSummary, shiftID string, cashIns []cashMovementItem, cashOuts []cashMovementItem) {
-	printerID, ok := h.getReceiptPrinterID(ctx)
+func (h *TransactionHandler) enqueueHandoverReceipt(ctx context.Context, openShift *cashierShiftRow, nextUser db.User, summary shiftPaymentSummary, voidSummary shiftVoidSummary, cancelSummary shiftCancelledSummary, cashCount *shiftCashCount, shiftID string, cashIns []cashMovementItem, cashOuts []cashMovementItem) {
+	printerID, ok := h.getReceiptPrinterID(ctx, openShift.TerminalID.String)
 	if !ok {
 		return
 	}
@@ -838,6 +1036,9 @@ func (h *TransactionHandler) enqueueHandoverReceipt(ctx context.Context, openShi
 		ClosingCard:     int(math.Round(summary.Card)),
 		ClosingQris:     int(math.Round(summary.Qris)),
 		ClosingTransfer: int(math.Round(summary.Transfer)),
+		ShiftPayments:   toPrintShiftPayments(summary.Methods),
+		TipAmount:       int(math.Round(summary.Tips)),
+		CashCount:       toPrintCashCount(cashCount),
 		VoidedCount:     voidSummary.Count,
 		VoidedTotal:     int(math.Round(voidSummary.Total)),
 		CancelledCount:  cancelSummary.Count,
@@ -858,8 +1059,8 @@ func (h *TransactionHandler) enqueueHandoverReceipt(ctx context.Context, openShi
 	})
 }
 
-func (h *TransactionHandler) enqueueCloseShiftReceipt(ctx context.Context, openShift *cashierShiftRow, summary shiftPaymentSummary, voidSummary shiftVoidSummary, cancelSummary shiftCancelledSummary, shiftID string, cashIns []cashMovementItem, cashOuts []cashMovementItem) {
-	printerID, ok := h.getReceiptPrinterID(ctx)
+func (h *TransactionHandler) enqueueCloseShiftReceipt(ctx context.Context, openShift *cashierShiftRow, summary shiftPaymentSummary, voidSummary shiftVoidSummary, cancelSummary shiftCancelledSummary, cashCount *shiftCashCount, shiftID string, cashIns []cashMovementItem, cashOuts []cashMovementItem) {
+	printerID, ok := h.getReceiptPrinterID(ctx, openShift.TerminalID.String)
 	if !ok {
 		return
 	}
@@ -890,6 +1091,9 @@ func (h *TransactionHandler) enqueueCloseShiftReceipt(ctx context.Context, openS
 		ClosingCard:     int(math.Round(summary.Card)),
 		ClosingQris:     int(math.Round(summary.Qris)),
 		ClosingTransfer: int(math.Round(summary.Transfer)),
+		ShiftPayments:   toPrintShiftPayments(summary.Methods),
+		TipAmount:       int(math.Round(summary.Tips)),
+		CashCount:       toPrintCashCount(cashCount),
 		VoidedCount:     voidSummary.Count,
 		VoidedTotal:     int(math.Round(voidSummary.Total)),
 		CancelledCount:  cancelSummary.Count,
@@ -911,7 +1115,7 @@ func (h *TransactionHandler) enqueueCloseShiftReceipt(ctx context.Context, openS
 }
 
 func (h *TransactionHandler) enqueueCashInReceipt(ctx context.Context, openShift *cashierShiftRow, movementID string, counterpart string, amount float64) {
-	printerID, ok := h.getReceiptPrinterID(ctx)
+	printerID, ok := h.getReceiptPrinterID(ctx, openShift.TerminalID.String)
 	if !ok {
 		return
 	}
@@ -943,7 +1147,7 @@ func (h *TransactionHandler) enqueueCashInReceipt(ctx context.Context, openShift
 }
 
 func (h *TransactionHandler) enqueueCashOutReceipt(ctx context.Context, openShift *cashierShiftRow, movementID string, recipient string, note string, amount float64) {
-	printerID, ok := h.getReceiptPrinterID(ctx)
+	printerID, ok := h.getReceiptPrinterID(ctx, openShift.TerminalID.String)
 	if !ok {
 		return
 	}
@@ -975,7 +1179,14 @@ func (h *TransactionHandler) enqueueCashOutReceipt(ctx context.Context, openShif
 	})
 }
 
-func (h *TransactionHandler) getReceiptPrinterID(ctx context.Context) (string, bool) {
+// getReceiptPrinterID memakai printer terminal shift jika diatur, selain itu printer struk pertama
+func (h *TransactionHandler) getReceiptPrinterID(ctx context.Context, terminalID string) (string, bool) {
+	if terminalID != "" {
+		if printerID, ok := terminalPrinterID(ctx, h.db, terminalID); ok {
+			return printerID, true
+		}
+	}
+
 	strukPrinters, err := h.queries.ListPrintersByType(ctx, "struk")
 	if err == nil && len(strukPrinters) > 0 {
 		return strukPrinters[0].ID, true
@@ -1013,6 +1224,8 @@ func (h *TransactionHandler) getCashierShiftByQuery(ctx context.Context, query s
 		&shift.OpenedByName,
 		&shift.ClosedByName,
 		&shift.HandoverToName,
+		&shift.TerminalID,
+		&shift.TerminalName,
 	)
 	if err != nil {
 		return nil, err
@@ -1020,12 +1233,18 @@ func (h *TransactionHandler) getCashierShiftByQuery(ctx context.Context, query s
 	return &shift, nil
 }
 
-func (h *TransactionHandler) getOpenCashierShift(ctx context.Context) (*cashierShiftRow, error) {
-	return h.getCashierShiftByQuery(ctx, cashierShiftSelect+" WHERE cs.status = 'open' ORDER BY cs.opened_at DESC LIMIT 1")
+func (h *TransactionHandler) getOpenCashierShift(ctx context.Context, terminalID string) (*cashierShiftRow, error) {
+	return h.getCashierShiftByQuery(ctx, cashierShiftSelect+" WHERE cs.status = 'open' AND cs.terminal_id = ? LIMIT 1", terminalID)
+}
+
+func (h *TransactionHandler) getLastClosedCashierShift(ctx context.Context, terminalID string) (*cashierShiftRow, error) {
+	return h.getCashierShiftByQuery(ctx, cashierShiftSelect+" WHERE cs.status = 'closed' AND cs.terminal_id = ? ORDER BY cs.closed_at DESC LIMIT 1", terminalID)
 }
 
-func (h *TransactionHandler) getLastClosedCashierShift(ctx context.Context) (*cashierShiftRow, error) {
-	return h.getCashierShiftByQuery(ctx, cashierShiftSelect+" WHERE cs.status = 'closed' ORDER BY cs.closed_at DESC LIMIT 1")
+func (h *TransactionHandler) countOtherOpenShifts(ctx context.Context, shiftID string) (int, error) {
+	var count int
+	err := h.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM cashier_shifts WHERE status = 'open' AND id != ?", shiftID).Scan(&count)
+	return count, err
 }
 
 func (h *TransactionHandler) getCashierShiftByID(ctx context.Context, shiftID string) (*cashierShiftRow, error) {
@@ -1047,31 +1266,391 @@ func (h *TransactionHandler) getPendingOrdersCount(ctx context.Context) (int, er
 	return count, nil
 }
 
-func (h *TransactionHandler) getShiftPaymentSummary(ctx context.Context, start time.Time, end time.Time, cashierID string) (shiftPaymentSummary, error) {
-	row := h.db.QueryRowContext(ctx, `
-		SELECT
-			COALESCE(SUM(CASE WHEN payment_method = 'cash' THEN amount ELSE 0 END), 0) AS cash,
-			COALESCE(SUM(CASE WHEN payment_method = 'card' THEN amount ELSE 0 END), 0) AS card,
-			COALESCE(SUM(CASE WHEN payment_method = 'qris' THEN amount ELSE 0 END), 0) AS qris,
-			COALESCE(SUM(CASE WHEN payment_method = 'transfer' THEN amount ELSE 0 END), 0) AS transfer
+// getShiftPaymentSummary menghitung penjualan shift per metode pembayaran dari
+// transaksi dan refund (payment negatif) yang ditandai shift (laci) ini. Payment
+// positif sudah tercatat juga sebagai transaksi (split bill, deposit), sehingga
+// tidak dijumlahkan lagi. Semua metode aktif selalu tampil (walau nol); kode yang
+// tidak dikenal tetap dihitung sebagai tipe other agar total shift tidak hilang.
+func (h *TransactionHandler) getShiftPaymentSummary(ctx context.Context, shiftID string) (shiftPaymentSummary, error) {
+	summary := shiftPaymentSummary{Methods: []shiftMethodSummary{}}
+
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT payment_method, COALESCE(SUM(amount), 0)
 		FROM (
-			SELECT payment_method, amount, created_by, created_at
+			SELECT payment_method, amount, shift_id
 			FROM payments
+			WHERE amount < 0
 			UNION ALL
-			SELECT payment_method, total_amount AS amount, created_by, transaction_date AS created_at
+			SELECT payment_method, total_amount AS amount, shift_id
 			FROM transactions
 			WHERE cancelled_at IS NULL
 		) t
-		WHERE created_at BETWEEN ? AND ? AND created_by = ?
-	`, start, end, cashierID)
-	summary := shiftPaymentSummary{}
-	if err := row.Scan(&summary.Cash, &summary.Card, &summary.Qris, &summary.Transfer); err != nil {
+		WHERE shift_id = ?
+		GROUP BY payment_method
+	`, shiftID)
+	if err != nil {
+		return summary, err
+	}
+	amounts := map[string]float64{}
+	for rows.Next() {
+		var code string
+		var amount float64
+		if err := rows.Scan(&code, &amount); err != nil {
+			rows.Close()
+			return summary, err
+		}
+		amounts[code] = amount
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return summary, err
+	}
+
+	methodRows, err := h.db.QueryContext(ctx, `
+		SELECT code, name, method_type, counts_to_drawer, is_active
+		FROM payment_methods
+		ORDER BY sort_order, name
+	`)
+	if err != nil {
 		return summary, err
 	}
-	summary.Total = summary.Cash + summary.Card + summary.Qris + summary.Transfer
+	for methodRows.Next() {
+		var item shiftMethodSummary
+		var countsToDrawer, isActive int64
+		if err := methodRows.Scan(&item.Code, &item.Name, &item.MethodType, &countsToDrawer, &isActive); err != nil {
+			methodRows.Close()
+			return summary, err
+		}
+		amount, used := amounts[item.Code]
+		if isActive == 0 && !used {
+			continue
+		}
+		item.CountsToDrawer = countsToDrawer == 1
+		item.Amount = amount
+		summary.Methods = append(summary.Methods, item)
+		delete(amounts, item.Code)
+	}
+	methodRows.Close()
+	if err := methodRows.Err(); err != nil {
+		return summary, err
+	}
+
+	unknownCodes := make([]string, 0, len(amounts))
+	for code := range amounts {
+		unknownCodes = append(unknownCodes, code)
+	}
+	sort.Strings(unknownCodes)
+	for _, code := range unknownCodes {
+		summary.Methods = append(summary.Methods, shiftMethodSummary{
+			Code:       code,
+			Name:       code,
+			MethodType: "other",
+			Amount:     amounts[code],
+		})
+	}
+
+	for _, item := range summary.Methods {
+		switch item.MethodType {
+		case "cash":
+			summary.Cash += item.Amount
+		case "card":
+			summary.Card += item.Amount
+		case "qris":
+			summary.Qris += item.Amount
+		case "transfer":
+			summary.Transfer += item.Amount
+		}
+		if item.CountsToDrawer {
+			summary.Drawer += item.Amount
+		}
+		summary.Total += item.Amount
+	}
+
+	tipRow := h.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(pt.amount), 0),
+			COALESCE(SUM(CASE WHEN COALESCE(pm.counts_to_drawer, pt.payment_method = 'cash') = 1 THEN pt.amount ELSE 0 END), 0)
+		FROM payment_tips pt
+		JOIN transactions t ON t.id = pt.transaction_id AND t.cancelled_at IS NULL
+		LEFT JOIN payment_methods pm ON pm.code = pt.payment_method
+		WHERE pt.shift_id = ?
+	`, shiftID)
+	if err := tipRow.Scan(&summary.Tips, &summary.TipsDrawer); err != nil {
+		return summary, err
+	}
+
+	summary.Deposits, err = repositories.GetShiftDepositSummary(ctx, h.db, shiftID)
+	if err != nil {
+		return summary, err
+	}
+	summary.DepositsDrawer = summary.Deposits.ReceivedDrawer - summary.Deposits.RefundedDrawer
+
+	summary.GiftCards, err = repositories.GetShiftGiftCardSummary(ctx, h.db, shiftID)
+	if err != nil {
+		return summary, err
+	}
+	summary.GiftCardsDrawer = summary.GiftCards.SoldDrawer
 	return summary, nil
 }
 
+// insertShiftPayments menyimpan rincian per metode saat shift ditutup sehingga
+// riwayat shift tidak berubah walau metode pembayaran diganti nama/nonaktif.
+func insertShiftPayments(ctx context.Context, tx *sql.Tx, shiftID string, summary shiftPaymentSummary) error {
+	for _, item := range summary.Methods {
+		countsToDrawer := 0
+		if item.CountsToDrawer {
+			countsToDrawer = 1
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO cashier_shift_payments (
+				shift_id, payment_method, method_name, method_type, counts_to_drawer, amount
+			) VALUES (?, ?, ?, ?, ?, ?)
+		`, shiftID, item.Code, item.Name, item.MethodType, countsToDrawer, item.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *TransactionHandler) getShiftClosingPayments(ctx context.Context, shiftID string) ([]shiftMethodSummary, error) {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT csp.payment_method, csp.method_name, csp.method_type, csp.counts_to_drawer, csp.amount
+		FROM cashier_shift_payments csp
+		LEFT JOIN payment_methods pm ON pm.code = csp.payment_method
+		WHERE csp.shift_id = ?
+		ORDER BY COALESCE(pm.sort_order, 999), csp.method_name
+	`, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []shiftMethodSummary{}
+	for rows.Next() {
+		var item shiftMethodSummary
+		var countsToDrawer int64
+		if err := rows.Scan(&item.Code, &item.Name, &item.MethodType, &countsToDrawer, &item.Amount); err != nil {
+			return nil, err
+		}
+		item.CountsToDrawer = countsToDrawer == 1
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// countShiftCash menghitung selisih kas fisik terhadap ekspektasi laci (modal +
+// metode yang masuk laci + tip tunai + deposit tunai bersih + penjualan gift card tunai + uang masuk - uang keluar). Tanpa rincian
+// pecahan, closing_cash dipakai sebagai hasil hitung; jika keduanya kosong dan
+// bukan blind close, shift ditutup tanpa hitung kas (nil).
+func (h *TransactionHandler) countShiftCash(ctx context.Context, expectedCash float64, denominations []CashDenominationCount, closingCash float64, managerPIN, note string) (*shiftCashCount, error) {
+	count := &shiftCashCount{
+		ExpectedCash:  math.Round(expectedCash),
+		BlindClose:    h.shiftSettings.BlindClose,
+		Denominations: []CashDenominationCount{},
+		VarianceNote:  strings.TrimSpace(note),
+	}
+
+	if len(denominations) > 0 {
+		merged := map[int]int{}
+		for _, item := range denominations {
+			if !cashDenominations[item.Denomination] {
+				return nil, fmt.Errorf("%w: pecahan %d tidak dikenal", errInvalidCashCount, item.Denomination)
+			}
+			if item.Qty < 0 {
+				return nil, fmt.Errorf("%w: jumlah pecahan %d tidak boleh negatif", errInvalidCashCount, item.Denomination)
+			}
+			merged[item.Denomination] += item.Qty
+		}
+		values := make([]int, 0, len(merged))
+		for denomination := range merged {
+			values = append(values, denomination)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(values)))
+		for _, denomination := range values {
+			amount := float64(denomination * merged[denomination])
+			count.Denominations = append(count.Denominations, CashDenominationCount{
+				Denomination: denomination,
+				Qty:          merged[denomination],
+				Amount:       amount,
+			})
+			count.CountedCash += amount
+		}
+	} else if closingCash > 0 && !h.shiftSettings.BlindClose {
+		count.CountedCash = math.Round(closingCash)
+	} else if h.shiftSettings.BlindClose {
+		return nil, errCashCountRequired
+	} else {
+		return nil, nil
+	}
+
+	count.Variance = count.CountedCash - count.ExpectedCash
+	if math.Abs(count.Variance) <= h.shiftSettings.VarianceThreshold {
+		return count, nil
+	}
+
+	count.RequiresApproval = true
+	if managerPIN == "" {
+		return count, errVarianceApprovalRequired
+	}
+	manager, err := verifyManagerPIN(ctx, h.queries, managerPIN)
+	if errors.Is(err, errInvalidManagerPIN) {
+		return count, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	count.ApprovedBy = &manager.ID
+	count.ApprovedByName = manager.FullName
+	return count, nil
+}
+
+// canSeeExpectedCash menentukan role yang boleh melihat ekspektasi kas saat blind close
+func canSeeExpectedCash(role string) bool {
+	return role == "admin" || role == "manager"
+}
+
+// respondCashCountError memetakan error hitung kas ke response. Selisih yang butuh
+// persetujuan dikembalikan beserta nilainya agar manager bisa memeriksa sebelum PIN;
+// saat blind close, kasir hanya menerima hasil hitungnya sendiri.
+func respondCashCountError(c *echo.Context, count *shiftCashCount, err error, role string) error {
+	switch {
+	case errors.Is(err, errCashCountRequired), errors.Is(err, errInvalidCashCount):
+		return BadRequestResponse(c, err.Error())
+	case errors.Is(err, errVarianceApprovalRequired):
+		var data interface{} = count
+		if count.BlindClose && !canSeeExpectedCash(role) {
+			data = blindCashCount{
+				CountedCash:      count.CountedCash,
+				BlindClose:       true,
+				Denominations:    count.Denominations,
+				RequiresApproval: true,
+			}
+		}
+		return (*c).JSON(http.StatusForbidden, APIResponse{
+			Success: false,
+			Message: err.Error(),
+			Data:    data,
+		})
+	case errors.Is(err, errInvalidManagerPIN):
+		return UnauthorizedResponse(c, err.Error())
+	default:
+		return InternalErrorResponse(c, "Gagal menghitung selisih kas")
+	}
+}
+
+func saveShiftCashCount(ctx context.Context, tx *sql.Tx, shiftID string, count *shiftCashCount) error {
+	if count == nil {
+		return nil
+	}
+	blindClose := 0
+	if count.BlindClose {
+		blindClose = 1
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE cashier_shifts
+		SET expected_cash = ?, counted_cash = ?, cash_variance = ?, blind_close = ?,
+		    variance_approved_by = ?, variance_note = ?
+		WHERE id = ?
+	`, count.ExpectedCash, count.CountedCash, count.Variance, blindClose, count.ApprovedBy, count.VarianceNote, shiftID); err != nil {
+		return err
+	}
+	for _, item := range count.Denominations {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO cashier_shift_counts (shift_id, denomination, qty, amount)
+			VALUES (?, ?, ?, ?)
+		`, shiftID, item.Denomination, item.Qty, item.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getShiftCashCount mengambil hasil hitung kas shift yang sudah ditutup (nil jika tidak dihitung)
+func (h *TransactionHandler) getShiftCashCount(ctx context.Context, shiftID string) (*shiftCashCount, error) {
+	var expected, counted, variance sql.NullFloat64
+	var blindClose int64
+	var approvedBy, approvedByName, note sql.NullString
+	err := h.db.QueryRowContext(ctx, `
+		SELECT cs.expected_cash, cs.counted_cash, cs.cash_variance, cs.blind_close,
+		       cs.variance_approved_by, u.full_name, cs.variance_note
+		FROM cashier_shifts cs
+		LEFT JOIN users u ON u.id = cs.variance_approved_by
+		WHERE cs.id = ?
+	`, shiftID).Scan(&expected, &counted, &variance, &blindClose, &approvedBy, &approvedByName, &note)
+	if err != nil {
+		return nil, err
+	}
+	if !counted.Valid {
+		return nil, nil
+	}
+
+	count := &shiftCashCount{
+		ExpectedCash:     expected.Float64,
+		CountedCash:      counted.Float64,
+		Variance:         variance.Float64,
+		BlindClose:       blindClose == 1,
+		Denominations:    []CashDenominationCount{},
+		ApprovedByName:   approvedByName.String,
+		VarianceNote:     note.String,
+		RequiresApproval: math.Abs(variance.Float64) > h.shiftSettings.VarianceThreshold,
+	}
+	if approvedBy.Valid {
+		count.ApprovedBy = &approvedBy.String
+	}
+
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT denomination, qty, amount
+		FROM cashier_shift_counts
+		WHERE shift_id = ?
+		ORDER BY denomination DESC
+	`, shiftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var item CashDenominationCount
+		if err := rows.Scan(&item.Denomination, &item.Qty, &item.Amount); err != nil {
+			return nil, err
+		}
+		count.Denominations = append(count.Denominations, item)
+	}
+	return count, rows.Err()
+}
+
+func toPrintCashCount(count *shiftCashCount) *workers.CashCountData {
+	if count == nil {
+		return nil
+	}
+	data := &workers.CashCountData{
+		ExpectedCash: int(math.Round(count.ExpectedCash)),
+		CountedCash:  int(math.Round(count.CountedCash)),
+		Variance:     int(math.Round(count.Variance)),
+		ApprovedBy:   count.ApprovedByName,
+	}
+	for _, item := range count.Denominations {
+		if item.Qty == 0 {
+			continue
+		}
+		data.Denominations = append(data.Denominations, workers.CashDenominationData{
+			Denomination: item.Denomination,
+			Qty:          item.Qty,
+		})
+	}
+	return data
+}
+
+func toPrintShiftPayments(items []shiftMethodSummary) []workers.ShiftPaymentData {
+	printItems := make([]workers.ShiftPaymentData, 0, len(items))
+	for _, item := range items {
+		printItems = append(printItems, workers.ShiftPaymentData{
+			Name:   item.Name,
+			Amount: int(math.Round(item.Amount)),
+		})
+	}
+	return printItems
+}
+
 type shiftVoidSummary struct {
 	Count int     `json:"count"`
 	Total float64 `json:"total"`
@@ -1082,16 +1661,15 @@ type shiftCancelledSummary struct {
 	Total float64 `json:"total"`
 }
 
-func (h *TransactionHandler) getShiftVoidSummary(ctx context.Context, start time.Time, end time.Time, cashierID string) (shiftVoidSummary, error) {
+func (h *TransactionHandler) getShiftVoidSummary(ctx context.Context, shiftID string) (shiftVoidSummary, error) {
 	row := h.db.QueryRowContext(ctx, `
 		SELECT
 			COALESCE(COUNT(*), 0) AS total_count,
 			COALESCE(SUM(total_amount), 0) AS total_amount
 		FROM orders
 		WHERE voided_at IS NOT NULL
-		AND voided_at BETWEEN ? AND ?
-		AND voided_by = ?
-	`, start, end, cashierID)
+		AND voided_shift_id = ?
+	`, shiftID)
 	summary := shiftVoidSummary{}
 	if err := row.Scan(&summary.Count, &summary.Total); err != nil {
 		return summary, err
@@ -1099,15 +1677,15 @@ func (h *TransactionHandler) getShiftVoidSummary(ctx context.Context, start time
 	return summary, nil
 }
 
-func (h *TransactionHandler) getShiftCancelledSummary(ctx context.Context, start time.Time, end time.Time) (shiftCancelledSummary, error) {
+func (h *TransactionHandler) getShiftCancelledSummary(ctx context.Context, shiftID string) (shiftCancelledSummary, error) {
 	row := h.db.QueryRowContext(ctx, `
 		SELECT
 			COALESCE(COUNT(*), 0) AS total_count,
 			COALESCE(SUM(total_amount), 0) AS total_amount
 		FROM transactions
 		WHERE cancelled_at IS NOT NULL
-		AND cancelled_at BETWEEN ? AND ?
-	`, start, end)
+		AND cancelled_shift_id = ?
+	`, shiftID)
 	summary := shiftCancelledSummary{}
 	if err := row.Scan(&summary.Count, &summary.Total); err != nil {
 		return summary, err
@@ -1229,31 +1807,33 @@ func (h *TransactionHandler) CancelTransaction(c *echo.Context) error {
 		}
 	}
 
-	managers, err := h.queries.ListActiveManagers((*c).Request().Context())
+	manager, err := verifyManagerPIN((*c).Request().Context(), h.queries, req.ManagerPIN)
+	if errors.Is(err, errInvalidManagerPIN) {
+		return UnauthorizedResponse(c, err.Error())
+	}
 	if err != nil {
 		return InternalErrorResponse(c, "Gagal mengambil data manager")
 	}
+	managerID := manager.ID
 
-	var managerID string
-	for _, manager := range managers {
-		if err := bcrypt.CompareHashAndPassword([]byte(manager.PasswordHash), []byte(req.ManagerPIN)); err == nil {
-			managerID = manager.ID
-			break
-		}
-	}
-	if managerID == "" {
-		return UnauthorizedResponse(c, "PIN manager salah")
-	}
-
-	if err := h.transactionService.CancelTransaction((*c).Request().Context(), transactionID, managerID, req.Reason); err != nil {
+	// Pembatalan tercatat di shift terminal tempat manager membatalkan (jika ada)
+	cancelShiftID := optionalTerminalShiftID(c, h.db)
+	if err := h.transactionService.CancelTransaction((*c).Request().Context(), transactionID, managerID, req.Reason, cancelShiftID); err != nil {
 		if err == sql.ErrNoRows {
 			return NotFoundResponse(c, "Transaksi tidak ditemukan")
 		}
 		if err == repositories.ErrTransactionAlreadyCancelled {
 			return BadRequestResponse(c, "Transaksi sudah dibatalkan")
 		}
+		if repositories.IsBusinessDayClosed(err) {
+			return BadRequestResponse(c, repositories.ErrBusinessDayClosed.Error())
+		}
 		return InternalErrorResponse(c, "Gagal membatalkan transaksi: "+err.Error())
 	}
+	// Saldo gift card yang dipakai transaksi ini dikembalikan ke kartu
+	if err := repositories.ReverseGiftCardTransaction((*c).Request().Context(), h.db, transactionID, managerID); err != nil {
+		log.Printf("Failed to reverse gift card for transaction %s: %v", transactionID, err)
+	}
 
 	return SuccessResponse(c, "Transaksi berhasil dibatalkan", map[string]interface{}{
 		"transaction_id": transactionID,
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v5"
+	echomw "github.com/labstack/echo/v5/middleware"
+)
+
+// RateLimitPerMinute membatasi jumlah request per IP untuk endpoint publik
+// (misalnya pesan mandiri tamu lewat QR meja)
+func RateLimitPerMinute(limit int) echo.MiddlewareFunc {
+	if limit <= 0 {
+		limit = 30
+	}
+	return echomw.RateLimiterWithConfig(echomw.RateLimiterConfig{
+		Store: echomw.NewRateLimiterMemoryStoreWithConfig(echomw.RateLimiterMemoryStoreConfig{
+			Rate:      float64(limit) / 60,
+			Burst:     limit,
+			ExpiresIn: 3 * time.Minute,
+		}),
+		IdentifierExtractor: func(c *echo.Context) (string, error) {
+			return c.RealIP(), nil
+		},
+		ErrorHandler: func(c *echo.Context, err error) error {
+			return errorResponse(c, http.StatusForbidden, "Gagal mengenali klien")
+		},
+		DenyHandler: func(c *echo.Context, identifier string, err error) error {
+			return errorResponse(c, http.StatusTooManyRequests, "Terlalu banyak permintaan, coba lagi sebentar")
+		},
+	})
+}
@@ -40,14 +40,15 @@ type OutletConfig struct {
 }
 
 type AdditionalCharge struct {
-	ID         int64     `json:"id"`
-	OutletID   string    `json:"outlet_id"`
-	Name       string    `json:"name"`
-	ChargeType string    `json:"charge_type"`
-	Value      float64   `json:"value"`
-	IsActive   bool      `json:"is_active"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID          int64     `json:"id"`
+	OutletID    string    `json:"outlet_id"`
+	Name        string    `json:"name"`
+	ChargeType  string    `json:"charge_type"`
+	Value       float64   `json:"value"`
+	IsActive    bool      `json:"is_active"`
+	ManagedByHQ bool      `json:"managed_by_hq"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 // SyncLog represents a sync operation log
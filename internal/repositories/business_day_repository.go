@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+const (
+	ReportTypeX = "X"
+	ReportTypeZ = "Z"
+)
+
+// SalesReport adalah ringkasan penjualan satu periode hari bisnis. X report dihitung
+// ulang setiap diminta, Z report disimpan apa adanya saat hari bisnis ditutup.
+type SalesReport struct {
+	ReportType     string               `json:"report_type"`
+	ZNumber        int64                `json:"z_number,omitempty"`
+	BusinessDate   string               `json:"business_date"`
+	PeriodStart    time.Time            `json:"period_start"`
+	PeriodEnd      time.Time            `json:"period_end"`
+	OrdersCount    int64                `json:"orders_count"`
+	Pax            int64                `json:"pax"`
+	GrossSales     float64              `json:"gross_sales"`
+	Discounts      float64              `json:"discounts"`
+	Charges        float64              `json:"charges"`
+	Taxes          float64              `json:"taxes"`
+	NetSales       float64              `json:"net_sales"`
+	RefundsCount   int64                `json:"refunds_count"`
+	Refunds        float64              `json:"refunds"`
+	VoidsCount     int64                `json:"voids_count"`
+	Voids          float64              `json:"voids"`
+	CancelledCount int64                `json:"cancelled_count"`
+	Cancelled      float64              `json:"cancelled"`
+	Tips           float64              `json:"tips"`
+	PaymentsTotal  float64              `json:"payments_total"`
+	CashVariance   float64              `json:"cash_variance"`
+	DiscountLines  []SalesReportLine    `json:"discount_lines"`
+	ChargeLines    []SalesReportLine    `json:"charge_lines"`
+	TaxLines       []SalesReportTax     `json:"tax_lines"`
+	PaymentMethods []SalesReportPayment `json:"payment_methods"`
+	Shifts         []SalesReportShift   `json:"shifts"`
+
+	// Deposit bukan penjualan: pemakaian deposit tampil sebagai metode deposit,
+	// saldo yang belum dipakai/direfund adalah kewajiban per akhir periode
+	DepositsReceived float64 `json:"deposits_received"`
+	DepositsApplied  float64 `json:"deposits_applied"`
+	DepositsRefunded float64 `json:"deposits_refunded"`
+	DepositLiability float64 `json:"deposit_liability"`
+
+	// Penjualan gift card bukan pendapatan; penukaran tampil sebagai metode gift_card
+	GiftCardsSold     float64 `json:"gift_cards_sold"`
+	GiftCardsRedeemed float64 `json:"gift_cards_redeemed"`
+	GiftCardLiability float64 `json:"gift_card_liability"`
+}
+
+type SalesReportLine struct {
+	Name   string  `json:"name"`
+	Count  int64   `json:"count"`
+	Amount float64 `json:"amount"`
+}
+
+// SalesReportTax adalah rekap pajak per tarif; pajak inklusif sudah termasuk harga
+type SalesReportTax struct {
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	IsInclusive   bool    `json:"is_inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// SalesReportPayment adalah penerimaan per metode pembayaran setelah dikurangi refund
+type SalesReportPayment struct {
+	Code       string  `json:"code"`
+	Name       string  `json:"name"`
+	MethodType string  `json:"method_type"`
+	Count      int64   `json:"count"`
+	Amount     float64 `json:"amount"`
+	Refunds    float64 `json:"refunds"`
+	Net        float64 `json:"net"`
+}
+
+// SalesReportShift adalah shift kasir yang ditutup dalam periode beserta selisih kasnya
+type SalesReportShift struct {
+	ShiftID      string     `json:"shift_id"`
+	CashierName  string     `json:"cashier_name"`
+	TerminalName string     `json:"terminal_name"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	ExpectedCash *float64   `json:"expected_cash"`
+	CountedCash  *float64   `json:"counted_cash"`
+	CashVariance *float64   `json:"cash_variance"`
+	ApprovedBy   string     `json:"approved_by,omitempty"`
+}
+
+// ZReport adalah penutupan hari bisnis dengan nomor Z berurutan. Baris z_reports
+// tidak bisa diubah/dihapus (dijaga trigger database).
+type ZReport struct {
+	ID            string      `json:"id"`
+	ZNumber       int64       `json:"z_number"`
+	BusinessDate  string      `json:"business_date"`
+	PeriodStart   time.Time   `json:"period_start"`
+	PeriodEnd     time.Time   `json:"period_end"`
+	OrdersCount   int64       `json:"orders_count"`
+	NetSales      float64     `json:"net_sales"`
+	PaymentsTotal float64     `json:"payments_total"`
+	ClosedBy      string      `json:"closed_by"`
+	ClosedByName  string      `json:"closed_by_name"`
+	CreatedAt     time.Time   `json:"created_at"`
+	Report        SalesReport `json:"report"`
+}
+
+var (
+	ErrZReportNotFound          = errors.New("Z report tidak ditemukan")
+	ErrBusinessDayClosed        = errors.New("hari bisnis sudah ditutup (Z report), data tidak bisa diubah")
+	ErrInvalidBusinessDate      = errors.New("tanggal bisnis tidak valid")
+	ErrBusinessDayPendingOrders = errors.New("masih ada order yang belum dibayar")
+	ErrBusinessDayOpenShift     = errors.New("masih ada shift kasir yang terbuka")
+)
+
+// IsBusinessDayClosed mengenali penolakan dari trigger database saat data hari
+// bisnis yang sudah ditutup Z report akan diubah.
+func IsBusinessDayClosed(err error) bool {
+	if err == nil {
+		return false
+	}
+	return errors.Is(err, ErrBusinessDayClosed) || strings.Contains(err.Error(), ErrBusinessDayClosed.Error())
+}
+
+type BusinessDayRepository interface {
+	GetCurrentReport(ctx context.Context) (*SalesReport, error)
+	CloseDay(ctx context.Context, businessDate string, closedBy string) (*ZReport, error)
+	List(ctx context.Context, limit, offset int64) ([]ZReport, error)
+	Count(ctx context.Context) (int64, error)
+	GetByID(ctx context.Context, id string) (*ZReport, error)
+}
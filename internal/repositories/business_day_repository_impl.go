@@ -0,0 +1,481 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type businessDayRepository struct {
+	db *sql.DB
+}
+
+func NewBusinessDayRepository(dbConn *sql.DB) BusinessDayRepository {
+	return &businessDayRepository{db: dbConn}
+}
+
+// Order periode berjalan adalah order yang belum ditandai z_report_id. Penjualan
+// hanya menghitung order lunas yang tidak digabung, tidak di-void dan tidak dibatalkan.
+const openSalesOrderFilter = `
+	o.z_report_id IS NULL
+	AND o.payment_status = 'paid'
+	AND o.is_merged = 0
+	AND o.voided_at IS NULL
+	AND NOT EXISTS (
+		SELECT 1
+		FROM transactions t
+		WHERE t.order_id = o.id
+		AND t.status = 'cancelled'
+	)`
+
+// lastPeriodEnd mengembalikan akhir periode Z report terakhir (zero time jika
+// belum pernah ada Z report) dan tanggal bisnisnya.
+func lastPeriodEnd(ctx context.Context, q db.DBTX) (time.Time, string, error) {
+	var periodEnd time.Time
+	var businessDate string
+	err := q.QueryRowContext(ctx, `
+		SELECT period_end, business_date
+		FROM z_reports
+		ORDER BY z_number DESC
+		LIMIT 1
+	`).Scan(&periodEnd, &businessDate)
+	if err == sql.ErrNoRows {
+		return time.Time{}, "", nil
+	}
+	return periodEnd, businessDate, err
+}
+
+// buildSalesReport menghitung ringkasan periode (start, end]. Data order diambil
+// dari order yang belum ditutup Z, data pembayaran/refund/tip dari rentang waktu.
+func buildSalesReport(ctx context.Context, q db.DBTX, start, end time.Time) (*SalesReport, error) {
+	report := &SalesReport{
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		DiscountLines:  []SalesReportLine{},
+		ChargeLines:    []SalesReportLine{},
+		TaxLines:       []SalesReportTax{},
+		PaymentMethods: []SalesReportPayment{},
+		Shifts:         []SalesReportShift{},
+	}
+	if start.IsZero() {
+		var firstOrder time.Time
+		err := q.QueryRowContext(ctx, "SELECT created_at FROM orders ORDER BY created_at LIMIT 1").Scan(&firstOrder)
+		switch {
+		case err == sql.ErrNoRows:
+			report.PeriodStart = end
+		case err != nil:
+			return nil, err
+		default:
+			report.PeriodStart = firstOrder
+		}
+	}
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(o.pax), 0), COALESCE(SUM(o.total_amount), 0)
+		FROM orders o
+		WHERE `+openSalesOrderFilter,
+	).Scan(&report.OrdersCount, &report.Pax, &report.NetSales); err != nil {
+		return nil, fmt.Errorf("gagal menghitung penjualan: %w", err)
+	}
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(oi.qty * oi.price), 0)
+		FROM order_items oi
+		INNER JOIN orders o ON o.id = oi.order_id
+		WHERE `+openSalesOrderFilter,
+	).Scan(&report.GrossSales); err != nil {
+		return nil, fmt.Errorf("gagal menghitung penjualan kotor: %w", err)
+	}
+
+	chargeRows, err := q.QueryContext(ctx, `
+		SELECT oac.name, COUNT(DISTINCT oac.order_id), COALESCE(SUM(oac.applied_amount), 0)
+		FROM order_additional_charges oac
+		INNER JOIN orders o ON o.id = oac.order_id
+		WHERE `+openSalesOrderFilter+`
+		GROUP BY oac.name, oac.applied_amount < 0
+		ORDER BY oac.name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung diskon dan biaya tambahan: %w", err)
+	}
+	for chargeRows.Next() {
+		var line SalesReportLine
+		if err := chargeRows.Scan(&line.Name, &line.Count, &line.Amount); err != nil {
+			chargeRows.Close()
+			return nil, err
+		}
+		if line.Amount < 0 {
+			report.Discounts += line.Amount
+			report.DiscountLines = append(report.DiscountLines, line)
+		} else if line.Amount > 0 {
+			report.Charges += line.Amount
+			report.ChargeLines = append(report.ChargeLines, line)
+		}
+	}
+	chargeRows.Close()
+	if err := chargeRows.Err(); err != nil {
+		return nil, err
+	}
+
+	taxRows, err := q.QueryContext(ctx, `
+		SELECT ot.name, ot.rate, ot.is_inclusive,
+		       COALESCE(SUM(ot.taxable_amount), 0), COALESCE(SUM(ot.tax_amount), 0)
+		FROM order_taxes ot
+		INNER JOIN orders o ON o.id = ot.order_id
+		WHERE `+openSalesOrderFilter+`
+		GROUP BY ot.name, ot.rate, ot.is_inclusive
+		ORDER BY ot.name, ot.rate
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghitung pajak: %w", err)
+	}
+	for taxRows.Next() {
+		var line SalesReportTax
+		var isInclusive int64
+		if err := taxRows.Scan(&line.Name, &line.Rate, &isInclusive, &line.TaxableAmount, &line.TaxAmount); err != nil {
+			taxRows.Close()
+			return nil, err
+		}
+		line.IsInclusive = isInclusive == 1
+		if !line.IsInclusive {
+			report.Taxes += line.TaxAmount
+		}
+		report.TaxLines = append(report.TaxLines, line)
+	}
+	taxRows.Close()
+	if err := taxRows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(total_amount), 0)
+		FROM orders
+		WHERE z_report_id IS NULL AND voided_at IS NOT NULL
+	`).Scan(&report.VoidsCount, &report.Voids); err != nil {
+		return nil, fmt.Errorf("gagal menghitung void: %w", err)
+	}
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(total_amount), 0)
+		FROM transactions
+		WHERE cancelled_at IS NOT NULL AND transaction_date > ? AND transaction_date <= ?
+	`, start, end).Scan(&report.CancelledCount, &report.Cancelled); err != nil {
+		return nil, fmt.Errorf("gagal menghitung transaksi batal: %w", err)
+	}
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(amount), 0)
+		FROM refunds
+		WHERE created_at > ? AND created_at <= ?
+	`, start, end).Scan(&report.RefundsCount, &report.Refunds); err != nil {
+		return nil, fmt.Errorf("gagal menghitung refund: %w", err)
+	}
+	if err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(pt.amount), 0)
+		FROM payment_tips pt
+		JOIN transactions t ON t.id = pt.transaction_id AND t.cancelled_at IS NULL
+		WHERE pt.created_at > ? AND pt.created_at <= ?
+	`, start, end).Scan(&report.Tips); err != nil {
+		return nil, fmt.Errorf("gagal menghitung tip: %w", err)
+	}
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN event_type = 'received' AND created_at > ? THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN event_type = 'applied' AND created_at > ? THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN event_type = 'refunded' AND created_at > ? THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN event_type = 'received' THEN amount ELSE -amount END), 0)
+		FROM deposit_events
+		WHERE created_at <= ?
+	`, start, start, start, end).Scan(&report.DepositsReceived, &report.DepositsApplied, &report.DepositsRefunded,
+		&report.DepositLiability); err != nil {
+		return nil, fmt.Errorf("gagal menghitung deposit: %w", err)
+	}
+	if err := q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN entry_type IN ('issue', 'topup') AND created_at > ? THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN created_at > ? THEN
+				CASE entry_type WHEN 'redeem' THEN amount WHEN 'reversal' THEN -amount ELSE 0 END
+			ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN entry_type = 'redeem' THEN -amount ELSE amount END), 0)
+		FROM gift_card_ledger
+		WHERE created_at <= ?
+	`, start, start, end).Scan(&report.GiftCardsSold, &report.GiftCardsRedeemed, &report.GiftCardLiability); err != nil {
+		return nil, fmt.Errorf("gagal menghitung gift card: %w", err)
+	}
+
+	if err := loadReportPayments(ctx, q, report, start, end); err != nil {
+		return nil, err
+	}
+	if err := loadReportShifts(ctx, q, report, start, end); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// loadReportPayments merekap transaksi (tidak dibatalkan) dan refund (payment
+// negatif) per metode. Metode yang sudah dihapus tetap tampil dengan kodenya.
+func loadReportPayments(ctx context.Context, q db.DBTX, report *SalesReport, start, end time.Time) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT p.payment_method, COALESCE(pm.name, p.payment_method), COALESCE(pm.method_type, 'other'),
+		       COALESCE(pm.sort_order, 999), SUM(p.cnt), SUM(p.amount), SUM(p.refunds)
+		FROM (
+			SELECT payment_method, COUNT(*) AS cnt, SUM(total_amount) AS amount, 0 AS refunds
+			FROM transactions
+			WHERE cancelled_at IS NULL AND transaction_date > ? AND transaction_date <= ?
+			GROUP BY payment_method
+			UNION ALL
+			SELECT payment_method, 0, 0, SUM(-amount)
+			FROM payments
+			WHERE amount < 0 AND created_at > ? AND created_at <= ?
+			GROUP BY payment_method
+		) p
+		LEFT JOIN payment_methods pm ON pm.code = p.payment_method
+		GROUP BY p.payment_method
+	`, start, end, start, end)
+	if err != nil {
+		return fmt.Errorf("gagal merekap metode pembayaran: %w", err)
+	}
+	defer rows.Close()
+
+	sortOrders := map[string]int64{}
+	for rows.Next() {
+		var line SalesReportPayment
+		var sortOrder int64
+		if err := rows.Scan(&line.Code, &line.Name, &line.MethodType, &sortOrder, &line.Count, &line.Amount, &line.Refunds); err != nil {
+			return err
+		}
+		line.Net = line.Amount - line.Refunds
+		report.PaymentsTotal += line.Net
+		sortOrders[line.Code] = sortOrder
+		report.PaymentMethods = append(report.PaymentMethods, line)
+	}
+	sort.SliceStable(report.PaymentMethods, func(i, j int) bool {
+		a, b := report.PaymentMethods[i], report.PaymentMethods[j]
+		if sortOrders[a.Code] != sortOrders[b.Code] {
+			return sortOrders[a.Code] < sortOrders[b.Code]
+		}
+		return a.Name < b.Name
+	})
+	return rows.Err()
+}
+
+func loadReportShifts(ctx context.Context, q db.DBTX, report *SalesReport, start, end time.Time) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT cs.id, COALESCE(u.full_name, ''), COALESCE(t.name, ''), cs.opened_at, cs.closed_at,
+		       cs.expected_cash, cs.counted_cash, cs.cash_variance, COALESCE(m.full_name, '')
+		FROM cashier_shifts cs
+		LEFT JOIN users u ON u.id = cs.opened_by
+		LEFT JOIN cashier_terminals t ON t.id = cs.terminal_id
+		LEFT JOIN users m ON m.id = cs.variance_approved_by
+		WHERE cs.status = 'closed' AND cs.closed_at > ? AND cs.closed_at <= ?
+		ORDER BY cs.closed_at
+	`, start, end)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil shift kasir: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var shift SalesReportShift
+		var closedAt sql.NullTime
+		var expected, counted, variance sql.NullFloat64
+		if err := rows.Scan(&shift.ShiftID, &shift.CashierName, &shift.TerminalName, &shift.OpenedAt, &closedAt,
+			&expected, &counted, &variance, &shift.ApprovedBy); err != nil {
+			return err
+		}
+		if closedAt.Valid {
+			shift.ClosedAt = &closedAt.Time
+		}
+		if expected.Valid {
+			shift.ExpectedCash = &expected.Float64
+		}
+		if counted.Valid {
+			shift.CountedCash = &counted.Float64
+		}
+		if variance.Valid {
+			shift.CashVariance = &variance.Float64
+			report.CashVariance += variance.Float64
+		}
+		report.Shifts = append(report.Shifts, shift)
+	}
+	return rows.Err()
+}
+
+func (r *businessDayRepository) GetCurrentReport(ctx context.Context) (*SalesReport, error) {
+	start, _, err := lastPeriodEnd(ctx, r.db)
+	if err != nil {
+		return nil, err
+	}
+	report, err := buildSalesReport(ctx, r.db, start, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	report.ReportType = ReportTypeX
+	report.BusinessDate = time.Now().Format("2006-01-02")
+	return report, nil
+}
+
+// CloseDay menutup hari bisnis: ringkasan periode dibekukan sebagai Z report dan
+// semua order periode ditandai z_report_id sehingga tidak bisa diubah lagi.
+func (r *businessDayRepository) CloseDay(ctx context.Context, businessDate string, closedBy string) (*ZReport, error) {
+	if businessDate == "" {
+		businessDate = time.Now().Format("2006-01-02")
+	}
+	parsedDate, err := time.ParseInLocation("2006-01-02", businessDate, time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("%w: gunakan format YYYY-MM-DD", ErrInvalidBusinessDate)
+	}
+	if parsedDate.After(time.Now()) {
+		return nil, fmt.Errorf("%w: tanggal bisnis tidak boleh di masa depan", ErrInvalidBusinessDate)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	start, lastDate, err := lastPeriodEnd(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	if lastDate != "" && businessDate <= lastDate {
+		return nil, fmt.Errorf("%w: %s sudah ditutup Z report", ErrInvalidBusinessDate, businessDate)
+	}
+
+	var pendingOrders int64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE payment_status != 'paid'
+		AND is_merged = 0
+		AND voided_at IS NULL
+	`).Scan(&pendingOrders); err != nil {
+		return nil, err
+	}
+	if pendingOrders > 0 {
+		return nil, fmt.Errorf("%w (%d order)", ErrBusinessDayPendingOrders, pendingOrders)
+	}
+	var openShifts int64
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM cashier_shifts WHERE status = 'open'").Scan(&openShifts); err != nil {
+		return nil, err
+	}
+	if openShifts > 0 {
+		return nil, ErrBusinessDayOpenShift
+	}
+
+	end := time.Now().UTC().Truncate(time.Second)
+	report, err := buildSalesReport(ctx, tx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var zNumber int64
+	if err := tx.QueryRowContext(ctx, "SELECT COALESCE(MAX(z_number), 0) + 1 FROM z_reports").Scan(&zNumber); err != nil {
+		return nil, err
+	}
+	report.ReportType = ReportTypeZ
+	report.ZNumber = zNumber
+	report.BusinessDate = businessDate
+
+	summary, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+
+	zReport := &ZReport{
+		ID:            utils.GenerateULID(),
+		ZNumber:       zNumber,
+		BusinessDate:  businessDate,
+		PeriodStart:   report.PeriodStart,
+		PeriodEnd:     end,
+		OrdersCount:   report.OrdersCount,
+		NetSales:      report.NetSales,
+		PaymentsTotal: report.PaymentsTotal,
+		ClosedBy:      closedBy,
+		CreatedAt:     end,
+		Report:        *report,
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO z_reports (
+			id, z_number, business_date, period_start, period_end, orders_count,
+			net_sales, payments_total, summary, closed_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, zReport.ID, zReport.ZNumber, zReport.BusinessDate, zReport.PeriodStart, zReport.PeriodEnd, zReport.OrdersCount,
+		zReport.NetSales, zReport.PaymentsTotal, string(summary), closedBy, zReport.CreatedAt); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan Z report: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE orders SET z_report_id = ? WHERE z_report_id IS NULL", zReport.ID); err != nil {
+		return nil, fmt.Errorf("gagal menutup order periode: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	_ = r.db.QueryRowContext(ctx, "SELECT full_name FROM users WHERE id = ?", closedBy).Scan(&zReport.ClosedByName)
+	return zReport, nil
+}
+
+const zReportColumns = `
+	z.id, z.z_number, z.business_date, z.period_start, z.period_end, z.orders_count,
+	z.net_sales, z.payments_total, z.closed_by, COALESCE(u.full_name, ''), z.created_at
+`
+
+func (r *businessDayRepository) List(ctx context.Context, limit, offset int64) ([]ZReport, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+zReportColumns+`
+		FROM z_reports z
+		LEFT JOIN users u ON u.id = z.closed_by
+		ORDER BY z.z_number DESC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []ZReport{}
+	for rows.Next() {
+		var report ZReport
+		if err := rows.Scan(&report.ID, &report.ZNumber, &report.BusinessDate, &report.PeriodStart, &report.PeriodEnd,
+			&report.OrdersCount, &report.NetSales, &report.PaymentsTotal, &report.ClosedBy, &report.ClosedByName,
+			&report.CreatedAt); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+func (r *businessDayRepository) Count(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM z_reports").Scan(&count)
+	return count, err
+}
+
+func (r *businessDayRepository) GetByID(ctx context.Context, id string) (*ZReport, error) {
+	var report ZReport
+	var summary string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT `+zReportColumns+`, z.summary
+		FROM z_reports z
+		LEFT JOIN users u ON u.id = z.closed_by
+		WHERE z.id = ?
+	`, id).Scan(&report.ID, &report.ZNumber, &report.BusinessDate, &report.PeriodStart, &report.PeriodEnd,
+		&report.OrdersCount, &report.NetSales, &report.PaymentsTotal, &report.ClosedBy, &report.ClosedByName,
+		&report.CreatedAt, &summary)
+	if err == sql.ErrNoRows {
+		return nil, ErrZReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(summary), &report.Report); err != nil {
+		return nil, fmt.Errorf("gagal membaca ringkasan Z report: %w", err)
+	}
+	return &report, nil
+}
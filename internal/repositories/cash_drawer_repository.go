@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// DrawerSettings adalah pengaturan pulsa laci kas (ESC p) pada sebuah printer struk.
+// Pin 2 atau 5 sesuai konektor laci; durasi on/off dalam milidetik.
+type DrawerSettings struct {
+	PrinterID string `json:"printer_id"`
+	Enabled   bool   `json:"enabled"`
+	Pin       int    `json:"pin"`
+	OnMs      int    `json:"on_ms"`
+	OffMs     int    `json:"off_ms"`
+}
+
+// CashDrawerEvent adalah catatan audit satu kali laci kas dibuka
+type CashDrawerEvent struct {
+	ID           string    `json:"id"`
+	PrinterID    *string   `json:"printer_id"`
+	PrinterName  string    `json:"printer_name,omitempty"`
+	TerminalID   *string   `json:"terminal_id"`
+	TerminalName string    `json:"terminal_name,omitempty"`
+	ShiftID      *string   `json:"shift_id"`
+	Source       string    `json:"source"`
+	ReferenceID  *string   `json:"reference_id"`
+	Reason       string    `json:"reason"`
+	OpenedBy     string    `json:"opened_by"`
+	OpenedByName string    `json:"opened_by_name,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CashDrawerEventFilter membatasi log laci kas per shift, terminal atau rentang tanggal
+type CashDrawerEventFilter struct {
+	ShiftID    string
+	TerminalID string
+	Source     string
+	StartDate  time.Time
+	EndDate    time.Time
+}
+
+const (
+	DrawerSourcePayment      = "payment"
+	DrawerSourceSplitPayment = "split_payment"
+	DrawerSourceCashIn       = "cash_in"
+	DrawerSourceCashOut      = "cash_out"
+	DrawerSourceManual       = "manual"
+)
+
+var (
+	ErrDrawerNotConfigured   = errors.New("laci kas belum diatur pada printer struk terminal ini")
+	ErrInvalidDrawerSettings = errors.New("pengaturan laci kas tidak valid")
+	ErrDrawerPrinterNotFound = errors.New("printer tidak ditemukan")
+)
+
+type CashDrawerRepository interface {
+	GetSettings(ctx context.Context, printerID string) (*DrawerSettings, error)
+	UpdateSettings(ctx context.Context, settings *DrawerSettings) error
+	ListEvents(ctx context.Context, filter CashDrawerEventFilter) ([]CashDrawerEvent, error)
+}
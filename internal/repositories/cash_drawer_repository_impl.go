@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type cashDrawerRepository struct {
+	db *sql.DB
+}
+
+func NewCashDrawerRepository(dbConn *sql.DB) CashDrawerRepository {
+	return &cashDrawerRepository{db: dbConn}
+}
+
+// OpenCashDrawer memilih printer laci kas lalu mencatat event ke log audit. Printer
+// terminal dipakai jika diatur, selain itu printer struk/kasir aktif pertama yang
+// laci kasnya diaktifkan. Terminal diambil dari shift jika tidak diisi.
+// Mengembalikan ErrDrawerNotConfigured jika tidak ada laci yang bisa dibuka.
+func OpenCashDrawer(ctx context.Context, q db.DBTX, event *CashDrawerEvent) error {
+	if event.TerminalID == nil && event.ShiftID != nil && *event.ShiftID != "" {
+		var terminalID sql.NullString
+		if err := q.QueryRowContext(ctx, "SELECT terminal_id FROM cashier_shifts WHERE id = ?", *event.ShiftID).Scan(&terminalID); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if terminalID.Valid {
+			event.TerminalID = &terminalID.String
+		}
+	}
+	if event.ShiftID != nil && *event.ShiftID == "" {
+		event.ShiftID = nil
+	}
+
+	var printerID string
+	var enabled int64
+	var err error
+	var terminalPrinter sql.NullString
+	if event.TerminalID != nil {
+		if err := q.QueryRowContext(ctx, "SELECT printer_id FROM cashier_terminals WHERE id = ?", *event.TerminalID).Scan(&terminalPrinter); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+	if terminalPrinter.Valid {
+		err = q.QueryRowContext(ctx, `
+			SELECT id, drawer_enabled
+			FROM printers
+			WHERE id = ? AND is_active = 1
+		`, terminalPrinter.String).Scan(&printerID, &enabled)
+	} else {
+		err = q.QueryRowContext(ctx, `
+			SELECT id, drawer_enabled
+			FROM printers
+			WHERE is_active = 1 AND drawer_enabled = 1 AND printer_type IN ('struk', 'cashier')
+			ORDER BY CASE printer_type WHEN 'struk' THEN 0 ELSE 1 END, created_at
+			LIMIT 1
+		`).Scan(&printerID, &enabled)
+	}
+	if err == sql.ErrNoRows || (err == nil && enabled != 1) {
+		return ErrDrawerNotConfigured
+	}
+	if err != nil {
+		return err
+	}
+
+	event.ID = utils.GenerateULID()
+	event.PrinterID = &printerID
+	event.Reason = strings.TrimSpace(event.Reason)
+	event.CreatedAt = time.Now().UTC()
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO cash_drawer_events (
+			id, printer_id, terminal_id, shift_id, source, reference_id, reason, opened_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.ID, event.PrinterID, event.TerminalID, event.ShiftID, event.Source, event.ReferenceID,
+		event.Reason, event.OpenedBy, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat pembukaan laci kas: %w", err)
+	}
+	return nil
+}
+
+func (r *cashDrawerRepository) GetSettings(ctx context.Context, printerID string) (*DrawerSettings, error) {
+	settings := DrawerSettings{PrinterID: printerID}
+	var enabled int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT drawer_enabled, drawer_pin, drawer_on_ms, drawer_off_ms
+		FROM printers
+		WHERE id = ?
+	`, printerID).Scan(&enabled, &settings.Pin, &settings.OnMs, &settings.OffMs)
+	if err == sql.ErrNoRows {
+		return nil, ErrDrawerPrinterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	settings.Enabled = enabled == 1
+	return &settings, nil
+}
+
+func (r *cashDrawerRepository) UpdateSettings(ctx context.Context, settings *DrawerSettings) error {
+	if settings.Pin != 2 && settings.Pin != 5 {
+		return fmt.Errorf("%w: pin harus 2 atau 5", ErrInvalidDrawerSettings)
+	}
+	if settings.OnMs < 2 || settings.OnMs > 510 || settings.OffMs < 2 || settings.OffMs > 510 {
+		return fmt.Errorf("%w: on_ms dan off_ms harus 2-510 ms", ErrInvalidDrawerSettings)
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE printers
+		SET drawer_enabled = ?, drawer_pin = ?, drawer_on_ms = ?, drawer_off_ms = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, boolToInt(settings.Enabled), settings.Pin, settings.OnMs, settings.OffMs, settings.PrinterID)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan pengaturan laci kas: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrDrawerPrinterNotFound
+	}
+	return nil
+}
+
+func (r *cashDrawerRepository) ListEvents(ctx context.Context, filter CashDrawerEventFilter) ([]CashDrawerEvent, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	if filter.ShiftID != "" {
+		conditions = append(conditions, "e.shift_id = ?")
+		args = append(args, filter.ShiftID)
+	} else {
+		conditions = append(conditions, "e.created_at BETWEEN ? AND ?")
+		args = append(args, filter.StartDate, filter.EndDate)
+	}
+	if filter.TerminalID != "" {
+		conditions = append(conditions, "e.terminal_id = ?")
+		args = append(args, filter.TerminalID)
+	}
+	if filter.Source != "" {
+		conditions = append(conditions, "e.source = ?")
+		args = append(args, filter.Source)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.id, e.printer_id, COALESCE(p.name, ''), e.terminal_id, COALESCE(t.name, ''), e.shift_id,
+		       e.source, e.reference_id, COALESCE(e.reason, ''), e.opened_by, COALESCE(u.full_name, ''), e.created_at
+		FROM cash_drawer_events e
+		LEFT JOIN printers p ON p.id = e.printer_id
+		LEFT JOIN cashier_terminals t ON t.id = e.terminal_id
+		LEFT JOIN users u ON u.id = e.opened_by
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY e.created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []CashDrawerEvent{}
+	for rows.Next() {
+		var event CashDrawerEvent
+		var printerID, terminalID, shiftID, referenceID sql.NullString
+		if err := rows.Scan(&event.ID, &printerID, &event.PrinterName, &terminalID, &event.TerminalName, &shiftID,
+			&event.Source, &referenceID, &event.Reason, &event.OpenedBy, &event.OpenedByName, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if printerID.Valid {
+			event.PrinterID = &printerID.String
+		}
+		if terminalID.Valid {
+			event.TerminalID = &terminalID.String
+		}
+		if shiftID.Valid {
+			event.ShiftID = &shiftID.String
+		}
+		if referenceID.Valid {
+			event.ReferenceID = &referenceID.String
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
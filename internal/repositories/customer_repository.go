@@ -3,12 +3,101 @@ package repositories
 import (
 	"backend/internal/db"
 	"context"
+	"errors"
+	"strings"
 	"time"
 )
 
+// CustomerProfile adalah data lengkap pelanggan. Statistik lifetime (kunjungan, belanja)
+// ditambah setiap order lunas, bukan dihitung ulang dari riwayat order.
+type CustomerProfile struct {
+	ID           string             `json:"id"`
+	Name         string             `json:"name"`
+	Phone        string             `json:"phone"`
+	Email        string             `json:"email"`
+	Birthday     *string            `json:"birthday"`
+	Tags         []string           `json:"tags"`
+	Allergies    string             `json:"allergies"`
+	DietaryNotes string             `json:"dietary_notes"`
+	TotalVisits  int64              `json:"total_visits"`
+	TotalSpent   float64            `json:"total_spent"`
+	LastVisitAt  *time.Time         `json:"last_visit_at"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	LastItems    []CustomerLastItem `json:"last_items,omitempty"`
+}
+
+// CustomerLastItem adalah menu yang terakhir dipesan pelanggan, qty dijumlah dari order terakhir
+type CustomerLastItem struct {
+	ProductID     *string   `json:"product_id"`
+	ProductName   string    `json:"product_name"`
+	Qty           int64     `json:"qty"`
+	LastOrderID   string    `json:"last_order_id"`
+	LastOrderedAt time.Time `json:"last_ordered_at"`
+}
+
+type CustomerInput struct {
+	Name         string
+	Phone        string
+	Email        string
+	Birthday     string
+	Tags         []string
+	Allergies    string
+	DietaryNotes string
+}
+
+type CustomerFilter struct {
+	// Search mencari di nama, nomor HP, email dan tag
+	Search        string
+	Tag           string
+	BirthdayMonth int
+	// Sort: name (default), recent, visits atau spent
+	Sort   string
+	Limit  int
+	Offset int
+}
+
+// CustomerMergeResult adalah hasil penggabungan pelanggan duplikat ke satu profil
+type CustomerMergeResult struct {
+	Customer    *CustomerProfile `json:"customer"`
+	MergedIDs   []string         `json:"merged_ids"`
+	MovedOrders int64            `json:"moved_orders"`
+}
+
+var (
+	ErrInvalidCustomer     = errors.New("data pelanggan tidak valid")
+	ErrCustomerNotFound    = errors.New("pelanggan tidak ditemukan")
+	ErrCustomerPhoneExists = errors.New("nomor HP sudah terdaftar")
+	ErrCustomerInUse       = errors.New("pelanggan sudah memiliki riwayat transaksi")
+)
+
+// NormalizeCustomerTags merapikan tag: huruf kecil, tanpa spasi di ujung, tanpa koma dan duplikat
+func NormalizeCustomerTags(tags []string) []string {
+	normalized := []string{}
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, ",", " ")))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
 type CustomerRepository interface {
 	Create(ctx context.Context, name, phone string) (*db.Customer, error)
 	FindByID(ctx context.Context, id string) (*db.Customer, error)
 	FindByPhone(ctx context.Context, phone string) (*db.Customer, error)
 	GetTopCustomers(ctx context.Context, startDate, endDate time.Time, limit int64) ([]db.GetTopCustomersRow, error)
+	CreateProfile(ctx context.Context, input CustomerInput) (*CustomerProfile, error)
+	GetProfile(ctx context.Context, id string) (*CustomerProfile, error)
+	ListProfiles(ctx context.Context, filter CustomerFilter) ([]CustomerProfile, int64, error)
+	UpdateProfile(ctx context.Context, id string, input CustomerInput) (*CustomerProfile, error)
+	// Delete hanya untuk pelanggan tanpa riwayat; pelanggan duplikat digabung lewat Merge
+	Delete(ctx context.Context, id string) error
+	GetLastItems(ctx context.Context, id string, limit int) ([]CustomerLastItem, error)
+	// Merge memindahkan seluruh riwayat pelanggan duplikat ke target lalu menghapus duplikatnya
+	Merge(ctx context.Context, targetID string, duplicateIDs []string) (*CustomerMergeResult, error)
 }
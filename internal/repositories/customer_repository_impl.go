@@ -5,6 +5,9 @@ import (
 	"backend/pkg/utils"
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
@@ -91,3 +94,399 @@ func (r *customerRepository) GetTopCustomers(ctx context.Context, startDate, end
 
 	return results, nil
 }
+
+const customerProfileSelect = `
+	SELECT id, name, phone, COALESCE(email, ''), birthday, COALESCE(tags, ''), COALESCE(allergies, ''),
+	       COALESCE(dietary_notes, ''), total_visits, total_spent, last_visit_at, created_at, updated_at
+	FROM customers
+`
+
+// customerReferenceTables adalah tabel yang menyimpan customer_id; dipakai saat merge dan hapus
+var customerReferenceTables = []string{"orders", "deposits", "gift_cards", "reservations", "waitlist_entries", "customer_visits"}
+
+func scanCustomerProfile(scanner interface{ Scan(dest ...any) error }) (*CustomerProfile, error) {
+	var customer CustomerProfile
+	var birthday sql.NullString
+	var tags string
+	var lastVisitAt sql.NullTime
+	if err := scanner.Scan(
+		&customer.ID, &customer.Name, &customer.Phone, &customer.Email, &birthday, &tags, &customer.Allergies,
+		&customer.DietaryNotes, &customer.TotalVisits, &customer.TotalSpent, &lastVisitAt, &customer.CreatedAt, &customer.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	customer.Birthday = nullStringPtr(birthday)
+	customer.Tags = splitCustomerTags(tags)
+	customer.LastVisitAt = nullTimePtr(lastVisitAt)
+	return &customer, nil
+}
+
+func splitCustomerTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
+}
+
+func getCustomerProfile(ctx context.Context, q db.DBTX, id string) (*CustomerProfile, error) {
+	customer, err := scanCustomerProfile(q.QueryRowContext(ctx, customerProfileSelect+" WHERE id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrCustomerNotFound
+	}
+	return customer, err
+}
+
+// checkCustomerPhone memastikan nomor HP belum dipakai pelanggan lain
+func checkCustomerPhone(ctx context.Context, q db.DBTX, phone, exceptID string) error {
+	var count int
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM customers WHERE phone = ? AND id != ?
+	`, phone, exceptID).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrCustomerPhoneExists
+	}
+	return nil
+}
+
+func (r *customerRepository) CreateProfile(ctx context.Context, input CustomerInput) (*CustomerProfile, error) {
+	if err := checkCustomerPhone(ctx, r.db, input.Phone, ""); err != nil {
+		return nil, err
+	}
+
+	id := utils.GenerateULID()
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO customers (id, name, phone, email, birthday, tags, allergies, dietary_notes, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, id, input.Name, input.Phone, nullableID(input.Email), nullableID(input.Birthday), nullableID(strings.Join(input.Tags, ",")),
+		nullableID(input.Allergies), nullableID(input.DietaryNotes)); err != nil {
+		return nil, err
+	}
+	return getCustomerProfile(ctx, r.db, id)
+}
+
+func (r *customerRepository) GetProfile(ctx context.Context, id string) (*CustomerProfile, error) {
+	return getCustomerProfile(ctx, r.db, id)
+}
+
+func (r *customerRepository) ListProfiles(ctx context.Context, filter CustomerFilter) ([]CustomerProfile, int64, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if search := strings.TrimSpace(filter.Search); search != "" {
+		conditions = append(conditions, "(name LIKE ? OR phone LIKE ? OR email LIKE ? OR tags LIKE ?)")
+		like := "%" + search + "%"
+		args = append(args, like, like, like, strings.ToLower(like))
+	}
+	if filter.Tag != "" {
+		conditions = append(conditions, "(',' || COALESCE(tags, '') || ',') LIKE ?")
+		args = append(args, "%,"+filter.Tag+",%")
+	}
+	if filter.BirthdayMonth > 0 {
+		conditions = append(conditions, "substr(birthday, 6, 2) = ?")
+		args = append(args, fmt.Sprintf("%02d", filter.BirthdayMonth))
+	}
+	where := " WHERE " + strings.Join(conditions, " AND ")
+
+	var total int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM customers"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	orderBy := "name COLLATE NOCASE ASC"
+	switch filter.Sort {
+	case "recent":
+		orderBy = "last_visit_at IS NULL, last_visit_at DESC"
+	case "visits":
+		orderBy = "total_visits DESC, total_spent DESC"
+	case "spent":
+		orderBy = "total_spent DESC, total_visits DESC"
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := r.db.QueryContext(ctx, customerProfileSelect+where+" ORDER BY "+orderBy+", id LIMIT ? OFFSET ?",
+		append(args, limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	customers := []CustomerProfile{}
+	for rows.Next() {
+		customer, err := scanCustomerProfile(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		customers = append(customers, *customer)
+	}
+	return customers, total, rows.Err()
+}
+
+func (r *customerRepository) UpdateProfile(ctx context.Context, id string, input CustomerInput) (*CustomerProfile, error) {
+	if _, err := getCustomerProfile(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	if err := checkCustomerPhone(ctx, r.db, input.Phone, id); err != nil {
+		return nil, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE customers
+		SET name = ?, phone = ?, email = ?, birthday = ?, tags = ?, allergies = ?, dietary_notes = ?,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, input.Name, input.Phone, nullableID(input.Email), nullableID(input.Birthday), nullableID(strings.Join(input.Tags, ",")),
+		nullableID(input.Allergies), nullableID(input.DietaryNotes), id); err != nil {
+		return nil, err
+	}
+	return getCustomerProfile(ctx, r.db, id)
+}
+
+func (r *customerRepository) Delete(ctx context.Context, id string) error {
+	if _, err := getCustomerProfile(ctx, r.db, id); err != nil {
+		return err
+	}
+	for _, table := range customerReferenceTables {
+		var count int
+		if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE customer_id = ?", id).Scan(&count); err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%w: gabungkan ke profil lain untuk menghapus", ErrCustomerInUse)
+		}
+	}
+	_, err := r.db.ExecContext(ctx, "DELETE FROM customers WHERE id = ?", id)
+	return err
+}
+
+// GetLastItems mengambil menu dari 10 order terakhir pelanggan, terbaru di atas
+func (r *customerRepository) GetLastItems(ctx context.Context, id string, limit int) ([]CustomerLastItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT oi.product_id, oi.product_name, oi.qty, o.id, o.created_at
+		FROM order_items oi
+		JOIN orders o ON o.id = oi.order_id
+		WHERE o.id IN (
+			SELECT id FROM orders
+			WHERE customer_id = ? AND voided_at IS NULL
+			ORDER BY created_at DESC
+			LIMIT 10
+		)
+		ORDER BY o.created_at DESC, oi.created_at DESC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []CustomerLastItem{}
+	indexByKey := make(map[string]int)
+	for rows.Next() {
+		var item CustomerLastItem
+		var productID sql.NullString
+		if err := rows.Scan(&productID, &item.ProductName, &item.Qty, &item.LastOrderID, &item.LastOrderedAt); err != nil {
+			return nil, err
+		}
+		key := productID.String
+		if !productID.Valid {
+			key = "name:" + item.ProductName
+		}
+		if i, ok := indexByKey[key]; ok {
+			items[i].Qty += item.Qty
+			continue
+		}
+		item.ProductID = nullStringPtr(productID)
+		indexByKey[key] = len(items)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items, nil
+}
+
+func (r *customerRepository) Merge(ctx context.Context, targetID string, duplicateIDs []string) (*CustomerMergeResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	target, err := getCustomerProfile(ctx, tx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := target.Tags
+	allergies := []string{target.Allergies}
+	dietaryNotes := []string{target.DietaryNotes}
+	email := target.Email
+	birthday := ""
+	if target.Birthday != nil {
+		birthday = *target.Birthday
+	}
+
+	result := &CustomerMergeResult{MergedIDs: []string{}}
+	for _, duplicateID := range duplicateIDs {
+		if duplicateID == targetID {
+			return nil, fmt.Errorf("%w: pelanggan tidak bisa digabung ke dirinya sendiri", ErrInvalidCustomer)
+		}
+		duplicate, err := getCustomerProfile(ctx, tx, duplicateID)
+		if err != nil {
+			if errors.Is(err, ErrCustomerNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrCustomerNotFound, duplicateID)
+			}
+			return nil, err
+		}
+
+		// Data kosong di profil utama diisi dari duplikat; tag dan catatan digabung
+		if email == "" {
+			email = duplicate.Email
+		}
+		if birthday == "" && duplicate.Birthday != nil {
+			birthday = *duplicate.Birthday
+		}
+		tags = append(tags, duplicate.Tags...)
+		allergies = append(allergies, duplicate.Allergies)
+		dietaryNotes = append(dietaryNotes, duplicate.DietaryNotes)
+
+		for _, table := range customerReferenceTables {
+			res, err := tx.ExecContext(ctx, "UPDATE "+table+" SET customer_id = ? WHERE customer_id = ?", targetID, duplicateID)
+			if err != nil {
+				return nil, fmt.Errorf("gagal memindah %s pelanggan: %w", table, err)
+			}
+			if table == "orders" {
+				moved, _ := res.RowsAffected()
+				result.MovedOrders += moved
+			}
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM customers WHERE id = ?", duplicateID); err != nil {
+			return nil, fmt.Errorf("gagal menghapus pelanggan duplikat: %w", err)
+		}
+		result.MergedIDs = append(result.MergedIDs, duplicateID)
+	}
+
+	// Statistik lifetime dijumlah ulang dari kunjungan yang kini milik profil utama
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE customers
+		SET email = ?, birthday = ?, tags = ?, allergies = ?, dietary_notes = ?,
+		    total_visits = (SELECT COUNT(*) FROM customer_visits WHERE customer_id = customers.id),
+		    total_spent = (SELECT COALESCE(SUM(amount), 0) FROM customer_visits WHERE customer_id = customers.id),
+		    last_visit_at = (SELECT visited_at FROM customer_visits WHERE customer_id = customers.id ORDER BY visited_at DESC LIMIT 1),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, nullableID(email), nullableID(birthday), nullableID(strings.Join(NormalizeCustomerTags(tags), ",")),
+		nullableID(joinCustomerNotes(allergies)), nullableID(joinCustomerNotes(dietaryNotes)), targetID); err != nil {
+		return nil, fmt.Errorf("gagal memperbarui profil pelanggan: %w", err)
+	}
+
+	result.Customer, err = getCustomerProfile(ctx, tx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// joinCustomerNotes menggabungkan catatan alergi/diet dari beberapa profil tanpa duplikat
+func joinCustomerNotes(notes []string) string {
+	joined := []string{}
+	seen := make(map[string]bool, len(notes))
+	for _, note := range notes {
+		note = strings.TrimSpace(note)
+		key := strings.ToLower(note)
+		if note == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		joined = append(joined, note)
+	}
+	return strings.Join(joined, "; ")
+}
+
+// RecordCustomerVisit mencatat order lunas sebagai kunjungan pelanggan dan menambah statistik
+// lifetime-nya. Aman dipanggil berulang: tiap order hanya dihitung sekali.
+func RecordCustomerVisit(ctx context.Context, q db.DBTX, orderID string) error {
+	var customerID sql.NullString
+	var totalAmount float64
+	var paymentStatus string
+	err := q.QueryRowContext(ctx, `
+		SELECT customer_id, total_amount, payment_status FROM orders WHERE id = ?
+	`, orderID).Scan(&customerID, &totalAmount, &paymentStatus)
+	if err != nil {
+		return err
+	}
+	if paymentStatus != "paid" || customerID.String == "" {
+		return nil
+	}
+
+	res, err := q.ExecContext(ctx, `
+		INSERT OR IGNORE INTO customer_visits (order_id, customer_id, amount, visited_at)
+		SELECT ?, id, ?, CURRENT_TIMESTAMP FROM customers WHERE id = ?
+	`, orderID, totalAmount, customerID.String)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat kunjungan pelanggan: %w", err)
+	}
+	if recorded, _ := res.RowsAffected(); recorded == 0 {
+		return nil
+	}
+	_, err = q.ExecContext(ctx, `
+		UPDATE customers
+		SET total_visits = total_visits + 1,
+		    total_spent = total_spent + ?,
+		    last_visit_at = CURRENT_TIMESTAMP,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, totalAmount, customerID.String)
+	if err != nil {
+		return fmt.Errorf("gagal memperbarui statistik pelanggan: %w", err)
+	}
+	return nil
+}
+
+// DeductCustomerSpend mengurangi belanja lifetime pelanggan saat order-nya di-refund
+func DeductCustomerSpend(ctx context.Context, q db.DBTX, orderID string, amount float64) error {
+	var customerID string
+	err := q.QueryRowContext(ctx, `SELECT customer_id FROM customer_visits WHERE order_id = ?`, orderID).Scan(&customerID)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, `
+		UPDATE customer_visits SET amount = amount - ? WHERE order_id = ?
+	`, amount, orderID); err != nil {
+		return fmt.Errorf("gagal mengurangi kunjungan pelanggan: %w", err)
+	}
+	if _, err := q.ExecContext(ctx, `
+		UPDATE customers
+		SET total_spent = total_spent - ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, amount, customerID); err != nil {
+		return fmt.Errorf("gagal memperbarui statistik pelanggan: %w", err)
+	}
+	return nil
+}
+
+// GetCustomerKitchenNotes mengambil catatan alergi dan diet pelanggan untuk tiket dapur
+func GetCustomerKitchenNotes(ctx context.Context, q db.DBTX, customerID string) (string, string, error) {
+	if customerID == "" {
+		return "", "", nil
+	}
+	var allergies, dietaryNotes string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(allergies, ''), COALESCE(dietary_notes, '') FROM customers WHERE id = ?
+	`, customerID).Scan(&allergies, &dietaryNotes)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	return allergies, dietaryNotes, err
+}
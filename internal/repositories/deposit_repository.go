@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Deposit adalah uang muka (DP) yang diterima sebelum order dilunasi. Saldo yang
+// belum dipakai adalah kewajiban outlet kepada pelanggan.
+type Deposit struct {
+	ID              string         `json:"id"`
+	CustomerID      *string        `json:"customer_id"`
+	CustomerName    string         `json:"customer_name,omitempty"`
+	OrderID         *string        `json:"order_id"`
+	ReservationID   *string        `json:"reservation_id"`
+	Amount          float64        `json:"amount"`
+	Balance         float64        `json:"balance"`
+	PaymentMethod   string         `json:"payment_method"`
+	ReferenceNumber string         `json:"reference_number"`
+	Note            string         `json:"note"`
+	Status          string         `json:"status"`
+	ShiftID         *string        `json:"shift_id"`
+	CreatedBy       string         `json:"created_by"`
+	CreatedByName   string         `json:"created_by_name,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	Events          []DepositEvent `json:"events,omitempty"`
+}
+
+// DepositEvent adalah satu baris jejak audit deposit
+type DepositEvent struct {
+	ID            string    `json:"id"`
+	DepositID     string    `json:"deposit_id"`
+	EventType     string    `json:"event_type"`
+	Amount        float64   `json:"amount"`
+	PaymentMethod string    `json:"payment_method"`
+	OrderID       *string   `json:"order_id"`
+	TransactionID *string   `json:"transaction_id"`
+	ShiftID       *string   `json:"shift_id"`
+	Reason        string    `json:"reason"`
+	ApprovedBy    *string   `json:"approved_by"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedByName string    `json:"created_by_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type DepositFilter struct {
+	CustomerID    string
+	OrderID       string
+	ReservationID string
+	Status        string
+}
+
+// DepositRefundInput mengembalikan sisa saldo deposit; metode kosong berarti
+// dikembalikan dengan metode saat deposit diterima
+type DepositRefundInput struct {
+	DepositID     string
+	PaymentMethod string
+	Reason        string
+	ApprovedBy    string
+	CreatedBy     string
+	ShiftID       string
+}
+
+// DepositShiftSummary adalah deposit yang diterima/direfund lewat laci sebuah shift.
+// Pemakaian deposit tercatat sebagai penjualan metode deposit, bukan di sini.
+type DepositShiftSummary struct {
+	Received       float64 `json:"received"`
+	ReceivedDrawer float64 `json:"received_drawer"`
+	Refunded       float64 `json:"refunded"`
+	RefundedDrawer float64 `json:"refunded_drawer"`
+}
+
+const (
+	DepositStatusHeld     = "held"
+	DepositStatusApplied  = "applied"
+	DepositStatusRefunded = "refunded"
+
+	DepositEventReceived = "received"
+	DepositEventApplied  = "applied"
+	DepositEventRefunded = "refunded"
+
+	// DepositPaymentMethod adalah kode metode pembayaran untuk pemakaian saldo deposit
+	DepositPaymentMethod = "deposit"
+)
+
+var (
+	ErrDepositNotFound = errors.New("deposit tidak ditemukan")
+	ErrInvalidDeposit  = errors.New("data deposit tidak valid")
+	ErrDepositNotHeld  = errors.New("deposit sudah dipakai atau direfund")
+	ErrDepositChanged  = errors.New("saldo deposit berubah, ulangi pembayaran")
+)
+
+type DepositRepository interface {
+	Create(ctx context.Context, deposit *Deposit) error
+	GetByID(ctx context.Context, id string) (*Deposit, error)
+	List(ctx context.Context, filter DepositFilter) ([]Deposit, error)
+	Attach(ctx context.Context, id, orderID string) error
+	Refund(ctx context.Context, input DepositRefundInput) error
+}
@@ -0,0 +1,538 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type depositRepository struct {
+	db *sql.DB
+}
+
+func NewDepositRepository(dbConn *sql.DB) DepositRepository {
+	return &depositRepository{db: dbConn}
+}
+
+// insertDepositEvent mencatat satu baris jejak audit deposit
+func insertDepositEvent(ctx context.Context, q db.DBTX, event *DepositEvent) error {
+	event.ID = utils.GenerateULID()
+	event.CreatedAt = time.Now().UTC()
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO deposit_events (
+			id, deposit_id, event_type, amount, payment_method, order_id, transaction_id,
+			shift_id, reason, approved_by, created_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, event.ID, event.DepositID, event.EventType, event.Amount, event.PaymentMethod, event.OrderID, event.TransactionID,
+		event.ShiftID, sql.NullString{String: event.Reason, Valid: event.Reason != ""}, event.ApprovedBy, event.CreatedBy, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat riwayat deposit: %w", err)
+	}
+	return nil
+}
+
+func nullableID(id string) *string {
+	if id == "" {
+		return nil
+	}
+	return &id
+}
+
+// checkDepositOrder memastikan order ada, belum lunas dan tidak di-void.
+// Mengembalikan customer_id order (kosong jika order tanpa pelanggan).
+func checkDepositOrder(ctx context.Context, q db.DBTX, orderID string) (string, error) {
+	var customerID sql.NullString
+	var paymentStatus string
+	var voidedAt sql.NullTime
+	err := q.QueryRowContext(ctx, `
+		SELECT customer_id, payment_status, voided_at
+		FROM orders
+		WHERE id = ?
+	`, orderID).Scan(&customerID, &paymentStatus, &voidedAt)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: order tidak ditemukan", ErrInvalidDeposit)
+	}
+	if err != nil {
+		return "", err
+	}
+	if voidedAt.Valid {
+		return "", fmt.Errorf("%w: order sudah di-void", ErrInvalidDeposit)
+	}
+	if paymentStatus == "paid" {
+		return "", fmt.Errorf("%w: order sudah lunas", ErrInvalidDeposit)
+	}
+	return customerID.String, nil
+}
+
+func (r *depositRepository) Create(ctx context.Context, deposit *Deposit) error {
+	deposit.Amount = math.Round(deposit.Amount)
+	if deposit.Amount <= 0 {
+		return fmt.Errorf("%w: jumlah deposit harus lebih dari 0", ErrInvalidDeposit)
+	}
+	if deposit.PaymentMethod == DepositPaymentMethod {
+		return fmt.Errorf("%w: deposit tidak bisa dibayar dengan saldo deposit", ErrInvalidDeposit)
+	}
+	if deposit.CustomerID != nil && *deposit.CustomerID == "" {
+		deposit.CustomerID = nil
+	}
+	if deposit.OrderID != nil && *deposit.OrderID == "" {
+		deposit.OrderID = nil
+	}
+	if deposit.ReservationID != nil && *deposit.ReservationID == "" {
+		deposit.ReservationID = nil
+	}
+	if deposit.CustomerID == nil && deposit.OrderID == nil && deposit.ReservationID == nil {
+		return fmt.Errorf("%w: customer_id, order_id atau reservation_id wajib diisi", ErrInvalidDeposit)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if deposit.OrderID != nil {
+		orderCustomerID, err := checkDepositOrder(ctx, tx, *deposit.OrderID)
+		if err != nil {
+			return err
+		}
+		if deposit.CustomerID == nil && orderCustomerID != "" {
+			deposit.CustomerID = &orderCustomerID
+		}
+	}
+	if deposit.ReservationID != nil {
+		// Deposit reservasi milik pelanggan reservasi dan pindah ke order saat tamu duduk
+		var customerID, status string
+		var orderID sql.NullString
+		err := tx.QueryRowContext(ctx, "SELECT customer_id, status, order_id FROM reservations WHERE id = ?", *deposit.ReservationID).
+			Scan(&customerID, &status, &orderID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: reservasi tidak ditemukan", ErrInvalidDeposit)
+		}
+		if err != nil {
+			return err
+		}
+		if status != ReservationStatusBooked && status != ReservationStatusSeated {
+			return fmt.Errorf("%w: reservasi sudah dibatalkan atau no-show", ErrInvalidDeposit)
+		}
+		if deposit.CustomerID == nil {
+			deposit.CustomerID = &customerID
+		}
+		if deposit.OrderID == nil && orderID.Valid {
+			if _, err := checkDepositOrder(ctx, tx, orderID.String); err != nil {
+				return err
+			}
+			deposit.OrderID = &orderID.String
+		}
+	}
+	if deposit.CustomerID != nil {
+		var exists int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM customers WHERE id = ?", *deposit.CustomerID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: pelanggan tidak ditemukan", ErrInvalidDeposit)
+		}
+	}
+
+	now := time.Now().UTC()
+	deposit.ID = utils.GenerateULID()
+	deposit.Balance = deposit.Amount
+	deposit.Status = DepositStatusHeld
+	deposit.ReferenceNumber = strings.TrimSpace(deposit.ReferenceNumber)
+	deposit.Note = strings.TrimSpace(deposit.Note)
+	deposit.CreatedAt = now
+	deposit.UpdatedAt = now
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO deposits (
+			id, customer_id, order_id, reservation_id, amount, balance, payment_method, reference_number, note,
+			status, shift_id, created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, deposit.ID, deposit.CustomerID, deposit.OrderID, deposit.ReservationID, deposit.Amount, deposit.Balance, deposit.PaymentMethod,
+		sql.NullString{String: deposit.ReferenceNumber, Valid: deposit.ReferenceNumber != ""},
+		sql.NullString{String: deposit.Note, Valid: deposit.Note != ""},
+		deposit.Status, deposit.ShiftID, deposit.CreatedBy, now, now); err != nil {
+		return fmt.Errorf("gagal menyimpan deposit: %w", err)
+	}
+
+	event := DepositEvent{
+		DepositID:     deposit.ID,
+		EventType:     DepositEventReceived,
+		Amount:        deposit.Amount,
+		PaymentMethod: deposit.PaymentMethod,
+		OrderID:       deposit.OrderID,
+		ShiftID:       deposit.ShiftID,
+		CreatedBy:     deposit.CreatedBy,
+	}
+	if err := insertDepositEvent(ctx, tx, &event); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	deposit.Events = []DepositEvent{event}
+	return nil
+}
+
+const depositSelect = `
+	SELECT d.id, d.customer_id, COALESCE(c.name, ''), d.order_id, d.reservation_id, d.amount, d.balance, d.payment_method,
+	       COALESCE(d.reference_number, ''), COALESCE(d.note, ''), d.status, d.shift_id,
+	       d.created_by, COALESCE(u.full_name, ''), d.created_at, d.updated_at
+	FROM deposits d
+	LEFT JOIN customers c ON c.id = d.customer_id
+	LEFT JOIN users u ON u.id = d.created_by
+`
+
+func scanDeposit(scanner interface{ Scan(dest ...any) error }) (*Deposit, error) {
+	var deposit Deposit
+	var customerID, orderID, reservationID, shiftID sql.NullString
+	if err := scanner.Scan(&deposit.ID, &customerID, &deposit.CustomerName, &orderID, &reservationID, &deposit.Amount, &deposit.Balance,
+		&deposit.PaymentMethod, &deposit.ReferenceNumber, &deposit.Note, &deposit.Status, &shiftID,
+		&deposit.CreatedBy, &deposit.CreatedByName, &deposit.CreatedAt, &deposit.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if customerID.Valid {
+		deposit.CustomerID = &customerID.String
+	}
+	if orderID.Valid {
+		deposit.OrderID = &orderID.String
+	}
+	if reservationID.Valid {
+		deposit.ReservationID = &reservationID.String
+	}
+	if shiftID.Valid {
+		deposit.ShiftID = &shiftID.String
+	}
+	return &deposit, nil
+}
+
+func (r *depositRepository) GetByID(ctx context.Context, id string) (*Deposit, error) {
+	deposit, err := scanDeposit(r.db.QueryRowContext(ctx, depositSelect+" WHERE d.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrDepositNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT e.id, e.deposit_id, e.event_type, e.amount, e.payment_method, e.order_id, e.transaction_id,
+		       e.shift_id, COALESCE(e.reason, ''), e.approved_by, e.created_by, COALESCE(u.full_name, ''), e.created_at
+		FROM deposit_events e
+		LEFT JOIN users u ON u.id = e.created_by
+		WHERE e.deposit_id = ?
+		ORDER BY e.created_at, e.id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deposit.Events = []DepositEvent{}
+	for rows.Next() {
+		var event DepositEvent
+		var orderID, transactionID, shiftID, approvedBy sql.NullString
+		if err := rows.Scan(&event.ID, &event.DepositID, &event.EventType, &event.Amount, &event.PaymentMethod,
+			&orderID, &transactionID, &shiftID, &event.Reason, &approvedBy, &event.CreatedBy, &event.CreatedByName,
+			&event.CreatedAt); err != nil {
+			return nil, err
+		}
+		if orderID.Valid {
+			event.OrderID = &orderID.String
+		}
+		if transactionID.Valid {
+			event.TransactionID = &transactionID.String
+		}
+		if shiftID.Valid {
+			event.ShiftID = &shiftID.String
+		}
+		if approvedBy.Valid {
+			event.ApprovedBy = &approvedBy.String
+		}
+		deposit.Events = append(deposit.Events, event)
+	}
+	return deposit, rows.Err()
+}
+
+func (r *depositRepository) List(ctx context.Context, filter DepositFilter) ([]Deposit, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if filter.CustomerID != "" {
+		conditions = append(conditions, "d.customer_id = ?")
+		args = append(args, filter.CustomerID)
+	}
+	if filter.OrderID != "" {
+		conditions = append(conditions, "d.order_id = ?")
+		args = append(args, filter.OrderID)
+	}
+	if filter.ReservationID != "" {
+		conditions = append(conditions, "d.reservation_id = ?")
+		args = append(args, filter.ReservationID)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "d.status = ?")
+		args = append(args, filter.Status)
+	}
+
+	rows, err := r.db.QueryContext(ctx, depositSelect+`
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY d.created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deposits := []Deposit{}
+	for rows.Next() {
+		deposit, err := scanDeposit(rows)
+		if err != nil {
+			return nil, err
+		}
+		deposits = append(deposits, *deposit)
+	}
+	return deposits, rows.Err()
+}
+
+// Attach menautkan deposit pelanggan ke order tertentu sehingga dipakai saat order
+// tersebut dilunasi
+func (r *depositRepository) Attach(ctx context.Context, id, orderID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	if err := tx.QueryRowContext(ctx, "SELECT status FROM deposits WHERE id = ?", id).Scan(&status); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrDepositNotFound
+		}
+		return err
+	}
+	if status != DepositStatusHeld {
+		return ErrDepositNotHeld
+	}
+	if _, err := checkDepositOrder(ctx, tx, orderID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE deposits
+		SET order_id = ?, updated_at = ?
+		WHERE id = ?
+	`, orderID, time.Now().UTC(), id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Refund mengembalikan seluruh sisa saldo deposit yang masih held
+func (r *depositRepository) Refund(ctx context.Context, input DepositRefundInput) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status, paymentMethod string
+	var balance float64
+	var orderID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT status, balance, payment_method, order_id
+		FROM deposits
+		WHERE id = ?
+	`, input.DepositID).Scan(&status, &balance, &paymentMethod, &orderID)
+	if err == sql.ErrNoRows {
+		return ErrDepositNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if status != DepositStatusHeld || balance <= 0 {
+		return ErrDepositNotHeld
+	}
+	if input.PaymentMethod == "" {
+		input.PaymentMethod = paymentMethod
+	}
+	if input.PaymentMethod == DepositPaymentMethod {
+		return fmt.Errorf("%w: metode refund tidak valid", ErrInvalidDeposit)
+	}
+
+	event := DepositEvent{
+		DepositID:     input.DepositID,
+		EventType:     DepositEventRefunded,
+		Amount:        balance,
+		PaymentMethod: input.PaymentMethod,
+		ShiftID:       nullableID(input.ShiftID),
+		Reason:        strings.TrimSpace(input.Reason),
+		ApprovedBy:    nullableID(input.ApprovedBy),
+		CreatedBy:     input.CreatedBy,
+	}
+	if orderID.Valid {
+		event.OrderID = &orderID.String
+	}
+	if err := insertDepositEvent(ctx, tx, &event); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE deposits
+		SET balance = 0, status = ?, updated_at = ?
+		WHERE id = ?
+	`, DepositStatusRefunded, event.CreatedAt, input.DepositID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// HeldDepositBalance menjumlahkan saldo deposit yang akan dipakai saat order dilunasi:
+// deposit yang ditautkan ke order, atau deposit pelanggan order yang belum ditautkan.
+func HeldDepositBalance(ctx context.Context, q db.DBTX, orderID string) (float64, error) {
+	var balance float64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(d.balance), 0)
+		FROM deposits d
+		JOIN orders o ON o.id = ?
+		WHERE d.status = 'held'
+		AND (d.order_id = o.id OR (d.order_id IS NULL AND d.customer_id = o.customer_id))
+	`, orderID).Scan(&balance)
+	return balance, err
+}
+
+// ApplyOrderDeposits memakai saldo deposit (tertua lebih dulu) untuk sisa tagihan
+// order. Tiap pemakaian dicatat sebagai payment + transaksi metode deposit sehingga
+// tampil sebagai baris pembayaran. Dipanggil di dalam transaksi pelunasan order agar
+// deposit tidak terpakai jika pelunasan gagal. Mengembalikan total deposit yang dipakai.
+func ApplyOrderDeposits(ctx context.Context, tx db.DBTX, orderID string, remaining float64, createdBy, shiftID string) (float64, error) {
+	remaining = math.Round(remaining)
+	if remaining <= 0 {
+		return 0, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT d.id, d.balance
+		FROM deposits d
+		JOIN orders o ON o.id = ?
+		WHERE d.status = 'held' AND d.balance > 0
+		AND (d.order_id = o.id OR (d.order_id IS NULL AND d.customer_id = o.customer_id))
+		ORDER BY d.created_at, d.id
+	`, orderID)
+	if err != nil {
+		return 0, err
+	}
+	type heldDeposit struct {
+		id      string
+		balance float64
+	}
+	held := []heldDeposit{}
+	for rows.Next() {
+		var item heldDeposit
+		if err := rows.Scan(&item.id, &item.balance); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		held = append(held, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(held) == 0 {
+		return 0, nil
+	}
+
+	applied := 0.0
+	now := time.Now().UTC()
+	for _, item := range held {
+		if remaining <= 0 {
+			break
+		}
+		amount := math.Min(item.balance, remaining)
+		balance := item.balance - amount
+		status := DepositStatusHeld
+		if balance <= 0 {
+			status = DepositStatusApplied
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE deposits
+			SET balance = ?, status = ?, order_id = ?, updated_at = ?
+			WHERE id = ?
+		`, balance, status, orderID, now, item.id); err != nil {
+			return 0, err
+		}
+
+		transaction, err := insertTransaction(ctx, db.New(tx), db.CreateTransactionParams{
+			OrderID:         orderID,
+			TotalAmount:     amount,
+			PaymentMethod:   DepositPaymentMethod,
+			Status:          "completed",
+			TransactionDate: now,
+			CreatedBy:       createdBy,
+			ShiftID:         sql.NullString{String: shiftID, Valid: shiftID != ""},
+		})
+		if err != nil {
+			return 0, fmt.Errorf("gagal mencatat transaksi deposit: %w", err)
+		}
+		transactionID := transaction.ID
+		if _, err := tx.ExecContext(ctx, "UPDATE transactions SET reference_number = ? WHERE id = ?", item.id, transactionID); err != nil {
+			return 0, fmt.Errorf("gagal mencatat transaksi deposit: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO payments (id, order_id, amount, payment_method, payment_note, created_by, shift_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, utils.GenerateULID(), orderID, amount, DepositPaymentMethod, "Deposit "+item.id, createdBy,
+			nullableID(shiftID)); err != nil {
+			return 0, fmt.Errorf("gagal mencatat pembayaran deposit: %w", err)
+		}
+		if err := insertDepositEvent(ctx, tx, &DepositEvent{
+			DepositID:     item.id,
+			EventType:     DepositEventApplied,
+			Amount:        amount,
+			PaymentMethod: DepositPaymentMethod,
+			OrderID:       &orderID,
+			TransactionID: &transactionID,
+			ShiftID:       nullableID(shiftID),
+			CreatedBy:     createdBy,
+		}); err != nil {
+			return 0, err
+		}
+
+		applied += amount
+		remaining -= amount
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE orders
+		SET paid_amount = paid_amount + ?,
+		    payment_status = CASE WHEN paid_amount + ? >= total_amount THEN 'paid' ELSE 'partial' END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, applied, applied, orderID); err != nil {
+		return 0, fmt.Errorf("gagal update pembayaran order: %w", err)
+	}
+	if err := RecordCustomerVisit(ctx, tx, orderID); err != nil {
+		return 0, err
+	}
+	return applied, nil
+}
+
+// GetShiftDepositSummary merekap deposit yang diterima dan direfund pada sebuah shift.
+// Kolom *_drawer hanya metode yang masuk laci kas.
+func GetShiftDepositSummary(ctx context.Context, q db.DBTX, shiftID string) (DepositShiftSummary, error) {
+	var summary DepositShiftSummary
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN e.event_type = 'received' THEN e.amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN e.event_type = 'received' AND COALESCE(pm.counts_to_drawer, e.payment_method = 'cash') = 1 THEN e.amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN e.event_type = 'refunded' THEN e.amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN e.event_type = 'refunded' AND COALESCE(pm.counts_to_drawer, e.payment_method = 'cash') = 1 THEN e.amount ELSE 0 END), 0)
+		FROM deposit_events e
+		LEFT JOIN payment_methods pm ON pm.code = e.payment_method
+		WHERE e.shift_id = ?
+	`, shiftID).Scan(&summary.Received, &summary.ReceivedDrawer, &summary.Refunded, &summary.RefundedDrawer)
+	return summary, err
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FloorArea adalah ruang pada denah lantai (indoor, outdoor, VIP, lantai 2) beserta
+// aturan service charge yang berlaku untuk order di meja area tersebut
+type FloorArea struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	SortOrder   int64            `json:"sort_order"`
+	IsActive    bool             `json:"is_active"`
+	TableCount  int64            `json:"table_count"`
+	ChargeRules []AreaChargeRule `json:"charge_rules"`
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// AreaChargeRule mengganti tarif biaya tambahan (service charge) untuk satu area atau
+// tipe order. IsExempt = biaya tidak berlaku; selain itu ChargeType/Value menggantikan
+// tarif global.
+type AreaChargeRule struct {
+	ChargeID   int64    `json:"charge_id"`
+	ChargeName string   `json:"charge_name"`
+	IsExempt   bool     `json:"is_exempt"`
+	ChargeType *string  `json:"charge_type"`
+	Value      *float64 `json:"value"`
+}
+
+type FloorAreaInput struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	SortOrder   int64  `json:"sort_order"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// FloorSection adalah kelompok meja yang dilayani pelayan tertentu
+type FloorSection struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	AreaID    *string         `json:"area_id"`
+	AreaName  string          `json:"area_name"`
+	Color     string          `json:"color"`
+	TableIDs  []string        `json:"table_ids"`
+	Waiters   []SectionWaiter `json:"waiters"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type SectionWaiter struct {
+	UserID     string    `json:"user_id"`
+	FullName   string    `json:"full_name"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// FloorSectionInput menyimpan section; TableIDs menggantikan seluruh meja section
+type FloorSectionInput struct {
+	Name     string   `json:"name"`
+	AreaID   string   `json:"area_id"`
+	Color    string   `json:"color"`
+	TableIDs []string `json:"table_ids"`
+}
+
+// FloorTable adalah meja beserta letaknya pada denah. Posisi dan ukuran dalam satuan
+// grid editor denah; rotasi dalam derajat.
+type FloorTable struct {
+	ID          string   `json:"id"`
+	TableNumber string   `json:"table_number"`
+	Capacity    int64    `json:"capacity"`
+	Status      string   `json:"status"`
+	AreaID      *string  `json:"area_id"`
+	AreaName    string   `json:"area_name"`
+	SectionID   *string  `json:"section_id"`
+	SectionName string   `json:"section_name"`
+	Waiters     []string `json:"waiters"`
+	Shape       string   `json:"shape"`
+	PosX        float64  `json:"pos_x"`
+	PosY        float64  `json:"pos_y"`
+	Width       float64  `json:"width"`
+	Height      float64  `json:"height"`
+	Rotation    float64  `json:"rotation"`
+}
+
+// TableLayoutInput menyimpan letak satu meja; AreaID/SectionID kosong = tanpa area/section
+type TableLayoutInput struct {
+	TableID   string  `json:"table_id"`
+	AreaID    string  `json:"area_id"`
+	SectionID string  `json:"section_id"`
+	Shape     string  `json:"shape"`
+	PosX      float64 `json:"pos_x"`
+	PosY      float64 `json:"pos_y"`
+	Width     float64 `json:"width"`
+	Height    float64 `json:"height"`
+	Rotation  float64 `json:"rotation"`
+}
+
+// TableCombination adalah set meja yang bisa disatukan untuk rombongan besar.
+// Order gabungan memakai nomor meja utama.
+type TableCombination struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	PrimaryTableID     string    `json:"primary_table_id"`
+	PrimaryTableNumber string    `json:"primary_table_number"`
+	TableIDs           []string  `json:"table_ids"`
+	TableNumbers       []string  `json:"table_numbers"`
+	Capacity           int64     `json:"capacity"`
+	IsActive           bool      `json:"is_active"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type TableCombinationInput struct {
+	Name           string   `json:"name"`
+	PrimaryTableID string   `json:"primary_table_id"`
+	TableIDs       []string `json:"table_ids"`
+	IsActive       *bool    `json:"is_active"`
+}
+
+// FloorPlanArea adalah satu area denah beserta mejanya
+type FloorPlanArea struct {
+	Area   *FloorArea   `json:"area"`
+	Tables []FloorTable `json:"tables"`
+}
+
+// FloorPlan adalah denah lengkap: meja per area (meja tanpa area di Unplaced),
+// section pelayan dan gabungan meja
+type FloorPlan struct {
+	Areas        []FloorPlanArea    `json:"areas"`
+	Unplaced     []FloorTable       `json:"unplaced"`
+	Sections     []FloorSection     `json:"sections"`
+	Combinations []TableCombination `json:"combinations"`
+}
+
+const (
+	TableShapeSquare    = "square"
+	TableShapeRectangle = "rectangle"
+	TableShapeRound     = "round"
+)
+
+var (
+	ErrFloorAreaNotFound        = errors.New("area tidak ditemukan")
+	ErrFloorSectionNotFound     = errors.New("section tidak ditemukan")
+	ErrTableCombinationNotFound = errors.New("gabungan meja tidak ditemukan")
+	ErrInvalidFloorPlan         = errors.New("data denah tidak valid")
+	ErrFloorPlanDuplicate       = errors.New("nama sudah dipakai")
+)
+
+type FloorPlanRepository interface {
+	GetFloorPlan(ctx context.Context) (*FloorPlan, error)
+	SaveLayout(ctx context.Context, layouts []TableLayoutInput) error
+
+	ListAreas(ctx context.Context) ([]FloorArea, error)
+	GetArea(ctx context.Context, id string) (*FloorArea, error)
+	CreateArea(ctx context.Context, input FloorAreaInput) (*FloorArea, error)
+	UpdateArea(ctx context.Context, id string, input FloorAreaInput) (*FloorArea, error)
+	DeleteArea(ctx context.Context, id string) error
+	SetAreaChargeRules(ctx context.Context, id string, rules []AreaChargeRule) (*FloorArea, error)
+
+	ListSections(ctx context.Context) ([]FloorSection, error)
+	GetSection(ctx context.Context, id string) (*FloorSection, error)
+	CreateSection(ctx context.Context, input FloorSectionInput) (*FloorSection, error)
+	UpdateSection(ctx context.Context, id string, input FloorSectionInput) (*FloorSection, error)
+	DeleteSection(ctx context.Context, id string) error
+	SetSectionWaiters(ctx context.Context, id string, userIDs []string) (*FloorSection, error)
+	ListWaiterTables(ctx context.Context, userID string) ([]FloorTable, error)
+
+	ListCombinations(ctx context.Context) ([]TableCombination, error)
+	CreateCombination(ctx context.Context, input TableCombinationInput) (*TableCombination, error)
+	UpdateCombination(ctx context.Context, id string, input TableCombinationInput) (*TableCombination, error)
+	DeleteCombination(ctx context.Context, id string) error
+}
@@ -0,0 +1,942 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type floorPlanRepository struct {
+	db *sql.DB
+}
+
+func NewFloorPlanRepository(dbConn *sql.DB) FloorPlanRepository {
+	return &floorPlanRepository{db: dbConn}
+}
+
+func floorPlanWriteError(err error, name string) error {
+	if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return fmt.Errorf("%w: %s", ErrFloorPlanDuplicate, name)
+	}
+	return err
+}
+
+const floorTableSelect = `
+	SELECT t.id, t.table_number, t.capacity, t.status, t.area_id, COALESCE(a.name, ''), t.section_id,
+	       COALESCE(s.name, ''), t.shape, t.pos_x, t.pos_y, t.width, t.height, t.rotation
+	FROM tables t
+	LEFT JOIN floor_areas a ON a.id = t.area_id
+	LEFT JOIN floor_sections s ON s.id = t.section_id
+`
+
+func (r *floorPlanRepository) listFloorTables(ctx context.Context, where string, args ...interface{}) ([]FloorTable, error) {
+	rows, err := r.db.QueryContext(ctx, floorTableSelect+where+" ORDER BY t.table_number", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := []FloorTable{}
+	for rows.Next() {
+		var table FloorTable
+		var areaID, sectionID sql.NullString
+		if err := rows.Scan(&table.ID, &table.TableNumber, &table.Capacity, &table.Status, &areaID, &table.AreaName,
+			&sectionID, &table.SectionName, &table.Shape, &table.PosX, &table.PosY, &table.Width, &table.Height,
+			&table.Rotation); err != nil {
+			return nil, err
+		}
+		if areaID.Valid {
+			table.AreaID = &areaID.String
+		}
+		if sectionID.Valid {
+			table.SectionID = &sectionID.String
+		}
+		table.Waiters = []string{}
+		tables = append(tables, table)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Nama pelayan per section untuk label meja di denah
+	waiters, err := r.sectionWaiterNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range tables {
+		if tables[i].SectionID != nil {
+			if names, ok := waiters[*tables[i].SectionID]; ok {
+				tables[i].Waiters = names
+			}
+		}
+	}
+	return tables, nil
+}
+
+func (r *floorPlanRepository) sectionWaiterNames(ctx context.Context) (map[string][]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.section_id, u.full_name
+		FROM floor_section_waiters w
+		JOIN users u ON u.id = w.user_id
+		ORDER BY u.full_name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	names := map[string][]string{}
+	for rows.Next() {
+		var sectionID, name string
+		if err := rows.Scan(&sectionID, &name); err != nil {
+			return nil, err
+		}
+		names[sectionID] = append(names[sectionID], name)
+	}
+	return names, rows.Err()
+}
+
+func (r *floorPlanRepository) GetFloorPlan(ctx context.Context) (*FloorPlan, error) {
+	areas, err := r.ListAreas(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := r.listFloorTables(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	sections, err := r.ListSections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	combinations, err := r.ListCombinations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &FloorPlan{
+		Areas:        make([]FloorPlanArea, 0, len(areas)),
+		Unplaced:     []FloorTable{},
+		Sections:     sections,
+		Combinations: combinations,
+	}
+	areaIndex := map[string]int{}
+	for i := range areas {
+		areaIndex[areas[i].ID] = i
+		plan.Areas = append(plan.Areas, FloorPlanArea{Area: &areas[i], Tables: []FloorTable{}})
+	}
+	for _, table := range tables {
+		if table.AreaID != nil {
+			if i, ok := areaIndex[*table.AreaID]; ok {
+				plan.Areas[i].Tables = append(plan.Areas[i].Tables, table)
+				continue
+			}
+		}
+		plan.Unplaced = append(plan.Unplaced, table)
+	}
+	return plan, nil
+}
+
+func validateTableLayout(layout *TableLayoutInput) error {
+	if layout.TableID == "" {
+		return fmt.Errorf("%w: table_id wajib diisi", ErrInvalidFloorPlan)
+	}
+	if layout.Shape == "" {
+		layout.Shape = TableShapeSquare
+	}
+	switch layout.Shape {
+	case TableShapeSquare, TableShapeRectangle, TableShapeRound:
+	default:
+		return fmt.Errorf("%w: shape harus square, rectangle atau round", ErrInvalidFloorPlan)
+	}
+	if layout.Width <= 0 || layout.Height <= 0 {
+		return fmt.Errorf("%w: width dan height harus lebih dari 0", ErrInvalidFloorPlan)
+	}
+	if layout.PosX < 0 || layout.PosY < 0 {
+		return fmt.Errorf("%w: posisi meja tidak boleh negatif", ErrInvalidFloorPlan)
+	}
+	layout.Rotation = math.Mod(layout.Rotation, 360)
+	if layout.Rotation < 0 {
+		layout.Rotation += 360
+	}
+	return nil
+}
+
+func checkFloorRef(ctx context.Context, q db.DBTX, table, id string, notFound error) error {
+	var exists int
+	if err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+table+" WHERE id = ?", id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// SaveLayout menyimpan letak meja dari editor denah sekaligus dalam satu transaksi
+func (r *floorPlanRepository) SaveLayout(ctx context.Context, layouts []TableLayoutInput) error {
+	if len(layouts) == 0 {
+		return fmt.Errorf("%w: layout meja kosong", ErrInvalidFloorPlan)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	for i := range layouts {
+		layout := &layouts[i]
+		if err := validateTableLayout(layout); err != nil {
+			return err
+		}
+		if layout.AreaID != "" {
+			if err := checkFloorRef(ctx, tx, "floor_areas", layout.AreaID, ErrFloorAreaNotFound); err != nil {
+				return err
+			}
+		}
+		if layout.SectionID != "" {
+			if err := checkFloorRef(ctx, tx, "floor_sections", layout.SectionID, ErrFloorSectionNotFound); err != nil {
+				return err
+			}
+		}
+		result, err := tx.ExecContext(ctx, `
+			UPDATE tables
+			SET area_id = ?, section_id = ?, shape = ?, pos_x = ?, pos_y = ?, width = ?, height = ?, rotation = ?,
+			    updated_at = ?
+			WHERE id = ?
+		`, nullableID(layout.AreaID), nullableID(layout.SectionID), layout.Shape, layout.PosX, layout.PosY,
+			layout.Width, layout.Height, layout.Rotation, now, layout.TableID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return fmt.Errorf("%w: meja %s tidak ditemukan", ErrInvalidFloorPlan, layout.TableID)
+		}
+	}
+	return tx.Commit()
+}
+
+const floorAreaSelect = `
+	SELECT a.id, a.name, COALESCE(a.description, ''), a.sort_order, a.is_active,
+	       (SELECT COUNT(*) FROM tables t WHERE t.area_id = a.id), a.created_at, a.updated_at
+	FROM floor_areas a
+`
+
+func scanFloorArea(scanner interface{ Scan(dest ...any) error }) (*FloorArea, error) {
+	var area FloorArea
+	if err := scanner.Scan(&area.ID, &area.Name, &area.Description, &area.SortOrder, &area.IsActive, &area.TableCount,
+		&area.CreatedAt, &area.UpdatedAt); err != nil {
+		return nil, err
+	}
+	area.ChargeRules = []AreaChargeRule{}
+	return &area, nil
+}
+
+func loadAreaChargeRules(ctx context.Context, q db.DBTX, areaID string) ([]AreaChargeRule, error) {
+	return loadChargeRules(ctx, q, "floor_area_charge_rules", "area_id", areaID)
+}
+
+// loadChargeRules membaca aturan biaya tambahan dari tabel aturan area atau tipe order
+func loadChargeRules(ctx context.Context, q db.DBTX, table, keyColumn, key string) ([]AreaChargeRule, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT r.charge_id, COALESCE(c.name, ''), r.is_exempt, r.charge_type, r.value
+		FROM `+table+` r
+		LEFT JOIN additional_charges c ON c.id = r.charge_id
+		WHERE r.`+keyColumn+` = ?
+		ORDER BY r.charge_id
+	`, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []AreaChargeRule{}
+	for rows.Next() {
+		var rule AreaChargeRule
+		var chargeType sql.NullString
+		var value sql.NullFloat64
+		if err := rows.Scan(&rule.ChargeID, &rule.ChargeName, &rule.IsExempt, &chargeType, &value); err != nil {
+			return nil, err
+		}
+		if chargeType.Valid {
+			rule.ChargeType = &chargeType.String
+		}
+		if value.Valid {
+			rule.Value = &value.Float64
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// loadOrderAreaChargeRules mengambil aturan service charge area meja sebuah order,
+// dipetakan per charge_id. Order di meja tanpa area memakai tarif global.
+func loadOrderAreaChargeRules(ctx context.Context, q db.DBTX, orderID string) (map[int64]AreaChargeRule, error) {
+	var areaID sql.NullString
+	err := q.QueryRowContext(ctx, `
+		SELECT t.area_id
+		FROM orders o
+		JOIN tables t ON t.table_number = o.table_number
+		WHERE o.id = ?
+	`, orderID).Scan(&areaID)
+	if err == sql.ErrNoRows || (err == nil && !areaID.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := loadAreaChargeRules(ctx, q, areaID.String)
+	if err != nil {
+		return nil, err
+	}
+	byCharge := make(map[int64]AreaChargeRule, len(rules))
+	for _, rule := range rules {
+		byCharge[rule.ChargeID] = rule
+	}
+	return byCharge, nil
+}
+
+func (r *floorPlanRepository) ListAreas(ctx context.Context) ([]FloorArea, error) {
+	rows, err := r.db.QueryContext(ctx, floorAreaSelect+" ORDER BY a.sort_order, a.name")
+	if err != nil {
+		return nil, err
+	}
+	areas := []FloorArea{}
+	for rows.Next() {
+		area, err := scanFloorArea(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		areas = append(areas, *area)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range areas {
+		if areas[i].ChargeRules, err = loadAreaChargeRules(ctx, r.db, areas[i].ID); err != nil {
+			return nil, err
+		}
+	}
+	return areas, nil
+}
+
+func (r *floorPlanRepository) GetArea(ctx context.Context, id string) (*FloorArea, error) {
+	area, err := scanFloorArea(r.db.QueryRowContext(ctx, floorAreaSelect+" WHERE a.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrFloorAreaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if area.ChargeRules, err = loadAreaChargeRules(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	return area, nil
+}
+
+func (r *floorPlanRepository) CreateArea(ctx context.Context, input FloorAreaInput) (*FloorArea, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: nama area wajib diisi", ErrInvalidFloorPlan)
+	}
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	id := utils.GenerateULID()
+	now := time.Now().UTC()
+	description := strings.TrimSpace(input.Description)
+	if _, err := r.db.ExecContext(ctx, `
+		INSERT INTO floor_areas (id, name, description, sort_order, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, id, name, sql.NullString{String: description, Valid: description != ""}, input.SortOrder, isActive, now, now); err != nil {
+		return nil, floorPlanWriteError(err, name)
+	}
+	return r.GetArea(ctx, id)
+}
+
+func (r *floorPlanRepository) UpdateArea(ctx context.Context, id string, input FloorAreaInput) (*FloorArea, error) {
+	current, err := r.GetArea(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: nama area wajib diisi", ErrInvalidFloorPlan)
+	}
+	isActive := current.IsActive
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	description := strings.TrimSpace(input.Description)
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE floor_areas
+		SET name = ?, description = ?, sort_order = ?, is_active = ?, updated_at = ?
+		WHERE id = ?
+	`, name, sql.NullString{String: description, Valid: description != ""}, input.SortOrder, isActive,
+		time.Now().UTC(), id); err != nil {
+		return nil, floorPlanWriteError(err, name)
+	}
+	return r.GetArea(ctx, id)
+}
+
+// DeleteArea menghapus area; meja dan section di area tersebut menjadi tanpa area
+func (r *floorPlanRepository) DeleteArea(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkFloorRef(ctx, tx, "floor_areas", id, ErrFloorAreaNotFound); err != nil {
+		return err
+	}
+	for _, query := range []string{
+		"UPDATE tables SET area_id = NULL WHERE area_id = ?",
+		"UPDATE floor_sections SET area_id = NULL WHERE area_id = ?",
+		"DELETE FROM floor_area_charge_rules WHERE area_id = ?",
+		"DELETE FROM floor_areas WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// validateChargeRule memeriksa aturan biaya tambahan area/tipe order; aturan bebas biaya
+// tidak menyimpan tarif pengganti
+func validateChargeRule(ctx context.Context, q db.DBTX, rule *AreaChargeRule, seen map[int64]bool, invalid error) error {
+	if seen[rule.ChargeID] {
+		return fmt.Errorf("%w: charge_id %d lebih dari sekali", invalid, rule.ChargeID)
+	}
+	seen[rule.ChargeID] = true
+
+	var exists int
+	if err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM additional_charges WHERE id = ?", rule.ChargeID).Scan(&exists); err != nil {
+		return err
+	}
+	if exists == 0 {
+		return fmt.Errorf("%w: biaya tambahan %d tidak ditemukan", invalid, rule.ChargeID)
+	}
+	if rule.IsExempt {
+		rule.ChargeType = nil
+		rule.Value = nil
+		return nil
+	}
+	if rule.ChargeType == nil || (*rule.ChargeType != "percentage" && *rule.ChargeType != "fixed") {
+		return fmt.Errorf("%w: charge_type harus percentage atau fixed", invalid)
+	}
+	if rule.Value == nil || *rule.Value < 0 {
+		return fmt.Errorf("%w: value tarif pengganti tidak boleh kosong atau negatif", invalid)
+	}
+	return nil
+}
+
+// SetAreaChargeRules mengganti seluruh aturan service charge area
+func (r *floorPlanRepository) SetAreaChargeRules(ctx context.Context, id string, rules []AreaChargeRule) (*FloorArea, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkFloorRef(ctx, tx, "floor_areas", id, ErrFloorAreaNotFound); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM floor_area_charge_rules WHERE area_id = ?", id); err != nil {
+		return nil, err
+	}
+
+	seen := map[int64]bool{}
+	for _, rule := range rules {
+		if err := validateChargeRule(ctx, tx, &rule, seen, ErrInvalidFloorPlan); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO floor_area_charge_rules (area_id, charge_id, is_exempt, charge_type, value)
+			VALUES (?, ?, ?, ?, ?)
+		`, id, rule.ChargeID, rule.IsExempt, rule.ChargeType, rule.Value); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE floor_areas SET updated_at = ? WHERE id = ?", time.Now().UTC(), id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetArea(ctx, id)
+}
+
+const floorSectionSelect = `
+	SELECT s.id, s.name, s.area_id, COALESCE(a.name, ''), COALESCE(s.color, ''), s.created_at, s.updated_at
+	FROM floor_sections s
+	LEFT JOIN floor_areas a ON a.id = s.area_id
+`
+
+func (r *floorPlanRepository) loadSectionDetails(ctx context.Context, section *FloorSection) error {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM tables WHERE section_id = ? ORDER BY table_number", section.ID)
+	if err != nil {
+		return err
+	}
+	section.TableIDs = []string{}
+	for rows.Next() {
+		var tableID string
+		if err := rows.Scan(&tableID); err != nil {
+			rows.Close()
+			return err
+		}
+		section.TableIDs = append(section.TableIDs, tableID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT w.user_id, COALESCE(u.full_name, ''), w.assigned_at
+		FROM floor_section_waiters w
+		LEFT JOIN users u ON u.id = w.user_id
+		WHERE w.section_id = ?
+		ORDER BY u.full_name
+	`, section.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	section.Waiters = []SectionWaiter{}
+	for rows.Next() {
+		var waiter SectionWaiter
+		if err := rows.Scan(&waiter.UserID, &waiter.FullName, &waiter.AssignedAt); err != nil {
+			return err
+		}
+		section.Waiters = append(section.Waiters, waiter)
+	}
+	return rows.Err()
+}
+
+func scanFloorSection(scanner interface{ Scan(dest ...any) error }) (*FloorSection, error) {
+	var section FloorSection
+	var areaID sql.NullString
+	if err := scanner.Scan(&section.ID, &section.Name, &areaID, &section.AreaName, &section.Color,
+		&section.CreatedAt, &section.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if areaID.Valid {
+		section.AreaID = &areaID.String
+	}
+	return &section, nil
+}
+
+func (r *floorPlanRepository) ListSections(ctx context.Context) ([]FloorSection, error) {
+	rows, err := r.db.QueryContext(ctx, floorSectionSelect+" ORDER BY s.name")
+	if err != nil {
+		return nil, err
+	}
+	sections := []FloorSection{}
+	for rows.Next() {
+		section, err := scanFloorSection(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sections = append(sections, *section)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range sections {
+		if err := r.loadSectionDetails(ctx, &sections[i]); err != nil {
+			return nil, err
+		}
+	}
+	return sections, nil
+}
+
+func (r *floorPlanRepository) GetSection(ctx context.Context, id string) (*FloorSection, error) {
+	section, err := scanFloorSection(r.db.QueryRowContext(ctx, floorSectionSelect+" WHERE s.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrFloorSectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.loadSectionDetails(ctx, section); err != nil {
+		return nil, err
+	}
+	return section, nil
+}
+
+// saveSectionTables mengganti meja section; meja pindah dari section lamanya
+func saveSectionTables(ctx context.Context, q db.DBTX, sectionID string, tableIDs []string, now time.Time) error {
+	if _, err := q.ExecContext(ctx, "UPDATE tables SET section_id = NULL, updated_at = ? WHERE section_id = ?", now, sectionID); err != nil {
+		return err
+	}
+	for _, tableID := range tableIDs {
+		result, err := q.ExecContext(ctx, "UPDATE tables SET section_id = ?, updated_at = ? WHERE id = ?", sectionID, now, tableID)
+		if err != nil {
+			return err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return fmt.Errorf("%w: meja %s tidak ditemukan", ErrInvalidFloorPlan, tableID)
+		}
+	}
+	return nil
+}
+
+func (r *floorPlanRepository) saveSection(ctx context.Context, id string, input FloorSectionInput, create bool) (*FloorSection, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: nama section wajib diisi", ErrInvalidFloorPlan)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if input.AreaID != "" {
+		if err := checkFloorRef(ctx, tx, "floor_areas", input.AreaID, ErrFloorAreaNotFound); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now().UTC()
+	color := strings.TrimSpace(input.Color)
+	if create {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO floor_sections (id, name, area_id, color, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, id, name, nullableID(input.AreaID), sql.NullString{String: color, Valid: color != ""}, now, now)
+	} else {
+		if err := checkFloorRef(ctx, tx, "floor_sections", id, ErrFloorSectionNotFound); err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE floor_sections
+			SET name = ?, area_id = ?, color = ?, updated_at = ?
+			WHERE id = ?
+		`, name, nullableID(input.AreaID), sql.NullString{String: color, Valid: color != ""}, now, id)
+	}
+	if err != nil {
+		return nil, floorPlanWriteError(err, name)
+	}
+	if input.TableIDs != nil {
+		if err := saveSectionTables(ctx, tx, id, input.TableIDs, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetSection(ctx, id)
+}
+
+func (r *floorPlanRepository) CreateSection(ctx context.Context, input FloorSectionInput) (*FloorSection, error) {
+	return r.saveSection(ctx, utils.GenerateULID(), input, true)
+}
+
+// UpdateSection mengubah section; TableIDs nil = meja section tidak diubah
+func (r *floorPlanRepository) UpdateSection(ctx context.Context, id string, input FloorSectionInput) (*FloorSection, error) {
+	return r.saveSection(ctx, id, input, false)
+}
+
+func (r *floorPlanRepository) DeleteSection(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkFloorRef(ctx, tx, "floor_sections", id, ErrFloorSectionNotFound); err != nil {
+		return err
+	}
+	for _, query := range []string{
+		"UPDATE tables SET section_id = NULL WHERE section_id = ?",
+		"DELETE FROM floor_section_waiters WHERE section_id = ?",
+		"DELETE FROM floor_sections WHERE id = ?",
+	} {
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// SetSectionWaiters mengganti pelayan section; hanya user aktif berperan waiter
+func (r *floorPlanRepository) SetSectionWaiters(ctx context.Context, id string, userIDs []string) (*FloorSection, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkFloorRef(ctx, tx, "floor_sections", id, ErrFloorSectionNotFound); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM floor_section_waiters WHERE section_id = ?", id); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for _, userID := range userIDs {
+		var role string
+		var isActive bool
+		err := tx.QueryRowContext(ctx, "SELECT role, is_active FROM users WHERE id = ?", userID).Scan(&role, &isActive)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: user %s tidak ditemukan", ErrInvalidFloorPlan, userID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if role != "waiter" || !isActive {
+			return nil, fmt.Errorf("%w: user %s bukan waiter aktif", ErrInvalidFloorPlan, userID)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO floor_section_waiters (section_id, user_id, assigned_at)
+			VALUES (?, ?, ?)
+		`, id, userID, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetSection(ctx, id)
+}
+
+// ListWaiterTables mengembalikan meja di section yang ditugaskan ke pelayan
+func (r *floorPlanRepository) ListWaiterTables(ctx context.Context, userID string) ([]FloorTable, error) {
+	return r.listFloorTables(ctx, `
+		WHERE t.section_id IN (SELECT section_id FROM floor_section_waiters WHERE user_id = ?)
+	`, userID)
+}
+
+// GetTableCombination mengambil gabungan meja beserta nomor meja anggotanya
+func GetTableCombination(ctx context.Context, q db.DBTX, id string) (*TableCombination, error) {
+	var combination TableCombination
+	err := q.QueryRowContext(ctx, `
+		SELECT c.id, c.name, c.primary_table_id, COALESCE(t.table_number, ''), c.is_active, c.created_at, c.updated_at
+		FROM table_combinations c
+		LEFT JOIN tables t ON t.id = c.primary_table_id
+		WHERE c.id = ?
+	`, id).Scan(&combination.ID, &combination.Name, &combination.PrimaryTableID, &combination.PrimaryTableNumber,
+		&combination.IsActive, &combination.CreatedAt, &combination.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrTableCombinationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT t.id, t.table_number, t.capacity
+		FROM table_combination_tables ct
+		JOIN tables t ON t.id = ct.table_id
+		WHERE ct.combination_id = ?
+		ORDER BY t.table_number
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	combination.TableIDs = []string{}
+	combination.TableNumbers = []string{}
+	for rows.Next() {
+		var tableID, tableNumber string
+		var capacity int64
+		if err := rows.Scan(&tableID, &tableNumber, &capacity); err != nil {
+			return nil, err
+		}
+		combination.TableIDs = append(combination.TableIDs, tableID)
+		combination.TableNumbers = append(combination.TableNumbers, tableNumber)
+		combination.Capacity += capacity
+	}
+	return &combination, rows.Err()
+}
+
+func (r *floorPlanRepository) ListCombinations(ctx context.Context) ([]TableCombination, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT id FROM table_combinations ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	combinations := make([]TableCombination, 0, len(ids))
+	for _, id := range ids {
+		combination, err := GetTableCombination(ctx, r.db, id)
+		if err != nil {
+			return nil, err
+		}
+		combinations = append(combinations, *combination)
+	}
+	return combinations, nil
+}
+
+func (r *floorPlanRepository) saveCombination(ctx context.Context, id string, input TableCombinationInput, create bool) (*TableCombination, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: nama gabungan meja wajib diisi", ErrInvalidFloorPlan)
+	}
+	tableIDs := []string{}
+	seen := map[string]bool{}
+	for _, tableID := range input.TableIDs {
+		if tableID != "" && !seen[tableID] {
+			seen[tableID] = true
+			tableIDs = append(tableIDs, tableID)
+		}
+	}
+	if len(tableIDs) < 2 {
+		return nil, fmt.Errorf("%w: gabungan meja minimal 2 meja", ErrInvalidFloorPlan)
+	}
+	if input.PrimaryTableID == "" {
+		input.PrimaryTableID = tableIDs[0]
+	}
+	if !seen[input.PrimaryTableID] {
+		return nil, fmt.Errorf("%w: meja utama harus termasuk dalam gabungan", ErrInvalidFloorPlan)
+	}
+	isActive := true
+	if input.IsActive != nil {
+		isActive = *input.IsActive
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, tableID := range tableIDs {
+		if err := checkFloorRef(ctx, tx, "tables", tableID, fmt.Errorf("%w: meja %s tidak ditemukan", ErrInvalidFloorPlan, tableID)); err != nil {
+			return nil, err
+		}
+	}
+	now := time.Now().UTC()
+	if create {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO table_combinations (id, name, primary_table_id, is_active, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, id, name, input.PrimaryTableID, isActive, now, now)
+	} else {
+		if err := checkFloorRef(ctx, tx, "table_combinations", id, ErrTableCombinationNotFound); err != nil {
+			return nil, err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE table_combinations
+			SET name = ?, primary_table_id = ?, is_active = ?, updated_at = ?
+			WHERE id = ?
+		`, name, input.PrimaryTableID, isActive, now, id)
+	}
+	if err != nil {
+		return nil, floorPlanWriteError(err, name)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM table_combination_tables WHERE combination_id = ?", id); err != nil {
+		return nil, err
+	}
+	for _, tableID := range tableIDs {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO table_combination_tables (combination_id, table_id)
+			VALUES (?, ?)
+		`, id, tableID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return GetTableCombination(ctx, r.db, id)
+}
+
+func (r *floorPlanRepository) CreateCombination(ctx context.Context, input TableCombinationInput) (*TableCombination, error) {
+	return r.saveCombination(ctx, utils.GenerateULID(), input, true)
+}
+
+func (r *floorPlanRepository) UpdateCombination(ctx context.Context, id string, input TableCombinationInput) (*TableCombination, error) {
+	return r.saveCombination(ctx, id, input, false)
+}
+
+func (r *floorPlanRepository) DeleteCombination(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkFloorRef(ctx, tx, "table_combinations", id, ErrTableCombinationNotFound); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM table_combination_tables WHERE combination_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM table_combinations WHERE id = ?", id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// CheckCombinationOrders memastikan order yang akan digabung berada di meja anggota gabungan
+func CheckCombinationOrders(ctx context.Context, q db.DBTX, combination *TableCombination, orderIDs []string) error {
+	if !combination.IsActive {
+		return fmt.Errorf("%w: gabungan meja %s tidak aktif", ErrInvalidFloorPlan, combination.Name)
+	}
+	members := map[string]bool{}
+	for _, number := range combination.TableNumbers {
+		members[number] = true
+	}
+	for _, orderID := range orderIDs {
+		var tableNumber string
+		err := q.QueryRowContext(ctx, "SELECT table_number FROM orders WHERE id = ?", orderID).Scan(&tableNumber)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("%w: order %s tidak ditemukan", ErrInvalidFloorPlan, orderID)
+		}
+		if err != nil {
+			return err
+		}
+		if !members[tableNumber] {
+			return fmt.Errorf("%w: meja %s bukan bagian dari gabungan %s", ErrInvalidFloorPlan, tableNumber, combination.Name)
+		}
+	}
+	return nil
+}
+
+// OccupyTableCombination menandai seluruh meja gabungan terisi setelah order digabung
+func OccupyTableCombination(ctx context.Context, q db.DBTX, combination *TableCombination) error {
+	now := time.Now().UTC()
+	for _, tableID := range combination.TableIDs {
+		if _, err := q.ExecContext(ctx, `
+			UPDATE tables SET status = ?, updated_at = ? WHERE id = ?
+		`, TableStatusOrdered, now, tableID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// GiftCard adalah kartu/dompet saldo (stored value). Saldo yang belum ditukar adalah
+// kewajiban outlet; penjualan kartu bukan pendapatan.
+type GiftCard struct {
+	ID           string          `json:"id"`
+	Code         string          `json:"code"`
+	CustomerID   *string         `json:"customer_id"`
+	CustomerName string          `json:"customer_name,omitempty"`
+	Balance      float64         `json:"balance"`
+	Status       string          `json:"status"`
+	Note         string          `json:"note"`
+	CreatedBy    string          `json:"created_by"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+	Ledger       []GiftCardEntry `json:"ledger,omitempty"`
+}
+
+// GiftCardEntry adalah satu mutasi saldo gift card
+type GiftCardEntry struct {
+	ID            string    `json:"id"`
+	GiftCardID    string    `json:"gift_card_id"`
+	GiftCardCode  string    `json:"gift_card_code,omitempty"`
+	EntryType     string    `json:"entry_type"`
+	Amount        float64   `json:"amount"`
+	BalanceAfter  float64   `json:"balance_after"`
+	PaymentMethod *string   `json:"payment_method"`
+	OrderID       *string   `json:"order_id"`
+	TransactionID *string   `json:"transaction_id"`
+	ShiftID       *string   `json:"shift_id"`
+	ReversalOf    *string   `json:"reversal_of"`
+	Note          string    `json:"note"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedByName string    `json:"created_by_name,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// GiftCardLoadInput adalah penjualan kartu baru (issue) atau top up saldo
+type GiftCardLoadInput struct {
+	GiftCardID    string
+	Code          string
+	CustomerID    string
+	Amount        float64
+	PaymentMethod string
+	Note          string
+	ShiftID       string
+	CreatedBy     string
+}
+
+type GiftCardFilter struct {
+	CustomerID string
+	Status     string
+	Search     string
+}
+
+type GiftCardLedgerFilter struct {
+	GiftCardID string
+	CustomerID string
+}
+
+// GiftCardShiftSummary adalah penjualan/top up gift card pada sebuah shift
+type GiftCardShiftSummary struct {
+	Sold       float64 `json:"sold"`
+	SoldDrawer float64 `json:"sold_drawer"`
+}
+
+// GiftCardLiability adalah saldo gift card yang belum ditukar saat ini
+type GiftCardLiability struct {
+	ActiveCards        int64   `json:"active_cards"`
+	ActiveBalance      float64 `json:"active_balance"`
+	DisabledCards      int64   `json:"disabled_cards"`
+	DisabledBalance    float64 `json:"disabled_balance"`
+	OutstandingBalance float64 `json:"outstanding_balance"`
+	TotalSold          float64 `json:"total_sold"`
+	TotalRedeemed      float64 `json:"total_redeemed"`
+}
+
+const (
+	GiftCardStatusActive   = "active"
+	GiftCardStatusDisabled = "disabled"
+
+	GiftCardEntryIssue    = "issue"
+	GiftCardEntryTopUp    = "topup"
+	GiftCardEntryRedeem   = "redeem"
+	GiftCardEntryReversal = "reversal"
+
+	// GiftCardPaymentMethod adalah kode metode pembayaran untuk penukaran saldo gift card
+	GiftCardPaymentMethod = "gift_card"
+)
+
+var (
+	ErrGiftCardNotFound     = errors.New("gift card tidak ditemukan")
+	ErrInvalidGiftCard      = errors.New("data gift card tidak valid")
+	ErrGiftCardDisabled     = errors.New("gift card tidak aktif")
+	ErrGiftCardInsufficient = errors.New("saldo gift card tidak cukup")
+	ErrGiftCardCodeExists   = errors.New("kode gift card sudah dipakai")
+)
+
+type GiftCardRepository interface {
+	Issue(ctx context.Context, input GiftCardLoadInput) (*GiftCard, error)
+	TopUp(ctx context.Context, input GiftCardLoadInput) (*GiftCard, error)
+	GetByID(ctx context.Context, id string) (*GiftCard, error)
+	GetByCode(ctx context.Context, code string) (*GiftCard, error)
+	List(ctx context.Context, filter GiftCardFilter) ([]GiftCard, error)
+	ListLedger(ctx context.Context, filter GiftCardLedgerFilter) ([]GiftCardEntry, error)
+	SetStatus(ctx context.Context, id, status string) error
+	GetLiability(ctx context.Context) (*GiftCardLiability, error)
+}
@@ -0,0 +1,561 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type giftCardRepository struct {
+	db *sql.DB
+}
+
+func NewGiftCardRepository(dbConn *sql.DB) GiftCardRepository {
+	return &giftCardRepository{db: dbConn}
+}
+
+// Huruf/angka yang mudah dibaca (tanpa 0/O dan 1/I) untuk kode gift card
+const giftCardCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// NormalizeGiftCardCode menyamakan format kode input kasir (huruf besar, tanpa spasi/strip)
+func NormalizeGiftCardCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func generateGiftCardCode() (string, error) {
+	var sb strings.Builder
+	sb.WriteString("GC")
+	max := big.NewInt(int64(len(giftCardCodeAlphabet)))
+	for i := 0; i < 12; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(giftCardCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+// insertGiftCardEntry mencatat mutasi saldo gift card
+func insertGiftCardEntry(ctx context.Context, q db.DBTX, entry *GiftCardEntry) error {
+	entry.ID = utils.GenerateULID()
+	entry.CreatedAt = time.Now().UTC()
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO gift_card_ledger (
+			id, gift_card_id, entry_type, amount, balance_after, payment_method, order_id,
+			transaction_id, shift_id, reversal_of, note, created_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, entry.ID, entry.GiftCardID, entry.EntryType, entry.Amount, entry.BalanceAfter, entry.PaymentMethod, entry.OrderID,
+		entry.TransactionID, entry.ShiftID, entry.ReversalOf, sql.NullString{String: entry.Note, Valid: entry.Note != ""},
+		entry.CreatedBy, entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal mencatat mutasi gift card: %w", err)
+	}
+	return nil
+}
+
+func validateGiftCardLoad(input *GiftCardLoadInput) error {
+	input.Amount = math.Round(input.Amount)
+	if input.Amount <= 0 {
+		return fmt.Errorf("%w: nominal harus lebih dari 0", ErrInvalidGiftCard)
+	}
+	if input.PaymentMethod == "" || input.PaymentMethod == GiftCardPaymentMethod || input.PaymentMethod == DepositPaymentMethod {
+		return fmt.Errorf("%w: metode pembayaran tidak valid", ErrInvalidGiftCard)
+	}
+	return nil
+}
+
+// Issue menjual gift card baru dengan saldo awal. Kode dibuat otomatis jika kosong.
+func (r *giftCardRepository) Issue(ctx context.Context, input GiftCardLoadInput) (*GiftCard, error) {
+	if err := validateGiftCardLoad(&input); err != nil {
+		return nil, err
+	}
+	code := NormalizeGiftCardCode(input.Code)
+	if code == "" {
+		generated, err := generateGiftCardCode()
+		if err != nil {
+			return nil, err
+		}
+		code = generated
+	}
+	if len(code) < 6 {
+		return nil, fmt.Errorf("%w: kode minimal 6 karakter", ErrInvalidGiftCard)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM gift_cards WHERE code = ?", code).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if exists > 0 {
+		return nil, ErrGiftCardCodeExists
+	}
+	customerID := nullableID(input.CustomerID)
+	if customerID != nil {
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM customers WHERE id = ?", *customerID).Scan(&exists); err != nil {
+			return nil, err
+		}
+		if exists == 0 {
+			return nil, fmt.Errorf("%w: pelanggan tidak ditemukan", ErrInvalidGiftCard)
+		}
+	}
+
+	now := time.Now().UTC()
+	card := &GiftCard{
+		ID:         utils.GenerateULID(),
+		Code:       code,
+		CustomerID: customerID,
+		Balance:    input.Amount,
+		Status:     GiftCardStatusActive,
+		Note:       strings.TrimSpace(input.Note),
+		CreatedBy:  input.CreatedBy,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO gift_cards (id, code, customer_id, balance, status, note, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, card.ID, card.Code, card.CustomerID, card.Balance, card.Status,
+		sql.NullString{String: card.Note, Valid: card.Note != ""}, card.CreatedBy, now, now); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan gift card: %w", err)
+	}
+
+	entry := GiftCardEntry{
+		GiftCardID:    card.ID,
+		EntryType:     GiftCardEntryIssue,
+		Amount:        input.Amount,
+		BalanceAfter:  card.Balance,
+		PaymentMethod: &input.PaymentMethod,
+		ShiftID:       nullableID(input.ShiftID),
+		CreatedBy:     input.CreatedBy,
+	}
+	if err := insertGiftCardEntry(ctx, tx, &entry); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	card.Ledger = []GiftCardEntry{entry}
+	return card, nil
+}
+
+// TopUp menambah saldo gift card/dompet yang masih aktif
+func (r *giftCardRepository) TopUp(ctx context.Context, input GiftCardLoadInput) (*GiftCard, error) {
+	if err := validateGiftCardLoad(&input); err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var status string
+	var balance float64
+	err = tx.QueryRowContext(ctx, "SELECT status, balance FROM gift_cards WHERE id = ?", input.GiftCardID).Scan(&status, &balance)
+	if err == sql.ErrNoRows {
+		return nil, ErrGiftCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != GiftCardStatusActive {
+		return nil, ErrGiftCardDisabled
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE gift_cards
+		SET balance = balance + ?, updated_at = ?
+		WHERE id = ?
+	`, input.Amount, time.Now().UTC(), input.GiftCardID); err != nil {
+		return nil, err
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT balance FROM gift_cards WHERE id = ?", input.GiftCardID).Scan(&balance); err != nil {
+		return nil, err
+	}
+	if err := insertGiftCardEntry(ctx, tx, &GiftCardEntry{
+		GiftCardID:    input.GiftCardID,
+		EntryType:     GiftCardEntryTopUp,
+		Amount:        input.Amount,
+		BalanceAfter:  balance,
+		PaymentMethod: &input.PaymentMethod,
+		ShiftID:       nullableID(input.ShiftID),
+		Note:          strings.TrimSpace(input.Note),
+		CreatedBy:     input.CreatedBy,
+	}); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, input.GiftCardID)
+}
+
+const giftCardSelect = `
+	SELECT g.id, g.code, g.customer_id, COALESCE(c.name, ''), g.balance, g.status, COALESCE(g.note, ''),
+	       g.created_by, g.created_at, g.updated_at
+	FROM gift_cards g
+	LEFT JOIN customers c ON c.id = g.customer_id
+`
+
+func scanGiftCard(scanner interface{ Scan(dest ...any) error }) (*GiftCard, error) {
+	var card GiftCard
+	var customerID sql.NullString
+	if err := scanner.Scan(&card.ID, &card.Code, &customerID, &card.CustomerName, &card.Balance, &card.Status, &card.Note,
+		&card.CreatedBy, &card.CreatedAt, &card.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if customerID.Valid {
+		card.CustomerID = &customerID.String
+	}
+	return &card, nil
+}
+
+func (r *giftCardRepository) GetByID(ctx context.Context, id string) (*GiftCard, error) {
+	card, err := scanGiftCard(r.db.QueryRowContext(ctx, giftCardSelect+" WHERE g.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrGiftCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	card.Ledger, err = r.ListLedger(ctx, GiftCardLedgerFilter{GiftCardID: id})
+	if err != nil {
+		return nil, err
+	}
+	return card, nil
+}
+
+// GetByCode dipakai kasir untuk cek saldo sebelum menukar gift card
+func (r *giftCardRepository) GetByCode(ctx context.Context, code string) (*GiftCard, error) {
+	card, err := scanGiftCard(r.db.QueryRowContext(ctx, giftCardSelect+" WHERE g.code = ?", NormalizeGiftCardCode(code)))
+	if err == sql.ErrNoRows {
+		return nil, ErrGiftCardNotFound
+	}
+	return card, err
+}
+
+func (r *giftCardRepository) List(ctx context.Context, filter GiftCardFilter) ([]GiftCard, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if filter.CustomerID != "" {
+		conditions = append(conditions, "g.customer_id = ?")
+		args = append(args, filter.CustomerID)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "g.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "(g.code LIKE ? OR c.name LIKE ? OR c.phone LIKE ?)")
+		search := "%" + strings.TrimSpace(filter.Search) + "%"
+		args = append(args, "%"+NormalizeGiftCardCode(filter.Search)+"%", search, search)
+	}
+
+	rows, err := r.db.QueryContext(ctx, giftCardSelect+`
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY g.created_at DESC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []GiftCard{}
+	for rows.Next() {
+		card, err := scanGiftCard(rows)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, *card)
+	}
+	return cards, rows.Err()
+}
+
+// ListLedger mengembalikan riwayat saldo per kartu atau semua kartu milik pelanggan
+func (r *giftCardRepository) ListLedger(ctx context.Context, filter GiftCardLedgerFilter) ([]GiftCardEntry, error) {
+	condition := "l.gift_card_id = ?"
+	arg := filter.GiftCardID
+	if filter.GiftCardID == "" {
+		condition = "g.customer_id = ?"
+		arg = filter.CustomerID
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.gift_card_id, g.code, l.entry_type, l.amount, l.balance_after, l.payment_method, l.order_id,
+		       l.transaction_id, l.shift_id, l.reversal_of, COALESCE(l.note, ''), l.created_by, COALESCE(u.full_name, ''),
+		       l.created_at
+		FROM gift_card_ledger l
+		JOIN gift_cards g ON g.id = l.gift_card_id
+		LEFT JOIN users u ON u.id = l.created_by
+		WHERE `+condition+`
+		ORDER BY l.created_at DESC, l.id DESC
+	`, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []GiftCardEntry{}
+	for rows.Next() {
+		var entry GiftCardEntry
+		var paymentMethod, orderID, transactionID, shiftID, reversalOf sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.GiftCardID, &entry.GiftCardCode, &entry.EntryType, &entry.Amount,
+			&entry.BalanceAfter, &paymentMethod, &orderID, &transactionID, &shiftID, &reversalOf, &entry.Note,
+			&entry.CreatedBy, &entry.CreatedByName, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		if paymentMethod.Valid {
+			entry.PaymentMethod = &paymentMethod.String
+		}
+		if orderID.Valid {
+			entry.OrderID = &orderID.String
+		}
+		if transactionID.Valid {
+			entry.TransactionID = &transactionID.String
+		}
+		if shiftID.Valid {
+			entry.ShiftID = &shiftID.String
+		}
+		if reversalOf.Valid {
+			entry.ReversalOf = &reversalOf.String
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// SetStatus menonaktifkan kartu hilang/diblokir atau mengaktifkannya kembali
+func (r *giftCardRepository) SetStatus(ctx context.Context, id, status string) error {
+	if status != GiftCardStatusActive && status != GiftCardStatusDisabled {
+		return fmt.Errorf("%w: status harus active atau disabled", ErrInvalidGiftCard)
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE gift_cards
+		SET status = ?, updated_at = ?
+		WHERE id = ?
+	`, status, time.Now().UTC(), id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrGiftCardNotFound
+	}
+	return nil
+}
+
+func (r *giftCardRepository) GetLiability(ctx context.Context) (*GiftCardLiability, error) {
+	var liability GiftCardLiability
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN status = 'active' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'active' THEN balance ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'disabled' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'disabled' THEN balance ELSE 0 END), 0)
+		FROM gift_cards
+	`).Scan(&liability.ActiveCards, &liability.ActiveBalance, &liability.DisabledCards, &liability.DisabledBalance); err != nil {
+		return nil, err
+	}
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(CASE WHEN entry_type IN ('issue', 'topup') THEN amount ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN entry_type = 'redeem' THEN amount WHEN entry_type = 'reversal' THEN -amount ELSE 0 END), 0)
+		FROM gift_card_ledger
+	`).Scan(&liability.TotalSold, &liability.TotalRedeemed); err != nil {
+		return nil, err
+	}
+	// Kartu nonaktif tetap kewajiban sampai saldonya dikembalikan/diaktifkan lagi
+	liability.OutstandingBalance = liability.ActiveBalance + liability.DisabledBalance
+	return &liability, nil
+}
+
+// redeemGiftCardTx memotong saldo gift card untuk pembayaran order di dalam transaksi
+// pelunasan (SettleOrder/SplitBillPayment) agar saldo tidak terpotong jika pelunasan gagal.
+// Cek saldo dan pemotongan dilakukan dalam satu UPDATE bersyarat sehingga dua kasir
+// tidak bisa memakai saldo yang sama.
+func redeemGiftCardTx(ctx context.Context, tx db.DBTX, code string, amount float64, orderID, createdBy, shiftID string) (*GiftCardEntry, error) {
+	amount = math.Round(amount)
+	if amount <= 0 {
+		return nil, fmt.Errorf("%w: nominal penukaran harus lebih dari 0", ErrInvalidGiftCard)
+	}
+
+	var cardID, status string
+	var balance float64
+	err := tx.QueryRowContext(ctx, "SELECT id, status, balance FROM gift_cards WHERE code = ?", NormalizeGiftCardCode(code)).
+		Scan(&cardID, &status, &balance)
+	if err == sql.ErrNoRows {
+		return nil, ErrGiftCardNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status != GiftCardStatusActive {
+		return nil, ErrGiftCardDisabled
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE gift_cards
+		SET balance = balance - ?, updated_at = ?
+		WHERE id = ? AND status = 'active' AND balance >= ?
+	`, amount, time.Now().UTC(), cardID, amount)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		return nil, fmt.Errorf("%w (sisa saldo %.0f)", ErrGiftCardInsufficient, balance)
+	}
+	if err := tx.QueryRowContext(ctx, "SELECT balance FROM gift_cards WHERE id = ?", cardID).Scan(&balance); err != nil {
+		return nil, err
+	}
+
+	method := GiftCardPaymentMethod
+	entry := &GiftCardEntry{
+		GiftCardID:    cardID,
+		EntryType:     GiftCardEntryRedeem,
+		Amount:        amount,
+		BalanceAfter:  balance,
+		PaymentMethod: &method,
+		OrderID:       nullableID(orderID),
+		ShiftID:       nullableID(shiftID),
+		CreatedBy:     createdBy,
+	}
+	if err := insertGiftCardEntry(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// LinkGiftCardRedemption menautkan mutasi redeem ke transaksi penjualan
+func LinkGiftCardRedemption(ctx context.Context, q db.DBTX, entryID, transactionID string) error {
+	_, err := q.ExecContext(ctx, "UPDATE gift_card_ledger SET transaction_id = ? WHERE id = ?", transactionID, entryID)
+	return err
+}
+
+// ReverseGiftCardRedemption mengembalikan saldo redeem yang gagal dicatat atau
+// transaksinya dibatalkan. Redeem yang sudah di-reverse dilewati.
+func ReverseGiftCardRedemption(ctx context.Context, dbConn *sql.DB, entryID, createdBy, note string) error {
+	tx, err := dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var cardID string
+	var amount float64
+	var orderID, transactionID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT gift_card_id, amount, order_id, transaction_id
+		FROM gift_card_ledger
+		WHERE id = ? AND entry_type = 'redeem'
+	`, entryID).Scan(&cardID, &amount, &orderID, &transactionID)
+	if err == sql.ErrNoRows {
+		return ErrGiftCardNotFound
+	}
+	if err != nil {
+		return err
+	}
+	var reversed int
+	if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM gift_card_ledger WHERE reversal_of = ?", entryID).Scan(&reversed); err != nil {
+		return err
+	}
+	if reversed > 0 {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE gift_cards
+		SET balance = balance + ?, updated_at = ?
+		WHERE id = ?
+	`, amount, time.Now().UTC(), cardID); err != nil {
+		return err
+	}
+	var balance float64
+	if err := tx.QueryRowContext(ctx, "SELECT balance FROM gift_cards WHERE id = ?", cardID).Scan(&balance); err != nil {
+		return err
+	}
+	entry := &GiftCardEntry{
+		GiftCardID:   cardID,
+		EntryType:    GiftCardEntryReversal,
+		Amount:       amount,
+		BalanceAfter: balance,
+		ReversalOf:   &entryID,
+		Note:         strings.TrimSpace(note),
+		CreatedBy:    createdBy,
+	}
+	if orderID.Valid {
+		entry.OrderID = &orderID.String
+	}
+	if transactionID.Valid {
+		entry.TransactionID = &transactionID.String
+	}
+	if err := insertGiftCardEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReverseGiftCardTransaction mengembalikan saldo semua redeem milik transaksi yang dibatalkan
+func ReverseGiftCardTransaction(ctx context.Context, dbConn *sql.DB, transactionID, createdBy string) error {
+	rows, err := dbConn.QueryContext(ctx, `
+		SELECT id FROM gift_card_ledger WHERE transaction_id = ? AND entry_type = 'redeem'
+	`, transactionID)
+	if err != nil {
+		return err
+	}
+	entryIDs := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		entryIDs = append(entryIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range entryIDs {
+		if err := ReverseGiftCardRedemption(ctx, dbConn, id, createdBy, "Transaksi dibatalkan"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetShiftGiftCardSummary merekap penjualan/top up gift card pada sebuah shift.
+// Penukaran tercatat sebagai penjualan metode gift_card, bukan di sini.
+func GetShiftGiftCardSummary(ctx context.Context, q db.DBTX, shiftID string) (GiftCardShiftSummary, error) {
+	var summary GiftCardShiftSummary
+	err := q.QueryRowContext(ctx, `
+		SELECT
+			COALESCE(SUM(l.amount), 0),
+			COALESCE(SUM(CASE WHEN COALESCE(pm.counts_to_drawer, l.payment_method = 'cash') = 1 THEN l.amount ELSE 0 END), 0)
+		FROM gift_card_ledger l
+		LEFT JOIN payment_methods pm ON pm.code = l.payment_method
+		WHERE l.shift_id = ? AND l.entry_type IN ('issue', 'topup')
+	`, shiftID).Scan(&summary.Sold, &summary.SoldDrawer)
+	return summary, err
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// GuestTable adalah meja yang dituju QR pesan mandiri. GuestNonce ikut ditandatangani
+// di token QR; mengganti nonce membatalkan semua QR lama meja tersebut.
+type GuestTable struct {
+	ID          string `json:"table_id"`
+	TableNumber string `json:"table_number"`
+	Capacity    int64  `json:"capacity"`
+	Status      string `json:"status"`
+	GuestNonce  string `json:"-"`
+}
+
+// GuestMenuProduct adalah produk pada menu tamu dengan harga price list yang berlaku
+type GuestMenuProduct struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Code        string  `json:"code"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+	CategoryID  string  `json:"category_id"`
+}
+
+type GuestMenuCategory struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Products    []GuestMenuProduct `json:"products"`
+}
+
+// GuestOrderItemInput adalah item yang dipilih tamu; Course opsional seperti order biasa
+type GuestOrderItemInput struct {
+	ProductID string `json:"product_id"`
+	Qty       int64  `json:"qty"`
+	Course    string `json:"course,omitempty"`
+}
+
+type GuestOrderInput struct {
+	TableNumber  string
+	CustomerName string
+	Pax          int64
+	Note         string
+	ClientIP     string
+	Items        []GuestOrderItemInput
+}
+
+type GuestOrderItem struct {
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Qty         int64   `json:"qty"`
+	Price       float64 `json:"price"`
+	Course      string  `json:"course,omitempty"`
+}
+
+// GuestOrderRequest adalah pesanan tamu dari QR meja. Pesanan pending menunggu
+// persetujuan waiter; setelah approved item masuk ke order meja dan dicetak ke dapur.
+type GuestOrderRequest struct {
+	ID             string           `json:"id"`
+	TableNumber    string           `json:"table_number"`
+	OrderID        *string          `json:"order_id"`
+	CustomerName   string           `json:"customer_name"`
+	Pax            int64            `json:"pax"`
+	Note           string           `json:"note"`
+	Status         string           `json:"status"`
+	RejectReason   string           `json:"reject_reason"`
+	Total          float64          `json:"total"`
+	Items          []GuestOrderItem `json:"items"`
+	ReviewedBy     string           `json:"reviewed_by"`
+	ReviewedByName string           `json:"reviewed_by_name,omitempty"`
+	ReviewedAt     *time.Time       `json:"reviewed_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+}
+
+type GuestOrderFilter struct {
+	Status      string
+	TableNumber string
+}
+
+// GuestOrderSettings mengatur pesan mandiri tamu lewat QR meja
+type GuestOrderSettings struct {
+	// Secret menandatangani token QR per meja
+	Secret string
+	// RequireApproval = pesanan tamu menunggu persetujuan waiter sebelum dicetak
+	RequireApproval bool
+	// MaxPendingPerTable membatasi pesanan pending per meja agar QR tidak disalahgunakan
+	MaxPendingPerTable int
+}
+
+const (
+	GuestOrderStatusPending  = "pending"
+	GuestOrderStatusApproved = "approved"
+	GuestOrderStatusRejected = "rejected"
+
+	// Batas per pesanan tamu
+	GuestOrderMaxItems = 30
+	GuestOrderMaxQty   = 50
+)
+
+var (
+	ErrInvalidGuestToken    = errors.New("QR meja tidak valid atau sudah diganti")
+	ErrInvalidGuestOrder    = errors.New("pesanan tamu tidak valid")
+	ErrGuestOrderNotFound   = errors.New("pesanan tamu tidak ditemukan")
+	ErrGuestOrderNotPending = errors.New("pesanan tamu sudah diproses")
+	ErrGuestOrderLimit      = errors.New("terlalu banyak pesanan menunggu konfirmasi di meja ini")
+	ErrGuestTableNotFound   = errors.New("meja tidak ditemukan")
+)
+
+type GuestOrderRepository interface {
+	GetTable(ctx context.Context, tableID string) (*GuestTable, error)
+	// RotateTableNonce membuat nonce baru untuk meja; rotate=false hanya mengisi nonce yang kosong
+	RotateTableNonce(ctx context.Context, tableID string, rotate bool) (*GuestTable, error)
+	GetMenu(ctx context.Context, at time.Time) ([]GuestMenuCategory, error)
+	// Create menolak pesanan (ErrGuestOrderLimit) jika meja sudah punya maxPending pesanan
+	// pending; batas diperiksa di transaksi yang sama dengan penyimpanan pesanan
+	Create(ctx context.Context, input GuestOrderInput, maxPending int) (*GuestOrderRequest, error)
+	GetByID(ctx context.Context, id string) (*GuestOrderRequest, error)
+	List(ctx context.Context, filter GuestOrderFilter) ([]GuestOrderRequest, error)
+	// Claim mengunci pesanan pending agar tidak diteruskan dua kali
+	Claim(ctx context.Context, id, reviewedBy string) (*GuestOrderRequest, error)
+	// Release mengembalikan pesanan ke pending jika gagal diteruskan ke order
+	Release(ctx context.Context, id string) error
+	AttachOrder(ctx context.Context, id, orderID string) (*GuestOrderRequest, error)
+	Reject(ctx context.Context, id, reviewedBy, reason string) (*GuestOrderRequest, error)
+}
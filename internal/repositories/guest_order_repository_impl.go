@@ -0,0 +1,359 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type guestOrderRepository struct {
+	db *sql.DB
+}
+
+func NewGuestOrderRepository(dbConn *sql.DB) GuestOrderRepository {
+	return &guestOrderRepository{db: dbConn}
+}
+
+const guestOrderSelect = `
+	SELECT g.id, g.table_number, g.order_id, COALESCE(g.customer_name, ''), g.pax, COALESCE(g.note, ''),
+	       g.status, COALESCE(g.reject_reason, ''), COALESCE(g.reviewed_by, ''), COALESCE(u.full_name, ''),
+	       g.reviewed_at, g.created_at, g.updated_at
+	FROM guest_order_requests g
+	LEFT JOIN users u ON u.id = g.reviewed_by
+`
+
+func scanGuestOrder(scanner interface{ Scan(dest ...any) error }) (*GuestOrderRequest, error) {
+	var request GuestOrderRequest
+	var orderID sql.NullString
+	var reviewedAt sql.NullTime
+	if err := scanner.Scan(&request.ID, &request.TableNumber, &orderID, &request.CustomerName, &request.Pax,
+		&request.Note, &request.Status, &request.RejectReason, &request.ReviewedBy, &request.ReviewedByName,
+		&reviewedAt, &request.CreatedAt, &request.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if orderID.Valid {
+		request.OrderID = &orderID.String
+	}
+	if reviewedAt.Valid {
+		request.ReviewedAt = &reviewedAt.Time
+	}
+	return &request, nil
+}
+
+// loadGuestOrderItems mengisi item dan total pesanan tamu
+func loadGuestOrderItems(ctx context.Context, q db.DBTX, request *GuestOrderRequest) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT product_id, product_name, qty, price, COALESCE(course, '')
+		FROM guest_order_request_items
+		WHERE request_id = ?
+		ORDER BY line_no
+	`, request.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	request.Items = []GuestOrderItem{}
+	request.Total = 0
+	for rows.Next() {
+		var item GuestOrderItem
+		if err := rows.Scan(&item.ProductID, &item.ProductName, &item.Qty, &item.Price, &item.Course); err != nil {
+			return err
+		}
+		request.Items = append(request.Items, item)
+		request.Total += item.Price * float64(item.Qty)
+	}
+	return rows.Err()
+}
+
+func loadGuestOrder(ctx context.Context, q db.DBTX, id string) (*GuestOrderRequest, error) {
+	request, err := scanGuestOrder(q.QueryRowContext(ctx, guestOrderSelect+" WHERE g.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrGuestOrderNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := loadGuestOrderItems(ctx, q, request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func newGuestNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func (r *guestOrderRepository) GetTable(ctx context.Context, tableID string) (*GuestTable, error) {
+	var table GuestTable
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, table_number, capacity, status, COALESCE(guest_nonce, '')
+		FROM tables
+		WHERE id = ?
+	`, tableID).Scan(&table.ID, &table.TableNumber, &table.Capacity, &table.Status, &table.GuestNonce)
+	if err == sql.ErrNoRows {
+		return nil, ErrGuestTableNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &table, nil
+}
+
+func (r *guestOrderRepository) RotateTableNonce(ctx context.Context, tableID string, rotate bool) (*GuestTable, error) {
+	table, err := r.GetTable(ctx, tableID)
+	if err != nil {
+		return nil, err
+	}
+	if table.GuestNonce != "" && !rotate {
+		return table, nil
+	}
+
+	nonce, err := newGuestNonce()
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat token meja: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, "UPDATE tables SET guest_nonce = ? WHERE id = ?", nonce, tableID); err != nil {
+		return nil, err
+	}
+	table.GuestNonce = nonce
+	return table, nil
+}
+
+func (r *guestOrderRepository) GetMenu(ctx context.Context, at time.Time) ([]GuestMenuCategory, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.name, COALESCE(p.code, ''), COALESCE(p.description, ''), p.price,
+		       COALESCE(c.id, ''), COALESCE(c.name, ''), COALESCE(c.description, '')
+		FROM products p
+		LEFT JOIN categories c ON c.id = p.category_id
+		ORDER BY c.name IS NULL, c.name, p.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := []GuestMenuCategory{}
+	index := map[string]int{}
+	for rows.Next() {
+		var product GuestMenuProduct
+		var categoryName, categoryDescription string
+		if err := rows.Scan(&product.ID, &product.Name, &product.Code, &product.Description, &product.Price,
+			&product.CategoryID, &categoryName, &categoryDescription); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		pos, ok := index[product.CategoryID]
+		if !ok {
+			if product.CategoryID == "" {
+				categoryName = "Lainnya"
+			}
+			categories = append(categories, GuestMenuCategory{
+				ID:          product.CategoryID,
+				Name:        categoryName,
+				Description: categoryDescription,
+				Products:    []GuestMenuProduct{},
+			})
+			pos = len(categories) - 1
+			index[product.CategoryID] = pos
+		}
+		categories[pos].Products = append(categories[pos].Products, product)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// Harga mengikuti price list yang berlaku, sama seperti saat order dibuat
+	for i := range categories {
+		for j := range categories[i].Products {
+			product := &categories[i].Products[j]
+			scheduled, err := resolveScheduledPrice(ctx, r.db, product.ID, product.Price, at)
+			if err != nil {
+				return nil, fmt.Errorf("gagal menentukan harga %s: %w", product.Name, err)
+			}
+			product.Price = scheduled.Price
+		}
+	}
+	return categories, nil
+}
+
+func (r *guestOrderRepository) Create(ctx context.Context, input GuestOrderInput, maxPending int) (*GuestOrderRequest, error) {
+	if len(input.Items) == 0 {
+		return nil, fmt.Errorf("%w: items tidak boleh kosong", ErrInvalidGuestOrder)
+	}
+	if len(input.Items) > GuestOrderMaxItems {
+		return nil, fmt.Errorf("%w: maksimal %d item per pesanan", ErrInvalidGuestOrder, GuestOrderMaxItems)
+	}
+	if input.Pax < 0 {
+		return nil, fmt.Errorf("%w: pax tidak boleh negatif", ErrInvalidGuestOrder)
+	}
+	if input.Pax == 0 {
+		input.Pax = 1
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var pending int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM guest_order_requests WHERE table_number = ? AND status = ?
+	`, input.TableNumber, GuestOrderStatusPending).Scan(&pending); err != nil {
+		return nil, err
+	}
+	if pending >= maxPending {
+		return nil, ErrGuestOrderLimit
+	}
+
+	q := db.New(tx)
+	now := time.Now().UTC()
+	id := utils.GenerateULID()
+	customerName := strings.TrimSpace(input.CustomerName)
+	note := strings.TrimSpace(input.Note)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO guest_order_requests (
+			id, table_number, customer_name, pax, note, status, client_ip, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, input.TableNumber, nullableID(customerName), input.Pax, nullableID(note), GuestOrderStatusPending,
+		nullableID(input.ClientIP), now, now); err != nil {
+		return nil, err
+	}
+
+	for i, item := range input.Items {
+		if item.ProductID == "" {
+			return nil, fmt.Errorf("%w: product_id wajib diisi untuk semua item", ErrInvalidGuestOrder)
+		}
+		if item.Qty <= 0 || item.Qty > GuestOrderMaxQty {
+			return nil, fmt.Errorf("%w: qty harus 1 sampai %d", ErrInvalidGuestOrder, GuestOrderMaxQty)
+		}
+		course, err := normalizeCourse(item.Course)
+		if err != nil {
+			return nil, err
+		}
+		product, err := q.GetProduct(ctx, item.ProductID)
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("%w: produk %s tidak ditemukan", ErrInvalidGuestOrder, item.ProductID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		scheduled, err := resolveScheduledPrice(ctx, tx, product.ID, product.Price, now)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menentukan harga %s: %w", product.Name, err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO guest_order_request_items (request_id, line_no, product_id, product_name, qty, price, course)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, id, i+1, product.ID, product.Name, item.Qty, scheduled.Price, nullableID(course)); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return loadGuestOrder(ctx, r.db, id)
+}
+
+func (r *guestOrderRepository) GetByID(ctx context.Context, id string) (*GuestOrderRequest, error) {
+	return loadGuestOrder(ctx, r.db, id)
+}
+
+func (r *guestOrderRepository) List(ctx context.Context, filter GuestOrderFilter) ([]GuestOrderRequest, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if filter.Status != "" {
+		conditions = append(conditions, "g.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.TableNumber != "" {
+		conditions = append(conditions, "g.table_number = ?")
+		args = append(args, filter.TableNumber)
+	}
+
+	rows, err := r.db.QueryContext(ctx, guestOrderSelect+" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY g.created_at DESC LIMIT 200", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []GuestOrderRequest{}
+	for rows.Next() {
+		request, err := scanGuestOrder(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	for i := range requests {
+		if err := loadGuestOrderItems(ctx, r.db, &requests[i]); err != nil {
+			return nil, err
+		}
+	}
+	return requests, nil
+}
+
+// reviewGuestOrder mengubah status pesanan yang masih pending
+func (r *guestOrderRepository) reviewGuestOrder(ctx context.Context, id, status, reviewedBy, reason string) (*GuestOrderRequest, error) {
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE guest_order_requests
+		SET status = ?, reviewed_by = ?, reviewed_at = ?, reject_reason = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, status, nullableID(reviewedBy), now, nullableID(reason), now, id, GuestOrderStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		if _, err := loadGuestOrder(ctx, r.db, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrGuestOrderNotPending
+	}
+	return loadGuestOrder(ctx, r.db, id)
+}
+
+func (r *guestOrderRepository) Claim(ctx context.Context, id, reviewedBy string) (*GuestOrderRequest, error) {
+	return r.reviewGuestOrder(ctx, id, GuestOrderStatusApproved, reviewedBy, "")
+}
+
+func (r *guestOrderRepository) Release(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE guest_order_requests
+		SET status = ?, reviewed_by = NULL, reviewed_at = NULL, updated_at = ?
+		WHERE id = ? AND order_id IS NULL
+	`, GuestOrderStatusPending, time.Now().UTC(), id)
+	return err
+}
+
+func (r *guestOrderRepository) AttachOrder(ctx context.Context, id, orderID string) (*GuestOrderRequest, error) {
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE guest_order_requests SET order_id = ?, updated_at = ? WHERE id = ?
+	`, orderID, time.Now().UTC(), id); err != nil {
+		return nil, err
+	}
+	return loadGuestOrder(ctx, r.db, id)
+}
+
+func (r *guestOrderRepository) Reject(ctx context.Context, id, reviewedBy, reason string) (*GuestOrderRequest, error) {
+	return r.reviewGuestOrder(ctx, id, GuestOrderStatusRejected, reviewedBy, strings.TrimSpace(reason))
+}
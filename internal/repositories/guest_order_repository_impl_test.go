@@ -0,0 +1,140 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/pkg/utils"
+)
+
+// TestGuestOrderCreatePendingLimit memastikan pesanan tamu bersamaan tidak melewati batas pending meja
+func TestGuestOrderCreatePendingLimit(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		maxPending  int
+		submissions int
+	}{
+		{name: "batas satu pesanan", maxPending: 1, submissions: 4},
+		{name: "batas tiga pesanan", maxPending: 3, submissions: 6},
+		{name: "pesanan di bawah batas diterima semua", maxPending: 5, submissions: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			productID := insertTestProduct(t, conn, "Nasi Goreng", 25000, "")
+			repo := NewGuestOrderRepository(conn)
+
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			accepted, limited := 0, 0
+			for i := 0; i < tt.submissions; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := repo.Create(ctx, GuestOrderInput{
+						TableNumber: "A1",
+						Items:       []GuestOrderItemInput{{ProductID: productID, Qty: 1}},
+					}, tt.maxPending)
+					mu.Lock()
+					defer mu.Unlock()
+					switch {
+					case err == nil:
+						accepted++
+					case errors.Is(err, ErrGuestOrderLimit):
+						limited++
+					default:
+						t.Errorf("Create: %v", err)
+					}
+				}()
+			}
+			wg.Wait()
+
+			want := tt.submissions
+			if want > tt.maxPending {
+				want = tt.maxPending
+			}
+			if accepted != want || limited != tt.submissions-want {
+				t.Errorf("diterima=%d ditolak=%d, want %d dan %d", accepted, limited, want, tt.submissions-want)
+			}
+		})
+	}
+}
+
+// TestAddItemsToTableOrder memastikan pesanan bersamaan untuk satu meja masuk ke satu order
+func TestAddItemsToTableOrder(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		openOrder   bool
+		submissions int
+		wantCreated int
+	}{
+		{name: "meja kosong membuka satu order", submissions: 4, wantCreated: 1},
+		{name: "meja dengan order aktif menambah item", openOrder: true, submissions: 3, wantCreated: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			productID := insertTestProduct(t, conn, "Nasi Goreng", 25000, "")
+			mustExec(t, conn, `INSERT INTO tables (id, table_number) VALUES (?, 'A1')`, utils.GenerateULID())
+			existing := ""
+			if tt.openOrder {
+				existing = insertTestOrder(t, conn, time.Now(), testOrderItem{productID: productID, name: "Nasi Goreng", price: 25000, qty: 1})
+			}
+			repo := NewOrderRepository(conn)
+
+			var wg sync.WaitGroup
+			var mu sync.Mutex
+			orderIDs := map[string]bool{}
+			created := 0
+			for i := 0; i < tt.submissions; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					orderID, isNew, err := repo.AddItemsToTableOrder(ctx, OrderInput{
+						TableNumber: "A1",
+						Pax:         1,
+						Items:       []OrderItemInput{{ProductID: productID, Qty: 1}},
+					})
+					mu.Lock()
+					defer mu.Unlock()
+					if err != nil {
+						t.Errorf("AddItemsToTableOrder: %v", err)
+						return
+					}
+					orderIDs[orderID] = true
+					if isNew {
+						created++
+					}
+				}()
+			}
+			wg.Wait()
+
+			if created != tt.wantCreated {
+				t.Errorf("order baru = %d, want %d", created, tt.wantCreated)
+			}
+			if len(orderIDs) != 1 {
+				t.Fatalf("order untuk meja A1 = %v, want satu order", orderIDs)
+			}
+			if existing != "" && !orderIDs[existing] {
+				t.Errorf("item tidak masuk ke order aktif %s: %v", existing, orderIDs)
+			}
+
+			var open int
+			if err := conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE table_number = 'A1' AND payment_status != 'paid'`).Scan(&open); err != nil {
+				t.Fatalf("baca order: %v", err)
+			}
+			if open != 1 {
+				t.Errorf("order terbuka = %d, want 1", open)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// OrderCheck adalah satu tagihan terpisah (split check) dalam sebuah order. Check mode
+// items berisi item order (per kursi atau digeser antar check), check mode amount
+// berisi nominal tetap (bagi rata atau per nominal).
+type OrderCheck struct {
+	ID            string           `json:"id"`
+	OrderID       string           `json:"order_id"`
+	CheckNumber   int64            `json:"check_number"`
+	Name          string           `json:"name"`
+	SeatNumber    *int64           `json:"seat_number"`
+	SplitMode     string           `json:"split_mode"`
+	Amount        float64          `json:"amount"`
+	Subtotal      float64          `json:"subtotal"`
+	Due           float64          `json:"due"`
+	Status        string           `json:"status"`
+	PaidAmount    float64          `json:"paid_amount"`
+	TransactionID *string          `json:"transaction_id"`
+	PaidAt        *time.Time       `json:"paid_at"`
+	CreatedBy     string           `json:"created_by"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+	Items         []OrderCheckItem `json:"items"`
+}
+
+// OrderCheckItem adalah porsi qty sebuah item order di satu check (atau yang belum masuk check)
+type OrderCheckItem struct {
+	OrderItemID string  `json:"order_item_id"`
+	ProductName string  `json:"product_name"`
+	SeatNumber  *int64  `json:"seat_number"`
+	Qty         int64   `json:"qty"`
+	Price       float64 `json:"price"`
+	Total       float64 `json:"total"`
+}
+
+// OrderChecks adalah seluruh split check sebuah order. Complete = check sudah mencakup
+// seluruh tagihan sehingga tiap check bisa dibayar.
+type OrderChecks struct {
+	OrderID     string           `json:"order_id"`
+	SplitMode   string           `json:"split_mode"`
+	TotalAmount float64          `json:"total_amount"`
+	PaidAmount  float64          `json:"paid_amount"`
+	Remaining   float64          `json:"remaining"`
+	Complete    bool             `json:"complete"`
+	Checks      []OrderCheck     `json:"checks"`
+	Unassigned  []OrderCheckItem `json:"unassigned"`
+}
+
+type OrderCheckInput struct {
+	Name       string          `json:"name"`
+	SeatNumber *int64          `json:"seat_number"`
+	Items      []SplitBillItem `json:"items"`
+}
+
+// MoveCheckItemInput memindahkan qty item antar check; FromCheckID/ToCheckID kosong
+// berarti item yang belum masuk check
+type MoveCheckItemInput struct {
+	OrderID     string `json:"-"`
+	ItemID      string `json:"item_id"`
+	Qty         int64  `json:"qty"`
+	FromCheckID string `json:"from_check_id"`
+	ToCheckID   string `json:"to_check_id"`
+}
+
+const (
+	OrderCheckModeItems  = "items"
+	OrderCheckModeAmount = "amount"
+
+	OrderCheckStatusOpen   = "open"
+	OrderCheckStatusPaying = "paying"
+	OrderCheckStatusPaid   = "paid"
+
+	// maxOrderChecks membatasi jumlah check per order saat bagi rata
+	maxOrderChecks = 50
+)
+
+var (
+	ErrOrderCheckNotFound    = errors.New("check tidak ditemukan")
+	ErrInvalidOrderCheck     = errors.New("data split check tidak valid")
+	ErrOrderChecksIncomplete = errors.New("split check belum mencakup seluruh tagihan")
+	ErrOrderCheckPaid        = errors.New("check sudah dibayar atau sedang diproses")
+	ErrOrderHasChecks        = errors.New("order memakai split check, bayar per check")
+)
+
+type OrderCheckRepository interface {
+	List(ctx context.Context, orderID string) (*OrderChecks, error)
+	Create(ctx context.Context, orderID string, input OrderCheckInput, createdBy string) (*OrderChecks, error)
+	SplitEven(ctx context.Context, orderID string, count int, createdBy string) (*OrderChecks, error)
+	SplitByAmounts(ctx context.Context, orderID string, amounts []float64, createdBy string) (*OrderChecks, error)
+	SplitBySeat(ctx context.Context, orderID string, createdBy string) (*OrderChecks, error)
+	MoveItem(ctx context.Context, input MoveCheckItemInput) (*OrderChecks, error)
+	Delete(ctx context.Context, orderID, checkID string) (*OrderChecks, error)
+	Clear(ctx context.Context, orderID string) (*OrderChecks, error)
+	SetItemSeat(ctx context.Context, itemID string, seatNumber *int64) error
+}
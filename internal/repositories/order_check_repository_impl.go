@@ -0,0 +1,814 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type orderCheckRepository struct {
+	db *sql.DB
+}
+
+func NewOrderCheckRepository(dbConn *sql.DB) OrderCheckRepository {
+	return &orderCheckRepository{db: dbConn}
+}
+
+// unassignedItem adalah sisa qty item order yang belum masuk check mana pun
+type unassignedItem struct {
+	ID         string
+	Qty        int64
+	SeatNumber *int64
+}
+
+// checkOrderForChecks memastikan order bisa di-split: ada, belum lunas dan tidak di-void
+func checkOrderForChecks(ctx context.Context, q db.DBTX, orderID string) error {
+	var paymentStatus string
+	var voidedAt sql.NullTime
+	err := q.QueryRowContext(ctx, `
+		SELECT payment_status, voided_at
+		FROM orders
+		WHERE id = ?
+	`, orderID).Scan(&paymentStatus, &voidedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("%w: order tidak ditemukan", ErrInvalidOrderCheck)
+	}
+	if err != nil {
+		return err
+	}
+	if voidedAt.Valid {
+		return fmt.Errorf("%w: order sudah di-void", ErrInvalidOrderCheck)
+	}
+	if paymentStatus == "paid" {
+		return fmt.Errorf("%w: order sudah lunas", ErrInvalidOrderCheck)
+	}
+	return nil
+}
+
+// mutate menjalankan perubahan split check dalam satu transaksi lalu mengembalikan
+// susunan check terbaru
+func (r *orderCheckRepository) mutate(ctx context.Context, orderID string, fn func(tx *sql.Tx) error) (*OrderChecks, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := checkOrderForChecks(ctx, tx, orderID); err != nil {
+		return nil, err
+	}
+	if err := fn(tx); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return LoadOrderChecks(ctx, r.db, orderID)
+}
+
+func scanOrderCheck(scanner interface{ Scan(dest ...any) error }) (*OrderCheck, error) {
+	var check OrderCheck
+	var seatNumber sql.NullInt64
+	var transactionID sql.NullString
+	var paidAt sql.NullTime
+	if err := scanner.Scan(&check.ID, &check.OrderID, &check.CheckNumber, &check.Name, &seatNumber, &check.SplitMode,
+		&check.Amount, &check.Status, &check.PaidAmount, &transactionID, &paidAt, &check.CreatedBy,
+		&check.CreatedAt, &check.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if seatNumber.Valid {
+		check.SeatNumber = &seatNumber.Int64
+	}
+	if transactionID.Valid {
+		check.TransactionID = &transactionID.String
+	}
+	if paidAt.Valid {
+		check.PaidAt = &paidAt.Time
+	}
+	check.Items = []OrderCheckItem{}
+	return &check, nil
+}
+
+// LoadOrderChecks menyusun seluruh check order beserta isi, item yang belum masuk check
+// dan nominal yang harus dibayar (due) tiap check. Check mode items mendapat porsi total
+// order sesuai subtotal itemnya (pembulatan kumulatif agar jumlahnya pas); check terakhir
+// yang belum dibayar selalu menagih sisa tagihan sehingga order lunas tepat saat semua
+// check dibayar.
+func LoadOrderChecks(ctx context.Context, q db.DBTX, orderID string) (*OrderChecks, error) {
+	result := &OrderChecks{
+		OrderID:    orderID,
+		Checks:     []OrderCheck{},
+		Unassigned: []OrderCheckItem{},
+	}
+	err := q.QueryRowContext(ctx, "SELECT total_amount, paid_amount FROM orders WHERE id = ?", orderID).
+		Scan(&result.TotalAmount, &result.PaidAmount)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: order tidak ditemukan", ErrInvalidOrderCheck)
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Remaining = math.Max(math.Round(result.TotalAmount-result.PaidAmount), 0)
+
+	itemRows, err := q.QueryContext(ctx, `
+		SELECT id, product_name, qty, price, seat_number
+		FROM order_items
+		WHERE order_id = ?
+		ORDER BY created_at, id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	items := make(map[string]OrderCheckItem)
+	var itemOrder []string
+	orderSubtotal := 0.0
+	for itemRows.Next() {
+		var item OrderCheckItem
+		var seatNumber sql.NullInt64
+		if err := itemRows.Scan(&item.OrderItemID, &item.ProductName, &item.Qty, &item.Price, &seatNumber); err != nil {
+			return nil, err
+		}
+		if seatNumber.Valid {
+			item.SeatNumber = &seatNumber.Int64
+		}
+		item.Total = item.Price * float64(item.Qty)
+		orderSubtotal += item.Total
+		items[item.OrderItemID] = item
+		itemOrder = append(itemOrder, item.OrderItemID)
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, err
+	}
+
+	checkRows, err := q.QueryContext(ctx, `
+		SELECT id, order_id, check_number, name, seat_number, split_mode, amount, status, paid_amount,
+		       transaction_id, paid_at, created_by, created_at, updated_at
+		FROM order_checks
+		WHERE order_id = ?
+		ORDER BY check_number
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer checkRows.Close()
+
+	checkIndex := make(map[string]int)
+	for checkRows.Next() {
+		check, err := scanOrderCheck(checkRows)
+		if err != nil {
+			return nil, err
+		}
+		checkIndex[check.ID] = len(result.Checks)
+		result.Checks = append(result.Checks, *check)
+	}
+	if err := checkRows.Err(); err != nil {
+		return nil, err
+	}
+	if len(result.Checks) == 0 {
+		for _, id := range itemOrder {
+			result.Unassigned = append(result.Unassigned, items[id])
+		}
+		return result, nil
+	}
+
+	// Alokasi item ke check; alokasi item yang sudah dihapus dari order diabaikan
+	allocRows, err := q.QueryContext(ctx, `
+		SELECT ci.check_id, ci.order_item_id, ci.qty
+		FROM order_check_items ci
+		JOIN order_checks oc ON oc.id = ci.check_id
+		JOIN order_items oi ON oi.id = ci.order_item_id
+		WHERE oc.order_id = ?
+		ORDER BY oi.created_at, oi.id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer allocRows.Close()
+
+	allocated := make(map[string]int64)
+	for allocRows.Next() {
+		var checkID, itemID string
+		var qty int64
+		if err := allocRows.Scan(&checkID, &itemID, &qty); err != nil {
+			return nil, err
+		}
+		item, ok := items[itemID]
+		if !ok {
+			continue
+		}
+		item.Qty = qty
+		item.Total = item.Price * float64(qty)
+		check := &result.Checks[checkIndex[checkID]]
+		check.Items = append(check.Items, item)
+		check.Subtotal += item.Total
+		allocated[itemID] += qty
+	}
+	if err := allocRows.Err(); err != nil {
+		return nil, err
+	}
+
+	overAllocated := false
+	for _, id := range itemOrder {
+		item := items[id]
+		rest := item.Qty - allocated[id]
+		if rest < 0 {
+			overAllocated = true
+		}
+		if rest > 0 {
+			item.Qty = rest
+			item.Total = item.Price * float64(rest)
+			result.Unassigned = append(result.Unassigned, item)
+		}
+	}
+
+	result.SplitMode = result.Checks[0].SplitMode
+	paidChecks := 0.0
+	openChecks := 0
+	for _, check := range result.Checks {
+		if check.Status == OrderCheckStatusPaid {
+			paidChecks += check.PaidAmount
+		} else {
+			openChecks++
+		}
+	}
+	// Pembayaran di luar check (mis. sebelum order di-split) tidak ikut dibagi
+	nonCheckPaid := math.Max(result.PaidAmount-paidChecks, 0)
+
+	switch result.SplitMode {
+	case OrderCheckModeAmount:
+		// Check nominal tidak berisi item
+		result.Unassigned = []OrderCheckItem{}
+		covered := nonCheckPaid
+		for i := range result.Checks {
+			check := &result.Checks[i]
+			if check.Status == OrderCheckStatusPaid {
+				covered += check.PaidAmount
+				continue
+			}
+			covered += check.Amount
+			check.Due = check.Amount
+		}
+		result.Complete = math.Round(covered) == math.Round(result.TotalAmount)
+	default:
+		result.Complete = len(result.Unassigned) == 0 && !overAllocated
+		base := result.TotalAmount - nonCheckPaid
+		cumulative, previous := 0.0, 0.0
+		for i := range result.Checks {
+			check := &result.Checks[i]
+			cumulative += check.Subtotal
+			share := 0.0
+			if orderSubtotal > 0 {
+				next := math.Round(base * cumulative / orderSubtotal)
+				share = next - previous
+				previous = next
+			}
+			if check.Status != OrderCheckStatusPaid {
+				check.Due = share
+			}
+		}
+	}
+
+	for i := range result.Checks {
+		check := &result.Checks[i]
+		if check.Status == OrderCheckStatusPaid {
+			continue
+		}
+		if result.Complete && openChecks == 1 {
+			check.Due = result.Remaining
+		}
+		check.Due = math.Max(math.Min(check.Due, result.Remaining), 0)
+	}
+	return result, nil
+}
+
+// HasOpenOrderChecks mengecek apakah order masih punya check yang belum dibayar
+func HasOpenOrderChecks(ctx context.Context, q db.DBTX, orderID string) (bool, error) {
+	var count int
+	err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM order_checks
+		WHERE order_id = ? AND status != 'paid'
+	`, orderID).Scan(&count)
+	return count > 0, err
+}
+
+// ClaimOrderCheck menandai check sedang dibayar agar tidak dibayar dua kali bersamaan
+func ClaimOrderCheck(ctx context.Context, q db.DBTX, checkID string) error {
+	result, err := q.ExecContext(ctx, `
+		UPDATE order_checks
+		SET status = 'paying', updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'open'
+	`, checkID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrOrderCheckPaid
+	}
+	return nil
+}
+
+// SettleOrderCheck menandai check lunas beserta transaksi pembayarannya
+func SettleOrderCheck(ctx context.Context, q db.DBTX, checkID string, amount float64, transactionID string) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE order_checks
+		SET status = 'paid', paid_amount = ?, transaction_id = ?, paid_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, amount, nullableID(transactionID), time.Now().UTC(), checkID)
+	return err
+}
+
+func (r *orderCheckRepository) List(ctx context.Context, orderID string) (*OrderChecks, error) {
+	return LoadOrderChecks(ctx, r.db, orderID)
+}
+
+func nextCheckNumber(ctx context.Context, q db.DBTX, orderID string) (int64, error) {
+	var number int64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(check_number), 0) + 1
+		FROM order_checks
+		WHERE order_id = ?
+	`, orderID).Scan(&number)
+	return number, err
+}
+
+func insertOrderCheck(ctx context.Context, q db.DBTX, check *OrderCheck) error {
+	number, err := nextCheckNumber(ctx, q, check.OrderID)
+	if err != nil {
+		return err
+	}
+	check.ID = utils.GenerateULID()
+	check.CheckNumber = number
+	check.Name = strings.TrimSpace(check.Name)
+	if check.Name == "" {
+		check.Name = fmt.Sprintf("Check %d", number)
+	}
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO order_checks (id, order_id, check_number, name, seat_number, split_mode, amount, created_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, check.ID, check.OrderID, check.CheckNumber, check.Name, check.SeatNumber, check.SplitMode, check.Amount, check.CreatedBy)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan check: %w", err)
+	}
+	return nil
+}
+
+// addCheckItem menambah qty item ke check (digabung jika item sudah ada di check)
+func addCheckItem(ctx context.Context, q db.DBTX, checkID, itemID string, qty int64) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO order_check_items (check_id, order_item_id, qty)
+		VALUES (?, ?, ?)
+		ON CONFLICT(check_id, order_item_id) DO UPDATE SET qty = qty + excluded.qty
+	`, checkID, itemID, qty)
+	return err
+}
+
+// removeCheckItem mengurangi qty item di check; baris dihapus jika qty habis
+func removeCheckItem(ctx context.Context, q db.DBTX, checkID, itemID string, qty int64) error {
+	var current int64
+	err := q.QueryRowContext(ctx, `
+		SELECT qty FROM order_check_items WHERE check_id = ? AND order_item_id = ?
+	`, checkID, itemID).Scan(&current)
+	if err == sql.ErrNoRows || (err == nil && qty > current) {
+		return fmt.Errorf("%w: qty melebihi item di check", ErrInvalidOrderCheck)
+	}
+	if err != nil {
+		return err
+	}
+	if qty == current {
+		_, err = q.ExecContext(ctx, "DELETE FROM order_check_items WHERE check_id = ? AND order_item_id = ?", checkID, itemID)
+		return err
+	}
+	_, err = q.ExecContext(ctx, `
+		UPDATE order_check_items SET qty = qty - ? WHERE check_id = ? AND order_item_id = ?
+	`, qty, checkID, itemID)
+	return err
+}
+
+// loadUnassignedItems mengambil sisa qty item order yang belum masuk check
+func loadUnassignedItems(ctx context.Context, q db.DBTX, orderID string) ([]unassignedItem, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT oi.id, oi.qty - COALESCE((
+			SELECT SUM(ci.qty)
+			FROM order_check_items ci
+			JOIN order_checks oc ON oc.id = ci.check_id
+			WHERE ci.order_item_id = oi.id AND oc.order_id = oi.order_id
+		), 0) AS rest, oi.seat_number
+		FROM order_items oi
+		WHERE oi.order_id = ?
+		ORDER BY oi.created_at, oi.id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []unassignedItem
+	for rows.Next() {
+		var item unassignedItem
+		var seatNumber sql.NullInt64
+		if err := rows.Scan(&item.ID, &item.Qty, &seatNumber); err != nil {
+			return nil, err
+		}
+		if item.Qty <= 0 {
+			continue
+		}
+		if seatNumber.Valid {
+			item.SeatNumber = &seatNumber.Int64
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// currentCheckMode mengembalikan mode split order saat ini (kosong jika belum di-split)
+func currentCheckMode(ctx context.Context, q db.DBTX, orderID string) (string, error) {
+	var mode string
+	err := q.QueryRowContext(ctx, `
+		SELECT split_mode FROM order_checks WHERE order_id = ? ORDER BY check_number LIMIT 1
+	`, orderID).Scan(&mode)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return mode, err
+}
+
+// ensureCheckMode menolak mencampur check per item dengan check per nominal
+func ensureCheckMode(ctx context.Context, q db.DBTX, orderID, mode string) error {
+	current, err := currentCheckMode(ctx, q, orderID)
+	if err != nil {
+		return err
+	}
+	if current == "" || current == mode {
+		return nil
+	}
+	if current == OrderCheckModeAmount {
+		return fmt.Errorf("%w: order sudah dibagi per nominal", ErrInvalidOrderCheck)
+	}
+	return fmt.Errorf("%w: order sudah dibagi per item", ErrInvalidOrderCheck)
+}
+
+// deleteOpenChecks menghapus check yang belum dibayar; check lunas tetap tersimpan
+func deleteOpenChecks(ctx context.Context, q db.DBTX, orderID string) error {
+	var paying int
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM order_checks WHERE order_id = ? AND status = 'paying'
+	`, orderID).Scan(&paying); err != nil {
+		return err
+	}
+	if paying > 0 {
+		return ErrOrderCheckPaid
+	}
+	if _, err := q.ExecContext(ctx, `
+		DELETE FROM order_check_items
+		WHERE check_id IN (SELECT id FROM order_checks WHERE order_id = ? AND status = 'open')
+	`, orderID); err != nil {
+		return err
+	}
+	_, err := q.ExecContext(ctx, "DELETE FROM order_checks WHERE order_id = ? AND status = 'open'", orderID)
+	return err
+}
+
+// loadOpenCheck mengambil check milik order yang masih bisa diubah
+func loadOpenCheck(ctx context.Context, q db.DBTX, orderID, checkID string) (*OrderCheck, error) {
+	check, err := scanOrderCheck(q.QueryRowContext(ctx, `
+		SELECT id, order_id, check_number, name, seat_number, split_mode, amount, status, paid_amount,
+		       transaction_id, paid_at, created_by, created_at, updated_at
+		FROM order_checks
+		WHERE id = ? AND order_id = ?
+	`, checkID, orderID))
+	if err == sql.ErrNoRows {
+		return nil, ErrOrderCheckNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if check.Status != OrderCheckStatusOpen {
+		return nil, ErrOrderCheckPaid
+	}
+	return check, nil
+}
+
+// orderRemaining adalah sisa tagihan order (dibulatkan ke rupiah)
+func orderRemaining(ctx context.Context, q db.DBTX, orderID string) (float64, error) {
+	var total, paid float64
+	if err := q.QueryRowContext(ctx, "SELECT total_amount, paid_amount FROM orders WHERE id = ?", orderID).Scan(&total, &paid); err != nil {
+		return 0, err
+	}
+	return math.Max(math.Round(total-paid), 0), nil
+}
+
+func (r *orderCheckRepository) Create(ctx context.Context, orderID string, input OrderCheckInput, createdBy string) (*OrderChecks, error) {
+	if input.SeatNumber != nil && *input.SeatNumber <= 0 {
+		return nil, fmt.Errorf("%w: nomor kursi harus lebih dari 0", ErrInvalidOrderCheck)
+	}
+	return r.mutate(ctx, orderID, func(tx *sql.Tx) error {
+		if err := ensureCheckMode(ctx, tx, orderID, OrderCheckModeItems); err != nil {
+			return err
+		}
+		unassigned, err := loadUnassignedItems(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		available := make(map[string]int64, len(unassigned))
+		for _, item := range unassigned {
+			available[item.ID] = item.Qty
+		}
+		for _, item := range input.Items {
+			if item.ItemID == "" || item.Qty <= 0 {
+				return fmt.Errorf("%w: item_id dan qty wajib diisi", ErrInvalidOrderCheck)
+			}
+			if item.Qty > available[item.ItemID] {
+				return fmt.Errorf("%w: qty item %s melebihi item yang belum masuk check", ErrInvalidOrderCheck, item.ItemID)
+			}
+			available[item.ItemID] -= item.Qty
+		}
+
+		check := &OrderCheck{
+			OrderID:    orderID,
+			Name:       input.Name,
+			SeatNumber: input.SeatNumber,
+			SplitMode:  OrderCheckModeItems,
+			CreatedBy:  createdBy,
+		}
+		if err := insertOrderCheck(ctx, tx, check); err != nil {
+			return err
+		}
+		for _, item := range input.Items {
+			if err := addCheckItem(ctx, tx, check.ID, item.ItemID, item.Qty); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SplitEven membagi sisa tagihan rata ke count check. Sisa pembulatan rupiah
+// dibebankan satu per satu ke check pertama sehingga jumlahnya tepat.
+func (r *orderCheckRepository) SplitEven(ctx context.Context, orderID string, count int, createdBy string) (*OrderChecks, error) {
+	if count < 2 || count > maxOrderChecks {
+		return nil, fmt.Errorf("%w: jumlah check harus 2 sampai %d", ErrInvalidOrderCheck, maxOrderChecks)
+	}
+	return r.mutate(ctx, orderID, func(tx *sql.Tx) error {
+		if err := deleteOpenChecks(ctx, tx, orderID); err != nil {
+			return err
+		}
+		if err := ensureCheckMode(ctx, tx, orderID, OrderCheckModeAmount); err != nil {
+			return err
+		}
+		remaining, err := orderRemaining(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if remaining < float64(count) {
+			return fmt.Errorf("%w: sisa tagihan terlalu kecil untuk dibagi %d", ErrInvalidOrderCheck, count)
+		}
+
+		share := math.Floor(remaining / float64(count))
+		extra := int(remaining - share*float64(count))
+		for i := 0; i < count; i++ {
+			amount := share
+			if i < extra {
+				amount++
+			}
+			check := &OrderCheck{
+				OrderID:   orderID,
+				Name:      fmt.Sprintf("Bagi rata %d/%d", i+1, count),
+				SplitMode: OrderCheckModeAmount,
+				Amount:    amount,
+				CreatedBy: createdBy,
+			}
+			if err := insertOrderCheck(ctx, tx, check); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SplitByAmounts membuat check per nominal; jika nominal belum menutup sisa tagihan,
+// ditambahkan satu check untuk sisanya
+func (r *orderCheckRepository) SplitByAmounts(ctx context.Context, orderID string, amounts []float64, createdBy string) (*OrderChecks, error) {
+	if len(amounts) == 0 || len(amounts) > maxOrderChecks {
+		return nil, fmt.Errorf("%w: jumlah check harus 1 sampai %d", ErrInvalidOrderCheck, maxOrderChecks)
+	}
+	rounded := make([]float64, len(amounts))
+	total := 0.0
+	for i, amount := range amounts {
+		rounded[i] = math.Round(amount)
+		if rounded[i] <= 0 {
+			return nil, fmt.Errorf("%w: nominal check harus lebih dari 0", ErrInvalidOrderCheck)
+		}
+		total += rounded[i]
+	}
+	return r.mutate(ctx, orderID, func(tx *sql.Tx) error {
+		if err := deleteOpenChecks(ctx, tx, orderID); err != nil {
+			return err
+		}
+		if err := ensureCheckMode(ctx, tx, orderID, OrderCheckModeAmount); err != nil {
+			return err
+		}
+		remaining, err := orderRemaining(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if total > remaining {
+			return fmt.Errorf("%w: total nominal melebihi sisa tagihan", ErrInvalidOrderCheck)
+		}
+		if total < remaining {
+			rounded = append(rounded, remaining-total)
+		}
+		for i, amount := range rounded {
+			name := fmt.Sprintf("Nominal %d", i+1)
+			if i == len(amounts) {
+				name = "Sisa tagihan"
+			}
+			check := &OrderCheck{
+				OrderID:   orderID,
+				Name:      name,
+				SplitMode: OrderCheckModeAmount,
+				Amount:    amount,
+				CreatedBy: createdBy,
+			}
+			if err := insertOrderCheck(ctx, tx, check); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SplitBySeat membuat satu check per nomor kursi dari item yang belum dibayar.
+// Item tanpa nomor kursi masuk check "Bersama".
+func (r *orderCheckRepository) SplitBySeat(ctx context.Context, orderID string, createdBy string) (*OrderChecks, error) {
+	return r.mutate(ctx, orderID, func(tx *sql.Tx) error {
+		if err := deleteOpenChecks(ctx, tx, orderID); err != nil {
+			return err
+		}
+		if err := ensureCheckMode(ctx, tx, orderID, OrderCheckModeItems); err != nil {
+			return err
+		}
+		unassigned, err := loadUnassignedItems(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if len(unassigned) == 0 {
+			return fmt.Errorf("%w: tidak ada item untuk dibagi", ErrInvalidOrderCheck)
+		}
+
+		const sharedSeat = int64(-1)
+		bySeat := make(map[int64][]unassignedItem)
+		for _, item := range unassigned {
+			seat := sharedSeat
+			if item.SeatNumber != nil {
+				seat = *item.SeatNumber
+			}
+			bySeat[seat] = append(bySeat[seat], item)
+		}
+		if _, onlyShared := bySeat[sharedSeat]; onlyShared && len(bySeat) == 1 {
+			return fmt.Errorf("%w: atur nomor kursi item terlebih dahulu", ErrInvalidOrderCheck)
+		}
+
+		seats := make([]int64, 0, len(bySeat))
+		for seat := range bySeat {
+			seats = append(seats, seat)
+		}
+		// Kursi berurutan, item bersama di akhir
+		sort.Slice(seats, func(i, j int) bool {
+			if seats[i] == sharedSeat || seats[j] == sharedSeat {
+				return seats[j] == sharedSeat && seats[i] != sharedSeat
+			}
+			return seats[i] < seats[j]
+		})
+
+		for _, seat := range seats {
+			check := &OrderCheck{
+				OrderID:   orderID,
+				Name:      "Bersama",
+				SplitMode: OrderCheckModeItems,
+				CreatedBy: createdBy,
+			}
+			if seat != sharedSeat {
+				seatNumber := seat
+				check.Name = fmt.Sprintf("Kursi %d", seat)
+				check.SeatNumber = &seatNumber
+			}
+			if err := insertOrderCheck(ctx, tx, check); err != nil {
+				return err
+			}
+			for _, item := range bySeat[seat] {
+				if err := addCheckItem(ctx, tx, check.ID, item.ID, item.Qty); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// MoveItem memindahkan qty item antar check yang belum dibayar
+func (r *orderCheckRepository) MoveItem(ctx context.Context, input MoveCheckItemInput) (*OrderChecks, error) {
+	if input.ItemID == "" || input.Qty <= 0 {
+		return nil, fmt.Errorf("%w: item_id dan qty wajib diisi", ErrInvalidOrderCheck)
+	}
+	if input.FromCheckID == input.ToCheckID {
+		return nil, fmt.Errorf("%w: check asal dan tujuan sama", ErrInvalidOrderCheck)
+	}
+	return r.mutate(ctx, input.OrderID, func(tx *sql.Tx) error {
+		var exists int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM order_items WHERE id = ? AND order_id = ?
+		`, input.ItemID, input.OrderID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: item tidak ditemukan di order", ErrInvalidOrderCheck)
+		}
+		for _, checkID := range []string{input.FromCheckID, input.ToCheckID} {
+			if checkID == "" {
+				continue
+			}
+			check, err := loadOpenCheck(ctx, tx, input.OrderID, checkID)
+			if err != nil {
+				return err
+			}
+			if check.SplitMode != OrderCheckModeItems {
+				return fmt.Errorf("%w: item hanya bisa dipindah antar check per item", ErrInvalidOrderCheck)
+			}
+		}
+
+		if input.FromCheckID == "" {
+			unassigned, err := loadUnassignedItems(ctx, tx, input.OrderID)
+			if err != nil {
+				return err
+			}
+			available := int64(0)
+			for _, item := range unassigned {
+				if item.ID == input.ItemID {
+					available = item.Qty
+				}
+			}
+			if input.Qty > available {
+				return fmt.Errorf("%w: qty melebihi item yang belum masuk check", ErrInvalidOrderCheck)
+			}
+		} else if err := removeCheckItem(ctx, tx, input.FromCheckID, input.ItemID, input.Qty); err != nil {
+			return err
+		}
+		if input.ToCheckID == "" {
+			return nil
+		}
+		return addCheckItem(ctx, tx, input.ToCheckID, input.ItemID, input.Qty)
+	})
+}
+
+// Delete menghapus satu check yang belum dibayar; itemnya kembali belum masuk check
+func (r *orderCheckRepository) Delete(ctx context.Context, orderID, checkID string) (*OrderChecks, error) {
+	return r.mutate(ctx, orderID, func(tx *sql.Tx) error {
+		if _, err := loadOpenCheck(ctx, tx, orderID, checkID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM order_check_items WHERE check_id = ?", checkID); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx, "DELETE FROM order_checks WHERE id = ?", checkID)
+		return err
+	})
+}
+
+// Clear membatalkan split: semua check yang belum dibayar dihapus
+func (r *orderCheckRepository) Clear(ctx context.Context, orderID string) (*OrderChecks, error) {
+	return r.mutate(ctx, orderID, func(tx *sql.Tx) error {
+		return deleteOpenChecks(ctx, tx, orderID)
+	})
+}
+
+// SetItemSeat mengatur nomor kursi item order (nil = item bersama)
+func (r *orderCheckRepository) SetItemSeat(ctx context.Context, itemID string, seatNumber *int64) error {
+	if seatNumber != nil && *seatNumber <= 0 {
+		return fmt.Errorf("%w: nomor kursi harus lebih dari 0", ErrInvalidOrderCheck)
+	}
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE order_items
+		SET seat_number = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, seatNumber, itemID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("%w: item tidak ditemukan", ErrInvalidOrderCheck)
+	}
+	return nil
+}
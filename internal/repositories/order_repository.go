@@ -12,6 +12,33 @@ import (
 type OrderItemInput struct {
 	ProductID string `json:"product_id"`
 	Qty       int64  `json:"qty"`
+	// Course: starter, main atau dessert; Hold = tahan item sampai course di-fire
+	Course string `json:"course,omitempty"`
+	Hold   bool   `json:"hold,omitempty"`
+}
+
+const (
+	CourseStarter = "starter"
+	CourseMain    = "main"
+	CourseDessert = "dessert"
+
+	KitchenMarkerFire = "FIRE"
+	KitchenMarkerHold = "HOLD"
+)
+
+// ItemCourse adalah data course item order (kolom tambahan di luar query sqlc)
+type ItemCourse struct {
+	Course  string     `json:"course"`
+	IsHeld  bool       `json:"is_held"`
+	FiredAt *time.Time `json:"fired_at"`
+}
+
+// CourseItem adalah item yang di-fire atau ditahan
+type CourseItem struct {
+	ItemID      string `json:"item_id"`
+	ProductName string `json:"product_name"`
+	Qty         int64  `json:"qty"`
+	Course      string `json:"course"`
 }
 
 type SplitBillItem struct {
@@ -19,6 +46,61 @@ type SplitBillItem struct {
 	Qty    int64  `json:"qty"`
 }
 
+// OrderSettlement adalah pelunasan sisa tagihan order oleh kasir atau payment provider.
+// Deposit, status bayar, meja, transaksi dan tip dicatat dalam satu transaksi database.
+type OrderSettlement struct {
+	OrderID         string
+	PaymentMethod   string
+	ReferenceNumber string
+	// Amount adalah nominal yang ditagih lewat PaymentMethod (sisa tagihan setelah deposit)
+	Amount float64
+	// DepositAmount adalah deposit yang dihitung saat validasi; pelunasan dibatalkan
+	// jika saldo deposit berubah sebelum transaksi berjalan
+	DepositAmount float64
+	// GiftCardCode wajib untuk metode gift_card; saldo dipotong sebesar Amount + tip
+	GiftCardCode string
+	Tip          *PaymentTip
+	CashierID    string
+	ShiftID      string
+	// PaymentIntentID diisi saat pelunasan dari intent QRIS; intent ditandai settled di transaksi yang sama
+	PaymentIntentID string
+}
+
+// SplitPayment adalah pembayaran parsial per item/nominal atau pelunasan satu split check.
+// Baris pembayaran, transaksi, tip, gift card dan status check dicatat dalam satu transaksi database.
+type SplitPayment struct {
+	OrderID         string
+	PaymentMethod   string
+	ReferenceNumber string
+	Note            string
+	Amount          float64
+	// Items berisi item yang dibayar; kosong untuk pembayaran nominal
+	Items []SplitBillItem
+	// CheckID diisi saat melunasi satu split check
+	CheckID string
+	// GiftCardCode wajib untuk metode gift_card; saldo dipotong sebesar Amount + tip
+	GiftCardCode string
+	Tip          *PaymentTip
+	CashierID    string
+	ShiftID      string
+	// PaymentIntentID diisi saat pelunasan dari intent QRIS; intent ditandai settled di transaksi yang sama
+	PaymentIntentID string
+}
+
+type SplitPaymentResult struct {
+	Transaction   db.Transaction
+	PaymentStatus string
+	// TableNumbers berisi meja yang ditandai paid jika order menjadi lunas
+	TableNumbers []string
+}
+
+type OrderSettlementResult struct {
+	// Transaction nil jika tagihan tertutup penuh oleh deposit dan tanpa tip
+	Transaction    *db.Transaction
+	DepositApplied float64
+	TableNumbers   []string
+}
+
 // OrderInput represents the complete order request.
 type OrderInput struct {
 	TableNumber   string           `json:"table_number"`
@@ -29,6 +111,10 @@ type OrderInput struct {
 	Items         []OrderItemInput `json:"items"`
 	PrinterID     string           `json:"printer_id"` // Target printer ULID
 	CreatedBy     string           `json:"created_by,omitempty"`
+	// Tipe order; kosong = dine-in. Order tanpa meja mendapat nomor antrian.
+	OrderType       string     `json:"order_type,omitempty"`
+	PickupAt        *time.Time `json:"pickup_at,omitempty"`
+	DeliveryAddress string     `json:"delivery_address,omitempty"`
 }
 
 // TimeSeriesData represents revenue data for a time point
@@ -56,12 +142,118 @@ type VoidedOrderHistory struct {
 	VoidReason    sql.NullString `json:"void_reason"`
 }
 
+// RefundItemInput adalah item order yang dikembalikan beserta qty-nya
+type RefundItemInput struct {
+	ItemID string `json:"item_id"`
+	Qty    int64  `json:"qty"`
+}
+
+// RefundInput adalah permintaan refund untuk order yang sudah lunas.
+// Jika Items kosong, refund berupa nominal (Amount) saja.
+type RefundInput struct {
+	OrderID           string
+	OriginalPaymentID string
+	RefundMethod      string
+	Amount            float64
+	Reason            string
+	Items             []RefundItemInput
+	Restock           bool
+	ApprovedBy        string
+	CreatedBy         string
+	ShiftID           string
+}
+
+type RefundItem struct {
+	OrderItemID string  `json:"order_item_id"`
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Qty         int64   `json:"qty"`
+	Price       float64 `json:"price"`
+	Amount      float64 `json:"amount"`
+}
+
+type Refund struct {
+	ID                string       `json:"id"`
+	OrderID           string       `json:"order_id"`
+	PaymentID         string       `json:"payment_id"`
+	OriginalPaymentID string       `json:"original_payment_id"`
+	RefundType        string       `json:"refund_type"`
+	RefundMethod      string       `json:"refund_method"`
+	Amount            float64      `json:"amount"`
+	Reason            string       `json:"reason"`
+	Restock           bool         `json:"restock"`
+	ApprovedBy        string       `json:"approved_by"`
+	ApprovedByName    string       `json:"approved_by_name"`
+	CreatedBy         string       `json:"created_by"`
+	CreatedByName     string       `json:"created_by_name"`
+	CreatedAt         time.Time    `json:"created_at"`
+	Items             []RefundItem `json:"items"`
+}
+
+// RefundSummary adalah rekap refund per metode untuk analytics
+type RefundSummary struct {
+	RefundMethod string  `json:"refund_method"`
+	Count        int64   `json:"count"`
+	Total        float64 `json:"total"`
+}
+
+const (
+	TableChangeTransfer  = "transfer"
+	TableChangeMoveItems = "move_items"
+	TableChangeMerge     = "merge"
+	TableChangeUnmerge   = "unmerge"
+)
+
+// MoveItemInput adalah item yang dipindah ke order lain; qty 0 = seluruh qty item
+type MoveItemInput struct {
+	ItemID string `json:"item_id"`
+	Qty    int64  `json:"qty"`
+}
+
+// TableChangeItem adalah item yang ikut berpindah
+type TableChangeItem struct {
+	ItemID      string `json:"item_id"`
+	ProductName string `json:"product_name"`
+	Qty         int64  `json:"qty"`
+	ItemStatus  string `json:"item_status"`
+}
+
+// TableChange adalah satu perpindahan meja/item; dipakai untuk response,
+// event realtime dan slip perpindahan di dapur
+type TableChange struct {
+	ID              string            `json:"id"`
+	ChangeType      string            `json:"change_type"`
+	FromOrderID     string            `json:"from_order_id"`
+	ToOrderID       string            `json:"to_order_id"`
+	FromTableNumber string            `json:"from_table_number"`
+	ToTableNumber   string            `json:"to_table_number"`
+	Items           []TableChangeItem `json:"items"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+// UnmergeResult adalah hasil pisah meja: order asal yang dipulihkan dan meja yang terdampak
+type UnmergeResult struct {
+	MergedOrderID  string        `json:"merged_order_id"`
+	RestoredOrders []string      `json:"restored_orders"`
+	FreedTables    []string      `json:"freed_tables"`
+	Changes        []TableChange `json:"changes"`
+}
+
 var (
 	ErrOrderAlreadyPaid   = errors.New("order sudah dibayar")
 	ErrOrderItemNotFound  = errors.New("item tidak ditemukan")
 	ErrOrderItemProcessed = errors.New("item sudah diproses kitchen")
 	ErrInvalidItemQty     = errors.New("qty tidak valid")
 	ErrOrderVoided        = errors.New("order sudah di-void")
+	ErrOrderNotPaid       = errors.New("order belum lunas")
+	ErrInvalidRefund      = errors.New("data refund tidak valid")
+	ErrRefundExceedsPaid  = errors.New("nominal refund melebihi sisa pembayaran")
+	ErrInvalidTableChange = errors.New("perpindahan meja tidak valid")
+	ErrTableOccupied      = errors.New("meja tujuan sedang dipakai order lain")
+	ErrInvalidCourse      = errors.New("course tidak valid")
+	ErrNoHeldItems        = errors.New("tidak ada item yang ditahan")
+	ErrNoHoldableItems    = errors.New("tidak ada item pending yang bisa ditahan")
+	ErrOrderItemHeld      = errors.New("item sedang ditahan, fire course terlebih dahulu")
 )
 
 // OrderRepository adalah interface untuk operasi database order
@@ -73,9 +265,16 @@ type OrderRepository interface {
 	UpdateOrderItemStatus(ctx context.Context, itemID string, status string) error
 	UpdateOrderItemQty(ctx context.Context, itemID string, qty int64) error
 	AddItemsToOrder(ctx context.Context, orderID string, items []OrderItemInput) error
+	// AddItemsToTableOrder menambahkan item ke order aktif meja, atau membuat order baru jika
+	// belum ada, dalam satu transaksi. Mengembalikan true jika order baru dibuat.
+	AddItemsToTableOrder(ctx context.Context, input OrderInput) (string, bool, error)
 	ProcessPayment(ctx context.Context, orderID string) error
+	SettleOrder(ctx context.Context, input OrderSettlement) (*OrderSettlementResult, error)
 	ApplyOrderDiscount(ctx context.Context, orderID string, chargeType string, value float64) error
 	ApplyOrderCompliment(ctx context.Context, orderID string) error
+	ApplyVoucher(ctx context.Context, orderID string, code string, appliedBy string) error
+	RemoveVoucher(ctx context.Context, orderID string, code string) error
+	GetOrderPromotions(ctx context.Context, orderID string) ([]AppliedPromotion, error)
 	GetOrderWithItems(ctx context.Context, orderID string) (*db.Order, []db.OrderItem, error)
 	GetOrderByTableID(ctx context.Context, tableID string) (*db.Order, []db.OrderItem, error)
 	GetOrderAnalytics(ctx context.Context, startDate, endDate time.Time) (*db.GetOrderAnalyticsRow, error)
@@ -83,14 +282,23 @@ type OrderRepository interface {
 	GetVoidedTotalByDateRange(ctx context.Context, startDate, endDate time.Time) (float64, error)
 	GetCancelledTotalByDateRange(ctx context.Context, startDate, endDate time.Time) (float64, error)
 	GetAdditionalChargesSummary(ctx context.Context, startDate, endDate time.Time) (total float64, breakdowns []AdditionalChargeBreakdown, err error)
+	GetPromotionSummary(ctx context.Context, startDate, endDate time.Time) (total float64, summaries []PromotionSummary, err error)
 	GetProductsSold(ctx context.Context, startDate, endDate time.Time) (int64, error)
 	GetRevenueTimeSeries(ctx context.Context, startDate, endDate time.Time, period string) ([]TimeSeriesData, error)
 	ListOrders(ctx context.Context, limit, offset int64) ([]db.Order, int64, error)
 	ListOrdersByCustomer(ctx context.Context, customerID string, startDate, endDate time.Time) ([]db.Order, error)
-	SplitBillPayment(ctx context.Context, orderID string, amount float64, paymentMethod string, note string, createdBy string, items []SplitBillItem) error
-	MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string) (string, error)
+	SplitBillPayment(ctx context.Context, input SplitPayment) (*SplitPaymentResult, error)
+	MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string, mergedBy string) (string, error)
+	TransferOrder(ctx context.Context, orderID string, targetTableNumber string, movedBy string) (*TableChange, error)
+	MoveOrderItems(ctx context.Context, sourceOrderID, targetOrderID string, items []MoveItemInput, movedBy string) (*TableChange, error)
+	UnmergeOrder(ctx context.Context, mergedOrderID string, movedBy string) (*UnmergeResult, error)
+	FireOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]CourseItem, error)
+	HoldOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]CourseItem, error)
 	GetOrderPayments(ctx context.Context, orderID string) ([]db.Payment, error)
-	VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string) error
+	VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string, shiftID string) error
+	RefundOrder(ctx context.Context, input RefundInput) (string, error)
+	GetOrderRefunds(ctx context.Context, orderID string) ([]Refund, error)
+	GetRefundSummary(ctx context.Context, startDate, endDate time.Time) (total float64, count int64, summaries []RefundSummary, err error)
 	ListVoidedOrders(ctx context.Context, limit, offset int64) ([]VoidedOrderHistory, error)
 	CountVoidedOrders(ctx context.Context) (int64, error)
 	ListVoidedOrdersByDateRange(ctx context.Context, startDate, endDate time.Time, limit, offset int64) ([]VoidedOrderHistory, error)
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,6 +21,8 @@ type PrintPayload struct {
 	OrderID       string      `json:"order_id"`
 	ReceiptNumber string      `json:"receipt_number"`
 	TableNumber   string      `json:"table_number"`
+	OrderType     string      `json:"order_type,omitempty"`
+	QueueLabel    string      `json:"queue_label,omitempty"`
 	CustomerName  string      `json:"customer_name"`
 	WaiterName    string      `json:"waiter_name"`
 	CashierName   string      `json:"cashier_name"`
@@ -31,6 +34,18 @@ type PrintPayload struct {
 	PaidAmount    int         `json:"paid_amount"`
 	ChangeAmount  int         `json:"change_amount"`
 	DateTime      time.Time   `json:"datetime"`
+	// Slip pindah meja/item untuk dapur
+	IsTableChange   bool   `json:"is_table_change,omitempty"`
+	TableChangeType string `json:"table_change_type,omitempty"`
+	FromOrderID     string `json:"from_order_id,omitempty"`
+	FromTableNumber string `json:"from_table_number,omitempty"`
+	MovedBy         string `json:"moved_by,omitempty"`
+	// Tiket course: FIRE/HOLD dan course yang masih ditahan
+	KitchenMarker string   `json:"kitchen_marker,omitempty"`
+	HeldCourses   []string `json:"held_courses,omitempty"`
+	// Catatan alergi/diet pelanggan yang terhubung ke order, dicetak di tiket dapur
+	CustomerAllergies    string `json:"customer_allergies,omitempty"`
+	CustomerDietaryNotes string `json:"customer_dietary_notes,omitempty"`
 }
 
 // PrintItemWithInfo represents an item in print payload with full details.
@@ -39,6 +54,7 @@ type PrintItem struct {
 	Quantity int    `json:"quantity"`
 	Price    int    `json:"price"`
 	Total    int    `json:"total"`
+	Course   string `json:"course,omitempty"`
 }
 
 func parseNumeric(value interface{}) (float64, error) {
@@ -119,15 +135,20 @@ func NewOrderRepository(dbConn *sql.DB) OrderRepository {
 	return &orderRepository{db: dbConn}
 }
 
-func (r *orderRepository) recalculateOrderTotals(ctx context.Context, q *db.Queries, tx *sql.Tx, orderID string) (float64, float64, error) {
+// recalculateOrderTotals menghitung ulang baris biaya otomatis, promosi, pajak, dan total order.
+// Promosi dievaluasi lebih dulu sehingga biaya persentase dihitung dari subtotal setelah diskon,
+// lalu pajak dihitung dari subtotal setelah diskon (dan service charge sesuai tarif).
+func recalculateOrderTotals(ctx context.Context, q *db.Queries, tx *sql.Tx, orderID string) (float64, float64, error) {
 	items, err := q.GetOrderItems(ctx, orderID)
 	if err != nil {
 		return 0, 0, err
 	}
 
 	subtotal := 0.0
+	itemQty := int64(0)
 	for _, item := range items {
 		subtotal += item.Price * float64(item.Qty)
+		itemQty += item.Qty
 	}
 
 	basketSize := int64(len(items))
@@ -135,13 +156,21 @@ func (r *orderRepository) recalculateOrderTotals(ctx context.Context, q *db.Quer
 	_, err = tx.ExecContext(ctx, `
 		DELETE FROM order_additional_charges
 		WHERE order_id = ?
-
-		AND charge_id IS NOT NULL
+		  AND (charge_id IS NOT NULL OR promotion_id IS NOT NULL OR is_packaging = 1)
 	`, orderID)
 	if err != nil {
 		return 0, 0, err
 	}
 
+	promotionTotal, err := applyPromotions(ctx, tx, orderID, subtotal)
+	if err != nil {
+		return 0, 0, err
+	}
+	chargeBase := subtotal + promotionTotal
+	if chargeBase < 0 {
+		chargeBase = 0
+	}
+
 	rows, err := tx.QueryContext(ctx, `
 		SELECT id, name, charge_type, value
 		FROM additional_charges
@@ -172,17 +201,46 @@ func (r *orderRepository) recalculateOrderTotals(ctx context.Context, q *db.Quer
 		return 0, 0, err
 	}
 
+	// Aturan tipe order (mis. takeaway bebas service charge) lalu aturan area meja
+	// (mis. outdoor bebas service charge, VIP tarif khusus)
+	typeCharges, err := loadOrderTypeCharges(ctx, tx, orderID, itemQty)
+	if err != nil {
+		return 0, 0, err
+	}
+	areaRules, err := loadOrderAreaChargeRules(ctx, tx, orderID)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	chargesTotal := 0.0
 	for _, charge := range charges {
 		chargeID := charge.id
 		name := charge.name
 		chargeType := charge.chargeType
 		value := charge.value
+		exempt := false
+		for _, rules := range []map[int64]AreaChargeRule{typeCharges.rules, areaRules} {
+			rule, ok := rules[chargeID]
+			if !ok {
+				continue
+			}
+			if rule.IsExempt {
+				exempt = true
+				break
+			}
+			if rule.ChargeType != nil && rule.Value != nil {
+				chargeType = *rule.ChargeType
+				value = *rule.Value
+			}
+		}
+		if exempt {
+			continue
+		}
 
 		applied := 0.0
 		if subtotal > 0 {
 			if chargeType == "percentage" {
-				applied = subtotal * value / 100
+				applied = chargeBase * value / 100
 			} else {
 				applied = value
 			}
@@ -211,12 +269,39 @@ func (r *orderRepository) recalculateOrderTotals(ctx context.Context, q *db.Quer
 		chargesTotal += applied
 	}
 
+	// Biaya kemasan order tanpa meja, per item atau per order sesuai pengaturan tipe order
+	if typeCharges.packagingFee > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO order_additional_charges (
+				order_id,
+				name,
+				charge_type,
+				value,
+				applied_amount,
+				is_packaging,
+				created_at,
+				updated_at
+			) VALUES (?, ?, 'fixed', ?, ?, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, orderID, "Biaya Kemasan", typeCharges.packagingFee, typeCharges.packagingFee)
+		if err != nil {
+			return 0, 0, err
+		}
+		chargesTotal += typeCharges.packagingFee
+	}
+
+	taxTotal, err := applyTaxes(ctx, tx, orderID, subtotal, chargeBase, chargesTotal)
+	if err != nil {
+		return 0, 0, err
+	}
+
 	manualTotal := 0.0
 	manualRows, err := tx.QueryContext(ctx, `
 		SELECT id, charge_type, value, applied_amount
 		FROM order_additional_charges
 		WHERE order_id = ?
 		  AND charge_id IS NULL
+		  AND promotion_id IS NULL
+		  AND is_packaging = 0
 	`, orderID)
 	if err != nil {
 		return 0, 0, err
@@ -269,7 +354,7 @@ func (r *orderRepository) recalculateOrderTotals(ctx context.Context, q *db.Quer
 		return 0, 0, err
 	}
 
-	totalAmount := subtotal + chargesTotal + manualTotal
+	totalAmount := subtotal + promotionTotal + chargesTotal + taxTotal + manualTotal
 	if totalAmount < 0 {
 		totalAmount = 0
 	}
@@ -285,328 +370,721 @@ func (r *orderRepository) recalculateOrderTotals(ctx context.Context, q *db.Quer
 	return subtotal, chargesTotal, nil
 }
 
-// execTx runs fn within a database transaction, rolling back on error.
-func (r *orderRepository) execTx(ctx context.Context, fn func(*db.Queries, *sql.Tx) error) error {
-	tx, err := r.db.BeginTx(ctx, nil)
+// setOrderItemProduct menyimpan product_id item order (kolom tambahan di luar query sqlc)
+func setOrderItemProduct(ctx context.Context, tx *sql.Tx, itemID, productID string) error {
+	_, err := tx.ExecContext(ctx, "UPDATE order_items SET product_id = ? WHERE id = ?", productID, itemID)
 	if err != nil {
-		return err
+		return fmt.Errorf("gagal menyimpan produk item order: %w", err)
 	}
+	return nil
+}
 
-	q := db.New(tx)
-	if err := fn(q, tx); err != nil {
-		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("kesalahan transaksi: %v, kesalahan rollback: %v", err, rbErr)
-		}
-		return err
+// courseSequence menentukan urutan course pada tiket dapur
+var courseSequence = map[string]int{
+	CourseStarter: 1,
+	CourseMain:    2,
+	CourseDessert: 3,
+}
+
+// normalizeCourse memvalidasi course item; kosong berarti item tanpa course
+func normalizeCourse(course string) (string, error) {
+	course = strings.ToLower(strings.TrimSpace(course))
+	if course == "" {
+		return "", nil
 	}
+	if _, ok := courseSequence[course]; !ok {
+		return "", fmt.Errorf("%w: %s (starter, main, dessert)", ErrInvalidCourse, course)
+	}
+	return course, nil
+}
 
-	return tx.Commit()
+// setOrderItemCourse menyimpan course dan status tahan item order (kolom tambahan di luar query sqlc)
+func setOrderItemCourse(ctx context.Context, tx *sql.Tx, itemID, course string, held bool) error {
+	if course == "" && !held {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, "UPDATE order_items SET course = ?, is_held = ? WHERE id = ?", nullableID(course), held, itemID)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan course item order: %w", err)
+	}
+	return nil
 }
 
-func (r *orderRepository) CreateOrderWithItems(ctx context.Context, input OrderInput) (string, error) {
-	var orderID string
+// LoadOrderItemCourses mengambil course dan status hold/fire seluruh item order
+func LoadOrderItemCourses(ctx context.Context, q db.DBTX, orderID string) (map[string]ItemCourse, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, COALESCE(course, ''), is_held, fired_at
+		FROM order_items
+		WHERE order_id = ?
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
-		generatedID, err := r.generateOrderID(ctx, tx, input.TableNumber)
-		if err != nil {
-			return fmt.Errorf("gagal membuat nomor pesanan: %w", err)
+	courses := make(map[string]ItemCourse)
+	for rows.Next() {
+		var itemID string
+		var course ItemCourse
+		var firedAt sql.NullTime
+		if err := rows.Scan(&itemID, &course.Course, &course.IsHeld, &firedAt); err != nil {
+			return nil, err
 		}
-		orderID = generatedID
-
-		// Fetch product details and group by printer
-		subtotal := 0.0
-		type ItemWithDetails struct {
-			ProductName string
-			Price       float64
-			Qty         int64
-			PrinterID   string
-			Destination string
-		}
-		itemsWithDetails := make([]ItemWithDetails, 0, len(input.Items))
-		itemsByPrinter := make(map[string][]ItemWithDetails)
-
-		for _, item := range input.Items {
-			// Get product from database
-			product, err := q.GetProduct(ctx, item.ProductID)
-			if err != nil {
-				return fmt.Errorf("product %s tidak ditemukan: %w", item.ProductID, err)
-			}
+		if firedAt.Valid {
+			course.FiredAt = &firedAt.Time
+		}
+		courses[itemID] = course
+	}
+	return courses, rows.Err()
+}
 
-			// Get printer from category (if exists)
-			var printerID string
-			var destination string = "kitchen" // default untuk order_items table
+// orderItemPrinterID mengambil printer dapur/bar item dari kategori produknya
+func orderItemPrinterID(ctx context.Context, q db.DBTX, itemID string) (string, error) {
+	var printerID sql.NullString
+	err := q.QueryRowContext(ctx, `
+		SELECT c.printer_id
+		FROM order_items oi
+		JOIN products p ON p.id = oi.product_id
+		JOIN categories c ON c.id = p.category_id
+		WHERE oi.id = ?
+	`, itemID).Scan(&printerID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return printerID.String, nil
+}
 
-			if product.CategoryID.Valid {
-				category, err := q.GetCategory(ctx, product.CategoryID.String)
-				if err == nil && category.PrinterID.Valid {
-					printerID = category.PrinterID.String
+// kitchenTicketItem adalah item yang dikirim ke printer dapur/bar
+type kitchenTicketItem struct {
+	ItemID      string
+	ProductName string
+	Price       float64
+	Qty         int64
+	Course      string
+	PrinterID   string
+}
 
-					// Check printer type for destination field in order_items
-					printer, err := q.GetPrinter(ctx, printerID)
-					if err == nil {
-						destination = printer.PrinterType
-					}
-				}
-			}
+// queueKitchenTickets membuat print job tiket dapur/bar per printer. Marker FIRE/HOLD dicetak
+// di tiket untuk course yang di-fire atau ditahan; course yang masih ditahan ikut dicantumkan.
+func queueKitchenTickets(ctx context.Context, q *db.Queries, tx *sql.Tx, orderID, marker string, items []kitchenTicketItem) error {
+	if len(items) == 0 {
+		return nil
+	}
 
-			// Calculate subtotal
-			subtotal += product.Price * float64(item.Qty)
-			itemDetail := ItemWithDetails{
-				ProductName: product.Name,
-				Price:       product.Price,
-				Qty:         item.Qty,
-				PrinterID:   printerID,
-				Destination: destination,
-			}
-			itemsWithDetails = append(itemsWithDetails, itemDetail)
+	order, err := q.GetOrderWithItems(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil order untuk tiket dapur: %w", err)
+	}
+	channel, err := GetOrderChannel(ctx, tx, orderID)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil tipe order untuk tiket dapur: %w", err)
+	}
+	waiterName := ""
+	if order.CreatedBy.Valid && order.CreatedBy.String != "" {
+		if user, err := q.GetUserByID(ctx, order.CreatedBy.String); err == nil {
+			waiterName = user.FullName
+		}
+	}
 
-			// Group by printer for print jobs
-			if printerID != "" {
-				itemsByPrinter[printerID] = append(itemsByPrinter[printerID], itemDetail)
-			}
+	heldCourses := make(map[string][]string)
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT COALESCE(c.printer_id, ''), COALESCE(oi.course, '')
+		FROM order_items oi
+		LEFT JOIN products p ON p.id = oi.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE oi.order_id = ? AND oi.is_held = 1
+	`, orderID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var printerID, course string
+		if err := rows.Scan(&printerID, &course); err != nil {
+			rows.Close()
+			return err
+		}
+		heldCourses[printerID] = append(heldCourses[printerID], course)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
 
+	sort.SliceStable(items, func(i, j int) bool {
+		return courseSequence[items[i].Course] < courseSequence[items[j].Course]
+	})
+	itemsByPrinter := make(map[string][]PrintItem)
+	printerIDs := []string{}
+	for _, item := range items {
+		if item.PrinterID == "" {
+			continue
 		}
+		if _, ok := itemsByPrinter[item.PrinterID]; !ok {
+			printerIDs = append(printerIDs, item.PrinterID)
+		}
+		price := int(math.Round(item.Price))
+		itemsByPrinter[item.PrinterID] = append(itemsByPrinter[item.PrinterID], PrintItem{
+			Name:     item.ProductName,
+			Quantity: int(item.Qty),
+			Price:    price,
+			Total:    price * int(item.Qty),
+			Course:   item.Course,
+		})
+	}
 
-		// Calculate basket size
-		basketSize := int64(len(itemsWithDetails))
+	customerName := ""
+	if order.CustomerName.Valid {
+		customerName = order.CustomerName.String
+	}
+	allergies, dietaryNotes, err := GetCustomerKitchenNotes(ctx, tx, order.CustomerID.String)
+	if err != nil {
+		return fmt.Errorf("gagal mengambil catatan alergi pelanggan: %w", err)
+	}
+	now := time.Now()
+	for _, printerID := range printerIDs {
+		printItems := itemsByPrinter[printerID]
+		printerTotal := 0
+		for _, item := range printItems {
+			printerTotal += item.Total
+		}
+		courses := heldCourses[printerID]
+		sort.Slice(courses, func(i, j int) bool {
+			return courseSequence[courses[i]] < courseSequence[courses[j]]
+		})
 
-		// Create order
-		_, err = q.CreateOrder(ctx, db.CreateOrderParams{
-			ID:            orderID,
-			TableNumber:   input.TableNumber,
-			CustomerName:  sql.NullString{String: input.CustomerName, Valid: input.CustomerName != ""},
-			CustomerPhone: sql.NullString{String: input.CustomerPhone, Valid: input.CustomerPhone != ""},
-			CustomerID:    sql.NullString{String: input.CustomerID, Valid: input.CustomerID != ""},
-			Pax:           input.Pax,
-			BasketSize:    basketSize,
-			TotalAmount:   subtotal,
-			CreatedBy:     sql.NullString{String: input.CreatedBy, Valid: input.CreatedBy != ""},
+		payloadJSON, err := json.Marshal(PrintPayload{
+			OrderID:       orderID,
+			ReceiptNumber: orderID,
+			TableNumber:   order.TableNumber,
+			OrderType:     channel.OrderType,
+			QueueLabel:    channel.QueueLabel,
+			CustomerName:  customerName,
+			WaiterName:    waiterName,
+			Items:         printItems,
+			Subtotal:      printerTotal,
+			Total:         printerTotal,
+			DateTime:      now,
+			KitchenMarker: marker,
+			HeldCourses:   courses,
+
+			CustomerAllergies:    allergies,
+			CustomerDietaryNotes: dietaryNotes,
 		})
 		if err != nil {
-			return fmt.Errorf("gagal membuat order: %w", err)
+			return fmt.Errorf("gagal marshal payload print: %w", err)
 		}
 
-		for _, item := range itemsWithDetails {
-			itemID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
-			_, err = q.CreateOrderItem(ctx, db.CreateOrderItemParams{
-				ID:          itemID,
-				OrderID:     orderID,
-				ProductName: item.ProductName,
-				Qty:         item.Qty,
-				Price:       item.Price,
-				Destination: item.Destination,
-			})
-			if err != nil {
-				return fmt.Errorf("gagal membuat item order: %w", err)
-			}
+		_, err = q.CreatePrintJob(ctx, db.CreatePrintJobParams{
+			ID:        ulid.MustNew(ulid.Timestamp(now), rand.Reader).String(),
+			PrinterID: printerID,
+			Data:      string(payloadJSON),
+		})
+		if err != nil {
+			return fmt.Errorf("gagal membuat print job: %w", err)
 		}
+	}
+	return nil
+}
 
-		// Create print jobs grouped by printer
-		now := time.Now()
-		waiterName := ""
-		if input.CreatedBy != "" {
-			user, err := q.GetUserByID(ctx, input.CreatedBy)
-			if err == nil {
-				waiterName = user.FullName
-			}
+// checkCourseOrder memastikan order masih berjalan sebelum course di-fire atau ditahan
+func checkCourseOrder(ctx context.Context, q db.DBTX, orderID string) error {
+	var paymentStatus string
+	var voidedAt sql.NullTime
+	err := q.QueryRowContext(ctx, `
+		SELECT payment_status, voided_at FROM orders WHERE id = ?
+	`, orderID).Scan(&paymentStatus, &voidedAt)
+	if err != nil {
+		return err
+	}
+	if voidedAt.Valid {
+		return ErrOrderVoided
+	}
+	if paymentStatus == "paid" {
+		return ErrOrderAlreadyPaid
+	}
+	return nil
+}
+
+// loadCourseItems mengambil item order per course atau per item: item yang ditahan (untuk fire)
+// atau item pending yang belum ditahan (untuk hold)
+func loadCourseItems(ctx context.Context, q db.DBTX, orderID, course string, itemIDs []string, held bool, notFound error) ([]kitchenTicketItem, error) {
+	query := `
+		SELECT oi.id, oi.product_name, oi.price, oi.qty, COALESCE(oi.course, ''), COALESCE(c.printer_id, '')
+		FROM order_items oi
+		LEFT JOIN products p ON p.id = oi.product_id
+		LEFT JOIN categories c ON c.id = p.category_id
+		WHERE oi.order_id = ? AND oi.is_held = ?`
+	args := []interface{}{orderID, held}
+	if !held {
+		query += " AND oi.item_status = 'pending'"
+	}
+	if course != "" {
+		query += " AND oi.course = ?"
+		args = append(args, course)
+	}
+	if len(itemIDs) > 0 {
+		query += " AND oi.id IN (" + strings.TrimSuffix(strings.Repeat("?,", len(itemIDs)), ",") + ")"
+		for _, itemID := range itemIDs {
+			args = append(args, itemID)
 		}
+	}
+	query += " ORDER BY oi.created_at"
 
-		for printerID, items := range itemsByPrinter {
-			printItems := make([]PrintItem, len(items))
-			var printerTotal int
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-			for i, item := range items {
-				price := int(math.Round(item.Price))
-				total := price * int(item.Qty)
-				printItems[i] = PrintItem{
-					Name:     item.ProductName,
-					Quantity: int(item.Qty),
-					Price:    price,
-					Total:    total,
-				}
-				printerTotal += total
-			}
+	var items []kitchenTicketItem
+	for rows.Next() {
+		var item kitchenTicketItem
+		if err := rows.Scan(&item.ItemID, &item.ProductName, &item.Price, &item.Qty, &item.Course, &item.PrinterID); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, notFound
+	}
+	if len(itemIDs) > 0 && len(items) != len(itemIDs) {
+		return nil, fmt.Errorf("%w: sebagian item tidak ditemukan atau sudah diproses", notFound)
+	}
+	return items, nil
+}
 
-			// Build JSON payload for this printer
-			payload := PrintPayload{
-				OrderID:       orderID,
-				ReceiptNumber: orderID,
-				TableNumber:   input.TableNumber,
-				CustomerName:  input.CustomerName,
-				WaiterName:    waiterName,
-				Items:         printItems,
-				Subtotal:      printerTotal,
-				Total:         printerTotal,
-				DateTime:      now,
-			}
+func toCourseItems(items []kitchenTicketItem) []CourseItem {
+	result := make([]CourseItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, CourseItem{
+			ItemID:      item.ItemID,
+			ProductName: item.ProductName,
+			Qty:         item.Qty,
+			Course:      item.Course,
+		})
+	}
+	return result
+}
 
-			payloadJSON, err := json.Marshal(payload)
-			if err != nil {
-				return fmt.Errorf("gagal marshal payload print: %w", err)
-			}
+// FireOrderItems melepas item yang ditahan (per course atau per item) dan mencetak tiket FIRE ke dapur/bar
+func (r *orderRepository) FireOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]CourseItem, error) {
+	course, err := normalizeCourse(course)
+	if err != nil {
+		return nil, err
+	}
+	if course == "" && len(itemIDs) == 0 {
+		return nil, fmt.Errorf("%w: pilih course atau item", ErrInvalidCourse)
+	}
 
-			// Generate ULID for print job
-			printJobID := ulid.MustNew(ulid.Timestamp(now), rand.Reader).String()
+	var fired []CourseItem
+	err = r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		if err := checkCourseOrder(ctx, tx, orderID); err != nil {
+			return err
+		}
+		items, err := loadCourseItems(ctx, tx, orderID, course, itemIDs, true, ErrNoHeldItems)
+		if err != nil {
+			return err
+		}
 
-			_, err = q.CreatePrintJob(ctx, db.CreatePrintJobParams{
-				ID:        printJobID,
-				PrinterID: printerID,
-				Data:      string(payloadJSON),
-			})
-			if err != nil {
-				return fmt.Errorf("gagal membuat print job: %w", err)
+		now := time.Now().UTC()
+		for _, item := range items {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE order_items
+				SET is_held = 0, fired_at = ?, updated_at = CURRENT_TIMESTAMP
+				WHERE id = ?
+			`, now, item.ItemID); err != nil {
+				return fmt.Errorf("gagal fire item: %w", err)
 			}
 		}
+		fired = toCourseItems(items)
+		return queueKitchenTickets(ctx, q, tx, orderID, KitchenMarkerFire, items)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fired, nil
+}
+
+// HoldOrderItems menahan item pending (per course atau per item). Item yang sudah terkirim
+// ke dapur dicetakkan tiket HOLD agar belum dimasak.
+func (r *orderRepository) HoldOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]CourseItem, error) {
+	course, err := normalizeCourse(course)
+	if err != nil {
+		return nil, err
+	}
+	if course == "" && len(itemIDs) == 0 {
+		return nil, fmt.Errorf("%w: pilih course atau item", ErrInvalidCourse)
+	}
 
-		_, _, err = r.recalculateOrderTotals(ctx, q, tx, orderID)
+	var held []CourseItem
+	err = r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		if err := checkCourseOrder(ctx, tx, orderID); err != nil {
+			return err
+		}
+		items, err := loadCourseItems(ctx, tx, orderID, course, itemIDs, false, ErrNoHoldableItems)
 		if err != nil {
 			return err
 		}
 
-		return nil
+		for _, item := range items {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE order_items
+				SET is_held = 1, fired_at = NULL, updated_at = CURRENT_TIMESTAMP
+				WHERE id = ?
+			`, item.ItemID); err != nil {
+				return fmt.Errorf("gagal menahan item: %w", err)
+			}
+		}
+		held = toCourseItems(items)
+		return queueKitchenTickets(ctx, q, tx, orderID, KitchenMarkerHold, items)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return held, nil
+}
+
+// execTx runs fn within a database transaction, rolling back on error.
+func (r *orderRepository) execTx(ctx context.Context, fn func(*db.Queries, *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	q := db.New(tx)
+	if err := fn(q, tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("kesalahan transaksi: %v, kesalahan rollback: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
 
+func (r *orderRepository) CreateOrderWithItems(ctx context.Context, input OrderInput) (string, error) {
+	var orderID string
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		var err error
+		orderID, err = r.createOrderWithItemsTx(ctx, q, tx, input)
+		return err
+	})
 	return orderID, err
 }
 
-func (r *orderRepository) AddItemsToOrder(ctx context.Context, orderID string, items []OrderItemInput) error {
-	return r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
-		order, err := q.GetOrderWithItems(ctx, orderID)
+// createOrderWithItemsTx membuat order beserta item dan antrian cetak di dalam transaksi pemanggil
+func (r *orderRepository) createOrderWithItemsTx(ctx context.Context, q *db.Queries, tx *sql.Tx, input OrderInput) (string, error) {
+	var orderID string
+
+	channel, err := prepareOrderChannel(ctx, tx, &input)
+	if err != nil {
+		return "", err
+	}
+
+	generatedID, err := r.generateOrderID(ctx, tx, input.TableNumber)
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat nomor pesanan: %w", err)
+	}
+	orderID = generatedID
+
+	// Fetch product details and group by printer
+	subtotal := 0.0
+	type ItemWithDetails struct {
+		ProductID   string
+		ProductName string
+		Price       float64
+		Qty         int64
+		PrinterID   string
+		Destination string
+		Course      string
+		Hold        bool
+	}
+	itemsWithDetails := make([]ItemWithDetails, 0, len(input.Items))
+	orderTime := time.Now()
+
+	for _, item := range input.Items {
+		course, err := normalizeCourse(item.Course)
 		if err != nil {
-			return err
+			return "", err
 		}
 
-		if order.PaymentStatus == "paid" {
-			return fmt.Errorf("order sudah dibayar")
+		// Get product from database
+		product, err := q.GetProduct(ctx, item.ProductID)
+		if err != nil {
+			return "", fmt.Errorf("product %s tidak ditemukan: %w", item.ProductID, err)
 		}
 
-		var totalAmount float64
-		type ItemWithDetails struct {
-			ProductName string
-			Price       float64
-			Qty         int64
-			PrinterID   string
-			Destination string
+		// Harga mengikuti price list yang berlaku saat order dibuat
+		scheduled, err := resolveScheduledPrice(ctx, tx, product.ID, product.Price, orderTime)
+		if err != nil {
+			return "", fmt.Errorf("gagal menentukan harga %s: %w", product.Name, err)
 		}
-		itemsWithDetails := make([]ItemWithDetails, 0, len(items))
-		itemsByPrinter := make(map[string][]ItemWithDetails)
+		product.Price = scheduled.Price
 
-		for _, item := range items {
-			product, err := q.GetProduct(ctx, item.ProductID)
-			if err != nil {
-				return fmt.Errorf("product %s tidak ditemukan: %w", item.ProductID, err)
-			}
+		// Get printer from category (if exists)
+		var printerID string
+		var destination string = "kitchen" // default untuk order_items table
 
-			var printerID string
-			destination := "kitchen"
+		if product.CategoryID.Valid {
+			category, err := q.GetCategory(ctx, product.CategoryID.String)
+			if err == nil && category.PrinterID.Valid {
+				printerID = category.PrinterID.String
 
-			if product.CategoryID.Valid {
-				category, err := q.GetCategory(ctx, product.CategoryID.String)
-				if err == nil && category.PrinterID.Valid {
-					printerID = category.PrinterID.String
-					printer, err := q.GetPrinter(ctx, printerID)
-					if err == nil {
-						destination = printer.PrinterType
-					}
+				// Check printer type for destination field in order_items
+				printer, err := q.GetPrinter(ctx, printerID)
+				if err == nil {
+					destination = printer.PrinterType
 				}
 			}
+		}
 
-			itemTotal := product.Price * float64(item.Qty)
-			totalAmount += itemTotal
-
-			itemDetail := ItemWithDetails{
-				ProductName: product.Name,
-				Price:       product.Price,
-				Qty:         item.Qty,
-				PrinterID:   printerID,
-				Destination: destination,
-			}
-			itemsWithDetails = append(itemsWithDetails, itemDetail)
+		// Calculate subtotal
+		subtotal += product.Price * float64(item.Qty)
+		itemsWithDetails = append(itemsWithDetails, ItemWithDetails{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Price:       product.Price,
+			Qty:         item.Qty,
+			PrinterID:   printerID,
+			Destination: destination,
+			Course:      course,
+			Hold:        item.Hold,
+		})
+	}
 
-			if printerID != "" {
-				itemsByPrinter[printerID] = append(itemsByPrinter[printerID], itemDetail)
-			}
+	// Calculate basket size
+	basketSize := int64(len(itemsWithDetails))
+
+	// Create order
+	_, err = q.CreateOrder(ctx, db.CreateOrderParams{
+		ID:            orderID,
+		TableNumber:   input.TableNumber,
+		CustomerName:  sql.NullString{String: input.CustomerName, Valid: input.CustomerName != ""},
+		CustomerPhone: sql.NullString{String: input.CustomerPhone, Valid: input.CustomerPhone != ""},
+		CustomerID:    sql.NullString{String: input.CustomerID, Valid: input.CustomerID != ""},
+		Pax:           input.Pax,
+		BasketSize:    basketSize,
+		TotalAmount:   subtotal,
+		CreatedBy:     sql.NullString{String: input.CreatedBy, Valid: input.CreatedBy != ""},
+	})
+	if err != nil {
+		return "", fmt.Errorf("gagal membuat order: %w", err)
+	}
+	if err := saveOrderChannel(ctx, tx, orderID, channel); err != nil {
+		return "", fmt.Errorf("gagal menyimpan tipe order: %w", err)
+	}
+
+	// Item yang ditahan baru dicetak saat course-nya di-fire
+	ticketItems := make([]kitchenTicketItem, 0, len(itemsWithDetails))
+	for _, item := range itemsWithDetails {
+		itemID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+		_, err = q.CreateOrderItem(ctx, db.CreateOrderItemParams{
+			ID:          itemID,
+			OrderID:     orderID,
+			ProductName: item.ProductName,
+			Qty:         item.Qty,
+			Price:       item.Price,
+			Destination: item.Destination,
+		})
+		if err != nil {
+			return "", fmt.Errorf("gagal membuat item order: %w", err)
 		}
-
-		for _, item := range itemsWithDetails {
-			itemID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
-			_, err = q.CreateOrderItem(ctx, db.CreateOrderItemParams{
-				ID:          itemID,
-				OrderID:     orderID,
+		if err := setOrderItemProduct(ctx, tx, itemID, item.ProductID); err != nil {
+			return "", err
+		}
+		if err := setOrderItemCourse(ctx, tx, itemID, item.Course, item.Hold); err != nil {
+			return "", err
+		}
+		if item.PrinterID != "" && !item.Hold {
+			ticketItems = append(ticketItems, kitchenTicketItem{
+				ItemID:      itemID,
 				ProductName: item.ProductName,
-				Qty:         item.Qty,
 				Price:       item.Price,
-				Destination: item.Destination,
+				Qty:         item.Qty,
+				Course:      item.Course,
+				PrinterID:   item.PrinterID,
 			})
-			if err != nil {
-				return fmt.Errorf("gagal membuat item order: %w", err)
-			}
 		}
+	}
+
+	// Create print jobs grouped by printer
+	if err := queueKitchenTickets(ctx, q, tx, orderID, "", ticketItems); err != nil {
+		return "", err
+	}
 
-		customerName := ""
-		if order.CustomerName.Valid {
-			customerName = order.CustomerName.String
+	_, _, err = recalculateOrderTotals(ctx, q, tx, orderID)
+	if err != nil {
+		return "", err
+	}
+
+	// Order dine-in pertama membuka sesi meja (jika tamu belum ditandai duduk)
+	if channel.OrderType == OrderTypeDineIn {
+		if err := RecordTableSessionEvent(ctx, tx, input.TableNumber, TableSessionFirstOrder, orderID); err != nil {
+			return "", fmt.Errorf("gagal mencatat sesi meja: %w", err)
 		}
-		waiterName := ""
-		if order.CreatedBy.Valid && order.CreatedBy.String != "" {
-			user, err := q.GetUserByID(ctx, order.CreatedBy.String)
-			if err == nil {
-				waiterName = user.FullName
-			}
+		if _, err := advanceTableStatus(ctx, tx, input.TableNumber, TableStatusOrdered); err != nil {
+			return "", fmt.Errorf("gagal update status meja: %w", err)
 		}
+	}
 
-		now := time.Now()
-		for printerID, items := range itemsByPrinter {
-			printItems := make([]PrintItem, len(items))
-			var printerTotal int
+	return orderID, nil
+}
 
-			for i, item := range items {
-				price := int(math.Round(item.Price))
-				total := price * int(item.Qty)
-				printItems[i] = PrintItem{
-					Name:     item.ProductName,
-					Quantity: int(item.Qty),
-					Price:    price,
-					Total:    total,
-				}
-				printerTotal += total
-			}
+func (r *orderRepository) AddItemsToOrder(ctx context.Context, orderID string, items []OrderItemInput) error {
+	return r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		return addItemsToOrderTx(ctx, q, tx, orderID, items)
+	})
+}
 
-			payload := PrintPayload{
-				OrderID:       orderID,
-				ReceiptNumber: orderID,
-				TableNumber:   order.TableNumber,
-				CustomerName:  customerName,
-				WaiterName:    waiterName,
-				Items:         printItems,
-				Subtotal:      printerTotal,
-				Total:         printerTotal,
-				DateTime:      now,
-			}
+// AddItemsToTableOrder mencari order aktif dan membuat order baru di transaksi yang sama
+// sehingga dua pesanan bersamaan untuk satu meja tidak membuka dua order
+func (r *orderRepository) AddItemsToTableOrder(ctx context.Context, input OrderInput) (string, bool, error) {
+	var orderID string
+	created := false
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		active, err := q.GetActiveOrderByTable(ctx, input.TableNumber)
+		if err == sql.ErrNoRows {
+			orderID, err = r.createOrderWithItemsTx(ctx, q, tx, input)
+			created = err == nil
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		orderID = active.ID
+		return addItemsToOrderTx(ctx, q, tx, orderID, input.Items)
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return orderID, created, nil
+}
 
-			payloadJSON, err := json.Marshal(payload)
-			if err != nil {
-				return fmt.Errorf("gagal marshal payload print: %w", err)
-			}
+// addItemsToOrderTx menambahkan item ke order di dalam transaksi pemanggil
+func addItemsToOrderTx(ctx context.Context, q *db.Queries, tx *sql.Tx, orderID string, items []OrderItemInput) error {
+	order, err := q.GetOrderWithItems(ctx, orderID)
+	if err != nil {
+		return err
+	}
 
-			printJobID := ulid.MustNew(ulid.Timestamp(now), rand.Reader).String()
-			_, err = q.CreatePrintJob(ctx, db.CreatePrintJobParams{
-				ID:        printJobID,
-				PrinterID: printerID,
-				Data:      string(payloadJSON),
-			})
-			if err != nil {
-				return fmt.Errorf("gagal membuat print job: %w", err)
+	if order.PaymentStatus == "paid" {
+		return fmt.Errorf("order sudah dibayar")
+	}
+
+	var totalAmount float64
+	type ItemWithDetails struct {
+		ProductID   string
+		ProductName string
+		Price       float64
+		Qty         int64
+		PrinterID   string
+		Destination string
+		Course      string
+		Hold        bool
+	}
+	itemsWithDetails := make([]ItemWithDetails, 0, len(items))
+	orderTime := time.Now()
+
+	for _, item := range items {
+		course, err := normalizeCourse(item.Course)
+		if err != nil {
+			return err
+		}
+
+		product, err := q.GetProduct(ctx, item.ProductID)
+		if err != nil {
+			return fmt.Errorf("product %s tidak ditemukan: %w", item.ProductID, err)
+		}
+
+		scheduled, err := resolveScheduledPrice(ctx, tx, product.ID, product.Price, orderTime)
+		if err != nil {
+			return fmt.Errorf("gagal menentukan harga %s: %w", product.Name, err)
+		}
+		product.Price = scheduled.Price
+
+		var printerID string
+		destination := "kitchen"
+
+		if product.CategoryID.Valid {
+			category, err := q.GetCategory(ctx, product.CategoryID.String)
+			if err == nil && category.PrinterID.Valid {
+				printerID = category.PrinterID.String
+				printer, err := q.GetPrinter(ctx, printerID)
+				if err == nil {
+					destination = printer.PrinterType
+				}
 			}
 		}
 
-		_, _, err = r.recalculateOrderTotals(ctx, q, tx, orderID)
+		itemTotal := product.Price * float64(item.Qty)
+		totalAmount += itemTotal
+
+		itemsWithDetails = append(itemsWithDetails, ItemWithDetails{
+			ProductID:   product.ID,
+			ProductName: product.Name,
+			Price:       product.Price,
+			Qty:         item.Qty,
+			PrinterID:   printerID,
+			Destination: destination,
+			Course:      course,
+			Hold:        item.Hold,
+		})
+	}
+
+	// Item yang ditahan baru dicetak saat course-nya di-fire
+	ticketItems := make([]kitchenTicketItem, 0, len(itemsWithDetails))
+	for _, item := range itemsWithDetails {
+		itemID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+		_, err = q.CreateOrderItem(ctx, db.CreateOrderItemParams{
+			ID:          itemID,
+			OrderID:     orderID,
+			ProductName: item.ProductName,
+			Qty:         item.Qty,
+			Price:       item.Price,
+			Destination: item.Destination,
+		})
 		if err != nil {
+			return fmt.Errorf("gagal membuat item order: %w", err)
+		}
+		if err := setOrderItemProduct(ctx, tx, itemID, item.ProductID); err != nil {
+			return err
+		}
+		if err := setOrderItemCourse(ctx, tx, itemID, item.Course, item.Hold); err != nil {
 			return err
 		}
+		if item.PrinterID != "" && !item.Hold {
+			ticketItems = append(ticketItems, kitchenTicketItem{
+				ItemID:      itemID,
+				ProductName: item.ProductName,
+				Price:       item.Price,
+				Qty:         item.Qty,
+				Course:      item.Course,
+				PrinterID:   item.PrinterID,
+			})
+		}
+	}
 
-		return nil
-	})
+	if err := queueKitchenTickets(ctx, q, tx, orderID, "", ticketItems); err != nil {
+		return err
+	}
+
+	_, _, err = recalculateOrderTotals(ctx, q, tx, orderID)
+	if err != nil {
+		return err
+	}
+
+	// Tambahan item setelah bill diminta mengembalikan meja ke ordered
+	if _, _, err := AdvanceOrderTable(ctx, tx, orderID, TableStatusOrdered); err != nil {
+		return fmt.Errorf("gagal update status meja: %w", err)
+	}
+
+	return nil
 }
 
 func (r *orderRepository) GetPendingJobs(ctx context.Context) ([]db.PrintQueue, error) {
@@ -625,6 +1103,19 @@ func (r *orderRepository) UpdateOrderStatus(ctx context.Context, orderID string,
 }
 
 func (r *orderRepository) UpdateOrderItemStatus(ctx context.Context, itemID string, status string) error {
+	// Item yang ditahan tetap pending sampai course-nya di-fire
+	var held bool
+	err := r.db.QueryRowContext(ctx, "SELECT is_held FROM order_items WHERE id = ?", itemID).Scan(&held)
+	if err == sql.ErrNoRows {
+		return ErrOrderItemNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if held && status != "pending" {
+		return ErrOrderItemHeld
+	}
+
 	return db.New(r.db).UpdateOrderItemStatus(ctx, db.UpdateOrderItemStatusParams{
 		ItemStatus: status,
 		ID:         itemID,
@@ -701,7 +1192,7 @@ func (r *orderRepository) UpdateOrderItemQty(ctx context.Context, itemID string,
 		}
 	}
 
-	if _, _, err := r.recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
+	if _, _, err := recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
 		_ = tx.Rollback()
 		return err
 	}
@@ -711,7 +1202,7 @@ func (r *orderRepository) UpdateOrderItemQty(ctx context.Context, itemID string,
 
 func (r *orderRepository) ProcessPayment(ctx context.Context, orderID string) error {
 	return r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
-		_, _, err := r.recalculateOrderTotals(ctx, q, tx, orderID)
+		_, _, err := recalculateOrderTotals(ctx, q, tx, orderID)
 		if err != nil {
 			return err
 		}
@@ -721,12 +1212,146 @@ func (r *orderRepository) ProcessPayment(ctx context.Context, orderID string) er
 			return err
 		}
 
-		return q.UpdateOrderPaidAmount(ctx, db.UpdateOrderPaidAmountParams{
+		if err := q.UpdateOrderPaidAmount(ctx, db.UpdateOrderPaidAmountParams{
 			PaidAmount:    order.TotalAmount,
 			PaymentStatus: "paid",
 			ID:            orderID,
+		}); err != nil {
+			return err
+		}
+		return RecordCustomerVisit(ctx, tx, orderID)
+	})
+}
+
+// SettleOrder melunasi sisa tagihan order dalam satu transaksi: deposit dipakai lebih dulu,
+// order ditandai lunas dan served, meja (termasuk meja gabungan) ditandai paid, lalu
+// transaksi metode kasir, tip dan potongan gift card dicatat. Jika satu langkah gagal
+// tidak ada yang tersimpan.
+func (r *orderRepository) SettleOrder(ctx context.Context, input OrderSettlement) (*OrderSettlementResult, error) {
+	result := &OrderSettlementResult{}
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		if _, _, err := recalculateOrderTotals(ctx, q, tx, input.OrderID); err != nil {
+			return err
+		}
+		order, err := q.GetOrderWithItems(ctx, input.OrderID)
+		if err != nil {
+			return err
+		}
+		if order.PaymentStatus == "paid" {
+			return ErrOrderAlreadyPaid
+		}
+		if input.PaymentIntentID != "" {
+			if err := settlePaymentIntent(ctx, tx, input.PaymentIntentID); err != nil {
+				return err
+			}
+		}
+
+		applied, err := ApplyOrderDeposits(ctx, tx, order.ID, order.TotalAmount-order.PaidAmount, input.CashierID, input.ShiftID)
+		if err != nil {
+			return fmt.Errorf("gagal memakai deposit: %w", err)
+		}
+		if math.Round(applied) != math.Round(input.DepositAmount) {
+			return ErrDepositChanged
+		}
+		result.DepositApplied = applied
+
+		if err := q.UpdateOrderPaidAmount(ctx, db.UpdateOrderPaidAmountParams{
+			PaidAmount:    order.TotalAmount,
+			PaymentStatus: "paid",
+			ID:            order.ID,
+		}); err != nil {
+			return err
+		}
+		if err := RecordCustomerVisit(ctx, tx, order.ID); err != nil {
+			return err
+		}
+		result.TableNumbers, err = serveAndSettleTables(ctx, q, tx, order.ID, order.TableNumber)
+		if err != nil {
+			return err
+		}
+
+		// Tagihan yang tertutup penuh oleh deposit tidak perlu transaksi metode kasir,
+		// kecuali ada tip yang dibayar lewat metode tersebut
+		if math.Round(input.Amount) <= 0 && input.Tip == nil {
+			return nil
+		}
+		transaction, err := insertTransaction(ctx, q, db.CreateTransactionParams{
+			OrderID:         order.ID,
+			TotalAmount:     input.Amount,
+			PaymentMethod:   input.PaymentMethod,
+			Status:          "completed",
+			TransactionDate: time.Now().UTC(),
+			CreatedBy:       input.CashierID,
+			ShiftID:         sql.NullString{String: input.ShiftID, Valid: input.ShiftID != ""},
 		})
+		if err != nil {
+			return fmt.Errorf("gagal mencatat transaksi: %w", err)
+		}
+		if input.ReferenceNumber != "" {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE transactions SET reference_number = ? WHERE id = ?
+			`, input.ReferenceNumber, transaction.ID); err != nil {
+				return fmt.Errorf("gagal menyimpan nomor referensi: %w", err)
+			}
+		}
+		tipAmount := 0.0
+		if input.Tip != nil {
+			input.Tip.TransactionID = transaction.ID
+			input.Tip.PaymentMethod = input.PaymentMethod
+			if err := CreatePaymentTip(ctx, tx, input.Tip); err != nil {
+				return err
+			}
+			tipAmount = input.Tip.Amount
+		}
+		if input.PaymentMethod == GiftCardPaymentMethod {
+			entry, err := redeemGiftCardTx(ctx, tx, input.GiftCardCode, input.Amount+tipAmount, order.ID, input.CashierID, input.ShiftID)
+			if err != nil {
+				return err
+			}
+			if err := LinkGiftCardRedemption(ctx, tx, entry.ID, transaction.ID); err != nil {
+				return err
+			}
+		}
+		result.Transaction = &transaction
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// serveAndSettleTables menandai order lunas sebagai served dan meja order (termasuk meja
+// gabungan) sebagai paid. Mengembalikan nomor meja yang diperbarui.
+func serveAndSettleTables(ctx context.Context, q *db.Queries, tx *sql.Tx, orderID, tableNumber string) ([]string, error) {
+	if err := q.UpdateOrderStatus(ctx, db.UpdateOrderStatusParams{
+		OrderStatus: "served",
+		ID:          orderID,
+	}); err != nil {
+		return nil, fmt.Errorf("gagal update status order: %w", err)
+	}
+
+	settled := map[string]bool{}
+	tableNumbers := []string{tableNumber}
+	mergedOrders, err := q.GetMergedOrders(ctx, sql.NullString{String: orderID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil order hasil gabungan: %w", err)
+	}
+	for _, mergedOrder := range mergedOrders {
+		tableNumbers = append(tableNumbers, mergedOrder.TableNumber)
+	}
+	updated := []string{}
+	for _, tableNumber := range tableNumbers {
+		if tableNumber == "" || settled[tableNumber] {
+			continue
+		}
+		if _, err := SettleTable(ctx, tx, tableNumber, TableStatusPaid); err != nil {
+			return nil, fmt.Errorf("gagal update status meja %s: %w", tableNumber, err)
+		}
+		settled[tableNumber] = true
+		updated = append(updated, tableNumber)
+	}
+	return updated, nil
 }
 
 func (r *orderRepository) ApplyOrderDiscount(ctx context.Context, orderID string, chargeType string, value float64) error {
@@ -758,7 +1383,7 @@ func (r *orderRepository) ApplyOrderDiscount(ctx context.Context, orderID string
 			return err
 		}
 
-		if _, _, err := r.recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
+		if _, _, err := recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
 			return err
 		}
 
@@ -841,6 +1466,9 @@ func (r *orderRepository) ApplyOrderCompliment(ctx context.Context, orderID stri
 		`, orderID); err != nil {
 			return err
 		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM order_taxes WHERE order_id = ?", orderID); err != nil {
+			return err
+		}
 
 		currentTotal := math.Round(subtotal)
 		if currentTotal <= 0 {
@@ -873,6 +1501,149 @@ func (r *orderRepository) ApplyOrderCompliment(ctx context.Context, orderID stri
 	})
 }
 
+// ApplyVoucher memasang voucher ke order; kuota dicek dalam transaksi yang sama
+// dan voucher ditolak jika promosinya tidak menghasilkan diskon untuk order ini.
+func (r *orderRepository) ApplyVoucher(ctx context.Context, orderID string, code string, appliedBy string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		var paymentStatus string
+		var voidedAt sql.NullTime
+		if err := tx.QueryRowContext(ctx, `
+			SELECT payment_status, voided_at
+			FROM orders
+			WHERE id = ?
+		`, orderID).Scan(&paymentStatus, &voidedAt); err != nil {
+			return err
+		}
+		if voidedAt.Valid {
+			return ErrOrderVoided
+		}
+		if paymentStatus == "paid" {
+			return ErrOrderAlreadyPaid
+		}
+
+		var voucherID, promotionID string
+		var usageLimit, isActive, promoActive int64
+		var expiresAt sql.NullTime
+		err := tx.QueryRowContext(ctx, `
+			SELECT v.id, v.promotion_id, v.usage_limit, v.is_active, v.expires_at, p.is_active
+			FROM vouchers v
+			JOIN promotions p ON p.id = v.promotion_id
+			WHERE v.code = ?
+		`, code).Scan(&voucherID, &promotionID, &usageLimit, &isActive, &expiresAt, &promoActive)
+		if err == sql.ErrNoRows {
+			return ErrVoucherNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if isActive != 1 || promoActive != 1 || (expiresAt.Valid && !time.Now().Before(expiresAt.Time)) {
+			return ErrVoucherInvalid
+		}
+
+		var alreadyApplied int64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM order_vouchers WHERE order_id = ? AND voucher_id = ?
+		`, orderID, voucherID).Scan(&alreadyApplied); err != nil {
+			return err
+		}
+		if alreadyApplied > 0 {
+			return ErrVoucherAlreadyApplied
+		}
+
+		if usageLimit > 0 {
+			var used int64
+			if err := tx.QueryRowContext(ctx, `
+				SELECT COUNT(*)
+				FROM order_vouchers ov
+				JOIN orders o ON o.id = ov.order_id
+				WHERE ov.voucher_id = ? AND o.voided_at IS NULL
+			`, voucherID).Scan(&used); err != nil {
+				return err
+			}
+			if used >= usageLimit {
+				return ErrVoucherLimitReached
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO order_vouchers (order_id, voucher_id, applied_by, applied_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		`, orderID, voucherID, sql.NullString{String: appliedBy, Valid: appliedBy != ""}); err != nil {
+			return err
+		}
+
+		if _, _, err := recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
+			return err
+		}
+
+		var applied int64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM order_additional_charges WHERE order_id = ? AND promotion_id = ?
+		`, orderID, promotionID).Scan(&applied); err != nil {
+			return err
+		}
+		if applied == 0 {
+			return ErrVoucherNotApplicable
+		}
+		return nil
+	})
+}
+
+// RemoveVoucher melepas voucher dari order dan menghitung ulang total
+func (r *orderRepository) RemoveVoucher(ctx context.Context, orderID string, code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		order, err := q.GetOrderWithItems(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.PaymentStatus == "paid" {
+			return ErrOrderAlreadyPaid
+		}
+
+		result, err := tx.ExecContext(ctx, `
+			DELETE FROM order_vouchers
+			WHERE order_id = ?
+			  AND voucher_id = (SELECT id FROM vouchers WHERE code = ?)
+		`, orderID, code)
+		if err != nil {
+			return err
+		}
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return ErrVoucherNotFound
+		}
+
+		_, _, err = recalculateOrderTotals(ctx, q, tx, orderID)
+		return err
+	})
+}
+
+// GetOrderPromotions mengembalikan baris promosi yang berlaku pada order
+func (r *orderRepository) GetOrderPromotions(ctx context.Context, orderID string) ([]AppliedPromotion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT promotion_id, name, applied_amount
+		FROM order_additional_charges
+		WHERE order_id = ?
+		  AND promotion_id IS NOT NULL
+		ORDER BY id
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil promosi order: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := []AppliedPromotion{}
+	for rows.Next() {
+		var promo AppliedPromotion
+		if err := rows.Scan(&promo.PromotionID, &promo.Name, &promo.AppliedAmount); err != nil {
+			return nil, fmt.Errorf("gagal membaca promosi order: %w", err)
+		}
+		promotions = append(promotions, promo)
+	}
+	return promotions, rows.Err()
+}
+
 func (r *orderRepository) GetOrderWithItems(ctx context.Context, orderID string) (*db.Order, []db.OrderItem, error) {
 	q := db.New(r.db)
 
@@ -1188,6 +1959,7 @@ func (r *orderRepository) GetAdditionalChargesSummary(ctx context.Context, start
 		FROM order_additional_charges oac
 		INNER JOIN orders o ON oac.order_id = o.id
 		WHERE o.created_at BETWEEN ? AND ?
+		AND oac.promotion_id IS NULL
 		AND o.payment_status = 'paid'
 		AND o.is_merged = 0
 		AND o.voided_at IS NULL
@@ -1235,13 +2007,20 @@ func (r *orderRepository) GetAdditionalChargesSummary(ctx context.Context, start
 }
 
 // GetProductsSold menghitung total produk yang terjual
-func (r *orderRepository) GetProductsSold(ctx context.Context, startDate, endDate time.Time) (int64, error) {
-	query := `
-		SELECT 
-			COALESCE(SUM(oi.qty), 0) as total_qty
-		FROM order_items oi
-		INNER JOIN orders o ON oi.order_id = o.id
+// GetPromotionSummary merangkum diskon promosi per promosi untuk order yang sudah dibayar
+func (r *orderRepository) GetPromotionSummary(ctx context.Context, startDate, endDate time.Time) (float64, []PromotionSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			oac.promotion_id,
+			MAX(oac.name) as name,
+			COUNT(DISTINCT oac.order_id) as orders_count,
+			COALESCE(SUM(oac.applied_amount), 0) as total_discount
+		FROM order_additional_charges oac
+		INNER JOIN orders o ON oac.order_id = o.id
 		WHERE o.created_at BETWEEN ? AND ?
+		AND oac.promotion_id IS NOT NULL
+		AND o.payment_status = 'paid'
+		AND o.is_merged = 0
 		AND o.voided_at IS NULL
 		AND NOT EXISTS (
 			SELECT 1
@@ -1249,12 +2028,50 @@ func (r *orderRepository) GetProductsSold(ctx context.Context, startDate, endDat
 			WHERE t.order_id = o.id
 			AND t.status = 'cancelled'
 		)
-	`
-
-	var totalQty int64
-	err := r.db.QueryRowContext(ctx, query, startDate, endDate).Scan(&totalQty)
+		GROUP BY oac.promotion_id
+		ORDER BY total_discount ASC
+	`, startDate, endDate)
 	if err != nil {
-		return 0, fmt.Errorf("gagal mengambil total produk terjual: %w", err)
+		return 0, nil, fmt.Errorf("gagal mengambil ringkasan promosi: %w", err)
+	}
+	defer rows.Close()
+
+	total := 0.0
+	summaries := []PromotionSummary{}
+	for rows.Next() {
+		var summary PromotionSummary
+		if err := rows.Scan(&summary.PromotionID, &summary.Name, &summary.OrdersCount, &summary.TotalDiscount); err != nil {
+			return 0, nil, fmt.Errorf("gagal scan ringkasan promosi: %w", err)
+		}
+		total += summary.TotalDiscount
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, err
+	}
+	return total, summaries, nil
+}
+
+func (r *orderRepository) GetProductsSold(ctx context.Context, startDate, endDate time.Time) (int64, error) {
+	query := `
+		SELECT 
+			COALESCE(SUM(oi.qty), 0) as total_qty
+		FROM order_items oi
+		INNER JOIN orders o ON oi.order_id = o.id
+		WHERE o.created_at BETWEEN ? AND ?
+		AND o.voided_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1
+			FROM transactions t
+			WHERE t.order_id = o.id
+			AND t.status = 'cancelled'
+		)
+	`
+
+	var totalQty int64
+	err := r.db.QueryRowContext(ctx, query, startDate, endDate).Scan(&totalQty)
+	if err != nil {
+		return 0, fmt.Errorf("gagal mengambil total produk terjual: %w", err)
 	}
 
 	return totalQty, nil
@@ -1362,9 +2179,32 @@ func (r *orderRepository) GetRevenueTimeSeries(ctx context.Context, startDate, e
 	return result, nil
 }
 
-// SplitBillPayment menambahkan pembayaran parsial (split bill)
-func (r *orderRepository) SplitBillPayment(ctx context.Context, orderID string, amount float64, paymentMethod string, note string, createdBy string, items []SplitBillItem) error {
-	return r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+// SplitBillPayment mencatat pembayaran parsial (split bill) dalam satu transaksi: check
+// diklaim, item yang dibayar dikeluarkan dari order, baris pembayaran, transaksi, tip dan
+// potongan gift card dicatat, lalu check ditandai lunas. Jika order menjadi lunas, order
+// ditandai served dan meja ditandai paid. Jika satu langkah gagal tidak ada yang tersimpan.
+func (r *orderRepository) SplitBillPayment(ctx context.Context, input SplitPayment) (*SplitPaymentResult, error) {
+	orderID, amount, createdBy, shiftID := input.OrderID, input.Amount, input.CashierID, input.ShiftID
+	items := input.Items
+	result := &SplitPaymentResult{}
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		current, err := q.GetOrderWithItems(ctx, orderID)
+		if err != nil {
+			return fmt.Errorf("gagal mendapatkan order: %w", err)
+		}
+		if current.PaymentStatus == "paid" {
+			return ErrOrderAlreadyPaid
+		}
+		if input.PaymentIntentID != "" {
+			if err := settlePaymentIntent(ctx, tx, input.PaymentIntentID); err != nil {
+				return err
+			}
+		}
+		if input.CheckID != "" {
+			if err := ClaimOrderCheck(ctx, tx, input.CheckID); err != nil {
+				return err
+			}
+		}
 		if len(items) > 0 {
 			qtyByID := make(map[string]int64, len(items))
 			for _, item := range items {
@@ -1418,16 +2258,13 @@ func (r *orderRepository) SplitBillPayment(ctx context.Context, orderID string,
 			}
 		}
 
-		// Create payment record
+		// Create payment record (shift_id = laci terminal yang menerima pembayaran)
 		paymentID := ulid.MustNew(ulid.Now(), rand.Reader).String()
-		_, err := q.CreatePayment(ctx, db.CreatePaymentParams{
-			ID:            paymentID,
-			OrderID:       orderID,
-			Amount:        amount,
-			PaymentMethod: paymentMethod,
-			PaymentNote:   sql.NullString{String: note, Valid: note != ""},
-			CreatedBy:     createdBy,
-		})
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO payments (id, order_id, amount, payment_method, payment_note, created_by, shift_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, paymentID, orderID, amount, input.PaymentMethod, sql.NullString{String: input.Note, Valid: input.Note != ""}, createdBy,
+			sql.NullString{String: shiftID, Valid: shiftID != ""})
 		if err != nil {
 			return fmt.Errorf("gagal membuat pembayaran: %w", err)
 		}
@@ -1465,12 +2302,87 @@ func (r *orderRepository) SplitBillPayment(ctx context.Context, orderID string,
 			return fmt.Errorf("gagal update order: %w", err)
 		}
 
+		// Deposit dipakai saat pelunasan: jika saldo deposit menutup sisa tagihan setelah
+		// pembayaran ini, deposit dipotong di transaksi yang sama dan order menjadi lunas
+		if paymentStatus != "paid" {
+			rest := math.Round(order.TotalAmount - totalPaid)
+			held, err := HeldDepositBalance(ctx, tx, orderID)
+			if err != nil {
+				return fmt.Errorf("gagal memeriksa deposit: %w", err)
+			}
+			if held > 0 && held >= rest {
+				if _, err := ApplyOrderDeposits(ctx, tx, orderID, rest, createdBy, shiftID); err != nil {
+					return fmt.Errorf("gagal memakai deposit: %w", err)
+				}
+				paymentStatus = "paid"
+			}
+		}
+
+		if err := RecordCustomerVisit(ctx, tx, orderID); err != nil {
+			return err
+		}
+
+		transaction, err := insertTransaction(ctx, q, db.CreateTransactionParams{
+			OrderID:         orderID,
+			TotalAmount:     amount,
+			PaymentMethod:   input.PaymentMethod,
+			Status:          "completed",
+			TransactionDate: time.Now().UTC(),
+			CreatedBy:       createdBy,
+			ShiftID:         sql.NullString{String: shiftID, Valid: shiftID != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("gagal mencatat transaksi: %w", err)
+		}
+		if input.ReferenceNumber != "" {
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE transactions SET reference_number = ? WHERE id = ?
+			`, input.ReferenceNumber, transaction.ID); err != nil {
+				return fmt.Errorf("gagal menyimpan nomor referensi: %w", err)
+			}
+		}
+		tipAmount := 0.0
+		if input.Tip != nil {
+			input.Tip.TransactionID = transaction.ID
+			input.Tip.PaymentMethod = input.PaymentMethod
+			if err := CreatePaymentTip(ctx, tx, input.Tip); err != nil {
+				return err
+			}
+			tipAmount = input.Tip.Amount
+		}
+		if input.PaymentMethod == GiftCardPaymentMethod {
+			entry, err := redeemGiftCardTx(ctx, tx, input.GiftCardCode, amount+tipAmount, orderID, createdBy, shiftID)
+			if err != nil {
+				return err
+			}
+			if err := LinkGiftCardRedemption(ctx, tx, entry.ID, transaction.ID); err != nil {
+				return err
+			}
+		}
+		if input.CheckID != "" {
+			if err := SettleOrderCheck(ctx, tx, input.CheckID, amount, transaction.ID); err != nil {
+				return fmt.Errorf("gagal menandai check lunas: %w", err)
+			}
+		}
+
+		if paymentStatus == "paid" {
+			result.TableNumbers, err = serveAndSettleTables(ctx, q, tx, orderID, order.TableNumber)
+			if err != nil {
+				return err
+			}
+		}
+		result.Transaction = transaction
+		result.PaymentStatus = paymentStatus
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // MergeTables menggabungkan beberapa order/meja menjadi satu
-func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string) (string, error) {
+func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string, mergedBy string) (string, error) {
 	var newOrderID string
 
 	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
@@ -1480,6 +2392,7 @@ func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []stri
 		var totalPax int64
 		var customerNames []string
 		var createdBy string
+		sourceChanges := make([]*TableChange, 0, len(sourceOrderIDs))
 
 		for _, sourceID := range sourceOrderIDs {
 			order, err := q.GetOrderWithItems(ctx, sourceID)
@@ -1499,6 +2412,13 @@ func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []stri
 			}
 
 			allItems = append(allItems, items...)
+			sourceChanges = append(sourceChanges, &TableChange{
+				ChangeType:      TableChangeMerge,
+				FromOrderID:     sourceID,
+				FromTableNumber: order.TableNumber,
+				ToTableNumber:   targetTableNumber,
+				Items:           toTableChangeItems(items),
+			})
 			totalAmount += order.TotalAmount
 			totalPax += order.Pax
 			if order.CustomerName.Valid && order.CustomerName.String != "" {
@@ -1543,6 +2463,15 @@ func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []stri
 				return fmt.Errorf("gagal transfer item dari %s: %w", sourceID, err)
 			}
 
+			// Voucher ikut pindah agar promosinya tetap berlaku di order gabungan
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE OR IGNORE order_vouchers
+				SET order_id = ?
+				WHERE order_id = ?
+			`, newOrderID, sourceID); err != nil {
+				return fmt.Errorf("gagal transfer voucher dari %s: %w", sourceID, err)
+			}
+
 			// Mark source order as merged
 			err = q.MergeOrders(ctx, db.MergeOrdersParams{
 				MergedFrom: sql.NullString{String: newOrderID, Valid: true},
@@ -1553,7 +2482,15 @@ func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []stri
 			}
 		}
 
-		_, _, err = r.recalculateOrderTotals(ctx, q, tx, newOrderID)
+		// Asal tiap item dicatat agar gabungan bisa dipisah kembali
+		for _, change := range sourceChanges {
+			change.ToOrderID = newOrderID
+			if err := recordTableChange(ctx, tx, change, mergedBy); err != nil {
+				return fmt.Errorf("gagal mencatat gabungan meja: %w", err)
+			}
+		}
+
+		_, _, err = recalculateOrderTotals(ctx, q, tx, newOrderID)
 		if err != nil {
 			return err
 		}
@@ -1564,12 +2501,559 @@ func (r *orderRepository) MergeTables(ctx context.Context, sourceOrderIDs []stri
 	return newOrderID, err
 }
 
+// movableOrder adalah order yang masih boleh dipindah meja atau itemnya
+type movableOrder struct {
+	ID          string
+	TableNumber string
+	OrderType   string
+}
+
+// loadMovableOrder memastikan order masih berjalan: belum ada pembayaran, tidak di-void,
+// tidak tergabung ke order lain dan tidak sedang di-split per check
+func loadMovableOrder(ctx context.Context, q db.DBTX, orderID string) (*movableOrder, error) {
+	var order movableOrder
+	var paymentStatus string
+	var paidAmount float64
+	var isMerged int64
+	var voidedAt sql.NullTime
+	err := q.QueryRowContext(ctx, `
+		SELECT id, table_number, order_type, payment_status, paid_amount, is_merged, voided_at
+		FROM orders
+		WHERE id = ?
+	`, orderID).Scan(&order.ID, &order.TableNumber, &order.OrderType, &paymentStatus, &paidAmount, &isMerged, &voidedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: order %s tidak ditemukan", ErrInvalidTableChange, orderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if voidedAt.Valid {
+		return nil, fmt.Errorf("%w: %s", ErrOrderVoided, orderID)
+	}
+	if paymentStatus != "unpaid" || paidAmount > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrOrderAlreadyPaid, orderID)
+	}
+	if isMerged == 1 {
+		return nil, fmt.Errorf("%w: order %s sudah digabung ke order lain", ErrInvalidTableChange, orderID)
+	}
+	hasChecks, err := HasOpenOrderChecks(ctx, q, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if hasChecks {
+		return nil, fmt.Errorf("%w: %s", ErrOrderHasChecks, orderID)
+	}
+	return &order, nil
+}
+
+// countOpenTableOrders menghitung order yang masih berjalan di satu meja
+func countOpenTableOrders(ctx context.Context, q db.DBTX, tableNumber string) (int, error) {
+	var count int
+	err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE table_number = ?
+		  AND payment_status != 'paid'
+		  AND is_merged = 0
+		  AND voided_at IS NULL
+	`, tableNumber).Scan(&count)
+	return count, err
+}
+
+// releaseTableIfFree menandai meja perlu dibersihkan jika tidak ada lagi order yang berjalan di sana
+func releaseTableIfFree(ctx context.Context, q db.DBTX, tableNumber string) (bool, error) {
+	return SettleTable(ctx, q, tableNumber, TableStatusDirty)
+}
+
+func toTableChangeItems(items []db.OrderItem) []TableChangeItem {
+	result := make([]TableChangeItem, 0, len(items))
+	for _, item := range items {
+		result = append(result, TableChangeItem{
+			ItemID:      item.ID,
+			ProductName: item.ProductName,
+			Qty:         item.Qty,
+			ItemStatus:  item.ItemStatus,
+		})
+	}
+	return result
+}
+
+// recordTableChange menyimpan riwayat perpindahan beserta item yang ikut berpindah
+func recordTableChange(ctx context.Context, q db.DBTX, change *TableChange, createdBy string) error {
+	change.ID = ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+	change.CreatedAt = time.Now().UTC()
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO table_changes (
+			id, change_type, from_order_id, to_order_id, from_table_number, to_table_number, created_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, change.ID, change.ChangeType, change.FromOrderID, change.ToOrderID, change.FromTableNumber,
+		change.ToTableNumber, nullableID(createdBy), change.CreatedAt); err != nil {
+		return err
+	}
+	for _, item := range change.Items {
+		if _, err := q.ExecContext(ctx, `
+			INSERT INTO table_change_items (change_id, order_item_id, product_name, qty)
+			VALUES (?, ?, ?, ?)
+		`, change.ID, item.ItemID, item.ProductName, item.Qty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueTableChangeSlips mencetak slip perpindahan ke printer dapur/bar dari item yang belum disajikan,
+// agar pesanan yang sedang dimasak diantar ke meja yang benar
+func queueTableChangeSlips(ctx context.Context, q *db.Queries, tx *sql.Tx, change *TableChange, movedBy string) error {
+	itemsByPrinter := make(map[string][]PrintItem)
+	printerIDs := []string{}
+	for _, item := range change.Items {
+		if item.ItemStatus == "served" {
+			continue
+		}
+		printerID, err := orderItemPrinterID(ctx, tx, item.ItemID)
+		if err != nil {
+			return err
+		}
+		if printerID == "" {
+			continue
+		}
+		if _, ok := itemsByPrinter[printerID]; !ok {
+			printerIDs = append(printerIDs, printerID)
+		}
+		itemsByPrinter[printerID] = append(itemsByPrinter[printerID], PrintItem{
+			Name:     item.ProductName,
+			Quantity: int(item.Qty),
+		})
+	}
+
+	movedByName := ""
+	if movedBy != "" {
+		if user, err := q.GetUserByID(ctx, movedBy); err == nil {
+			movedByName = user.FullName
+		}
+	}
+
+	now := time.Now()
+	for _, printerID := range printerIDs {
+		payloadJSON, err := json.Marshal(PrintPayload{
+			OrderID:         change.ToOrderID,
+			ReceiptNumber:   change.ToOrderID,
+			TableNumber:     change.ToTableNumber,
+			Items:           itemsByPrinter[printerID],
+			DateTime:        now,
+			IsTableChange:   true,
+			TableChangeType: change.ChangeType,
+			FromOrderID:     change.FromOrderID,
+			FromTableNumber: change.FromTableNumber,
+			MovedBy:         movedByName,
+		})
+		if err != nil {
+			return fmt.Errorf("gagal marshal payload slip pindah meja: %w", err)
+		}
+		if _, err := q.CreatePrintJob(ctx, db.CreatePrintJobParams{
+			ID:        ulid.MustNew(ulid.Timestamp(now), rand.Reader).String(),
+			PrinterID: printerID,
+			Data:      string(payloadJSON),
+		}); err != nil {
+			return fmt.Errorf("gagal membuat print job slip pindah meja: %w", err)
+		}
+	}
+	return nil
+}
+
+// TransferOrder memindahkan order dine-in yang masih berjalan ke meja lain yang kosong
+func (r *orderRepository) TransferOrder(ctx context.Context, orderID string, targetTableNumber string, movedBy string) (*TableChange, error) {
+	targetTableNumber = strings.TrimSpace(targetTableNumber)
+	if targetTableNumber == "" {
+		return nil, fmt.Errorf("%w: meja tujuan wajib diisi", ErrInvalidTableChange)
+	}
+
+	var change *TableChange
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		order, err := loadMovableOrder(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+		if order.OrderType != OrderTypeDineIn {
+			return fmt.Errorf("%w: hanya order dine-in yang bisa pindah meja", ErrInvalidTableChange)
+		}
+		if order.TableNumber == targetTableNumber {
+			return fmt.Errorf("%w: meja tujuan sama dengan meja asal", ErrInvalidTableChange)
+		}
+		if _, err := q.GetTableByNumber(ctx, targetTableNumber); err != nil {
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: meja %s tidak ditemukan", ErrInvalidTableChange, targetTableNumber)
+			}
+			return err
+		}
+		open, err := countOpenTableOrders(ctx, tx, targetTableNumber)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return fmt.Errorf("%w: meja %s", ErrTableOccupied, targetTableNumber)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE orders
+			SET table_number = ?, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, targetTableNumber, orderID); err != nil {
+			return fmt.Errorf("gagal memindah order: %w", err)
+		}
+
+		items, err := q.GetOrderItems(ctx, orderID)
+		if err != nil {
+			return err
+		}
+		change = &TableChange{
+			ChangeType:      TableChangeTransfer,
+			FromOrderID:     orderID,
+			ToOrderID:       orderID,
+			FromTableNumber: order.TableNumber,
+			ToTableNumber:   targetTableNumber,
+			Items:           toTableChangeItems(items),
+		}
+		if err := recordTableChange(ctx, tx, change, movedBy); err != nil {
+			return fmt.Errorf("gagal mencatat pindah meja: %w", err)
+		}
+
+		// Aturan biaya per area mengikuti meja baru
+		if _, _, err := recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
+			return err
+		}
+		if err := moveTableSession(ctx, tx, order.TableNumber, targetTableNumber, orderID); err != nil {
+			return fmt.Errorf("gagal memindah sesi meja: %w", err)
+		}
+		if _, err := releaseTableIfFree(ctx, tx, order.TableNumber); err != nil {
+			return fmt.Errorf("gagal update status meja asal: %w", err)
+		}
+		if err := setTableOccupied(ctx, tx, targetTableNumber); err != nil {
+			return fmt.Errorf("gagal update status meja tujuan: %w", err)
+		}
+		return queueTableChangeSlips(ctx, q, tx, change, movedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// MoveOrderItems memindahkan sebagian item (atau sebagian qty item) ke order lain yang masih berjalan.
+// Qty parsial memecah item: sisa tetap di order asal, sisanya menjadi item baru di order tujuan.
+func (r *orderRepository) MoveOrderItems(ctx context.Context, sourceOrderID, targetOrderID string, items []MoveItemInput, movedBy string) (*TableChange, error) {
+	if sourceOrderID == targetOrderID {
+		return nil, fmt.Errorf("%w: order tujuan sama dengan order asal", ErrInvalidTableChange)
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: pilih minimal satu item", ErrInvalidTableChange)
+	}
+
+	var change *TableChange
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		source, err := loadMovableOrder(ctx, tx, sourceOrderID)
+		if err != nil {
+			return err
+		}
+		target, err := loadMovableOrder(ctx, tx, targetOrderID)
+		if err != nil {
+			return err
+		}
+
+		change = &TableChange{
+			ChangeType:      TableChangeMoveItems,
+			FromOrderID:     sourceOrderID,
+			ToOrderID:       targetOrderID,
+			FromTableNumber: source.TableNumber,
+			ToTableNumber:   target.TableNumber,
+		}
+		seen := make(map[string]bool, len(items))
+		for _, input := range items {
+			if seen[input.ItemID] {
+				return fmt.Errorf("%w: item %s dipilih lebih dari sekali", ErrInvalidTableChange, input.ItemID)
+			}
+			seen[input.ItemID] = true
+
+			var item TableChangeItem
+			err := tx.QueryRowContext(ctx, `
+				SELECT id, product_name, qty, item_status
+				FROM order_items
+				WHERE id = ? AND order_id = ?
+			`, input.ItemID, sourceOrderID).Scan(&item.ItemID, &item.ProductName, &item.Qty, &item.ItemStatus)
+			if err == sql.ErrNoRows {
+				return fmt.Errorf("%w: %s", ErrOrderItemNotFound, input.ItemID)
+			}
+			if err != nil {
+				return err
+			}
+
+			qty := input.Qty
+			if qty == 0 {
+				qty = item.Qty
+			}
+			if qty < 0 || qty > item.Qty {
+				return fmt.Errorf("%w: qty %s maksimal %d", ErrInvalidItemQty, item.ProductName, item.Qty)
+			}
+
+			if qty == item.Qty {
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE order_items
+					SET order_id = ?, updated_at = CURRENT_TIMESTAMP
+					WHERE id = ?
+				`, targetOrderID, item.ItemID); err != nil {
+					return fmt.Errorf("gagal memindah item: %w", err)
+				}
+			} else {
+				newItemID := ulid.MustNew(ulid.Timestamp(time.Now()), rand.Reader).String()
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE order_items
+					SET qty = qty - ?, updated_at = CURRENT_TIMESTAMP
+					WHERE id = ?
+				`, qty, item.ItemID); err != nil {
+					return fmt.Errorf("gagal memecah item: %w", err)
+				}
+				if _, err := tx.ExecContext(ctx, `
+					INSERT INTO order_items (
+						id, order_id, product_name, qty, price, destination, item_status,
+						product_id, seat_number, course, is_held, fired_at, created_at, updated_at
+					)
+					SELECT ?, ?, product_name, ?, price, destination, item_status,
+						product_id, seat_number, course, is_held, fired_at, created_at, CURRENT_TIMESTAMP
+					FROM order_items
+					WHERE id = ?
+				`, newItemID, targetOrderID, qty, item.ItemID); err != nil {
+					return fmt.Errorf("gagal memecah item: %w", err)
+				}
+				item.ItemID = newItemID
+				item.Qty = qty
+			}
+			change.Items = append(change.Items, item)
+		}
+
+		var remaining int
+		if err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM order_items WHERE order_id = ?", sourceOrderID).Scan(&remaining); err != nil {
+			return err
+		}
+		if remaining == 0 {
+			return fmt.Errorf("%w: seluruh item akan berpindah, gunakan pindah meja atau gabung meja", ErrInvalidTableChange)
+		}
+
+		if err := recordTableChange(ctx, tx, change, movedBy); err != nil {
+			return fmt.Errorf("gagal mencatat pindah item: %w", err)
+		}
+		for _, orderID := range []string{sourceOrderID, targetOrderID} {
+			if _, _, err := recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
+				return err
+			}
+		}
+		return queueTableChangeSlips(ctx, q, tx, change, movedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// UnmergeOrder memisahkan order hasil gabung meja kembali ke order asalnya.
+// Item kembali ke order asal sesuai catatan gabungan; item yang ditambahkan setelah
+// digabung (atau dari gabungan lama tanpa catatan) masuk ke order di meja gabungan,
+// atau order asal pertama jika meja gabungan bukan meja asal mana pun.
+func (r *orderRepository) UnmergeOrder(ctx context.Context, mergedOrderID string, movedBy string) (*UnmergeResult, error) {
+	var result *UnmergeResult
+	err := r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		merged, err := loadMovableOrder(ctx, tx, mergedOrderID)
+		if err != nil {
+			return err
+		}
+
+		var pendingIntents int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(*) FROM payment_intents WHERE order_id = ? AND status = 'pending'
+		`, mergedOrderID).Scan(&pendingIntents); err != nil {
+			return err
+		}
+		if pendingIntents > 0 {
+			return fmt.Errorf("%w: masih ada pembayaran online yang menunggu", ErrInvalidTableChange)
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			SELECT id, table_number
+			FROM orders
+			WHERE merged_from = ? AND is_merged = 1
+			ORDER BY created_at ASC, id ASC
+		`, mergedOrderID)
+		if err != nil {
+			return err
+		}
+		var sources []movableOrder
+		for rows.Next() {
+			var source movableOrder
+			if err := rows.Scan(&source.ID, &source.TableNumber); err != nil {
+				rows.Close()
+				return err
+			}
+			sources = append(sources, source)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(sources) == 0 {
+			return fmt.Errorf("%w: order bukan hasil gabung meja", ErrInvalidTableChange)
+		}
+
+		primary := sources[0].ID
+		isSource := make(map[string]bool, len(sources))
+		for _, source := range sources {
+			isSource[source.ID] = true
+			if source.TableNumber == merged.TableNumber {
+				primary = source.ID
+			}
+		}
+
+		itemOrigin := make(map[string]string)
+		originRows, err := tx.QueryContext(ctx, `
+			SELECT tci.order_item_id, tc.from_order_id
+			FROM table_changes tc
+			JOIN table_change_items tci ON tci.change_id = tc.id
+			WHERE tc.to_order_id = ? AND tc.change_type = ?
+		`, mergedOrderID, TableChangeMerge)
+		if err != nil {
+			return err
+		}
+		for originRows.Next() {
+			var itemID, sourceID string
+			if err := originRows.Scan(&itemID, &sourceID); err != nil {
+				originRows.Close()
+				return err
+			}
+			itemOrigin[itemID] = sourceID
+		}
+		originRows.Close()
+		if err := originRows.Err(); err != nil {
+			return err
+		}
+
+		items, err := q.GetOrderItems(ctx, mergedOrderID)
+		if err != nil {
+			return err
+		}
+		itemsBySource := make(map[string][]TableChangeItem, len(sources))
+		for _, item := range toTableChangeItems(items) {
+			sourceID := itemOrigin[item.ItemID]
+			if !isSource[sourceID] {
+				sourceID = primary
+			}
+			itemsBySource[sourceID] = append(itemsBySource[sourceID], item)
+		}
+
+		result = &UnmergeResult{MergedOrderID: mergedOrderID}
+		for _, source := range sources {
+			for _, item := range itemsBySource[source.ID] {
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE order_items
+					SET order_id = ?, updated_at = CURRENT_TIMESTAMP
+					WHERE id = ?
+				`, source.ID, item.ItemID); err != nil {
+					return fmt.Errorf("gagal mengembalikan item ke %s: %w", source.ID, err)
+				}
+			}
+			if _, err := tx.ExecContext(ctx, `
+				UPDATE orders
+				SET is_merged = 0, merged_from = NULL, updated_at = CURRENT_TIMESTAMP
+				WHERE id = ?
+			`, source.ID); err != nil {
+				return fmt.Errorf("gagal memulihkan order %s: %w", source.ID, err)
+			}
+
+			change := &TableChange{
+				ChangeType:      TableChangeUnmerge,
+				FromOrderID:     mergedOrderID,
+				ToOrderID:       source.ID,
+				FromTableNumber: merged.TableNumber,
+				ToTableNumber:   source.TableNumber,
+				Items:           itemsBySource[source.ID],
+			}
+			if err := recordTableChange(ctx, tx, change, movedBy); err != nil {
+				return fmt.Errorf("gagal mencatat pisah meja: %w", err)
+			}
+			result.RestoredOrders = append(result.RestoredOrders, source.ID)
+			result.Changes = append(result.Changes, *change)
+		}
+
+		// Voucher, deposit dan reservasi order gabungan ikut ke order utama
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE OR IGNORE order_vouchers SET order_id = ? WHERE order_id = ?
+		`, primary, mergedOrderID); err != nil {
+			return fmt.Errorf("gagal memindah voucher: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE deposits SET order_id = ? WHERE order_id = ? AND status = 'held'
+		`, primary, mergedOrderID); err != nil {
+			return fmt.Errorf("gagal memindah deposit: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE reservations SET order_id = ? WHERE order_id = ?
+		`, primary, mergedOrderID); err != nil {
+			return fmt.Errorf("gagal memindah reservasi: %w", err)
+		}
+
+		// Order gabungan sudah kosong dan belum pernah dibayar, jadi dihapus
+		for _, query := range []string{
+			"DELETE FROM order_vouchers WHERE order_id = ?",
+			"DELETE FROM order_additional_charges WHERE order_id = ?",
+			"DELETE FROM order_taxes WHERE order_id = ?",
+			"DELETE FROM order_checks WHERE order_id = ?",
+			"DELETE FROM payment_intents WHERE order_id = ?",
+			"DELETE FROM orders WHERE id = ?",
+		} {
+			if _, err := tx.ExecContext(ctx, query, mergedOrderID); err != nil {
+				return fmt.Errorf("gagal menghapus order gabungan: %w", err)
+			}
+		}
+
+		sourceTables := make(map[string]bool, len(sources))
+		for _, source := range sources {
+			if _, _, err := recalculateOrderTotals(ctx, q, tx, source.ID); err != nil {
+				return err
+			}
+			if sourceTables[source.TableNumber] {
+				continue
+			}
+			sourceTables[source.TableNumber] = true
+			if err := setTableOccupied(ctx, tx, source.TableNumber); err != nil {
+				return fmt.Errorf("gagal update status meja %s: %w", source.TableNumber, err)
+			}
+		}
+		if !sourceTables[merged.TableNumber] {
+			freed, err := releaseTableIfFree(ctx, tx, merged.TableNumber)
+			if err != nil {
+				return fmt.Errorf("gagal update status meja gabungan: %w", err)
+			}
+			if freed {
+				result.FreedTables = append(result.FreedTables, merged.TableNumber)
+			}
+		}
+
+		for i := range result.Changes {
+			if err := queueTableChangeSlips(ctx, q, tx, &result.Changes[i], movedBy); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // GetOrderPayments mendapatkan semua pembayaran untuk order (untuk tracking split bill)
 func (r *orderRepository) GetOrderPayments(ctx context.Context, orderID string) ([]db.Payment, error) {
 	return db.New(r.db).GetPaymentsByOrder(ctx, orderID)
 }
 
-func (r *orderRepository) VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string) error {
+func (r *orderRepository) VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string, shiftID string) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
@@ -1599,9 +3083,9 @@ func (r *orderRepository) VoidOrder(ctx context.Context, orderID string, voidedB
 
 	_, err = tx.ExecContext(ctx, `
 		UPDATE orders
-		SET voided_at = CURRENT_TIMESTAMP, voided_by = ?, void_reason = ?, updated_at = CURRENT_TIMESTAMP
+		SET voided_at = CURRENT_TIMESTAMP, voided_by = ?, void_reason = ?, voided_shift_id = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
-	`, voidedBy, voidReason, orderID)
+	`, voidedBy, voidReason, sql.NullString{String: shiftID, Valid: shiftID != ""}, orderID)
 	if err != nil {
 		_ = tx.Rollback()
 		return err
@@ -1610,6 +3094,357 @@ func (r *orderRepository) VoidOrder(ctx context.Context, orderID string, voidedB
 	return tx.Commit()
 }
 
+// RefundOrder mencatat refund sebagian/penuh untuk order yang sudah lunas.
+// Nominal refund per item mengikuti porsi item terhadap total order (termasuk
+// biaya tambahan, promosi dan pajak). Refund juga ditulis sebagai payment negatif
+// sehingga ringkasan shift per metode ikut berkurang.
+func (r *orderRepository) RefundOrder(ctx context.Context, input RefundInput) (string, error) {
+	refundMethod, err := GetActivePaymentMethod(ctx, r.db, input.RefundMethod)
+	if err != nil {
+		if err == ErrPaymentMethodNotFound {
+			return "", fmt.Errorf("%w: metode refund tidak valid atau tidak aktif", ErrInvalidRefund)
+		}
+		return "", err
+	}
+	if refundMethod.MethodType == "qris" {
+		return "", fmt.Errorf("%w: refund QRIS tidak didukung, gunakan tunai atau transfer", ErrInvalidRefund)
+	}
+	input.Reason = strings.TrimSpace(input.Reason)
+	if input.Reason == "" {
+		return "", fmt.Errorf("%w: alasan refund wajib diisi", ErrInvalidRefund)
+	}
+	if len(input.Items) == 0 && input.Amount <= 0 {
+		return "", fmt.Errorf("%w: pilih item atau isi nominal refund", ErrInvalidRefund)
+	}
+	if input.Amount < 0 {
+		return "", fmt.Errorf("%w: nominal refund tidak valid", ErrInvalidRefund)
+	}
+
+	refundID := ulid.MustNew(ulid.Now(), rand.Reader).String()
+	err = r.execTx(ctx, func(q *db.Queries, tx *sql.Tx) error {
+		order, err := q.GetOrderWithItems(ctx, input.OrderID)
+		if err != nil {
+			return err
+		}
+		var voidedAt sql.NullTime
+		if err := tx.QueryRowContext(ctx, `SELECT voided_at FROM orders WHERE id = ?`, order.ID).Scan(&voidedAt); err != nil {
+			return err
+		}
+		if voidedAt.Valid {
+			return ErrOrderVoided
+		}
+		if order.PaymentStatus != "paid" {
+			return ErrOrderNotPaid
+		}
+
+		originalPaymentID, err := resolveRefundOriginalPayment(ctx, tx, order.ID, input.OriginalPaymentID)
+		if err != nil {
+			return err
+		}
+
+		var refundedTotal float64
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(amount), 0)
+			FROM refunds
+			WHERE order_id = ?
+		`, order.ID).Scan(&refundedTotal); err != nil {
+			return fmt.Errorf("gagal menghitung refund sebelumnya: %w", err)
+		}
+		refundable := order.PaidAmount - refundedTotal
+
+		refundItems, err := buildRefundItems(ctx, tx, order, input.Items)
+		if err != nil {
+			return err
+		}
+
+		refundType := "amount"
+		amount := input.Amount
+		if len(refundItems) > 0 {
+			refundType = "items"
+			if amount == 0 {
+				for _, item := range refundItems {
+					amount += item.Amount
+				}
+			}
+		}
+		amount = math.Round(amount)
+		if amount <= 0 {
+			return fmt.Errorf("%w: nominal refund tidak valid", ErrInvalidRefund)
+		}
+		if amount > math.Round(refundable) {
+			return ErrRefundExceedsPaid
+		}
+
+		paymentID := ulid.MustNew(ulid.Now(), rand.Reader).String()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO payments (id, order_id, amount, payment_method, payment_note, created_by, refund_of, shift_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		`, paymentID, order.ID, -amount, input.RefundMethod, "Refund: "+input.Reason, input.CreatedBy, sql.NullString{String: originalPaymentID, Valid: originalPaymentID != ""},
+			sql.NullString{String: input.ShiftID, Valid: input.ShiftID != ""}); err != nil {
+			return fmt.Errorf("gagal mencatat payment refund: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO refunds (
+				id, order_id, payment_id, original_payment_id, refund_type, refund_method,
+				amount, reason, restock, approved_by, created_by
+			)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, refundID, order.ID, paymentID, sql.NullString{String: originalPaymentID, Valid: originalPaymentID != ""}, refundType, input.RefundMethod,
+			amount, input.Reason, boolToInt(input.Restock), input.ApprovedBy, input.CreatedBy); err != nil {
+			return fmt.Errorf("gagal menyimpan refund: %w", err)
+		}
+
+		for _, item := range refundItems {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO refund_items (refund_id, order_item_id, product_id, product_name, qty, price, amount)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, refundID, item.OrderItemID, sql.NullString{String: item.ProductID, Valid: item.ProductID != ""}, item.ProductName, item.Qty, item.Price, item.Amount); err != nil {
+				return fmt.Errorf("gagal menyimpan item refund: %w", err)
+			}
+			if input.Restock && item.ProductID != "" {
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE products
+					SET stock = stock + ?, updated_at = CURRENT_TIMESTAMP
+					WHERE id = ?
+				`, item.Qty, item.ProductID); err != nil {
+					return fmt.Errorf("gagal mengembalikan stok: %w", err)
+				}
+			}
+		}
+		return DeductCustomerSpend(ctx, tx, order.ID, amount)
+	})
+	if err != nil {
+		return "", err
+	}
+	return refundID, nil
+}
+
+// resolveRefundOriginalPayment memastikan pembayaran asal milik order ini.
+// Pembayaran bisa berupa payments (split bill) atau transactions (bayar penuh);
+// jika tidak dipilih, dipakai pembayaran terakhir.
+func resolveRefundOriginalPayment(ctx context.Context, tx *sql.Tx, orderID, paymentID string) (string, error) {
+	query := `
+		SELECT id
+		FROM (
+			SELECT id, created_at
+			FROM payments
+			WHERE order_id = ? AND amount > 0
+			UNION ALL
+			SELECT id, transaction_date AS created_at
+			FROM transactions
+			WHERE order_id = ? AND cancelled_at IS NULL
+		) p
+	`
+	args := []interface{}{orderID, orderID}
+	if paymentID != "" {
+		query += " WHERE id = ?"
+		args = append(args, paymentID)
+	}
+	query += " ORDER BY created_at DESC LIMIT 1"
+
+	var id string
+	err := tx.QueryRowContext(ctx, query, args...).Scan(&id)
+	if err == sql.ErrNoRows {
+		if paymentID != "" {
+			return "", fmt.Errorf("%w: pembayaran asal tidak ditemukan", ErrInvalidRefund)
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("gagal mengambil pembayaran asal: %w", err)
+	}
+	return id, nil
+}
+
+// buildRefundItems memvalidasi qty terhadap sisa qty yang belum di-refund dan
+// menghitung nominal refund per item secara proporsional terhadap total order.
+func buildRefundItems(ctx context.Context, tx *sql.Tx, order db.Order, inputs []RefundItemInput) ([]RefundItem, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+	qtyByID := make(map[string]int64, len(inputs))
+	for _, input := range inputs {
+		if input.ItemID == "" || input.Qty <= 0 {
+			return nil, fmt.Errorf("%w: item_id dan qty wajib diisi", ErrInvalidRefund)
+		}
+		qtyByID[input.ItemID] += input.Qty
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			oi.id,
+			COALESCE(oi.product_id, ''),
+			oi.product_name,
+			oi.qty,
+			oi.price,
+			COALESCE((SELECT SUM(ri.qty) FROM refund_items ri WHERE ri.order_item_id = oi.id), 0)
+		FROM order_items oi
+		WHERE oi.order_id = ?
+	`, order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil item order: %w", err)
+	}
+	defer rows.Close()
+
+	subtotal := 0.0
+	items := []RefundItem{}
+	for rows.Next() {
+		var item RefundItem
+		var orderedQty, refundedQty int64
+		if err := rows.Scan(&item.OrderItemID, &item.ProductID, &item.ProductName, &orderedQty, &item.Price, &refundedQty); err != nil {
+			return nil, fmt.Errorf("gagal scan item order: %w", err)
+		}
+		subtotal += item.Price * float64(orderedQty)
+		qty, ok := qtyByID[item.OrderItemID]
+		if !ok {
+			continue
+		}
+		if qty > orderedQty-refundedQty {
+			return nil, fmt.Errorf("%w: qty refund %s melebihi sisa qty", ErrInvalidRefund, item.ProductName)
+		}
+		item.Qty = qty
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) != len(qtyByID) {
+		return nil, ErrOrderItemNotFound
+	}
+
+	ratio := 1.0
+	if subtotal > 0 {
+		ratio = order.TotalAmount / subtotal
+	}
+	for i := range items {
+		items[i].Amount = math.Round(items[i].Price * float64(items[i].Qty) * ratio)
+	}
+	return items, nil
+}
+
+func (r *orderRepository) GetOrderRefunds(ctx context.Context, orderID string) ([]Refund, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			rf.id,
+			rf.order_id,
+			rf.payment_id,
+			COALESCE(rf.original_payment_id, ''),
+			rf.refund_type,
+			rf.refund_method,
+			rf.amount,
+			rf.reason,
+			rf.restock,
+			rf.approved_by,
+			COALESCE(ua.full_name, ''),
+			rf.created_by,
+			COALESCE(uc.full_name, ''),
+			rf.created_at
+		FROM refunds rf
+		LEFT JOIN users ua ON rf.approved_by = ua.id
+		LEFT JOIN users uc ON rf.created_by = uc.id
+		WHERE rf.order_id = ?
+		ORDER BY rf.created_at ASC
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil refund: %w", err)
+	}
+	defer rows.Close()
+
+	refunds := []Refund{}
+	indexByID := map[string]int{}
+	for rows.Next() {
+		var refund Refund
+		var restock int64
+		if err := rows.Scan(
+			&refund.ID,
+			&refund.OrderID,
+			&refund.PaymentID,
+			&refund.OriginalPaymentID,
+			&refund.RefundType,
+			&refund.RefundMethod,
+			&refund.Amount,
+			&refund.Reason,
+			&restock,
+			&refund.ApprovedBy,
+			&refund.ApprovedByName,
+			&refund.CreatedBy,
+			&refund.CreatedByName,
+			&refund.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("gagal scan refund: %w", err)
+		}
+		refund.Restock = restock == 1
+		refund.Items = []RefundItem{}
+		indexByID[refund.ID] = len(refunds)
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(refunds) == 0 {
+		return refunds, nil
+	}
+
+	itemRows, err := r.db.QueryContext(ctx, `
+		SELECT ri.refund_id, ri.order_item_id, COALESCE(ri.product_id, ''), ri.product_name, ri.qty, ri.price, ri.amount
+		FROM refund_items ri
+		INNER JOIN refunds rf ON ri.refund_id = rf.id
+		WHERE rf.order_id = ?
+		ORDER BY ri.id ASC
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil item refund: %w", err)
+	}
+	defer itemRows.Close()
+	for itemRows.Next() {
+		var refundID string
+		var item RefundItem
+		if err := itemRows.Scan(&refundID, &item.OrderItemID, &item.ProductID, &item.ProductName, &item.Qty, &item.Price, &item.Amount); err != nil {
+			return nil, fmt.Errorf("gagal scan item refund: %w", err)
+		}
+		if idx, ok := indexByID[refundID]; ok {
+			refunds[idx].Items = append(refunds[idx].Items, item)
+		}
+	}
+	if err := itemRows.Err(); err != nil {
+		return nil, err
+	}
+	return refunds, nil
+}
+
+// GetRefundSummary merekap refund per metode berdasarkan waktu refund (bukan waktu order)
+func (r *orderRepository) GetRefundSummary(ctx context.Context, startDate, endDate time.Time) (float64, int64, []RefundSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT refund_method, COUNT(*), COALESCE(SUM(amount), 0)
+		FROM refunds
+		WHERE created_at BETWEEN ? AND ?
+		GROUP BY refund_method
+		ORDER BY refund_method
+	`, startDate, endDate)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("gagal mengambil ringkasan refund: %w", err)
+	}
+	defer rows.Close()
+
+	total := 0.0
+	var count int64
+	summaries := []RefundSummary{}
+	for rows.Next() {
+		var summary RefundSummary
+		if err := rows.Scan(&summary.RefundMethod, &summary.Count, &summary.Total); err != nil {
+			return 0, 0, nil, fmt.Errorf("gagal scan ringkasan refund: %w", err)
+		}
+		total += summary.Total
+		count += summary.Count
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, nil, err
+	}
+	return total, count, summaries, nil
+}
+
 func (r *orderRepository) ListVoidedOrders(ctx context.Context, limit, offset int64) ([]VoidedOrderHistory, error) {
 	rows, err := r.db.QueryContext(ctx, `
 		SELECT
@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"backend/pkg/utils"
+)
+
+// TestSplitBillPayment memastikan pembayaran split, transaksi, gift card dan check
+// tersimpan bersama atau tidak sama sekali
+func TestSplitBillPayment(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name          string
+		amount        float64
+		method        string
+		giftBalance   float64
+		checkStatus   string
+		wantErr       error
+		wantStatus    string
+		wantPaid      float64
+		wantTables    []string
+		wantCheckPaid bool
+	}{
+		{name: "pembayaran nominal parsial", amount: 50000, method: "cash", wantStatus: "partial", wantPaid: 50000},
+		{name: "pelunasan menandai meja paid", amount: 120000, method: "cash", wantStatus: "paid", wantPaid: 120000, wantTables: []string{"A1"}},
+		{name: "gift card dipotong bersama pembayaran", amount: 50000, method: GiftCardPaymentMethod, giftBalance: 80000, wantStatus: "partial", wantPaid: 50000},
+		{name: "saldo gift card kurang membatalkan pembayaran", amount: 50000, method: GiftCardPaymentMethod, giftBalance: 10000, wantErr: ErrGiftCardInsufficient},
+		{name: "check dilunasi bersama transaksi", amount: 40000, method: "cash", checkStatus: "open", wantStatus: "partial", wantPaid: 40000, wantCheckPaid: true},
+		{name: "check yang sudah dibayar ditolak", amount: 40000, method: "cash", checkStatus: "paid", wantErr: ErrOrderCheckPaid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			adminID := testAdminID(t, conn)
+			shiftID := insertTestShift(t, conn, defaultTestTerminalID(t, conn), adminID, "open")
+			orderID, _ := taxTestOrder(t, conn)
+			mustExec(t, conn, `INSERT INTO tables (id, table_number, status) VALUES (?, 'A1', 'ordered')`, utils.GenerateULID())
+
+			input := SplitPayment{
+				OrderID:       orderID,
+				PaymentMethod: tt.method,
+				Amount:        tt.amount,
+				CashierID:     adminID,
+				ShiftID:       shiftID,
+			}
+			giftCardID := ""
+			if tt.method == GiftCardPaymentMethod {
+				giftCardID = utils.GenerateULID()
+				input.GiftCardCode = NormalizeGiftCardCode("GC-" + giftCardID)
+				mustExec(t, conn, `INSERT INTO gift_cards (id, code, balance, created_by) VALUES (?, ?, ?, ?)`,
+					giftCardID, input.GiftCardCode, tt.giftBalance, adminID)
+			}
+			if tt.checkStatus != "" {
+				input.CheckID = utils.GenerateULID()
+				mustExec(t, conn, `
+					INSERT INTO order_checks (id, order_id, check_number, name, split_mode, amount, status, created_by)
+					VALUES (?, ?, 1, 'Check 1', 'amount', ?, ?, ?)
+				`, input.CheckID, orderID, tt.amount, tt.checkStatus, adminID)
+			}
+
+			result, err := NewOrderRepository(conn).SplitBillPayment(ctx, input)
+
+			var payments, transactions int
+			var paidAmount float64
+			if err := conn.QueryRow(`SELECT COUNT(*) FROM payments WHERE order_id = ?`, orderID).Scan(&payments); err != nil {
+				t.Fatalf("baca payments: %v", err)
+			}
+			if err := conn.QueryRow(`SELECT COUNT(*) FROM transactions WHERE order_id = ? AND shift_id = ?`, orderID, shiftID).Scan(&transactions); err != nil {
+				t.Fatalf("baca transaksi: %v", err)
+			}
+			if err := conn.QueryRow(`SELECT paid_amount FROM orders WHERE id = ?`, orderID).Scan(&paidAmount); err != nil {
+				t.Fatalf("baca order: %v", err)
+			}
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				if payments != 0 || transactions != 0 || paidAmount != 0 {
+					t.Errorf("pembayaran gagal tetap tersimpan: payments=%d transaksi=%d paid_amount=%v", payments, transactions, paidAmount)
+				}
+				if giftCardID != "" {
+					var balance float64
+					if err := conn.QueryRow(`SELECT balance FROM gift_cards WHERE id = ?`, giftCardID).Scan(&balance); err != nil {
+						t.Fatalf("baca gift card: %v", err)
+					}
+					if balance != tt.giftBalance {
+						t.Errorf("saldo gift card = %v, want %v", balance, tt.giftBalance)
+					}
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitBillPayment: %v", err)
+			}
+
+			if result.PaymentStatus != tt.wantStatus {
+				t.Errorf("payment_status = %q, want %q", result.PaymentStatus, tt.wantStatus)
+			}
+			if payments != 1 || transactions != 1 {
+				t.Errorf("payments=%d transaksi=%d, want 1 dan 1", payments, transactions)
+			}
+			if paidAmount != tt.wantPaid {
+				t.Errorf("paid_amount = %v, want %v", paidAmount, tt.wantPaid)
+			}
+			if len(result.TableNumbers) != len(tt.wantTables) {
+				t.Errorf("meja = %v, want %v", result.TableNumbers, tt.wantTables)
+			}
+
+			if giftCardID != "" {
+				var balance float64
+				var linked int
+				if err := conn.QueryRow(`SELECT balance FROM gift_cards WHERE id = ?`, giftCardID).Scan(&balance); err != nil {
+					t.Fatalf("baca gift card: %v", err)
+				}
+				if balance != tt.giftBalance-tt.amount {
+					t.Errorf("saldo gift card = %v, want %v", balance, tt.giftBalance-tt.amount)
+				}
+				if err := conn.QueryRow(`SELECT COUNT(*) FROM gift_card_ledger WHERE transaction_id = ?`, result.Transaction.ID).Scan(&linked); err != nil {
+					t.Fatalf("baca mutasi gift card: %v", err)
+				}
+				if linked != 1 {
+					t.Errorf("mutasi gift card tertaut = %d, want 1", linked)
+				}
+			}
+
+			if tt.wantCheckPaid {
+				var status string
+				var transactionID string
+				if err := conn.QueryRow(`SELECT status, transaction_id FROM order_checks WHERE id = ?`, input.CheckID).Scan(&status, &transactionID); err != nil {
+					t.Fatalf("baca check: %v", err)
+				}
+				if status != "paid" || transactionID != result.Transaction.ID {
+					t.Errorf("check status=%q transaction_id=%q, want paid dan %q", status, transactionID, result.Transaction.ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	OrderTypeDineIn   = "dine_in"
+	OrderTypeTakeaway = "takeaway"
+	OrderTypeDelivery = "delivery"
+	OrderTypePickup   = "pickup"
+
+	PackagingFeePerItem  = "per_item"
+	PackagingFeePerOrder = "per_order"
+)
+
+// OrderTypeSetting adalah aturan satu tipe order: aktif/tidak, biaya kemasan,
+// prefix nomor antrian dan aturan biaya tambahan khusus tipe tersebut
+type OrderTypeSetting struct {
+	OrderType        string           `json:"order_type"`
+	IsActive         bool             `json:"is_active"`
+	PackagingFee     float64          `json:"packaging_fee"`
+	PackagingFeeMode string           `json:"packaging_fee_mode"`
+	QueuePrefix      string           `json:"queue_prefix"`
+	ChargeRules      []AreaChargeRule `json:"charge_rules"`
+	UpdatedAt        time.Time        `json:"updated_at"`
+}
+
+type OrderTypeSettingInput struct {
+	IsActive         *bool    `json:"is_active"`
+	PackagingFee     *float64 `json:"packaging_fee"`
+	PackagingFeeMode *string  `json:"packaging_fee_mode"`
+	QueuePrefix      *string  `json:"queue_prefix"`
+}
+
+// OrderChannel adalah data tipe order yang disimpan di luar query sqlc orders
+type OrderChannel struct {
+	OrderType       string     `json:"order_type"`
+	QueueNumber     *int64     `json:"queue_number"`
+	QueueLabel      string     `json:"queue_label"`
+	PickupAt        *time.Time `json:"pickup_at"`
+	DeliveryAddress string     `json:"delivery_address"`
+}
+
+// QueueOrder adalah order tanpa meja pada papan antrian hari ini
+type QueueOrder struct {
+	OrderID         string     `json:"order_id"`
+	OrderType       string     `json:"order_type"`
+	QueueNumber     int64      `json:"queue_number"`
+	QueueLabel      string     `json:"queue_label"`
+	CustomerName    string     `json:"customer_name"`
+	CustomerPhone   string     `json:"customer_phone"`
+	PickupAt        *time.Time `json:"pickup_at"`
+	DeliveryAddress string     `json:"delivery_address"`
+	OrderStatus     string     `json:"order_status"`
+	PaymentStatus   string     `json:"payment_status"`
+	TotalAmount     float64    `json:"total_amount"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+var (
+	ErrOrderTypeNotFound = errors.New("tipe order tidak ditemukan")
+	ErrInvalidOrderType  = errors.New("data tipe order tidak valid")
+)
+
+type OrderTypeRepository interface {
+	List(ctx context.Context) ([]OrderTypeSetting, error)
+	Get(ctx context.Context, orderType string) (*OrderTypeSetting, error)
+	Update(ctx context.Context, orderType string, input OrderTypeSettingInput) (*OrderTypeSetting, error)
+	SetChargeRules(ctx context.Context, orderType string, rules []AreaChargeRule) (*OrderTypeSetting, error)
+	ListQueue(ctx context.Context, orderType string, activeOnly bool) ([]QueueOrder, error)
+}
@@ -0,0 +1,340 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type orderTypeRepository struct {
+	db *sql.DB
+}
+
+func NewOrderTypeRepository(dbConn *sql.DB) OrderTypeRepository {
+	return &orderTypeRepository{db: dbConn}
+}
+
+func isOrderType(orderType string) bool {
+	switch orderType {
+	case OrderTypeDineIn, OrderTypeTakeaway, OrderTypeDelivery, OrderTypePickup:
+		return true
+	}
+	return false
+}
+
+// QueueLabel menyusun label antrian yang dicetak besar di struk, mis. TA-007
+func QueueLabel(prefix string, number int64) string {
+	if prefix == "" {
+		return fmt.Sprintf("%03d", number)
+	}
+	return fmt.Sprintf("%s-%03d", prefix, number)
+}
+
+func getOrderTypeSetting(ctx context.Context, q db.DBTX, orderType string) (*OrderTypeSetting, error) {
+	var setting OrderTypeSetting
+	err := q.QueryRowContext(ctx, `
+		SELECT order_type, is_active, packaging_fee, packaging_fee_mode, queue_prefix, updated_at
+		FROM order_type_settings
+		WHERE order_type = ?
+	`, orderType).Scan(&setting.OrderType, &setting.IsActive, &setting.PackagingFee, &setting.PackagingFeeMode,
+		&setting.QueuePrefix, &setting.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrOrderTypeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if setting.ChargeRules, err = loadChargeRules(ctx, q, "order_type_charge_rules", "order_type", orderType); err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+func (r *orderTypeRepository) List(ctx context.Context) ([]OrderTypeSetting, error) {
+	settings := []OrderTypeSetting{}
+	for _, orderType := range []string{OrderTypeDineIn, OrderTypeTakeaway, OrderTypeDelivery, OrderTypePickup} {
+		setting, err := getOrderTypeSetting(ctx, r.db, orderType)
+		if err == ErrOrderTypeNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		settings = append(settings, *setting)
+	}
+	return settings, nil
+}
+
+func (r *orderTypeRepository) Get(ctx context.Context, orderType string) (*OrderTypeSetting, error) {
+	return getOrderTypeSetting(ctx, r.db, orderType)
+}
+
+func (r *orderTypeRepository) Update(ctx context.Context, orderType string, input OrderTypeSettingInput) (*OrderTypeSetting, error) {
+	current, err := getOrderTypeSetting(ctx, r.db, orderType)
+	if err != nil {
+		return nil, err
+	}
+	if input.IsActive != nil {
+		if orderType == OrderTypeDineIn && !*input.IsActive {
+			return nil, fmt.Errorf("%w: dine-in tidak bisa dinonaktifkan", ErrInvalidOrderType)
+		}
+		current.IsActive = *input.IsActive
+	}
+	if input.PackagingFee != nil {
+		if *input.PackagingFee < 0 {
+			return nil, fmt.Errorf("%w: packaging_fee tidak boleh negatif", ErrInvalidOrderType)
+		}
+		current.PackagingFee = *input.PackagingFee
+	}
+	if input.PackagingFeeMode != nil {
+		if *input.PackagingFeeMode != PackagingFeePerItem && *input.PackagingFeeMode != PackagingFeePerOrder {
+			return nil, fmt.Errorf("%w: packaging_fee_mode harus per_item atau per_order", ErrInvalidOrderType)
+		}
+		current.PackagingFeeMode = *input.PackagingFeeMode
+	}
+	if input.QueuePrefix != nil {
+		prefix := strings.ToUpper(strings.TrimSpace(*input.QueuePrefix))
+		if len(prefix) > 4 || strings.ContainsAny(prefix, "- ") {
+			return nil, fmt.Errorf("%w: queue_prefix maksimal 4 karakter tanpa spasi atau tanda hubung", ErrInvalidOrderType)
+		}
+		current.QueuePrefix = prefix
+	}
+
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE order_type_settings
+		SET is_active = ?, packaging_fee = ?, packaging_fee_mode = ?, queue_prefix = ?, updated_at = ?
+		WHERE order_type = ?
+	`, current.IsActive, current.PackagingFee, current.PackagingFeeMode, current.QueuePrefix, time.Now().UTC(),
+		orderType); err != nil {
+		return nil, err
+	}
+	return getOrderTypeSetting(ctx, r.db, orderType)
+}
+
+// SetChargeRules mengganti seluruh aturan biaya tambahan tipe order
+func (r *orderTypeRepository) SetChargeRules(ctx context.Context, orderType string, rules []AreaChargeRule) (*OrderTypeSetting, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if _, err := getOrderTypeSetting(ctx, tx, orderType); err != nil {
+		return nil, err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_type_charge_rules WHERE order_type = ?", orderType); err != nil {
+		return nil, err
+	}
+	seen := map[int64]bool{}
+	for _, rule := range rules {
+		if err := validateChargeRule(ctx, tx, &rule, seen, ErrInvalidOrderType); err != nil {
+			return nil, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO order_type_charge_rules (order_type, charge_id, is_exempt, charge_type, value)
+			VALUES (?, ?, ?, ?, ?)
+		`, orderType, rule.ChargeID, rule.IsExempt, rule.ChargeType, rule.Value); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE order_type_settings SET updated_at = ? WHERE order_type = ?", time.Now().UTC(), orderType); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return getOrderTypeSetting(ctx, r.db, orderType)
+}
+
+// ListQueue mengembalikan order tanpa meja hari ini urut nomor antrian.
+// activeOnly = hanya order yang belum diserahkan atau belum lunas.
+func (r *orderTypeRepository) ListQueue(ctx context.Context, orderType string, activeOnly bool) ([]QueueOrder, error) {
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).UTC()
+
+	query := `
+		SELECT o.id, o.order_type, o.queue_number, COALESCE(s.queue_prefix, ''), COALESCE(o.customer_name, ''),
+		       COALESCE(o.customer_phone, ''), o.pickup_at, COALESCE(o.delivery_address, ''), o.order_status,
+		       o.payment_status, o.total_amount, o.created_at
+		FROM orders o
+		LEFT JOIN order_type_settings s ON s.order_type = o.order_type
+		WHERE o.order_type != 'dine_in'
+		  AND o.queue_number IS NOT NULL
+		  AND o.voided_at IS NULL
+		  AND o.is_merged = 0
+		  AND o.created_at >= ?
+	`
+	args := []interface{}{dayStart}
+	if orderType != "" {
+		query += " AND o.order_type = ?"
+		args = append(args, orderType)
+	}
+	if activeOnly {
+		query += " AND (o.order_status != 'served' OR o.payment_status != 'paid')"
+	}
+	query += " ORDER BY o.queue_number"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []QueueOrder{}
+	for rows.Next() {
+		var order QueueOrder
+		var prefix string
+		var pickupAt sql.NullTime
+		if err := rows.Scan(&order.OrderID, &order.OrderType, &order.QueueNumber, &prefix, &order.CustomerName,
+			&order.CustomerPhone, &pickupAt, &order.DeliveryAddress, &order.OrderStatus, &order.PaymentStatus,
+			&order.TotalAmount, &order.CreatedAt); err != nil {
+			return nil, err
+		}
+		if pickupAt.Valid {
+			order.PickupAt = &pickupAt.Time
+		}
+		order.QueueLabel = QueueLabel(prefix, order.QueueNumber)
+		orders = append(orders, order)
+	}
+	return orders, rows.Err()
+}
+
+// prepareOrderChannel memvalidasi field wajib per tipe order dan memberi nomor antrian
+// untuk order tanpa meja. table_number order tanpa meja diisi label antrian agar nomor
+// pesanan dan tampilan order tetap unik tanpa membuat meja palsu.
+func prepareOrderChannel(ctx context.Context, q db.DBTX, input *OrderInput) (*OrderChannel, error) {
+	if input.OrderType == "" {
+		input.OrderType = OrderTypeDineIn
+	}
+	if !isOrderType(input.OrderType) {
+		return nil, fmt.Errorf("%w: order_type harus dine_in, takeaway, delivery atau pickup", ErrInvalidOrderType)
+	}
+	setting, err := getOrderTypeSetting(ctx, q, input.OrderType)
+	if err != nil {
+		return nil, err
+	}
+	if !setting.IsActive {
+		return nil, fmt.Errorf("%w: tipe order %s tidak aktif", ErrInvalidOrderType, input.OrderType)
+	}
+
+	channel := &OrderChannel{OrderType: input.OrderType}
+	input.DeliveryAddress = strings.TrimSpace(input.DeliveryAddress)
+	switch input.OrderType {
+	case OrderTypeDineIn:
+		if strings.TrimSpace(input.TableNumber) == "" {
+			return nil, fmt.Errorf("%w: table_number wajib diisi untuk dine-in", ErrInvalidOrderType)
+		}
+		return channel, nil
+	case OrderTypeTakeaway, OrderTypePickup:
+		if input.CustomerName == "" || input.CustomerPhone == "" {
+			return nil, fmt.Errorf("%w: nama dan nomor HP pelanggan wajib diisi untuk %s", ErrInvalidOrderType, input.OrderType)
+		}
+		if input.PickupAt == nil {
+			return nil, fmt.Errorf("%w: pickup_at wajib diisi untuk %s", ErrInvalidOrderType, input.OrderType)
+		}
+	case OrderTypeDelivery:
+		if input.CustomerName == "" || input.CustomerPhone == "" {
+			return nil, fmt.Errorf("%w: nama dan nomor HP pelanggan wajib diisi untuk delivery", ErrInvalidOrderType)
+		}
+		if input.DeliveryAddress == "" {
+			return nil, fmt.Errorf("%w: delivery_address wajib diisi untuk delivery", ErrInvalidOrderType)
+		}
+	}
+	if input.Pax <= 0 {
+		input.Pax = 1
+	}
+
+	queueDate := time.Now().Format("2006-01-02")
+	var number int64
+	if err := q.QueryRowContext(ctx, `
+		INSERT INTO order_queue_counters (queue_date, last_number)
+		VALUES (?, 1)
+		ON CONFLICT(queue_date) DO UPDATE SET last_number = last_number + 1
+		RETURNING last_number
+	`, queueDate).Scan(&number); err != nil {
+		return nil, err
+	}
+	channel.QueueNumber = &number
+	channel.QueueLabel = QueueLabel(setting.QueuePrefix, number)
+	channel.PickupAt = input.PickupAt
+	channel.DeliveryAddress = input.DeliveryAddress
+	input.TableNumber = channel.QueueLabel
+	return channel, nil
+}
+
+func saveOrderChannel(ctx context.Context, q db.DBTX, orderID string, channel *OrderChannel) error {
+	var pickupAt interface{}
+	if channel.PickupAt != nil {
+		pickupAt = channel.PickupAt.UTC()
+	}
+	_, err := q.ExecContext(ctx, `
+		UPDATE orders
+		SET order_type = ?, queue_number = ?, pickup_at = ?, delivery_address = ?
+		WHERE id = ?
+	`, channel.OrderType, channel.QueueNumber, pickupAt, nullableID(channel.DeliveryAddress), orderID)
+	return err
+}
+
+// GetOrderChannel mengambil tipe order, nomor antrian dan data pengambilan/pengantaran order
+func GetOrderChannel(ctx context.Context, q db.DBTX, orderID string) (*OrderChannel, error) {
+	var channel OrderChannel
+	var queueNumber sql.NullInt64
+	var pickupAt sql.NullTime
+	var prefix string
+	err := q.QueryRowContext(ctx, `
+		SELECT o.order_type, o.queue_number, o.pickup_at, COALESCE(o.delivery_address, ''), COALESCE(s.queue_prefix, '')
+		FROM orders o
+		LEFT JOIN order_type_settings s ON s.order_type = o.order_type
+		WHERE o.id = ?
+	`, orderID).Scan(&channel.OrderType, &queueNumber, &pickupAt, &channel.DeliveryAddress, &prefix)
+	if err != nil {
+		return nil, err
+	}
+	if queueNumber.Valid {
+		channel.QueueNumber = &queueNumber.Int64
+		channel.QueueLabel = QueueLabel(prefix, queueNumber.Int64)
+	}
+	if pickupAt.Valid {
+		channel.PickupAt = &pickupAt.Time
+	}
+	return &channel, nil
+}
+
+// orderTypeCharges adalah aturan biaya tipe order yang dipakai saat total order dihitung ulang
+type orderTypeCharges struct {
+	rules        map[int64]AreaChargeRule
+	packagingFee float64
+}
+
+func loadOrderTypeCharges(ctx context.Context, q db.DBTX, orderID string, itemQty int64) (*orderTypeCharges, error) {
+	var orderType string
+	err := q.QueryRowContext(ctx, "SELECT order_type FROM orders WHERE id = ?", orderID).Scan(&orderType)
+	if err == sql.ErrNoRows {
+		return &orderTypeCharges{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	setting, err := getOrderTypeSetting(ctx, q, orderType)
+	if err == ErrOrderTypeNotFound {
+		return &orderTypeCharges{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	charges := &orderTypeCharges{rules: make(map[int64]AreaChargeRule, len(setting.ChargeRules))}
+	for _, rule := range setting.ChargeRules {
+		charges.rules[rule.ChargeID] = rule
+	}
+	if setting.PackagingFee > 0 && itemQty > 0 {
+		charges.packagingFee = setting.PackagingFee
+		if setting.PackagingFeeMode == PackagingFeePerItem {
+			charges.packagingFee = setting.PackagingFee * float64(itemQty)
+		}
+	}
+	return charges, nil
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PaymentIntent adalah permintaan bayar ke payment provider (mis. QRIS dinamis)
+// yang menunggu konfirmasi. Order dilunasi otomatis saat intent berstatus paid.
+type PaymentIntent struct {
+	ID            string     `json:"id"`
+	OrderID       string     `json:"order_id"`
+	Provider      string     `json:"provider"`
+	ProviderRef   string     `json:"provider_ref"`
+	PaymentMethod string     `json:"payment_method"`
+	Amount        float64    `json:"amount"`
+	QRString      string     `json:"qr_string"`
+	Status        string     `json:"status"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	PaidAt        *time.Time `json:"paid_at"`
+	CreatedBy     string     `json:"created_by"`
+	ShiftID       string     `json:"shift_id"`
+	// SettledAt terisi setelah pembayaran intent dicatat ke order (atau ditandai refund)
+	SettledAt *time.Time `json:"settled_at"`
+	// RefundRequired menandai intent paid untuk order yang sudah lunas; dana dikembalikan manual
+	RefundRequired bool      `json:"refund_required"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+var (
+	ErrPaymentIntentNotFound = errors.New("intent pembayaran tidak ditemukan")
+	ErrPaymentIntentSettled  = errors.New("intent pembayaran sudah dicatat ke order")
+)
+
+type PaymentIntentRepository interface {
+	Create(ctx context.Context, intent *PaymentIntent) error
+	GetByID(ctx context.Context, id string) (*PaymentIntent, error)
+	GetByProviderRef(ctx context.Context, provider, providerRef string) (*PaymentIntent, error)
+	GetActiveByOrder(ctx context.Context, orderID string) (*PaymentIntent, error)
+	ListByOrder(ctx context.Context, orderID string) ([]PaymentIntent, error)
+	// UpdateStatus hanya mengubah intent yang masih pending; mengembalikan false
+	// jika intent sudah final (mis. callback ganda)
+	UpdateStatus(ctx context.Context, id string, status string) (bool, error)
+	// MarkRefundRequired menandai intent paid yang belum dicatat ke order untuk dikembalikan
+	MarkRefundRequired(ctx context.Context, id string) (bool, error)
+}
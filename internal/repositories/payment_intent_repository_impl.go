@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type paymentIntentRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentIntentRepository(dbConn *sql.DB) PaymentIntentRepository {
+	return &paymentIntentRepository{db: dbConn}
+}
+
+const paymentIntentColumns = `
+	id, order_id, provider, COALESCE(provider_ref, ''), payment_method, amount,
+	COALESCE(qr_string, ''), status, expires_at, paid_at, COALESCE(created_by, ''),
+	COALESCE(shift_id, ''), settled_at, refund_required, created_at, updated_at
+`
+
+func scanPaymentIntent(scanner interface{ Scan(dest ...any) error }) (*PaymentIntent, error) {
+	var intent PaymentIntent
+	var expiresAt, paidAt, settledAt sql.NullTime
+	var refundRequired int64
+	if err := scanner.Scan(
+		&intent.ID, &intent.OrderID, &intent.Provider, &intent.ProviderRef, &intent.PaymentMethod, &intent.Amount,
+		&intent.QRString, &intent.Status, &expiresAt, &paidAt, &intent.CreatedBy,
+		&intent.ShiftID, &settledAt, &refundRequired, &intent.CreatedAt, &intent.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		intent.ExpiresAt = &expiresAt.Time
+	}
+	if paidAt.Valid {
+		intent.PaidAt = &paidAt.Time
+	}
+	if settledAt.Valid {
+		intent.SettledAt = &settledAt.Time
+	}
+	intent.RefundRequired = refundRequired == 1
+	return &intent, nil
+}
+
+func (r *paymentIntentRepository) Create(ctx context.Context, intent *PaymentIntent) error {
+	if intent.ID == "" {
+		intent.ID = utils.GenerateULID()
+	}
+	if intent.Status == "" {
+		intent.Status = "pending"
+	}
+	var expiresAt interface{}
+	if intent.ExpiresAt != nil {
+		expiresAt = intent.ExpiresAt.UTC()
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO payment_intents (
+			id, order_id, provider, provider_ref, payment_method, amount, qr_string, status,
+			expires_at, created_by, shift_id, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, intent.ID, intent.OrderID, intent.Provider,
+		sql.NullString{String: intent.ProviderRef, Valid: intent.ProviderRef != ""},
+		intent.PaymentMethod, intent.Amount,
+		sql.NullString{String: intent.QRString, Valid: intent.QRString != ""},
+		intent.Status, expiresAt,
+		sql.NullString{String: intent.CreatedBy, Valid: intent.CreatedBy != ""},
+		sql.NullString{String: intent.ShiftID, Valid: intent.ShiftID != ""},
+	)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan intent pembayaran: %w", err)
+	}
+	return nil
+}
+
+func (r *paymentIntentRepository) GetByID(ctx context.Context, id string) (*PaymentIntent, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+paymentIntentColumns+" FROM payment_intents WHERE id = ?", id)
+	intent, err := scanPaymentIntent(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentIntentNotFound
+	}
+	return intent, err
+}
+
+func (r *paymentIntentRepository) GetByProviderRef(ctx context.Context, provider, providerRef string) (*PaymentIntent, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+paymentIntentColumns+" FROM payment_intents WHERE provider = ? AND provider_ref = ?", provider, providerRef)
+	intent, err := scanPaymentIntent(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentIntentNotFound
+	}
+	return intent, err
+}
+
+// GetActiveByOrder mengambil intent pending terbaru yang belum kedaluwarsa
+func (r *paymentIntentRepository) GetActiveByOrder(ctx context.Context, orderID string) (*PaymentIntent, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+paymentIntentColumns+`
+		FROM payment_intents
+		WHERE order_id = ? AND status = 'pending'
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, orderID)
+	intent, err := scanPaymentIntent(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentIntentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if intent.ExpiresAt != nil && time.Now().After(*intent.ExpiresAt) {
+		return nil, ErrPaymentIntentNotFound
+	}
+	return intent, nil
+}
+
+func (r *paymentIntentRepository) ListByOrder(ctx context.Context, orderID string) ([]PaymentIntent, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+paymentIntentColumns+`
+		FROM payment_intents
+		WHERE order_id = ?
+		ORDER BY created_at DESC, id DESC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	intents := []PaymentIntent{}
+	for rows.Next() {
+		intent, err := scanPaymentIntent(rows)
+		if err != nil {
+			return nil, err
+		}
+		intents = append(intents, *intent)
+	}
+	return intents, rows.Err()
+}
+
+func (r *paymentIntentRepository) UpdateStatus(ctx context.Context, id string, status string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE payment_intents
+		SET status = ?,
+		    paid_at = CASE WHEN ? = 'paid' THEN CURRENT_TIMESTAMP ELSE paid_at END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'pending'
+	`, status, status, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (r *paymentIntentRepository) MarkRefundRequired(ctx context.Context, id string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE payment_intents
+		SET refund_required = 1, settled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'paid' AND settled_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// settlePaymentIntent menandai intent paid sudah dicatat ke order. Dipanggil di dalam
+// transaksi pelunasan sehingga intent hanya tercatat jika order ikut terlunasi;
+// ErrPaymentIntentSettled jika intent sudah dicatat oleh callback lain.
+func settlePaymentIntent(ctx context.Context, q db.DBTX, id string) error {
+	result, err := q.ExecContext(ctx, `
+		UPDATE payment_intents
+		SET settled_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND status = 'paid' AND settled_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrPaymentIntentSettled
+	}
+	return nil
+}
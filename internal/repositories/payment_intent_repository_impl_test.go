@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestPaymentIntentSettlement memastikan intent paid hanya ditandai settled bersama
+// pelunasan order, dan intent untuk order yang sudah lunas ditandai refund
+func TestPaymentIntentSettlement(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		split          bool
+		depositAmount  float64
+		orderPaid      bool
+		wantErr        error
+		wantSettled    bool
+		wantRefundFlag bool
+	}{
+		{name: "pelunasan penuh menandai intent settled", wantSettled: true},
+		{name: "pembayaran parsial menandai intent settled", split: true, wantSettled: true},
+		{name: "pelunasan gagal membiarkan intent belum settled", depositAmount: 5000, wantErr: ErrDepositChanged, wantRefundFlag: true},
+		{name: "order sudah lunas ditolak lalu ditandai refund", orderPaid: true, wantErr: ErrOrderAlreadyPaid, wantRefundFlag: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			adminID := testAdminID(t, conn)
+			orderID, _ := taxTestOrder(t, conn)
+			if tt.orderPaid {
+				mustExec(t, conn, `UPDATE orders SET paid_amount = total_amount, payment_status = 'paid' WHERE id = ?`, orderID)
+			}
+
+			intents := NewPaymentIntentRepository(conn)
+			intent := &PaymentIntent{OrderID: orderID, Provider: "sandbox", ProviderRef: "ref-" + orderID, PaymentMethod: "qris", Amount: 120000, CreatedBy: adminID}
+			if err := intents.Create(ctx, intent); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if _, err := intents.UpdateStatus(ctx, intent.ID, "paid"); err != nil {
+				t.Fatalf("UpdateStatus: %v", err)
+			}
+
+			orders := NewOrderRepository(conn)
+			var err error
+			if tt.split {
+				_, err = orders.SplitBillPayment(ctx, SplitPayment{
+					OrderID: orderID, PaymentMethod: "qris", Amount: 50000, CashierID: adminID, PaymentIntentID: intent.ID,
+				})
+			} else {
+				_, err = orders.SettleOrder(ctx, OrderSettlement{
+					OrderID: orderID, PaymentMethod: "qris", Amount: 120000, DepositAmount: tt.depositAmount,
+					CashierID: adminID, PaymentIntentID: intent.ID,
+				})
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+
+			stored, err := intents.GetByID(ctx, intent.ID)
+			if err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+			if (stored.SettledAt != nil) != tt.wantSettled {
+				t.Errorf("settled_at = %v, want settled %v", stored.SettledAt, tt.wantSettled)
+			}
+
+			if tt.wantSettled {
+				// callback ganda tidak mencatat intent dua kali
+				_, err := orders.SplitBillPayment(ctx, SplitPayment{
+					OrderID: orderID, PaymentMethod: "qris", Amount: 1000, CashierID: adminID, PaymentIntentID: intent.ID,
+				})
+				if !errors.Is(err, ErrPaymentIntentSettled) && !errors.Is(err, ErrOrderAlreadyPaid) {
+					t.Errorf("pelunasan ulang error = %v, want intent sudah settled", err)
+				}
+			}
+
+			flagged, err := intents.MarkRefundRequired(ctx, intent.ID)
+			if err != nil {
+				t.Fatalf("MarkRefundRequired: %v", err)
+			}
+			if flagged != tt.wantRefundFlag {
+				t.Errorf("refund ditandai = %v, want %v", flagged, tt.wantRefundFlag)
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PaymentMethod adalah metode pembayaran yang bisa dipilih kasir.
+// Code disimpan di payments/transactions sehingga tidak bisa diubah setelah dibuat.
+type PaymentMethod struct {
+	ID                string    `json:"id"`
+	Code              string    `json:"code"`
+	Name              string    `json:"name"`
+	MethodType        string    `json:"method_type"`
+	RequiresReference bool      `json:"requires_reference"`
+	CountsToDrawer    bool      `json:"counts_to_drawer"`
+	IsActive          bool      `json:"is_active"`
+	IsSystem          bool      `json:"is_system"`
+	SortOrder         int64     `json:"sort_order"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+var (
+	ErrPaymentMethodNotFound = errors.New("metode pembayaran tidak ditemukan")
+	ErrInvalidPaymentMethod  = errors.New("data metode pembayaran tidak valid")
+	ErrPaymentMethodInUse    = errors.New("metode pembayaran sudah dipakai transaksi, nonaktifkan saja")
+	ErrPaymentMethodSystem   = errors.New("metode pembayaran bawaan tidak bisa dihapus")
+)
+
+type PaymentMethodRepository interface {
+	List(ctx context.Context, activeOnly bool) ([]PaymentMethod, error)
+	GetByID(ctx context.Context, id string) (*PaymentMethod, error)
+	GetByCode(ctx context.Context, code string) (*PaymentMethod, error)
+	Create(ctx context.Context, method *PaymentMethod) error
+	Update(ctx context.Context, method *PaymentMethod) error
+	Delete(ctx context.Context, id string) error
+}
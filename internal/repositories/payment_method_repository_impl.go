@@ -0,0 +1,198 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type paymentMethodRepository struct {
+	db *sql.DB
+}
+
+func NewPaymentMethodRepository(dbConn *sql.DB) PaymentMethodRepository {
+	return &paymentMethodRepository{db: dbConn}
+}
+
+var (
+	validPaymentMethodTypes = map[string]bool{
+		"cash": true, "card": true, "qris": true, "transfer": true,
+		"ewallet": true, "account": true, "other": true,
+	}
+	paymentMethodCodePattern = regexp.MustCompile(`^[a-z0-9_]{2,32}$`)
+)
+
+const paymentMethodColumns = `
+	id, code, name, method_type, requires_reference, counts_to_drawer,
+	is_active, is_system, sort_order, created_at, updated_at
+`
+
+func scanPaymentMethod(scanner interface{ Scan(dest ...any) error }) (*PaymentMethod, error) {
+	var method PaymentMethod
+	var requiresReference, countsToDrawer, isActive, isSystem int64
+	if err := scanner.Scan(
+		&method.ID, &method.Code, &method.Name, &method.MethodType, &requiresReference, &countsToDrawer,
+		&isActive, &isSystem, &method.SortOrder, &method.CreatedAt, &method.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	method.RequiresReference = requiresReference == 1
+	method.CountsToDrawer = countsToDrawer == 1
+	method.IsActive = isActive == 1
+	method.IsSystem = isSystem == 1
+	return &method, nil
+}
+
+// GetActivePaymentMethod mengambil metode aktif berdasarkan kode. Dipakai saat
+// validasi pembayaran sehingga metode baru langsung bisa dipakai tanpa ubah kode.
+func GetActivePaymentMethod(ctx context.Context, q db.DBTX, code string) (*PaymentMethod, error) {
+	row := q.QueryRowContext(ctx, "SELECT "+paymentMethodColumns+" FROM payment_methods WHERE code = ? AND is_active = 1", code)
+	method, err := scanPaymentMethod(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentMethodNotFound
+	}
+	return method, err
+}
+
+func validatePaymentMethod(method *PaymentMethod) error {
+	method.Code = strings.ToLower(strings.TrimSpace(method.Code))
+	method.Name = strings.TrimSpace(method.Name)
+	if !paymentMethodCodePattern.MatchString(method.Code) {
+		return fmt.Errorf("%w: code harus 2-32 karakter huruf kecil, angka atau _", ErrInvalidPaymentMethod)
+	}
+	if method.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidPaymentMethod)
+	}
+	if !validPaymentMethodTypes[method.MethodType] {
+		return fmt.Errorf("%w: method_type harus cash, card, qris, transfer, ewallet, account atau other", ErrInvalidPaymentMethod)
+	}
+	return nil
+}
+
+func (r *paymentMethodRepository) List(ctx context.Context, activeOnly bool) ([]PaymentMethod, error) {
+	query := "SELECT " + paymentMethodColumns + " FROM payment_methods"
+	if activeOnly {
+		query += " WHERE is_active = 1"
+	}
+	query += " ORDER BY sort_order, name"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	methods := []PaymentMethod{}
+	for rows.Next() {
+		method, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, *method)
+	}
+	return methods, rows.Err()
+}
+
+func (r *paymentMethodRepository) GetByID(ctx context.Context, id string) (*PaymentMethod, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+paymentMethodColumns+" FROM payment_methods WHERE id = ?", id)
+	method, err := scanPaymentMethod(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentMethodNotFound
+	}
+	return method, err
+}
+
+func (r *paymentMethodRepository) GetByCode(ctx context.Context, code string) (*PaymentMethod, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+paymentMethodColumns+" FROM payment_methods WHERE code = ?", code)
+	method, err := scanPaymentMethod(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPaymentMethodNotFound
+	}
+	return method, err
+}
+
+func (r *paymentMethodRepository) Create(ctx context.Context, method *PaymentMethod) error {
+	if err := validatePaymentMethod(method); err != nil {
+		return err
+	}
+	if _, err := r.GetByCode(ctx, method.Code); err == nil {
+		return fmt.Errorf("%w: code %s sudah dipakai", ErrInvalidPaymentMethod, method.Code)
+	}
+
+	method.ID = utils.GenerateULID()
+	method.IsSystem = false
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO payment_methods (
+			id, code, name, method_type, requires_reference, counts_to_drawer,
+			is_active, is_system, sort_order, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, 0, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, method.ID, method.Code, method.Name, method.MethodType, boolToInt(method.RequiresReference),
+		boolToInt(method.CountsToDrawer), boolToInt(method.IsActive), method.SortOrder)
+	if err != nil {
+		return fmt.Errorf("gagal membuat metode pembayaran: %w", err)
+	}
+	return nil
+}
+
+// Update tidak mengubah code. Metode tunai bawaan harus tetap aktif karena
+// dipakai untuk kembalian dan carry over laci kas.
+func (r *paymentMethodRepository) Update(ctx context.Context, method *PaymentMethod) error {
+	existing, err := r.GetByID(ctx, method.ID)
+	if err != nil {
+		return err
+	}
+	method.Code = existing.Code
+	if err := validatePaymentMethod(method); err != nil {
+		return err
+	}
+	if existing.IsSystem && existing.Code == "cash" {
+		if !method.IsActive {
+			return fmt.Errorf("%w: metode tunai tidak bisa dinonaktifkan", ErrInvalidPaymentMethod)
+		}
+		method.MethodType = existing.MethodType
+		method.CountsToDrawer = true
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE payment_methods
+		SET name = ?, method_type = ?, requires_reference = ?, counts_to_drawer = ?,
+		    is_active = ?, sort_order = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, method.Name, method.MethodType, boolToInt(method.RequiresReference), boolToInt(method.CountsToDrawer),
+		boolToInt(method.IsActive), method.SortOrder, method.ID)
+	if err != nil {
+		return fmt.Errorf("gagal mengubah metode pembayaran: %w", err)
+	}
+	return nil
+}
+
+func (r *paymentMethodRepository) Delete(ctx context.Context, id string) error {
+	existing, err := r.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing.IsSystem {
+		return ErrPaymentMethodSystem
+	}
+
+	var used int64
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM payments WHERE payment_method = ?) +
+			(SELECT COUNT(*) FROM transactions WHERE payment_method = ?)
+	`, existing.Code, existing.Code).Scan(&used); err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrPaymentMethodInUse
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM payment_methods WHERE id = ?", id); err != nil {
+		return fmt.Errorf("gagal menghapus metode pembayaran: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PriceList adalah versi menu dengan harga yang berlaku mulai effective_from
+type PriceList struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	EffectiveFrom time.Time       `json:"effective_from"`
+	IsActive      bool            `json:"is_active"`
+	CreatedBy     string          `json:"created_by"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+	ItemCount     int64           `json:"item_count"`
+	Items         []PriceListItem `json:"items,omitempty"`
+}
+
+// PriceListItem adalah harga produk dalam price list, opsional per day-part (mis. lunch/dinner)
+type PriceListItem struct {
+	ID          string  `json:"id"`
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	DayPart     string  `json:"day_part"`
+	StartTime   string  `json:"start_time"`
+	EndTime     string  `json:"end_time"`
+	Price       float64 `json:"price"`
+}
+
+// ScheduledPrice adalah hasil resolusi harga produk pada waktu tertentu
+type ScheduledPrice struct {
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	BasePrice     float64 `json:"base_price"`
+	Price         float64 `json:"price"`
+	PriceListID   string  `json:"price_list_id,omitempty"`
+	PriceListName string  `json:"price_list_name,omitempty"`
+	DayPart       string  `json:"day_part,omitempty"`
+}
+
+var (
+	ErrPriceListNotFound    = errors.New("price list tidak ditemukan")
+	ErrInvalidDayPartWindow = errors.New("jam day-part harus format HH:MM dan diisi berpasangan")
+)
+
+type PriceListRepository interface {
+	List(ctx context.Context) ([]PriceList, error)
+	GetByID(ctx context.Context, id string) (*PriceList, error)
+	Create(ctx context.Context, list *PriceList) error
+	Update(ctx context.Context, list *PriceList) error
+	Delete(ctx context.Context, id string) error
+	PreviewPrices(ctx context.Context, at time.Time) ([]ScheduledPrice, error)
+}
@@ -0,0 +1,358 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// priceListTimeLayout dipakai untuk menyimpan effective_from dalam UTC agar bisa dibandingkan sebagai teks
+const priceListTimeLayout = "2006-01-02 15:04:05"
+
+type priceListRepository struct {
+	db *sql.DB
+}
+
+func NewPriceListRepository(dbConn *sql.DB) PriceListRepository {
+	return &priceListRepository{db: dbConn}
+}
+
+// resolveScheduledPrice mencari harga produk yang berlaku pada waktu at.
+// Price list aktif terbaru (effective_from <= at) yang memuat produk tersebut dipakai;
+// di dalam satu price list, harga day-part yang cocok dengan jam lokal didahulukan
+// dibanding harga sepanjang hari. Jika tidak ada, harga dasar produk dipakai.
+func resolveScheduledPrice(ctx context.Context, dbtx db.DBTX, productID string, basePrice float64, at time.Time) (ScheduledPrice, error) {
+	result := ScheduledPrice{
+		ProductID: productID,
+		BasePrice: basePrice,
+		Price:     basePrice,
+	}
+
+	rows, err := dbtx.QueryContext(ctx, `
+		SELECT pl.id, pl.name, pli.day_part, pli.start_time, pli.end_time, pli.price
+		FROM price_list_items pli
+		JOIN price_lists pl ON pl.id = pli.price_list_id
+		WHERE pli.product_id = ?
+		  AND pl.is_active = 1
+		  AND pl.effective_from <= ?
+		ORDER BY pl.effective_from DESC, pl.id DESC
+	`, productID, at.UTC().Format(priceListTimeLayout))
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	clock := at.In(time.Local).Format("15:04")
+	currentListID := ""
+	var allDay *ScheduledPrice
+	for rows.Next() {
+		var listID, listName, dayPart, startTime, endTime string
+		var price float64
+		if err := rows.Scan(&listID, &listName, &dayPart, &startTime, &endTime, &price); err != nil {
+			return result, err
+		}
+
+		if listID != currentListID {
+			if allDay != nil {
+				break
+			}
+			currentListID = listID
+		}
+
+		candidate := result
+		candidate.Price = price
+		candidate.PriceListID = listID
+		candidate.PriceListName = listName
+		candidate.DayPart = dayPart
+
+		if startTime == "" && endTime == "" {
+			if allDay == nil {
+				allDay = &candidate
+			}
+			continue
+		}
+		if inDayPartWindow(clock, startTime, endTime) {
+			return candidate, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return result, err
+	}
+
+	if allDay != nil {
+		return *allDay, nil
+	}
+	return result, nil
+}
+
+// inDayPartWindow memeriksa jam HH:MM dalam rentang [start, end), mendukung rentang lewat tengah malam
+func inDayPartWindow(clock, start, end string) bool {
+	if start <= end {
+		return clock >= start && clock < end
+	}
+	return clock >= start || clock < end
+}
+
+func validateDayPartTime(value string) bool {
+	if value == "" {
+		return true
+	}
+	_, err := time.Parse("15:04", value)
+	return err == nil && len(value) == 5
+}
+
+func (r *priceListRepository) List(ctx context.Context) ([]PriceList, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pl.id, pl.name, pl.description, pl.effective_from, pl.is_active,
+		       COALESCE(pl.created_by, ''), pl.created_at, pl.updated_at,
+		       (SELECT COUNT(*) FROM price_list_items pli WHERE pli.price_list_id = pl.id)
+		FROM price_lists pl
+		ORDER BY pl.effective_from DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil price list: %w", err)
+	}
+	defer rows.Close()
+
+	lists := []PriceList{}
+	for rows.Next() {
+		var list PriceList
+		var isActive int64
+		if err := rows.Scan(
+			&list.ID,
+			&list.Name,
+			&list.Description,
+			&list.EffectiveFrom,
+			&isActive,
+			&list.CreatedBy,
+			&list.CreatedAt,
+			&list.UpdatedAt,
+			&list.ItemCount,
+		); err != nil {
+			return nil, fmt.Errorf("gagal membaca price list: %w", err)
+		}
+		list.IsActive = isActive == 1
+		lists = append(lists, list)
+	}
+	return lists, rows.Err()
+}
+
+func (r *priceListRepository) GetByID(ctx context.Context, id string) (*PriceList, error) {
+	var list PriceList
+	var isActive int64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, name, description, effective_from, is_active, COALESCE(created_by, ''), created_at, updated_at
+		FROM price_lists
+		WHERE id = ?
+	`, id).Scan(
+		&list.ID,
+		&list.Name,
+		&list.Description,
+		&list.EffectiveFrom,
+		&isActive,
+		&list.CreatedBy,
+		&list.CreatedAt,
+		&list.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrPriceListNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil price list: %w", err)
+	}
+	list.IsActive = isActive == 1
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pli.id, pli.product_id, COALESCE(p.name, ''), pli.day_part, pli.start_time, pli.end_time, pli.price
+		FROM price_list_items pli
+		LEFT JOIN products p ON p.id = pli.product_id
+		WHERE pli.price_list_id = ?
+		ORDER BY p.name, pli.start_time
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil item price list: %w", err)
+	}
+	defer rows.Close()
+
+	list.Items = []PriceListItem{}
+	for rows.Next() {
+		var item PriceListItem
+		if err := rows.Scan(&item.ID, &item.ProductID, &item.ProductName, &item.DayPart, &item.StartTime, &item.EndTime, &item.Price); err != nil {
+			return nil, fmt.Errorf("gagal membaca item price list: %w", err)
+		}
+		list.Items = append(list.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	list.ItemCount = int64(len(list.Items))
+
+	return &list, nil
+}
+
+func (r *priceListRepository) Create(ctx context.Context, list *PriceList) error {
+	if err := validatePriceListItems(list.Items); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	list.ID = utils.GenerateULID()
+	isActive := 0
+	if list.IsActive {
+		isActive = 1
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO price_lists (id, name, description, effective_from, is_active, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, list.ID, list.Name, list.Description, list.EffectiveFrom.UTC().Format(priceListTimeLayout), isActive,
+		sql.NullString{String: list.CreatedBy, Valid: list.CreatedBy != ""})
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("gagal membuat price list: %w", err)
+	}
+
+	if err := insertPriceListItems(ctx, tx, list.ID, list.Items); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *priceListRepository) Update(ctx context.Context, list *PriceList) error {
+	if err := validatePriceListItems(list.Items); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	isActive := 0
+	if list.IsActive {
+		isActive = 1
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE price_lists
+		SET name = ?, description = ?, effective_from = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, list.Name, list.Description, list.EffectiveFrom.UTC().Format(priceListTimeLayout), isActive, list.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("gagal update price list: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		_ = tx.Rollback()
+		return ErrPriceListNotFound
+	}
+
+	// Item diganti seluruhnya agar versi menu selalu sesuai payload terakhir
+	if _, err := tx.ExecContext(ctx, "DELETE FROM price_list_items WHERE price_list_id = ?", list.ID); err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("gagal menghapus item price list: %w", err)
+	}
+	if err := insertPriceListItems(ctx, tx, list.ID, list.Items); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *priceListRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM price_lists WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus price list: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPriceListNotFound
+	}
+	return nil
+}
+
+func (r *priceListRepository) PreviewPrices(ctx context.Context, at time.Time) ([]ScheduledPrice, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, price
+		FROM products
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil produk: %w", err)
+	}
+
+	type productPrice struct {
+		id    string
+		name  string
+		price float64
+	}
+	products := []productPrice{}
+	for rows.Next() {
+		var p productPrice
+		if err := rows.Scan(&p.id, &p.name, &p.price); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("gagal membaca produk: %w", err)
+		}
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, err
+	}
+	rows.Close()
+
+	prices := make([]ScheduledPrice, 0, len(products))
+	for _, p := range products {
+		resolved, err := resolveScheduledPrice(ctx, r.db, p.id, p.price, at)
+		if err != nil {
+			return nil, fmt.Errorf("gagal menghitung harga %s: %w", p.name, err)
+		}
+		resolved.ProductName = p.name
+		prices = append(prices, resolved)
+	}
+	return prices, nil
+}
+
+func validatePriceListItems(items []PriceListItem) error {
+	for _, item := range items {
+		if item.ProductID == "" {
+			return fmt.Errorf("product_id wajib diisi")
+		}
+		if item.Price < 0 {
+			return fmt.Errorf("harga tidak boleh negatif")
+		}
+		if (item.StartTime == "") != (item.EndTime == "") {
+			return ErrInvalidDayPartWindow
+		}
+		if !validateDayPartTime(item.StartTime) || !validateDayPartTime(item.EndTime) {
+			return ErrInvalidDayPartWindow
+		}
+		if item.StartTime != "" && item.StartTime == item.EndTime {
+			return ErrInvalidDayPartWindow
+		}
+	}
+	return nil
+}
+
+func insertPriceListItems(ctx context.Context, tx *sql.Tx, priceListID string, items []PriceListItem) error {
+	for i := range items {
+		items[i].ID = utils.GenerateULID()
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO price_list_items (id, price_list_id, product_id, day_part, start_time, end_time, price, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		`, items[i].ID, priceListID, items[i].ProductID, items[i].DayPart, items[i].StartTime, items[i].EndTime, items[i].Price)
+		if err != nil {
+			return fmt.Errorf("gagal menyimpan item price list: %w", err)
+		}
+	}
+	return nil
+}
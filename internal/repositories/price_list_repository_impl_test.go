@@ -0,0 +1,209 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"backend/pkg/database"
+	"backend/pkg/utils"
+)
+
+// newTestDB membuat database SQLite sementara dengan skema dan migrasi lengkap
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	conn, err := database.NewDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("buka database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func mustExec(t *testing.T, conn *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := conn.Exec(query, args...); err != nil {
+		t.Fatalf("exec %q: %v", query, err)
+	}
+}
+
+func insertTestProduct(t *testing.T, conn *sql.DB, name string, price float64, categoryID string) string {
+	t.Helper()
+	id := utils.GenerateULID()
+	var category interface{}
+	if categoryID != "" {
+		category = categoryID
+	}
+	mustExec(t, conn, `INSERT INTO products (id, name, price, stock, category_id) VALUES (?, ?, ?, 100, ?)`,
+		id, name, price, category)
+	return id
+}
+
+func insertTestPriceList(t *testing.T, conn *sql.DB, name string, effectiveFrom time.Time, active bool) string {
+	t.Helper()
+	id := utils.GenerateULID()
+	isActive := 0
+	if active {
+		isActive = 1
+	}
+	mustExec(t, conn, `INSERT INTO price_lists (id, name, effective_from, is_active) VALUES (?, ?, ?, ?)`,
+		id, name, effectiveFrom.UTC().Format(priceListTimeLayout), isActive)
+	return id
+}
+
+func insertTestPriceListItem(t *testing.T, conn *sql.DB, listID, productID, dayPart, start, end string, price float64) {
+	t.Helper()
+	mustExec(t, conn, `
+		INSERT INTO price_list_items (id, price_list_id, product_id, day_part, start_time, end_time, price)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, utils.GenerateULID(), listID, productID, dayPart, start, end, price)
+}
+
+func TestInDayPartWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		clock      string
+		start, end string
+		want       bool
+	}{
+		{"di dalam rentang", "11:30", "11:00", "14:00", true},
+		{"tepat di awal", "11:00", "11:00", "14:00", true},
+		{"tepat di akhir tidak termasuk", "14:00", "11:00", "14:00", false},
+		{"sebelum rentang", "10:59", "11:00", "14:00", false},
+		{"lewat tengah malam, malam hari", "23:15", "22:00", "02:00", true},
+		{"lewat tengah malam, dini hari", "01:59", "22:00", "02:00", true},
+		{"lewat tengah malam, akhir tidak termasuk", "02:00", "22:00", "02:00", false},
+		{"lewat tengah malam, siang hari", "12:00", "22:00", "02:00", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inDayPartWindow(tt.clock, tt.start, tt.end); got != tt.want {
+				t.Errorf("inDayPartWindow(%q, %q, %q) = %v, want %v", tt.clock, tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveScheduledPrice(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 10, 19, 12, 30, 0, 0, time.Local)
+	dayBefore := at.Add(-24 * time.Hour)
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, conn *sql.DB, productID string)
+		wantPrice float64
+		wantList  string
+		wantPart  string
+	}{
+		{
+			name:      "tanpa daftar harga memakai harga dasar",
+			setup:     func(t *testing.T, conn *sql.DB, productID string) {},
+			wantPrice: 20000,
+		},
+		{
+			name: "harga sepanjang hari",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				list := insertTestPriceList(t, conn, "Reguler", dayBefore, true)
+				insertTestPriceListItem(t, conn, list, productID, "", "", "", 22000)
+			},
+			wantPrice: 22000,
+			wantList:  "Reguler",
+		},
+		{
+			name: "day-part yang cocok mengalahkan harga sepanjang hari",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				list := insertTestPriceList(t, conn, "Reguler", dayBefore, true)
+				insertTestPriceListItem(t, conn, list, productID, "", "", "", 22000)
+				insertTestPriceListItem(t, conn, list, productID, "lunch", "11:00", "14:00", 18000)
+				insertTestPriceListItem(t, conn, list, productID, "dinner", "18:00", "22:00", 25000)
+			},
+			wantPrice: 18000,
+			wantList:  "Reguler",
+			wantPart:  "lunch",
+		},
+		{
+			name: "day-part yang tidak cocok kembali ke harga sepanjang hari",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				list := insertTestPriceList(t, conn, "Reguler", dayBefore, true)
+				insertTestPriceListItem(t, conn, list, productID, "dinner", "18:00", "22:00", 25000)
+				insertTestPriceListItem(t, conn, list, productID, "", "", "", 22000)
+			},
+			wantPrice: 22000,
+			wantList:  "Reguler",
+		},
+		{
+			name: "hanya day-part yang tidak cocok memakai harga dasar",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				list := insertTestPriceList(t, conn, "Malam", dayBefore, true)
+				insertTestPriceListItem(t, conn, list, productID, "dinner", "18:00", "22:00", 25000)
+			},
+			wantPrice: 20000,
+		},
+		{
+			name: "daftar terbaru yang sudah berlaku dipakai",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				old := insertTestPriceList(t, conn, "Lama", dayBefore.Add(-24*time.Hour), true)
+				insertTestPriceListItem(t, conn, old, productID, "", "", "", 21000)
+				current := insertTestPriceList(t, conn, "Baru", dayBefore, true)
+				insertTestPriceListItem(t, conn, current, productID, "", "", "", 23000)
+			},
+			wantPrice: 23000,
+			wantList:  "Baru",
+		},
+		{
+			name: "daftar nonaktif dan belum berlaku diabaikan",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				current := insertTestPriceList(t, conn, "Reguler", dayBefore, true)
+				insertTestPriceListItem(t, conn, current, productID, "", "", "", 22000)
+				inactive := insertTestPriceList(t, conn, "Nonaktif", at.Add(-time.Hour), false)
+				insertTestPriceListItem(t, conn, inactive, productID, "", "", "", 15000)
+				future := insertTestPriceList(t, conn, "Besok", at.Add(time.Hour), true)
+				insertTestPriceListItem(t, conn, future, productID, "", "", "", 30000)
+			},
+			wantPrice: 22000,
+			wantList:  "Reguler",
+		},
+		{
+			name: "daftar terbaru tanpa item produk memakai daftar sebelumnya",
+			setup: func(t *testing.T, conn *sql.DB, productID string) {
+				old := insertTestPriceList(t, conn, "Lama", dayBefore.Add(-24*time.Hour), true)
+				insertTestPriceListItem(t, conn, old, productID, "lunch", "11:00", "14:00", 17000)
+				other := insertTestProduct(t, conn, "Teh", 5000, "")
+				current := insertTestPriceList(t, conn, "Baru", dayBefore, true)
+				insertTestPriceListItem(t, conn, current, other, "", "", "", 6000)
+			},
+			wantPrice: 17000,
+			wantList:  "Lama",
+			wantPart:  "lunch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			productID := insertTestProduct(t, conn, "Sup", 20000, "")
+			tt.setup(t, conn, productID)
+
+			got, err := resolveScheduledPrice(ctx, conn, productID, 20000, at)
+			if err != nil {
+				t.Fatalf("resolveScheduledPrice: %v", err)
+			}
+			if got.BasePrice != 20000 {
+				t.Errorf("BasePrice = %v, want 20000", got.BasePrice)
+			}
+			if got.Price != tt.wantPrice {
+				t.Errorf("Price = %v, want %v", got.Price, tt.wantPrice)
+			}
+			if got.PriceListName != tt.wantList {
+				t.Errorf("PriceListName = %q, want %q", got.PriceListName, tt.wantList)
+			}
+			if got.DayPart != tt.wantPart {
+				t.Errorf("DayPart = %q, want %q", got.DayPart, tt.wantPart)
+			}
+		})
+	}
+}
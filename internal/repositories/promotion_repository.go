@@ -0,0 +1,81 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Promotion adalah aturan diskon yang dievaluasi otomatis saat total order dihitung ulang.
+// promo_type: percentage_off, fixed_off, buy_x_get_y, bundle.
+// scope: order, category, product (target_ids berisi id kategori/produk).
+type Promotion struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	PromoType       string     `json:"promo_type"`
+	Scope           string     `json:"scope"`
+	TargetIDs       []string   `json:"target_ids"`
+	Value           float64    `json:"value"`
+	BuyQty          int64      `json:"buy_qty"`
+	GetQty          int64      `json:"get_qty"`
+	MinSpend        float64    `json:"min_spend"`
+	StartAt         *time.Time `json:"start_at"`
+	EndAt           *time.Time `json:"end_at"`
+	DaysOfWeek      string     `json:"days_of_week"`
+	StartTime       string     `json:"start_time"`
+	EndTime         string     `json:"end_time"`
+	RequiresVoucher bool       `json:"requires_voucher"`
+	Priority        int64      `json:"priority"`
+	IsActive        bool       `json:"is_active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Voucher adalah kode yang membuka promosi dengan requires_voucher
+type Voucher struct {
+	ID            string     `json:"id"`
+	Code          string     `json:"code"`
+	PromotionID   string     `json:"promotion_id"`
+	PromotionName string     `json:"promotion_name"`
+	UsageLimit    int64      `json:"usage_limit"`
+	UsedCount     int64      `json:"used_count"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	IsActive      bool       `json:"is_active"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// AppliedPromotion adalah baris promosi yang menempel pada order
+type AppliedPromotion struct {
+	PromotionID   string  `json:"promotion_id"`
+	Name          string  `json:"name"`
+	AppliedAmount float64 `json:"applied_amount"`
+}
+
+// PromotionSummary adalah ringkasan diskon promosi untuk analitik
+type PromotionSummary struct {
+	PromotionID   string  `json:"promotion_id"`
+	Name          string  `json:"name"`
+	OrdersCount   int64   `json:"orders_count"`
+	TotalDiscount float64 `json:"total_discount"`
+}
+
+var (
+	ErrPromotionNotFound     = errors.New("promosi tidak ditemukan")
+	ErrInvalidPromotion      = errors.New("data promosi tidak valid")
+	ErrVoucherNotFound       = errors.New("voucher tidak ditemukan")
+	ErrVoucherInvalid        = errors.New("voucher tidak aktif atau sudah kedaluwarsa")
+	ErrVoucherLimitReached   = errors.New("kuota voucher sudah habis")
+	ErrVoucherNotApplicable  = errors.New("order tidak memenuhi syarat promosi voucher")
+	ErrVoucherAlreadyApplied = errors.New("voucher sudah dipakai pada order ini")
+)
+
+type PromotionRepository interface {
+	List(ctx context.Context) ([]Promotion, error)
+	GetByID(ctx context.Context, id string) (*Promotion, error)
+	Create(ctx context.Context, promo *Promotion) error
+	Update(ctx context.Context, promo *Promotion) error
+	Delete(ctx context.Context, id string) error
+	ListVouchers(ctx context.Context, promotionID string) ([]Voucher, error)
+	CreateVoucher(ctx context.Context, voucher *Voucher) error
+	DeleteVoucher(ctx context.Context, id string) error
+}
@@ -0,0 +1,608 @@
+package repositories
+
+import (
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type promotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(dbConn *sql.DB) PromotionRepository {
+	return &promotionRepository{db: dbConn}
+}
+
+// promotionOrderItem adalah item order yang dipakai saat evaluasi promosi
+type promotionOrderItem struct {
+	productID  string
+	categoryID string
+	price      float64
+	qty        int64
+}
+
+// applyPromotions mengevaluasi promosi aktif untuk order dan menyimpan setiap promosi
+// yang berlaku sebagai baris diskon tersendiri (promotion_id terisi, applied_amount negatif).
+// Jendela waktu (happy hour) dinilai dari waktu order dibuat. Mengembalikan total diskon (negatif).
+func applyPromotions(ctx context.Context, tx *sql.Tx, orderID string, subtotal float64) (float64, error) {
+	if subtotal <= 0 {
+		return 0, nil
+	}
+
+	var orderTime time.Time
+	if err := tx.QueryRowContext(ctx, "SELECT created_at FROM orders WHERE id = ?", orderID).Scan(&orderTime); err != nil {
+		return 0, err
+	}
+
+	items, err := loadPromotionOrderItems(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	promos, err := loadApplicablePromotions(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	remaining := subtotal
+	for _, promo := range promos {
+		if !promotionActiveAt(promo, orderTime) || subtotal < promo.MinSpend {
+			continue
+		}
+
+		discount := math.Round(calculatePromotionDiscount(promo, items))
+		if discount > remaining {
+			discount = remaining
+		}
+		if discount <= 0 {
+			continue
+		}
+
+		chargeType := "fixed"
+		value := discount
+		if promo.PromoType == "percentage_off" {
+			chargeType = "percentage"
+			value = promo.Value
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO order_additional_charges (
+				order_id,
+				promotion_id,
+				name,
+				charge_type,
+				value,
+				applied_amount,
+				created_at,
+				updated_at
+			) VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, orderID, promo.ID, promo.Name, chargeType, value, -discount)
+		if err != nil {
+			return 0, fmt.Errorf("gagal menyimpan promosi %s: %w", promo.Name, err)
+		}
+
+		total -= discount
+		remaining -= discount
+		if remaining <= 0 {
+			break
+		}
+	}
+
+	return total, nil
+}
+
+func loadPromotionOrderItems(ctx context.Context, tx *sql.Tx, orderID string) ([]promotionOrderItem, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT COALESCE(oi.product_id, ''), COALESCE(p.category_id, ''), oi.price, oi.qty
+		FROM order_items oi
+		LEFT JOIN products p ON p.id = oi.product_id
+		WHERE oi.order_id = ?
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []promotionOrderItem{}
+	for rows.Next() {
+		var item promotionOrderItem
+		if err := rows.Scan(&item.productID, &item.categoryID, &item.price, &item.qty); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// loadApplicablePromotions mengambil promosi aktif otomatis ditambah promosi voucher yang dipasang di order
+func loadApplicablePromotions(ctx context.Context, tx *sql.Tx, orderID string) ([]Promotion, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+promotionColumns+`
+		FROM promotions p
+		WHERE p.is_active = 1
+		  AND (
+			p.requires_voucher = 0
+			OR EXISTS (
+				SELECT 1
+				FROM order_vouchers ov
+				JOIN vouchers v ON v.id = ov.voucher_id
+				WHERE ov.order_id = ? AND v.promotion_id = p.id
+			)
+		  )
+		ORDER BY p.priority DESC, p.created_at ASC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promos := []Promotion{}
+	for rows.Next() {
+		promo, err := scanPromotion(rows)
+		if err != nil {
+			return nil, err
+		}
+		promos = append(promos, promo)
+	}
+	return promos, rows.Err()
+}
+
+// promotionActiveAt memeriksa periode, hari, dan jam berlaku promosi pada waktu lokal at
+func promotionActiveAt(promo Promotion, at time.Time) bool {
+	if promo.StartAt != nil && at.Before(*promo.StartAt) {
+		return false
+	}
+	if promo.EndAt != nil && !at.Before(*promo.EndAt) {
+		return false
+	}
+
+	local := at.In(time.Local)
+	if promo.DaysOfWeek != "" {
+		today := strconv.Itoa(int(local.Weekday()))
+		matched := false
+		for _, day := range strings.Split(promo.DaysOfWeek, ",") {
+			if strings.TrimSpace(day) == today {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if promo.StartTime != "" && promo.EndTime != "" {
+		return inDayPartWindow(local.Format("15:04"), promo.StartTime, promo.EndTime)
+	}
+	return true
+}
+
+func promotionMatchesItem(promo Promotion, item promotionOrderItem) bool {
+	switch promo.Scope {
+	case "category":
+		return containsString(promo.TargetIDs, item.categoryID)
+	case "product":
+		return containsString(promo.TargetIDs, item.productID)
+	default:
+		return true
+	}
+}
+
+func calculatePromotionDiscount(promo Promotion, items []promotionOrderItem) float64 {
+	eligible := make([]promotionOrderItem, 0, len(items))
+	eligibleTotal := 0.0
+	for _, item := range items {
+		if promotionMatchesItem(promo, item) {
+			eligible = append(eligible, item)
+			eligibleTotal += item.price * float64(item.qty)
+		}
+	}
+	if len(eligible) == 0 {
+		return 0
+	}
+
+	switch promo.PromoType {
+	case "percentage_off":
+		return eligibleTotal * promo.Value / 100
+	case "fixed_off":
+		return math.Min(promo.Value, eligibleTotal)
+	case "buy_x_get_y":
+		return buyXGetYDiscount(promo, eligible)
+	case "bundle":
+		return bundleDiscount(promo, eligible)
+	}
+	return 0
+}
+
+// buyXGetYDiscount: unit diurutkan dari harga tertinggi, di setiap kelompok (buy+get) unit
+// termurah mendapat potongan value% (0 berarti gratis)
+func buyXGetYDiscount(promo Promotion, items []promotionOrderItem) float64 {
+	if promo.BuyQty <= 0 || promo.GetQty <= 0 {
+		return 0
+	}
+	percent := promo.Value
+	if percent <= 0 || percent > 100 {
+		percent = 100
+	}
+
+	units := []float64{}
+	for _, item := range items {
+		for i := int64(0); i < item.qty; i++ {
+			units = append(units, item.price)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(units)))
+
+	groupSize := int(promo.BuyQty + promo.GetQty)
+	discount := 0.0
+	for start := 0; start+groupSize <= len(units); start += groupSize {
+		for _, price := range units[start+int(promo.BuyQty) : start+groupSize] {
+			discount += price * percent / 100
+		}
+	}
+	return discount
+}
+
+// bundleDiscount: setiap produk di target_ids adalah komponen paket; jumlah paket mengikuti
+// komponen dengan qty paling sedikit dan tiap paket dihargai value
+func bundleDiscount(promo Promotion, items []promotionOrderItem) float64 {
+	if promo.Scope != "product" || len(promo.TargetIDs) == 0 {
+		return 0
+	}
+
+	qtyByProduct := map[string]int64{}
+	priceByProduct := map[string]float64{}
+	for _, item := range items {
+		qtyByProduct[item.productID] += item.qty
+		if item.price > priceByProduct[item.productID] {
+			priceByProduct[item.productID] = item.price
+		}
+	}
+
+	bundles := int64(-1)
+	regularPrice := 0.0
+	for _, productID := range promo.TargetIDs {
+		qty := qtyByProduct[productID]
+		if bundles < 0 || qty < bundles {
+			bundles = qty
+		}
+		regularPrice += priceByProduct[productID]
+	}
+	if bundles <= 0 || regularPrice <= promo.Value {
+		return 0
+	}
+	return (regularPrice - promo.Value) * float64(bundles)
+}
+
+func containsString(values []string, target string) bool {
+	if target == "" {
+		return false
+	}
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+const promotionColumns = `p.id, p.name, p.promo_type, p.scope, p.target_ids, p.value, p.buy_qty, p.get_qty,
+		       p.min_spend, p.start_at, p.end_at, p.days_of_week, p.start_time, p.end_time,
+		       p.requires_voucher, p.priority, p.is_active, p.created_at, p.updated_at`
+
+type promotionScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPromotion(row promotionScanner) (Promotion, error) {
+	var promo Promotion
+	var targetIDs string
+	var startAt, endAt sql.NullTime
+	var requiresVoucher, isActive int64
+	if err := row.Scan(
+		&promo.ID,
+		&promo.Name,
+		&promo.PromoType,
+		&promo.Scope,
+		&targetIDs,
+		&promo.Value,
+		&promo.BuyQty,
+		&promo.GetQty,
+		&promo.MinSpend,
+		&startAt,
+		&endAt,
+		&promo.DaysOfWeek,
+		&promo.StartTime,
+		&promo.EndTime,
+		&requiresVoucher,
+		&promo.Priority,
+		&isActive,
+		&promo.CreatedAt,
+		&promo.UpdatedAt,
+	); err != nil {
+		return promo, err
+	}
+
+	promo.TargetIDs = []string{}
+	if targetIDs != "" {
+		if err := json.Unmarshal([]byte(targetIDs), &promo.TargetIDs); err != nil {
+			return promo, fmt.Errorf("target_ids promosi %s tidak valid: %w", promo.ID, err)
+		}
+	}
+	if startAt.Valid {
+		promo.StartAt = &startAt.Time
+	}
+	if endAt.Valid {
+		promo.EndAt = &endAt.Time
+	}
+	promo.RequiresVoucher = requiresVoucher == 1
+	promo.IsActive = isActive == 1
+	return promo, nil
+}
+
+func validatePromotion(promo *Promotion) error {
+	switch promo.PromoType {
+	case "percentage_off":
+		if promo.Value <= 0 || promo.Value > 100 {
+			return fmt.Errorf("%w: value persentase harus 1-100", ErrInvalidPromotion)
+		}
+	case "fixed_off":
+		if promo.Value <= 0 {
+			return fmt.Errorf("%w: value potongan harus lebih dari 0", ErrInvalidPromotion)
+		}
+	case "buy_x_get_y":
+		if promo.BuyQty <= 0 || promo.GetQty <= 0 {
+			return fmt.Errorf("%w: buy_qty dan get_qty harus lebih dari 0", ErrInvalidPromotion)
+		}
+	case "bundle":
+		if promo.Scope != "product" || len(promo.TargetIDs) < 2 {
+			return fmt.Errorf("%w: bundle membutuhkan scope product dengan minimal 2 produk", ErrInvalidPromotion)
+		}
+	default:
+		return fmt.Errorf("%w: promo_type harus percentage_off, fixed_off, buy_x_get_y, atau bundle", ErrInvalidPromotion)
+	}
+
+	switch promo.Scope {
+	case "order":
+		promo.TargetIDs = []string{}
+	case "category", "product":
+		if len(promo.TargetIDs) == 0 {
+			return fmt.Errorf("%w: target_ids wajib diisi untuk scope %s", ErrInvalidPromotion, promo.Scope)
+		}
+	default:
+		return fmt.Errorf("%w: scope harus order, category, atau product", ErrInvalidPromotion)
+	}
+
+	if promo.MinSpend < 0 {
+		return fmt.Errorf("%w: min_spend tidak boleh negatif", ErrInvalidPromotion)
+	}
+	if (promo.StartTime == "") != (promo.EndTime == "") ||
+		!validateDayPartTime(promo.StartTime) || !validateDayPartTime(promo.EndTime) {
+		return fmt.Errorf("%w: start_time/end_time harus format HH:MM dan diisi berpasangan", ErrInvalidPromotion)
+	}
+	if promo.StartAt != nil && promo.EndAt != nil && !promo.EndAt.After(*promo.StartAt) {
+		return fmt.Errorf("%w: end_at harus setelah start_at", ErrInvalidPromotion)
+	}
+	if promo.DaysOfWeek != "" {
+		for _, day := range strings.Split(promo.DaysOfWeek, ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(day)); err != nil || n < 0 || n > 6 {
+				return fmt.Errorf("%w: days_of_week berisi angka 0-6 dipisah koma", ErrInvalidPromotion)
+			}
+		}
+	}
+	return nil
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
+
+func nullablePromotionTime(value *time.Time) interface{} {
+	if value == nil {
+		return nil
+	}
+	return value.UTC().Format(priceListTimeLayout)
+}
+
+func (r *promotionRepository) List(ctx context.Context) ([]Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+promotionColumns+`
+		FROM promotions p
+		ORDER BY p.is_active DESC, p.priority DESC, p.created_at DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil promosi: %w", err)
+	}
+	defer rows.Close()
+
+	promos := []Promotion{}
+	for rows.Next() {
+		promo, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca promosi: %w", err)
+		}
+		promos = append(promos, promo)
+	}
+	return promos, rows.Err()
+}
+
+func (r *promotionRepository) GetByID(ctx context.Context, id string) (*Promotion, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+promotionColumns+`
+		FROM promotions p
+		WHERE p.id = ?
+	`, id)
+	promo, err := scanPromotion(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrPromotionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil promosi: %w", err)
+	}
+	return &promo, nil
+}
+
+func (r *promotionRepository) Create(ctx context.Context, promo *Promotion) error {
+	if err := validatePromotion(promo); err != nil {
+		return err
+	}
+	targetIDs, err := json.Marshal(promo.TargetIDs)
+	if err != nil {
+		return err
+	}
+
+	promo.ID = utils.GenerateULID()
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO promotions (
+			id, name, promo_type, scope, target_ids, value, buy_qty, get_qty, min_spend,
+			start_at, end_at, days_of_week, start_time, end_time, requires_voucher, priority, is_active,
+			created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, promo.ID, promo.Name, promo.PromoType, promo.Scope, string(targetIDs), promo.Value, promo.BuyQty, promo.GetQty,
+		promo.MinSpend, nullablePromotionTime(promo.StartAt), nullablePromotionTime(promo.EndAt), promo.DaysOfWeek,
+		promo.StartTime, promo.EndTime, boolToInt(promo.RequiresVoucher), promo.Priority, boolToInt(promo.IsActive))
+	if err != nil {
+		return fmt.Errorf("gagal membuat promosi: %w", err)
+	}
+	return nil
+}
+
+func (r *promotionRepository) Update(ctx context.Context, promo *Promotion) error {
+	if err := validatePromotion(promo); err != nil {
+		return err
+	}
+	targetIDs, err := json.Marshal(promo.TargetIDs)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE promotions
+		SET name = ?, promo_type = ?, scope = ?, target_ids = ?, value = ?, buy_qty = ?, get_qty = ?,
+		    min_spend = ?, start_at = ?, end_at = ?, days_of_week = ?, start_time = ?, end_time = ?,
+		    requires_voucher = ?, priority = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, promo.Name, promo.PromoType, promo.Scope, string(targetIDs), promo.Value, promo.BuyQty, promo.GetQty,
+		promo.MinSpend, nullablePromotionTime(promo.StartAt), nullablePromotionTime(promo.EndAt), promo.DaysOfWeek,
+		promo.StartTime, promo.EndTime, boolToInt(promo.RequiresVoucher), promo.Priority, boolToInt(promo.IsActive), promo.ID)
+	if err != nil {
+		return fmt.Errorf("gagal update promosi: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *promotionRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM promotions WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus promosi: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrPromotionNotFound
+	}
+	return nil
+}
+
+func (r *promotionRepository) ListVouchers(ctx context.Context, promotionID string) ([]Voucher, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT v.id, v.code, v.promotion_id, p.name, v.usage_limit, v.expires_at, v.is_active, v.created_at,
+		       (SELECT COUNT(*)
+		        FROM order_vouchers ov
+		        JOIN orders o ON o.id = ov.order_id
+		        WHERE ov.voucher_id = v.id AND o.voided_at IS NULL)
+		FROM vouchers v
+		JOIN promotions p ON p.id = v.promotion_id
+		WHERE (? = '' OR v.promotion_id = ?)
+		ORDER BY v.created_at DESC
+	`, promotionID, promotionID)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil voucher: %w", err)
+	}
+	defer rows.Close()
+
+	vouchers := []Voucher{}
+	for rows.Next() {
+		var voucher Voucher
+		var expiresAt sql.NullTime
+		var isActive int64
+		if err := rows.Scan(
+			&voucher.ID,
+			&voucher.Code,
+			&voucher.PromotionID,
+			&voucher.PromotionName,
+			&voucher.UsageLimit,
+			&expiresAt,
+			&isActive,
+			&voucher.CreatedAt,
+			&voucher.UsedCount,
+		); err != nil {
+			return nil, fmt.Errorf("gagal membaca voucher: %w", err)
+		}
+		if expiresAt.Valid {
+			voucher.ExpiresAt = &expiresAt.Time
+		}
+		voucher.IsActive = isActive == 1
+		vouchers = append(vouchers, voucher)
+	}
+	return vouchers, rows.Err()
+}
+
+func (r *promotionRepository) CreateVoucher(ctx context.Context, voucher *Voucher) error {
+	voucher.Code = strings.ToUpper(strings.TrimSpace(voucher.Code))
+	if voucher.Code == "" {
+		return fmt.Errorf("%w: kode voucher wajib diisi", ErrInvalidPromotion)
+	}
+	if voucher.UsageLimit < 0 {
+		return fmt.Errorf("%w: usage_limit tidak boleh negatif", ErrInvalidPromotion)
+	}
+
+	var requiresVoucher int64
+	err := r.db.QueryRowContext(ctx, "SELECT requires_voucher FROM promotions WHERE id = ?", voucher.PromotionID).Scan(&requiresVoucher)
+	if err == sql.ErrNoRows {
+		return ErrPromotionNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("gagal mengambil promosi: %w", err)
+	}
+	if requiresVoucher != 1 {
+		return fmt.Errorf("%w: promosi tidak memakai voucher", ErrInvalidPromotion)
+	}
+
+	voucher.ID = utils.GenerateULID()
+	_, err = r.db.ExecContext(ctx, `
+		INSERT INTO vouchers (id, code, promotion_id, usage_limit, expires_at, is_active, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, voucher.ID, voucher.Code, voucher.PromotionID, voucher.UsageLimit, nullablePromotionTime(voucher.ExpiresAt), boolToInt(voucher.IsActive))
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE") {
+			return fmt.Errorf("%w: kode voucher sudah dipakai", ErrInvalidPromotion)
+		}
+		return fmt.Errorf("gagal membuat voucher: %w", err)
+	}
+	return nil
+}
+
+func (r *promotionRepository) DeleteVoucher(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM vouchers WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus voucher: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrVoucherNotFound
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"backend/pkg/utils"
+)
+
+type testOrderItem struct {
+	productID string
+	name      string
+	price     float64
+	qty       int64
+}
+
+func insertTestCategory(t *testing.T, conn *sql.DB, name string) string {
+	t.Helper()
+	id := utils.GenerateULID()
+	mustExec(t, conn, `INSERT INTO categories (id, name) VALUES (?, ?)`, id, name)
+	return id
+}
+
+// insertTestOrder membuat order dengan created_at disimpan seperti CURRENT_TIMESTAMP (UTC)
+func insertTestOrder(t *testing.T, conn *sql.DB, createdAt time.Time, items ...testOrderItem) string {
+	t.Helper()
+	orderID := utils.GenerateULID()
+	total := 0.0
+	for _, item := range items {
+		total += item.price * float64(item.qty)
+	}
+	mustExec(t, conn, `INSERT INTO orders (id, table_number, total_amount, created_at) VALUES (?, 'A1', ?, ?)`,
+		orderID, total, createdAt.UTC().Format(priceListTimeLayout))
+	for _, item := range items {
+		mustExec(t, conn, `
+			INSERT INTO order_items (id, order_id, product_id, product_name, qty, price, destination)
+			VALUES (?, ?, ?, ?, ?, ?, 'kitchen')
+		`, utils.GenerateULID(), orderID, item.productID, item.name, item.qty, item.price)
+	}
+	return orderID
+}
+
+type testPromotion struct {
+	name            string
+	promoType       string
+	scope           string
+	targetIDs       string
+	value           float64
+	buyQty, getQty  int64
+	minSpend        float64
+	daysOfWeek      string
+	startTime       string
+	endTime         string
+	requiresVoucher bool
+	priority        int64
+	inactive        bool
+}
+
+func insertTestPromotion(t *testing.T, conn *sql.DB, promo testPromotion) string {
+	t.Helper()
+	id := utils.GenerateULID()
+	scope := promo.scope
+	if scope == "" {
+		scope = "order"
+	}
+	targetIDs := promo.targetIDs
+	if targetIDs == "" {
+		targetIDs = "[]"
+	}
+	requiresVoucher, isActive := 0, 1
+	if promo.requiresVoucher {
+		requiresVoucher = 1
+	}
+	if promo.inactive {
+		isActive = 0
+	}
+	mustExec(t, conn, `
+		INSERT INTO promotions (
+			id, name, promo_type, scope, target_ids, value, buy_qty, get_qty, min_spend,
+			days_of_week, start_time, end_time, requires_voucher, priority, is_active
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, promo.name, promo.promoType, scope, targetIDs, promo.value, promo.buyQty, promo.getQty, promo.minSpend,
+		promo.daysOfWeek, promo.startTime, promo.endTime, requiresVoucher, promo.priority, isActive)
+	return id
+}
+
+func TestApplyPromotions(t *testing.T) {
+	ctx := context.Background()
+	// Senin 16:30 waktu lokal
+	orderTime := time.Date(2026, 10, 19, 16, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		promos    func(foodCategory, steakID string) []testPromotion
+		wantTotal float64
+		wantRows  int
+	}{
+		{
+			name:      "tanpa promosi",
+			promos:    func(string, string) []testPromotion { return nil },
+			wantTotal: 0,
+		},
+		{
+			name: "persentase seluruh order",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Diskon 10%", promoType: "percentage_off", value: 10}}
+			},
+			wantTotal: -12000,
+			wantRows:  1,
+		},
+		{
+			name: "happy hour kategori di dalam jendela",
+			promos: func(food, _ string) []testPromotion {
+				return []testPromotion{{name: "Happy Hour", promoType: "percentage_off", scope: "category",
+					targetIDs: `["` + food + `"]`, value: 20, startTime: "15:00", endTime: "18:00"}}
+			},
+			wantTotal: -20000,
+			wantRows:  1,
+		},
+		{
+			name: "happy hour di luar jendela",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Dinner", promoType: "percentage_off", value: 20, startTime: "18:00", endTime: "21:00"}}
+			},
+			wantTotal: 0,
+		},
+		{
+			name: "jendela lewat tengah malam tidak berlaku sore hari",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Late Night", promoType: "fixed_off", value: 5000, startTime: "22:00", endTime: "02:00"}}
+			},
+			wantTotal: 0,
+		},
+		{
+			name: "hari tidak cocok",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Weekend", promoType: "fixed_off", value: 5000, daysOfWeek: "0,6"}}
+			},
+			wantTotal: 0,
+		},
+		{
+			name: "hari cocok",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Weekday", promoType: "fixed_off", value: 5000, daysOfWeek: "1,2,3,4,5"}}
+			},
+			wantTotal: -5000,
+			wantRows:  1,
+		},
+		{
+			name: "promosi bertumpuk",
+			promos: func(food, _ string) []testPromotion {
+				return []testPromotion{
+					{name: "Diskon 10%", promoType: "percentage_off", value: 10, priority: 10},
+					{name: "Potongan 5rb", promoType: "fixed_off", value: 5000, priority: 5},
+					{name: "Happy Hour", promoType: "percentage_off", scope: "category",
+						targetIDs: `["` + food + `"]`, value: 20, startTime: "15:00", endTime: "18:00"},
+				}
+			},
+			wantTotal: -37000,
+			wantRows:  3,
+		},
+		{
+			name: "total diskon bertumpuk dibatasi subtotal",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{
+					{name: "Potongan besar", promoType: "fixed_off", value: 100000, priority: 2},
+					{name: "Potongan kedua", promoType: "fixed_off", value: 50000, priority: 1},
+					{name: "Potongan ketiga", promoType: "fixed_off", value: 1000},
+				}
+			},
+			wantTotal: -120000,
+			wantRows:  2,
+		},
+		{
+			name: "minimal belanja tidak terpenuhi",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Min 200rb", promoType: "fixed_off", value: 20000, minSpend: 200000}}
+			},
+			wantTotal: 0,
+		},
+		{
+			name: "promosi voucher tanpa voucher di order",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Voucher", promoType: "fixed_off", value: 10000, requiresVoucher: true}}
+			},
+			wantTotal: 0,
+		},
+		{
+			name: "promosi nonaktif",
+			promos: func(string, string) []testPromotion {
+				return []testPromotion{{name: "Nonaktif", promoType: "fixed_off", value: 10000, inactive: true}}
+			},
+			wantTotal: 0,
+		},
+		{
+			name: "beli 1 gratis 1 produk",
+			promos: func(_, steak string) []testPromotion {
+				return []testPromotion{{name: "B1G1", promoType: "buy_x_get_y", scope: "product",
+					targetIDs: `["` + steak + `"]`, buyQty: 1, getQty: 1}}
+			},
+			wantTotal: -50000,
+			wantRows:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			food := insertTestCategory(t, conn, "Makanan")
+			drink := insertTestCategory(t, conn, "Minuman")
+			steak := insertTestProduct(t, conn, "Steak", 50000, food)
+			tea := insertTestProduct(t, conn, "Es Teh", 20000, drink)
+			orderID := insertTestOrder(t, conn, orderTime,
+				testOrderItem{productID: steak, name: "Steak", price: 50000, qty: 2},
+				testOrderItem{productID: tea, name: "Es Teh", price: 20000, qty: 1},
+			)
+			for _, promo := range tt.promos(food, steak) {
+				insertTestPromotion(t, conn, promo)
+			}
+
+			tx, err := conn.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("begin tx: %v", err)
+			}
+			defer tx.Rollback()
+
+			total, err := applyPromotions(ctx, tx, orderID, 120000)
+			if err != nil {
+				t.Fatalf("applyPromotions: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total = %v, want %v", total, tt.wantTotal)
+			}
+
+			var rows int
+			var stored float64
+			if err := tx.QueryRowContext(ctx, `
+				SELECT COUNT(*), COALESCE(SUM(applied_amount), 0)
+				FROM order_additional_charges
+				WHERE order_id = ? AND promotion_id IS NOT NULL
+			`, orderID).Scan(&rows, &stored); err != nil {
+				t.Fatalf("baca baris promosi: %v", err)
+			}
+			if rows != tt.wantRows {
+				t.Errorf("baris promosi = %d, want %d", rows, tt.wantRows)
+			}
+			if stored != tt.wantTotal {
+				t.Errorf("applied_amount tersimpan = %v, want %v", stored, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestPromotionActiveAt(t *testing.T) {
+	at := time.Date(2026, 10, 19, 23, 30, 0, 0, time.Local)
+	start := at.Add(-time.Hour)
+	end := at.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		promo Promotion
+		want  bool
+	}{
+		{"tanpa batasan", Promotion{}, true},
+		{"sebelum periode mulai", Promotion{StartAt: &end}, false},
+		{"setelah periode berakhir", Promotion{EndAt: &start}, false},
+		{"di dalam periode", Promotion{StartAt: &start, EndAt: &end}, true},
+		{"jendela lewat tengah malam", Promotion{StartTime: "22:00", EndTime: "02:00"}, true},
+		{"jendela siang", Promotion{StartTime: "11:00", EndTime: "14:00"}, false},
+		{"hari Senin", Promotion{DaysOfWeek: "1"}, true},
+		{"hari Minggu saja", Promotion{DaysOfWeek: "0"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := promotionActiveAt(tt.promo, at); got != tt.want {
+				t.Errorf("promotionActiveAt() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Reservation adalah pemesanan meja untuk slot reserved_at s/d ends_at. Meja yang
+// ditugaskan berstatus reserved menjelang slot dan occupied saat tamu duduk.
+type Reservation struct {
+	ID              string     `json:"id"`
+	CustomerID      string     `json:"customer_id"`
+	CustomerName    string     `json:"customer_name"`
+	CustomerPhone   string     `json:"customer_phone"`
+	Pax             int64      `json:"pax"`
+	ReservedAt      time.Time  `json:"reserved_at"`
+	DurationMinutes int64      `json:"duration_minutes"`
+	EndsAt          time.Time  `json:"ends_at"`
+	TableID         *string    `json:"table_id"`
+	TableNumber     string     `json:"table_number"`
+	TableCapacity   int64      `json:"table_capacity"`
+	Status          string     `json:"status"`
+	OrderID         *string    `json:"order_id"`
+	Note            string     `json:"note"`
+	CancelReason    string     `json:"cancel_reason"`
+	DepositBalance  float64    `json:"deposit_balance"`
+	SeatedAt        *time.Time `json:"seated_at"`
+	CancelledAt     *time.Time `json:"cancelled_at"`
+	CreatedBy       string     `json:"created_by"`
+	CreatedByName   string     `json:"created_by_name,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// ReservationInput membuat reservasi. Pelanggan dipilih lewat CustomerID atau dicari
+// per nomor HP (didaftarkan jika belum ada). TableID kosong = meja ditugaskan nanti.
+type ReservationInput struct {
+	CustomerID      string
+	CustomerName    string
+	CustomerPhone   string
+	Pax             int64
+	ReservedAt      time.Time
+	DurationMinutes int64
+	TableID         string
+	Note            string
+	CreatedBy       string
+}
+
+// ReservationUpdate mengubah reservasi yang masih booked; field nil tidak diubah.
+// TableID kosong melepas meja dari reservasi.
+type ReservationUpdate struct {
+	Pax             *int64
+	ReservedAt      *time.Time
+	DurationMinutes *int64
+	TableID         *string
+	Note            *string
+}
+
+type ReservationFilter struct {
+	From       time.Time
+	To         time.Time
+	Status     string
+	TableID    string
+	CustomerID string
+}
+
+// TableAvailability adalah kecocokan satu meja untuk slot reservasi. Meja diurutkan
+// dari kapasitas terkecil yang cukup sehingga meja pertama yang Available adalah
+// pilihan terbaik.
+type TableAvailability struct {
+	TableID        string   `json:"table_id"`
+	TableNumber    string   `json:"table_number"`
+	Capacity       int64    `json:"capacity"`
+	Status         string   `json:"status"`
+	Available      bool     `json:"available"`
+	ConflictingIDs []string `json:"conflicting_reservation_ids"`
+}
+
+// ReservationTransitions adalah hasil satu putaran transisi otomatis reservasi
+type ReservationTransitions struct {
+	ReservedTables []string `json:"reserved_tables"`
+	ReleasedTables []string `json:"released_tables"`
+	NoShowIDs      []string `json:"no_show_ids"`
+}
+
+// WaitlistEntry adalah tamu walk-in yang menunggu meja. Position dan WaitedMinutes
+// dihitung saat dibaca untuk entry yang masih menunggu.
+type WaitlistEntry struct {
+	ID                string     `json:"id"`
+	CustomerID        *string    `json:"customer_id"`
+	CustomerName      string     `json:"customer_name"`
+	CustomerPhone     string     `json:"customer_phone"`
+	Pax               int64      `json:"pax"`
+	QuotedWaitMinutes int64      `json:"quoted_wait_minutes"`
+	Position          int        `json:"position"`
+	WaitedMinutes     int64      `json:"waited_minutes"`
+	Status            string     `json:"status"`
+	TableID           *string    `json:"table_id"`
+	TableNumber       string     `json:"table_number"`
+	OrderID           *string    `json:"order_id"`
+	Note              string     `json:"note"`
+	NotifiedAt        *time.Time `json:"notified_at"`
+	SeatedAt          *time.Time `json:"seated_at"`
+	LeftAt            *time.Time `json:"left_at"`
+	CreatedBy         string     `json:"created_by"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// WaitlistInput mendaftarkan tamu walk-in; QuotedWaitMinutes nil = estimasi otomatis
+type WaitlistInput struct {
+	CustomerName      string
+	CustomerPhone     string
+	Pax               int64
+	QuotedWaitMinutes *int64
+	Note              string
+	CreatedBy         string
+}
+
+// ReservationSettings mengatur transisi otomatis status meja reservasi
+type ReservationSettings struct {
+	// HoldBefore adalah jarak sebelum slot saat meja ditandai reserved
+	HoldBefore time.Duration
+	// NoShowGrace adalah toleransi keterlambatan sebelum reservasi menjadi no_show
+	NoShowGrace time.Duration
+	// DefaultDuration adalah lama pemakaian meja per reservasi/tamu walk-in
+	DefaultDuration time.Duration
+}
+
+const (
+	ReservationStatusBooked    = "booked"
+	ReservationStatusSeated    = "seated"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusNoShow    = "no_show"
+
+	WaitlistStatusWaiting  = "waiting"
+	WaitlistStatusNotified = "notified"
+	WaitlistStatusSeated   = "seated"
+	WaitlistStatusLeft     = "left"
+)
+
+var (
+	ErrReservationNotFound   = errors.New("reservasi tidak ditemukan")
+	ErrInvalidReservation    = errors.New("data reservasi tidak valid")
+	ErrReservationConflict   = errors.New("meja sudah dipesan pada slot tersebut")
+	ErrReservationClosed     = errors.New("reservasi sudah duduk, dibatalkan atau no-show")
+	ErrWaitlistEntryNotFound = errors.New("antrian tidak ditemukan")
+	ErrInvalidWaitlistEntry  = errors.New("data antrian tidak valid")
+	ErrWaitlistEntryClosed   = errors.New("antrian sudah duduk atau pergi")
+)
+
+type ReservationRepository interface {
+	Create(ctx context.Context, input ReservationInput, settings ReservationSettings) (*Reservation, error)
+	GetByID(ctx context.Context, id string) (*Reservation, error)
+	List(ctx context.Context, filter ReservationFilter) ([]Reservation, error)
+	Update(ctx context.Context, id string, input ReservationUpdate, settings ReservationSettings) (*Reservation, error)
+	Assign(ctx context.Context, id, tableID string, settings ReservationSettings) (*Reservation, error)
+	Seat(ctx context.Context, id, tableID, orderID string, settings ReservationSettings) (*Reservation, error)
+	Cancel(ctx context.Context, id, reason string, settings ReservationSettings) (*Reservation, error)
+	Availability(ctx context.Context, start time.Time, durationMinutes, pax int64, settings ReservationSettings) ([]TableAvailability, error)
+	ProcessTransitions(ctx context.Context, now time.Time, settings ReservationSettings) (*ReservationTransitions, error)
+
+	AddWaitlist(ctx context.Context, input WaitlistInput, settings ReservationSettings) (*WaitlistEntry, error)
+	ListWaitlist(ctx context.Context, activeOnly bool) ([]WaitlistEntry, error)
+	NotifyWaitlist(ctx context.Context, id string) (*WaitlistEntry, error)
+	SeatWaitlist(ctx context.Context, id, tableID, orderID string) (*WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, id string) (*WaitlistEntry, error)
+}
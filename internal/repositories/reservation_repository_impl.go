@@ -0,0 +1,1119 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type reservationRepository struct {
+	db *sql.DB
+}
+
+func NewReservationRepository(dbConn *sql.DB) ReservationRepository {
+	return &reservationRepository{db: dbConn}
+}
+
+const reservationSelect = `
+	SELECT r.id, r.customer_id, COALESCE(c.name, ''), COALESCE(c.phone, ''), r.pax, r.reserved_at,
+	       r.duration_minutes, r.ends_at, r.table_id, COALESCE(t.table_number, ''), COALESCE(t.capacity, 0),
+	       r.status, r.order_id, COALESCE(r.note, ''), COALESCE(r.cancel_reason, ''),
+	       COALESCE((SELECT SUM(d.balance) FROM deposits d WHERE d.reservation_id = r.id AND d.status = 'held'), 0),
+	       r.seated_at, r.cancelled_at, r.created_by, COALESCE(u.full_name, ''), r.created_at, r.updated_at
+	FROM reservations r
+	LEFT JOIN customers c ON c.id = r.customer_id
+	LEFT JOIN tables t ON t.id = r.table_id
+	LEFT JOIN users u ON u.id = r.created_by
+`
+
+func scanReservation(scanner interface{ Scan(dest ...any) error }) (*Reservation, error) {
+	var reservation Reservation
+	var tableID, orderID sql.NullString
+	var seatedAt, cancelledAt sql.NullTime
+	if err := scanner.Scan(&reservation.ID, &reservation.CustomerID, &reservation.CustomerName, &reservation.CustomerPhone,
+		&reservation.Pax, &reservation.ReservedAt, &reservation.DurationMinutes, &reservation.EndsAt, &tableID,
+		&reservation.TableNumber, &reservation.TableCapacity, &reservation.Status, &orderID, &reservation.Note,
+		&reservation.CancelReason, &reservation.DepositBalance, &seatedAt, &cancelledAt, &reservation.CreatedBy,
+		&reservation.CreatedByName, &reservation.CreatedAt, &reservation.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if tableID.Valid {
+		reservation.TableID = &tableID.String
+	}
+	if orderID.Valid {
+		reservation.OrderID = &orderID.String
+	}
+	if seatedAt.Valid {
+		reservation.SeatedAt = &seatedAt.Time
+	}
+	if cancelledAt.Valid {
+		reservation.CancelledAt = &cancelledAt.Time
+	}
+	return &reservation, nil
+}
+
+func loadReservation(ctx context.Context, q db.DBTX, id string) (*Reservation, error) {
+	reservation, err := scanReservation(q.QueryRowContext(ctx, reservationSelect+" WHERE r.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrReservationNotFound
+	}
+	return reservation, err
+}
+
+// findOrCreateCustomer mencari pelanggan per nomor HP dan mendaftarkannya jika belum
+// ada, sama seperti saat order dibuat
+func findOrCreateCustomer(ctx context.Context, q db.DBTX, name, phone string, invalid error) (*db.Customer, error) {
+	queries := db.New(q)
+	customer, err := queries.GetCustomerByPhone(ctx, phone)
+	if err == nil {
+		return &customer, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: nomor HP belum terdaftar, isi nama pelanggan untuk mendaftarkan", invalid)
+	}
+	customer, err = queries.CreateCustomer(ctx, db.CreateCustomerParams{
+		ID:    utils.GenerateULID(),
+		Name:  name,
+		Phone: phone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal menyimpan data pelanggan: %w", err)
+	}
+	return &customer, nil
+}
+
+func resolveReservationCustomer(ctx context.Context, q db.DBTX, input ReservationInput) (string, error) {
+	if input.CustomerID != "" {
+		var exists int
+		if err := q.QueryRowContext(ctx, "SELECT COUNT(*) FROM customers WHERE id = ?", input.CustomerID).Scan(&exists); err != nil {
+			return "", err
+		}
+		if exists == 0 {
+			return "", fmt.Errorf("%w: pelanggan tidak ditemukan", ErrInvalidReservation)
+		}
+		return input.CustomerID, nil
+	}
+	phone := strings.TrimSpace(input.CustomerPhone)
+	if phone == "" {
+		return "", fmt.Errorf("%w: customer_id atau customer_phone wajib diisi", ErrInvalidReservation)
+	}
+	customer, err := findOrCreateCustomer(ctx, q, strings.TrimSpace(input.CustomerName), phone, ErrInvalidReservation)
+	if err != nil {
+		return "", err
+	}
+	return customer.ID, nil
+}
+
+// reservationConflicts mengembalikan reservasi aktif lain di meja yang slotnya beririsan
+func reservationConflicts(ctx context.Context, q db.DBTX, tableID string, start, end time.Time, excludeID string) ([]string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id
+		FROM reservations
+		WHERE table_id = ?
+		  AND id != ?
+		  AND status IN ('booked', 'seated')
+		  AND reserved_at < ?
+		  AND ends_at > ?
+		ORDER BY reserved_at
+	`, tableID, excludeID, end, start)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// checkTableSlot memastikan meja muat untuk pax dan tidak bentrok dengan reservasi
+// lain maupun tamu yang sedang duduk (untuk slot yang sudah dekat)
+func checkTableSlot(ctx context.Context, q db.DBTX, tableID string, pax int64, start, end time.Time, excludeID string, settings ReservationSettings) (*db.Table, error) {
+	table, err := db.New(q).GetTableByID(ctx, tableID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: meja tidak ditemukan", ErrInvalidReservation)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if pax > table.Capacity {
+		return nil, fmt.Errorf("%w: kapasitas meja %s hanya %d orang", ErrInvalidReservation, table.TableNumber, table.Capacity)
+	}
+	conflicts, err := reservationConflicts(ctx, q, tableID, start, end, excludeID)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: meja %s", ErrReservationConflict, table.TableNumber)
+	}
+	if IsTableInUse(table.Status) && start.Before(time.Now().UTC().Add(settings.HoldBefore)) {
+		return nil, fmt.Errorf("%w: meja %s sedang terpakai", ErrReservationConflict, table.TableNumber)
+	}
+	return &table, nil
+}
+
+// refreshTableHold menyesuaikan status meja dengan reservasi yang akan datang: reserved
+// jika ada reservasi booked dalam jendela hold, kembali available jika tidak ada lagi.
+// Meja occupied tidak diubah. Mengembalikan status baru (kosong jika tidak berubah).
+func refreshTableHold(ctx context.Context, q db.DBTX, tableID string, now time.Time, settings ReservationSettings) (string, string, error) {
+	var tableNumber, status string
+	err := q.QueryRowContext(ctx, "SELECT table_number, status FROM tables WHERE id = ?", tableID).Scan(&tableNumber, &status)
+	if err == sql.ErrNoRows {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	var upcoming int
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM reservations
+		WHERE table_id = ?
+		  AND status = 'booked'
+		  AND reserved_at <= ?
+		  AND reserved_at > ?
+	`, tableID, now.Add(settings.HoldBefore), now.Add(-settings.NoShowGrace)).Scan(&upcoming); err != nil {
+		return "", "", err
+	}
+
+	newStatus := ""
+	switch {
+	case upcoming > 0 && status == "available":
+		newStatus = "reserved"
+	case upcoming == 0 && status == "reserved":
+		newStatus = "available"
+	default:
+		return tableNumber, "", nil
+	}
+	if err := db.New(q).UpdateTableStatus(ctx, db.UpdateTableStatusParams{
+		Status:      newStatus,
+		TableNumber: tableNumber,
+	}); err != nil {
+		return "", "", err
+	}
+	return tableNumber, newStatus, nil
+}
+
+// checkSeatOrder memastikan order yang ditautkan masih berjalan dan mengembalikan nomor mejanya
+func checkSeatOrder(ctx context.Context, q db.DBTX, orderID string, invalid error) (string, error) {
+	var tableNumber, paymentStatus string
+	var voidedAt sql.NullTime
+	err := q.QueryRowContext(ctx, `
+		SELECT table_number, payment_status, voided_at
+		FROM orders
+		WHERE id = ?
+	`, orderID).Scan(&tableNumber, &paymentStatus, &voidedAt)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("%w: order tidak ditemukan", invalid)
+	}
+	if err != nil {
+		return "", err
+	}
+	if voidedAt.Valid {
+		return "", fmt.Errorf("%w: order sudah di-void", invalid)
+	}
+	if paymentStatus == "paid" {
+		return "", fmt.Errorf("%w: order sudah lunas", invalid)
+	}
+	return tableNumber, nil
+}
+
+// attachReservationOrder menautkan order ke reservasi: pelanggan reservasi menjadi
+// pelanggan order (jika order belum punya) dan deposit reservasi dipakai untuk order
+func attachReservationOrder(ctx context.Context, q db.DBTX, reservationID, orderID string, now time.Time) error {
+	if _, err := q.ExecContext(ctx, `
+		UPDATE orders
+		SET customer_id = r.customer_id,
+		    customer_name = c.name,
+		    customer_phone = c.phone,
+		    updated_at = ?
+		FROM reservations r
+		JOIN customers c ON c.id = r.customer_id
+		WHERE r.id = ?
+		  AND orders.id = ?
+		  AND COALESCE(orders.customer_id, '') = ''
+	`, now, reservationID, orderID); err != nil {
+		return err
+	}
+	if _, err := q.ExecContext(ctx, `
+		UPDATE deposits
+		SET order_id = ?, updated_at = ?
+		WHERE reservation_id = ?
+		  AND status = 'held'
+		  AND order_id IS NULL
+	`, orderID, now, reservationID); err != nil {
+		return err
+	}
+	_, err := q.ExecContext(ctx, `
+		UPDATE reservations
+		SET order_id = ?, updated_at = ?
+		WHERE id = ?
+	`, orderID, now, reservationID)
+	return err
+}
+
+// AttachSeatedReservation menautkan order baru di sebuah meja ke reservasi yang sudah
+// duduk di meja tersebut tetapi belum punya order. Mengembalikan ID reservasi yang
+// ditautkan (kosong jika tidak ada).
+func AttachSeatedReservation(ctx context.Context, dbConn *sql.DB, tableNumber, orderID string) (string, error) {
+	tx, err := dbConn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var reservationID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT r.id
+		FROM reservations r
+		JOIN tables t ON t.id = r.table_id
+		WHERE t.table_number = ?
+		  AND r.status = 'seated'
+		  AND r.order_id IS NULL
+		ORDER BY r.seated_at DESC
+		LIMIT 1
+	`, tableNumber).Scan(&reservationID)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := attachReservationOrder(ctx, tx, reservationID, orderID, time.Now().UTC()); err != nil {
+		return "", err
+	}
+	return reservationID, tx.Commit()
+}
+
+func (r *reservationRepository) Create(ctx context.Context, input ReservationInput, settings ReservationSettings) (*Reservation, error) {
+	if input.Pax <= 0 {
+		return nil, fmt.Errorf("%w: pax harus lebih dari 0", ErrInvalidReservation)
+	}
+	if input.ReservedAt.IsZero() {
+		return nil, fmt.Errorf("%w: reserved_at wajib diisi", ErrInvalidReservation)
+	}
+	if input.DurationMinutes <= 0 {
+		input.DurationMinutes = int64(settings.DefaultDuration / time.Minute)
+	}
+	now := time.Now().UTC()
+	start := input.ReservedAt.UTC().Truncate(time.Minute)
+	if !start.Add(settings.NoShowGrace).After(now) {
+		return nil, fmt.Errorf("%w: slot reservasi sudah lewat", ErrInvalidReservation)
+	}
+	end := start.Add(time.Duration(input.DurationMinutes) * time.Minute)
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	customerID, err := resolveReservationCustomer(ctx, tx, input)
+	if err != nil {
+		return nil, err
+	}
+	var tableID *string
+	if input.TableID != "" {
+		if _, err := checkTableSlot(ctx, tx, input.TableID, input.Pax, start, end, "", settings); err != nil {
+			return nil, err
+		}
+		tableID = &input.TableID
+	}
+
+	id := utils.GenerateULID()
+	note := strings.TrimSpace(input.Note)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO reservations (
+			id, customer_id, pax, reserved_at, duration_minutes, ends_at, table_id, status, note,
+			created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, customerID, input.Pax, start, input.DurationMinutes, end, tableID, ReservationStatusBooked,
+		sql.NullString{String: note, Valid: note != ""}, input.CreatedBy, now, now); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan reservasi: %w", err)
+	}
+	if tableID != nil {
+		if _, _, err := refreshTableHold(ctx, tx, *tableID, now, settings); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return loadReservation(ctx, r.db, id)
+}
+
+func (r *reservationRepository) GetByID(ctx context.Context, id string) (*Reservation, error) {
+	return loadReservation(ctx, r.db, id)
+}
+
+func (r *reservationRepository) List(ctx context.Context, filter ReservationFilter) ([]Reservation, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "r.reserved_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "r.reserved_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "r.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.TableID != "" {
+		conditions = append(conditions, "r.table_id = ?")
+		args = append(args, filter.TableID)
+	}
+	if filter.CustomerID != "" {
+		conditions = append(conditions, "r.customer_id = ?")
+		args = append(args, filter.CustomerID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, reservationSelect+" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY r.reserved_at, r.id", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reservations := []Reservation{}
+	for rows.Next() {
+		reservation, err := scanReservation(rows)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, *reservation)
+	}
+	return reservations, rows.Err()
+}
+
+func (r *reservationRepository) Update(ctx context.Context, id string, input ReservationUpdate, settings ReservationSettings) (*Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := loadReservation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != ReservationStatusBooked {
+		return nil, ErrReservationClosed
+	}
+
+	now := time.Now().UTC()
+	pax := current.Pax
+	if input.Pax != nil {
+		pax = *input.Pax
+	}
+	if pax <= 0 {
+		return nil, fmt.Errorf("%w: pax harus lebih dari 0", ErrInvalidReservation)
+	}
+	start := current.ReservedAt.UTC()
+	if input.ReservedAt != nil {
+		start = input.ReservedAt.UTC().Truncate(time.Minute)
+		if !start.Add(settings.NoShowGrace).After(now) {
+			return nil, fmt.Errorf("%w: slot reservasi sudah lewat", ErrInvalidReservation)
+		}
+	}
+	duration := current.DurationMinutes
+	if input.DurationMinutes != nil {
+		duration = *input.DurationMinutes
+	}
+	if duration <= 0 {
+		return nil, fmt.Errorf("%w: duration_minutes harus lebih dari 0", ErrInvalidReservation)
+	}
+	end := start.Add(time.Duration(duration) * time.Minute)
+	tableID := current.TableID
+	if input.TableID != nil {
+		tableID = nullableID(*input.TableID)
+	}
+	note := current.Note
+	if input.Note != nil {
+		note = strings.TrimSpace(*input.Note)
+	}
+
+	if tableID != nil {
+		if _, err := checkTableSlot(ctx, tx, *tableID, pax, start, end, id, settings); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET pax = ?, reserved_at = ?, duration_minutes = ?, ends_at = ?, table_id = ?, note = ?, updated_at = ?
+		WHERE id = ?
+	`, pax, start, duration, end, tableID, sql.NullString{String: note, Valid: note != ""}, now, id); err != nil {
+		return nil, err
+	}
+
+	// Meja lama dilepas dan meja baru ditahan sesuai slot terbaru
+	if current.TableID != nil {
+		if _, _, err := refreshTableHold(ctx, tx, *current.TableID, now, settings); err != nil {
+			return nil, err
+		}
+	}
+	if tableID != nil && (current.TableID == nil || *current.TableID != *tableID) {
+		if _, _, err := refreshTableHold(ctx, tx, *tableID, now, settings); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return loadReservation(ctx, r.db, id)
+}
+
+// Assign menugaskan meja ke reservasi; tableID kosong = pilih meja terkecil yang
+// cukup dan bebas pada slot reservasi
+func (r *reservationRepository) Assign(ctx context.Context, id, tableID string, settings ReservationSettings) (*Reservation, error) {
+	if tableID == "" {
+		current, err := loadReservation(ctx, r.db, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.Status != ReservationStatusBooked {
+			return nil, ErrReservationClosed
+		}
+		tables, err := r.availability(ctx, current.ReservedAt, current.EndsAt, current.Pax, current.ID, settings)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			if table.Available {
+				tableID = table.TableID
+				break
+			}
+		}
+		if tableID == "" {
+			return nil, fmt.Errorf("%w: tidak ada meja tersedia untuk %d orang", ErrReservationConflict, current.Pax)
+		}
+	}
+	return r.Update(ctx, id, ReservationUpdate{TableID: &tableID}, settings)
+}
+
+// Seat menandai tamu reservasi sudah duduk. Meja diambil dari tableID, order yang
+// ditautkan, atau meja reservasi; meja menjadi occupied.
+func (r *reservationRepository) Seat(ctx context.Context, id, tableID, orderID string, settings ReservationSettings) (*Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := loadReservation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != ReservationStatusBooked {
+		return nil, ErrReservationClosed
+	}
+
+	if orderID != "" {
+		orderTable, err := checkSeatOrder(ctx, tx, orderID, ErrInvalidReservation)
+		if err != nil {
+			return nil, err
+		}
+		if tableID == "" {
+			table, err := db.New(tx).GetTableByNumber(ctx, orderTable)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, err
+			}
+			tableID = table.ID
+		}
+	}
+	if tableID == "" && current.TableID != nil {
+		tableID = *current.TableID
+	}
+	if tableID == "" {
+		return nil, fmt.Errorf("%w: meja reservasi belum ditentukan", ErrInvalidReservation)
+	}
+
+	// Tamu yang datang lebih awal cukup bebas bentrok sampai akhir slotnya sendiri
+	now := time.Now().UTC()
+	end := current.EndsAt
+	if !end.After(now) {
+		end = now.Add(time.Minute)
+	}
+	table, err := checkTableSlot(ctx, tx, tableID, current.Pax, now, end, id, ReservationSettings{})
+	if err != nil {
+		return nil, err
+	}
+	if orderID == "" && IsTableInUse(table.Status) {
+		return nil, fmt.Errorf("%w: meja %s sedang terpakai", ErrReservationConflict, table.TableNumber)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET status = ?, table_id = ?, seated_at = ?, updated_at = ?
+		WHERE id = ?
+	`, ReservationStatusSeated, tableID, now, now, id); err != nil {
+		return nil, err
+	}
+	if orderID != "" {
+		if err := attachReservationOrder(ctx, tx, id, orderID, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := setTableOccupied(ctx, tx, table.TableNumber); err != nil {
+		return nil, err
+	}
+	if current.TableID != nil && *current.TableID != tableID {
+		if _, _, err := refreshTableHold(ctx, tx, *current.TableID, now, settings); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return loadReservation(ctx, r.db, id)
+}
+
+// Cancel membatalkan reservasi booked; deposit reservasi tetap tersimpan untuk
+// direfund atau dipakai pelanggan
+func (r *reservationRepository) Cancel(ctx context.Context, id, reason string, settings ReservationSettings) (*Reservation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	current, err := loadReservation(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Status != ReservationStatusBooked {
+		return nil, ErrReservationClosed
+	}
+
+	now := time.Now().UTC()
+	reason = strings.TrimSpace(reason)
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reservations
+		SET status = ?, cancel_reason = ?, cancelled_at = ?, updated_at = ?
+		WHERE id = ?
+	`, ReservationStatusCancelled, sql.NullString{String: reason, Valid: reason != ""}, now, now, id); err != nil {
+		return nil, err
+	}
+	if current.TableID != nil {
+		if _, _, err := refreshTableHold(ctx, tx, *current.TableID, now, settings); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return loadReservation(ctx, r.db, id)
+}
+
+func (r *reservationRepository) Availability(ctx context.Context, start time.Time, durationMinutes, pax int64, settings ReservationSettings) ([]TableAvailability, error) {
+	if pax <= 0 {
+		return nil, fmt.Errorf("%w: pax harus lebih dari 0", ErrInvalidReservation)
+	}
+	if durationMinutes <= 0 {
+		durationMinutes = int64(settings.DefaultDuration / time.Minute)
+	}
+	start = start.UTC().Truncate(time.Minute)
+	return r.availability(ctx, start, start.Add(time.Duration(durationMinutes)*time.Minute), pax, "", settings)
+}
+
+func (r *reservationRepository) availability(ctx context.Context, start, end time.Time, pax int64, excludeID string, settings ReservationSettings) ([]TableAvailability, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, table_number, capacity, status
+		FROM tables
+		WHERE capacity >= ?
+		ORDER BY capacity, table_number
+	`, pax)
+	if err != nil {
+		return nil, err
+	}
+	tables := []TableAvailability{}
+	for rows.Next() {
+		var table TableAvailability
+		if err := rows.Scan(&table.TableID, &table.TableNumber, &table.Capacity, &table.Status); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	soon := start.Before(time.Now().UTC().Add(settings.HoldBefore))
+	for i := range tables {
+		conflicts, err := reservationConflicts(ctx, r.db, tables[i].TableID, start, end, excludeID)
+		if err != nil {
+			return nil, err
+		}
+		tables[i].ConflictingIDs = conflicts
+		tables[i].Available = len(conflicts) == 0 && !(soon && IsTableInUse(tables[i].Status))
+	}
+	return tables, nil
+}
+
+// ProcessTransitions menjalankan transisi otomatis: reservasi yang lewat toleransi
+// menjadi no_show, meja dengan reservasi dalam jendela hold menjadi reserved, dan
+// meja reserved tanpa reservasi aktif kembali available
+func (r *reservationRepository) ProcessTransitions(ctx context.Context, now time.Time, settings ReservationSettings) (*ReservationTransitions, error) {
+	now = now.UTC()
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &ReservationTransitions{ReservedTables: []string{}, ReleasedTables: []string{}, NoShowIDs: []string{}}
+	tableIDs := []string{}
+	seen := map[string]bool{}
+	addTable := func(tableID sql.NullString) {
+		if tableID.Valid && !seen[tableID.String] {
+			seen[tableID.String] = true
+			tableIDs = append(tableIDs, tableID.String)
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, table_id
+		FROM reservations
+		WHERE status = 'booked'
+		  AND reserved_at <= ?
+	`, now.Add(-settings.NoShowGrace))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var id string
+		var tableID sql.NullString
+		if err := rows.Scan(&id, &tableID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		result.NoShowIDs = append(result.NoShowIDs, id)
+		addTable(tableID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, id := range result.NoShowIDs {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE reservations
+			SET status = ?, updated_at = ?
+			WHERE id = ?
+		`, ReservationStatusNoShow, now, id); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT DISTINCT table_id
+		FROM reservations
+		WHERE status = 'booked'
+		  AND table_id IS NOT NULL
+		  AND reserved_at <= ?
+	`, now.Add(settings.HoldBefore))
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var tableID sql.NullString
+		if err := rows.Scan(&tableID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		addTable(tableID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, tableID := range tableIDs {
+		tableNumber, status, err := refreshTableHold(ctx, tx, tableID, now, settings)
+		if err != nil {
+			return nil, err
+		}
+		switch status {
+		case "reserved":
+			result.ReservedTables = append(result.ReservedTables, tableNumber)
+		case "available":
+			result.ReleasedTables = append(result.ReleasedTables, tableNumber)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+const waitlistSelect = `
+	SELECT w.id, w.customer_id, w.customer_name, COALESCE(w.customer_phone, ''), w.pax, w.quoted_wait_minutes,
+	       w.status, w.table_id, COALESCE(t.table_number, ''), w.order_id, COALESCE(w.note, ''),
+	       w.notified_at, w.seated_at, w.left_at, w.created_by, w.created_at, w.updated_at
+	FROM waitlist_entries w
+	LEFT JOIN tables t ON t.id = w.table_id
+`
+
+func scanWaitlistEntry(scanner interface{ Scan(dest ...any) error }) (*WaitlistEntry, error) {
+	var entry WaitlistEntry
+	var customerID, tableID, orderID sql.NullString
+	var notifiedAt, seatedAt, leftAt sql.NullTime
+	if err := scanner.Scan(&entry.ID, &customerID, &entry.CustomerName, &entry.CustomerPhone, &entry.Pax,
+		&entry.QuotedWaitMinutes, &entry.Status, &tableID, &entry.TableNumber, &orderID, &entry.Note,
+		&notifiedAt, &seatedAt, &leftAt, &entry.CreatedBy, &entry.CreatedAt, &entry.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if customerID.Valid {
+		entry.CustomerID = &customerID.String
+	}
+	if tableID.Valid {
+		entry.TableID = &tableID.String
+	}
+	if orderID.Valid {
+		entry.OrderID = &orderID.String
+	}
+	if notifiedAt.Valid {
+		entry.NotifiedAt = &notifiedAt.Time
+	}
+	if seatedAt.Valid {
+		entry.SeatedAt = &seatedAt.Time
+	}
+	if leftAt.Valid {
+		entry.LeftAt = &leftAt.Time
+	}
+
+	waitedUntil := time.Now().UTC()
+	if entry.SeatedAt != nil {
+		waitedUntil = *entry.SeatedAt
+	} else if entry.LeftAt != nil {
+		waitedUntil = *entry.LeftAt
+	}
+	entry.WaitedMinutes = int64(waitedUntil.Sub(entry.CreatedAt) / time.Minute)
+	return &entry, nil
+}
+
+func loadWaitlistEntry(ctx context.Context, q db.DBTX, id string) (*WaitlistEntry, error) {
+	entry, err := scanWaitlistEntry(q.QueryRowContext(ctx, waitlistSelect+" WHERE w.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrWaitlistEntryNotFound
+	}
+	return entry, err
+}
+
+// estimateWaitMinutes memperkirakan waktu tunggu tamu walk-in: tiap meja yang muat
+// diberi perkiraan jam kosong (meja terpakai = order terlama + durasi, meja reserved =
+// akhir reservasi), lalu antrian di depan mengisi meja yang paling cepat kosong
+func estimateWaitMinutes(ctx context.Context, q db.DBTX, pax int64, now time.Time, settings ReservationSettings) (int64, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT id, table_number, capacity, status
+		FROM tables
+		WHERE capacity >= ?
+	`, pax)
+	if err != nil {
+		return 0, err
+	}
+	type tableInfo struct {
+		id, number, status string
+		capacity           int64
+	}
+	tables := []tableInfo{}
+	maxCapacity := int64(0)
+	for rows.Next() {
+		var table tableInfo
+		if err := rows.Scan(&table.id, &table.number, &table.capacity, &table.status); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		tables = append(tables, table)
+		if table.capacity > maxCapacity {
+			maxCapacity = table.capacity
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(tables) == 0 {
+		return 0, fmt.Errorf("%w: tidak ada meja untuk %d orang", ErrInvalidWaitlistEntry, pax)
+	}
+
+	freeAt := make([]time.Time, 0, len(tables))
+	for _, table := range tables {
+		free := now
+		if IsTableInUse(table.status) {
+			var openedAt time.Time
+			err := q.QueryRowContext(ctx, `
+				SELECT created_at
+				FROM orders
+				WHERE table_number = ?
+				  AND payment_status != 'paid'
+				  AND voided_at IS NULL
+				ORDER BY created_at
+				LIMIT 1
+			`, table.number).Scan(&openedAt)
+			if err != nil && err != sql.ErrNoRows {
+				return 0, err
+			}
+			free = now.Add(settings.DefaultDuration / 2)
+			if err == nil {
+				free = openedAt.Add(settings.DefaultDuration)
+			}
+		}
+		// Reservasi yang beririsan dengan kunjungan walk-in menunda meja sampai reservasi selesai
+		var reservedUntil time.Time
+		err := q.QueryRowContext(ctx, `
+			SELECT ends_at
+			FROM reservations
+			WHERE table_id = ?
+			  AND status IN ('booked', 'seated')
+			  AND reserved_at < ?
+			  AND ends_at > ?
+			ORDER BY ends_at DESC
+			LIMIT 1
+		`, table.id, now.Add(settings.DefaultDuration), now).Scan(&reservedUntil)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, err
+		}
+		if err == nil && reservedUntil.After(free) {
+			free = reservedUntil
+		}
+		if free.Before(now) {
+			free = now
+		}
+		freeAt = append(freeAt, free)
+	}
+
+	var ahead int
+	if err := q.QueryRowContext(ctx, `
+		SELECT COUNT(*)
+		FROM waitlist_entries
+		WHERE status IN ('waiting', 'notified')
+		  AND pax <= ?
+	`, maxCapacity).Scan(&ahead); err != nil {
+		return 0, err
+	}
+
+	sort.Slice(freeAt, func(i, j int) bool { return freeAt[i].Before(freeAt[j]) })
+	for i := 0; i < ahead; i++ {
+		freeAt[0] = freeAt[0].Add(settings.DefaultDuration)
+		sort.Slice(freeAt, func(i, j int) bool { return freeAt[i].Before(freeAt[j]) })
+	}
+	return int64(math.Ceil(freeAt[0].Sub(now).Minutes())), nil
+}
+
+func (r *reservationRepository) AddWaitlist(ctx context.Context, input WaitlistInput, settings ReservationSettings) (*WaitlistEntry, error) {
+	name := strings.TrimSpace(input.CustomerName)
+	phone := strings.TrimSpace(input.CustomerPhone)
+	if input.Pax <= 0 {
+		return nil, fmt.Errorf("%w: pax harus lebih dari 0", ErrInvalidWaitlistEntry)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var customerID *string
+	if phone != "" {
+		customer, err := findOrCreateCustomer(ctx, tx, name, phone, ErrInvalidWaitlistEntry)
+		if err != nil {
+			return nil, err
+		}
+		customerID = &customer.ID
+		name = customer.Name
+	}
+	if name == "" {
+		return nil, fmt.Errorf("%w: nama tamu wajib diisi", ErrInvalidWaitlistEntry)
+	}
+
+	now := time.Now().UTC()
+	quoted, err := estimateWaitMinutes(ctx, tx, input.Pax, now, settings)
+	if err != nil {
+		return nil, err
+	}
+	if input.QuotedWaitMinutes != nil {
+		if *input.QuotedWaitMinutes < 0 {
+			return nil, fmt.Errorf("%w: quoted_wait_minutes tidak boleh negatif", ErrInvalidWaitlistEntry)
+		}
+		quoted = *input.QuotedWaitMinutes
+	}
+
+	id := utils.GenerateULID()
+	note := strings.TrimSpace(input.Note)
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO waitlist_entries (
+			id, customer_id, customer_name, customer_phone, pax, quoted_wait_minutes, status, note,
+			created_by, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, customerID, name, sql.NullString{String: phone, Valid: phone != ""}, input.Pax, quoted,
+		WaitlistStatusWaiting, sql.NullString{String: note, Valid: note != ""}, input.CreatedBy, now, now); err != nil {
+		return nil, fmt.Errorf("gagal menyimpan antrian: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	entries, err := r.ListWaitlist(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+	}
+	return loadWaitlistEntry(ctx, r.db, id)
+}
+
+// ListWaitlist mengembalikan antrian urut waktu daftar; Position diisi untuk tamu
+// yang masih menunggu
+func (r *reservationRepository) ListWaitlist(ctx context.Context, activeOnly bool) ([]WaitlistEntry, error) {
+	query := waitlistSelect
+	if activeOnly {
+		query += " WHERE w.status IN ('waiting', 'notified')"
+	}
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY w.created_at, w.id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []WaitlistEntry{}
+	position := 0
+	for rows.Next() {
+		entry, err := scanWaitlistEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Status == WaitlistStatusWaiting || entry.Status == WaitlistStatusNotified {
+			position++
+			entry.Position = position
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, rows.Err()
+}
+
+func loadActiveWaitlistEntry(ctx context.Context, q db.DBTX, id string) (*WaitlistEntry, error) {
+	entry, err := loadWaitlistEntry(ctx, q, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != WaitlistStatusWaiting && entry.Status != WaitlistStatusNotified {
+		return nil, ErrWaitlistEntryClosed
+	}
+	return entry, nil
+}
+
+// NotifyWaitlist menandai tamu sudah dipanggil karena meja siap
+func (r *reservationRepository) NotifyWaitlist(ctx context.Context, id string) (*WaitlistEntry, error) {
+	if _, err := loadActiveWaitlistEntry(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE waitlist_entries
+		SET status = ?, notified_at = ?, updated_at = ?
+		WHERE id = ?
+	`, WaitlistStatusNotified, now, now, id); err != nil {
+		return nil, err
+	}
+	return loadWaitlistEntry(ctx, r.db, id)
+}
+
+// SeatWaitlist mendudukkan tamu antrian di meja (atau meja order yang ditautkan)
+func (r *reservationRepository) SeatWaitlist(ctx context.Context, id, tableID, orderID string) (*WaitlistEntry, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	entry, err := loadActiveWaitlistEntry(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	queries := db.New(tx)
+	var table db.Table
+	switch {
+	case tableID != "":
+		table, err = queries.GetTableByID(ctx, tableID)
+	case orderID != "":
+		var orderTable string
+		if orderTable, err = checkSeatOrder(ctx, tx, orderID, ErrInvalidWaitlistEntry); err != nil {
+			return nil, err
+		}
+		table, err = queries.GetTableByNumber(ctx, orderTable)
+	default:
+		return nil, fmt.Errorf("%w: table_id atau order_id wajib diisi", ErrInvalidWaitlistEntry)
+	}
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: meja tidak ditemukan", ErrInvalidWaitlistEntry)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if orderID != "" && tableID != "" {
+		if _, err := checkSeatOrder(ctx, tx, orderID, ErrInvalidWaitlistEntry); err != nil {
+			return nil, err
+		}
+	}
+	if entry.Pax > table.Capacity {
+		return nil, fmt.Errorf("%w: kapasitas meja %s hanya %d orang", ErrInvalidWaitlistEntry, table.TableNumber, table.Capacity)
+	}
+	if orderID == "" && IsTableInUse(table.Status) {
+		return nil, fmt.Errorf("%w: meja %s sedang terpakai", ErrInvalidWaitlistEntry, table.TableNumber)
+	}
+
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE waitlist_entries
+		SET status = ?, table_id = ?, order_id = ?, seated_at = ?, updated_at = ?
+		WHERE id = ?
+	`, WaitlistStatusSeated, table.ID, nullableID(orderID), now, now, id); err != nil {
+		return nil, err
+	}
+	if err := setTableOccupied(ctx, tx, table.TableNumber); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return loadWaitlistEntry(ctx, r.db, id)
+}
+
+// LeaveWaitlist menandai tamu batal menunggu
+func (r *reservationRepository) LeaveWaitlist(ctx context.Context, id string) (*WaitlistEntry, error) {
+	if _, err := loadActiveWaitlistEntry(ctx, r.db, id); err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	if _, err := r.db.ExecContext(ctx, `
+		UPDATE waitlist_entries
+		SET status = ?, left_at = ?, updated_at = ?
+		WHERE id = ?
+	`, WaitlistStatusLeft, now, now, id); err != nil {
+		return nil, err
+	}
+	return loadWaitlistEntry(ctx, r.db, id)
+}
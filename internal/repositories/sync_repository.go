@@ -1,13 +1,27 @@
 package repositories
 
 import (
+	"backend/internal/db"
 	"backend/internal/models"
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 )
 
+// ErrManagedByHQ dikembalikan saat outlet mencoba mengubah data yang dikelola HQ
+var ErrManagedByHQ = errors.New("data dikelola oleh HQ dan tidak dapat diubah di outlet")
+
+// hqManagedTables memetakan entity type ke tabel yang mendukung flag managed_by_hq
+var hqManagedTables = map[string]string{
+	"product":           "products",
+	"category":          "categories",
+	"additional_charge": "additional_charges",
+	"table":             "tables",
+	"user":              "users",
+}
+
 type SyncRepository interface {
 	// Queue operations
 	EnqueueSync(ctx context.Context, entityType, entityID, operation string, payload interface{}) error
@@ -29,6 +43,9 @@ type SyncRepository interface {
 	DeleteAdditionalCharge(ctx context.Context, id int64) error
 	RefreshOpenOrderTotalsForAdditionalCharges(ctx context.Context) error
 
+	// HQ managed data
+	IsManagedByHQ(ctx context.Context, entityType, entityID string) (bool, error)
+
 	// Version tracking
 	GetEntityVersion(ctx context.Context, entityType, entityID string) (*models.EntityVersion, error)
 	UpdateEntityVersion(ctx context.Context, entityType, entityID string, version, cloudVersion int) error
@@ -309,7 +326,7 @@ func (r *syncRepositoryImpl) UpdateOutletConfig(ctx context.Context, config *mod
 
 func (r *syncRepositoryImpl) ListAdditionalCharges(ctx context.Context) ([]models.AdditionalCharge, error) {
 	query := `
-		SELECT id, outlet_id, name, charge_type, value, is_active, created_at, updated_at
+		SELECT id, outlet_id, name, charge_type, value, is_active, managed_by_hq, created_at, updated_at
 		FROM additional_charges
 		ORDER BY id DESC
 	`
@@ -324,6 +341,7 @@ func (r *syncRepositoryImpl) ListAdditionalCharges(ctx context.Context) ([]model
 	for rows.Next() {
 		var charge models.AdditionalCharge
 		var isActive int64
+		var managedByHQ int64
 		if err := rows.Scan(
 			&charge.ID,
 			&charge.OutletID,
@@ -331,12 +349,14 @@ func (r *syncRepositoryImpl) ListAdditionalCharges(ctx context.Context) ([]model
 			&charge.ChargeType,
 			&charge.Value,
 			&isActive,
+			&managedByHQ,
 			&charge.CreatedAt,
 			&charge.UpdatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan additional charge: %w", err)
 		}
 		charge.IsActive = isActive == 1
+		charge.ManagedByHQ = managedByHQ == 1
 		charges = append(charges, charge)
 	}
 
@@ -407,6 +427,25 @@ func (r *syncRepositoryImpl) DeleteAdditionalCharge(ctx context.Context, id int6
 	return nil
 }
 
+// IsManagedByHQ checks whether an entity was pulled from HQ and is locked for local edits
+func (r *syncRepositoryImpl) IsManagedByHQ(ctx context.Context, entityType, entityID string) (bool, error) {
+	table, ok := hqManagedTables[entityType]
+	if !ok {
+		return false, fmt.Errorf("unsupported entity type: %s", entityType)
+	}
+
+	var managed int64
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf("SELECT managed_by_hq FROM %s WHERE id = ?", table), entityID).Scan(&managed)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check managed_by_hq: %w", err)
+	}
+
+	return managed == 1, nil
+}
+
 func (r *syncRepositoryImpl) RefreshOpenOrderTotalsForAdditionalCharges(ctx context.Context) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
@@ -442,127 +481,12 @@ func (r *syncRepositoryImpl) RefreshOpenOrderTotalsForAdditionalCharges(ctx cont
 	}
 	rows.Close()
 
-	chargeRows, err := tx.QueryContext(ctx, `
-		SELECT id, name, charge_type, value
-		FROM additional_charges
-		WHERE is_active = 1
-		ORDER BY id ASC
-	`)
-	if err != nil {
-		_ = tx.Rollback()
-		return fmt.Errorf("failed to list active additional charges: %w", err)
-	}
-
-	type activeCharge struct {
-		id         int64
-		name       string
-		chargeType string
-		value      float64
-	}
-
-	charges := make([]activeCharge, 0)
-	for chargeRows.Next() {
-		var charge activeCharge
-		if err := chargeRows.Scan(&charge.id, &charge.name, &charge.chargeType, &charge.value); err != nil {
-			chargeRows.Close()
-			_ = tx.Rollback()
-			return fmt.Errorf("failed to scan active additional charge: %w", err)
-		}
-		charges = append(charges, charge)
-	}
-	if err := chargeRows.Err(); err != nil {
-		chargeRows.Close()
-		_ = tx.Rollback()
-		return fmt.Errorf("failed to iterate active additional charges: %w", err)
-	}
-	chargeRows.Close()
-
+	// Perhitungan sama dengan order repository agar promosi dan biaya manual ikut diperbarui
+	q := db.New(tx)
 	for _, orderID := range orderIDs {
-		var subtotal float64
-		if err := tx.QueryRowContext(ctx, `
-			SELECT COALESCE(SUM(price * qty), 0)
-			FROM order_items
-			WHERE order_id = ?
-		`, orderID).Scan(&subtotal); err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("failed to calculate subtotal for order %s: %w", orderID, err)
-		}
-
-		var basketSize int64
-		if err := tx.QueryRowContext(ctx, `
-			SELECT COUNT(*)
-			FROM order_items
-			WHERE order_id = ?
-		`, orderID).Scan(&basketSize); err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("failed to calculate basket size for order %s: %w", orderID, err)
-		}
-
-		if _, err := tx.ExecContext(ctx, `
-			DELETE FROM order_additional_charges
-			WHERE order_id = ?
-			  AND charge_id IS NOT NULL
-		`, orderID); err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("failed to reset additional charges for order %s: %w", orderID, err)
-		}
-
-		chargesTotal := 0.0
-		for _, charge := range charges {
-			applied := 0.0
-			if subtotal > 0 {
-				if charge.chargeType == "percentage" {
-					applied = subtotal * charge.value / 100
-				} else {
-					applied = charge.value
-				}
-			}
-
-			if applied == 0 {
-				continue
-			}
-
-			if _, err := tx.ExecContext(ctx, `
-				INSERT INTO order_additional_charges (
-					order_id,
-					charge_id,
-					name,
-					charge_type,
-					value,
-					applied_amount,
-					created_at,
-					updated_at
-				) VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-			`, orderID, charge.id, charge.name, charge.chargeType, charge.value, applied); err != nil {
-				_ = tx.Rollback()
-				return fmt.Errorf("failed to insert additional charge for order %s: %w", orderID, err)
-			}
-
-			chargesTotal += applied
-		}
-
-		var manualTotal float64
-		if err := tx.QueryRowContext(ctx, `
-			SELECT COALESCE(SUM(applied_amount), 0)
-			FROM order_additional_charges
-			WHERE order_id = ?
-			  AND charge_id IS NULL
-		`, orderID).Scan(&manualTotal); err != nil {
-			_ = tx.Rollback()
-			return fmt.Errorf("failed to calculate manual adjustments for order %s: %w", orderID, err)
-		}
-
-		totalAmount := subtotal + chargesTotal + manualTotal
-		if totalAmount < 0 {
-			totalAmount = 0
-		}
-		if _, err := tx.ExecContext(ctx, `
-			UPDATE orders
-			SET total_amount = ?, basket_size = ?, updated_at = CURRENT_TIMESTAMP
-			WHERE id = ?
-		`, totalAmount, basketSize, orderID); err != nil {
+		if _, _, err := recalculateOrderTotals(ctx, q, tx, orderID); err != nil {
 			_ = tx.Rollback()
-			return fmt.Errorf("failed to update order total for order %s: %w", orderID, err)
+			return fmt.Errorf("failed to refresh totals for order %s: %w", orderID, err)
 		}
 	}
 
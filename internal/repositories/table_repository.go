@@ -3,8 +3,78 @@ package repositories
 import (
 	"backend/internal/db"
 	"context"
+	"errors"
 )
 
+// Siklus status meja: available → seated → ordered → bill_requested → paid → dirty → available.
+// reserved dipakai reservasi sebelum tamu datang.
+const (
+	TableStatusAvailable     = "available"
+	TableStatusReserved      = "reserved"
+	TableStatusSeated        = "seated"
+	TableStatusOrdered       = "ordered"
+	TableStatusBillRequested = "bill_requested"
+	TableStatusPaid          = "paid"
+	TableStatusDirty         = "dirty"
+
+	// TableStatusOccupied hanya diterima sebagai input lama: meja mulai dipakai (seated)
+	TableStatusOccupied = "occupied"
+)
+
+// TableStatuses adalah seluruh status meja yang tersimpan, sesuai urutan siklus
+var TableStatuses = []string{
+	TableStatusAvailable,
+	TableStatusReserved,
+	TableStatusSeated,
+	TableStatusOrdered,
+	TableStatusBillRequested,
+	TableStatusPaid,
+	TableStatusDirty,
+}
+
+// TableStatusTransition adalah satu perpindahan status yang diizinkan beserta role yang boleh
+// melakukannya. Admin selalu boleh.
+type TableStatusTransition struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Roles []string `json:"roles"`
+}
+
+// TableStatusChange adalah hasil perubahan status meja untuk broadcast ke perangkat lain
+type TableStatusChange struct {
+	TableNumber string `json:"table_number"`
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Changed     bool   `json:"changed"`
+}
+
+var (
+	ErrTableNotFound             = errors.New("meja tidak ditemukan")
+	ErrInvalidTableStatus        = errors.New("status meja tidak valid")
+	ErrTableTransitionNotAllowed = errors.New("perpindahan status meja tidak diizinkan")
+	ErrTableTransitionForbidden  = errors.New("role tidak boleh mengubah status meja ini")
+	ErrTableHasOpenOrders        = errors.New("meja masih memiliki order berjalan")
+)
+
+// IsValidTableStatus mengecek status yang boleh disimpan di tabel tables
+func IsValidTableStatus(status string) bool {
+	for _, s := range TableStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTableInUse true jika tamu masih duduk di meja (dari seated sampai paid)
+func IsTableInUse(status string) bool {
+	switch status {
+	case TableStatusSeated, TableStatusOrdered, TableStatusBillRequested, TableStatusPaid:
+		return true
+	}
+	return false
+}
+
 // TableRepository adalah interface untuk operasi database table
 type TableRepository interface {
 	Create(ctx context.Context, tableNumber string, capacity int64) (*db.Table, error)
@@ -15,8 +85,10 @@ type TableRepository interface {
 	FindByNumber(ctx context.Context, tableNumber string) (*db.Table, error)
 	FindByStatus(ctx context.Context, status string) ([]db.Table, error)
 	Update(ctx context.Context, id string, tableNumber string, capacity int64) error
-	UpdateStatus(ctx context.Context, tableNumber string, status string) error
+	UpdateStatus(ctx context.Context, tableNumber string, from, to string) error
 	Delete(ctx context.Context, id string) error
 	GetAvailable(ctx context.Context) ([]db.Table, error)
 	GetOccupied(ctx context.Context) ([]db.Table, error)
+	ListStatusTransitions(ctx context.Context) ([]TableStatusTransition, error)
+	ReplaceStatusTransitions(ctx context.Context, transitions []TableStatusTransition) error
 }
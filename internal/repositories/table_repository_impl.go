@@ -5,6 +5,10 @@ import (
 	"backend/pkg/utils"
 	"context"
 	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 )
 
 type tableRepository struct {
@@ -70,11 +74,43 @@ func (r *tableRepository) Update(ctx context.Context, id string, tableNumber str
 	})
 }
 
-func (r *tableRepository) UpdateStatus(ctx context.Context, tableNumber string, status string) error {
-	return db.New(r.db).UpdateTableStatus(ctx, db.UpdateTableStatusParams{
-		Status:      status,
-		TableNumber: tableNumber,
-	})
+// UpdateStatus memindah status meja secara manual. Update bersyarat pada status asal
+// supaya perubahan bersamaan dari perangkat lain tidak tertimpa.
+func (r *tableRepository) UpdateStatus(ctx context.Context, tableNumber string, from, to string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Meja dengan order berjalan hanya boleh berada di ordered atau bill_requested
+	if to != TableStatusOrdered && to != TableStatusBillRequested {
+		open, err := countOpenTableOrders(ctx, tx, tableNumber)
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return fmt.Errorf("%w: meja %s masih punya %d order belum lunas", ErrTableHasOpenOrders, tableNumber, open)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tables SET status = ?, updated_at = ?
+		WHERE table_number = ? AND status = ?
+	`, to, time.Now().UTC(), tableNumber, from)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("%w: status meja %s sudah berubah", ErrTableTransitionNotAllowed, tableNumber)
+	}
+
+	if err := recordTableStatusSession(ctx, tx, tableNumber, to); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *tableRepository) Delete(ctx context.Context, id string) error {
@@ -85,6 +121,183 @@ func (r *tableRepository) GetAvailable(ctx context.Context) ([]db.Table, error)
 	return db.New(r.db).GetAvailableTables(ctx)
 }
 
+// GetOccupied mengembalikan meja yang masih ditempati tamu (seated sampai paid)
 func (r *tableRepository) GetOccupied(ctx context.Context) ([]db.Table, error) {
 	return db.New(r.db).GetOccupiedTables(ctx)
 }
+
+func (r *tableRepository) ListStatusTransitions(ctx context.Context) ([]TableStatusTransition, error) {
+	return listTableStatusTransitions(ctx, r.db)
+}
+
+// ReplaceStatusTransitions mengganti seluruh aturan perpindahan status meja sekaligus
+func (r *tableRepository) ReplaceStatusTransitions(ctx context.Context, transitions []TableStatusTransition) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM table_status_transitions"); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, transition := range transitions {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO table_status_transitions (from_status, to_status, roles, updated_at)
+			VALUES (?, ?, ?, ?)
+		`, transition.From, transition.To, strings.Join(transition.Roles, ","), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func listTableStatusTransitions(ctx context.Context, q db.DBTX) ([]TableStatusTransition, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT from_status, to_status, roles
+		FROM table_status_transitions
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	order := make(map[string]int, len(TableStatuses))
+	for i, status := range TableStatuses {
+		order[status] = i
+	}
+	transitions := []TableStatusTransition{}
+	for rows.Next() {
+		var transition TableStatusTransition
+		var roles string
+		if err := rows.Scan(&transition.From, &transition.To, &roles); err != nil {
+			return nil, err
+		}
+		transition.Roles = []string{}
+		for _, role := range strings.Split(roles, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				transition.Roles = append(transition.Roles, role)
+			}
+		}
+		transitions = append(transitions, transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Urut mengikuti siklus meja agar mudah dibaca di layar pengaturan
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if order[transitions[i].From] != order[transitions[j].From] {
+			return order[transitions[i].From] < order[transitions[j].From]
+		}
+		return order[transitions[i].To] < order[transitions[j].To]
+	})
+	return transitions, nil
+}
+
+// recordTableStatusSession mencatat tahap sesi meja dari status baru: seated membuka sesi,
+// available menutupnya (meja sudah dibersihkan dan siap dipakai lagi)
+func recordTableStatusSession(ctx context.Context, q db.DBTX, tableNumber, status string) error {
+	switch status {
+	case TableStatusSeated:
+		return RecordTableSessionEvent(ctx, q, tableNumber, TableSessionSeated, "")
+	case TableStatusAvailable:
+		return RecordTableSessionEvent(ctx, q, tableNumber, TableSessionCleared, "")
+	}
+	return nil
+}
+
+// setTableStatus mengubah status meja dari alur order/reservasi tanpa cek aturan transisi
+func setTableStatus(ctx context.Context, q db.DBTX, tableNumber, status string) error {
+	if err := db.New(q).UpdateTableStatus(ctx, db.UpdateTableStatusParams{
+		Status:      status,
+		TableNumber: tableNumber,
+	}); err != nil {
+		return err
+	}
+	return recordTableStatusSession(ctx, q, tableNumber, status)
+}
+
+// setTableOccupied menandai meja dipakai: ordered jika sudah ada order berjalan, selain itu seated.
+// Meja yang billnya sudah diminta tidak dimundurkan.
+func setTableOccupied(ctx context.Context, q db.DBTX, tableNumber string) error {
+	open, err := countOpenTableOrders(ctx, q, tableNumber)
+	if err != nil {
+		return err
+	}
+	if open == 0 {
+		return setTableStatus(ctx, q, tableNumber, TableStatusSeated)
+	}
+	var current string
+	if err := q.QueryRowContext(ctx, "SELECT status FROM tables WHERE table_number = ?", tableNumber).Scan(&current); err != nil {
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		return err
+	}
+	if current == TableStatusOrdered || current == TableStatusBillRequested {
+		return nil
+	}
+	return setTableStatus(ctx, q, tableNumber, TableStatusOrdered)
+}
+
+// advanceTableStatus memajukan status meja dari alur order: ordered saat ada order/item baru,
+// bill_requested saat bill dicetak (hanya dari seated/ordered). Meja yang tidak ada diabaikan.
+func advanceTableStatus(ctx context.Context, q db.DBTX, tableNumber, status string) (bool, error) {
+	var current string
+	err := q.QueryRowContext(ctx, "SELECT status FROM tables WHERE table_number = ?", tableNumber).Scan(&current)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch status {
+	case TableStatusOrdered:
+		if current == TableStatusOrdered {
+			return false, nil
+		}
+	case TableStatusBillRequested:
+		if current != TableStatusSeated && current != TableStatusOrdered {
+			return false, nil
+		}
+	default:
+		return false, fmt.Errorf("%w: %s", ErrInvalidTableStatus, status)
+	}
+	return true, setTableStatus(ctx, q, tableNumber, status)
+}
+
+// AdvanceOrderTable memajukan status meja milik order dine-in (lihat advanceTableStatus).
+// Mengembalikan nomor meja dan apakah statusnya berubah.
+func AdvanceOrderTable(ctx context.Context, q db.DBTX, orderID, status string) (string, bool, error) {
+	var tableNumber, orderType string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(table_number, ''), COALESCE(order_type, 'dine_in') FROM orders WHERE id = ?
+	`, orderID).Scan(&tableNumber, &orderType)
+	if err != nil && err != sql.ErrNoRows {
+		return "", false, err
+	}
+	if err == sql.ErrNoRows || orderType != OrderTypeDineIn || tableNumber == "" {
+		return "", false, nil
+	}
+	changed, err := advanceTableStatus(ctx, q, tableNumber, status)
+	return tableNumber, changed, err
+}
+
+// SettleTable memindah meja ke status akhir order (paid setelah lunas, dirty setelah void
+// atau pindah meja) jika tidak ada lagi order yang berjalan di meja itu.
+func SettleTable(ctx context.Context, q db.DBTX, tableNumber, status string) (bool, error) {
+	if tableNumber == "" {
+		return false, nil
+	}
+	open, err := countOpenTableOrders(ctx, q, tableNumber)
+	if err != nil || open > 0 {
+		return false, err
+	}
+	if err := setTableStatus(ctx, q, tableNumber, status); err != nil {
+		return false, err
+	}
+	return true, nil
+}
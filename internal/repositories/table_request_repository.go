@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TableRequest adalah permintaan dari meja (minta bill, panggil pelayan, minta air).
+// WaiterIDs berisi pelayan section meja saat ini, dipakai perangkat waiter untuk menyaring notifikasi.
+type TableRequest struct {
+	ID                 string     `json:"id"`
+	TableID            *string    `json:"table_id"`
+	TableNumber        string     `json:"table_number"`
+	SectionID          *string    `json:"section_id"`
+	SectionName        string     `json:"section_name"`
+	WaiterIDs          []string   `json:"waiter_ids"`
+	RequestType        string     `json:"request_type"`
+	Source             string     `json:"source"`
+	Note               string     `json:"note"`
+	Status             string     `json:"status"`
+	CreatedBy          *string    `json:"created_by"`
+	AcknowledgedBy     *string    `json:"acknowledged_by"`
+	AcknowledgedByName string     `json:"acknowledged_by_name"`
+	AcknowledgedAt     *time.Time `json:"acknowledged_at"`
+	ResolvedBy         *string    `json:"resolved_by"`
+	ResolvedByName     string     `json:"resolved_by_name"`
+	ResolvedAt         *time.Time `json:"resolved_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+type TableRequestInput struct {
+	TableNumber string
+	RequestType string
+	Source      string
+	Note        string
+	CreatedBy   string
+	ClientIP    string
+}
+
+type TableRequestFilter struct {
+	// Status kosong/active = belum selesai (open dan acknowledged)
+	Status      string
+	TableNumber string
+	SectionID   string
+	// WaiterID membatasi ke meja di section pelayan tersebut dan meja tanpa section
+	WaiterID string
+}
+
+// StaffResponseTime adalah waktu respon satu staf. Acknowledge dihitung untuk staf yang
+// pertama menanggapi, selesai untuk staf yang menutup permintaan.
+type StaffResponseTime struct {
+	UserID            string  `json:"user_id"`
+	FullName          string  `json:"full_name"`
+	Role              string  `json:"role"`
+	Acknowledged      int     `json:"acknowledged"`
+	AvgAckSeconds     float64 `json:"avg_ack_seconds"`
+	MaxAckSeconds     float64 `json:"max_ack_seconds"`
+	Resolved          int     `json:"resolved"`
+	AvgResolveSeconds float64 `json:"avg_resolve_seconds"`
+}
+
+type TableRequestTypeStats struct {
+	RequestType       string  `json:"request_type"`
+	Total             int     `json:"total"`
+	Unresolved        int     `json:"unresolved"`
+	AvgAckSeconds     float64 `json:"avg_ack_seconds"`
+	AvgResolveSeconds float64 `json:"avg_resolve_seconds"`
+}
+
+// TableRequestAnalytics merangkum permintaan meja pada rentang tanggal dibuat
+type TableRequestAnalytics struct {
+	From              time.Time               `json:"from"`
+	To                time.Time               `json:"to"`
+	Total             int                     `json:"total"`
+	FromGuests        int                     `json:"from_guests"`
+	Unresolved        int                     `json:"unresolved"`
+	AvgAckSeconds     float64                 `json:"avg_ack_seconds"`
+	AvgResolveSeconds float64                 `json:"avg_resolve_seconds"`
+	ByType            []TableRequestTypeStats `json:"by_type"`
+	ByStaff           []StaffResponseTime     `json:"by_staff"`
+}
+
+const (
+	TableRequestBill   = "bill"
+	TableRequestWaiter = "waiter"
+	TableRequestWater  = "water"
+
+	TableRequestSourceGuest = "guest"
+	TableRequestSourceStaff = "staff"
+
+	TableRequestOpen         = "open"
+	TableRequestAcknowledged = "acknowledged"
+	TableRequestResolved     = "resolved"
+	// TableRequestActive hanya untuk filter: open dan acknowledged
+	TableRequestActive = "active"
+)
+
+var (
+	ErrInvalidTableRequest  = errors.New("permintaan meja tidak valid")
+	ErrTableRequestNotFound = errors.New("permintaan meja tidak ditemukan")
+	ErrTableRequestResolved = errors.New("permintaan meja sudah selesai")
+)
+
+type TableRequestRepository interface {
+	// Create mencatat permintaan baru; permintaan jenis sama yang masih terbuka di meja itu
+	// dikembalikan apa adanya (created=false) supaya tamu tidak bisa membanjiri pelayan
+	Create(ctx context.Context, input TableRequestInput) (*TableRequest, bool, error)
+	GetByID(ctx context.Context, id string) (*TableRequest, error)
+	List(ctx context.Context, filter TableRequestFilter) ([]TableRequest, error)
+	Acknowledge(ctx context.Context, id, userID string) (*TableRequest, error)
+	Resolve(ctx context.Context, id, userID string) (*TableRequest, error)
+	Analytics(ctx context.Context, from, to time.Time) (*TableRequestAnalytics, error)
+}
@@ -0,0 +1,425 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+type tableRequestRepository struct {
+	db *sql.DB
+}
+
+func NewTableRequestRepository(dbConn *sql.DB) TableRequestRepository {
+	return &tableRequestRepository{db: dbConn}
+}
+
+const tableRequestSelect = `
+	SELECT r.id, r.table_id, r.table_number, r.section_id, COALESCE(s.name, ''), r.request_type, r.source,
+	       COALESCE(r.note, ''), r.status, r.created_by,
+	       r.acknowledged_by, COALESCE(ua.full_name, ''), r.acknowledged_at,
+	       r.resolved_by, COALESCE(ur.full_name, ''), r.resolved_at,
+	       r.created_at, r.updated_at
+	FROM table_requests r
+	LEFT JOIN floor_sections s ON s.id = r.section_id
+	LEFT JOIN users ua ON ua.id = r.acknowledged_by
+	LEFT JOIN users ur ON ur.id = r.resolved_by
+`
+
+func scanTableRequest(scanner interface{ Scan(dest ...any) error }) (*TableRequest, error) {
+	var request TableRequest
+	var tableID, sectionID, createdBy, acknowledgedBy, resolvedBy sql.NullString
+	var acknowledgedAt, resolvedAt sql.NullTime
+	if err := scanner.Scan(&request.ID, &tableID, &request.TableNumber, &sectionID, &request.SectionName,
+		&request.RequestType, &request.Source, &request.Note, &request.Status, &createdBy,
+		&acknowledgedBy, &request.AcknowledgedByName, &acknowledgedAt,
+		&resolvedBy, &request.ResolvedByName, &resolvedAt,
+		&request.CreatedAt, &request.UpdatedAt); err != nil {
+		return nil, err
+	}
+	request.TableID = nullStringPtr(tableID)
+	request.SectionID = nullStringPtr(sectionID)
+	request.CreatedBy = nullStringPtr(createdBy)
+	request.AcknowledgedBy = nullStringPtr(acknowledgedBy)
+	request.ResolvedBy = nullStringPtr(resolvedBy)
+	request.AcknowledgedAt = nullTimePtr(acknowledgedAt)
+	request.ResolvedAt = nullTimePtr(resolvedAt)
+	request.WaiterIDs = []string{}
+	return &request, nil
+}
+
+func nullStringPtr(value sql.NullString) *string {
+	if !value.Valid {
+		return nil
+	}
+	return &value.String
+}
+
+// loadSectionWaiters mengisi pelayan section meja untuk penyaringan notifikasi
+func loadSectionWaiters(ctx context.Context, q db.DBTX, requests []TableRequest) error {
+	for i := range requests {
+		if requests[i].SectionID == nil {
+			continue
+		}
+		rows, err := q.QueryContext(ctx, `
+			SELECT user_id FROM floor_section_waiters WHERE section_id = ? ORDER BY user_id
+		`, *requests[i].SectionID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var userID string
+			if err := rows.Scan(&userID); err != nil {
+				rows.Close()
+				return err
+			}
+			requests[i].WaiterIDs = append(requests[i].WaiterIDs, userID)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadTableRequest(ctx context.Context, q db.DBTX, id string) (*TableRequest, error) {
+	request, err := scanTableRequest(q.QueryRowContext(ctx, tableRequestSelect+" WHERE r.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTableRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	requests := []TableRequest{*request}
+	if err := loadSectionWaiters(ctx, q, requests); err != nil {
+		return nil, err
+	}
+	return &requests[0], nil
+}
+
+func (r *tableRequestRepository) Create(ctx context.Context, input TableRequestInput) (*TableRequest, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var tableID string
+	var sectionID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		SELECT id, section_id FROM tables WHERE table_number = ?
+	`, input.TableNumber).Scan(&tableID, &sectionID)
+	if err == sql.ErrNoRows {
+		return nil, false, fmt.Errorf("%w: meja %s", ErrTableNotFound, input.TableNumber)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var existingID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT id FROM table_requests
+		WHERE table_number = ? AND request_type = ? AND status != ?
+		ORDER BY created_at
+		LIMIT 1
+	`, input.TableNumber, input.RequestType, TableRequestResolved).Scan(&existingID)
+	if err == nil {
+		request, err := loadTableRequest(ctx, tx, existingID)
+		return request, false, err
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	id := utils.GenerateULID()
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO table_requests (
+			id, table_id, table_number, section_id, request_type, source, note, status,
+			created_by, client_ip, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, id, tableID, input.TableNumber, sectionID, input.RequestType, input.Source,
+		nullableID(strings.TrimSpace(input.Note)), TableRequestOpen,
+		nullableID(input.CreatedBy), nullableID(input.ClientIP), now, now); err != nil {
+		return nil, false, err
+	}
+
+	// Minta bill memajukan meja ke bill_requested seperti bill yang dicetak
+	if input.RequestType == TableRequestBill {
+		if _, err := advanceTableStatus(ctx, tx, input.TableNumber, TableStatusBillRequested); err != nil {
+			return nil, false, err
+		}
+	}
+
+	request, err := loadTableRequest(ctx, tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return request, true, nil
+}
+
+func (r *tableRequestRepository) GetByID(ctx context.Context, id string) (*TableRequest, error) {
+	return loadTableRequest(ctx, r.db, id)
+}
+
+func (r *tableRequestRepository) List(ctx context.Context, filter TableRequestFilter) ([]TableRequest, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	switch filter.Status {
+	case "", TableRequestActive:
+		conditions = append(conditions, "r.status != ?")
+		args = append(args, TableRequestResolved)
+	default:
+		conditions = append(conditions, "r.status = ?")
+		args = append(args, filter.Status)
+	}
+	if filter.TableNumber != "" {
+		conditions = append(conditions, "r.table_number = ?")
+		args = append(args, filter.TableNumber)
+	}
+	if filter.SectionID != "" {
+		conditions = append(conditions, "r.section_id = ?")
+		args = append(args, filter.SectionID)
+	}
+	if filter.WaiterID != "" {
+		conditions = append(conditions, `(r.section_id IS NULL OR r.section_id IN (
+			SELECT section_id FROM floor_section_waiters WHERE user_id = ?
+		))`)
+		args = append(args, filter.WaiterID)
+	}
+
+	// Permintaan terlama di atas: yang paling lama menunggu dilayani dulu
+	rows, err := r.db.QueryContext(ctx, tableRequestSelect+" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY r.created_at LIMIT 200", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	requests := []TableRequest{}
+	for rows.Next() {
+		request, err := scanTableRequest(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := loadSectionWaiters(ctx, r.db, requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// Acknowledge menandai permintaan sedang ditangani. Permintaan yang sudah ditanggapi
+// staf lain dikembalikan tanpa mengubah pencatat pertamanya.
+func (r *tableRequestRepository) Acknowledge(ctx context.Context, id, userID string) (*TableRequest, error) {
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE table_requests
+		SET status = ?, acknowledged_by = ?, acknowledged_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, TableRequestAcknowledged, nullableID(userID), now, now, id, TableRequestOpen)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	request, err := loadTableRequest(ctx, r.db, id)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 && request.Status == TableRequestResolved {
+		return nil, ErrTableRequestResolved
+	}
+	return request, nil
+}
+
+// Resolve menutup permintaan. Permintaan yang langsung diselesaikan tanpa acknowledge
+// dianggap ditanggapi oleh staf yang sama pada saat itu.
+func (r *tableRequestRepository) Resolve(ctx context.Context, id, userID string) (*TableRequest, error) {
+	now := time.Now().UTC()
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE table_requests
+		SET status = ?, resolved_by = ?, resolved_at = ?,
+		    acknowledged_by = COALESCE(acknowledged_by, ?),
+		    acknowledged_at = COALESCE(acknowledged_at, ?),
+		    updated_at = ?
+		WHERE id = ? AND status != ?
+	`, TableRequestResolved, nullableID(userID), now, nullableID(userID), now, now, id, TableRequestResolved)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		if _, err := loadTableRequest(ctx, r.db, id); err != nil {
+			return nil, err
+		}
+		return nil, ErrTableRequestResolved
+	}
+	return loadTableRequest(ctx, r.db, id)
+}
+
+func secondsBetween(from, to time.Time) float64 {
+	seconds := to.Sub(from).Seconds()
+	if seconds < 0 {
+		return 0
+	}
+	return seconds
+}
+
+func averageSeconds(total float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(total / float64(count))
+}
+
+func (r *tableRequestRepository) Analytics(ctx context.Context, from, to time.Time) (*TableRequestAnalytics, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT r.request_type, r.source, r.status, r.created_at,
+		       r.acknowledged_by, COALESCE(ua.full_name, ''), COALESCE(ua.role, ''), r.acknowledged_at,
+		       r.resolved_by, COALESCE(ur.full_name, ''), COALESCE(ur.role, ''), r.resolved_at
+		FROM table_requests r
+		LEFT JOIN users ua ON ua.id = r.acknowledged_by
+		LEFT JOIN users ur ON ur.id = r.resolved_by
+		WHERE r.created_at >= ? AND r.created_at < ?
+	`, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type typeTotals struct {
+		stats                  TableRequestTypeStats
+		ackTotal, resolveTotal float64
+		ackCount, resolveCount int
+	}
+	type staffTotals struct {
+		stats                  StaffResponseTime
+		ackTotal, resolveTotal float64
+	}
+
+	analytics := &TableRequestAnalytics{
+		From:    from,
+		To:      to,
+		ByType:  []TableRequestTypeStats{},
+		ByStaff: []StaffResponseTime{},
+	}
+	byType := map[string]*typeTotals{}
+	byStaff := map[string]*staffTotals{}
+	staffFor := func(userID, fullName, role string) *staffTotals {
+		staff := byStaff[userID]
+		if staff == nil {
+			staff = &staffTotals{stats: StaffResponseTime{UserID: userID, FullName: fullName, Role: role}}
+			byStaff[userID] = staff
+		}
+		return staff
+	}
+
+	var ackTotal, resolveTotal float64
+	var ackCount, resolveCount int
+	for rows.Next() {
+		var requestType, source, status string
+		var createdAt time.Time
+		var ackBy, resolvedBy sql.NullString
+		var ackName, ackRole, resolvedName, resolvedRole string
+		var ackAt, resolvedAt sql.NullTime
+		if err := rows.Scan(&requestType, &source, &status, &createdAt,
+			&ackBy, &ackName, &ackRole, &ackAt,
+			&resolvedBy, &resolvedName, &resolvedRole, &resolvedAt); err != nil {
+			return nil, err
+		}
+
+		analytics.Total++
+		if source == TableRequestSourceGuest {
+			analytics.FromGuests++
+		}
+		typeStats := byType[requestType]
+		if typeStats == nil {
+			typeStats = &typeTotals{stats: TableRequestTypeStats{RequestType: requestType}}
+			byType[requestType] = typeStats
+		}
+		typeStats.stats.Total++
+		if status != TableRequestResolved {
+			analytics.Unresolved++
+			typeStats.stats.Unresolved++
+		}
+
+		if ackAt.Valid {
+			seconds := secondsBetween(createdAt, ackAt.Time)
+			ackTotal += seconds
+			ackCount++
+			typeStats.ackTotal += seconds
+			typeStats.ackCount++
+			if ackBy.Valid {
+				staff := staffFor(ackBy.String, ackName, ackRole)
+				staff.stats.Acknowledged++
+				staff.ackTotal += seconds
+				if seconds > staff.stats.MaxAckSeconds {
+					staff.stats.MaxAckSeconds = math.Round(seconds)
+				}
+			}
+		}
+		if resolvedAt.Valid {
+			seconds := secondsBetween(createdAt, resolvedAt.Time)
+			resolveTotal += seconds
+			resolveCount++
+			typeStats.resolveTotal += seconds
+			typeStats.resolveCount++
+			if resolvedBy.Valid {
+				staff := staffFor(resolvedBy.String, resolvedName, resolvedRole)
+				staff.stats.Resolved++
+				staff.resolveTotal += seconds
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	analytics.AvgAckSeconds = averageSeconds(ackTotal, ackCount)
+	analytics.AvgResolveSeconds = averageSeconds(resolveTotal, resolveCount)
+	for _, typeStats := range byType {
+		typeStats.stats.AvgAckSeconds = averageSeconds(typeStats.ackTotal, typeStats.ackCount)
+		typeStats.stats.AvgResolveSeconds = averageSeconds(typeStats.resolveTotal, typeStats.resolveCount)
+		analytics.ByType = append(analytics.ByType, typeStats.stats)
+	}
+	for _, staff := range byStaff {
+		staff.stats.AvgAckSeconds = averageSeconds(staff.ackTotal, staff.stats.Acknowledged)
+		staff.stats.AvgResolveSeconds = averageSeconds(staff.resolveTotal, staff.stats.Resolved)
+		analytics.ByStaff = append(analytics.ByStaff, staff.stats)
+	}
+
+	sort.Slice(analytics.ByType, func(i, j int) bool {
+		return analytics.ByType[i].RequestType < analytics.ByType[j].RequestType
+	})
+	// Staf tercepat menanggapi di atas; staf yang hanya menutup permintaan di bawah
+	sort.Slice(analytics.ByStaff, func(i, j int) bool {
+		a, b := analytics.ByStaff[i], analytics.ByStaff[j]
+		if (a.Acknowledged == 0) != (b.Acknowledged == 0) {
+			return a.Acknowledged > 0
+		}
+		if a.AvgAckSeconds != b.AvgAckSeconds {
+			return a.AvgAckSeconds < b.AvgAckSeconds
+		}
+		return a.FullName < b.FullName
+	})
+	return analytics, nil
+}
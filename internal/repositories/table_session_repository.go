@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TableSession adalah satu kali pemakaian meja: dari tamu duduk sampai meja kosong lagi.
+// Setiap tahap dicatat sekali (yang pertama); riwayat lengkap ada di Events.
+type TableSession struct {
+	ID            string              `json:"id"`
+	TableID       *string             `json:"table_id"`
+	TableNumber   string              `json:"table_number"`
+	AreaID        *string             `json:"area_id"`
+	AreaName      string              `json:"area_name"`
+	OrderID       *string             `json:"order_id"`
+	Pax           int64               `json:"pax"`
+	Status        string              `json:"status"`
+	SeatedAt      time.Time           `json:"seated_at"`
+	FirstOrderAt  *time.Time          `json:"first_order_at"`
+	BillPrintedAt *time.Time          `json:"bill_printed_at"`
+	PaidAt        *time.Time          `json:"paid_at"`
+	ClearedAt     *time.Time          `json:"cleared_at"`
+	TurnMinutes   *float64            `json:"turn_minutes"`
+	DwellMinutes  *float64            `json:"dwell_minutes"`
+	Events        []TableSessionEvent `json:"events,omitempty"`
+}
+
+type TableSessionEvent struct {
+	Event     string    `json:"event"`
+	OrderID   string    `json:"order_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TableSessionFilter struct {
+	From        time.Time
+	To          time.Time
+	TableNumber string
+	Status      string
+}
+
+// TableTimer adalah timer meja yang sedang terisi untuk layar waiter/kasir
+type TableTimer struct {
+	SessionID          string     `json:"session_id"`
+	TableID            *string    `json:"table_id"`
+	TableNumber        string     `json:"table_number"`
+	AreaName           string     `json:"area_name"`
+	OrderID            *string    `json:"order_id"`
+	Pax                int64      `json:"pax"`
+	Stage              string     `json:"stage"`
+	SeatedAt           time.Time  `json:"seated_at"`
+	FirstOrderAt       *time.Time `json:"first_order_at"`
+	BillPrintedAt      *time.Time `json:"bill_printed_at"`
+	PaidAt             *time.Time `json:"paid_at"`
+	ElapsedMinutes     float64    `json:"elapsed_minutes"`
+	StageMinutes       float64    `json:"stage_minutes"`
+	AvgTurnMinutes     float64    `json:"avg_turn_minutes"`
+	OverAverageMinutes float64    `json:"over_average_minutes"`
+}
+
+type PaxDwell struct {
+	Pax             int64   `json:"pax"`
+	Sessions        int     `json:"sessions"`
+	AvgDwellMinutes float64 `json:"avg_dwell_minutes"`
+	AvgTurnMinutes  float64 `json:"avg_turn_minutes"`
+}
+
+type AreaTurn struct {
+	AreaID          string  `json:"area_id"`
+	AreaName        string  `json:"area_name"`
+	Sessions        int     `json:"sessions"`
+	Covers          int64   `json:"covers"`
+	AvgDwellMinutes float64 `json:"avg_dwell_minutes"`
+	AvgTurnMinutes  float64 `json:"avg_turn_minutes"`
+}
+
+type TableDayCovers struct {
+	Date        string `json:"date"`
+	TableNumber string `json:"table_number"`
+	Sessions    int    `json:"sessions"`
+	Covers      int64  `json:"covers"`
+}
+
+// TableTurnAnalytics merangkum sesi meja yang sudah selesai pada rentang tanggal.
+// Turn time = duduk sampai meja kosong lagi; dwell time = duduk sampai lunas.
+type TableTurnAnalytics struct {
+	From                    time.Time        `json:"from"`
+	To                      time.Time        `json:"to"`
+	Sessions                int              `json:"sessions"`
+	SessionsWithoutOrder    int              `json:"sessions_without_order"`
+	Covers                  int64            `json:"covers"`
+	AvgTurnMinutes          float64          `json:"avg_turn_minutes"`
+	AvgDwellMinutes         float64          `json:"avg_dwell_minutes"`
+	AvgSeatToOrderMinutes   float64          `json:"avg_seat_to_order_minutes"`
+	AvgBillToPaidMinutes    float64          `json:"avg_bill_to_paid_minutes"`
+	AvgCoversPerTablePerDay float64          `json:"avg_covers_per_table_per_day"`
+	ByPax                   []PaxDwell       `json:"by_pax"`
+	ByArea                  []AreaTurn       `json:"by_area"`
+	CoversPerTableDay       []TableDayCovers `json:"covers_per_table_day"`
+}
+
+const (
+	TableSessionOpen   = "open"
+	TableSessionClosed = "closed"
+
+	TableSessionSeated      = "seated"
+	TableSessionFirstOrder  = "first_order"
+	TableSessionBillPrinted = "bill_printed"
+	TableSessionPaid        = "paid"
+	TableSessionCleared     = "cleared"
+	TableSessionTransferred = "transferred"
+)
+
+var ErrTableSessionNotFound = errors.New("sesi meja tidak ditemukan")
+
+type TableSessionRepository interface {
+	List(ctx context.Context, filter TableSessionFilter) ([]TableSession, error)
+	GetByID(ctx context.Context, id string) (*TableSession, error)
+	Timers(ctx context.Context, now time.Time) ([]TableTimer, error)
+	Analytics(ctx context.Context, from, to time.Time) (*TableTurnAnalytics, error)
+}
@@ -0,0 +1,509 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type tableSessionRepository struct {
+	db *sql.DB
+}
+
+func NewTableSessionRepository(dbConn *sql.DB) TableSessionRepository {
+	return &tableSessionRepository{db: dbConn}
+}
+
+const tableSessionSelect = `
+	SELECT s.id, s.table_id, s.table_number, s.area_id, COALESCE(a.name, ''), s.order_id, s.pax, s.status,
+	       s.seated_at, s.first_order_at, s.bill_printed_at, s.paid_at, s.cleared_at
+	FROM table_sessions s
+	LEFT JOIN floor_areas a ON a.id = s.area_id
+`
+
+func scanTableSession(scanner interface{ Scan(dest ...any) error }) (*TableSession, error) {
+	var session TableSession
+	var tableID, areaID, orderID sql.NullString
+	var firstOrderAt, billPrintedAt, paidAt, clearedAt sql.NullTime
+	if err := scanner.Scan(&session.ID, &tableID, &session.TableNumber, &areaID, &session.AreaName, &orderID,
+		&session.Pax, &session.Status, &session.SeatedAt, &firstOrderAt, &billPrintedAt, &paidAt, &clearedAt); err != nil {
+		return nil, err
+	}
+	if tableID.Valid {
+		session.TableID = &tableID.String
+	}
+	if areaID.Valid {
+		session.AreaID = &areaID.String
+	}
+	if orderID.Valid {
+		session.OrderID = &orderID.String
+	}
+	session.FirstOrderAt = nullTimePtr(firstOrderAt)
+	session.BillPrintedAt = nullTimePtr(billPrintedAt)
+	session.PaidAt = nullTimePtr(paidAt)
+	session.ClearedAt = nullTimePtr(clearedAt)
+	if session.ClearedAt != nil {
+		turn := minutesBetween(session.SeatedAt, *session.ClearedAt)
+		session.TurnMinutes = &turn
+	}
+	if session.PaidAt != nil {
+		dwell := minutesBetween(session.SeatedAt, *session.PaidAt)
+		session.DwellMinutes = &dwell
+	}
+	return &session, nil
+}
+
+func nullTimePtr(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Time
+}
+
+func minutesBetween(from, to time.Time) float64 {
+	minutes := to.Sub(from).Minutes()
+	if minutes < 0 {
+		return 0
+	}
+	return roundMinutes(minutes)
+}
+
+func roundMinutes(minutes float64) float64 {
+	return float64(int64(minutes*10+0.5)) / 10
+}
+
+// openTableSession mengambil sesi meja yang masih berjalan; nil jika meja kosong
+func openTableSession(ctx context.Context, q db.DBTX, tableNumber string) (*TableSession, error) {
+	session, err := scanTableSession(q.QueryRowContext(ctx, tableSessionSelect+`
+		WHERE s.table_number = ? AND s.status = 'open'
+		ORDER BY s.seated_at DESC
+		LIMIT 1
+	`, tableNumber))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return session, err
+}
+
+func startTableSession(ctx context.Context, q db.DBTX, tableNumber string, now time.Time) (*TableSession, error) {
+	var tableID, areaID sql.NullString
+	err := q.QueryRowContext(ctx, "SELECT id, area_id FROM tables WHERE table_number = ?", tableNumber).Scan(&tableID, &areaID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	id := utils.GenerateULID()
+	if _, err := q.ExecContext(ctx, `
+		INSERT INTO table_sessions (id, table_id, table_number, area_id, status, seated_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'open', ?, ?, ?)
+	`, id, tableID, tableNumber, areaID, now, now, now); err != nil {
+		return nil, err
+	}
+	if err := logTableSessionEvent(ctx, q, id, TableSessionSeated, "", now); err != nil {
+		return nil, err
+	}
+	return openTableSession(ctx, q, tableNumber)
+}
+
+func logTableSessionEvent(ctx context.Context, q db.DBTX, sessionID, event, orderID string, now time.Time) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO table_session_events (session_id, event, order_id, created_at)
+		VALUES (?, ?, ?, ?)
+	`, sessionID, event, nullableID(orderID), now)
+	return err
+}
+
+// RecordTableSessionEvent mencatat tahap sesi meja. Sesi dibuka saat tamu duduk atau
+// order pertama masuk; tahap bill, lunas dan meja kosong hanya berlaku untuk sesi yang
+// masih berjalan. Meja kosong menutup sesi.
+func RecordTableSessionEvent(ctx context.Context, q db.DBTX, tableNumber, event, orderID string) error {
+	tableNumber = strings.TrimSpace(tableNumber)
+	if tableNumber == "" {
+		return nil
+	}
+	now := time.Now().UTC()
+	session, err := openTableSession(ctx, q, tableNumber)
+	if err != nil {
+		return err
+	}
+
+	switch event {
+	case TableSessionSeated:
+		if session == nil {
+			_, err = startTableSession(ctx, q, tableNumber, now)
+		}
+		return err
+	case TableSessionFirstOrder:
+		if session == nil {
+			if session, err = startTableSession(ctx, q, tableNumber, now); err != nil {
+				return err
+			}
+		}
+		if session.FirstOrderAt != nil {
+			return nil
+		}
+		var pax int64
+		if err := q.QueryRowContext(ctx, "SELECT COALESCE(pax, 0) FROM orders WHERE id = ?", orderID).Scan(&pax); err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if _, err := q.ExecContext(ctx, `
+			UPDATE table_sessions SET first_order_at = ?, order_id = ?, pax = ?, updated_at = ? WHERE id = ?
+		`, now, nullableID(orderID), pax, now, session.ID); err != nil {
+			return err
+		}
+	case TableSessionBillPrinted, TableSessionPaid, TableSessionCleared:
+		if session == nil {
+			return nil
+		}
+		column := map[string]string{
+			TableSessionBillPrinted: "bill_printed_at",
+			TableSessionPaid:        "paid_at",
+			TableSessionCleared:     "cleared_at",
+		}[event]
+		status := TableSessionOpen
+		if event == TableSessionCleared {
+			status = TableSessionClosed
+		}
+		if _, err := q.ExecContext(ctx, fmt.Sprintf(`
+			UPDATE table_sessions SET %s = COALESCE(%s, ?), status = ?, updated_at = ? WHERE id = ?
+		`, column, column), now, status, now, session.ID); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("tahap sesi meja tidak dikenal: %s", event)
+	}
+	return logTableSessionEvent(ctx, q, session.ID, event, orderID, now)
+}
+
+// RecordOrderTableSessionEvent mencatat tahap sesi meja milik order dine-in
+func RecordOrderTableSessionEvent(ctx context.Context, q db.DBTX, orderID, event string) error {
+	var tableNumber, orderType string
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(table_number, ''), COALESCE(order_type, 'dine_in') FROM orders WHERE id = ?
+	`, orderID).Scan(&tableNumber, &orderType)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if err == sql.ErrNoRows || orderType != OrderTypeDineIn {
+		return nil
+	}
+	return RecordTableSessionEvent(ctx, q, tableNumber, event, orderID)
+}
+
+// moveTableSession memindahkan sesi yang berjalan ke meja baru saat tamu pindah meja,
+// sehingga waktu duduk tidak terpotong. Sesi hanya ikut pindah jika meja asal kosong.
+func moveTableSession(ctx context.Context, q db.DBTX, fromTableNumber, toTableNumber, orderID string) error {
+	open, err := countOpenTableOrders(ctx, q, fromTableNumber)
+	if err != nil || open > 0 {
+		return err
+	}
+	session, err := openTableSession(ctx, q, fromTableNumber)
+	if err != nil || session == nil {
+		return err
+	}
+	target, err := openTableSession(ctx, q, toTableNumber)
+	if err != nil || target != nil {
+		return err
+	}
+
+	var tableID, areaID sql.NullString
+	err = q.QueryRowContext(ctx, "SELECT id, area_id FROM tables WHERE table_number = ?", toTableNumber).Scan(&tableID, &areaID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	now := time.Now().UTC()
+	if _, err := q.ExecContext(ctx, `
+		UPDATE table_sessions SET table_id = ?, table_number = ?, area_id = ?, updated_at = ? WHERE id = ?
+	`, tableID, toTableNumber, areaID, now, session.ID); err != nil {
+		return err
+	}
+	return logTableSessionEvent(ctx, q, session.ID, TableSessionTransferred, orderID, now)
+}
+
+func (r *tableSessionRepository) List(ctx context.Context, filter TableSessionFilter) ([]TableSession, error) {
+	conditions := []string{"1 = 1"}
+	args := []interface{}{}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "s.seated_at >= ?")
+		args = append(args, filter.From.UTC())
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "s.seated_at < ?")
+		args = append(args, filter.To.UTC())
+	}
+	if filter.TableNumber != "" {
+		conditions = append(conditions, "s.table_number = ?")
+		args = append(args, filter.TableNumber)
+	}
+	if filter.Status != "" {
+		conditions = append(conditions, "s.status = ?")
+		args = append(args, filter.Status)
+	}
+
+	rows, err := r.db.QueryContext(ctx, tableSessionSelect+" WHERE "+strings.Join(conditions, " AND ")+" ORDER BY s.seated_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []TableSession{}
+	for rows.Next() {
+		session, err := scanTableSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *tableSessionRepository) GetByID(ctx context.Context, id string) (*TableSession, error) {
+	session, err := scanTableSession(r.db.QueryRowContext(ctx, tableSessionSelect+" WHERE s.id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, ErrTableSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT event, COALESCE(order_id, ''), created_at
+		FROM table_session_events
+		WHERE session_id = ?
+		ORDER BY created_at, id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	session.Events = []TableSessionEvent{}
+	for rows.Next() {
+		var event TableSessionEvent
+		if err := rows.Scan(&event.Event, &event.OrderID, &event.CreatedAt); err != nil {
+			return nil, err
+		}
+		session.Events = append(session.Events, event)
+	}
+	return session, rows.Err()
+}
+
+// averageTurnMinutes adalah rata-rata turn time sesi yang punya order selama 30 hari terakhir
+func (r *tableSessionRepository) averageTurnMinutes(ctx context.Context, now time.Time) (float64, error) {
+	sessions, err := r.List(ctx, TableSessionFilter{From: now.AddDate(0, 0, -30), Status: TableSessionClosed})
+	if err != nil {
+		return 0, err
+	}
+	total, count := 0.0, 0
+	for _, session := range sessions {
+		if session.FirstOrderAt == nil || session.TurnMinutes == nil {
+			continue
+		}
+		total += *session.TurnMinutes
+		count++
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	return roundMinutes(total / float64(count)), nil
+}
+
+func (r *tableSessionRepository) Timers(ctx context.Context, now time.Time) ([]TableTimer, error) {
+	sessions, err := r.List(ctx, TableSessionFilter{Status: TableSessionOpen})
+	if err != nil {
+		return nil, err
+	}
+	avgTurn, err := r.averageTurnMinutes(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	timers := make([]TableTimer, 0, len(sessions))
+	for _, session := range sessions {
+		timer := TableTimer{
+			SessionID:      session.ID,
+			TableID:        session.TableID,
+			TableNumber:    session.TableNumber,
+			AreaName:       session.AreaName,
+			OrderID:        session.OrderID,
+			Pax:            session.Pax,
+			Stage:          TableSessionSeated,
+			SeatedAt:       session.SeatedAt,
+			FirstOrderAt:   session.FirstOrderAt,
+			BillPrintedAt:  session.BillPrintedAt,
+			PaidAt:         session.PaidAt,
+			ElapsedMinutes: minutesBetween(session.SeatedAt, now),
+			AvgTurnMinutes: avgTurn,
+		}
+		// Tahap terakhir yang sudah dilalui menentukan timer tahap
+		stageStart := session.SeatedAt
+		for _, stage := range []struct {
+			name string
+			at   *time.Time
+		}{
+			{TableSessionFirstOrder, session.FirstOrderAt},
+			{TableSessionBillPrinted, session.BillPrintedAt},
+			{TableSessionPaid, session.PaidAt},
+		} {
+			if stage.at != nil {
+				timer.Stage = stage.name
+				stageStart = *stage.at
+			}
+		}
+		timer.StageMinutes = minutesBetween(stageStart, now)
+		if avgTurn > 0 && timer.ElapsedMinutes > avgTurn {
+			timer.OverAverageMinutes = roundMinutes(timer.ElapsedMinutes - avgTurn)
+		}
+		timers = append(timers, timer)
+	}
+	// Meja yang paling lama terisi di atas
+	sort.SliceStable(timers, func(i, j int) bool {
+		return timers[i].SeatedAt.Before(timers[j].SeatedAt)
+	})
+	return timers, nil
+}
+
+type minuteAverage struct {
+	total float64
+	count int
+}
+
+func (a *minuteAverage) add(minutes float64) {
+	a.total += minutes
+	a.count++
+}
+
+func (a minuteAverage) value() float64 {
+	if a.count == 0 {
+		return 0
+	}
+	return roundMinutes(a.total / float64(a.count))
+}
+
+func (r *tableSessionRepository) Analytics(ctx context.Context, from, to time.Time) (*TableTurnAnalytics, error) {
+	sessions, err := r.List(ctx, TableSessionFilter{From: from, To: to, Status: TableSessionClosed})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TableTurnAnalytics{
+		From:              from,
+		To:                to,
+		ByPax:             []PaxDwell{},
+		ByArea:            []AreaTurn{},
+		CoversPerTableDay: []TableDayCovers{},
+	}
+	var turn, dwell, seatToOrder, billToPaid minuteAverage
+	paxTurn := map[int64]*minuteAverage{}
+	paxDwell := map[int64]*minuteAverage{}
+	areaIndex := map[string]int{}
+	areaTurn := map[string]*minuteAverage{}
+	areaDwell := map[string]*minuteAverage{}
+	dayIndex := map[string]int{}
+
+	for _, session := range sessions {
+		// Sesi tanpa order = tamu duduk lalu pergi, tidak ikut rata-rata
+		if session.FirstOrderAt == nil {
+			result.SessionsWithoutOrder++
+			continue
+		}
+		result.Sessions++
+		result.Covers += session.Pax
+		seatToOrder.add(minutesBetween(session.SeatedAt, *session.FirstOrderAt))
+		if session.BillPrintedAt != nil && session.PaidAt != nil {
+			billToPaid.add(minutesBetween(*session.BillPrintedAt, *session.PaidAt))
+		}
+
+		areaKey := ""
+		if session.AreaID != nil {
+			areaKey = *session.AreaID
+		}
+		pos, ok := areaIndex[areaKey]
+		if !ok {
+			name := session.AreaName
+			if areaKey == "" {
+				name = "Tanpa area"
+			}
+			result.ByArea = append(result.ByArea, AreaTurn{AreaID: areaKey, AreaName: name})
+			pos = len(result.ByArea) - 1
+			areaIndex[areaKey] = pos
+			areaTurn[areaKey] = &minuteAverage{}
+			areaDwell[areaKey] = &minuteAverage{}
+		}
+		result.ByArea[pos].Sessions++
+		result.ByArea[pos].Covers += session.Pax
+
+		if _, ok := paxTurn[session.Pax]; !ok {
+			paxTurn[session.Pax] = &minuteAverage{}
+			paxDwell[session.Pax] = &minuteAverage{}
+		}
+		if session.TurnMinutes != nil {
+			turn.add(*session.TurnMinutes)
+			paxTurn[session.Pax].add(*session.TurnMinutes)
+			areaTurn[areaKey].add(*session.TurnMinutes)
+		}
+		if session.DwellMinutes != nil {
+			dwell.add(*session.DwellMinutes)
+			paxDwell[session.Pax].add(*session.DwellMinutes)
+			areaDwell[areaKey].add(*session.DwellMinutes)
+		}
+
+		dayKey := session.SeatedAt.In(time.Local).Format("2006-01-02") + "|" + session.TableNumber
+		dayPos, ok := dayIndex[dayKey]
+		if !ok {
+			result.CoversPerTableDay = append(result.CoversPerTableDay, TableDayCovers{
+				Date:        session.SeatedAt.In(time.Local).Format("2006-01-02"),
+				TableNumber: session.TableNumber,
+			})
+			dayPos = len(result.CoversPerTableDay) - 1
+			dayIndex[dayKey] = dayPos
+		}
+		result.CoversPerTableDay[dayPos].Sessions++
+		result.CoversPerTableDay[dayPos].Covers += session.Pax
+	}
+
+	result.AvgTurnMinutes = turn.value()
+	result.AvgDwellMinutes = dwell.value()
+	result.AvgSeatToOrderMinutes = seatToOrder.value()
+	result.AvgBillToPaidMinutes = billToPaid.value()
+	for i := range result.ByArea {
+		key := result.ByArea[i].AreaID
+		result.ByArea[i].AvgTurnMinutes = areaTurn[key].value()
+		result.ByArea[i].AvgDwellMinutes = areaDwell[key].value()
+	}
+	for pax := range paxTurn {
+		result.ByPax = append(result.ByPax, PaxDwell{
+			Pax:             pax,
+			Sessions:        paxTurn[pax].count,
+			AvgDwellMinutes: paxDwell[pax].value(),
+			AvgTurnMinutes:  paxTurn[pax].value(),
+		})
+	}
+	sort.Slice(result.ByPax, func(i, j int) bool { return result.ByPax[i].Pax < result.ByPax[j].Pax })
+	sort.Slice(result.ByArea, func(i, j int) bool { return result.ByArea[i].AreaName < result.ByArea[j].AreaName })
+	sort.Slice(result.CoversPerTableDay, func(i, j int) bool {
+		if result.CoversPerTableDay[i].Date != result.CoversPerTableDay[j].Date {
+			return result.CoversPerTableDay[i].Date < result.CoversPerTableDay[j].Date
+		}
+		return result.CoversPerTableDay[i].TableNumber < result.CoversPerTableDay[j].TableNumber
+	})
+
+	// Rata-rata tamu per meja per hari dihitung atas seluruh meja, termasuk yang tidak terpakai
+	var tableCount int
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM tables").Scan(&tableCount); err != nil {
+		return nil, err
+	}
+	days := int(to.Sub(from).Hours()/24 + 0.5)
+	if days < 1 {
+		days = 1
+	}
+	if tableCount > 0 {
+		result.AvgCoversPerTablePerDay = float64(int64(float64(result.Covers)/float64(tableCount*days)*100+0.5)) / 100
+	}
+	return result, nil
+}
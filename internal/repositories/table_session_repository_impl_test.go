@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/pkg/utils"
+)
+
+type testTableSession struct {
+	tableNumber string
+	areaID      string
+	pax         int64
+	status      string
+	seatedAt    time.Time
+	// offset menit dari seatedAt; 0 berarti tahap belum terjadi
+	firstOrder, billPrinted, paid, cleared int
+}
+
+func insertTestTableSession(t *testing.T, conn *sql.DB, session testTableSession) {
+	t.Helper()
+	at := func(minutes int) interface{} {
+		if minutes == 0 {
+			return nil
+		}
+		return session.seatedAt.Add(time.Duration(minutes) * time.Minute).UTC()
+	}
+	status := session.status
+	if status == "" {
+		status = TableSessionClosed
+	}
+	var areaID interface{}
+	if session.areaID != "" {
+		areaID = session.areaID
+	}
+	mustExec(t, conn, `
+		INSERT INTO table_sessions (
+			id, table_number, area_id, pax, status, seated_at, first_order_at, bill_printed_at, paid_at, cleared_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, utils.GenerateULID(), session.tableNumber, areaID, session.pax, status, session.seatedAt.UTC(),
+		at(session.firstOrder), at(session.billPrinted), at(session.paid), at(session.cleared))
+}
+
+func TestMinutesBetween(t *testing.T) {
+	base := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		to   time.Time
+		want float64
+	}{
+		{"menit penuh", base.Add(45 * time.Minute), 45},
+		{"dibulatkan ke 0,1 menit", base.Add(90*time.Minute + 20*time.Second), 90.3},
+		{"pembulatan ke atas", base.Add(10*time.Minute + 57*time.Second), 11},
+		{"waktu mundur dianggap nol", base.Add(-time.Minute), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := minutesBetween(base, tt.to); got != tt.want {
+				t.Errorf("minutesBetween() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTableSessionAnalytics(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestDB(t)
+
+	indoor, outdoor := utils.GenerateULID(), utils.GenerateULID()
+	mustExec(t, conn, `INSERT INTO floor_areas (id, name) VALUES (?, 'Indoor'), (?, 'Outdoor')`, indoor, outdoor)
+	for _, number := range []string{"T1", "T2", "T3", "T4"} {
+		mustExec(t, conn, `INSERT INTO tables (id, table_number) VALUES (?, ?)`, utils.GenerateULID(), number)
+	}
+
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 1)
+	noon := from.Add(12 * time.Hour)
+	for _, session := range []testTableSession{
+		{tableNumber: "T1", areaID: indoor, pax: 2, seatedAt: noon, firstOrder: 10, billPrinted: 50, paid: 60, cleared: 70},
+		{tableNumber: "T1", areaID: indoor, pax: 4, seatedAt: noon.Add(2 * time.Hour), firstOrder: 20, billPrinted: 80, paid: 90, cleared: 100},
+		{tableNumber: "T2", areaID: outdoor, pax: 2, seatedAt: noon.Add(time.Hour), firstOrder: 5, paid: 45, cleared: 50},
+		// Tamu duduk lalu pergi tanpa order
+		{tableNumber: "T2", areaID: outdoor, seatedAt: noon.Add(3 * time.Hour), cleared: 5},
+		// Sesi yang masih berjalan dan sesi di luar rentang tidak dihitung
+		{tableNumber: "T3", pax: 3, status: TableSessionOpen, seatedAt: noon, firstOrder: 5},
+		{tableNumber: "T3", pax: 6, seatedAt: from.Add(-2 * time.Hour), firstOrder: 5, paid: 30, cleared: 40},
+	} {
+		insertTestTableSession(t, conn, session)
+	}
+
+	analytics, err := NewTableSessionRepository(conn).Analytics(ctx, from, to)
+	if err != nil {
+		t.Fatalf("Analytics: %v", err)
+	}
+
+	summary := []struct {
+		name      string
+		got, want float64
+	}{
+		{"sessions", float64(analytics.Sessions), 3},
+		{"sessions_without_order", float64(analytics.SessionsWithoutOrder), 1},
+		{"covers", float64(analytics.Covers), 8},
+		{"avg_turn_minutes", analytics.AvgTurnMinutes, 73.3},
+		{"avg_dwell_minutes", analytics.AvgDwellMinutes, 65},
+		{"avg_seat_to_order_minutes", analytics.AvgSeatToOrderMinutes, 11.7},
+		{"avg_bill_to_paid_minutes", analytics.AvgBillToPaidMinutes, 10},
+		{"avg_covers_per_table_per_day", analytics.AvgCoversPerTablePerDay, 2},
+	}
+	for _, tt := range summary {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	wantPax := []PaxDwell{
+		{Pax: 2, Sessions: 2, AvgDwellMinutes: 52.5, AvgTurnMinutes: 60},
+		{Pax: 4, Sessions: 1, AvgDwellMinutes: 90, AvgTurnMinutes: 100},
+	}
+	if !reflect.DeepEqual(analytics.ByPax, wantPax) {
+		t.Errorf("by_pax = %+v, want %+v", analytics.ByPax, wantPax)
+	}
+
+	wantArea := []AreaTurn{
+		{AreaID: indoor, AreaName: "Indoor", Sessions: 2, Covers: 6, AvgDwellMinutes: 75, AvgTurnMinutes: 85},
+		{AreaID: outdoor, AreaName: "Outdoor", Sessions: 1, Covers: 2, AvgDwellMinutes: 45, AvgTurnMinutes: 50},
+	}
+	if !reflect.DeepEqual(analytics.ByArea, wantArea) {
+		t.Errorf("by_area = %+v, want %+v", analytics.ByArea, wantArea)
+	}
+
+	wantCovers := []TableDayCovers{
+		{Date: "2026-10-19", TableNumber: "T1", Sessions: 2, Covers: 6},
+		{Date: "2026-10-19", TableNumber: "T2", Sessions: 1, Covers: 2},
+	}
+	if !reflect.DeepEqual(analytics.CoversPerTableDay, wantCovers) {
+		t.Errorf("covers_per_table_day = %+v, want %+v", analytics.CoversPerTableDay, wantCovers)
+	}
+}
+
+func TestRecordTableSessionEvent(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestDB(t)
+	orderID := insertTestOrder(t, conn, time.Now())
+	mustExec(t, conn, `UPDATE orders SET pax = 3 WHERE id = ?`, orderID)
+
+	// Bill sebelum sesi dibuka diabaikan; order pertama membuka sesi
+	for _, event := range []string{TableSessionBillPrinted, TableSessionFirstOrder, TableSessionFirstOrder, TableSessionBillPrinted, TableSessionPaid} {
+		if err := RecordTableSessionEvent(ctx, conn, "A1", event, orderID); err != nil {
+			t.Fatalf("RecordTableSessionEvent(%s): %v", event, err)
+		}
+	}
+
+	session, err := openTableSession(ctx, conn, "A1")
+	if err != nil || session == nil {
+		t.Fatalf("sesi terbuka = %+v, %v", session, err)
+	}
+	if session.Pax != 3 || session.OrderID == nil || *session.OrderID != orderID {
+		t.Errorf("pax/order sesi = %d/%v, want 3/%s", session.Pax, session.OrderID, orderID)
+	}
+	if session.FirstOrderAt == nil || session.BillPrintedAt == nil || session.PaidAt == nil || session.ClearedAt != nil {
+		t.Errorf("tahap sesi tidak sesuai: %+v", session)
+	}
+	if session.DwellMinutes == nil || session.TurnMinutes != nil {
+		t.Errorf("dwell/turn = %v/%v, want dwell terisi dan turn kosong", session.DwellMinutes, session.TurnMinutes)
+	}
+
+	if err := RecordTableSessionEvent(ctx, conn, "A1", TableSessionCleared, ""); err != nil {
+		t.Fatalf("RecordTableSessionEvent(cleared): %v", err)
+	}
+	closed, err := NewTableSessionRepository(conn).GetByID(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if closed.Status != TableSessionClosed || closed.TurnMinutes == nil {
+		t.Errorf("sesi setelah meja kosong = %s, turn %v", closed.Status, closed.TurnMinutes)
+	}
+
+	events := []string{}
+	for _, event := range closed.Events {
+		events = append(events, event.Event)
+	}
+	wantEvents := []string{TableSessionSeated, TableSessionFirstOrder, TableSessionBillPrinted, TableSessionPaid, TableSessionCleared}
+	if !reflect.DeepEqual(events, wantEvents) {
+		t.Errorf("events = %v, want %v", events, wantEvents)
+	}
+
+	if open, err := openTableSession(ctx, conn, "A1"); err != nil || open != nil {
+		t.Errorf("sesi terbuka setelah meja kosong = %+v, %v", open, err)
+	}
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TaxRate adalah tarif pajak bernama (mis. PB1 10%, PPN 11%).
+// Tarif berlaku untuk produk yang ditugaskan langsung, lalu kategori produk,
+// dan jika tidak ada penugasan sama sekali dipakai tarif is_default.
+type TaxRate struct {
+	ID                   string    `json:"id"`
+	Name                 string    `json:"name"`
+	Rate                 float64   `json:"rate"`
+	IsInclusive          bool      `json:"is_inclusive"`
+	IncludeServiceCharge bool      `json:"include_service_charge"`
+	IsDefault            bool      `json:"is_default"`
+	IsActive             bool      `json:"is_active"`
+	SortOrder            int64     `json:"sort_order"`
+	CategoryIDs          []string  `json:"category_ids"`
+	ProductIDs           []string  `json:"product_ids"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// TaxReportLine adalah rekap pajak per tarif untuk pelaporan
+type TaxReportLine struct {
+	TaxRateID     string  `json:"tax_rate_id"`
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	IsInclusive   bool    `json:"is_inclusive"`
+	OrdersCount   int64   `json:"orders_count"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+// TaxReportDay adalah rekap pajak harian per tarif
+type TaxReportDay struct {
+	Date          string  `json:"date"`
+	TaxRateID     string  `json:"tax_rate_id"`
+	Name          string  `json:"name"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	TaxAmount     float64 `json:"tax_amount"`
+}
+
+var (
+	ErrTaxRateNotFound = errors.New("tarif pajak tidak ditemukan")
+	ErrInvalidTaxRate  = errors.New("data tarif pajak tidak valid")
+)
+
+type TaxRepository interface {
+	List(ctx context.Context) ([]TaxRate, error)
+	GetByID(ctx context.Context, id string) (*TaxRate, error)
+	Create(ctx context.Context, rate *TaxRate) error
+	Update(ctx context.Context, rate *TaxRate) error
+	Delete(ctx context.Context, id string) error
+	GetTaxReport(ctx context.Context, startDate, endDate time.Time) ([]TaxReportLine, []TaxReportDay, error)
+}
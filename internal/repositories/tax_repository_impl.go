@@ -0,0 +1,434 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+type taxRepository struct {
+	db *sql.DB
+}
+
+func NewTaxRepository(dbConn *sql.DB) TaxRepository {
+	return &taxRepository{db: dbConn}
+}
+
+// applyTaxes menghitung pajak order dan menyimpannya di order_taxes.
+// Urutan: subtotal item -> diskon promosi -> service charge -> pajak -> penyesuaian manual kasir.
+// Dasar pajak tiap item dikurangi diskon promosi secara proporsional; tarif dengan
+// include_service_charge ikut menanggung porsi service charge. Harga inklusif menghitung
+// pajak dari dalam harga (base * rate / (100 + rate)) dan tidak menambah total.
+// Pajak dibulatkan ke rupiah terdekat per tarif per order. Mengembalikan total pajak eksklusif.
+func applyTaxes(ctx context.Context, tx *sql.Tx, orderID string, subtotal, discountedSubtotal, serviceTotal float64) (float64, error) {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM order_taxes WHERE order_id = ?", orderID); err != nil {
+		return 0, err
+	}
+	if subtotal <= 0 || discountedSubtotal <= 0 {
+		return 0, nil
+	}
+
+	rates, err := loadActiveTaxRates(ctx, tx)
+	if err != nil {
+		return 0, err
+	}
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	items, err := loadPromotionOrderItems(ctx, tx, orderID)
+	if err != nil {
+		return 0, err
+	}
+
+	defaults := []int{}
+	byProduct := map[string][]int{}
+	byCategory := map[string][]int{}
+	for i, rate := range rates {
+		if rate.IsDefault {
+			defaults = append(defaults, i)
+		}
+		for _, productID := range rate.ProductIDs {
+			byProduct[productID] = append(byProduct[productID], i)
+		}
+		for _, categoryID := range rate.CategoryIDs {
+			byCategory[categoryID] = append(byCategory[categoryID], i)
+		}
+	}
+
+	itemBase := make([]float64, len(rates))
+	for _, item := range items {
+		applicable := byProduct[item.productID]
+		if len(applicable) == 0 {
+			applicable = byCategory[item.categoryID]
+		}
+		if len(applicable) == 0 {
+			applicable = defaults
+		}
+		for _, idx := range applicable {
+			itemBase[idx] += item.price * float64(item.qty)
+		}
+	}
+
+	discountRatio := discountedSubtotal / subtotal
+	serviceRatio := serviceTotal / discountedSubtotal
+
+	exclusiveTotal := 0.0
+	for i, rate := range rates {
+		base := itemBase[i] * discountRatio
+		if rate.IncludeServiceCharge {
+			base += base * serviceRatio
+		}
+		if base <= 0 || rate.Rate <= 0 {
+			continue
+		}
+
+		var taxAmount, taxable float64
+		if rate.IsInclusive {
+			taxAmount = math.Round(base * rate.Rate / (100 + rate.Rate))
+			taxable = math.Round(base) - taxAmount
+		} else {
+			taxAmount = math.Round(base * rate.Rate / 100)
+			taxable = math.Round(base)
+		}
+		if taxAmount <= 0 {
+			continue
+		}
+
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO order_taxes (
+				order_id,
+				tax_rate_id,
+				name,
+				rate,
+				is_inclusive,
+				taxable_amount,
+				tax_amount,
+				created_at
+			) VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		`, orderID, rate.ID, rate.Name, rate.Rate, boolToInt(rate.IsInclusive), taxable, taxAmount)
+		if err != nil {
+			return 0, fmt.Errorf("gagal menyimpan pajak %s: %w", rate.Name, err)
+		}
+
+		if !rate.IsInclusive {
+			exclusiveTotal += taxAmount
+		}
+	}
+
+	return exclusiveTotal, nil
+}
+
+func loadActiveTaxRates(ctx context.Context, dbtx db.DBTX) ([]TaxRate, error) {
+	rows, err := dbtx.QueryContext(ctx, `
+		SELECT id, name, rate, is_inclusive, include_service_charge, is_default, is_active, sort_order, created_at, updated_at
+		FROM tax_rates
+		WHERE is_active = 1
+		ORDER BY sort_order, name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := scanTaxRates(rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadTaxAssignments(ctx, dbtx, rates); err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+func scanTaxRates(rows *sql.Rows) ([]TaxRate, error) {
+	defer rows.Close()
+
+	rates := []TaxRate{}
+	for rows.Next() {
+		var rate TaxRate
+		var isInclusive, includeService, isDefault, isActive int64
+		if err := rows.Scan(
+			&rate.ID,
+			&rate.Name,
+			&rate.Rate,
+			&isInclusive,
+			&includeService,
+			&isDefault,
+			&isActive,
+			&rate.SortOrder,
+			&rate.CreatedAt,
+			&rate.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		rate.IsInclusive = isInclusive == 1
+		rate.IncludeServiceCharge = includeService == 1
+		rate.IsDefault = isDefault == 1
+		rate.IsActive = isActive == 1
+		rate.CategoryIDs = []string{}
+		rate.ProductIDs = []string{}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+func loadTaxAssignments(ctx context.Context, dbtx db.DBTX, rates []TaxRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	index := make(map[string]int, len(rates))
+	for i, rate := range rates {
+		index[rate.ID] = i
+	}
+
+	rows, err := dbtx.QueryContext(ctx, `
+		SELECT tax_rate_id, target_type, target_id
+		FROM tax_rate_assignments
+		ORDER BY target_type, target_id
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rateID, targetType, targetID string
+		if err := rows.Scan(&rateID, &targetType, &targetID); err != nil {
+			return err
+		}
+		i, ok := index[rateID]
+		if !ok {
+			continue
+		}
+		if targetType == "product" {
+			rates[i].ProductIDs = append(rates[i].ProductIDs, targetID)
+		} else {
+			rates[i].CategoryIDs = append(rates[i].CategoryIDs, targetID)
+		}
+	}
+	return rows.Err()
+}
+
+func validateTaxRate(rate *TaxRate) error {
+	rate.Name = strings.TrimSpace(rate.Name)
+	if rate.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidTaxRate)
+	}
+	if rate.Rate <= 0 || rate.Rate > 100 {
+		return fmt.Errorf("%w: rate harus lebih dari 0 dan maksimal 100", ErrInvalidTaxRate)
+	}
+	return nil
+}
+
+func saveTaxAssignments(ctx context.Context, tx *sql.Tx, rate *TaxRate) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tax_rate_assignments WHERE tax_rate_id = ?", rate.ID); err != nil {
+		return fmt.Errorf("gagal menghapus penugasan pajak: %w", err)
+	}
+	insert := func(targetType string, ids []string) error {
+		for _, id := range ids {
+			if id == "" {
+				continue
+			}
+			_, err := tx.ExecContext(ctx, `
+				INSERT OR IGNORE INTO tax_rate_assignments (tax_rate_id, target_type, target_id)
+				VALUES (?, ?, ?)
+			`, rate.ID, targetType, id)
+			if err != nil {
+				return fmt.Errorf("gagal menyimpan penugasan pajak: %w", err)
+			}
+		}
+		return nil
+	}
+	if err := insert("category", rate.CategoryIDs); err != nil {
+		return err
+	}
+	return insert("product", rate.ProductIDs)
+}
+
+func (r *taxRepository) List(ctx context.Context) ([]TaxRate, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, rate, is_inclusive, include_service_charge, is_default, is_active, sort_order, created_at, updated_at
+		FROM tax_rates
+		ORDER BY sort_order, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gagal mengambil tarif pajak: %w", err)
+	}
+	rates, err := scanTaxRates(rows)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca tarif pajak: %w", err)
+	}
+	if err := loadTaxAssignments(ctx, r.db, rates); err != nil {
+		return nil, fmt.Errorf("gagal mengambil penugasan pajak: %w", err)
+	}
+	return rates, nil
+}
+
+func (r *taxRepository) GetByID(ctx context.Context, id string) (*TaxRate, error) {
+	rates, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rates {
+		if rates[i].ID == id {
+			return &rates[i], nil
+		}
+	}
+	return nil, ErrTaxRateNotFound
+}
+
+func (r *taxRepository) Create(ctx context.Context, rate *TaxRate) error {
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	rate.ID = utils.GenerateULID()
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO tax_rates (
+			id, name, rate, is_inclusive, include_service_charge, is_default, is_active, sort_order, created_at, updated_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, rate.ID, rate.Name, rate.Rate, boolToInt(rate.IsInclusive), boolToInt(rate.IncludeServiceCharge),
+		boolToInt(rate.IsDefault), boolToInt(rate.IsActive), rate.SortOrder)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("gagal membuat tarif pajak: %w", err)
+	}
+
+	if err := saveTaxAssignments(ctx, tx, rate); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *taxRepository) Update(ctx context.Context, rate *TaxRate) error {
+	if err := validateTaxRate(rate); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE tax_rates
+		SET name = ?, rate = ?, is_inclusive = ?, include_service_charge = ?, is_default = ?,
+		    is_active = ?, sort_order = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, rate.Name, rate.Rate, boolToInt(rate.IsInclusive), boolToInt(rate.IncludeServiceCharge),
+		boolToInt(rate.IsDefault), boolToInt(rate.IsActive), rate.SortOrder, rate.ID)
+	if err != nil {
+		_ = tx.Rollback()
+		return fmt.Errorf("gagal update tarif pajak: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		_ = tx.Rollback()
+		return ErrTaxRateNotFound
+	}
+
+	if err := saveTaxAssignments(ctx, tx, rate); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *taxRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, "DELETE FROM tax_rates WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("gagal menghapus tarif pajak: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrTaxRateNotFound
+	}
+	return nil
+}
+
+// GetTaxReport merekap pajak dari order yang sudah dibayar (tidak void/merged/cancelled)
+func (r *taxRepository) GetTaxReport(ctx context.Context, startDate, endDate time.Time) ([]TaxReportLine, []TaxReportDay, error) {
+	const paidOrderFilter = `
+		o.created_at BETWEEN ? AND ?
+		AND o.payment_status = 'paid'
+		AND o.is_merged = 0
+		AND o.voided_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1
+			FROM transactions t
+			WHERE t.order_id = o.id
+			AND t.status = 'cancelled'
+		)`
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			COALESCE(ot.tax_rate_id, ''),
+			ot.name,
+			ot.rate,
+			ot.is_inclusive,
+			COUNT(DISTINCT ot.order_id),
+			COALESCE(SUM(ot.taxable_amount), 0),
+			COALESCE(SUM(ot.tax_amount), 0)
+		FROM order_taxes ot
+		INNER JOIN orders o ON o.id = ot.order_id
+		WHERE `+paidOrderFilter+`
+		GROUP BY ot.tax_rate_id, ot.name, ot.rate, ot.is_inclusive
+		ORDER BY ot.name, ot.rate
+	`, startDate, endDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal mengambil laporan pajak: %w", err)
+	}
+	defer rows.Close()
+
+	lines := []TaxReportLine{}
+	for rows.Next() {
+		var line TaxReportLine
+		var isInclusive int64
+		if err := rows.Scan(&line.TaxRateID, &line.Name, &line.Rate, &isInclusive, &line.OrdersCount, &line.TaxableAmount, &line.TaxAmount); err != nil {
+			return nil, nil, fmt.Errorf("gagal membaca laporan pajak: %w", err)
+		}
+		line.IsInclusive = isInclusive == 1
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	dayRows, err := r.db.QueryContext(ctx, `
+		SELECT
+			DATE(o.created_at) as day,
+			COALESCE(ot.tax_rate_id, ''),
+			ot.name,
+			COALESCE(SUM(ot.taxable_amount), 0),
+			COALESCE(SUM(ot.tax_amount), 0)
+		FROM order_taxes ot
+		INNER JOIN orders o ON o.id = ot.order_id
+		WHERE `+paidOrderFilter+`
+		GROUP BY day, ot.tax_rate_id, ot.name
+		ORDER BY day, ot.name
+	`, startDate, endDate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal mengambil laporan pajak harian: %w", err)
+	}
+	defer dayRows.Close()
+
+	days := []TaxReportDay{}
+	for dayRows.Next() {
+		var day TaxReportDay
+		if err := dayRows.Scan(&day.Date, &day.TaxRateID, &day.Name, &day.TaxableAmount, &day.TaxAmount); err != nil {
+			return nil, nil, fmt.Errorf("gagal membaca laporan pajak harian: %w", err)
+		}
+		days = append(days, day)
+	}
+	return lines, days, dayRows.Err()
+}
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/db"
+	"backend/pkg/utils"
+)
+
+type testTaxRate struct {
+	name           string
+	rate           float64
+	inclusive      bool
+	includeService bool
+	isDefault      bool
+	// assign berisi target penugasan, mis. "category:drink" atau "product:tea"
+	assign []string
+}
+
+func insertTestTaxRate(t *testing.T, conn *sql.DB, rate testTaxRate, targets map[string]string) {
+	t.Helper()
+	id := utils.GenerateULID()
+	mustExec(t, conn, `
+		INSERT INTO tax_rates (id, name, rate, is_inclusive, include_service_charge, is_default)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, id, rate.name, rate.rate, boolToInt(rate.inclusive), boolToInt(rate.includeService), boolToInt(rate.isDefault))
+	for _, key := range rate.assign {
+		targetType, targetKey, _ := strings.Cut(key, ":")
+		mustExec(t, conn, `INSERT INTO tax_rate_assignments (tax_rate_id, target_type, target_id) VALUES (?, ?, ?)`,
+			id, targetType, targets[targetKey])
+	}
+}
+
+// taxTestOrder membuat order Steak 2 x 50.000 (makanan) dan Es Teh 1 x 20.000 (minuman)
+func taxTestOrder(t *testing.T, conn *sql.DB) (string, map[string]string) {
+	t.Helper()
+	food := insertTestCategory(t, conn, "Makanan")
+	drink := insertTestCategory(t, conn, "Minuman")
+	steak := insertTestProduct(t, conn, "Steak", 50000, food)
+	tea := insertTestProduct(t, conn, "Es Teh", 20000, drink)
+	orderID := insertTestOrder(t, conn, time.Now(),
+		testOrderItem{productID: steak, name: "Steak", price: 50000, qty: 2},
+		testOrderItem{productID: tea, name: "Es Teh", price: 20000, qty: 1},
+	)
+	return orderID, map[string]string{"food": food, "drink": drink, "steak": steak, "tea": tea}
+}
+
+func TestApplyTaxes(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name               string
+		rates              []testTaxRate
+		discountedSubtotal float64
+		serviceTotal       float64
+		wantExclusive      float64
+		wantStored         float64
+		wantRows           int
+	}{
+		{
+			name:               "tanpa tarif pajak",
+			discountedSubtotal: 120000,
+		},
+		{
+			name:               "pajak eksklusif default",
+			rates:              []testTaxRate{{name: "PB1", rate: 10, isDefault: true}},
+			discountedSubtotal: 120000,
+			wantExclusive:      12000,
+			wantStored:         12000,
+			wantRows:           1,
+		},
+		{
+			name:               "pajak eksklusif termasuk service charge",
+			rates:              []testTaxRate{{name: "PB1", rate: 10, isDefault: true, includeService: true}},
+			discountedSubtotal: 120000,
+			serviceTotal:       6000,
+			wantExclusive:      12600,
+			wantStored:         12600,
+			wantRows:           1,
+		},
+		{
+			name:               "pajak eksklusif tanpa service charge",
+			rates:              []testTaxRate{{name: "PB1", rate: 10, isDefault: true}},
+			discountedSubtotal: 120000,
+			serviceTotal:       6000,
+			wantExclusive:      12000,
+			wantStored:         12000,
+			wantRows:           1,
+		},
+		{
+			name:               "pajak inklusif tidak menambah total",
+			rates:              []testTaxRate{{name: "PPN", rate: 11, inclusive: true, isDefault: true}},
+			discountedSubtotal: 120000,
+			wantExclusive:      0,
+			wantStored:         11892,
+			wantRows:           1,
+		},
+		{
+			name:               "dasar pajak dikurangi diskon promosi",
+			rates:              []testTaxRate{{name: "PB1", rate: 10, isDefault: true}},
+			discountedSubtotal: 108000,
+			wantExclusive:      10800,
+			wantStored:         10800,
+			wantRows:           1,
+		},
+		{
+			name: "tarif kategori menggantikan tarif default",
+			rates: []testTaxRate{
+				{name: "PB1", rate: 10, isDefault: true},
+				{name: "Pajak Minuman", rate: 5, assign: []string{"category:drink"}},
+			},
+			discountedSubtotal: 120000,
+			wantExclusive:      11000,
+			wantStored:         11000,
+			wantRows:           2,
+		},
+		{
+			name: "tarif produk mengalahkan tarif kategori",
+			rates: []testTaxRate{
+				{name: "PB1", rate: 10, isDefault: true},
+				{name: "Pajak Minuman", rate: 5, assign: []string{"category:drink"}},
+				{name: "Pajak Teh", rate: 20, assign: []string{"product:tea"}},
+			},
+			discountedSubtotal: 120000,
+			wantExclusive:      14000,
+			wantStored:         14000,
+			wantRows:           2,
+		},
+		{
+			name: "campuran inklusif dan eksklusif",
+			rates: []testTaxRate{
+				{name: "PB1", rate: 10, isDefault: true},
+				{name: "PPN", rate: 10, inclusive: true, assign: []string{"category:drink"}},
+			},
+			discountedSubtotal: 120000,
+			wantExclusive:      10000,
+			wantStored:         11818,
+			wantRows:           2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			orderID, targets := taxTestOrder(t, conn)
+			for _, rate := range tt.rates {
+				insertTestTaxRate(t, conn, rate, targets)
+			}
+
+			tx, err := conn.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("begin tx: %v", err)
+			}
+			defer tx.Rollback()
+
+			exclusive, err := applyTaxes(ctx, tx, orderID, 120000, tt.discountedSubtotal, tt.serviceTotal)
+			if err != nil {
+				t.Fatalf("applyTaxes: %v", err)
+			}
+			if exclusive != tt.wantExclusive {
+				t.Errorf("pajak eksklusif = %v, want %v", exclusive, tt.wantExclusive)
+			}
+
+			var rows int
+			var stored float64
+			if err := tx.QueryRowContext(ctx, `
+				SELECT COUNT(*), COALESCE(SUM(tax_amount), 0) FROM order_taxes WHERE order_id = ?
+			`, orderID).Scan(&rows, &stored); err != nil {
+				t.Fatalf("baca order_taxes: %v", err)
+			}
+			if rows != tt.wantRows {
+				t.Errorf("baris pajak = %d, want %d", rows, tt.wantRows)
+			}
+			if stored != tt.wantStored {
+				t.Errorf("tax_amount tersimpan = %v, want %v", stored, tt.wantStored)
+			}
+		})
+	}
+}
+
+// TestRecalculateOrderTotalsTaxOrder memastikan urutan diskon promosi -> service charge -> pajak -> penyesuaian manual
+func TestRecalculateOrderTotalsTaxOrder(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name           string
+		tax            testTaxRate
+		promoPercent   float64
+		manualDiscount float64
+		wantTotal      float64
+		wantTax        float64
+		wantTaxable    float64
+	}{
+		{
+			name:        "service charge lalu pajak",
+			tax:         testTaxRate{name: "PB1", rate: 10, isDefault: true, includeService: true},
+			wantTotal:   138600,
+			wantTax:     12600,
+			wantTaxable: 126000,
+		},
+		{
+			name:        "pajak tanpa service charge",
+			tax:         testTaxRate{name: "PB1", rate: 10, isDefault: true},
+			wantTotal:   138000,
+			wantTax:     12000,
+			wantTaxable: 120000,
+		},
+		{
+			name:         "diskon promosi sebelum service charge dan pajak",
+			tax:          testTaxRate{name: "PB1", rate: 10, isDefault: true, includeService: true},
+			promoPercent: 10,
+			wantTotal:    124740,
+			wantTax:      11340,
+			wantTaxable:  113400,
+		},
+		{
+			name:           "diskon manual setelah pajak",
+			tax:            testTaxRate{name: "PB1", rate: 10, isDefault: true, includeService: true},
+			manualDiscount: 10000,
+			wantTotal:      128600,
+			wantTax:        12600,
+			wantTaxable:    126000,
+		},
+		{
+			name:        "pajak inklusif tidak menambah total",
+			tax:         testTaxRate{name: "PPN", rate: 10, inclusive: true, isDefault: true, includeService: true},
+			wantTotal:   126000,
+			wantTax:     11455,
+			wantTaxable: 114545,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			orderID, targets := taxTestOrder(t, conn)
+			mustExec(t, conn, `DELETE FROM additional_charges`)
+			mustExec(t, conn, `INSERT INTO additional_charges (name, charge_type, value, is_active) VALUES ('Service', 'percentage', 5, 1)`)
+			insertTestTaxRate(t, conn, tt.tax, targets)
+			if tt.promoPercent > 0 {
+				insertTestPromotion(t, conn, testPromotion{name: "Diskon", promoType: "percentage_off", value: tt.promoPercent})
+			}
+			if tt.manualDiscount > 0 {
+				mustExec(t, conn, `
+					INSERT INTO order_additional_charges (order_id, name, charge_type, value, applied_amount)
+					VALUES (?, 'Diskon Kasir', 'fixed', ?, ?)
+				`, orderID, tt.manualDiscount, -tt.manualDiscount)
+			}
+
+			tx, err := conn.BeginTx(ctx, nil)
+			if err != nil {
+				t.Fatalf("begin tx: %v", err)
+			}
+			defer tx.Rollback()
+
+			if _, _, err := recalculateOrderTotals(ctx, db.New(tx), tx, orderID); err != nil {
+				t.Fatalf("recalculateOrderTotals: %v", err)
+			}
+
+			var total float64
+			if err := tx.QueryRowContext(ctx, `SELECT total_amount FROM orders WHERE id = ?`, orderID).Scan(&total); err != nil {
+				t.Fatalf("baca order: %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("total_amount = %v, want %v", total, tt.wantTotal)
+			}
+
+			var taxAmount, taxable float64
+			if err := tx.QueryRowContext(ctx, `
+				SELECT tax_amount, taxable_amount FROM order_taxes WHERE order_id = ?
+			`, orderID).Scan(&taxAmount, &taxable); err != nil {
+				t.Fatalf("baca order_taxes: %v", err)
+			}
+			if taxAmount != tt.wantTax {
+				t.Errorf("tax_amount = %v, want %v", taxAmount, tt.wantTax)
+			}
+			if taxable != tt.wantTaxable {
+				t.Errorf("taxable_amount = %v, want %v", taxable, tt.wantTaxable)
+			}
+		})
+	}
+}
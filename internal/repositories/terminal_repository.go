@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TerminalHeader adalah header HTTP yang dikirim aplikasi kasir untuk menyebut
+// terminalnya (id, code atau device_id terminal)
+const TerminalHeader = "X-Terminal-ID"
+
+// Terminal adalah stasiun kasir dengan laci kas sendiri. Shift kasir dibuka per
+// terminal sehingga beberapa kasir bisa berjalan bersamaan dalam satu outlet.
+type Terminal struct {
+	ID        string         `json:"id"`
+	Code      string         `json:"code"`
+	Name      string         `json:"name"`
+	DeviceID  *string        `json:"device_id"`
+	PrinterID *string        `json:"printer_id"`
+	IsActive  bool           `json:"is_active"`
+	OpenShift *TerminalShift `json:"open_shift"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// TerminalShift adalah ringkasan shift yang sedang terbuka di sebuah terminal
+type TerminalShift struct {
+	ID           string    `json:"id"`
+	OpenedBy     string    `json:"opened_by"`
+	OpenedByName string    `json:"opened_by_name"`
+	OpenedAt     time.Time `json:"opened_at"`
+}
+
+var (
+	ErrTerminalNotFound = errors.New("terminal kasir tidak ditemukan")
+	ErrInvalidTerminal  = errors.New("data terminal kasir tidak valid")
+	ErrTerminalRequired = errors.New("terminal kasir wajib dipilih (header X-Terminal-ID)")
+	ErrTerminalInUse    = errors.New("terminal kasir sudah memiliki riwayat shift, nonaktifkan saja")
+)
+
+type TerminalRepository interface {
+	List(ctx context.Context, activeOnly bool) ([]Terminal, error)
+	GetByID(ctx context.Context, id string) (*Terminal, error)
+	Create(ctx context.Context, terminal *Terminal) error
+	Update(ctx context.Context, terminal *Terminal) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type terminalRepository struct {
+	db *sql.DB
+}
+
+func NewTerminalRepository(dbConn *sql.DB) TerminalRepository {
+	return &terminalRepository{db: dbConn}
+}
+
+var terminalCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{2,32}$`)
+
+const terminalColumns = `
+	t.id, t.code, t.name, t.device_id, t.printer_id, t.is_active, t.created_at, t.updated_at,
+	cs.id, cs.opened_by, u.full_name, cs.opened_at
+`
+
+const terminalFrom = `
+	FROM cashier_terminals t
+	LEFT JOIN cashier_shifts cs ON cs.terminal_id = t.id AND cs.status = 'open'
+	LEFT JOIN users u ON u.id = cs.opened_by
+`
+
+func scanTerminal(scanner interface{ Scan(dest ...any) error }) (*Terminal, error) {
+	var terminal Terminal
+	var deviceID, printerID sql.NullString
+	var isActive int64
+	var shiftID, openedBy, openedByName sql.NullString
+	var openedAt sql.NullTime
+	if err := scanner.Scan(
+		&terminal.ID, &terminal.Code, &terminal.Name, &deviceID, &printerID, &isActive, &terminal.CreatedAt, &terminal.UpdatedAt,
+		&shiftID, &openedBy, &openedByName, &openedAt,
+	); err != nil {
+		return nil, err
+	}
+	if deviceID.Valid {
+		terminal.DeviceID = &deviceID.String
+	}
+	if printerID.Valid {
+		terminal.PrinterID = &printerID.String
+	}
+	terminal.IsActive = isActive == 1
+	if shiftID.Valid {
+		terminal.OpenShift = &TerminalShift{
+			ID:           shiftID.String,
+			OpenedBy:     openedBy.String,
+			OpenedByName: openedByName.String,
+			OpenedAt:     openedAt.Time,
+		}
+	}
+	return &terminal, nil
+}
+
+// ResolveTerminal mencari terminal aktif berdasarkan id, code atau device_id. Jika
+// ref kosong dan outlet hanya punya satu terminal aktif, terminal itu yang dipakai
+// sehingga outlet satu kasir tidak perlu mengirim header terminal.
+func ResolveTerminal(ctx context.Context, q db.DBTX, ref string) (*Terminal, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		rows, err := q.QueryContext(ctx, "SELECT "+terminalColumns+terminalFrom+" WHERE t.is_active = 1 LIMIT 2")
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		terminals := []*Terminal{}
+		for rows.Next() {
+			terminal, err := scanTerminal(rows)
+			if err != nil {
+				return nil, err
+			}
+			terminals = append(terminals, terminal)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		switch len(terminals) {
+		case 0:
+			return nil, ErrTerminalNotFound
+		case 1:
+			return terminals[0], nil
+		default:
+			return nil, ErrTerminalRequired
+		}
+	}
+
+	row := q.QueryRowContext(ctx, "SELECT "+terminalColumns+terminalFrom+`
+		WHERE t.is_active = 1 AND (t.id = ? OR t.code = ? OR t.device_id = ?)
+		LIMIT 1
+	`, ref, strings.ToUpper(ref), ref)
+	terminal, err := scanTerminal(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %s", ErrTerminalNotFound, ref)
+	}
+	return terminal, err
+}
+
+// GetOpenShiftID mengembalikan shift yang terbuka di terminal (sql.ErrNoRows jika belum dibuka)
+func GetOpenShiftID(ctx context.Context, q db.DBTX, terminalID string) (string, error) {
+	var shiftID string
+	err := q.QueryRowContext(ctx, `
+		SELECT id
+		FROM cashier_shifts
+		WHERE terminal_id = ? AND status = 'open'
+		LIMIT 1
+	`, terminalID).Scan(&shiftID)
+	return shiftID, err
+}
+
+// SettlementShiftID menentukan shift untuk pembayaran yang dikonfirmasi belakangan
+// (mis. QRIS dari webhook). Jika shift asal sudah ditutup, pembayaran masuk ke shift
+// yang sedang terbuka di terminal yang sama.
+func SettlementShiftID(ctx context.Context, q db.DBTX, shiftID string) string {
+	if shiftID == "" {
+		return ""
+	}
+	var current string
+	err := q.QueryRowContext(ctx, `
+		SELECT cur.id
+		FROM cashier_shifts origin
+		JOIN cashier_shifts cur ON cur.terminal_id = origin.terminal_id AND cur.status = 'open'
+		WHERE origin.id = ?
+		LIMIT 1
+	`, shiftID).Scan(&current)
+	if err != nil {
+		return shiftID
+	}
+	return current
+}
+
+func (r *terminalRepository) validate(ctx context.Context, terminal *Terminal) error {
+	terminal.Code = strings.ToUpper(strings.TrimSpace(terminal.Code))
+	terminal.Name = strings.TrimSpace(terminal.Name)
+	if !terminalCodePattern.MatchString(terminal.Code) {
+		return fmt.Errorf("%w: code harus 2-32 karakter huruf, angka, _ atau -", ErrInvalidTerminal)
+	}
+	if terminal.Name == "" {
+		return fmt.Errorf("%w: name wajib diisi", ErrInvalidTerminal)
+	}
+	if terminal.DeviceID != nil {
+		deviceID := strings.TrimSpace(*terminal.DeviceID)
+		terminal.DeviceID = nil
+		if deviceID != "" {
+			terminal.DeviceID = &deviceID
+		}
+	}
+	if terminal.PrinterID != nil && *terminal.PrinterID == "" {
+		terminal.PrinterID = nil
+	}
+	if terminal.PrinterID != nil {
+		var exists int64
+		if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM printers WHERE id = ?", *terminal.PrinterID).Scan(&exists); err != nil {
+			return err
+		}
+		if exists == 0 {
+			return fmt.Errorf("%w: printer tidak ditemukan", ErrInvalidTerminal)
+		}
+	}
+
+	var duplicate string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT code
+		FROM cashier_terminals
+		WHERE id != ? AND (code = ? OR (device_id IS NOT NULL AND device_id = ?))
+		LIMIT 1
+	`, terminal.ID, terminal.Code, terminal.DeviceID).Scan(&duplicate)
+	if err == nil {
+		return fmt.Errorf("%w: code atau device_id sudah dipakai terminal %s", ErrInvalidTerminal, duplicate)
+	}
+	if err != sql.ErrNoRows {
+		return err
+	}
+	return nil
+}
+
+func (r *terminalRepository) List(ctx context.Context, activeOnly bool) ([]Terminal, error) {
+	query := "SELECT " + terminalColumns + terminalFrom
+	if activeOnly {
+		query += " WHERE t.is_active = 1"
+	}
+	query += " ORDER BY t.code"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	terminals := []Terminal{}
+	for rows.Next() {
+		terminal, err := scanTerminal(rows)
+		if err != nil {
+			return nil, err
+		}
+		terminals = append(terminals, *terminal)
+	}
+	return terminals, rows.Err()
+}
+
+func (r *terminalRepository) GetByID(ctx context.Context, id string) (*Terminal, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+terminalColumns+terminalFrom+" WHERE t.id = ?", id)
+	terminal, err := scanTerminal(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrTerminalNotFound
+	}
+	return terminal, err
+}
+
+func (r *terminalRepository) Create(ctx context.Context, terminal *Terminal) error {
+	terminal.ID = utils.GenerateULID()
+	if err := r.validate(ctx, terminal); err != nil {
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO cashier_terminals (id, code, name, device_id, printer_id, is_active, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, terminal.ID, terminal.Code, terminal.Name, terminal.DeviceID, terminal.PrinterID, boolToInt(terminal.IsActive))
+	if err != nil {
+		return fmt.Errorf("gagal membuat terminal kasir: %w", err)
+	}
+	return nil
+}
+
+// Update menolak menonaktifkan terminal yang shift-nya masih terbuka agar laci
+// tidak tertinggal tanpa bisa ditutup.
+func (r *terminalRepository) Update(ctx context.Context, terminal *Terminal) error {
+	existing, err := r.GetByID(ctx, terminal.ID)
+	if err != nil {
+		return err
+	}
+	if err := r.validate(ctx, terminal); err != nil {
+		return err
+	}
+	if !terminal.IsActive && existing.OpenShift != nil {
+		return fmt.Errorf("%w: tutup shift terminal terlebih dahulu", ErrInvalidTerminal)
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE cashier_terminals
+		SET code = ?, name = ?, device_id = ?, printer_id = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, terminal.Code, terminal.Name, terminal.DeviceID, terminal.PrinterID, boolToInt(terminal.IsActive), terminal.ID)
+	if err != nil {
+		return fmt.Errorf("gagal mengubah terminal kasir: %w", err)
+	}
+	return nil
+}
+
+func (r *terminalRepository) Delete(ctx context.Context, id string) error {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return err
+	}
+
+	var used int64
+	if err := r.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM cashier_shifts WHERE terminal_id = ?", id).Scan(&used); err != nil {
+		return err
+	}
+	if used > 0 {
+		return ErrTerminalInUse
+	}
+
+	if _, err := r.db.ExecContext(ctx, "DELETE FROM cashier_terminals WHERE id = ?", id); err != nil {
+		return fmt.Errorf("gagal menghapus terminal kasir: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"backend/pkg/utils"
+)
+
+func testAdminID(t *testing.T, conn *sql.DB) string {
+	t.Helper()
+	var id string
+	if err := conn.QueryRow("SELECT id FROM users WHERE username = 'admin'").Scan(&id); err != nil {
+		t.Fatalf("baca user admin: %v", err)
+	}
+	return id
+}
+
+func defaultTestTerminalID(t *testing.T, conn *sql.DB) string {
+	t.Helper()
+	var id string
+	if err := conn.QueryRow("SELECT id FROM cashier_terminals WHERE code = 'KASIR-1'").Scan(&id); err != nil {
+		t.Fatalf("baca terminal default: %v", err)
+	}
+	return id
+}
+
+func insertTestTerminal(t *testing.T, conn *sql.DB, code, deviceID string, active bool) string {
+	t.Helper()
+	id := utils.GenerateULID()
+	var device interface{}
+	if deviceID != "" {
+		device = deviceID
+	}
+	mustExec(t, conn, `INSERT INTO cashier_terminals (id, code, name, device_id, is_active) VALUES (?, ?, ?, ?, ?)`,
+		id, code, code, device, boolToInt(active))
+	return id
+}
+
+func insertTestShift(t *testing.T, conn *sql.DB, terminalID, userID, status string) string {
+	t.Helper()
+	id := utils.GenerateULID()
+	mustExec(t, conn, `INSERT INTO cashier_shifts (id, opened_by, status, terminal_id) VALUES (?, ?, ?, ?)`,
+		id, userID, status, terminalID)
+	return id
+}
+
+func TestResolveTerminal(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, conn *sql.DB)
+		ref      string
+		wantCode string
+		wantErr  error
+	}{
+		{
+			name:     "tanpa header memakai satu-satunya terminal aktif",
+			wantCode: "KASIR-1",
+		},
+		{
+			name:     "code tidak peka huruf besar",
+			ref:      " kasir-1 ",
+			wantCode: "KASIR-1",
+		},
+		{
+			name: "berdasarkan device_id",
+			setup: func(t *testing.T, conn *sql.DB) {
+				insertTestTerminal(t, conn, "KASIR-2", "tablet-2", true)
+			},
+			ref:      "tablet-2",
+			wantCode: "KASIR-2",
+		},
+		{
+			name: "tanpa header dengan beberapa terminal aktif",
+			setup: func(t *testing.T, conn *sql.DB) {
+				insertTestTerminal(t, conn, "KASIR-2", "", true)
+			},
+			wantErr: ErrTerminalRequired,
+		},
+		{
+			name: "terminal nonaktif tidak dihitung",
+			setup: func(t *testing.T, conn *sql.DB) {
+				insertTestTerminal(t, conn, "KASIR-2", "", false)
+			},
+			wantCode: "KASIR-1",
+		},
+		{
+			name: "terminal nonaktif tidak bisa dipilih",
+			setup: func(t *testing.T, conn *sql.DB) {
+				insertTestTerminal(t, conn, "KASIR-2", "", false)
+			},
+			ref:     "KASIR-2",
+			wantErr: ErrTerminalNotFound,
+		},
+		{
+			name:    "terminal tidak dikenal",
+			ref:     "KASIR-9",
+			wantErr: ErrTerminalNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			if tt.setup != nil {
+				tt.setup(t, conn)
+			}
+
+			terminal, err := ResolveTerminal(ctx, conn, tt.ref)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveTerminal: %v", err)
+			}
+			if terminal.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", terminal.Code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestResolveTerminalOpenShift(t *testing.T) {
+	ctx := context.Background()
+	conn := newTestDB(t)
+	adminID := testAdminID(t, conn)
+	first := defaultTestTerminalID(t, conn)
+	second := insertTestTerminal(t, conn, "KASIR-2", "", true)
+
+	insertTestShift(t, conn, first, adminID, "closed")
+	firstShift := insertTestShift(t, conn, first, adminID, "open")
+	secondShift := insertTestShift(t, conn, second, adminID, "open")
+
+	for ref, want := range map[string]string{"KASIR-1": firstShift, "KASIR-2": secondShift} {
+		terminal, err := ResolveTerminal(ctx, conn, ref)
+		if err != nil {
+			t.Fatalf("ResolveTerminal(%s): %v", ref, err)
+		}
+		if terminal.OpenShift == nil || terminal.OpenShift.ID != want {
+			t.Errorf("shift terbuka %s = %+v, want %s", ref, terminal.OpenShift, want)
+		}
+		shiftID, err := GetOpenShiftID(ctx, conn, terminal.ID)
+		if err != nil || shiftID != want {
+			t.Errorf("GetOpenShiftID(%s) = %q, %v, want %q", ref, shiftID, err, want)
+		}
+	}
+
+	third := insertTestTerminal(t, conn, "KASIR-3", "", true)
+	if _, err := GetOpenShiftID(ctx, conn, third); err != sql.ErrNoRows {
+		t.Errorf("GetOpenShiftID terminal tanpa shift: err = %v, want sql.ErrNoRows", err)
+	}
+}
+
+func TestSettlementShiftID(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// setup mengembalikan shift asal pembayaran dan shift yang diharapkan
+		setup func(t *testing.T, conn *sql.DB, adminID string) (string, string)
+	}{
+		{
+			name: "tanpa shift asal",
+			setup: func(t *testing.T, conn *sql.DB, adminID string) (string, string) {
+				return "", ""
+			},
+		},
+		{
+			name: "shift asal masih terbuka",
+			setup: func(t *testing.T, conn *sql.DB, adminID string) (string, string) {
+				origin := insertTestShift(t, conn, defaultTestTerminalID(t, conn), adminID, "open")
+				return origin, origin
+			},
+		},
+		{
+			name: "shift asal ditutup, pindah ke shift terbuka di terminal yang sama",
+			setup: func(t *testing.T, conn *sql.DB, adminID string) (string, string) {
+				terminal := defaultTestTerminalID(t, conn)
+				origin := insertTestShift(t, conn, terminal, adminID, "closed")
+				current := insertTestShift(t, conn, terminal, adminID, "open")
+				return origin, current
+			},
+		},
+		{
+			name: "shift terbuka di terminal lain tidak dipakai",
+			setup: func(t *testing.T, conn *sql.DB, adminID string) (string, string) {
+				origin := insertTestShift(t, conn, defaultTestTerminalID(t, conn), adminID, "closed")
+				other := insertTestTerminal(t, conn, "KASIR-2", "", true)
+				insertTestShift(t, conn, other, adminID, "open")
+				return origin, origin
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			origin, want := tt.setup(t, conn, testAdminID(t, conn))
+			if got := SettlementShiftID(ctx, conn, origin); got != want {
+				t.Errorf("SettlementShiftID(%q) = %q, want %q", origin, got, want)
+			}
+		})
+	}
+}
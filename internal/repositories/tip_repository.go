@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// PaymentTip adalah tip yang dibayarkan bersama satu pembayaran. Tip tidak masuk
+// transaksi/omzet; penerimanya waiter pembuat order atau tip pool.
+type PaymentTip struct {
+	ID            string    `json:"id"`
+	OrderID       string    `json:"order_id"`
+	TransactionID string    `json:"transaction_id"`
+	ShiftID       *string   `json:"shift_id"`
+	PaymentMethod string    `json:"payment_method"`
+	Amount        float64   `json:"amount"`
+	RecipientType string    `json:"recipient_type"`
+	StaffID       *string   `json:"staff_id"`
+	StaffName     string    `json:"staff_name,omitempty"`
+	CreatedBy     string    `json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TipStaffSummary adalah rekap tip per penerima untuk payout. DrawerAmount adalah
+// tip yang diterima lewat metode yang masuk laci kas (tunai).
+type TipStaffSummary struct {
+	RecipientType string  `json:"recipient_type"`
+	StaffID       *string `json:"staff_id"`
+	StaffName     string  `json:"staff_name"`
+	TipsCount     int64   `json:"tips_count"`
+	Amount        float64 `json:"amount"`
+	DrawerAmount  float64 `json:"drawer_amount"`
+}
+
+// TipShiftSummary adalah rekap tip per shift kasir
+type TipShiftSummary struct {
+	ShiftID      string     `json:"shift_id"`
+	CashierName  string     `json:"cashier_name"`
+	OpenedAt     time.Time  `json:"opened_at"`
+	ClosedAt     *time.Time `json:"closed_at"`
+	TipsCount    int64      `json:"tips_count"`
+	Amount       float64    `json:"amount"`
+	DrawerAmount float64    `json:"drawer_amount"`
+}
+
+const (
+	TipRecipientWaiter = "waiter"
+	TipRecipientPool   = "pool"
+)
+
+var ErrInvalidTip = errors.New("data tip tidak valid")
+
+type TipRepository interface {
+	ListByOrder(ctx context.Context, orderID string) ([]PaymentTip, error)
+	GetStaffReport(ctx context.Context, startDate, endDate time.Time, shiftID string) ([]TipStaffSummary, error)
+	GetShiftReport(ctx context.Context, startDate, endDate time.Time) ([]TipShiftSummary, error)
+}
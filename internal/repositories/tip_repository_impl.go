@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"backend/internal/db"
+	"backend/pkg/utils"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type tipRepository struct {
+	db *sql.DB
+}
+
+func NewTipRepository(dbConn *sql.DB) TipRepository {
+	return &tipRepository{db: dbConn}
+}
+
+// CreatePaymentTip menyimpan tip untuk transaksi yang baru dicatat. Dipanggil dari
+// alur pembayaran order sehingga menerima db.DBTX.
+func CreatePaymentTip(ctx context.Context, q db.DBTX, tip *PaymentTip) error {
+	if tip.Amount <= 0 {
+		return fmt.Errorf("%w: tip harus lebih dari 0", ErrInvalidTip)
+	}
+	switch tip.RecipientType {
+	case TipRecipientWaiter:
+		if tip.StaffID == nil || *tip.StaffID == "" {
+			return fmt.Errorf("%w: order tidak memiliki waiter, gunakan tip pool", ErrInvalidTip)
+		}
+	case TipRecipientPool:
+		tip.StaffID = nil
+	default:
+		return fmt.Errorf("%w: tip_recipient harus waiter atau pool", ErrInvalidTip)
+	}
+
+	tip.ID = utils.GenerateULID()
+	tip.CreatedAt = time.Now().UTC()
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO payment_tips (
+			id, order_id, transaction_id, shift_id, payment_method, amount,
+			recipient_type, staff_id, created_by, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, tip.ID, tip.OrderID, tip.TransactionID, tip.ShiftID, tip.PaymentMethod, tip.Amount,
+		tip.RecipientType, tip.StaffID, tip.CreatedBy, tip.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("gagal menyimpan tip: %w", err)
+	}
+	return nil
+}
+
+func (r *tipRepository) ListByOrder(ctx context.Context, orderID string) ([]PaymentTip, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT pt.id, pt.order_id, pt.transaction_id, pt.shift_id, pt.payment_method, pt.amount,
+		       pt.recipient_type, pt.staff_id, COALESCE(u.full_name, ''), COALESCE(pt.created_by, ''), pt.created_at
+		FROM payment_tips pt
+		JOIN transactions t ON t.id = pt.transaction_id AND t.cancelled_at IS NULL
+		LEFT JOIN users u ON u.id = pt.staff_id
+		WHERE pt.order_id = ?
+		ORDER BY pt.created_at
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tips := []PaymentTip{}
+	for rows.Next() {
+		var tip PaymentTip
+		var shiftID, staffID sql.NullString
+		if err := rows.Scan(&tip.ID, &tip.OrderID, &tip.TransactionID, &shiftID, &tip.PaymentMethod, &tip.Amount,
+			&tip.RecipientType, &staffID, &tip.StaffName, &tip.CreatedBy, &tip.CreatedAt); err != nil {
+			return nil, err
+		}
+		if shiftID.Valid {
+			tip.ShiftID = &shiftID.String
+		}
+		if staffID.Valid {
+			tip.StaffID = &staffID.String
+		}
+		tips = append(tips, tip)
+	}
+	return tips, rows.Err()
+}
+
+// GetStaffReport merekap tip per waiter dan tip pool. Jika shiftID diisi,
+// rentang tanggal diabaikan dan hanya tip pada shift tersebut yang dihitung.
+func (r *tipRepository) GetStaffReport(ctx context.Context, startDate, endDate time.Time, shiftID string) ([]TipStaffSummary, error) {
+	filter := "pt.created_at BETWEEN ? AND ?"
+	args := []interface{}{startDate, endDate}
+	if shiftID != "" {
+		filter = "pt.shift_id = ?"
+		args = []interface{}{shiftID}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			pt.recipient_type,
+			pt.staff_id,
+			COALESCE(u.full_name, ''),
+			COUNT(*),
+			COALESCE(SUM(pt.amount), 0),
+			COALESCE(SUM(CASE WHEN COALESCE(pm.counts_to_drawer, pt.payment_method = 'cash') = 1 THEN pt.amount ELSE 0 END), 0)
+		FROM payment_tips pt
+		JOIN transactions t ON t.id = pt.transaction_id AND t.cancelled_at IS NULL
+		LEFT JOIN payment_methods pm ON pm.code = pt.payment_method
+		LEFT JOIN users u ON u.id = pt.staff_id
+		WHERE `+filter+`
+		GROUP BY pt.recipient_type, pt.staff_id
+		ORDER BY pt.recipient_type DESC, u.full_name
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []TipStaffSummary{}
+	for rows.Next() {
+		var line TipStaffSummary
+		var staffID sql.NullString
+		if err := rows.Scan(&line.RecipientType, &staffID, &line.StaffName, &line.TipsCount, &line.Amount, &line.DrawerAmount); err != nil {
+			return nil, err
+		}
+		if staffID.Valid {
+			line.StaffID = &staffID.String
+		}
+		if line.RecipientType == TipRecipientPool {
+			line.StaffName = "Tip Pool"
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
+
+func (r *tipRepository) GetShiftReport(ctx context.Context, startDate, endDate time.Time) ([]TipShiftSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			cs.id,
+			COALESCE(u.full_name, ''),
+			cs.opened_at,
+			cs.closed_at,
+			COUNT(*),
+			COALESCE(SUM(pt.amount), 0),
+			COALESCE(SUM(CASE WHEN COALESCE(pm.counts_to_drawer, pt.payment_method = 'cash') = 1 THEN pt.amount ELSE 0 END), 0)
+		FROM payment_tips pt
+		JOIN transactions t ON t.id = pt.transaction_id AND t.cancelled_at IS NULL
+		JOIN cashier_shifts cs ON cs.id = pt.shift_id
+		LEFT JOIN payment_methods pm ON pm.code = pt.payment_method
+		LEFT JOIN users u ON u.id = cs.opened_by
+		WHERE pt.created_at BETWEEN ? AND ?
+		GROUP BY cs.id
+		ORDER BY cs.opened_at
+	`, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []TipShiftSummary{}
+	for rows.Next() {
+		var line TipShiftSummary
+		var closedAt sql.NullTime
+		if err := rows.Scan(&line.ShiftID, &line.CashierName, &line.OpenedAt, &closedAt, &line.TipsCount, &line.Amount, &line.DrawerAmount); err != nil {
+			return nil, err
+		}
+		if closedAt.Valid {
+			line.ClosedAt = &closedAt.Time
+		}
+		lines = append(lines, line)
+	}
+	return lines, rows.Err()
+}
@@ -15,7 +15,6 @@ var (
 type TransactionRepository interface {
 	Create(ctx context.Context, orderID string, totalAmount float64, paymentMethod, status string, transactionDate time.Time, createdBy string) (*db.Transaction, error)
 	CreateWithID(ctx context.Context, id, orderID string, totalAmount float64, paymentMethod, status string, transactionDate time.Time, createdBy string) (*db.Transaction, error)
-	CreateItem(ctx context.Context, transactionID, productID string, quantity int64, price float64) (*db.TransactionItem, error)
 	FindByID(ctx context.Context, id string) (*db.Transaction, error)
 	FindAll(ctx context.Context) ([]db.Transaction, error)
 	FindPaginated(ctx context.Context, limit, offset int64) ([]db.Transaction, error)
@@ -24,5 +23,14 @@ type TransactionRepository interface {
 	FindByDateRangePaginated(ctx context.Context, startDate, endDate time.Time, limit, offset int64) ([]db.Transaction, error)
 	CountByDateRange(ctx context.Context, startDate, endDate time.Time) (int64, error)
 	FindItems(ctx context.Context, transactionID string) ([]db.ListTransactionItemsRow, error)
-	Cancel(ctx context.Context, transactionID, managerID, reason string) error
+	Cancel(ctx context.Context, transactionID, managerID, reason, shiftID string) error
+	SetReferenceNumber(ctx context.Context, transactionID, referenceNumber string) error
+	CreateForShift(ctx context.Context, orderID string, totalAmount float64, paymentMethod, status string, transactionDate time.Time, createdBy, shiftID string, items []TransactionItemInput) (*db.Transaction, error)
+}
+
+// TransactionItemInput adalah item produk yang dicatat bersama transaksi
+type TransactionItemInput struct {
+	ProductID string
+	Quantity  int64
+	Price     float64
 }
@@ -10,11 +10,12 @@ import (
 
 type transactionRepository struct {
 	queries *db.Queries
+	db      *sql.DB
 }
 
 // NewTransactionRepository membuat instance baru dari TransactionRepository
 func NewTransactionRepository(dbConn *sql.DB) TransactionRepository {
-	return &transactionRepository{queries: db.New(dbConn)}
+	return &transactionRepository{queries: db.New(dbConn), db: dbConn}
 }
 
 func (r *transactionRepository) Create(ctx context.Context, orderID string, totalAmount float64, paymentMethod, status string, transactionDate time.Time, createdBy string) (*db.Transaction, error) {
@@ -22,10 +23,7 @@ func (r *transactionRepository) Create(ctx context.Context, orderID string, tota
 }
 
 func (r *transactionRepository) CreateWithID(ctx context.Context, id, orderID string, totalAmount float64, paymentMethod, status string, transactionDate time.Time, createdBy string) (*db.Transaction, error) {
-	if orderID == "" {
-		orderID = id
-	}
-	transaction, err := r.queries.CreateTransaction(ctx, db.CreateTransactionParams{
+	transaction, err := insertTransaction(ctx, r.queries, db.CreateTransactionParams{
 		ID:              id,
 		OrderID:         orderID,
 		TotalAmount:     totalAmount,
@@ -40,18 +38,65 @@ func (r *transactionRepository) CreateWithID(ctx context.Context, id, orderID st
 	return &transaction, nil
 }
 
-func (r *transactionRepository) CreateItem(ctx context.Context, transactionID, productID string, quantity int64, price float64) (*db.TransactionItem, error) {
-	item, err := r.queries.CreateTransactionItem(ctx, db.CreateTransactionItemParams{
-		ID:            utils.GenerateULID(),
-		TransactionID: transactionID,
-		ProductID:     productID,
-		Quantity:      quantity,
-		Price:         price,
+// insertTransaction adalah satu-satunya jalur insert transaksi. Transaksi tanpa order
+// (penjualan langsung) memakai id transaksi sebagai order_id.
+func insertTransaction(ctx context.Context, q *db.Queries, arg db.CreateTransactionParams) (db.Transaction, error) {
+	if arg.ID == "" {
+		arg.ID = utils.GenerateULID()
+	}
+	if arg.OrderID == "" {
+		arg.OrderID = arg.ID
+	}
+	return q.CreateTransaction(ctx, arg)
+}
+
+// CreateForShift mencatat transaksi, shift (laci) terminal yang menerimanya, dan item
+// produknya dalam satu transaksi database.
+func (r *transactionRepository) CreateForShift(ctx context.Context, orderID string, totalAmount float64, paymentMethod, status string, transactionDate time.Time, createdBy, shiftID string, items []TransactionItemInput) (*db.Transaction, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	q := r.queries.WithTx(tx)
+	transaction, err := insertTransaction(ctx, q, db.CreateTransactionParams{
+		OrderID:         orderID,
+		TotalAmount:     totalAmount,
+		PaymentMethod:   paymentMethod,
+		Status:          status,
+		TransactionDate: transactionDate,
+		CreatedBy:       createdBy,
+		ShiftID:         sql.NullString{String: shiftID, Valid: shiftID != ""},
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &item, nil
+	for _, item := range items {
+		if _, err := q.CreateTransactionItem(ctx, db.CreateTransactionItemParams{
+			ID:            utils.GenerateULID(),
+			TransactionID: transaction.ID,
+			ProductID:     item.ProductID,
+			Quantity:      item.Quantity,
+			Price:         item.Price,
+		}); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// SetReferenceNumber menyimpan nomor referensi (approval code EDC, ID e-wallet, dll.)
+func (r *transactionRepository) SetReferenceNumber(ctx context.Context, transactionID, referenceNumber string) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE transactions
+		SET reference_number = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, referenceNumber, transactionID)
+	return err
 }
 
 func (r *transactionRepository) FindByID(ctx context.Context, id string) (*db.Transaction, error) {
@@ -119,7 +164,9 @@ func (r *transactionRepository) FindItems(ctx context.Context, transactionID str
 	return r.queries.ListTransactionItems(ctx, transactionID)
 }
 
-func (r *transactionRepository) Cancel(ctx context.Context, transactionID, managerID, reason string) error {
+// Cancel membatalkan transaksi; shiftID adalah shift terminal tempat pembatalan
+// dilakukan (kosong jika dibatalkan di luar shift)
+func (r *transactionRepository) Cancel(ctx context.Context, transactionID, managerID, reason, shiftID string) error {
 	transaction, err := r.queries.GetTransaction(ctx, transactionID)
 	if err != nil {
 		return err
@@ -136,5 +183,9 @@ func (r *transactionRepository) Cancel(ctx context.Context, transactionID, manag
 		UpdatedAt:    time.Now(),
 		ID:           transactionID,
 	})
+	if err != nil || shiftID == "" {
+		return err
+	}
+	_, err = r.db.ExecContext(ctx, "UPDATE transactions SET cancelled_shift_id = ? WHERE id = ?", shiftID, transactionID)
 	return err
 }
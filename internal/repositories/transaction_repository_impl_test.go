@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestCreateForShift(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		withShift bool
+		orderID   string
+		itemQty   []int64
+	}{
+		{name: "transaksi tercatat di shift terminal", withShift: true, orderID: "order-1"},
+		{name: "tanpa shift", orderID: "order-2"},
+		{name: "transaksi tanpa order memakai id transaksi beserta item", withShift: true, itemQty: []int64{2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := newTestDB(t)
+			adminID := testAdminID(t, conn)
+			shiftID := ""
+			if tt.withShift {
+				shiftID = insertTestShift(t, conn, defaultTestTerminalID(t, conn), adminID, "open")
+			}
+
+			items := []TransactionItemInput{}
+			for _, qty := range tt.itemQty {
+				items = append(items, TransactionItemInput{
+					ProductID: insertTestProduct(t, conn, "Sup", 20000, ""),
+					Quantity:  qty,
+					Price:     20000,
+				})
+			}
+
+			repo := NewTransactionRepository(conn)
+			transaction, err := repo.CreateForShift(ctx, tt.orderID, 50000, "cash", "completed", time.Now(), adminID, shiftID, items)
+			if err != nil {
+				t.Fatalf("CreateForShift: %v", err)
+			}
+
+			wantOrderID := tt.orderID
+			if wantOrderID == "" {
+				wantOrderID = transaction.ID
+			}
+			if transaction.OrderID != wantOrderID {
+				t.Errorf("order_id = %q, want %q", transaction.OrderID, wantOrderID)
+			}
+
+			var stored sql.NullString
+			if err := conn.QueryRow("SELECT shift_id FROM transactions WHERE id = ?", transaction.ID).Scan(&stored); err != nil {
+				t.Fatalf("baca transaksi: %v", err)
+			}
+			if stored.String != shiftID || stored.Valid != tt.withShift {
+				t.Errorf("shift_id = %+v, want %q", stored, shiftID)
+			}
+			if transaction.ShiftID != stored {
+				t.Errorf("shift_id hasil insert = %+v, want %+v", transaction.ShiftID, stored)
+			}
+
+			var itemCount int
+			if err := conn.QueryRow("SELECT COUNT(*) FROM transaction_items WHERE transaction_id = ?", transaction.ID).Scan(&itemCount); err != nil {
+				t.Fatalf("baca item transaksi: %v", err)
+			}
+			if itemCount != len(tt.itemQty) {
+				t.Errorf("item transaksi = %d, want %d", itemCount, len(tt.itemQty))
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type BusinessDayService interface {
+	GetXReport(ctx context.Context) (*repositories.SalesReport, error)
+	CloseDay(ctx context.Context, businessDate string, closedBy string) (*repositories.ZReport, error)
+	ListZReports(ctx context.Context, limit, offset int64) ([]repositories.ZReport, int64, error)
+	GetZReport(ctx context.Context, id string) (*repositories.ZReport, error)
+}
+
+type businessDayService struct {
+	businessDayRepo repositories.BusinessDayRepository
+}
+
+func NewBusinessDayService(businessDayRepo repositories.BusinessDayRepository) BusinessDayService {
+	return &businessDayService{
+		businessDayRepo: businessDayRepo,
+	}
+}
+
+func (s *businessDayService) GetXReport(ctx context.Context) (*repositories.SalesReport, error) {
+	return s.businessDayRepo.GetCurrentReport(ctx)
+}
+
+func (s *businessDayService) CloseDay(ctx context.Context, businessDate string, closedBy string) (*repositories.ZReport, error) {
+	return s.businessDayRepo.CloseDay(ctx, businessDate, closedBy)
+}
+
+func (s *businessDayService) ListZReports(ctx context.Context, limit, offset int64) ([]repositories.ZReport, int64, error) {
+	reports, err := s.businessDayRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	total, err := s.businessDayRepo.Count(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return reports, total, nil
+}
+
+func (s *businessDayService) GetZReport(ctx context.Context, id string) (*repositories.ZReport, error) {
+	return s.businessDayRepo.GetByID(ctx, id)
+}
@@ -0,0 +1,34 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type CashDrawerService interface {
+	GetSettings(ctx context.Context, printerID string) (*repositories.DrawerSettings, error)
+	UpdateSettings(ctx context.Context, settings *repositories.DrawerSettings) error
+	ListEvents(ctx context.Context, filter repositories.CashDrawerEventFilter) ([]repositories.CashDrawerEvent, error)
+}
+
+type cashDrawerService struct {
+	cashDrawerRepo repositories.CashDrawerRepository
+}
+
+func NewCashDrawerService(cashDrawerRepo repositories.CashDrawerRepository) CashDrawerService {
+	return &cashDrawerService{
+		cashDrawerRepo: cashDrawerRepo,
+	}
+}
+
+func (s *cashDrawerService) GetSettings(ctx context.Context, printerID string) (*repositories.DrawerSettings, error) {
+	return s.cashDrawerRepo.GetSettings(ctx, printerID)
+}
+
+func (s *cashDrawerService) UpdateSettings(ctx context.Context, settings *repositories.DrawerSettings) error {
+	return s.cashDrawerRepo.UpdateSettings(ctx, settings)
+}
+
+func (s *cashDrawerService) ListEvents(ctx context.Context, filter repositories.CashDrawerEventFilter) ([]repositories.CashDrawerEvent, error) {
+	return s.cashDrawerRepo.ListEvents(ctx, filter)
+}
@@ -4,7 +4,19 @@ import (
 	"backend/internal/db"
 	"backend/internal/repositories"
 	"context"
+	"fmt"
+	"net/mail"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+const (
+	customerNoteMaxLength = 500
+	customerTagMaxLength  = 30
+	customerMaxTags       = 20
+	// customerLastItemsLimit adalah jumlah menu terakhir yang ditampilkan di profil
+	customerLastItemsLimit = 10
 )
 
 type CustomerService interface {
@@ -12,6 +24,12 @@ type CustomerService interface {
 	GetCustomerByID(ctx context.Context, id string) (*db.Customer, error)
 	GetCustomerByPhone(ctx context.Context, phone string) (*db.Customer, error)
 	GetTopCustomers(ctx context.Context, startDate, endDate time.Time, limit int64) ([]db.GetTopCustomersRow, error)
+	CreateProfile(ctx context.Context, input repositories.CustomerInput) (*repositories.CustomerProfile, error)
+	GetProfile(ctx context.Context, id string) (*repositories.CustomerProfile, error)
+	ListProfiles(ctx context.Context, filter repositories.CustomerFilter) ([]repositories.CustomerProfile, int64, error)
+	UpdateProfile(ctx context.Context, id string, input repositories.CustomerInput) (*repositories.CustomerProfile, error)
+	DeleteCustomer(ctx context.Context, id string) error
+	MergeCustomers(ctx context.Context, targetID string, duplicateIDs []string) (*repositories.CustomerMergeResult, error)
 }
 
 type customerService struct {
@@ -39,3 +57,111 @@ func (s *customerService) GetCustomerByPhone(ctx context.Context, phone string)
 func (s *customerService) GetTopCustomers(ctx context.Context, startDate, endDate time.Time, limit int64) ([]db.GetTopCustomersRow, error) {
 	return s.customerRepo.GetTopCustomers(ctx, startDate, endDate, limit)
 }
+
+// normalizeCustomerInput merapikan dan memvalidasi profil sebelum disimpan
+func normalizeCustomerInput(input repositories.CustomerInput) (repositories.CustomerInput, error) {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Phone = strings.TrimSpace(input.Phone)
+	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
+	input.Birthday = strings.TrimSpace(input.Birthday)
+	input.Allergies = strings.TrimSpace(input.Allergies)
+	input.DietaryNotes = strings.TrimSpace(input.DietaryNotes)
+
+	if input.Name == "" {
+		return input, fmt.Errorf("%w: nama wajib diisi", repositories.ErrInvalidCustomer)
+	}
+	if input.Phone == "" {
+		return input, fmt.Errorf("%w: nomor HP wajib diisi", repositories.ErrInvalidCustomer)
+	}
+	if input.Email != "" {
+		if address, err := mail.ParseAddress(input.Email); err != nil || address.Address != input.Email {
+			return input, fmt.Errorf("%w: format email tidak valid", repositories.ErrInvalidCustomer)
+		}
+	}
+	if input.Birthday != "" {
+		birthday, err := time.Parse("2006-01-02", input.Birthday)
+		if err != nil {
+			return input, fmt.Errorf("%w: format birthday harus YYYY-MM-DD", repositories.ErrInvalidCustomer)
+		}
+		if birthday.After(time.Now()) {
+			return input, fmt.Errorf("%w: birthday tidak boleh di masa depan", repositories.ErrInvalidCustomer)
+		}
+	}
+	if utf8.RuneCountInString(input.Allergies) > customerNoteMaxLength || utf8.RuneCountInString(input.DietaryNotes) > customerNoteMaxLength {
+		return input, fmt.Errorf("%w: catatan alergi/diet maksimal %d karakter", repositories.ErrInvalidCustomer, customerNoteMaxLength)
+	}
+
+	input.Tags = repositories.NormalizeCustomerTags(input.Tags)
+	if len(input.Tags) > customerMaxTags {
+		return input, fmt.Errorf("%w: maksimal %d tag", repositories.ErrInvalidCustomer, customerMaxTags)
+	}
+	for _, tag := range input.Tags {
+		if utf8.RuneCountInString(tag) > customerTagMaxLength {
+			return input, fmt.Errorf("%w: tag maksimal %d karakter", repositories.ErrInvalidCustomer, customerTagMaxLength)
+		}
+	}
+	return input, nil
+}
+
+func (s *customerService) CreateProfile(ctx context.Context, input repositories.CustomerInput) (*repositories.CustomerProfile, error) {
+	input, err := normalizeCustomerInput(input)
+	if err != nil {
+		return nil, err
+	}
+	return s.customerRepo.CreateProfile(ctx, input)
+}
+
+// GetProfile mengembalikan profil beserta menu yang terakhir dipesan
+func (s *customerService) GetProfile(ctx context.Context, id string) (*repositories.CustomerProfile, error) {
+	customer, err := s.customerRepo.GetProfile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	customer.LastItems, err = s.customerRepo.GetLastItems(ctx, id, customerLastItemsLimit)
+	if err != nil {
+		return nil, err
+	}
+	return customer, nil
+}
+
+func (s *customerService) ListProfiles(ctx context.Context, filter repositories.CustomerFilter) ([]repositories.CustomerProfile, int64, error) {
+	switch filter.Sort {
+	case "", "name", "recent", "visits", "spent":
+	default:
+		return nil, 0, fmt.Errorf("%w: sort harus name, recent, visits atau spent", repositories.ErrInvalidCustomer)
+	}
+	if filter.BirthdayMonth < 0 || filter.BirthdayMonth > 12 {
+		return nil, 0, fmt.Errorf("%w: birthday_month harus 1-12", repositories.ErrInvalidCustomer)
+	}
+	filter.Tag = strings.ToLower(strings.TrimSpace(filter.Tag))
+	return s.customerRepo.ListProfiles(ctx, filter)
+}
+
+func (s *customerService) UpdateProfile(ctx context.Context, id string, input repositories.CustomerInput) (*repositories.CustomerProfile, error) {
+	input, err := normalizeCustomerInput(input)
+	if err != nil {
+		return nil, err
+	}
+	return s.customerRepo.UpdateProfile(ctx, id, input)
+}
+
+func (s *customerService) DeleteCustomer(ctx context.Context, id string) error {
+	return s.customerRepo.Delete(ctx, id)
+}
+
+func (s *customerService) MergeCustomers(ctx context.Context, targetID string, duplicateIDs []string) (*repositories.CustomerMergeResult, error) {
+	ids := []string{}
+	seen := make(map[string]bool, len(duplicateIDs))
+	for _, id := range duplicateIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("%w: duplicate_ids wajib diisi", repositories.ErrInvalidCustomer)
+	}
+	return s.customerRepo.Merge(ctx, targetID, ids)
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type DepositService interface {
+	CreateDeposit(ctx context.Context, deposit *repositories.Deposit) error
+	GetDeposit(ctx context.Context, id string) (*repositories.Deposit, error)
+	ListDeposits(ctx context.Context, filter repositories.DepositFilter) ([]repositories.Deposit, error)
+	AttachDeposit(ctx context.Context, id, orderID string) error
+	RefundDeposit(ctx context.Context, input repositories.DepositRefundInput) error
+}
+
+type depositService struct {
+	depositRepo repositories.DepositRepository
+}
+
+func NewDepositService(depositRepo repositories.DepositRepository) DepositService {
+	return &depositService{
+		depositRepo: depositRepo,
+	}
+}
+
+func (s *depositService) CreateDeposit(ctx context.Context, deposit *repositories.Deposit) error {
+	return s.depositRepo.Create(ctx, deposit)
+}
+
+func (s *depositService) GetDeposit(ctx context.Context, id string) (*repositories.Deposit, error) {
+	return s.depositRepo.GetByID(ctx, id)
+}
+
+func (s *depositService) ListDeposits(ctx context.Context, filter repositories.DepositFilter) ([]repositories.Deposit, error) {
+	return s.depositRepo.List(ctx, filter)
+}
+
+func (s *depositService) AttachDeposit(ctx context.Context, id, orderID string) error {
+	return s.depositRepo.Attach(ctx, id, orderID)
+}
+
+func (s *depositService) RefundDeposit(ctx context.Context, input repositories.DepositRefundInput) error {
+	return s.depositRepo.Refund(ctx, input)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type FloorPlanService interface {
+	GetFloorPlan(ctx context.Context) (*repositories.FloorPlan, error)
+	SaveLayout(ctx context.Context, layouts []repositories.TableLayoutInput) (*repositories.FloorPlan, error)
+
+	ListAreas(ctx context.Context) ([]repositories.FloorArea, error)
+	GetArea(ctx context.Context, id string) (*repositories.FloorArea, error)
+	CreateArea(ctx context.Context, input repositories.FloorAreaInput) (*repositories.FloorArea, error)
+	UpdateArea(ctx context.Context, id string, input repositories.FloorAreaInput) (*repositories.FloorArea, error)
+	DeleteArea(ctx context.Context, id string) error
+	SetAreaChargeRules(ctx context.Context, id string, rules []repositories.AreaChargeRule) (*repositories.FloorArea, error)
+
+	ListSections(ctx context.Context) ([]repositories.FloorSection, error)
+	GetSection(ctx context.Context, id string) (*repositories.FloorSection, error)
+	CreateSection(ctx context.Context, input repositories.FloorSectionInput) (*repositories.FloorSection, error)
+	UpdateSection(ctx context.Context, id string, input repositories.FloorSectionInput) (*repositories.FloorSection, error)
+	DeleteSection(ctx context.Context, id string) error
+	SetSectionWaiters(ctx context.Context, id string, userIDs []string) (*repositories.FloorSection, error)
+	ListWaiterTables(ctx context.Context, userID string) ([]repositories.FloorTable, error)
+
+	ListCombinations(ctx context.Context) ([]repositories.TableCombination, error)
+	CreateCombination(ctx context.Context, input repositories.TableCombinationInput) (*repositories.TableCombination, error)
+	UpdateCombination(ctx context.Context, id string, input repositories.TableCombinationInput) (*repositories.TableCombination, error)
+	DeleteCombination(ctx context.Context, id string) error
+}
+
+type floorPlanService struct {
+	floorPlanRepo repositories.FloorPlanRepository
+}
+
+func NewFloorPlanService(floorPlanRepo repositories.FloorPlanRepository) FloorPlanService {
+	return &floorPlanService{floorPlanRepo: floorPlanRepo}
+}
+
+func (s *floorPlanService) GetFloorPlan(ctx context.Context) (*repositories.FloorPlan, error) {
+	return s.floorPlanRepo.GetFloorPlan(ctx)
+}
+
+func (s *floorPlanService) SaveLayout(ctx context.Context, layouts []repositories.TableLayoutInput) (*repositories.FloorPlan, error) {
+	if err := s.floorPlanRepo.SaveLayout(ctx, layouts); err != nil {
+		return nil, err
+	}
+	return s.floorPlanRepo.GetFloorPlan(ctx)
+}
+
+func (s *floorPlanService) ListAreas(ctx context.Context) ([]repositories.FloorArea, error) {
+	return s.floorPlanRepo.ListAreas(ctx)
+}
+
+func (s *floorPlanService) GetArea(ctx context.Context, id string) (*repositories.FloorArea, error) {
+	return s.floorPlanRepo.GetArea(ctx, id)
+}
+
+func (s *floorPlanService) CreateArea(ctx context.Context, input repositories.FloorAreaInput) (*repositories.FloorArea, error) {
+	return s.floorPlanRepo.CreateArea(ctx, input)
+}
+
+func (s *floorPlanService) UpdateArea(ctx context.Context, id string, input repositories.FloorAreaInput) (*repositories.FloorArea, error) {
+	return s.floorPlanRepo.UpdateArea(ctx, id, input)
+}
+
+func (s *floorPlanService) DeleteArea(ctx context.Context, id string) error {
+	return s.floorPlanRepo.DeleteArea(ctx, id)
+}
+
+func (s *floorPlanService) SetAreaChargeRules(ctx context.Context, id string, rules []repositories.AreaChargeRule) (*repositories.FloorArea, error) {
+	return s.floorPlanRepo.SetAreaChargeRules(ctx, id, rules)
+}
+
+func (s *floorPlanService) ListSections(ctx context.Context) ([]repositories.FloorSection, error) {
+	return s.floorPlanRepo.ListSections(ctx)
+}
+
+func (s *floorPlanService) GetSection(ctx context.Context, id string) (*repositories.FloorSection, error) {
+	return s.floorPlanRepo.GetSection(ctx, id)
+}
+
+func (s *floorPlanService) CreateSection(ctx context.Context, input repositories.FloorSectionInput) (*repositories.FloorSection, error) {
+	return s.floorPlanRepo.CreateSection(ctx, input)
+}
+
+func (s *floorPlanService) UpdateSection(ctx context.Context, id string, input repositories.FloorSectionInput) (*repositories.FloorSection, error) {
+	return s.floorPlanRepo.UpdateSection(ctx, id, input)
+}
+
+func (s *floorPlanService) DeleteSection(ctx context.Context, id string) error {
+	return s.floorPlanRepo.DeleteSection(ctx, id)
+}
+
+func (s *floorPlanService) SetSectionWaiters(ctx context.Context, id string, userIDs []string) (*repositories.FloorSection, error) {
+	return s.floorPlanRepo.SetSectionWaiters(ctx, id, userIDs)
+}
+
+func (s *floorPlanService) ListWaiterTables(ctx context.Context, userID string) ([]repositories.FloorTable, error) {
+	return s.floorPlanRepo.ListWaiterTables(ctx, userID)
+}
+
+func (s *floorPlanService) ListCombinations(ctx context.Context) ([]repositories.TableCombination, error) {
+	return s.floorPlanRepo.ListCombinations(ctx)
+}
+
+func (s *floorPlanService) CreateCombination(ctx context.Context, input repositories.TableCombinationInput) (*repositories.TableCombination, error) {
+	return s.floorPlanRepo.CreateCombination(ctx, input)
+}
+
+func (s *floorPlanService) UpdateCombination(ctx context.Context, id string, input repositories.TableCombinationInput) (*repositories.TableCombination, error) {
+	return s.floorPlanRepo.UpdateCombination(ctx, id, input)
+}
+
+func (s *floorPlanService) DeleteCombination(ctx context.Context, id string) error {
+	return s.floorPlanRepo.DeleteCombination(ctx, id)
+}
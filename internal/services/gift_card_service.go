@@ -0,0 +1,59 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type GiftCardService interface {
+	IssueGiftCard(ctx context.Context, input repositories.GiftCardLoadInput) (*repositories.GiftCard, error)
+	TopUpGiftCard(ctx context.Context, input repositories.GiftCardLoadInput) (*repositories.GiftCard, error)
+	GetGiftCard(ctx context.Context, id string) (*repositories.GiftCard, error)
+	GetGiftCardByCode(ctx context.Context, code string) (*repositories.GiftCard, error)
+	ListGiftCards(ctx context.Context, filter repositories.GiftCardFilter) ([]repositories.GiftCard, error)
+	ListLedger(ctx context.Context, filter repositories.GiftCardLedgerFilter) ([]repositories.GiftCardEntry, error)
+	SetGiftCardStatus(ctx context.Context, id, status string) error
+	GetLiability(ctx context.Context) (*repositories.GiftCardLiability, error)
+}
+
+type giftCardService struct {
+	giftCardRepo repositories.GiftCardRepository
+}
+
+func NewGiftCardService(giftCardRepo repositories.GiftCardRepository) GiftCardService {
+	return &giftCardService{
+		giftCardRepo: giftCardRepo,
+	}
+}
+
+func (s *giftCardService) IssueGiftCard(ctx context.Context, input repositories.GiftCardLoadInput) (*repositories.GiftCard, error) {
+	return s.giftCardRepo.Issue(ctx, input)
+}
+
+func (s *giftCardService) TopUpGiftCard(ctx context.Context, input repositories.GiftCardLoadInput) (*repositories.GiftCard, error) {
+	return s.giftCardRepo.TopUp(ctx, input)
+}
+
+func (s *giftCardService) GetGiftCard(ctx context.Context, id string) (*repositories.GiftCard, error) {
+	return s.giftCardRepo.GetByID(ctx, id)
+}
+
+func (s *giftCardService) GetGiftCardByCode(ctx context.Context, code string) (*repositories.GiftCard, error) {
+	return s.giftCardRepo.GetByCode(ctx, code)
+}
+
+func (s *giftCardService) ListGiftCards(ctx context.Context, filter repositories.GiftCardFilter) ([]repositories.GiftCard, error) {
+	return s.giftCardRepo.List(ctx, filter)
+}
+
+func (s *giftCardService) ListLedger(ctx context.Context, filter repositories.GiftCardLedgerFilter) ([]repositories.GiftCardEntry, error) {
+	return s.giftCardRepo.ListLedger(ctx, filter)
+}
+
+func (s *giftCardService) SetGiftCardStatus(ctx context.Context, id, status string) error {
+	return s.giftCardRepo.SetStatus(ctx, id, status)
+}
+
+func (s *giftCardService) GetLiability(ctx context.Context) (*repositories.GiftCardLiability, error) {
+	return s.giftCardRepo.GetLiability(ctx)
+}
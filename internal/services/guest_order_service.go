@@ -0,0 +1,167 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type GuestOrderService interface {
+	// TableToken mengembalikan token QR meja; rotate=true membatalkan QR lama
+	TableToken(ctx context.Context, tableID string, rotate bool) (*repositories.GuestTable, string, error)
+	ResolveTable(ctx context.Context, token string) (*repositories.GuestTable, error)
+	RequiresApproval() bool
+	GetMenu(ctx context.Context) ([]repositories.GuestMenuCategory, error)
+	// SubmitOrder mencatat pesanan tamu; jika persetujuan tidak diwajibkan langsung diteruskan ke order meja
+	SubmitOrder(ctx context.Context, token string, input repositories.GuestOrderInput) (*repositories.GuestOrderRequest, bool, error)
+	GetGuestOrder(ctx context.Context, token, id string) (*repositories.GuestOrderRequest, error)
+	ListGuestOrders(ctx context.Context, filter repositories.GuestOrderFilter) ([]repositories.GuestOrderRequest, error)
+	ApproveGuestOrder(ctx context.Context, id, approvedBy string) (*repositories.GuestOrderRequest, bool, error)
+	RejectGuestOrder(ctx context.Context, id, rejectedBy, reason string) (*repositories.GuestOrderRequest, error)
+}
+
+type guestOrderService struct {
+	guestOrderRepo repositories.GuestOrderRepository
+	orderRepo      repositories.OrderRepository
+	settings       repositories.GuestOrderSettings
+}
+
+func NewGuestOrderService(guestOrderRepo repositories.GuestOrderRepository, orderRepo repositories.OrderRepository, settings repositories.GuestOrderSettings) GuestOrderService {
+	if settings.MaxPendingPerTable <= 0 {
+		settings.MaxPendingPerTable = 5
+	}
+	return &guestOrderService{
+		guestOrderRepo: guestOrderRepo,
+		orderRepo:      orderRepo,
+		settings:       settings,
+	}
+}
+
+// signTable menandatangani id meja beserta nonce-nya
+func (s *guestOrderService) signTable(table *repositories.GuestTable) string {
+	mac := hmac.New(sha256.New, []byte(s.settings.Secret))
+	mac.Write([]byte(table.ID + ":" + table.GuestNonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *guestOrderService) TableToken(ctx context.Context, tableID string, rotate bool) (*repositories.GuestTable, string, error) {
+	table, err := s.guestOrderRepo.RotateTableNonce(ctx, tableID, rotate)
+	if err != nil {
+		return nil, "", err
+	}
+	return table, table.ID + "." + s.signTable(table), nil
+}
+
+func (s *guestOrderService) ResolveTable(ctx context.Context, token string) (*repositories.GuestTable, error) {
+	tableID, signature, ok := strings.Cut(token, ".")
+	if !ok || tableID == "" || signature == "" {
+		return nil, repositories.ErrInvalidGuestToken
+	}
+	table, err := s.guestOrderRepo.GetTable(ctx, tableID)
+	if err == repositories.ErrGuestTableNotFound {
+		return nil, repositories.ErrInvalidGuestToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if table.GuestNonce == "" || !hmac.Equal([]byte(signature), []byte(s.signTable(table))) {
+		return nil, repositories.ErrInvalidGuestToken
+	}
+	return table, nil
+}
+
+func (s *guestOrderService) RequiresApproval() bool {
+	return s.settings.RequireApproval
+}
+
+func (s *guestOrderService) GetMenu(ctx context.Context) ([]repositories.GuestMenuCategory, error) {
+	return s.guestOrderRepo.GetMenu(ctx, time.Now())
+}
+
+func (s *guestOrderService) SubmitOrder(ctx context.Context, token string, input repositories.GuestOrderInput) (*repositories.GuestOrderRequest, bool, error) {
+	table, err := s.ResolveTable(ctx, token)
+	if err != nil {
+		return nil, false, err
+	}
+	input.TableNumber = table.TableNumber
+	request, err := s.guestOrderRepo.Create(ctx, input, s.settings.MaxPendingPerTable)
+	if err != nil {
+		return nil, false, err
+	}
+	if s.settings.RequireApproval {
+		return request, false, nil
+	}
+	return s.forwardToOrder(ctx, request.ID, "")
+}
+
+func (s *guestOrderService) GetGuestOrder(ctx context.Context, token, id string) (*repositories.GuestOrderRequest, error) {
+	table, err := s.ResolveTable(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	request, err := s.guestOrderRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// Tamu hanya boleh melihat pesanan dari mejanya sendiri
+	if request.TableNumber != table.TableNumber {
+		return nil, repositories.ErrGuestOrderNotFound
+	}
+	return request, nil
+}
+
+func (s *guestOrderService) ListGuestOrders(ctx context.Context, filter repositories.GuestOrderFilter) ([]repositories.GuestOrderRequest, error) {
+	return s.guestOrderRepo.List(ctx, filter)
+}
+
+func (s *guestOrderService) ApproveGuestOrder(ctx context.Context, id, approvedBy string) (*repositories.GuestOrderRequest, bool, error) {
+	return s.forwardToOrder(ctx, id, approvedBy)
+}
+
+func (s *guestOrderService) RejectGuestOrder(ctx context.Context, id, rejectedBy, reason string) (*repositories.GuestOrderRequest, error) {
+	return s.guestOrderRepo.Reject(ctx, id, rejectedBy, reason)
+}
+
+// forwardToOrder meneruskan pesanan tamu ke order aktif meja, atau membuat order baru,
+// lewat alur order yang sama dengan waiter sehingga item dicetak ke dapur.
+// Mengembalikan true jika order baru dibuat.
+func (s *guestOrderService) forwardToOrder(ctx context.Context, id, reviewedBy string) (*repositories.GuestOrderRequest, bool, error) {
+	request, err := s.guestOrderRepo.Claim(ctx, id, reviewedBy)
+	if err != nil {
+		return nil, false, err
+	}
+
+	items := make([]repositories.OrderItemInput, 0, len(request.Items))
+	for _, item := range request.Items {
+		items = append(items, repositories.OrderItemInput{
+			ProductID: item.ProductID,
+			Qty:       item.Qty,
+			Course:    item.Course,
+		})
+	}
+
+	orderID, created, err := s.orderRepo.AddItemsToTableOrder(ctx, repositories.OrderInput{
+		TableNumber:  request.TableNumber,
+		CustomerName: request.CustomerName,
+		Pax:          request.Pax,
+		Items:        items,
+		CreatedBy:    reviewedBy,
+	})
+	if err != nil {
+		if releaseErr := s.guestOrderRepo.Release(ctx, id); releaseErr != nil {
+			return nil, false, fmt.Errorf("gagal meneruskan pesanan tamu ke order: %w (status pesanan gagal dikembalikan: %v)", err, releaseErr)
+		}
+		return nil, false, fmt.Errorf("gagal meneruskan pesanan tamu ke order: %w", err)
+	}
+
+	request, err = s.guestOrderRepo.AttachOrder(ctx, id, orderID)
+	if err != nil {
+		return nil, false, err
+	}
+	return request, created, nil
+}
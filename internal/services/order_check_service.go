@@ -0,0 +1,64 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type OrderCheckService interface {
+	ListChecks(ctx context.Context, orderID string) (*repositories.OrderChecks, error)
+	CreateCheck(ctx context.Context, orderID string, input repositories.OrderCheckInput, createdBy string) (*repositories.OrderChecks, error)
+	SplitEven(ctx context.Context, orderID string, count int, createdBy string) (*repositories.OrderChecks, error)
+	SplitByAmounts(ctx context.Context, orderID string, amounts []float64, createdBy string) (*repositories.OrderChecks, error)
+	SplitBySeat(ctx context.Context, orderID string, createdBy string) (*repositories.OrderChecks, error)
+	MoveItem(ctx context.Context, input repositories.MoveCheckItemInput) (*repositories.OrderChecks, error)
+	DeleteCheck(ctx context.Context, orderID, checkID string) (*repositories.OrderChecks, error)
+	ClearChecks(ctx context.Context, orderID string) (*repositories.OrderChecks, error)
+	SetItemSeat(ctx context.Context, itemID string, seatNumber *int64) error
+}
+
+type orderCheckService struct {
+	orderCheckRepo repositories.OrderCheckRepository
+}
+
+func NewOrderCheckService(orderCheckRepo repositories.OrderCheckRepository) OrderCheckService {
+	return &orderCheckService{
+		orderCheckRepo: orderCheckRepo,
+	}
+}
+
+func (s *orderCheckService) ListChecks(ctx context.Context, orderID string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.List(ctx, orderID)
+}
+
+func (s *orderCheckService) CreateCheck(ctx context.Context, orderID string, input repositories.OrderCheckInput, createdBy string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.Create(ctx, orderID, input, createdBy)
+}
+
+func (s *orderCheckService) SplitEven(ctx context.Context, orderID string, count int, createdBy string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.SplitEven(ctx, orderID, count, createdBy)
+}
+
+func (s *orderCheckService) SplitByAmounts(ctx context.Context, orderID string, amounts []float64, createdBy string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.SplitByAmounts(ctx, orderID, amounts, createdBy)
+}
+
+func (s *orderCheckService) SplitBySeat(ctx context.Context, orderID string, createdBy string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.SplitBySeat(ctx, orderID, createdBy)
+}
+
+func (s *orderCheckService) MoveItem(ctx context.Context, input repositories.MoveCheckItemInput) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.MoveItem(ctx, input)
+}
+
+func (s *orderCheckService) DeleteCheck(ctx context.Context, orderID, checkID string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.Delete(ctx, orderID, checkID)
+}
+
+func (s *orderCheckService) ClearChecks(ctx context.Context, orderID string) (*repositories.OrderChecks, error) {
+	return s.orderCheckRepo.Clear(ctx, orderID)
+}
+
+func (s *orderCheckService) SetItemSeat(ctx context.Context, itemID string, seatNumber *int64) error {
+	return s.orderCheckRepo.SetItemSeat(ctx, itemID, seatNumber)
+}
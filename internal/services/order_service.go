@@ -16,8 +16,12 @@ type OrderService interface {
 	UpdateOrderItemQty(ctx context.Context, itemID string, qty int64) error
 	AddItemsToOrder(ctx context.Context, orderID string, items []repositories.OrderItemInput) error
 	ProcessPayment(ctx context.Context, orderID string) error
+	SettleOrder(ctx context.Context, input repositories.OrderSettlement) (*repositories.OrderSettlementResult, error)
 	ApplyOrderDiscount(ctx context.Context, orderID string, chargeType string, value float64) error
 	ApplyOrderCompliment(ctx context.Context, orderID string) error
+	ApplyVoucher(ctx context.Context, orderID string, code string, appliedBy string) error
+	RemoveVoucher(ctx context.Context, orderID string, code string) error
+	GetOrderPromotions(ctx context.Context, orderID string) ([]repositories.AppliedPromotion, error)
 	GetOrderDetails(ctx context.Context, orderID string) (*db.Order, []db.OrderItem, error)
 	GetOrderByTableID(ctx context.Context, tableID string) (*db.Order, []db.OrderItem, error)
 	GetAnalytics(ctx context.Context, startDate, endDate time.Time) (*db.GetOrderAnalyticsRow, error)
@@ -25,14 +29,23 @@ type OrderService interface {
 	GetVoidedTotalByDateRange(ctx context.Context, startDate, endDate time.Time) (float64, error)
 	GetCancelledTotalByDateRange(ctx context.Context, startDate, endDate time.Time) (float64, error)
 	GetAdditionalChargesSummary(ctx context.Context, startDate, endDate time.Time) (total float64, breakdowns []repositories.AdditionalChargeBreakdown, err error)
+	GetPromotionSummary(ctx context.Context, startDate, endDate time.Time) (total float64, summaries []repositories.PromotionSummary, err error)
 	GetProductsSold(ctx context.Context, startDate, endDate time.Time) (int64, error)
 	GetRevenueTimeSeries(ctx context.Context, startDate, endDate time.Time, period string) ([]repositories.TimeSeriesData, error)
 	ListOrders(ctx context.Context, limit, offset int64) ([]db.Order, int64, error)
 	ListOrdersByCustomer(ctx context.Context, customerID string, startDate, endDate time.Time) ([]db.Order, error)
-	SplitBillPayment(ctx context.Context, orderID string, amount float64, paymentMethod string, note string, createdBy string, items []repositories.SplitBillItem) error
-	MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string) (string, error)
+	SplitBillPayment(ctx context.Context, input repositories.SplitPayment) (*repositories.SplitPaymentResult, error)
+	MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string, mergedBy string) (string, error)
+	TransferOrder(ctx context.Context, orderID string, targetTableNumber string, movedBy string) (*repositories.TableChange, error)
+	MoveOrderItems(ctx context.Context, sourceOrderID, targetOrderID string, items []repositories.MoveItemInput, movedBy string) (*repositories.TableChange, error)
+	UnmergeOrder(ctx context.Context, mergedOrderID string, movedBy string) (*repositories.UnmergeResult, error)
+	FireOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]repositories.CourseItem, error)
+	HoldOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]repositories.CourseItem, error)
 	GetOrderPayments(ctx context.Context, orderID string) ([]db.Payment, error)
-	VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string) error
+	VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string, shiftID string) error
+	RefundOrder(ctx context.Context, input repositories.RefundInput) (string, error)
+	GetOrderRefunds(ctx context.Context, orderID string) ([]repositories.Refund, error)
+	GetRefundSummary(ctx context.Context, startDate, endDate time.Time) (total float64, count int64, summaries []repositories.RefundSummary, err error)
 	ListVoidedOrders(ctx context.Context, limit, offset int64) ([]repositories.VoidedOrderHistory, int64, error)
 	ListVoidedOrdersByDateRange(ctx context.Context, startDate, endDate time.Time, limit, offset int64) ([]repositories.VoidedOrderHistory, int64, error)
 }
@@ -79,6 +92,10 @@ func (s *orderService) ProcessPayment(ctx context.Context, orderID string) error
 	return s.orderRepo.ProcessPayment(ctx, orderID)
 }
 
+func (s *orderService) SettleOrder(ctx context.Context, input repositories.OrderSettlement) (*repositories.OrderSettlementResult, error) {
+	return s.orderRepo.SettleOrder(ctx, input)
+}
+
 func (s *orderService) ApplyOrderDiscount(ctx context.Context, orderID string, chargeType string, value float64) error {
 	return s.orderRepo.ApplyOrderDiscount(ctx, orderID, chargeType, value)
 }
@@ -87,6 +104,18 @@ func (s *orderService) ApplyOrderCompliment(ctx context.Context, orderID string)
 	return s.orderRepo.ApplyOrderCompliment(ctx, orderID)
 }
 
+func (s *orderService) ApplyVoucher(ctx context.Context, orderID string, code string, appliedBy string) error {
+	return s.orderRepo.ApplyVoucher(ctx, orderID, code, appliedBy)
+}
+
+func (s *orderService) RemoveVoucher(ctx context.Context, orderID string, code string) error {
+	return s.orderRepo.RemoveVoucher(ctx, orderID, code)
+}
+
+func (s *orderService) GetOrderPromotions(ctx context.Context, orderID string) ([]repositories.AppliedPromotion, error) {
+	return s.orderRepo.GetOrderPromotions(ctx, orderID)
+}
+
 func (s *orderService) GetOrderDetails(ctx context.Context, orderID string) (*db.Order, []db.OrderItem, error) {
 	return s.orderRepo.GetOrderWithItems(ctx, orderID)
 }
@@ -115,6 +144,10 @@ func (s *orderService) GetAdditionalChargesSummary(ctx context.Context, startDat
 	return s.orderRepo.GetAdditionalChargesSummary(ctx, startDate, endDate)
 }
 
+func (s *orderService) GetPromotionSummary(ctx context.Context, startDate, endDate time.Time) (total float64, summaries []repositories.PromotionSummary, err error) {
+	return s.orderRepo.GetPromotionSummary(ctx, startDate, endDate)
+}
+
 func (s *orderService) GetProductsSold(ctx context.Context, startDate, endDate time.Time) (int64, error) {
 	return s.orderRepo.GetProductsSold(ctx, startDate, endDate)
 }
@@ -131,20 +164,52 @@ func (s *orderService) ListOrdersByCustomer(ctx context.Context, customerID stri
 	return s.orderRepo.ListOrdersByCustomer(ctx, customerID, startDate, endDate)
 }
 
-func (s *orderService) SplitBillPayment(ctx context.Context, orderID string, amount float64, paymentMethod string, note string, createdBy string, items []repositories.SplitBillItem) error {
-	return s.orderRepo.SplitBillPayment(ctx, orderID, amount, paymentMethod, note, createdBy, items)
+func (s *orderService) SplitBillPayment(ctx context.Context, input repositories.SplitPayment) (*repositories.SplitPaymentResult, error) {
+	return s.orderRepo.SplitBillPayment(ctx, input)
+}
+
+func (s *orderService) MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string, mergedBy string) (string, error) {
+	return s.orderRepo.MergeTables(ctx, sourceOrderIDs, targetTableNumber, mergedBy)
+}
+
+func (s *orderService) TransferOrder(ctx context.Context, orderID string, targetTableNumber string, movedBy string) (*repositories.TableChange, error) {
+	return s.orderRepo.TransferOrder(ctx, orderID, targetTableNumber, movedBy)
+}
+
+func (s *orderService) MoveOrderItems(ctx context.Context, sourceOrderID, targetOrderID string, items []repositories.MoveItemInput, movedBy string) (*repositories.TableChange, error) {
+	return s.orderRepo.MoveOrderItems(ctx, sourceOrderID, targetOrderID, items, movedBy)
+}
+
+func (s *orderService) UnmergeOrder(ctx context.Context, mergedOrderID string, movedBy string) (*repositories.UnmergeResult, error) {
+	return s.orderRepo.UnmergeOrder(ctx, mergedOrderID, movedBy)
+}
+
+func (s *orderService) FireOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]repositories.CourseItem, error) {
+	return s.orderRepo.FireOrderItems(ctx, orderID, course, itemIDs)
 }
 
-func (s *orderService) MergeTables(ctx context.Context, sourceOrderIDs []string, targetTableNumber string) (string, error) {
-	return s.orderRepo.MergeTables(ctx, sourceOrderIDs, targetTableNumber)
+func (s *orderService) HoldOrderItems(ctx context.Context, orderID string, course string, itemIDs []string) ([]repositories.CourseItem, error) {
+	return s.orderRepo.HoldOrderItems(ctx, orderID, course, itemIDs)
 }
 
 func (s *orderService) GetOrderPayments(ctx context.Context, orderID string) ([]db.Payment, error) {
 	return s.orderRepo.GetOrderPayments(ctx, orderID)
 }
 
-func (s *orderService) VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string) error {
-	return s.orderRepo.VoidOrder(ctx, orderID, voidedBy, voidReason)
+func (s *orderService) VoidOrder(ctx context.Context, orderID string, voidedBy string, voidReason string, shiftID string) error {
+	return s.orderRepo.VoidOrder(ctx, orderID, voidedBy, voidReason, shiftID)
+}
+
+func (s *orderService) RefundOrder(ctx context.Context, input repositories.RefundInput) (string, error) {
+	return s.orderRepo.RefundOrder(ctx, input)
+}
+
+func (s *orderService) GetOrderRefunds(ctx context.Context, orderID string) ([]repositories.Refund, error) {
+	return s.orderRepo.GetOrderRefunds(ctx, orderID)
+}
+
+func (s *orderService) GetRefundSummary(ctx context.Context, startDate, endDate time.Time) (total float64, count int64, summaries []repositories.RefundSummary, err error) {
+	return s.orderRepo.GetRefundSummary(ctx, startDate, endDate)
 }
 
 func (s *orderService) ListVoidedOrders(ctx context.Context, limit, offset int64) ([]repositories.VoidedOrderHistory, int64, error) {
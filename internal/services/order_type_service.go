@@ -0,0 +1,42 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type OrderTypeService interface {
+	ListOrderTypes(ctx context.Context) ([]repositories.OrderTypeSetting, error)
+	GetOrderType(ctx context.Context, orderType string) (*repositories.OrderTypeSetting, error)
+	UpdateOrderType(ctx context.Context, orderType string, input repositories.OrderTypeSettingInput) (*repositories.OrderTypeSetting, error)
+	SetChargeRules(ctx context.Context, orderType string, rules []repositories.AreaChargeRule) (*repositories.OrderTypeSetting, error)
+	ListQueue(ctx context.Context, orderType string, activeOnly bool) ([]repositories.QueueOrder, error)
+}
+
+type orderTypeService struct {
+	orderTypeRepo repositories.OrderTypeRepository
+}
+
+func NewOrderTypeService(orderTypeRepo repositories.OrderTypeRepository) OrderTypeService {
+	return &orderTypeService{orderTypeRepo: orderTypeRepo}
+}
+
+func (s *orderTypeService) ListOrderTypes(ctx context.Context) ([]repositories.OrderTypeSetting, error) {
+	return s.orderTypeRepo.List(ctx)
+}
+
+func (s *orderTypeService) GetOrderType(ctx context.Context, orderType string) (*repositories.OrderTypeSetting, error) {
+	return s.orderTypeRepo.Get(ctx, orderType)
+}
+
+func (s *orderTypeService) UpdateOrderType(ctx context.Context, orderType string, input repositories.OrderTypeSettingInput) (*repositories.OrderTypeSetting, error) {
+	return s.orderTypeRepo.Update(ctx, orderType, input)
+}
+
+func (s *orderTypeService) SetChargeRules(ctx context.Context, orderType string, rules []repositories.AreaChargeRule) (*repositories.OrderTypeSetting, error) {
+	return s.orderTypeRepo.SetChargeRules(ctx, orderType, rules)
+}
+
+func (s *orderTypeService) ListQueue(ctx context.Context, orderType string, activeOnly bool) ([]repositories.QueueOrder, error) {
+	return s.orderTypeRepo.ListQueue(ctx, orderType, activeOnly)
+}
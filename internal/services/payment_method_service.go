@@ -0,0 +1,44 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type PaymentMethodService interface {
+	ListPaymentMethods(ctx context.Context, activeOnly bool) ([]repositories.PaymentMethod, error)
+	GetPaymentMethod(ctx context.Context, id string) (*repositories.PaymentMethod, error)
+	CreatePaymentMethod(ctx context.Context, method *repositories.PaymentMethod) error
+	UpdatePaymentMethod(ctx context.Context, method *repositories.PaymentMethod) error
+	DeletePaymentMethod(ctx context.Context, id string) error
+}
+
+type paymentMethodService struct {
+	paymentMethodRepo repositories.PaymentMethodRepository
+}
+
+func NewPaymentMethodService(paymentMethodRepo repositories.PaymentMethodRepository) PaymentMethodService {
+	return &paymentMethodService{
+		paymentMethodRepo: paymentMethodRepo,
+	}
+}
+
+func (s *paymentMethodService) ListPaymentMethods(ctx context.Context, activeOnly bool) ([]repositories.PaymentMethod, error) {
+	return s.paymentMethodRepo.List(ctx, activeOnly)
+}
+
+func (s *paymentMethodService) GetPaymentMethod(ctx context.Context, id string) (*repositories.PaymentMethod, error) {
+	return s.paymentMethodRepo.GetByID(ctx, id)
+}
+
+func (s *paymentMethodService) CreatePaymentMethod(ctx context.Context, method *repositories.PaymentMethod) error {
+	return s.paymentMethodRepo.Create(ctx, method)
+}
+
+func (s *paymentMethodService) UpdatePaymentMethod(ctx context.Context, method *repositories.PaymentMethod) error {
+	return s.paymentMethodRepo.Update(ctx, method)
+}
+
+func (s *paymentMethodService) DeletePaymentMethod(ctx context.Context, id string) error {
+	return s.paymentMethodRepo.Delete(ctx, id)
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"backend/pkg/payment"
+	"backend/pkg/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+)
+
+var (
+	ErrPaymentIntentNotPending = errors.New("intent pembayaran sudah tidak aktif")
+	ErrPaymentAmountMismatch   = errors.New("nominal callback tidak sesuai intent")
+)
+
+type PaymentService interface {
+	ListProviders() []string
+	DefaultProvider() string
+	CreateIntent(ctx context.Context, orderID string, amount float64, providerName string, createdBy, shiftID string) (*repositories.PaymentIntent, error)
+	GetIntent(ctx context.Context, id string) (*repositories.PaymentIntent, error)
+	GetActiveIntent(ctx context.Context, orderID string) (*repositories.PaymentIntent, error)
+	ListOrderIntents(ctx context.Context, orderID string) ([]repositories.PaymentIntent, error)
+	// RefreshIntent menanyakan status ke provider. settled bernilai true selama intent
+	// paid belum tercatat ke order, sehingga pelunasan yang gagal diulang pada panggilan berikutnya.
+	RefreshIntent(ctx context.Context, id string) (intent *repositories.PaymentIntent, settled bool, err error)
+	HandleCallback(ctx context.Context, providerName string, body []byte, header http.Header) (intent *repositories.PaymentIntent, settled bool, err error)
+	CancelIntent(ctx context.Context, id string) (*repositories.PaymentIntent, error)
+	// MarkRefundRequired menandai intent paid yang tidak bisa dicatat karena order sudah lunas
+	MarkRefundRequired(ctx context.Context, id string) (bool, error)
+	SimulateSandbox(ctx context.Context, id string, status string) (intent *repositories.PaymentIntent, settled bool, err error)
+}
+
+type paymentService struct {
+	intentRepo repositories.PaymentIntentRepository
+	registry   *payment.Registry
+	intentTTL  time.Duration
+}
+
+func NewPaymentService(intentRepo repositories.PaymentIntentRepository, registry *payment.Registry, intentTTL time.Duration) PaymentService {
+	if intentTTL <= 0 {
+		intentTTL = 15 * time.Minute
+	}
+	return &paymentService{
+		intentRepo: intentRepo,
+		registry:   registry,
+		intentTTL:  intentTTL,
+	}
+}
+
+func (s *paymentService) ListProviders() []string {
+	return s.registry.Names()
+}
+
+func (s *paymentService) DefaultProvider() string {
+	return s.registry.Default()
+}
+
+// CreateIntent membatalkan intent pending sebelumnya untuk order yang sama
+// agar hanya satu QR yang berlaku, lalu membuat intent baru di provider
+func (s *paymentService) CreateIntent(ctx context.Context, orderID string, amount float64, providerName string, createdBy, shiftID string) (*repositories.PaymentIntent, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		active, err := s.intentRepo.GetActiveByOrder(ctx, orderID)
+		if errors.Is(err, repositories.ErrPaymentIntentNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.CancelIntent(ctx, active.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	id := utils.GenerateULID()
+	created, err := provider.CreateIntent(ctx, payment.IntentRequest{
+		Reference:   id,
+		Amount:      int64(math.Round(amount)),
+		Description: "Order " + orderID,
+		ExpiresIn:   s.intentTTL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat intent di provider %s: %w", provider.Name(), err)
+	}
+
+	intent := &repositories.PaymentIntent{
+		ID:            id,
+		OrderID:       orderID,
+		Provider:      provider.Name(),
+		ProviderRef:   created.ProviderRef,
+		PaymentMethod: provider.Method(),
+		Amount:        math.Round(amount),
+		QRString:      created.QRString,
+		Status:        string(payment.StatusPending),
+		CreatedBy:     createdBy,
+		ShiftID:       shiftID,
+	}
+	if !created.ExpiresAt.IsZero() {
+		intent.ExpiresAt = &created.ExpiresAt
+	}
+	if err := s.intentRepo.Create(ctx, intent); err != nil {
+		_ = provider.Cancel(ctx, created.ProviderRef)
+		return nil, err
+	}
+	return s.intentRepo.GetByID(ctx, id)
+}
+
+func (s *paymentService) GetIntent(ctx context.Context, id string) (*repositories.PaymentIntent, error) {
+	return s.intentRepo.GetByID(ctx, id)
+}
+
+func (s *paymentService) GetActiveIntent(ctx context.Context, orderID string) (*repositories.PaymentIntent, error) {
+	return s.intentRepo.GetActiveByOrder(ctx, orderID)
+}
+
+func (s *paymentService) ListOrderIntents(ctx context.Context, orderID string) ([]repositories.PaymentIntent, error) {
+	return s.intentRepo.ListByOrder(ctx, orderID)
+}
+
+func (s *paymentService) RefreshIntent(ctx context.Context, id string) (*repositories.PaymentIntent, bool, error) {
+	intent, err := s.intentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if intent.Status != string(payment.StatusPending) {
+		return intent, awaitingSettlement(intent), nil
+	}
+
+	provider, err := s.registry.Get(intent.Provider)
+	if err != nil {
+		return nil, false, err
+	}
+	status, err := provider.GetStatus(ctx, intent.ProviderRef)
+	if err != nil {
+		return nil, false, fmt.Errorf("gagal cek status ke provider %s: %w", provider.Name(), err)
+	}
+	if status == payment.StatusPending && intent.ExpiresAt != nil && time.Now().After(*intent.ExpiresAt) {
+		status = payment.StatusExpired
+	}
+	return s.applyStatus(ctx, intent, status)
+}
+
+func (s *paymentService) HandleCallback(ctx context.Context, providerName string, body []byte, header http.Header) (*repositories.PaymentIntent, bool, error) {
+	provider, err := s.registry.Get(providerName)
+	if err != nil || providerName == "" {
+		return nil, false, payment.ErrProviderNotFound
+	}
+	event, err := provider.ParseCallback(body, header)
+	if err != nil {
+		return nil, false, err
+	}
+
+	intent, err := s.intentRepo.GetByID(ctx, event.Reference)
+	if errors.Is(err, repositories.ErrPaymentIntentNotFound) && event.ProviderRef != "" {
+		intent, err = s.intentRepo.GetByProviderRef(ctx, provider.Name(), event.ProviderRef)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if intent.Provider != provider.Name() {
+		return nil, false, repositories.ErrPaymentIntentNotFound
+	}
+	if event.Status == payment.StatusPaid && event.Amount > 0 && event.Amount != int64(math.Round(intent.Amount)) {
+		log.Printf("Payment callback amount mismatch: intent=%s expected=%.0f got=%d", intent.ID, intent.Amount, event.Amount)
+		return nil, false, ErrPaymentAmountMismatch
+	}
+	return s.applyStatus(ctx, intent, event.Status)
+}
+
+func (s *paymentService) CancelIntent(ctx context.Context, id string) (*repositories.PaymentIntent, error) {
+	intent, err := s.intentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != string(payment.StatusPending) {
+		return nil, ErrPaymentIntentNotPending
+	}
+
+	if provider, err := s.registry.Get(intent.Provider); err == nil {
+		if err := provider.Cancel(ctx, intent.ProviderRef); err != nil {
+			log.Printf("Failed to cancel payment intent %s at %s: %v", intent.ID, intent.Provider, err)
+		}
+	}
+	if _, err := s.intentRepo.UpdateStatus(ctx, id, string(payment.StatusCancelled)); err != nil {
+		return nil, err
+	}
+	return s.intentRepo.GetByID(ctx, id)
+}
+
+// SimulateSandbox melunasi (atau menggagalkan) intent sandbox lewat jalur
+// callback yang sama dengan provider sungguhan, termasuk verifikasi signature
+func (s *paymentService) SimulateSandbox(ctx context.Context, id string, status string) (*repositories.PaymentIntent, bool, error) {
+	intent, err := s.intentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	provider, err := s.registry.Get(intent.Provider)
+	if err != nil {
+		return nil, false, err
+	}
+	sandbox, ok := provider.(*payment.SandboxProvider)
+	if !ok {
+		return nil, false, fmt.Errorf("simulasi hanya untuk provider sandbox")
+	}
+
+	body, signature, err := sandbox.Simulate(intent.ProviderRef, payment.Status(status))
+	if err != nil {
+		return nil, false, err
+	}
+	header := http.Header{}
+	header.Set(payment.SandboxSignatureHeader, signature)
+	return s.HandleCallback(ctx, sandbox.Name(), body, header)
+}
+
+func (s *paymentService) applyStatus(ctx context.Context, intent *repositories.PaymentIntent, status payment.Status) (*repositories.PaymentIntent, bool, error) {
+	if !status.IsFinal() {
+		return intent, false, nil
+	}
+	if _, err := s.intentRepo.UpdateStatus(ctx, intent.ID, string(status)); err != nil {
+		return nil, false, err
+	}
+	updated, err := s.intentRepo.GetByID(ctx, intent.ID)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, awaitingSettlement(updated), nil
+}
+
+// awaitingSettlement bernilai true untuk intent paid yang belum dicatat ke order;
+// callback ulang dari provider mencoba lagi pelunasan yang sebelumnya gagal
+func awaitingSettlement(intent *repositories.PaymentIntent) bool {
+	return intent.Status == string(payment.StatusPaid) && intent.SettledAt == nil
+}
+
+func (s *paymentService) MarkRefundRequired(ctx context.Context, id string) (bool, error) {
+	return s.intentRepo.MarkRefundRequired(ctx, id)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"time"
+)
+
+type PriceListService interface {
+	ListPriceLists(ctx context.Context) ([]repositories.PriceList, error)
+	GetPriceList(ctx context.Context, id string) (*repositories.PriceList, error)
+	CreatePriceList(ctx context.Context, list *repositories.PriceList) error
+	UpdatePriceList(ctx context.Context, list *repositories.PriceList) error
+	DeletePriceList(ctx context.Context, id string) error
+	PreviewPrices(ctx context.Context, at time.Time) ([]repositories.ScheduledPrice, error)
+}
+
+type priceListService struct {
+	priceListRepo repositories.PriceListRepository
+}
+
+func NewPriceListService(priceListRepo repositories.PriceListRepository) PriceListService {
+	return &priceListService{
+		priceListRepo: priceListRepo,
+	}
+}
+
+func (s *priceListService) ListPriceLists(ctx context.Context) ([]repositories.PriceList, error) {
+	return s.priceListRepo.List(ctx)
+}
+
+func (s *priceListService) GetPriceList(ctx context.Context, id string) (*repositories.PriceList, error) {
+	return s.priceListRepo.GetByID(ctx, id)
+}
+
+func (s *priceListService) CreatePriceList(ctx context.Context, list *repositories.PriceList) error {
+	return s.priceListRepo.Create(ctx, list)
+}
+
+func (s *priceListService) UpdatePriceList(ctx context.Context, list *repositories.PriceList) error {
+	return s.priceListRepo.Update(ctx, list)
+}
+
+func (s *priceListService) DeletePriceList(ctx context.Context, id string) error {
+	return s.priceListRepo.Delete(ctx, id)
+}
+
+func (s *priceListService) PreviewPrices(ctx context.Context, at time.Time) ([]repositories.ScheduledPrice, error) {
+	return s.priceListRepo.PreviewPrices(ctx, at)
+}
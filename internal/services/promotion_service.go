@@ -0,0 +1,71 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type PromotionService interface {
+	ListPromotions(ctx context.Context) ([]repositories.Promotion, error)
+	GetPromotion(ctx context.Context, id string) (*repositories.Promotion, error)
+	CreatePromotion(ctx context.Context, promo *repositories.Promotion) error
+	UpdatePromotion(ctx context.Context, promo *repositories.Promotion) error
+	DeletePromotion(ctx context.Context, id string) error
+	ListVouchers(ctx context.Context, promotionID string) ([]repositories.Voucher, error)
+	CreateVoucher(ctx context.Context, voucher *repositories.Voucher) error
+	DeleteVoucher(ctx context.Context, id string) error
+}
+
+type promotionService struct {
+	promotionRepo repositories.PromotionRepository
+	syncRepo      repositories.SyncRepository
+}
+
+func NewPromotionService(promotionRepo repositories.PromotionRepository, syncRepo repositories.SyncRepository) PromotionService {
+	return &promotionService{
+		promotionRepo: promotionRepo,
+		syncRepo:      syncRepo,
+	}
+}
+
+func (s *promotionService) ListPromotions(ctx context.Context) ([]repositories.Promotion, error) {
+	return s.promotionRepo.List(ctx)
+}
+
+func (s *promotionService) GetPromotion(ctx context.Context, id string) (*repositories.Promotion, error) {
+	return s.promotionRepo.GetByID(ctx, id)
+}
+
+// Perubahan promosi langsung dihitung ulang ke order yang masih terbuka
+func (s *promotionService) CreatePromotion(ctx context.Context, promo *repositories.Promotion) error {
+	if err := s.promotionRepo.Create(ctx, promo); err != nil {
+		return err
+	}
+	return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+}
+
+func (s *promotionService) UpdatePromotion(ctx context.Context, promo *repositories.Promotion) error {
+	if err := s.promotionRepo.Update(ctx, promo); err != nil {
+		return err
+	}
+	return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+}
+
+func (s *promotionService) DeletePromotion(ctx context.Context, id string) error {
+	if err := s.promotionRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+}
+
+func (s *promotionService) ListVouchers(ctx context.Context, promotionID string) ([]repositories.Voucher, error) {
+	return s.promotionRepo.ListVouchers(ctx, promotionID)
+}
+
+func (s *promotionService) CreateVoucher(ctx context.Context, voucher *repositories.Voucher) error {
+	return s.promotionRepo.CreateVoucher(ctx, voucher)
+}
+
+func (s *promotionService) DeleteVoucher(ctx context.Context, id string) error {
+	return s.promotionRepo.DeleteVoucher(ctx, id)
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"time"
+)
+
+type ReservationService interface {
+	CreateReservation(ctx context.Context, input repositories.ReservationInput) (*repositories.Reservation, error)
+	GetReservation(ctx context.Context, id string) (*repositories.Reservation, error)
+	ListReservations(ctx context.Context, filter repositories.ReservationFilter) ([]repositories.Reservation, error)
+	UpdateReservation(ctx context.Context, id string, input repositories.ReservationUpdate) (*repositories.Reservation, error)
+	AssignTable(ctx context.Context, id, tableID string) (*repositories.Reservation, error)
+	SeatReservation(ctx context.Context, id, tableID, orderID string) (*repositories.Reservation, error)
+	CancelReservation(ctx context.Context, id, reason string) (*repositories.Reservation, error)
+	GetAvailability(ctx context.Context, start time.Time, durationMinutes, pax int64) ([]repositories.TableAvailability, error)
+	ProcessTransitions(ctx context.Context) (*repositories.ReservationTransitions, error)
+
+	AddToWaitlist(ctx context.Context, input repositories.WaitlistInput) (*repositories.WaitlistEntry, error)
+	ListWaitlist(ctx context.Context, activeOnly bool) ([]repositories.WaitlistEntry, error)
+	NotifyWaitlist(ctx context.Context, id string) (*repositories.WaitlistEntry, error)
+	SeatWaitlist(ctx context.Context, id, tableID, orderID string) (*repositories.WaitlistEntry, error)
+	LeaveWaitlist(ctx context.Context, id string) (*repositories.WaitlistEntry, error)
+}
+
+type reservationService struct {
+	reservationRepo repositories.ReservationRepository
+	settings        repositories.ReservationSettings
+}
+
+func NewReservationService(reservationRepo repositories.ReservationRepository, settings repositories.ReservationSettings) ReservationService {
+	if settings.HoldBefore < 0 {
+		settings.HoldBefore = 0
+	}
+	if settings.NoShowGrace < 0 {
+		settings.NoShowGrace = 0
+	}
+	if settings.DefaultDuration <= 0 {
+		settings.DefaultDuration = 90 * time.Minute
+	}
+	return &reservationService{
+		reservationRepo: reservationRepo,
+		settings:        settings,
+	}
+}
+
+func (s *reservationService) CreateReservation(ctx context.Context, input repositories.ReservationInput) (*repositories.Reservation, error) {
+	return s.reservationRepo.Create(ctx, input, s.settings)
+}
+
+func (s *reservationService) GetReservation(ctx context.Context, id string) (*repositories.Reservation, error) {
+	return s.reservationRepo.GetByID(ctx, id)
+}
+
+func (s *reservationService) ListReservations(ctx context.Context, filter repositories.ReservationFilter) ([]repositories.Reservation, error) {
+	return s.reservationRepo.List(ctx, filter)
+}
+
+func (s *reservationService) UpdateReservation(ctx context.Context, id string, input repositories.ReservationUpdate) (*repositories.Reservation, error) {
+	return s.reservationRepo.Update(ctx, id, input, s.settings)
+}
+
+func (s *reservationService) AssignTable(ctx context.Context, id, tableID string) (*repositories.Reservation, error) {
+	return s.reservationRepo.Assign(ctx, id, tableID, s.settings)
+}
+
+func (s *reservationService) SeatReservation(ctx context.Context, id, tableID, orderID string) (*repositories.Reservation, error) {
+	return s.reservationRepo.Seat(ctx, id, tableID, orderID, s.settings)
+}
+
+func (s *reservationService) CancelReservation(ctx context.Context, id, reason string) (*repositories.Reservation, error) {
+	return s.reservationRepo.Cancel(ctx, id, reason, s.settings)
+}
+
+func (s *reservationService) GetAvailability(ctx context.Context, start time.Time, durationMinutes, pax int64) ([]repositories.TableAvailability, error) {
+	return s.reservationRepo.Availability(ctx, start, durationMinutes, pax, s.settings)
+}
+
+func (s *reservationService) ProcessTransitions(ctx context.Context) (*repositories.ReservationTransitions, error) {
+	return s.reservationRepo.ProcessTransitions(ctx, time.Now(), s.settings)
+}
+
+func (s *reservationService) AddToWaitlist(ctx context.Context, input repositories.WaitlistInput) (*repositories.WaitlistEntry, error) {
+	return s.reservationRepo.AddWaitlist(ctx, input, s.settings)
+}
+
+func (s *reservationService) ListWaitlist(ctx context.Context, activeOnly bool) ([]repositories.WaitlistEntry, error) {
+	return s.reservationRepo.ListWaitlist(ctx, activeOnly)
+}
+
+func (s *reservationService) NotifyWaitlist(ctx context.Context, id string) (*repositories.WaitlistEntry, error) {
+	return s.reservationRepo.NotifyWaitlist(ctx, id)
+}
+
+func (s *reservationService) SeatWaitlist(ctx context.Context, id, tableID, orderID string) (*repositories.WaitlistEntry, error) {
+	return s.reservationRepo.SeatWaitlist(ctx, id, tableID, orderID)
+}
+
+func (s *reservationService) LeaveWaitlist(ctx context.Context, id string) (*repositories.WaitlistEntry, error) {
+	return s.reservationRepo.LeaveWaitlist(ctx, id)
+}
@@ -37,6 +37,15 @@ type SyncService interface {
 	ResolveConflict(ctx context.Context, entityType, entityID, strategy string) error
 }
 
+// cloudSyncedTables adalah tabel yang boleh di-upsert/hapus berdasarkan data HQ
+var cloudSyncedTables = map[string]bool{
+	"products":           true,
+	"categories":         true,
+	"additional_charges": true,
+	"tables":             true,
+	"users":              true,
+}
+
 type syncService struct {
 	syncRepo    repositories.SyncRepository
 	cloudClient *cloudapi.Client
@@ -289,6 +298,44 @@ func (s *syncService) PullUpdates(ctx context.Context, since time.Time) error {
 		}
 	}
 
+	hqCollections := []struct {
+		key    string
+		label  string
+		upsert func(context.Context, map[string]interface{}) error
+	}{
+		{"additional_charges", "additional charge", s.upsertAdditionalChargeFromCloud},
+		{"tables", "table", s.upsertTableFromCloud},
+		{"printer_routes", "printer route", s.upsertPrinterRouteFromCloud},
+		{"users", "user", s.upsertUserFromCloud},
+	}
+	chargesUpdated := false
+	for _, collection := range hqCollections {
+		entries, ok := data[collection.key].([]interface{})
+		if !ok || len(entries) == 0 {
+			continue
+		}
+		log.Printf("Processing %d %s updates", len(entries), collection.label)
+		for _, item := range entries {
+			itemData, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if err := collection.upsert(ctx, itemData); err != nil {
+				log.Printf("Error processing %s update: %v", collection.label, err)
+				continue
+			}
+			if collection.key == "additional_charges" {
+				chargesUpdated = true
+			}
+			processedCount++
+		}
+	}
+	if chargesUpdated {
+		if err := s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx); err != nil {
+			log.Printf("Failed to refresh open order totals: %v", err)
+		}
+	}
+
 	if deleted, ok := data["deleted"].([]interface{}); ok && len(deleted) > 0 {
 		log.Printf("Processing %d deletions", len(deleted))
 		for _, item := range deleted {
@@ -386,6 +433,17 @@ func (s *syncService) ProcessCloudUpdate(ctx context.Context, data map[string]in
 		return s.upsertProductFromCloud(ctx, merged)
 	case "category":
 		return s.upsertCategoryFromCloud(ctx, merged)
+	case "additional_charge":
+		if err := s.upsertAdditionalChargeFromCloud(ctx, merged); err != nil {
+			return err
+		}
+		return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+	case "table":
+		return s.upsertTableFromCloud(ctx, merged)
+	case "printer_route":
+		return s.upsertPrinterRouteFromCloud(ctx, merged)
+	case "user":
+		return s.upsertUserFromCloud(ctx, merged)
 	default:
 		log.Printf("Cloud update ignored: type=%s op=%s", entityType, operation)
 	}
@@ -407,6 +465,22 @@ func (s *syncService) ProcessCloudDelete(ctx context.Context, data map[string]in
 		return s.deleteByCloudRef(ctx, "products", localID, cloudID)
 	case "category":
 		return s.deleteByCloudRef(ctx, "categories", localID, cloudID)
+	case "additional_charge":
+		if err := s.deleteByCloudRef(ctx, "additional_charges", localID, cloudID); err != nil {
+			return err
+		}
+		return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+	case "table":
+		return s.deleteByCloudRef(ctx, "tables", localID, cloudID)
+	case "printer_route":
+		categoryID := getString(data, "category_id")
+		if categoryID == "" {
+			categoryID = getString(data, "category_cloud_id")
+		}
+		return s.clearPrinterRoute(ctx, categoryID)
+	case "user":
+		// User tidak dihapus agar riwayat order/transaksi tetap valid
+		return s.deactivateUserByCloudRef(ctx, localID, cloudID)
 	default:
 		log.Printf("Cloud delete ignored: type=%s", entityType)
 	}
@@ -457,15 +531,16 @@ func (s *syncService) upsertCategoryFromCloud(ctx context.Context, data map[stri
 		_, err = s.db.ExecContext(ctx, `
 			UPDATE categories
 			SET name = ?, description = ?, printer_id = ?, cloud_id = COALESCE(?, cloud_id),
-			    version = COALESCE(?, version), sync_status = 'synced', last_synced_at = CURRENT_TIMESTAMP
+			    version = COALESCE(?, version), sync_status = 'synced', last_synced_at = CURRENT_TIMESTAMP,
+			    managed_by_hq = 1, updated_at = CURRENT_TIMESTAMP
 			WHERE id = ?
 		`, name, nullDesc, nullPrinterID, nullCloudID, nullableInt64(version), localID)
 		return err
 	}
 
 	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO categories (id, name, description, printer_id, cloud_id, version, sync_status, last_synced_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, 'synced', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO categories (id, name, description, printer_id, cloud_id, version, sync_status, last_synced_at, managed_by_hq, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 'synced', CURRENT_TIMESTAMP, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`, localID, name, nullDesc, nullPrinterID, nullCloudID, nullableInt64(version))
 	return err
 }
@@ -522,13 +597,15 @@ func (s *syncService) upsertProductFromCloud(ctx context.Context, data map[strin
 	nullCloudID := toNullString(cloudID)
 
 	if exists {
+		// Stok dikelola outlet (terpotong saat order); HQ hanya mengisi stok awal produk baru
 		_, err = s.db.ExecContext(ctx, `
 			UPDATE products
-			SET name = ?, code = ?, description = ?, price = ?, stock = ?, category_id = ?,
+			SET name = ?, code = ?, description = ?, price = ?, category_id = ?,
 			    cloud_id = COALESCE(?, cloud_id), version = COALESCE(?, version),
-			    sync_status = 'synced', last_synced_at = CURRENT_TIMESTAMP
+			    sync_status = 'synced', last_synced_at = CURRENT_TIMESTAMP,
+			    managed_by_hq = 1, updated_at = CURRENT_TIMESTAMP
 			WHERE id = ?
-		`, name, nullCode, nullDesc, price, stock, nullCategoryID, nullCloudID, nullableInt64(version), localID)
+		`, name, nullCode, nullDesc, price, nullCategoryID, nullCloudID, nullableInt64(version), localID)
 		return err
 	}
 
@@ -538,8 +615,8 @@ func (s *syncService) upsertProductFromCloud(ctx context.Context, data map[strin
 	}
 
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO products (id, name, code, description, price, stock, category_id, cloud_id, version, sync_status, last_synced_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'synced', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		INSERT INTO products (id, name, code, description, price, stock, category_id, cloud_id, version, sync_status, last_synced_at, managed_by_hq, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 'synced', CURRENT_TIMESTAMP, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`, localID, name, nullCode, nullDesc, price, stock, nullCategoryID, nullCloudID, nullableInt64(version))
 	if err != nil {
 		tx.Rollback()
@@ -562,6 +639,298 @@ func (s *syncService) upsertProductFromCloud(ctx context.Context, data map[strin
 	return nil
 }
 
+func (s *syncService) upsertAdditionalChargeFromCloud(ctx context.Context, data map[string]interface{}) error {
+	cloudID := getString(data, "cloud_id")
+	localID := getString(data, "local_id")
+	if localID == "" {
+		localID = getString(data, "id")
+	}
+
+	name := getString(data, "name")
+	if name == "" {
+		return fmt.Errorf("additional charge name is required")
+	}
+
+	chargeType := getString(data, "charge_type")
+	if chargeType != "percentage" && chargeType != "fixed" {
+		return fmt.Errorf("invalid charge_type: %s", chargeType)
+	}
+
+	value := getFloat64(data, "value")
+	if value < 0 || (chargeType == "percentage" && value > 100) {
+		return fmt.Errorf("invalid additional charge value: %v", value)
+	}
+
+	isActive := int64(1)
+	if _, ok := data["is_active"]; ok {
+		isActive = getBoolAsInt64(data, "is_active")
+	}
+
+	if cloudID != "" {
+		existingID, err := s.findLocalIDByCloudID(ctx, "additional_charges", cloudID)
+		if err != nil {
+			return err
+		}
+		if existingID != "" {
+			localID = existingID
+		}
+	}
+
+	exists, err := s.entityExists(ctx, "additional_charges", localID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE additional_charges
+			SET name = ?, charge_type = ?, value = ?, is_active = ?, cloud_id = COALESCE(?, cloud_id),
+			    managed_by_hq = 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, name, chargeType, value, isActive, toNullString(cloudID), localID)
+		return err
+	}
+
+	outletID := ""
+	if config, err := s.syncRepo.GetOutletConfig(ctx); err == nil && config != nil {
+		outletID = config.OutletID
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO additional_charges (outlet_id, name, charge_type, value, is_active, cloud_id, managed_by_hq, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, outletID, name, chargeType, value, isActive, toNullString(cloudID))
+	return err
+}
+
+func (s *syncService) upsertTableFromCloud(ctx context.Context, data map[string]interface{}) error {
+	cloudID := getString(data, "cloud_id")
+	localID := getString(data, "local_id")
+	if localID == "" {
+		localID = getString(data, "id")
+	}
+
+	tableNumber := getString(data, "table_number")
+	if tableNumber == "" {
+		return fmt.Errorf("table_number is required")
+	}
+
+	capacity := getInt64(data, "capacity")
+	if capacity <= 0 {
+		capacity = 4
+	}
+
+	if cloudID != "" {
+		existingID, err := s.findLocalIDByCloudID(ctx, "tables", cloudID)
+		if err != nil {
+			return err
+		}
+		if existingID != "" {
+			localID = existingID
+		}
+	}
+
+	// Meja yang sudah dibuat lokal dengan nomor yang sama diambil alih oleh HQ
+	if localID == "" {
+		err := s.db.QueryRowContext(ctx, "SELECT id FROM tables WHERE table_number = ? LIMIT 1", tableNumber).Scan(&localID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	if localID == "" {
+		localID = utils.GenerateULID()
+	}
+
+	exists, err := s.entityExists(ctx, "tables", localID)
+	if err != nil {
+		return err
+	}
+
+	// Status meja tetap dikelola outlet karena berubah mengikuti order berjalan
+	if exists {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE tables
+			SET table_number = ?, capacity = ?, cloud_id = COALESCE(?, cloud_id),
+			    managed_by_hq = 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, tableNumber, capacity, toNullString(cloudID), localID)
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO tables (id, table_number, capacity, status, cloud_id, managed_by_hq, created_at, updated_at)
+		VALUES (?, ?, ?, 'available', ?, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, localID, tableNumber, capacity, toNullString(cloudID))
+	return err
+}
+
+// upsertPrinterRouteFromCloud mengatur printer tujuan per kategori.
+// Printer adalah perangkat fisik outlet, jadi HQ cukup mengirim printer_type
+// dan outlet memilih printer aktif pertama dengan tipe tersebut.
+func (s *syncService) upsertPrinterRouteFromCloud(ctx context.Context, data map[string]interface{}) error {
+	categoryID := getString(data, "category_id")
+	if categoryID == "" {
+		categoryID = getString(data, "category_cloud_id")
+	}
+	if categoryID == "" {
+		return fmt.Errorf("category_id is required")
+	}
+	if mappedID, err := s.findLocalIDByCloudID(ctx, "categories", categoryID); err == nil && mappedID != "" {
+		categoryID = mappedID
+	}
+
+	printerID := getString(data, "printer_id")
+	if printerID == "" {
+		printerType := getString(data, "printer_type")
+		if printerType == "" {
+			return fmt.Errorf("printer_id or printer_type is required")
+		}
+		err := s.db.QueryRowContext(ctx, `
+			SELECT id FROM printers
+			WHERE printer_type = ? AND is_active = 1
+			ORDER BY created_at ASC
+			LIMIT 1
+		`, printerType).Scan(&printerID)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no active %s printer for category %s", printerType, categoryID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE categories
+		SET printer_id = ?, managed_by_hq = 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, printerID, categoryID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("category %s not found", categoryID)
+	}
+	return nil
+}
+
+func (s *syncService) clearPrinterRoute(ctx context.Context, categoryID string) error {
+	if categoryID == "" {
+		return nil
+	}
+	if mappedID, err := s.findLocalIDByCloudID(ctx, "categories", categoryID); err == nil && mappedID != "" {
+		categoryID = mappedID
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE categories
+		SET printer_id = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, categoryID)
+	return err
+}
+
+func (s *syncService) upsertUserFromCloud(ctx context.Context, data map[string]interface{}) error {
+	cloudID := getString(data, "cloud_id")
+	localID := getString(data, "local_id")
+	if localID == "" {
+		localID = getString(data, "id")
+	}
+
+	username := getString(data, "username")
+	if username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	fullName := getString(data, "full_name")
+	if fullName == "" {
+		fullName = username
+	}
+
+	role := getString(data, "role")
+	validRoles := map[string]bool{
+		"admin": true, "waiter": true, "kitchen": true,
+		"bar": true, "cashier": true, "manager": true,
+	}
+	if !validRoles[role] {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+
+	isActive := int64(1)
+	if _, ok := data["is_active"]; ok {
+		isActive = getBoolAsInt64(data, "is_active")
+	}
+
+	// HQ hanya mengirim hash PIN, tidak pernah PIN mentah
+	passwordHash := getString(data, "password_hash")
+
+	if cloudID != "" {
+		existingID, err := s.findLocalIDByCloudID(ctx, "users", cloudID)
+		if err != nil {
+			return err
+		}
+		if existingID != "" {
+			localID = existingID
+		}
+	}
+
+	if localID == "" {
+		err := s.db.QueryRowContext(ctx, "SELECT id FROM users WHERE username = ? LIMIT 1", username).Scan(&localID)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+	}
+
+	exists, err := s.entityExists(ctx, "users", localID)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE users
+			SET username = ?, full_name = ?, role = ?, is_active = ?,
+			    password_hash = COALESCE(?, password_hash), cloud_id = COALESCE(?, cloud_id),
+			    managed_by_hq = 1, updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, username, fullName, role, isActive, toNullString(passwordHash), toNullString(cloudID), localID)
+		return err
+	}
+
+	if passwordHash == "" {
+		return fmt.Errorf("password_hash is required for new user %s", username)
+	}
+
+	if localID == "" || len(localID) != 26 {
+		localID = utils.GenerateULID()
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO users (id, username, password_hash, full_name, role, is_active, cloud_id, managed_by_hq, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+	`, localID, username, passwordHash, fullName, role, isActive, toNullString(cloudID))
+	return err
+}
+
+func (s *syncService) deactivateUserByCloudRef(ctx context.Context, localID, cloudID string) error {
+	if localID == "" && cloudID != "" {
+		foundID, err := s.findLocalIDByCloudID(ctx, "users", cloudID)
+		if err != nil {
+			return err
+		}
+		localID = foundID
+	}
+
+	if localID == "" {
+		return nil
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE users SET is_active = 0, updated_at = CURRENT_TIMESTAMP WHERE id = ?
+	`, localID)
+	return err
+}
+
 func (s *syncService) deleteByCloudRef(ctx context.Context, table, localID, cloudID string) error {
 	if localID == "" && cloudID != "" {
 		foundID, err := s.findLocalIDByCloudID(ctx, table, cloudID)
@@ -584,7 +953,7 @@ func (s *syncService) findLocalIDByCloudID(ctx context.Context, table, cloudID s
 		return "", nil
 	}
 
-	if table != "products" && table != "categories" {
+	if !cloudSyncedTables[table] {
 		return "", fmt.Errorf("unsupported table: %s", table)
 	}
 
@@ -603,7 +972,7 @@ func (s *syncService) entityExists(ctx context.Context, table, id string) (bool,
 	if id == "" {
 		return false, nil
 	}
-	if table != "products" && table != "categories" {
+	if !cloudSyncedTables[table] {
 		return false, fmt.Errorf("unsupported table: %s", table)
 	}
 
@@ -700,6 +1069,27 @@ func getInt64(data map[string]interface{}, key string) int64 {
 	return 0
 }
 
+func getBoolAsInt64(data map[string]interface{}, key string) int64 {
+	if value, ok := data[key]; ok {
+		switch v := value.(type) {
+		case bool:
+			if v {
+				return 1
+			}
+			return 0
+		case string:
+			if v == "true" || v == "1" {
+				return 1
+			}
+			return 0
+		}
+	}
+	if getInt64(data, key) != 0 {
+		return 1
+	}
+	return 0
+}
+
 func toNullString(value string) sql.NullString {
 	return sql.NullString{String: value, Valid: value != ""}
 }
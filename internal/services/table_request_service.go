@@ -0,0 +1,76 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// tableRequestNoteMaxLength membatasi catatan permintaan meja dari tamu maupun staf
+const tableRequestNoteMaxLength = 200
+
+type TableRequestService interface {
+	CreateRequest(ctx context.Context, input repositories.TableRequestInput) (*repositories.TableRequest, bool, error)
+	GetRequest(ctx context.Context, id string) (*repositories.TableRequest, error)
+	ListRequests(ctx context.Context, filter repositories.TableRequestFilter) ([]repositories.TableRequest, error)
+	AcknowledgeRequest(ctx context.Context, id, userID string) (*repositories.TableRequest, error)
+	ResolveRequest(ctx context.Context, id, userID string) (*repositories.TableRequest, error)
+	GetAnalytics(ctx context.Context, from, to time.Time) (*repositories.TableRequestAnalytics, error)
+}
+
+type tableRequestService struct {
+	tableRequestRepo repositories.TableRequestRepository
+}
+
+func NewTableRequestService(tableRequestRepo repositories.TableRequestRepository) TableRequestService {
+	return &tableRequestService{tableRequestRepo: tableRequestRepo}
+}
+
+func (s *tableRequestService) CreateRequest(ctx context.Context, input repositories.TableRequestInput) (*repositories.TableRequest, bool, error) {
+	switch input.RequestType {
+	case repositories.TableRequestBill, repositories.TableRequestWaiter, repositories.TableRequestWater:
+	default:
+		return nil, false, fmt.Errorf("%w: type harus bill, waiter atau water", repositories.ErrInvalidTableRequest)
+	}
+	if input.Source != repositories.TableRequestSourceGuest {
+		input.Source = repositories.TableRequestSourceStaff
+	}
+	input.TableNumber = strings.TrimSpace(input.TableNumber)
+	if input.TableNumber == "" {
+		return nil, false, fmt.Errorf("%w: table_number wajib diisi", repositories.ErrInvalidTableRequest)
+	}
+	input.Note = strings.TrimSpace(input.Note)
+	if utf8.RuneCountInString(input.Note) > tableRequestNoteMaxLength {
+		return nil, false, fmt.Errorf("%w: catatan maksimal %d karakter", repositories.ErrInvalidTableRequest, tableRequestNoteMaxLength)
+	}
+	return s.tableRequestRepo.Create(ctx, input)
+}
+
+func (s *tableRequestService) GetRequest(ctx context.Context, id string) (*repositories.TableRequest, error) {
+	return s.tableRequestRepo.GetByID(ctx, id)
+}
+
+func (s *tableRequestService) ListRequests(ctx context.Context, filter repositories.TableRequestFilter) ([]repositories.TableRequest, error) {
+	switch filter.Status {
+	case "", repositories.TableRequestActive, repositories.TableRequestOpen,
+		repositories.TableRequestAcknowledged, repositories.TableRequestResolved:
+	default:
+		return nil, fmt.Errorf("%w: status harus active, open, acknowledged atau resolved", repositories.ErrInvalidTableRequest)
+	}
+	return s.tableRequestRepo.List(ctx, filter)
+}
+
+func (s *tableRequestService) AcknowledgeRequest(ctx context.Context, id, userID string) (*repositories.TableRequest, error) {
+	return s.tableRequestRepo.Acknowledge(ctx, id, userID)
+}
+
+func (s *tableRequestService) ResolveRequest(ctx context.Context, id, userID string) (*repositories.TableRequest, error) {
+	return s.tableRequestRepo.Resolve(ctx, id, userID)
+}
+
+func (s *tableRequestService) GetAnalytics(ctx context.Context, from, to time.Time) (*repositories.TableRequestAnalytics, error) {
+	return s.tableRequestRepo.Analytics(ctx, from, to)
+}
@@ -4,6 +4,10 @@ import (
 	"backend/internal/db"
 	"backend/internal/repositories"
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
 )
 
 type TableService interface {
@@ -14,10 +18,12 @@ type TableService interface {
 	GetTableByNumber(ctx context.Context, tableNumber string) (*db.Table, error)
 	GetTablesByStatus(ctx context.Context, status string) ([]db.Table, error)
 	UpdateTable(ctx context.Context, id string, tableNumber string, capacity int64) error
-	UpdateTableStatus(ctx context.Context, tableNumber string, status string) error
+	UpdateTableStatus(ctx context.Context, tableNumber string, status string, role string) (*repositories.TableStatusChange, error)
 	DeleteTable(ctx context.Context, id string) error
 	GetAvailableTables(ctx context.Context) ([]db.Table, error)
 	GetOccupiedTables(ctx context.Context) ([]db.Table, error)
+	GetStatusTransitions(ctx context.Context) ([]repositories.TableStatusTransition, error)
+	UpdateStatusTransitions(ctx context.Context, transitions []repositories.TableStatusTransition) ([]repositories.TableStatusTransition, error)
 }
 
 type tableService struct {
@@ -68,8 +74,60 @@ func (s *tableService) UpdateTable(ctx context.Context, id string, tableNumber s
 	return s.tableRepo.Update(ctx, id, tableNumber, capacity)
 }
 
-func (s *tableService) UpdateTableStatus(ctx context.Context, tableNumber string, status string) error {
-	return s.tableRepo.UpdateStatus(ctx, tableNumber, status)
+// UpdateTableStatus memindah status meja secara manual sesuai aturan transisi dan role.
+// Admin boleh melakukan semua transisi yang terdaftar.
+func (s *tableService) UpdateTableStatus(ctx context.Context, tableNumber string, status string, role string) (*repositories.TableStatusChange, error) {
+	table, err := s.tableRepo.FindByNumber(ctx, tableNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", repositories.ErrTableNotFound, tableNumber)
+		}
+		return nil, err
+	}
+
+	change := &repositories.TableStatusChange{
+		TableNumber: table.TableNumber,
+		From:        table.Status,
+		To:          status,
+	}
+	// Input lama "occupied" berarti tamu mulai duduk; abaikan jika meja sudah dipakai
+	if status == repositories.TableStatusOccupied {
+		if repositories.IsTableInUse(table.Status) {
+			change.To = table.Status
+			return change, nil
+		}
+		change.To = repositories.TableStatusSeated
+	}
+	if !repositories.IsValidTableStatus(change.To) {
+		return nil, fmt.Errorf("%w: %s", repositories.ErrInvalidTableStatus, status)
+	}
+	if change.To == table.Status {
+		return change, nil
+	}
+
+	transitions, err := s.tableRepo.ListStatusTransitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var allowed *repositories.TableStatusTransition
+	for i := range transitions {
+		if transitions[i].From == change.From && transitions[i].To == change.To {
+			allowed = &transitions[i]
+			break
+		}
+	}
+	if allowed == nil {
+		return nil, fmt.Errorf("%w: %s ke %s", repositories.ErrTableTransitionNotAllowed, change.From, change.To)
+	}
+	if role != "admin" && !slices.Contains(allowed.Roles, role) {
+		return nil, fmt.Errorf("%w: %s ke %s hanya untuk %v", repositories.ErrTableTransitionForbidden, change.From, change.To, allowed.Roles)
+	}
+
+	if err := s.tableRepo.UpdateStatus(ctx, table.TableNumber, change.From, change.To); err != nil {
+		return nil, err
+	}
+	change.Changed = true
+	return change, nil
 }
 
 func (s *tableService) DeleteTable(ctx context.Context, id string) error {
@@ -83,3 +141,42 @@ func (s *tableService) GetAvailableTables(ctx context.Context) ([]db.Table, erro
 func (s *tableService) GetOccupiedTables(ctx context.Context) ([]db.Table, error) {
 	return s.tableRepo.GetOccupied(ctx)
 }
+
+func (s *tableService) GetStatusTransitions(ctx context.Context) ([]repositories.TableStatusTransition, error) {
+	return s.tableRepo.ListStatusTransitions(ctx)
+}
+
+// UpdateStatusTransitions mengganti seluruh aturan transisi status meja
+func (s *tableService) UpdateStatusTransitions(ctx context.Context, transitions []repositories.TableStatusTransition) ([]repositories.TableStatusTransition, error) {
+	validRoles := map[string]bool{
+		"admin": true, "waiter": true, "kitchen": true,
+		"bar": true, "cashier": true, "manager": true,
+	}
+	seen := make(map[string]bool, len(transitions))
+	for i, transition := range transitions {
+		if !repositories.IsValidTableStatus(transition.From) || !repositories.IsValidTableStatus(transition.To) {
+			return nil, fmt.Errorf("%w: transisi %s ke %s", repositories.ErrInvalidTableStatus, transition.From, transition.To)
+		}
+		if transition.From == transition.To {
+			return nil, fmt.Errorf("%w: status asal dan tujuan sama (%s)", repositories.ErrInvalidTableStatus, transition.From)
+		}
+		key := transition.From + ">" + transition.To
+		if seen[key] {
+			return nil, fmt.Errorf("%w: transisi %s ke %s ganda", repositories.ErrInvalidTableStatus, transition.From, transition.To)
+		}
+		seen[key] = true
+		for _, role := range transition.Roles {
+			if !validRoles[role] {
+				return nil, fmt.Errorf("%w: role %s tidak dikenal", repositories.ErrInvalidTableStatus, role)
+			}
+		}
+		if transitions[i].Roles == nil {
+			transitions[i].Roles = []string{}
+		}
+	}
+
+	if err := s.tableRepo.ReplaceStatusTransitions(ctx, transitions); err != nil {
+		return nil, err
+	}
+	return s.tableRepo.ListStatusTransitions(ctx)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"time"
+)
+
+type TableSessionService interface {
+	ListSessions(ctx context.Context, filter repositories.TableSessionFilter) ([]repositories.TableSession, error)
+	GetSession(ctx context.Context, id string) (*repositories.TableSession, error)
+	GetTimers(ctx context.Context) ([]repositories.TableTimer, error)
+	GetAnalytics(ctx context.Context, from, to time.Time) (*repositories.TableTurnAnalytics, error)
+}
+
+type tableSessionService struct {
+	tableSessionRepo repositories.TableSessionRepository
+}
+
+func NewTableSessionService(tableSessionRepo repositories.TableSessionRepository) TableSessionService {
+	return &tableSessionService{tableSessionRepo: tableSessionRepo}
+}
+
+func (s *tableSessionService) ListSessions(ctx context.Context, filter repositories.TableSessionFilter) ([]repositories.TableSession, error) {
+	return s.tableSessionRepo.List(ctx, filter)
+}
+
+func (s *tableSessionService) GetSession(ctx context.Context, id string) (*repositories.TableSession, error) {
+	return s.tableSessionRepo.GetByID(ctx, id)
+}
+
+func (s *tableSessionService) GetTimers(ctx context.Context) ([]repositories.TableTimer, error) {
+	return s.tableSessionRepo.Timers(ctx, time.Now().UTC())
+}
+
+func (s *tableSessionService) GetAnalytics(ctx context.Context, from, to time.Time) (*repositories.TableTurnAnalytics, error) {
+	return s.tableSessionRepo.Analytics(ctx, from, to)
+}
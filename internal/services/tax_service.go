@@ -0,0 +1,62 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"time"
+)
+
+type TaxService interface {
+	ListTaxRates(ctx context.Context) ([]repositories.TaxRate, error)
+	GetTaxRate(ctx context.Context, id string) (*repositories.TaxRate, error)
+	CreateTaxRate(ctx context.Context, rate *repositories.TaxRate) error
+	UpdateTaxRate(ctx context.Context, rate *repositories.TaxRate) error
+	DeleteTaxRate(ctx context.Context, id string) error
+	GetTaxReport(ctx context.Context, startDate, endDate time.Time) ([]repositories.TaxReportLine, []repositories.TaxReportDay, error)
+}
+
+type taxService struct {
+	taxRepo  repositories.TaxRepository
+	syncRepo repositories.SyncRepository
+}
+
+func NewTaxService(taxRepo repositories.TaxRepository, syncRepo repositories.SyncRepository) TaxService {
+	return &taxService{
+		taxRepo:  taxRepo,
+		syncRepo: syncRepo,
+	}
+}
+
+func (s *taxService) ListTaxRates(ctx context.Context) ([]repositories.TaxRate, error) {
+	return s.taxRepo.List(ctx)
+}
+
+func (s *taxService) GetTaxRate(ctx context.Context, id string) (*repositories.TaxRate, error) {
+	return s.taxRepo.GetByID(ctx, id)
+}
+
+// Perubahan tarif langsung dihitung ulang ke order yang masih terbuka
+func (s *taxService) CreateTaxRate(ctx context.Context, rate *repositories.TaxRate) error {
+	if err := s.taxRepo.Create(ctx, rate); err != nil {
+		return err
+	}
+	return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+}
+
+func (s *taxService) UpdateTaxRate(ctx context.Context, rate *repositories.TaxRate) error {
+	if err := s.taxRepo.Update(ctx, rate); err != nil {
+		return err
+	}
+	return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+}
+
+func (s *taxService) DeleteTaxRate(ctx context.Context, id string) error {
+	if err := s.taxRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	return s.syncRepo.RefreshOpenOrderTotalsForAdditionalCharges(ctx)
+}
+
+func (s *taxService) GetTaxReport(ctx context.Context, startDate, endDate time.Time) ([]repositories.TaxReportLine, []repositories.TaxReportDay, error) {
+	return s.taxRepo.GetTaxReport(ctx, startDate, endDate)
+}
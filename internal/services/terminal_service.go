@@ -0,0 +1,44 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+)
+
+type TerminalService interface {
+	ListTerminals(ctx context.Context, activeOnly bool) ([]repositories.Terminal, error)
+	GetTerminal(ctx context.Context, id string) (*repositories.Terminal, error)
+	CreateTerminal(ctx context.Context, terminal *repositories.Terminal) error
+	UpdateTerminal(ctx context.Context, terminal *repositories.Terminal) error
+	DeleteTerminal(ctx context.Context, id string) error
+}
+
+type terminalService struct {
+	terminalRepo repositories.TerminalRepository
+}
+
+func NewTerminalService(terminalRepo repositories.TerminalRepository) TerminalService {
+	return &terminalService{
+		terminalRepo: terminalRepo,
+	}
+}
+
+func (s *terminalService) ListTerminals(ctx context.Context, activeOnly bool) ([]repositories.Terminal, error) {
+	return s.terminalRepo.List(ctx, activeOnly)
+}
+
+func (s *terminalService) GetTerminal(ctx context.Context, id string) (*repositories.Terminal, error) {
+	return s.terminalRepo.GetByID(ctx, id)
+}
+
+func (s *terminalService) CreateTerminal(ctx context.Context, terminal *repositories.Terminal) error {
+	return s.terminalRepo.Create(ctx, terminal)
+}
+
+func (s *terminalService) UpdateTerminal(ctx context.Context, terminal *repositories.Terminal) error {
+	return s.terminalRepo.Update(ctx, terminal)
+}
+
+func (s *terminalService) DeleteTerminal(ctx context.Context, id string) error {
+	return s.terminalRepo.Delete(ctx, id)
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"backend/internal/repositories"
+	"context"
+	"time"
+)
+
+type TipService interface {
+	GetOrderTips(ctx context.Context, orderID string) ([]repositories.PaymentTip, error)
+	GetStaffReport(ctx context.Context, startDate, endDate time.Time, shiftID string) ([]repositories.TipStaffSummary, error)
+	GetShiftReport(ctx context.Context, startDate, endDate time.Time) ([]repositories.TipShiftSummary, error)
+}
+
+type tipService struct {
+	tipRepo repositories.TipRepository
+}
+
+func NewTipService(tipRepo repositories.TipRepository) TipService {
+	return &tipService{
+		tipRepo: tipRepo,
+	}
+}
+
+func (s *tipService) GetOrderTips(ctx context.Context, orderID string) ([]repositories.PaymentTip, error) {
+	return s.tipRepo.ListByOrder(ctx, orderID)
+}
+
+func (s *tipService) GetStaffReport(ctx context.Context, startDate, endDate time.Time, shiftID string) ([]repositories.TipStaffSummary, error) {
+	return s.tipRepo.GetStaffReport(ctx, startDate, endDate, shiftID)
+}
+
+func (s *tipService) GetShiftReport(ctx context.Context, startDate, endDate time.Time) ([]repositories.TipShiftSummary, error) {
+	return s.tipRepo.GetShiftReport(ctx, startDate, endDate)
+}
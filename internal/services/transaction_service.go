@@ -8,14 +8,15 @@ import (
 )
 
 type TransactionService interface {
-	CreateTransaction(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, items []TransactionItemInput, createdBy string) (*db.Transaction, error)
-	CreateTransactionForOrder(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, createdBy string) (*db.Transaction, error)
+	CreateTransaction(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, items []TransactionItemInput, createdBy, shiftID string) (*db.Transaction, error)
+	CreateTransactionForOrder(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, createdBy, shiftID string) (*db.Transaction, error)
 	GetTransactionByID(ctx context.Context, id string) (*TransactionWithItems, error)
 	GetAllTransactions(ctx context.Context) ([]db.Transaction, error)
 	GetTransactionsPaginated(ctx context.Context, limit, offset int64) ([]db.Transaction, int64, error)
 	GetTransactionsByDateRange(ctx context.Context, startDate, endDate time.Time) ([]db.Transaction, error)
 	GetTransactionsByDateRangePaginated(ctx context.Context, startDate, endDate time.Time, limit, offset int64) ([]db.Transaction, int64, error)
-	CancelTransaction(ctx context.Context, transactionID, managerID, reason string) error
+	CancelTransaction(ctx context.Context, transactionID, managerID, reason, shiftID string) error
+	SetReferenceNumber(ctx context.Context, transactionID, referenceNumber string) error
 }
 
 type TransactionItemInput struct {
@@ -44,32 +45,24 @@ func NewTransactionService(
 	}
 }
 
-func (s *transactionService) CreateTransaction(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, items []TransactionItemInput, createdBy string) (*db.Transaction, error) {
+func (s *transactionService) CreateTransaction(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, items []TransactionItemInput, createdBy, shiftID string) (*db.Transaction, error) {
 	transactionDate := time.Now().UTC()
 
-	transaction, err := s.transactionRepo.Create(ctx, orderID, totalAmount, paymentMethod, "completed", transactionDate, createdBy)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create transaction items
+	repoItems := make([]repositories.TransactionItemInput, 0, len(items))
 	for _, item := range items {
-		_, err := s.transactionRepo.CreateItem(ctx, transaction.ID, item.ProductID, item.Quantity, item.Price)
-		if err != nil {
-			return nil, err
-		}
+		repoItems = append(repoItems, repositories.TransactionItemInput{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Price:     item.Price,
+		})
 	}
 
-	return transaction, nil
+	return s.transactionRepo.CreateForShift(ctx, orderID, totalAmount, paymentMethod, "completed", transactionDate, createdBy, shiftID, repoItems)
 }
 
-func (s *transactionService) CreateTransactionForOrder(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, createdBy string) (*db.Transaction, error) {
+func (s *transactionService) CreateTransactionForOrder(ctx context.Context, orderID string, totalAmount float64, paymentMethod string, createdBy, shiftID string) (*db.Transaction, error) {
 	transactionDate := time.Now().UTC()
-	transaction, err := s.transactionRepo.Create(ctx, orderID, totalAmount, paymentMethod, "completed", transactionDate, createdBy)
-	if err != nil {
-		return nil, err
-	}
-	return transaction, nil
+	return s.transactionRepo.CreateForShift(ctx, orderID, totalAmount, paymentMethod, "completed", transactionDate, createdBy, shiftID, nil)
 }
 
 func (s *transactionService) GetTransactionByID(ctx context.Context, id string) (*TransactionWithItems, error) {
@@ -123,6 +116,10 @@ func (s *transactionService) GetTransactionsByDateRangePaginated(ctx context.Con
 	return transactions, total, nil
 }
 
-func (s *transactionService) CancelTransaction(ctx context.Context, transactionID, managerID, reason string) error {
-	return s.transactionRepo.Cancel(ctx, transactionID, managerID, reason)
+func (s *transactionService) CancelTransaction(ctx context.Context, transactionID, managerID, reason, shiftID string) error {
+	return s.transactionRepo.Cancel(ctx, transactionID, managerID, reason, shiftID)
+}
+
+func (s *transactionService) SetReferenceNumber(ctx context.Context, transactionID, referenceNumber string) error {
+	return s.transactionRepo.SetReferenceNumber(ctx, transactionID, referenceNumber)
 }
@@ -30,6 +30,8 @@ type PrintJobData struct {
 	RetryOf                string             `json:"retry_of,omitempty"`
 	ReceiptNumber          string             `json:"receipt_number"`
 	TableNumber            string             `json:"table_number"`
+	OrderType              string             `json:"order_type,omitempty"`
+	QueueLabel             string             `json:"queue_label,omitempty"`
 	CustomerName           string             `json:"customer_name"`
 	WaiterName             string             `json:"waiter_name"`
 	CashierName            string             `json:"cashier_name"`
@@ -38,17 +40,34 @@ type PrintJobData struct {
 	AdditionalChargesTotal int                `json:"additional_charges_total"`
 	AdditionalCharges      []ReceiptCharge    `json:"additional_charges"`
 	Tax                    int                `json:"tax"`
+	Taxes                  []ReceiptTax       `json:"taxes,omitempty"`
 	Total                  int                `json:"total"`
 	PaymentMethod          string             `json:"payment_method"`
 	PaidAmount             int                `json:"paid_amount"`
+	TipAmount              int                `json:"tip_amount,omitempty"`
 	ChangeAmount           int                `json:"change_amount"`
 	DateTime               time.Time          `json:"datetime"`
 	IsBill                 bool               `json:"is_bill"`
+	QRISPayload            string             `json:"qris_payload,omitempty"`
+	QRISExpiresAt          *time.Time         `json:"qris_expires_at,omitempty"`
 	IsSplitPayment         bool               `json:"is_split_payment"`
 	IsHandover             bool               `json:"is_handover"`
 	IsCloseShift           bool               `json:"is_close_shift"`
 	IsCashInReceipt        bool               `json:"is_cash_in_receipt"`
 	IsCashOutReceipt       bool               `json:"is_cash_out_receipt"`
+	IsRefundReceipt        bool               `json:"is_refund_receipt"`
+	IsDrawerKick           bool               `json:"is_drawer_kick"`
+	IsTableChange          bool               `json:"is_table_change"`
+	TableChangeType        string             `json:"table_change_type,omitempty"`
+	FromOrderID            string             `json:"from_order_id,omitempty"`
+	FromTableNumber        string             `json:"from_table_number,omitempty"`
+	MovedBy                string             `json:"moved_by,omitempty"`
+	KitchenMarker          string             `json:"kitchen_marker,omitempty"`
+	HeldCourses            []string           `json:"held_courses,omitempty"`
+	CustomerAllergies      string             `json:"customer_allergies,omitempty"`
+	CustomerDietaryNotes   string             `json:"customer_dietary_notes,omitempty"`
+	RefundReason           string             `json:"refund_reason,omitempty"`
+	ApprovedBy             string             `json:"approved_by,omitempty"`
 	HandoverFrom           string             `json:"handover_from"`
 	HandoverTo             string             `json:"handover_to"`
 	MovementName           string             `json:"movement_name"`
@@ -59,12 +78,15 @@ type PrintJobData struct {
 	ClosingCard            int                `json:"closing_card"`
 	ClosingQris            int                `json:"closing_qris"`
 	ClosingTransfer        int                `json:"closing_transfer"`
+	ShiftPayments          []ShiftPaymentData `json:"shift_payments,omitempty"`
+	CashCount              *CashCountData     `json:"cash_count,omitempty"`
 	VoidedCount            int                `json:"voided_count"`
 	VoidedTotal            int                `json:"voided_total"`
 	CancelledCount         int                `json:"cancelled_count"`
 	CancelledTotal         int                `json:"cancelled_total"`
 	CashIns                []CashMovementData `json:"cash_ins"`
 	CashOuts               []CashMovementData `json:"cash_outs"`
+	SalesReport            *SalesReportData   `json:"sales_report,omitempty"`
 }
 
 type CashMovementData struct {
@@ -72,12 +94,72 @@ type CashMovementData struct {
 	Amount int    `json:"amount"`
 }
 
+// ShiftPaymentData is the shift sales total for one payment method
+type ShiftPaymentData struct {
+	Name   string `json:"name"`
+	Amount int    `json:"amount"`
+}
+
+// CashCountData is the physical drawer count printed on shift close/handover
+type CashCountData struct {
+	ExpectedCash  int                    `json:"expected_cash"`
+	CountedCash   int                    `json:"counted_cash"`
+	Variance      int                    `json:"variance"`
+	ApprovedBy    string                 `json:"approved_by,omitempty"`
+	Denominations []CashDenominationData `json:"denominations,omitempty"`
+}
+
+type CashDenominationData struct {
+	Denomination int `json:"denomination"`
+	Qty          int `json:"qty"`
+}
+
+// SalesReportData is the X-report (snapshot) or Z-report (end of day) printout
+type SalesReportData struct {
+	ReportType     string                 `json:"report_type"`
+	ZNumber        int                    `json:"z_number,omitempty"`
+	BusinessDate   string                 `json:"business_date"`
+	PeriodStart    time.Time              `json:"period_start"`
+	PeriodEnd      time.Time              `json:"period_end"`
+	PrintedBy      string                 `json:"printed_by"`
+	IsReprint      bool                   `json:"is_reprint"`
+	OrdersCount    int                    `json:"orders_count"`
+	Pax            int                    `json:"pax"`
+	GrossSales     int                    `json:"gross_sales"`
+	Discounts      int                    `json:"discounts"`
+	Charges        int                    `json:"charges"`
+	Taxes          int                    `json:"taxes"`
+	NetSales       int                    `json:"net_sales"`
+	RefundsCount   int                    `json:"refunds_count"`
+	Refunds        int                    `json:"refunds"`
+	VoidsCount     int                    `json:"voids_count"`
+	Voids          int                    `json:"voids"`
+	CancelledCount int                    `json:"cancelled_count"`
+	Cancelled      int                    `json:"cancelled"`
+	Tips           int                    `json:"tips"`
+	PaymentsTotal  int                    `json:"payments_total"`
+	CashVariance   int                    `json:"cash_variance"`
+	DiscountLines  []ReceiptCharge        `json:"discount_lines,omitempty"`
+	ChargeLines    []ReceiptCharge        `json:"charge_lines,omitempty"`
+	TaxLines       []ReceiptTax           `json:"tax_lines,omitempty"`
+	Payments       []ShiftPaymentData     `json:"payments,omitempty"`
+	Shifts         []SalesReportShiftData `json:"shifts,omitempty"`
+}
+
+// SalesReportShiftData is a closed cashier shift and its drawer variance
+type SalesReportShiftData struct {
+	CashierName string `json:"cashier_name"`
+	Counted     bool   `json:"counted"`
+	Variance    int    `json:"variance"`
+}
+
 // ReceiptItem represents a single item on the receipt
 type ReceiptItem struct {
 	Name     string `json:"name"`
 	Quantity int    `json:"quantity"`
 	Price    int    `json:"price"`
 	Total    int    `json:"total"`
+	Course   string `json:"course,omitempty"`
 }
 
 type ReceiptCharge struct {
@@ -85,6 +167,14 @@ type ReceiptCharge struct {
 	Amount int    `json:"amount"`
 }
 
+// ReceiptTax represents a tax line on the receipt
+type ReceiptTax struct {
+	Name        string  `json:"name"`
+	Rate        float64 `json:"rate"`
+	Amount      int     `json:"amount"`
+	IsInclusive bool    `json:"is_inclusive"`
+}
+
 // NewPrintWorker creates a new print worker
 func NewPrintWorker(database *sql.DB, outlet printer.OutletConfig) *PrintWorker {
 	hostname, err := os.Hostname()
@@ -224,7 +314,8 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 
 	// Get printer info
 	printerRow := w.db.QueryRow(`
-		SELECT name, ip_address, port, printer_type, paper_size, is_active 
+		SELECT name, ip_address, port, printer_type, paper_size, is_active,
+		       drawer_enabled, drawer_pin, drawer_on_ms, drawer_off_ms
 		FROM printers 
 		WHERE id = ?
 	`, printerID)
@@ -232,8 +323,10 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 	var printerName, ipAddress, printerType, paperSize string
 	var port int
 	var isActive int
+	var drawerEnabled, drawerPin, drawerOnMs, drawerOffMs int
 
-	err := printerRow.Scan(&printerName, &ipAddress, &port, &printerType, &paperSize, &isActive)
+	err := printerRow.Scan(&printerName, &ipAddress, &port, &printerType, &paperSize, &isActive,
+		&drawerEnabled, &drawerPin, &drawerOnMs, &drawerOffMs)
 	if err != nil {
 		w.markJobFailed(jobID, fmt.Sprintf("Printer not found: %v", err))
 		return
@@ -252,10 +345,24 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 		w.markJobFailed(jobID, fmt.Sprintf("Invalid job data: %v", err))
 		return
 	}
-	if jobData.OrderID != "" && !jobData.IsBill && !jobData.IsHandover && !jobData.IsCloseShift && !jobData.IsCashInReceipt && !jobData.IsCashOutReceipt && printerType != "kitchen" && printerType != "bar" {
+	// Drawer kick hanya berisi pulsa ESC p, tanpa mencetak apa pun
+	if jobData.IsDrawerKick {
+		if drawerEnabled != 1 {
+			w.markJobFailed(jobID, fmt.Sprintf("Cash drawer is not enabled on printer '%s'", printerName))
+			return
+		}
+		kick := append(append([]byte{}, printer.ESC_INIT...), printer.DrawerKick(drawerPin, drawerOnMs, drawerOffMs)...)
+		if err := printer.SendToPrinter(ipAddress, port, kick); err != nil {
+			w.incrementRetry(jobID, err.Error())
+			return
+		}
+		w.markJobDone(jobID)
+		return
+	}
+	if jobData.OrderID != "" && !jobData.IsBill && !jobData.IsHandover && !jobData.IsCloseShift && !jobData.IsCashInReceipt && !jobData.IsCashOutReceipt && !jobData.IsRefundReceipt && printerType != "kitchen" && printerType != "bar" {
 		var paymentTime sql.NullTime
 		var paymentCreatedBy sql.NullString
-		_ = w.db.QueryRow(`SELECT created_at, created_by FROM payments WHERE order_id = ? ORDER BY created_at DESC LIMIT 1`, jobData.OrderID).Scan(&paymentTime, &paymentCreatedBy)
+		_ = w.db.QueryRow(`SELECT created_at, created_by FROM payments WHERE order_id = ? AND amount > 0 ORDER BY created_at DESC LIMIT 1`, jobData.OrderID).Scan(&paymentTime, &paymentCreatedBy)
 
 		var transactionTime sql.NullTime
 		var transactionCreatedBy sql.NullString
@@ -326,6 +433,34 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 		}
 		return printItems
 	}
+	toPrinterCashCount := func(count *CashCountData) *printer.CashCountData {
+		if count == nil {
+			return nil
+		}
+		printCount := &printer.CashCountData{
+			ExpectedCash: count.ExpectedCash,
+			CountedCash:  count.CountedCash,
+			Variance:     count.Variance,
+			ApprovedBy:   count.ApprovedBy,
+		}
+		for _, item := range count.Denominations {
+			printCount.Denominations = append(printCount.Denominations, printer.CashDenominationData{
+				Denomination: item.Denomination,
+				Qty:          item.Qty,
+			})
+		}
+		return printCount
+	}
+	toPrinterShiftPayments := func(items []ShiftPaymentData) []printer.ShiftPaymentData {
+		printItems := make([]printer.ShiftPaymentData, 0, len(items))
+		for _, item := range items {
+			printItems = append(printItems, printer.ShiftPaymentData{
+				Name:   item.Name,
+				Amount: item.Amount,
+			})
+		}
+		return printItems
+	}
 	toPrinterCharges := func(items []ReceiptCharge) []printer.ReceiptCharge {
 		printItems := make([]printer.ReceiptCharge, 0, len(items))
 		for _, item := range items {
@@ -336,8 +471,64 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 		}
 		return printItems
 	}
+	toPrinterTaxes := func(items []ReceiptTax) []printer.ReceiptTax {
+		printItems := make([]printer.ReceiptTax, 0, len(items))
+		for _, item := range items {
+			printItems = append(printItems, printer.ReceiptTax{
+				Name:        item.Name,
+				Rate:        item.Rate,
+				Amount:      item.Amount,
+				IsInclusive: item.IsInclusive,
+			})
+		}
+		return printItems
+	}
 
-	if jobData.IsHandover {
+	toPrinterSalesReport := func(report *SalesReportData) printer.SalesReportData {
+		shifts := make([]printer.SalesReportShiftData, 0, len(report.Shifts))
+		for _, shift := range report.Shifts {
+			shifts = append(shifts, printer.SalesReportShiftData{
+				CashierName: shift.CashierName,
+				Counted:     shift.Counted,
+				Variance:    shift.Variance,
+			})
+		}
+		return printer.SalesReportData{
+			ReportType:     report.ReportType,
+			ZNumber:        report.ZNumber,
+			BusinessDate:   report.BusinessDate,
+			PeriodStart:    report.PeriodStart.In(time.Local),
+			PeriodEnd:      report.PeriodEnd.In(time.Local),
+			PrintedBy:      report.PrintedBy,
+			IsReprint:      report.IsReprint,
+			OrdersCount:    report.OrdersCount,
+			Pax:            report.Pax,
+			GrossSales:     report.GrossSales,
+			Discounts:      report.Discounts,
+			Charges:        report.Charges,
+			Taxes:          report.Taxes,
+			NetSales:       report.NetSales,
+			RefundsCount:   report.RefundsCount,
+			Refunds:        report.Refunds,
+			VoidsCount:     report.VoidsCount,
+			Voids:          report.Voids,
+			CancelledCount: report.CancelledCount,
+			Cancelled:      report.Cancelled,
+			Tips:           report.Tips,
+			PaymentsTotal:  report.PaymentsTotal,
+			CashVariance:   report.CashVariance,
+			DiscountLines:  toPrinterCharges(report.DiscountLines),
+			ChargeLines:    toPrinterCharges(report.ChargeLines),
+			TaxLines:       toPrinterTaxes(report.TaxLines),
+			Payments:       toPrinterShiftPayments(report.Payments),
+			Shifts:         shifts,
+			DateTime:       jobData.DateTime,
+		}
+	}
+
+	if jobData.SalesReport != nil {
+		receiptData = formatter.FormatSalesReport(toPrinterSalesReport(jobData.SalesReport))
+	} else if jobData.IsHandover {
 		handoverPayload := printer.HandoverReceiptData{
 			ReceiptNumber:   jobData.ReceiptNumber,
 			CashierFrom:     jobData.HandoverFrom,
@@ -347,6 +538,9 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 			ClosingCard:     jobData.ClosingCard,
 			ClosingQris:     jobData.ClosingQris,
 			ClosingTransfer: jobData.ClosingTransfer,
+			ShiftPayments:   toPrinterShiftPayments(jobData.ShiftPayments),
+			TipTotal:        jobData.TipAmount,
+			CashCount:       toPrinterCashCount(jobData.CashCount),
 			VoidedCount:     jobData.VoidedCount,
 			VoidedTotal:     jobData.VoidedTotal,
 			CancelledCount:  jobData.CancelledCount,
@@ -365,6 +559,9 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 			ClosingCard:     jobData.ClosingCard,
 			ClosingQris:     jobData.ClosingQris,
 			ClosingTransfer: jobData.ClosingTransfer,
+			ShiftPayments:   toPrinterShiftPayments(jobData.ShiftPayments),
+			TipTotal:        jobData.TipAmount,
+			CashCount:       toPrinterCashCount(jobData.CashCount),
 			VoidedCount:     jobData.VoidedCount,
 			VoidedTotal:     jobData.VoidedTotal,
 			CancelledCount:  jobData.CancelledCount,
@@ -393,6 +590,47 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 			DateTime:      jobData.DateTime,
 		}
 		receiptData = formatter.FormatCashOutReceipt(cashOutPayload)
+	} else if jobData.IsRefundReceipt {
+		refundItems := make([]printer.ReceiptItem, len(jobData.Items))
+		for i, item := range jobData.Items {
+			refundItems[i] = printer.ReceiptItem{
+				Name:     item.Name,
+				Quantity: item.Quantity,
+				Price:    item.Price,
+				Total:    item.Total,
+			}
+		}
+		refundPayload := printer.RefundReceiptData{
+			ReceiptNumber: jobData.ReceiptNumber,
+			OrderNumber:   jobData.OrderID,
+			TableNumber:   jobData.TableNumber,
+			CashierName:   jobData.CashierName,
+			ApprovedBy:    jobData.ApprovedBy,
+			Items:         refundItems,
+			RefundMethod:  jobData.PaymentMethod,
+			Reason:        jobData.RefundReason,
+			Amount:        jobData.Total,
+			DateTime:      jobData.DateTime,
+		}
+		receiptData = formatter.FormatRefundReceipt(refundPayload)
+	} else if jobData.IsTableChange {
+		changeItems := make([]printer.ReceiptItem, len(jobData.Items))
+		for i, item := range jobData.Items {
+			changeItems[i] = printer.ReceiptItem{
+				Name:     item.Name,
+				Quantity: item.Quantity,
+			}
+		}
+		receiptData = formatter.FormatTableChange(printer.TableChangeData{
+			ChangeType:      jobData.TableChangeType,
+			FromOrderID:     jobData.FromOrderID,
+			ToOrderID:       jobData.OrderID,
+			FromTableNumber: jobData.FromTableNumber,
+			ToTableNumber:   jobData.TableNumber,
+			MovedBy:         jobData.MovedBy,
+			Items:           changeItems,
+			DateTime:        jobData.DateTime,
+		})
 	} else if printerType == "kitchen" || printerType == "bar" {
 		// Kitchen/Bar format - simple order list
 		// Convert to printer.ReceiptItem
@@ -403,16 +641,24 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 				Quantity: item.Quantity,
 				Price:    item.Price,
 				Total:    item.Total,
+				Course:   item.Course,
 			}
 		}
-		receiptData = formatter.FormatKitchenOrder(
-			printerName,
-			jobData.ReceiptNumber,
-			jobData.TableNumber,
-			jobData.WaiterName,
-			printerItems,
-			jobData.DateTime,
-		)
+		receiptData = formatter.FormatKitchenOrder(printer.KitchenTicketData{
+			HeaderTitle:  printerName,
+			OrderNumber:  jobData.ReceiptNumber,
+			TableName:    jobData.TableNumber,
+			OrderType:    jobData.OrderType,
+			QueueLabel:   jobData.QueueLabel,
+			WaiterName:   jobData.WaiterName,
+			Marker:       jobData.KitchenMarker,
+			HeldCourses:  jobData.HeldCourses,
+			CustomerName: jobData.CustomerName,
+			Allergies:    jobData.CustomerAllergies,
+			DietaryNotes: jobData.CustomerDietaryNotes,
+			Items:        printerItems,
+			DateTime:     jobData.DateTime,
+		})
 	} else {
 		printerItems := make([]printer.ReceiptItem, len(jobData.Items))
 		for i, item := range jobData.Items {
@@ -426,6 +672,8 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 		receiptPayload := printer.ReceiptData{
 			ReceiptNumber:          jobData.ReceiptNumber,
 			TableNumber:            jobData.TableNumber,
+			OrderType:              jobData.OrderType,
+			QueueLabel:             jobData.QueueLabel,
 			CustomerName:           jobData.CustomerName,
 			WaiterName:             jobData.WaiterName,
 			CashierName:            jobData.CashierName,
@@ -434,11 +682,15 @@ func (w *PrintWorker) processJob(jobID, printerID string, dataJSON string, retry
 			AdditionalChargesTotal: jobData.AdditionalChargesTotal,
 			AdditionalCharges:      toPrinterCharges(jobData.AdditionalCharges),
 			Tax:                    jobData.Tax,
+			Taxes:                  toPrinterTaxes(jobData.Taxes),
 			Total:                  jobData.Total,
 			PaymentMethod:          jobData.PaymentMethod,
 			PaidAmount:             jobData.PaidAmount,
+			TipAmount:              jobData.TipAmount,
 			ChangeAmount:           jobData.ChangeAmount,
 			DateTime:               jobData.DateTime,
+			QRISPayload:            jobData.QRISPayload,
+			QRISExpiresAt:          jobData.QRISExpiresAt,
 		}
 		if jobData.IsBill {
 			receiptData = formatter.FormatBill(receiptPayload)
@@ -0,0 +1,73 @@
+package workers
+
+import (
+	"backend/internal/repositories"
+	"backend/internal/services"
+	"context"
+	"log"
+	"time"
+)
+
+// EventEmitter mengirim event realtime ke klien (socket.io)
+type EventEmitter interface {
+	Emit(event string, payload map[string]interface{})
+}
+
+// ReservationWorker menjalankan transisi otomatis reservasi: meja ditandai reserved
+// menjelang slot dan dilepas setelah toleransi no-show
+type ReservationWorker struct {
+	reservationService services.ReservationService
+	realtime           EventEmitter
+	interval           time.Duration
+}
+
+// NewReservationWorker creates a new reservation worker
+func NewReservationWorker(reservationService services.ReservationService, realtime EventEmitter) *ReservationWorker {
+	return &ReservationWorker{
+		reservationService: reservationService,
+		realtime:           realtime,
+		interval:           time.Minute,
+	}
+}
+
+// Start runs the worker until the context is cancelled
+func (w *ReservationWorker) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.process(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.process(ctx)
+		}
+	}
+}
+
+func (w *ReservationWorker) process(ctx context.Context) {
+	result, err := w.reservationService.ProcessTransitions(ctx)
+	if err != nil {
+		log.Printf("Reservation worker failed: %v", err)
+		return
+	}
+	if len(result.NoShowIDs) > 0 {
+		log.Printf("📅 %d reservation(s) marked as no-show", len(result.NoShowIDs))
+	}
+	if w.realtime == nil {
+		return
+	}
+	for _, id := range result.NoShowIDs {
+		w.realtime.Emit("reservation_updated", map[string]interface{}{
+			"reservation_id": id,
+			"status":         repositories.ReservationStatusNoShow,
+		})
+	}
+	tableNumbers := append(append([]string{}, result.ReservedTables...), result.ReleasedTables...)
+	if len(tableNumbers) > 0 {
+		w.realtime.Emit("table_status_updated", map[string]interface{}{
+			"table_numbers": tableNumbers,
+		})
+	}
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"backend/pkg/utils"
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
 	"path/filepath"
@@ -93,7 +94,7 @@ func runMigrations(db *sql.DB) error {
 			id TEXT PRIMARY KEY CHECK (length(id) = 26),
 			table_number TEXT NOT NULL UNIQUE,
 			capacity INTEGER NOT NULL DEFAULT 4 CHECK (capacity > 0),
-			status TEXT NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'occupied', 'reserved')),
+			status TEXT NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'reserved', 'seated', 'ordered', 'bill_requested', 'paid', 'dirty')),
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
@@ -205,10 +206,11 @@ func runMigrations(db *sql.DB) error {
 		CREATE TABLE IF NOT EXISTS payments (
 			id TEXT PRIMARY KEY CHECK (length(id) = 26),
 			order_id TEXT NOT NULL,
-			amount REAL NOT NULL CHECK (amount > 0),
-			payment_method TEXT NOT NULL CHECK (payment_method IN ('cash', 'card', 'qris', 'transfer')),
+			amount REAL NOT NULL CHECK (amount <> 0),
+			payment_method TEXT NOT NULL,
 			payment_note TEXT,
 			created_by TEXT NOT NULL,
+			refund_of TEXT,
 			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
 			FOREIGN KEY (created_by) REFERENCES users(id)
@@ -354,217 +356,921 @@ func runMigrations(db *sql.DB) error {
 
 		CREATE INDEX IF NOT EXISTS idx_additional_charges_active ON additional_charges(is_active);
 
-		-- Tabel antrian sinkronisasi
-		CREATE TABLE IF NOT EXISTS sync_queue (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			entity_type TEXT NOT NULL,
-			entity_id TEXT NOT NULL,
-			operation TEXT NOT NULL,
-			payload TEXT NOT NULL,
-			status TEXT DEFAULT 'pending',
-			retry_count INTEGER DEFAULT 0,
-			max_retries INTEGER DEFAULT 3,
-			error_message TEXT,
-			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-			processed_at DATETIME,
-			synced_at DATETIME
+		-- Versi menu / daftar harga terjadwal
+		CREATE TABLE IF NOT EXISTS price_lists (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			name TEXT NOT NULL,
+			description TEXT NOT NULL DEFAULT '',
+			effective_from DATETIME NOT NULL,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_by TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
 
-		CREATE INDEX IF NOT EXISTS idx_sync_queue_status ON sync_queue(status);
-		CREATE INDEX IF NOT EXISTS idx_sync_queue_entity ON sync_queue(entity_type, entity_id);
+		-- Harga per produk; start_time/end_time (HH:MM) kosong berarti berlaku sepanjang hari
+		CREATE TABLE IF NOT EXISTS price_list_items (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			price_list_id TEXT NOT NULL,
+			product_id TEXT NOT NULL,
+			day_part TEXT NOT NULL DEFAULT '',
+			start_time TEXT NOT NULL DEFAULT '',
+			end_time TEXT NOT NULL DEFAULT '',
+			price REAL NOT NULL CHECK (price >= 0),
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (price_list_id) REFERENCES price_lists(id) ON DELETE CASCADE,
+			FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+		);
 
-		-- Tabel log sinkronisasi
-		CREATE TABLE IF NOT EXISTS sync_logs (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			sync_type TEXT NOT NULL,
-			entity_type TEXT NOT NULL,
-			entity_count INTEGER DEFAULT 0,
-			status TEXT NOT NULL,
-			error_message TEXT,
-			started_at DATETIME NOT NULL,
-			completed_at DATETIME,
-			duration_ms INTEGER,
-			details TEXT
+		CREATE INDEX IF NOT EXISTS idx_price_lists_effective ON price_lists(is_active, effective_from);
+		CREATE INDEX IF NOT EXISTS idx_price_list_items_list ON price_list_items(price_list_id);
+		CREATE INDEX IF NOT EXISTS idx_price_list_items_product ON price_list_items(product_id);
+
+		-- Promosi: diskon persentase/nominal, buy-X-get-Y, bundle harga tetap.
+		-- scope menentukan target (order/category/product), target_ids berisi JSON array id.
+		-- Jendela waktu (start_time/end_time HH:MM, days_of_week "1,2,..", 0 = Minggu) untuk happy hour.
+		CREATE TABLE IF NOT EXISTS promotions (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			name TEXT NOT NULL,
+			promo_type TEXT NOT NULL CHECK (promo_type IN ('percentage_off', 'fixed_off', 'buy_x_get_y', 'bundle')),
+			scope TEXT NOT NULL DEFAULT 'order' CHECK (scope IN ('order', 'category', 'product')),
+			target_ids TEXT NOT NULL DEFAULT '[]',
+			value REAL NOT NULL DEFAULT 0 CHECK (value >= 0),
+			buy_qty INTEGER NOT NULL DEFAULT 0,
+			get_qty INTEGER NOT NULL DEFAULT 0,
+			min_spend REAL NOT NULL DEFAULT 0,
+			start_at DATETIME,
+			end_at DATETIME,
+			days_of_week TEXT NOT NULL DEFAULT '',
+			start_time TEXT NOT NULL DEFAULT '',
+			end_time TEXT NOT NULL DEFAULT '',
+			requires_voucher INTEGER NOT NULL DEFAULT 0,
+			priority INTEGER NOT NULL DEFAULT 0,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);
-	`
 
-	_, err := db.Exec(schema)
-	if err != nil {
-		return err
-	}
+		-- Kode voucher untuk promosi; usage_limit 0 berarti tanpa batas
+		CREATE TABLE IF NOT EXISTS vouchers (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			code TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			promotion_id TEXT NOT NULL,
+			usage_limit INTEGER NOT NULL DEFAULT 0,
+			expires_at DATETIME,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE CASCADE
+		);
 
-	var passwordHashExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('users') 
-		WHERE name='password_hash'
-	`).Scan(&passwordHashExists)
-	if err != nil {
-		return err
-	}
-	if passwordHashExists == 0 {
-		_, err = db.Exec("ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT ''")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added password_hash column to users table")
-	}
+		CREATE TABLE IF NOT EXISTS order_vouchers (
+			order_id TEXT NOT NULL,
+			voucher_id TEXT NOT NULL,
+			applied_by TEXT,
+			applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (order_id, voucher_id),
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+			FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE
+		);
 
-	var fullNameExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('users') 
-		WHERE name='full_name'
-	`).Scan(&fullNameExists)
-	if err != nil {
-		return err
-	}
-	if fullNameExists == 0 {
-		_, err = db.Exec("ALTER TABLE users ADD COLUMN full_name TEXT NOT NULL DEFAULT ''")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added full_name column to users table")
-	}
+		CREATE INDEX IF NOT EXISTS idx_promotions_active ON promotions(is_active);
+		CREATE INDEX IF NOT EXISTS idx_vouchers_promotion ON vouchers(promotion_id);
+		CREATE INDEX IF NOT EXISTS idx_order_vouchers_voucher ON order_vouchers(voucher_id);
 
-	var roleExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('users') 
-		WHERE name='role'
-	`).Scan(&roleExists)
-	if err != nil {
-		return err
-	}
-	if roleExists == 0 {
-		_, err = db.Exec("ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'admin' CHECK (role IN ('admin', 'waiter', 'kitchen', 'bar', 'cashier', 'manager'))")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added role column to users table")
-	}
+		-- Tarif pajak bernama (PB1, PPN). is_inclusive: harga jual sudah termasuk pajak.
+		-- include_service_charge: service charge ikut menjadi dasar pengenaan pajak.
+		CREATE TABLE IF NOT EXISTS tax_rates (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			name TEXT NOT NULL,
+			rate REAL NOT NULL CHECK (rate > 0 AND rate <= 100),
+			is_inclusive INTEGER NOT NULL DEFAULT 0,
+			include_service_charge INTEGER NOT NULL DEFAULT 1,
+			is_default INTEGER NOT NULL DEFAULT 0,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
 
-	var isActiveExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('users') 
-		WHERE name='is_active'
-	`).Scan(&isActiveExists)
-	if err != nil {
-		return err
-	}
-	if isActiveExists == 0 {
-		_, err = db.Exec("ALTER TABLE users ADD COLUMN is_active INTEGER NOT NULL DEFAULT 1")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added is_active column to users table")
-	}
+		-- Penugasan tarif pajak ke kategori/produk; penugasan produk mengalahkan kategori
+		CREATE TABLE IF NOT EXISTS tax_rate_assignments (
+			tax_rate_id TEXT NOT NULL,
+			target_type TEXT NOT NULL CHECK (target_type IN ('category', 'product')),
+			target_id TEXT NOT NULL,
+			PRIMARY KEY (tax_rate_id, target_type, target_id),
+			FOREIGN KEY (tax_rate_id) REFERENCES tax_rates(id) ON DELETE CASCADE
+		);
 
-	var customerPhoneExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('orders') 
-		WHERE name='customer_phone'
-	`).Scan(&customerPhoneExists)
-	if err != nil {
-		return err
-	}
-	if customerPhoneExists == 0 {
-		_, err = db.Exec("ALTER TABLE orders ADD COLUMN customer_phone TEXT")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added customer_phone column to orders table")
-	}
+		-- Snapshot pajak per order (dihitung ulang setiap total order berubah)
+		CREATE TABLE IF NOT EXISTS order_taxes (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			order_id TEXT NOT NULL,
+			tax_rate_id TEXT,
+			name TEXT NOT NULL,
+			rate REAL NOT NULL,
+			is_inclusive INTEGER NOT NULL DEFAULT 0,
+			taxable_amount REAL NOT NULL,
+			tax_amount REAL NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+		);
 
-	var customerIDExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('orders') 
-		WHERE name='customer_id'
-	`).Scan(&customerIDExists)
-	if err != nil {
-		return err
-	}
-	if customerIDExists == 0 {
-		_, err = db.Exec("ALTER TABLE orders ADD COLUMN customer_id TEXT")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added customer_id column to orders table")
-	}
+		CREATE INDEX IF NOT EXISTS idx_tax_rate_assignments_target ON tax_rate_assignments(target_type, target_id);
+		CREATE INDEX IF NOT EXISTS idx_order_taxes_order ON order_taxes(order_id);
 
-	var createdByExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('orders') 
-		WHERE name='created_by'
-	`).Scan(&createdByExists)
-	if err != nil {
-		return err
-	}
-	if createdByExists == 0 {
-		_, err = db.Exec("ALTER TABLE orders ADD COLUMN created_by TEXT")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added created_by column to orders table")
-	}
+		-- Refund setelah pembayaran. Setiap refund juga dicatat sebagai payment negatif
+		-- (payment_id) yang menunjuk ke pembayaran asal lewat payments.refund_of.
+		CREATE TABLE IF NOT EXISTS refunds (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			order_id TEXT NOT NULL,
+			payment_id TEXT NOT NULL,
+			original_payment_id TEXT,
+			refund_type TEXT NOT NULL CHECK (refund_type IN ('items', 'amount')),
+			refund_method TEXT NOT NULL CHECK (refund_method IN ('cash', 'card', 'transfer')),
+			amount REAL NOT NULL CHECK (amount > 0),
+			reason TEXT NOT NULL,
+			restock INTEGER NOT NULL DEFAULT 0,
+			approved_by TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+			FOREIGN KEY (approved_by) REFERENCES users(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
 
-	var voidedAtExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('orders') 
-		WHERE name='voided_at'
-	`).Scan(&voidedAtExists)
-	if err != nil {
-		return err
-	}
-	if voidedAtExists == 0 {
-		_, err = db.Exec("ALTER TABLE orders ADD COLUMN voided_at DATETIME")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added voided_at column to orders table")
-	}
+		CREATE TABLE IF NOT EXISTS refund_items (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			refund_id TEXT NOT NULL,
+			order_item_id TEXT NOT NULL,
+			product_id TEXT,
+			product_name TEXT NOT NULL,
+			qty INTEGER NOT NULL CHECK (qty > 0),
+			price REAL NOT NULL,
+			amount REAL NOT NULL,
+			FOREIGN KEY (refund_id) REFERENCES refunds(id) ON DELETE CASCADE
+		);
 
-	var voidedByExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('orders') 
-		WHERE name='voided_by'
-	`).Scan(&voidedByExists)
-	if err != nil {
-		return err
-	}
-	if voidedByExists == 0 {
-		_, err = db.Exec("ALTER TABLE orders ADD COLUMN voided_by TEXT")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added voided_by column to orders table")
-	}
+		CREATE INDEX IF NOT EXISTS idx_refunds_order ON refunds(order_id);
+		CREATE INDEX IF NOT EXISTS idx_refunds_created_at ON refunds(created_at);
+		CREATE INDEX IF NOT EXISTS idx_refund_items_refund ON refund_items(refund_id);
+		CREATE INDEX IF NOT EXISTS idx_refund_items_order_item ON refund_items(order_item_id);
 
-	var voidReasonExists int
-	err = db.QueryRow(`
-		SELECT COUNT(*) 
-		FROM pragma_table_info('orders') 
-		WHERE name='void_reason'
-	`).Scan(&voidReasonExists)
-	if err != nil {
-		return err
-	}
-	if voidReasonExists == 0 {
-		_, err = db.Exec("ALTER TABLE orders ADD COLUMN void_reason TEXT")
-		if err != nil {
-			return err
-		}
-		log.Println("✅ Added void_reason column to orders table")
-	}
+		-- Tabel intent pembayaran via payment provider (QRIS dinamis, dll.)
+		CREATE TABLE IF NOT EXISTS payment_intents (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			order_id TEXT NOT NULL,
+			provider TEXT NOT NULL,
+			provider_ref TEXT,
+			payment_method TEXT NOT NULL DEFAULT 'qris',
+			amount REAL NOT NULL CHECK (amount > 0),
+			qr_string TEXT,
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'paid', 'expired', 'cancelled', 'failed')),
+			expires_at DATETIME,
+			paid_at DATETIME,
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
 
-	var cancelledAtExists int
-	err = db.QueryRow(`
+		CREATE INDEX IF NOT EXISTS idx_payment_intents_order ON payment_intents(order_id);
+		CREATE INDEX IF NOT EXISTS idx_payment_intents_status ON payment_intents(status);
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_intents_provider_ref ON payment_intents(provider, provider_ref);
+
+		-- Master metode pembayaran. payments/transactions menyimpan kode metode (code).
+		-- counts_to_drawer menandai metode yang masuk laci kas (ikut carry over shift).
+		CREATE TABLE IF NOT EXISTS payment_methods (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			code TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			method_type TEXT NOT NULL CHECK (method_type IN ('cash', 'card', 'qris', 'transfer', 'ewallet', 'account', 'other')),
+			requires_reference INTEGER NOT NULL DEFAULT 0,
+			counts_to_drawer INTEGER NOT NULL DEFAULT 0,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			is_system INTEGER NOT NULL DEFAULT 0,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Rekap penjualan per metode pembayaran saat shift kasir ditutup/serah terima
+		CREATE TABLE IF NOT EXISTS cashier_shift_payments (
+			shift_id TEXT NOT NULL,
+			payment_method TEXT NOT NULL,
+			method_name TEXT NOT NULL,
+			method_type TEXT NOT NULL,
+			counts_to_drawer INTEGER NOT NULL DEFAULT 0,
+			amount REAL NOT NULL DEFAULT 0,
+			PRIMARY KEY (shift_id, payment_method),
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id) ON DELETE CASCADE
+		);
+
+		-- Rincian pecahan uang yang dihitung kasir saat tutup shift / serah terima
+		CREATE TABLE IF NOT EXISTS cashier_shift_counts (
+			shift_id TEXT NOT NULL,
+			denomination INTEGER NOT NULL CHECK (denomination > 0),
+			qty INTEGER NOT NULL CHECK (qty >= 0),
+			amount REAL NOT NULL DEFAULT 0,
+			PRIMARY KEY (shift_id, denomination),
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id) ON DELETE CASCADE
+		);
+
+		-- Tip/gratuity per pembayaran. Disimpan terpisah dari transaksi sehingga
+		-- tidak ikut omzet maupun dasar perhitungan service charge.
+		CREATE TABLE IF NOT EXISTS payment_tips (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			order_id TEXT NOT NULL,
+			transaction_id TEXT NOT NULL,
+			shift_id TEXT,
+			payment_method TEXT NOT NULL,
+			amount REAL NOT NULL CHECK (amount > 0),
+			recipient_type TEXT NOT NULL DEFAULT 'waiter' CHECK (recipient_type IN ('waiter', 'pool')),
+			staff_id TEXT,
+			created_by TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE,
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id),
+			FOREIGN KEY (staff_id) REFERENCES users(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_payment_tips_order ON payment_tips(order_id);
+		CREATE INDEX IF NOT EXISTS idx_payment_tips_shift ON payment_tips(shift_id);
+		CREATE INDEX IF NOT EXISTS idx_payment_tips_staff ON payment_tips(staff_id, created_at);
+
+		-- Penutupan hari bisnis (Z report) dengan nomor Z berurutan. summary menyimpan
+		-- ringkasan penjualan dalam JSON yang dibekukan saat hari ditutup.
+		CREATE TABLE IF NOT EXISTS z_reports (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			z_number INTEGER NOT NULL UNIQUE,
+			business_date TEXT NOT NULL UNIQUE,
+			period_start DATETIME NOT NULL,
+			period_end DATETIME NOT NULL,
+			orders_count INTEGER NOT NULL DEFAULT 0,
+			net_sales REAL NOT NULL DEFAULT 0,
+			payments_total REAL NOT NULL DEFAULT 0,
+			summary TEXT NOT NULL,
+			closed_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (closed_by) REFERENCES users(id)
+		);
+
+		-- Terminal kasir (stasiun + laci kas). Setiap terminal punya shift sendiri;
+		-- device_id menautkan terminal ke perangkat kasir yang terdaftar.
+		CREATE TABLE IF NOT EXISTS cashier_terminals (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			code TEXT NOT NULL UNIQUE,
+			name TEXT NOT NULL,
+			device_id TEXT UNIQUE,
+			printer_id TEXT,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (printer_id) REFERENCES printers(id) ON DELETE SET NULL
+		);
+
+		-- Log audit setiap pembukaan laci kas (otomatis maupun manual)
+		CREATE TABLE IF NOT EXISTS cash_drawer_events (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			printer_id TEXT,
+			terminal_id TEXT,
+			shift_id TEXT,
+			source TEXT NOT NULL CHECK (source IN ('payment', 'split_payment', 'cash_in', 'cash_out', 'manual')),
+			reference_id TEXT,
+			reason TEXT,
+			opened_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (printer_id) REFERENCES printers(id) ON DELETE SET NULL,
+			FOREIGN KEY (terminal_id) REFERENCES cashier_terminals(id),
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id),
+			FOREIGN KEY (opened_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_cash_drawer_events_created ON cash_drawer_events(created_at);
+		CREATE INDEX IF NOT EXISTS idx_cash_drawer_events_shift ON cash_drawer_events(shift_id);
+
+		-- Deposit/DP diterima di muka untuk pelanggan atau order besar. Saldo deposit
+		-- adalah kewajiban (bukan penjualan) sampai dipakai melunasi order atau direfund.
+		CREATE TABLE IF NOT EXISTS deposits (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			customer_id TEXT,
+			order_id TEXT,
+			amount REAL NOT NULL CHECK (amount > 0),
+			balance REAL NOT NULL CHECK (balance >= 0),
+			payment_method TEXT NOT NULL,
+			reference_number TEXT,
+			note TEXT,
+			status TEXT NOT NULL DEFAULT 'held' CHECK (status IN ('held', 'applied', 'refunded')),
+			shift_id TEXT,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (customer_id) REFERENCES customers(id),
+			FOREIGN KEY (order_id) REFERENCES orders(id),
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_deposits_customer ON deposits(customer_id, status);
+		CREATE INDEX IF NOT EXISTS idx_deposits_order ON deposits(order_id);
+
+		-- Jejak audit deposit: diterima, dipakai untuk order, direfund
+		CREATE TABLE IF NOT EXISTS deposit_events (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			deposit_id TEXT NOT NULL,
+			event_type TEXT NOT NULL CHECK (event_type IN ('received', 'applied', 'refunded')),
+			amount REAL NOT NULL CHECK (amount > 0),
+			payment_method TEXT NOT NULL,
+			order_id TEXT,
+			transaction_id TEXT,
+			shift_id TEXT,
+			reason TEXT,
+			approved_by TEXT,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (deposit_id) REFERENCES deposits(id),
+			FOREIGN KEY (order_id) REFERENCES orders(id),
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id),
+			FOREIGN KEY (approved_by) REFERENCES users(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_deposit_events_deposit ON deposit_events(deposit_id);
+		CREATE INDEX IF NOT EXISTS idx_deposit_events_shift ON deposit_events(shift_id);
+		CREATE INDEX IF NOT EXISTS idx_deposit_events_created ON deposit_events(created_at);
+
+		-- Gift card / dompet saldo pelanggan. Penjualan dan top up bukan pendapatan;
+		-- saldo adalah kewajiban sampai ditukar sebagai metode pembayaran gift_card.
+		CREATE TABLE IF NOT EXISTS gift_cards (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			code TEXT NOT NULL UNIQUE,
+			customer_id TEXT,
+			balance REAL NOT NULL DEFAULT 0 CHECK (balance >= 0),
+			status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'disabled')),
+			note TEXT,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (customer_id) REFERENCES customers(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_gift_cards_customer ON gift_cards(customer_id);
+
+		-- Mutasi saldo gift card: issue/topup (uang masuk), redeem (dipakai bayar),
+		-- reversal (redeem dikembalikan karena transaksi batal). amount selalu positif.
+		CREATE TABLE IF NOT EXISTS gift_card_ledger (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			gift_card_id TEXT NOT NULL,
+			entry_type TEXT NOT NULL CHECK (entry_type IN ('issue', 'topup', 'redeem', 'reversal')),
+			amount REAL NOT NULL CHECK (amount > 0),
+			balance_after REAL NOT NULL,
+			payment_method TEXT,
+			order_id TEXT,
+			transaction_id TEXT,
+			shift_id TEXT,
+			reversal_of TEXT UNIQUE,
+			note TEXT,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (gift_card_id) REFERENCES gift_cards(id),
+			FOREIGN KEY (reversal_of) REFERENCES gift_card_ledger(id),
+			FOREIGN KEY (order_id) REFERENCES orders(id),
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+			FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_gift_card_ledger_card ON gift_card_ledger(gift_card_id);
+		CREATE INDEX IF NOT EXISTS idx_gift_card_ledger_shift ON gift_card_ledger(shift_id);
+		CREATE INDEX IF NOT EXISTS idx_gift_card_ledger_transaction ON gift_card_ledger(transaction_id);
+		CREATE INDEX IF NOT EXISTS idx_gift_card_ledger_created ON gift_card_ledger(created_at);
+
+		-- Split check: tagihan terpisah dalam satu order. split_mode items = isi check dari
+		-- item order (order_check_items), amount = nominal tetap (bagi rata/per nominal).
+		-- Status paying menahan check selama pembayarannya diproses.
+		CREATE TABLE IF NOT EXISTS order_checks (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			order_id TEXT NOT NULL,
+			check_number INTEGER NOT NULL,
+			name TEXT NOT NULL,
+			seat_number INTEGER,
+			split_mode TEXT NOT NULL CHECK (split_mode IN ('items', 'amount')),
+			amount REAL NOT NULL DEFAULT 0 CHECK (amount >= 0),
+			status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'paying', 'paid')),
+			paid_amount REAL NOT NULL DEFAULT 0,
+			transaction_id TEXT,
+			paid_at DATETIME,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (order_id, check_number),
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+			FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_order_checks_order ON order_checks(order_id);
+
+		CREATE TABLE IF NOT EXISTS order_check_items (
+			check_id TEXT NOT NULL,
+			order_item_id TEXT NOT NULL,
+			qty INTEGER NOT NULL CHECK (qty > 0),
+			PRIMARY KEY (check_id, order_item_id),
+			FOREIGN KEY (check_id) REFERENCES order_checks(id) ON DELETE CASCADE,
+			FOREIGN KEY (order_item_id) REFERENCES order_items(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_order_check_items_item ON order_check_items(order_item_id);
+
+		-- Reservasi meja: slot waktu reserved_at s/d reserved_at + duration_minutes.
+		-- Meja berstatus reserved menjelang slot, reservasi yang tidak datang menjadi no_show.
+		CREATE TABLE IF NOT EXISTS reservations (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			customer_id TEXT NOT NULL,
+			pax INTEGER NOT NULL CHECK (pax > 0),
+			reserved_at DATETIME NOT NULL,
+			duration_minutes INTEGER NOT NULL CHECK (duration_minutes > 0),
+			ends_at DATETIME NOT NULL,
+			table_id TEXT,
+			status TEXT NOT NULL DEFAULT 'booked' CHECK (status IN ('booked', 'seated', 'cancelled', 'no_show')),
+			order_id TEXT,
+			note TEXT,
+			cancel_reason TEXT,
+			seated_at DATETIME,
+			cancelled_at DATETIME,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (customer_id) REFERENCES customers(id),
+			FOREIGN KEY (table_id) REFERENCES tables(id),
+			FOREIGN KEY (order_id) REFERENCES orders(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_reservations_slot ON reservations(status, reserved_at);
+		CREATE INDEX IF NOT EXISTS idx_reservations_table ON reservations(table_id, status, reserved_at);
+		CREATE INDEX IF NOT EXISTS idx_reservations_customer ON reservations(customer_id);
+
+		-- Daftar tunggu tamu walk-in dengan estimasi waktu tunggu saat didaftarkan
+		CREATE TABLE IF NOT EXISTS waitlist_entries (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			customer_id TEXT,
+			customer_name TEXT NOT NULL,
+			customer_phone TEXT,
+			pax INTEGER NOT NULL CHECK (pax > 0),
+			quoted_wait_minutes INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'waiting' CHECK (status IN ('waiting', 'notified', 'seated', 'left')),
+			table_id TEXT,
+			order_id TEXT,
+			note TEXT,
+			notified_at DATETIME,
+			seated_at DATETIME,
+			left_at DATETIME,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (customer_id) REFERENCES customers(id),
+			FOREIGN KEY (table_id) REFERENCES tables(id),
+			FOREIGN KEY (order_id) REFERENCES orders(id),
+			FOREIGN KEY (created_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_waitlist_status ON waitlist_entries(status, created_at);
+
+		-- Denah lantai: area (indoor, outdoor, VIP, lantai 2), section pelayan dan gabungan
+		-- meja. Posisi/bentuk meja dan area/section-nya disimpan sebagai kolom tambahan tables.
+		CREATE TABLE IF NOT EXISTS floor_areas (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			name TEXT NOT NULL UNIQUE,
+			description TEXT,
+			sort_order INTEGER NOT NULL DEFAULT 0,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Aturan service charge per area: biaya tambahan tidak berlaku (is_exempt) atau
+		-- memakai tarif area sebagai pengganti tarif global
+		CREATE TABLE IF NOT EXISTS floor_area_charge_rules (
+			area_id TEXT NOT NULL,
+			charge_id INTEGER NOT NULL,
+			is_exempt INTEGER NOT NULL DEFAULT 0,
+			charge_type TEXT CHECK (charge_type IN ('percentage', 'fixed')),
+			value REAL,
+			PRIMARY KEY (area_id, charge_id),
+			FOREIGN KEY (area_id) REFERENCES floor_areas(id) ON DELETE CASCADE,
+			FOREIGN KEY (charge_id) REFERENCES additional_charges(id) ON DELETE CASCADE
+		);
+
+		CREATE TABLE IF NOT EXISTS floor_sections (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			name TEXT NOT NULL UNIQUE,
+			area_id TEXT,
+			color TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (area_id) REFERENCES floor_areas(id)
+		);
+
+		CREATE TABLE IF NOT EXISTS floor_section_waiters (
+			section_id TEXT NOT NULL,
+			user_id TEXT NOT NULL,
+			assigned_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (section_id, user_id),
+			FOREIGN KEY (section_id) REFERENCES floor_sections(id) ON DELETE CASCADE,
+			FOREIGN KEY (user_id) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_floor_section_waiters_user ON floor_section_waiters(user_id);
+
+		-- Set meja yang bisa digabung; order gabungan memakai nomor meja utama
+		CREATE TABLE IF NOT EXISTS table_combinations (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			name TEXT NOT NULL UNIQUE,
+			primary_table_id TEXT NOT NULL,
+			is_active INTEGER NOT NULL DEFAULT 1,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (primary_table_id) REFERENCES tables(id)
+		);
+
+		CREATE TABLE IF NOT EXISTS table_combination_tables (
+			combination_id TEXT NOT NULL,
+			table_id TEXT NOT NULL,
+			PRIMARY KEY (combination_id, table_id),
+			FOREIGN KEY (combination_id) REFERENCES table_combinations(id) ON DELETE CASCADE,
+			FOREIGN KEY (table_id) REFERENCES tables(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_table_combination_tables_table ON table_combination_tables(table_id);
+
+		-- Pengaturan tipe order (dine-in, takeaway, delivery, pickup): biaya kemasan dan prefix antrian
+		CREATE TABLE IF NOT EXISTS order_type_settings (
+			order_type TEXT PRIMARY KEY CHECK (order_type IN ('dine_in', 'takeaway', 'delivery', 'pickup')),
+			is_active INTEGER NOT NULL DEFAULT 1,
+			packaging_fee REAL NOT NULL DEFAULT 0 CHECK (packaging_fee >= 0),
+			packaging_fee_mode TEXT NOT NULL DEFAULT 'per_item' CHECK (packaging_fee_mode IN ('per_item', 'per_order')),
+			queue_prefix TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+
+		-- Aturan biaya tambahan per tipe order (mis. takeaway bebas service charge)
+		CREATE TABLE IF NOT EXISTS order_type_charge_rules (
+			order_type TEXT NOT NULL,
+			charge_id INTEGER NOT NULL,
+			is_exempt INTEGER NOT NULL DEFAULT 0,
+			charge_type TEXT CHECK (charge_type IN ('percentage', 'fixed')),
+			value REAL,
+			PRIMARY KEY (order_type, charge_id),
+			FOREIGN KEY (order_type) REFERENCES order_type_settings(order_type) ON DELETE CASCADE,
+			FOREIGN KEY (charge_id) REFERENCES additional_charges(id) ON DELETE CASCADE
+		);
+
+		-- Nomor antrian harian untuk order tanpa meja
+		CREATE TABLE IF NOT EXISTS order_queue_counters (
+			queue_date TEXT PRIMARY KEY,
+			last_number INTEGER NOT NULL DEFAULT 0
+		);
+
+		-- Riwayat perpindahan meja: pindah order, pindah item, gabung dan pisah meja.
+		-- table_change_items mencatat item yang berpindah beserta order asalnya,
+		-- dipakai untuk mengembalikan item saat gabungan meja dipisah lagi.
+		CREATE TABLE IF NOT EXISTS table_changes (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			change_type TEXT NOT NULL CHECK (change_type IN ('transfer', 'move_items', 'merge', 'unmerge')),
+			from_order_id TEXT NOT NULL,
+			to_order_id TEXT NOT NULL,
+			from_table_number TEXT NOT NULL,
+			to_table_number TEXT NOT NULL,
+			created_by TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_table_changes_from_order ON table_changes(from_order_id);
+		CREATE INDEX IF NOT EXISTS idx_table_changes_to_order ON table_changes(to_order_id, change_type);
+
+		CREATE TABLE IF NOT EXISTS table_change_items (
+			change_id TEXT NOT NULL,
+			order_item_id TEXT NOT NULL,
+			product_name TEXT NOT NULL,
+			qty INTEGER NOT NULL CHECK (qty > 0),
+			PRIMARY KEY (change_id, order_item_id),
+			FOREIGN KEY (change_id) REFERENCES table_changes(id) ON DELETE CASCADE
+		);
+
+		-- Pesanan mandiri tamu lewat QR meja. Item disalin dengan harga saat dipesan;
+		-- pesanan diteruskan ke order meja setelah disetujui waiter (atau langsung jika
+		-- persetujuan tidak diwajibkan).
+		CREATE TABLE IF NOT EXISTS guest_order_requests (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			table_number TEXT NOT NULL,
+			order_id TEXT,
+			customer_name TEXT,
+			pax INTEGER NOT NULL DEFAULT 1 CHECK (pax > 0),
+			note TEXT,
+			status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+			reject_reason TEXT,
+			client_ip TEXT,
+			reviewed_by TEXT,
+			reviewed_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL,
+			FOREIGN KEY (reviewed_by) REFERENCES users(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_guest_order_requests_status ON guest_order_requests(status, created_at);
+		CREATE INDEX IF NOT EXISTS idx_guest_order_requests_table ON guest_order_requests(table_number, status);
+
+		CREATE TABLE IF NOT EXISTS guest_order_request_items (
+			request_id TEXT NOT NULL,
+			line_no INTEGER NOT NULL,
+			product_id TEXT NOT NULL,
+			product_name TEXT NOT NULL,
+			qty INTEGER NOT NULL CHECK (qty > 0),
+			price REAL NOT NULL,
+			course TEXT,
+			PRIMARY KEY (request_id, line_no),
+			FOREIGN KEY (request_id) REFERENCES guest_order_requests(id) ON DELETE CASCADE
+		);
+
+		-- Sesi pemakaian meja dari tamu duduk sampai meja kosong lagi. Kolom *_at menyimpan
+		-- waktu pertama tiap tahap; table_session_events menyimpan riwayat lengkapnya.
+		CREATE TABLE IF NOT EXISTS table_sessions (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			table_id TEXT,
+			table_number TEXT NOT NULL,
+			area_id TEXT,
+			order_id TEXT,
+			pax INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+			seated_at DATETIME NOT NULL,
+			first_order_at DATETIME,
+			bill_printed_at DATETIME,
+			paid_at DATETIME,
+			cleared_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_table_sessions_table ON table_sessions(table_number, status);
+		CREATE INDEX IF NOT EXISTS idx_table_sessions_seated ON table_sessions(seated_at);
+
+		CREATE TABLE IF NOT EXISTS table_session_events (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			session_id TEXT NOT NULL,
+			event TEXT NOT NULL CHECK (event IN ('seated', 'first_order', 'bill_printed', 'paid', 'cleared', 'transferred')),
+			order_id TEXT,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (session_id) REFERENCES table_sessions(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_table_session_events_session ON table_session_events(session_id, created_at);
+
+		-- Aturan perpindahan status meja; roles dipisah koma, admin selalu boleh
+		CREATE TABLE IF NOT EXISTS table_status_transitions (
+			from_status TEXT NOT NULL,
+			to_status TEXT NOT NULL,
+			roles TEXT NOT NULL DEFAULT '',
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (from_status, to_status)
+		);
+
+		-- Permintaan dari meja (minta bill, panggil pelayan, minta air) oleh tamu lewat QR
+		-- atau staf. Waktu acknowledge/selesai dipakai untuk analitik waktu respon staf.
+		CREATE TABLE IF NOT EXISTS table_requests (
+			id TEXT PRIMARY KEY CHECK (length(id) = 26),
+			table_id TEXT,
+			table_number TEXT NOT NULL,
+			section_id TEXT,
+			request_type TEXT NOT NULL CHECK (request_type IN ('bill', 'waiter', 'water')),
+			source TEXT NOT NULL DEFAULT 'staff' CHECK (source IN ('guest', 'staff')),
+			note TEXT,
+			status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'acknowledged', 'resolved')),
+			created_by TEXT,
+			client_ip TEXT,
+			acknowledged_by TEXT,
+			acknowledged_at DATETIME,
+			resolved_by TEXT,
+			resolved_at DATETIME,
+			created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE INDEX IF NOT EXISTS idx_table_requests_status ON table_requests(status, created_at);
+		CREATE INDEX IF NOT EXISTS idx_table_requests_table ON table_requests(table_number, status);
+
+		-- Kunjungan pelanggan: satu baris per order lunas. Dipakai supaya statistik lifetime
+		-- di customers (total_visits, total_spent) hanya ditambah sekali per order.
+		CREATE TABLE IF NOT EXISTS customer_visits (
+			order_id TEXT PRIMARY KEY,
+			customer_id TEXT NOT NULL,
+			amount REAL NOT NULL DEFAULT 0,
+			visited_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY (order_id) REFERENCES orders(id),
+			FOREIGN KEY (customer_id) REFERENCES customers(id)
+		);
+		CREATE INDEX IF NOT EXISTS idx_customer_visits_customer ON customer_visits(customer_id, visited_at);
+
+		-- Tabel antrian sinkronisasi
+		CREATE TABLE IF NOT EXISTS sync_queue (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			entity_type TEXT NOT NULL,
+			entity_id TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			payload TEXT NOT NULL,
+			status TEXT DEFAULT 'pending',
+			retry_count INTEGER DEFAULT 0,
+			max_retries INTEGER DEFAULT 3,
+			error_message TEXT,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			processed_at DATETIME,
+			synced_at DATETIME
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_sync_queue_status ON sync_queue(status);
+		CREATE INDEX IF NOT EXISTS idx_sync_queue_entity ON sync_queue(entity_type, entity_id);
+
+		-- Tabel log sinkronisasi
+		CREATE TABLE IF NOT EXISTS sync_logs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			sync_type TEXT NOT NULL,
+			entity_type TEXT NOT NULL,
+			entity_count INTEGER DEFAULT 0,
+			status TEXT NOT NULL,
+			error_message TEXT,
+			started_at DATETIME NOT NULL,
+			completed_at DATETIME,
+			duration_ms INTEGER,
+			details TEXT
+		);
+	`
+
+	_, err := db.Exec(schema)
+	if err != nil {
+		return err
+	}
+
+	var passwordHashExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('users') 
+		WHERE name='password_hash'
+	`).Scan(&passwordHashExists)
+	if err != nil {
+		return err
+	}
+	if passwordHashExists == 0 {
+		_, err = db.Exec("ALTER TABLE users ADD COLUMN password_hash TEXT NOT NULL DEFAULT ''")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added password_hash column to users table")
+	}
+
+	var fullNameExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('users') 
+		WHERE name='full_name'
+	`).Scan(&fullNameExists)
+	if err != nil {
+		return err
+	}
+	if fullNameExists == 0 {
+		_, err = db.Exec("ALTER TABLE users ADD COLUMN full_name TEXT NOT NULL DEFAULT ''")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added full_name column to users table")
+	}
+
+	var roleExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('users') 
+		WHERE name='role'
+	`).Scan(&roleExists)
+	if err != nil {
+		return err
+	}
+	if roleExists == 0 {
+		_, err = db.Exec("ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'admin' CHECK (role IN ('admin', 'waiter', 'kitchen', 'bar', 'cashier', 'manager'))")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added role column to users table")
+	}
+
+	var isActiveExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('users') 
+		WHERE name='is_active'
+	`).Scan(&isActiveExists)
+	if err != nil {
+		return err
+	}
+	if isActiveExists == 0 {
+		_, err = db.Exec("ALTER TABLE users ADD COLUMN is_active INTEGER NOT NULL DEFAULT 1")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added is_active column to users table")
+	}
+
+	var customerPhoneExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('orders') 
+		WHERE name='customer_phone'
+	`).Scan(&customerPhoneExists)
+	if err != nil {
+		return err
+	}
+	if customerPhoneExists == 0 {
+		_, err = db.Exec("ALTER TABLE orders ADD COLUMN customer_phone TEXT")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added customer_phone column to orders table")
+	}
+
+	var customerIDExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('orders') 
+		WHERE name='customer_id'
+	`).Scan(&customerIDExists)
+	if err != nil {
+		return err
+	}
+	if customerIDExists == 0 {
+		_, err = db.Exec("ALTER TABLE orders ADD COLUMN customer_id TEXT")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added customer_id column to orders table")
+	}
+
+	var createdByExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('orders') 
+		WHERE name='created_by'
+	`).Scan(&createdByExists)
+	if err != nil {
+		return err
+	}
+	if createdByExists == 0 {
+		_, err = db.Exec("ALTER TABLE orders ADD COLUMN created_by TEXT")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added created_by column to orders table")
+	}
+
+	var voidedAtExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('orders') 
+		WHERE name='voided_at'
+	`).Scan(&voidedAtExists)
+	if err != nil {
+		return err
+	}
+	if voidedAtExists == 0 {
+		_, err = db.Exec("ALTER TABLE orders ADD COLUMN voided_at DATETIME")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added voided_at column to orders table")
+	}
+
+	var voidedByExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('orders') 
+		WHERE name='voided_by'
+	`).Scan(&voidedByExists)
+	if err != nil {
+		return err
+	}
+	if voidedByExists == 0 {
+		_, err = db.Exec("ALTER TABLE orders ADD COLUMN voided_by TEXT")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added voided_by column to orders table")
+	}
+
+	var voidReasonExists int
+	err = db.QueryRow(`
+		SELECT COUNT(*) 
+		FROM pragma_table_info('orders') 
+		WHERE name='void_reason'
+	`).Scan(&voidReasonExists)
+	if err != nil {
+		return err
+	}
+	if voidReasonExists == 0 {
+		_, err = db.Exec("ALTER TABLE orders ADD COLUMN void_reason TEXT")
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Added void_reason column to orders table")
+	}
+
+	var cancelledAtExists int
+	err = db.QueryRow(`
 		SELECT COUNT(*) 
 		FROM pragma_table_info('transactions') 
 		WHERE name='cancelled_at'
@@ -1567,7 +2273,264 @@ func runMigrations(db *sql.DB) error {
 				voided_by TEXT,
 				void_reason TEXT,
 				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-				updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+				updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			)
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(`
+			INSERT INTO orders_new (
+				id,
+				table_number,
+				customer_name,
+				customer_phone,
+				customer_id,
+				pax,
+				basket_size,
+				total_amount,
+				paid_amount,
+				order_status,
+				created_by,
+				payment_status,
+				merged_from,
+				is_merged,
+				voided_at,
+				voided_by,
+				void_reason,
+				created_at,
+				updated_at
+			)
+			SELECT
+				id,
+				table_number,
+				customer_name,
+				customer_phone,
+				customer_id,
+				pax,
+				basket_size,
+				total_amount,
+				paid_amount,
+				order_status,
+				created_by,
+				payment_status,
+				merged_from,
+				is_merged,
+				voided_at,
+				voided_by,
+				void_reason,
+				created_at,
+				updated_at
+			FROM orders
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("DROP TABLE orders")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("ALTER TABLE orders_new RENAME TO orders")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at)")
+		if err != nil {
+			return err
+		}
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_table_number ON orders(table_number)")
+		if err != nil {
+			return err
+		}
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(order_status)")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("PRAGMA foreign_keys = ON")
+		if err != nil {
+			return err
+		}
+
+		log.Println("✅ Orders table migrated to new order_id format")
+	}
+
+	// Kolom sinkronisasi HQ: cloud_id untuk mapping entitas cloud dan managed_by_hq
+	// untuk mengunci field yang dikelola terpusat dari perubahan di outlet.
+	hqSyncColumns := []struct {
+		table      string
+		column     string
+		definition string
+	}{
+		{"products", "cloud_id", "TEXT"},
+		{"products", "version", "INTEGER"},
+		{"products", "sync_status", "TEXT"},
+		{"products", "last_synced_at", "DATETIME"},
+		{"products", "managed_by_hq", "INTEGER NOT NULL DEFAULT 0"},
+		{"categories", "cloud_id", "TEXT"},
+		{"categories", "version", "INTEGER"},
+		{"categories", "sync_status", "TEXT"},
+		{"categories", "last_synced_at", "DATETIME"},
+		{"categories", "managed_by_hq", "INTEGER NOT NULL DEFAULT 0"},
+		{"additional_charges", "cloud_id", "TEXT"},
+		{"additional_charges", "managed_by_hq", "INTEGER NOT NULL DEFAULT 0"},
+		{"tables", "cloud_id", "TEXT"},
+		{"tables", "managed_by_hq", "INTEGER NOT NULL DEFAULT 0"},
+		{"users", "cloud_id", "TEXT"},
+		{"users", "managed_by_hq", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, col := range hqSyncColumns {
+		if err := ensureColumn(db, col.table, col.column, col.definition); err != nil {
+			return err
+		}
+	}
+	for _, table := range []string{"products", "categories", "additional_charges", "tables", "users"} {
+		_, err = db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_cloud_id ON %s(cloud_id)", table, table))
+		if err != nil {
+			return err
+		}
+	}
+
+	// product_id di order_items dipakai evaluasi promosi per produk/kategori;
+	// promotion_id menandai baris diskon promosi di order_additional_charges.
+	if err := ensureColumn(db, "order_items", "product_id", "TEXT"); err != nil {
+		return err
+	}
+	if err := ensureColumn(db, "order_additional_charges", "promotion_id", "TEXT"); err != nil {
+		return err
+	}
+	_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_order_additional_charges_promotion ON order_additional_charges(promotion_id)")
+	if err != nil {
+		return err
+	}
+
+	// Refund dicatat sebagai payment bernilai negatif, jadi CHECK (amount > 0)
+	// lama harus dilonggarkan. SQLite tidak bisa mengubah CHECK sehingga tabel dibangun ulang.
+	var paymentsSchema string
+	err = db.QueryRow(`
+		SELECT sql
+		FROM sqlite_master
+		WHERE type='table' AND name='payments'
+	`).Scan(&paymentsSchema)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if strings.Contains(paymentsSchema, "CHECK (amount > 0)") {
+		log.Println("🔄 Migrating payments table to support refunds...")
+
+		_, err = db.Exec("PRAGMA foreign_keys = OFF")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(`
+			CREATE TABLE payments_new (
+				id TEXT PRIMARY KEY CHECK (length(id) = 26),
+				order_id TEXT NOT NULL,
+				amount REAL NOT NULL CHECK (amount <> 0),
+				payment_method TEXT NOT NULL,
+				payment_note TEXT,
+				created_by TEXT NOT NULL,
+				refund_of TEXT,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+				FOREIGN KEY (created_by) REFERENCES users(id)
+			)
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(`
+			INSERT INTO payments_new (
+				id,
+				order_id,
+				amount,
+				payment_method,
+				payment_note,
+				created_by,
+				created_at
+			)
+			SELECT
+				id,
+				order_id,
+				amount,
+				payment_method,
+				payment_note,
+				created_by,
+				created_at
+			FROM payments
+		`)
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("DROP TABLE payments")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("ALTER TABLE payments_new RENAME TO payments")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id)")
+		if err != nil {
+			return err
+		}
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_created_at ON payments(created_at)")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec("PRAGMA foreign_keys = ON")
+		if err != nil {
+			return err
+		}
+
+		log.Println("✅ Payments table migrated to support refunds")
+	}
+
+	if err := ensureColumn(db, "transactions", "reference_number", "TEXT"); err != nil {
+		return err
+	}
+
+	// Metode pembayaran kini dinamis (tabel payment_methods), CHECK payment_method lama dibuang.
+	err = db.QueryRow(`
+		SELECT sql
+		FROM sqlite_master
+		WHERE type='table' AND name='payments'
+	`).Scan(&paymentsSchema)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if strings.Contains(paymentsSchema, "payment_method IN (") {
+		log.Println("🔄 Migrating payments table to dynamic payment methods...")
+
+		_, err = db.Exec("PRAGMA foreign_keys = OFF")
+		if err != nil {
+			return err
+		}
+
+		_, err = db.Exec(`
+			CREATE TABLE payments_new (
+				id TEXT PRIMARY KEY CHECK (length(id) = 26),
+				order_id TEXT NOT NULL,
+				amount REAL NOT NULL CHECK (amount <> 0),
+				payment_method TEXT NOT NULL,
+				payment_note TEXT,
+				created_by TEXT NOT NULL,
+				refund_of TEXT,
+				created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+				FOREIGN KEY (created_by) REFERENCES users(id)
 			)
 		`)
 		if err != nil {
@@ -1575,82 +2538,315 @@ func runMigrations(db *sql.DB) error {
 		}
 
 		_, err = db.Exec(`
-			INSERT INTO orders_new (
+			INSERT INTO payments_new (
 				id,
-				table_number,
-				customer_name,
-				customer_phone,
-				customer_id,
-				pax,
-				basket_size,
-				total_amount,
-				paid_amount,
-				order_status,
+				order_id,
+				amount,
+				payment_method,
+				payment_note,
 				created_by,
-				payment_status,
-				merged_from,
-				is_merged,
-				voided_at,
-				voided_by,
-				void_reason,
-				created_at,
-				updated_at
+				refund_of,
+				created_at
 			)
 			SELECT
 				id,
-				table_number,
-				customer_name,
-				customer_phone,
-				customer_id,
-				pax,
-				basket_size,
-				total_amount,
-				paid_amount,
-				order_status,
+				order_id,
+				amount,
+				payment_method,
+				payment_note,
 				created_by,
-				payment_status,
-				merged_from,
-				is_merged,
-				voided_at,
-				voided_by,
-				void_reason,
-				created_at,
-				updated_at
-			FROM orders
+				refund_of,
+				created_at
+			FROM payments
 		`)
 		if err != nil {
 			return err
 		}
 
-		_, err = db.Exec("DROP TABLE orders")
+		_, err = db.Exec("DROP TABLE payments")
 		if err != nil {
 			return err
 		}
 
-		_, err = db.Exec("ALTER TABLE orders_new RENAME TO orders")
+		_, err = db.Exec("ALTER TABLE payments_new RENAME TO payments")
 		if err != nil {
 			return err
 		}
 
-		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_created_at ON orders(created_at)")
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id)")
 		if err != nil {
 			return err
 		}
-		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_table_number ON orders(table_number)")
+		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_payments_created_at ON payments(created_at)")
 		if err != nil {
 			return err
 		}
-		_, err = db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(order_status)")
+
+		_, err = db.Exec("PRAGMA foreign_keys = ON")
 		if err != nil {
 			return err
 		}
 
-		_, err = db.Exec("PRAGMA foreign_keys = ON")
-		if err != nil {
+		log.Println("✅ Payments table migrated to dynamic payment methods")
+	}
+
+	if err := seedPaymentMethods(db); err != nil {
+		return err
+	}
+
+	// Hitung kas fisik saat tutup shift / serah terima (blind close & selisih kas)
+	shiftCountColumns := []struct {
+		name       string
+		definition string
+	}{
+		{"expected_cash", "REAL"},
+		{"counted_cash", "REAL"},
+		{"cash_variance", "REAL"},
+		{"blind_close", "INTEGER NOT NULL DEFAULT 0"},
+		{"variance_approved_by", "TEXT REFERENCES users(id)"},
+		{"variance_note", "TEXT"},
+	}
+	for _, column := range shiftCountColumns {
+		if err := ensureColumn(db, "cashier_shifts", column.name, column.definition); err != nil {
 			return err
 		}
+	}
 
-		log.Println("✅ Orders table migrated to new order_id format")
+	// Z report menandai order periode lewat z_report_id. Trigger menolak perubahan
+	// nominal pada hari bisnis yang sudah ditutup dan menjaga z_reports tetap immutable.
+	if err := ensureColumn(db, "orders", "z_report_id", "TEXT REFERENCES z_reports(id)"); err != nil {
+		return err
+	}
+	_, err = db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_orders_z_report ON orders(z_report_id);
+
+		CREATE TRIGGER IF NOT EXISTS trg_z_reports_no_update
+		BEFORE UPDATE ON z_reports
+		BEGIN
+			SELECT RAISE(ABORT, 'Z report tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_z_reports_no_delete
+		BEFORE DELETE ON z_reports
+		BEGIN
+			SELECT RAISE(ABORT, 'Z report tidak bisa dihapus');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_orders_closed_day_update
+		BEFORE UPDATE OF total_amount, paid_amount, payment_status, is_merged, merged_from, voided_at ON orders
+		WHEN OLD.z_report_id IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_orders_closed_day_delete
+		BEFORE DELETE ON orders
+		WHEN OLD.z_report_id IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_order_items_closed_day_insert
+		BEFORE INSERT ON order_items
+		WHEN (SELECT z_report_id FROM orders WHERE id = NEW.order_id) IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_order_items_closed_day_update
+		BEFORE UPDATE OF qty, price ON order_items
+		WHEN (SELECT z_report_id FROM orders WHERE id = OLD.order_id) IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_order_items_closed_day_delete
+		BEFORE DELETE ON order_items
+		WHEN (SELECT z_report_id FROM orders WHERE id = OLD.order_id) IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_order_charges_closed_day_insert
+		BEFORE INSERT ON order_additional_charges
+		WHEN (SELECT z_report_id FROM orders WHERE id = NEW.order_id) IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_order_charges_closed_day_delete
+		BEFORE DELETE ON order_additional_charges
+		WHEN (SELECT z_report_id FROM orders WHERE id = OLD.order_id) IS NOT NULL
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_transactions_closed_day_update
+		BEFORE UPDATE OF status, total_amount, payment_method, cancelled_at ON transactions
+		WHEN OLD.transaction_date <= (SELECT MAX(period_end) FROM z_reports)
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_transactions_closed_day_delete
+		BEFORE DELETE ON transactions
+		WHEN OLD.transaction_date <= (SELECT MAX(period_end) FROM z_reports)
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_payments_closed_day_update
+		BEFORE UPDATE ON payments
+		WHEN OLD.created_at <= (SELECT MAX(period_end) FROM z_reports)
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS trg_payments_closed_day_delete
+		BEFORE DELETE ON payments
+		WHEN OLD.created_at <= (SELECT MAX(period_end) FROM z_reports)
+		BEGIN
+			SELECT RAISE(ABORT, 'hari bisnis sudah ditutup (Z report), data tidak bisa diubah');
+		END;
+	`)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateCashierTerminals(db); err != nil {
+		return err
+	}
+
+	// Pulsa laci kas (ESC p) per printer: pin 2 atau 5, durasi on/off dalam ms
+	drawerColumns := []struct {
+		name       string
+		definition string
+	}{
+		{"drawer_enabled", "INTEGER NOT NULL DEFAULT 0"},
+		{"drawer_pin", "INTEGER NOT NULL DEFAULT 2"},
+		{"drawer_on_ms", "INTEGER NOT NULL DEFAULT 100"},
+		{"drawer_off_ms", "INTEGER NOT NULL DEFAULT 200"},
+	}
+	for _, column := range drawerColumns {
+		if err := ensureColumn(db, "printers", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	// Nomor kursi item untuk split check per kursi (NULL = item bersama)
+	if err := ensureColumn(db, "order_items", "seat_number", "INTEGER"); err != nil {
+		return err
+	}
+
+	// Deposit reservasi, dipindah ke order saat tamu reservasi duduk
+	if err := ensureColumn(db, "deposits", "reservation_id", "TEXT"); err != nil {
+		return err
+	}
+
+	// Letak meja pada denah lantai
+	tableLayoutColumns := []struct {
+		name       string
+		definition string
+	}{
+		{"area_id", "TEXT REFERENCES floor_areas(id)"},
+		{"section_id", "TEXT REFERENCES floor_sections(id)"},
+		{"shape", "TEXT NOT NULL DEFAULT 'square'"},
+		{"pos_x", "REAL NOT NULL DEFAULT 0"},
+		{"pos_y", "REAL NOT NULL DEFAULT 0"},
+		{"width", "REAL NOT NULL DEFAULT 1"},
+		{"height", "REAL NOT NULL DEFAULT 1"},
+		{"rotation", "REAL NOT NULL DEFAULT 0"},
+	}
+	for _, column := range tableLayoutColumns {
+		if err := ensureColumn(db, "tables", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	// Tipe order: meja hanya wajib untuk dine-in, order lain memakai nomor antrian
+	orderTypeColumns := []struct {
+		name       string
+		definition string
+	}{
+		{"order_type", "TEXT NOT NULL DEFAULT 'dine_in'"},
+		{"queue_number", "INTEGER"},
+		{"pickup_at", "DATETIME"},
+		{"delivery_address", "TEXT"},
+	}
+	for _, column := range orderTypeColumns {
+		if err := ensureColumn(db, "orders", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	// Baris biaya kemasan dihitung ulang bersama biaya otomatis lain
+	if err := ensureColumn(db, "order_additional_charges", "is_packaging", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_orders_order_type ON orders(order_type, created_at)"); err != nil {
+		return err
+	}
+	if err := seedOrderTypeSettings(db); err != nil {
+		return err
+	}
+
+	// Course dan hold/fire item dapur: item yang ditahan tidak dicetak sampai course-nya di-fire
+	courseColumns := []struct {
+		name       string
+		definition string
+	}{
+		{"course", "TEXT"},
+		{"is_held", "INTEGER NOT NULL DEFAULT 0"},
+		{"fired_at", "DATETIME"},
+	}
+	for _, column := range courseColumns {
+		if err := ensureColumn(db, "order_items", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	// Nonce token QR pesan mandiri per meja; diganti untuk membatalkan QR lama
+	if err := ensureColumn(db, "tables", "guest_nonce", "TEXT"); err != nil {
+		return err
+	}
+
+	// Siklus status meja: occupied dipecah menjadi seated/ordered/bill_requested/paid, ditambah dirty
+	if err := migrateTableStatuses(db); err != nil {
+		return err
+	}
+	if err := seedTableStatusTransitions(db); err != nil {
+		return err
+	}
+
+	// Profil pelanggan: kontak, preferensi, catatan alergi dan statistik lifetime
+	if err := migrateCustomerProfiles(db); err != nil {
+		return err
+	}
+
+	// Intent QRIS yang sudah dicatat ke order (settled_at) atau perlu dikembalikan karena
+	// order sudah lunas lebih dulu (refund_required)
+	intentSettlementTracked, err := hasColumn(db, "payment_intents", "settled_at")
+	if err != nil {
+		return err
+	}
+	paymentIntentColumns := []struct {
+		name       string
+		definition string
+	}{
+		{"settled_at", "DATETIME"},
+		{"refund_required", "INTEGER NOT NULL DEFAULT 0"},
+	}
+	for _, column := range paymentIntentColumns {
+		if err := ensureColumn(db, "payment_intents", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	if !intentSettlementTracked {
+		// Intent paid sebelum kolom ini ada sudah dilunaskan saat callback diterima
+		if _, err := db.Exec(`
+			UPDATE payment_intents SET settled_at = COALESCE(paid_at, updated_at) WHERE status = 'paid'
+		`); err != nil {
+			return err
+		}
 	}
 
 	if err := seedAdminUser(db); err != nil {
@@ -1663,6 +2859,35 @@ func runMigrations(db *sql.DB) error {
 	return nil
 }
 
+// ensureColumn menambahkan kolom jika belum ada.
+// SQLite tidak mendukung "ADD COLUMN IF NOT EXISTS", jadi cek lewat pragma_table_info.
+func ensureColumn(db *sql.DB, table, column, definition string) error {
+	exists, err := hasColumn(db, table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
+	if err != nil {
+		return err
+	}
+	log.Printf("✅ Added %s column to %s table", column, table)
+	return nil
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	var exists int
+	err := db.QueryRow(`
+		SELECT COUNT(*)
+		FROM pragma_table_info(?)
+		WHERE name = ?
+	`, table, column).Scan(&exists)
+	return exists > 0, err
+}
+
 func seedAdminUser(db *sql.DB) error {
 	var existing int
 	if err := db.QueryRow("SELECT COUNT(*) FROM users WHERE username = 'admin'").Scan(&existing); err != nil {
@@ -1701,3 +2926,361 @@ func seedOutletConfig(db *sql.DB) error {
 	`, outletID, "Outlet", "OUTLET-001", "", "", "Terima kasih atas kunjungan Anda!", "", 0)
 	return err
 }
+
+// seedOrderTypeSettings mengisi pengaturan empat tipe order bawaan
+func seedOrderTypeSettings(db *sql.DB) error {
+	defaults := []struct {
+		orderType   string
+		queuePrefix string
+	}{
+		{"dine_in", ""},
+		{"takeaway", "TA"},
+		{"delivery", "DL"},
+		{"pickup", "PU"},
+	}
+	for _, setting := range defaults {
+		_, err := db.Exec(`
+			INSERT OR IGNORE INTO order_type_settings (order_type, queue_prefix, updated_at)
+			VALUES (?, ?, CURRENT_TIMESTAMP)
+		`, setting.orderType, setting.queuePrefix)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateTableStatuses membangun ulang tabel tables dengan CHECK status baru.
+// Meja lama berstatus occupied menjadi ordered jika masih ada order berjalan, selain itu seated.
+func migrateTableStatuses(db *sql.DB) error {
+	var tablesSchema string
+	err := db.QueryRow(`
+		SELECT sql
+		FROM sqlite_master
+		WHERE type='table' AND name='tables'
+	`).Scan(&tablesSchema)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(tablesSchema, "'dirty'") {
+		return nil
+	}
+
+	oldCheck := "CHECK (status IN ('available', 'occupied', 'reserved'))"
+	if !strings.Contains(tablesSchema, oldCheck) {
+		return fmt.Errorf("skema tabel tables tidak dikenali untuk migrasi status")
+	}
+	log.Println("🔄 Migrating tables status lifecycle...")
+
+	newSchema := strings.Replace(tablesSchema, oldCheck, "CHECK (status IN ('available', 'reserved', 'seated', 'ordered', 'bill_requested', 'paid', 'dirty'))", 1)
+	newSchema = strings.Replace(newSchema, "CREATE TABLE tables", "CREATE TABLE tables_new", 1)
+
+	// Index ikut terhapus bersama tabel lama, jadi simpan dulu definisinya
+	indexRows, err := db.Query(`
+		SELECT sql
+		FROM sqlite_master
+		WHERE type='index' AND tbl_name='tables' AND sql IS NOT NULL
+	`)
+	if err != nil {
+		return err
+	}
+	var indexes []string
+	for indexRows.Next() {
+		var indexSQL string
+		if err := indexRows.Scan(&indexSQL); err != nil {
+			indexRows.Close()
+			return err
+		}
+		indexes = append(indexes, indexSQL)
+	}
+	indexRows.Close()
+
+	columnRows, err := db.Query("SELECT name FROM pragma_table_info('tables')")
+	if err != nil {
+		return err
+	}
+	var columns, selects []string
+	for columnRows.Next() {
+		var name string
+		if err := columnRows.Scan(&name); err != nil {
+			columnRows.Close()
+			return err
+		}
+		columns = append(columns, name)
+		if name == "status" {
+			selects = append(selects, `CASE WHEN status != 'occupied' THEN status
+				WHEN EXISTS (
+					SELECT 1 FROM orders o
+					WHERE o.table_number = tables.table_number
+					  AND o.payment_status != 'paid' AND o.is_merged = 0 AND o.voided_at IS NULL
+				) THEN 'ordered'
+				ELSE 'seated' END`)
+			continue
+		}
+		selects = append(selects, name)
+	}
+	columnRows.Close()
+
+	if _, err := db.Exec("PRAGMA foreign_keys = OFF"); err != nil {
+		return err
+	}
+	if _, err := db.Exec(newSchema); err != nil {
+		return err
+	}
+	if _, err := db.Exec(fmt.Sprintf(
+		"INSERT INTO tables_new (%s) SELECT %s FROM tables",
+		strings.Join(columns, ", "), strings.Join(selects, ", "),
+	)); err != nil {
+		return err
+	}
+	if _, err := db.Exec("DROP TABLE tables"); err != nil {
+		return err
+	}
+	if _, err := db.Exec("ALTER TABLE tables_new RENAME TO tables"); err != nil {
+		return err
+	}
+	for _, indexSQL := range indexes {
+		if _, err := db.Exec(indexSQL); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+		return err
+	}
+	log.Println("✅ Tables status lifecycle migrated")
+	return nil
+}
+
+// seedTableStatusTransitions mengisi aturan bawaan sekali saja; setelah itu
+// aturan sepenuhnya dikelola manager lewat API.
+func seedTableStatusTransitions(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM table_status_transitions").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	defaults := []struct {
+		from  string
+		to    string
+		roles string
+	}{
+		{"available", "seated", "waiter,manager"},
+		{"available", "reserved", "waiter,cashier,manager"},
+		{"reserved", "seated", "waiter,manager"},
+		{"reserved", "available", "waiter,cashier,manager"},
+		{"seated", "ordered", "waiter,manager"},
+		{"seated", "available", "waiter,manager"},
+		{"ordered", "bill_requested", "waiter,cashier,manager"},
+		{"bill_requested", "ordered", "waiter,cashier,manager"},
+		{"bill_requested", "paid", "cashier,manager"},
+		{"ordered", "paid", "cashier,manager"},
+		{"paid", "dirty", "waiter,cashier,manager"},
+		{"dirty", "available", "waiter,manager"},
+	}
+	for _, transition := range defaults {
+		if _, err := db.Exec(`
+			INSERT OR IGNORE INTO table_status_transitions (from_status, to_status, roles, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		`, transition.from, transition.to, transition.roles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCustomerProfiles menambah kolom profil pelanggan dan mengisi statistik lifetime
+// dari order lunas yang sudah ada (sekali, saat customer_visits masih kosong).
+func migrateCustomerProfiles(db *sql.DB) error {
+	columns := []struct {
+		name       string
+		definition string
+	}{
+		{"email", "TEXT"},
+		{"birthday", "TEXT"},
+		{"tags", "TEXT"},
+		{"allergies", "TEXT"},
+		{"dietary_notes", "TEXT"},
+		{"total_visits", "INTEGER NOT NULL DEFAULT 0"},
+		{"total_spent", "REAL NOT NULL DEFAULT 0"},
+		{"last_visit_at", "DATETIME"},
+	}
+	for _, column := range columns {
+		if err := ensureColumn(db, "customers", column.name, column.definition); err != nil {
+			return err
+		}
+	}
+	if _, err := db.Exec("CREATE INDEX IF NOT EXISTS idx_customers_email ON customers(email)"); err != nil {
+		return err
+	}
+
+	var visits int
+	if err := db.QueryRow("SELECT COUNT(*) FROM customer_visits").Scan(&visits); err != nil {
+		return err
+	}
+	if visits > 0 {
+		return nil
+	}
+	result, err := db.Exec(`
+		INSERT INTO customer_visits (order_id, customer_id, amount, visited_at)
+		SELECT o.id, o.customer_id,
+		       o.total_amount - COALESCE((SELECT SUM(r.amount) FROM refunds r WHERE r.order_id = o.id), 0),
+		       o.updated_at
+		FROM orders o
+		JOIN customers c ON c.id = o.customer_id
+		WHERE o.payment_status = 'paid' AND o.voided_at IS NULL
+	`)
+	if err != nil {
+		return err
+	}
+	backfilled, _ := result.RowsAffected()
+	if backfilled == 0 {
+		return nil
+	}
+	if _, err := db.Exec(`
+		UPDATE customers
+		SET total_visits = (SELECT COUNT(*) FROM customer_visits v WHERE v.customer_id = customers.id),
+		    total_spent = (SELECT COALESCE(SUM(v.amount), 0) FROM customer_visits v WHERE v.customer_id = customers.id),
+		    last_visit_at = (SELECT MAX(v.visited_at) FROM customer_visits v WHERE v.customer_id = customers.id)
+	`); err != nil {
+		return err
+	}
+	log.Printf("✅ Backfilled %d customer visits", backfilled)
+	return nil
+}
+
+// seedPaymentMethods mengisi empat metode bawaan yang dulu di-hardcode.
+// Metode sistem tidak bisa dihapus dan kodenya tidak bisa diubah.
+func seedPaymentMethods(db *sql.DB) error {
+	defaults := []struct {
+		code           string
+		name           string
+		methodType     string
+		countsToDrawer int
+		sortOrder      int
+	}{
+		{"cash", "Tunai", "cash", 1, 1},
+		{"card", "Kartu", "card", 0, 2},
+		{"qris", "QRIS", "qris", 0, 3},
+		{"transfer", "Transfer", "transfer", 0, 4},
+		// Pemakaian saldo deposit; hanya diterapkan otomatis saat order dilunasi
+		{"deposit", "Deposit", "other", 0, 90},
+		// Penukaran saldo gift card; wajib gift_card_code saat bayar
+		{"gift_card", "Gift Card", "other", 0, 91},
+	}
+	for _, method := range defaults {
+		_, err := db.Exec(`
+			INSERT OR IGNORE INTO payment_methods (
+				id, code, name, method_type, requires_reference, counts_to_drawer,
+				is_active, is_system, sort_order, created_at, updated_at
+			) VALUES (?, ?, ?, ?, 0, ?, 1, 1, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, utils.GenerateULID(), method.code, method.name, method.methodType, method.countsToDrawer, method.sortOrder)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateCashierTerminals menyiapkan shift per terminal. Pembayaran, transaksi, void
+// dan pembatalan ditandai shift (laci) yang memprosesnya. Saat kolom terminal_id baru
+// ditambahkan, shift lama dipindah ke terminal default dan data lama diisi berdasarkan
+// rentang waktu shift (perilaku sebelum multi terminal).
+func migrateCashierTerminals(db *sql.DB) error {
+	upgraded, err := hasColumn(db, "cashier_shifts", "terminal_id")
+	if err != nil {
+		return err
+	}
+	upgraded = !upgraded
+
+	columns := []struct {
+		table      string
+		name       string
+		definition string
+	}{
+		{"cashier_shifts", "terminal_id", "TEXT REFERENCES cashier_terminals(id)"},
+		{"payments", "shift_id", "TEXT REFERENCES cashier_shifts(id)"},
+		{"transactions", "shift_id", "TEXT REFERENCES cashier_shifts(id)"},
+		{"transactions", "cancelled_shift_id", "TEXT REFERENCES cashier_shifts(id)"},
+		{"orders", "voided_shift_id", "TEXT REFERENCES cashier_shifts(id)"},
+		{"payment_intents", "shift_id", "TEXT REFERENCES cashier_shifts(id)"},
+	}
+	for _, column := range columns {
+		if err := ensureColumn(db, column.table, column.name, column.definition); err != nil {
+			return err
+		}
+	}
+
+	var terminals int
+	if err := db.QueryRow("SELECT COUNT(*) FROM cashier_terminals").Scan(&terminals); err != nil {
+		return err
+	}
+	if terminals == 0 {
+		if _, err := db.Exec(`
+			INSERT INTO cashier_terminals (id, code, name, is_active, created_at, updated_at)
+			VALUES (?, 'KASIR-1', 'Kasir 1', 1, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		`, utils.GenerateULID()); err != nil {
+			return err
+		}
+	}
+
+	if upgraded {
+		_, err := db.Exec(`
+			UPDATE cashier_shifts
+			SET terminal_id = (SELECT id FROM cashier_terminals ORDER BY created_at, code LIMIT 1)
+			WHERE terminal_id IS NULL;
+
+			UPDATE transactions
+			SET shift_id = (
+				SELECT cs.id FROM cashier_shifts cs
+				WHERE cs.opened_by = transactions.created_by
+				AND transactions.transaction_date BETWEEN cs.opened_at AND COALESCE(cs.closed_at, '9999-12-31')
+				ORDER BY cs.opened_at DESC LIMIT 1
+			)
+			WHERE shift_id IS NULL;
+
+			UPDATE transactions
+			SET cancelled_shift_id = (
+				SELECT cs.id FROM cashier_shifts cs
+				WHERE transactions.cancelled_at BETWEEN cs.opened_at AND COALESCE(cs.closed_at, '9999-12-31')
+				ORDER BY cs.opened_at DESC LIMIT 1
+			)
+			WHERE cancelled_at IS NOT NULL AND cancelled_shift_id IS NULL;
+
+			UPDATE orders
+			SET voided_shift_id = (
+				SELECT cs.id FROM cashier_shifts cs
+				WHERE orders.voided_at BETWEEN cs.opened_at AND COALESCE(cs.closed_at, '9999-12-31')
+				ORDER BY cs.opened_at DESC LIMIT 1
+			)
+			WHERE voided_at IS NOT NULL AND voided_shift_id IS NULL;
+
+			-- Payment pada hari bisnis yang sudah ditutup Z dijaga trigger, jadi dilewati
+			UPDATE payments
+			SET shift_id = (
+				SELECT cs.id FROM cashier_shifts cs
+				WHERE cs.opened_by = payments.created_by
+				AND payments.created_at BETWEEN cs.opened_at AND COALESCE(cs.closed_at, '9999-12-31')
+				ORDER BY cs.opened_at DESC LIMIT 1
+			)
+			WHERE shift_id IS NULL
+			AND created_at > COALESCE((SELECT MAX(period_end) FROM z_reports), '');
+		`)
+		if err != nil {
+			return err
+		}
+		log.Println("✅ Cashier shifts migrated to per-terminal shifts")
+	}
+
+	_, err = db.Exec(`
+		CREATE UNIQUE INDEX IF NOT EXISTS idx_cashier_shifts_open_terminal ON cashier_shifts(terminal_id) WHERE status = 'open';
+		CREATE INDEX IF NOT EXISTS idx_cashier_shifts_terminal ON cashier_shifts(terminal_id);
+		CREATE INDEX IF NOT EXISTS idx_payments_shift ON payments(shift_id);
+		CREATE INDEX IF NOT EXISTS idx_transactions_shift ON transactions(shift_id);
+		CREATE INDEX IF NOT EXISTS idx_transactions_cancelled_shift ON transactions(cancelled_shift_id);
+		CREATE INDEX IF NOT EXISTS idx_orders_voided_shift ON orders(voided_shift_id);
+	`)
+	return err
+}
@@ -0,0 +1,203 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultMidtransBaseURL adalah endpoint Core API sandbox Midtrans
+const DefaultMidtransBaseURL = "https://api.sandbox.midtrans.com"
+
+// MidtransQRISProvider membuat QRIS dinamis lewat Midtrans Core API
+type MidtransQRISProvider struct {
+	serverKey  string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewMidtransQRISProvider membuat provider QRIS Midtrans
+func NewMidtransQRISProvider(serverKey, baseURL string) *MidtransQRISProvider {
+	if baseURL == "" {
+		baseURL = DefaultMidtransBaseURL
+	}
+	return &MidtransQRISProvider{
+		serverKey: serverKey,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (p *MidtransQRISProvider) Name() string   { return "midtrans" }
+func (p *MidtransQRISProvider) Method() string { return "qris" }
+
+type midtransResponse struct {
+	StatusCode        string `json:"status_code"`
+	StatusMessage     string `json:"status_message"`
+	TransactionID     string `json:"transaction_id"`
+	OrderID           string `json:"order_id"`
+	GrossAmount       string `json:"gross_amount"`
+	TransactionStatus string `json:"transaction_status"`
+	QRString          string `json:"qr_string"`
+	ExpiryTime        string `json:"expiry_time"`
+}
+
+func (p *MidtransQRISProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("nominal intent harus lebih dari 0")
+	}
+	body := map[string]interface{}{
+		"payment_type": "qris",
+		"transaction_details": map[string]interface{}{
+			"order_id":     req.Reference,
+			"gross_amount": req.Amount,
+		},
+	}
+	if req.ExpiresIn > 0 {
+		body["custom_expiry"] = map[string]interface{}{
+			"expiry_duration": int(math.Ceil(req.ExpiresIn.Minutes())),
+			"unit":            "minute",
+		}
+	}
+
+	var resp midtransResponse
+	if err := p.do(ctx, http.MethodPost, "/v2/charge", body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.QRString == "" {
+		return nil, fmt.Errorf("midtrans error: status=%s, message=%s", resp.StatusCode, resp.StatusMessage)
+	}
+
+	intent := &Intent{
+		ProviderRef: resp.TransactionID,
+		QRString:    resp.QRString,
+		Status:      midtransStatus(resp.TransactionStatus),
+	}
+	if resp.ExpiryTime != "" {
+		if t, err := time.ParseInLocation("2006-01-02 15:04:05", resp.ExpiryTime, jakartaLocation()); err == nil {
+			intent.ExpiresAt = t
+		}
+	}
+	if intent.ExpiresAt.IsZero() && req.ExpiresIn > 0 {
+		intent.ExpiresAt = time.Now().Add(req.ExpiresIn)
+	}
+	return intent, nil
+}
+
+func (p *MidtransQRISProvider) GetStatus(ctx context.Context, providerRef string) (Status, error) {
+	var resp midtransResponse
+	if err := p.do(ctx, http.MethodGet, "/v2/"+providerRef+"/status", nil, &resp); err != nil {
+		return "", err
+	}
+	if resp.StatusCode == "404" {
+		return "", ErrIntentNotFound
+	}
+	return midtransStatus(resp.TransactionStatus), nil
+}
+
+func (p *MidtransQRISProvider) Cancel(ctx context.Context, providerRef string) error {
+	var resp midtransResponse
+	return p.do(ctx, http.MethodPost, "/v2/"+providerRef+"/cancel", nil, &resp)
+}
+
+// ParseCallback memverifikasi notifikasi HTTP Midtrans:
+// signature_key = SHA512(order_id + status_code + gross_amount + server_key)
+func (p *MidtransQRISProvider) ParseCallback(body []byte, header http.Header) (*CallbackEvent, error) {
+	var payload struct {
+		midtransResponse
+		SignatureKey string `json:"signature_key"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("payload callback tidak valid: %w", err)
+	}
+
+	sum := sha512.Sum512([]byte(payload.OrderID + payload.StatusCode + payload.GrossAmount + p.serverKey))
+	expected := hex.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(payload.SignatureKey)) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	amount, _ := strconv.ParseFloat(payload.GrossAmount, 64)
+	return &CallbackEvent{
+		Reference:   payload.OrderID,
+		ProviderRef: payload.TransactionID,
+		Status:      midtransStatus(payload.TransactionStatus),
+		Amount:      int64(math.Round(amount)),
+	}, nil
+}
+
+func (p *MidtransQRISProvider) do(ctx context.Context, method, path string, body interface{}, out interface{}) error {
+	if p.serverKey == "" {
+		return fmt.Errorf("midtrans server key belum dikonfigurasi")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		jsonData, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewBuffer(jsonData)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, p.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(p.serverKey, "")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("midtrans error: status=%d, body=%s", resp.StatusCode, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return nil
+}
+
+func midtransStatus(status string) Status {
+	switch status {
+	case "settlement", "capture":
+		return StatusPaid
+	case "expire":
+		return StatusExpired
+	case "cancel":
+		return StatusCancelled
+	case "deny", "failure", "refund", "partial_refund":
+		return StatusFailed
+	default:
+		return StatusPending
+	}
+}
+
+func jakartaLocation() *time.Location {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
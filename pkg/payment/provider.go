@@ -0,0 +1,132 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status adalah status intent pembayaran yang sudah dinormalisasi dari provider
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusPaid      Status = "paid"
+	StatusExpired   Status = "expired"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+// IsFinal mengembalikan true jika status tidak akan berubah lagi
+func (s Status) IsFinal() bool {
+	return s != StatusPending
+}
+
+var (
+	ErrProviderNotFound = errors.New("payment provider tidak ditemukan")
+	ErrInvalidSignature = errors.New("signature callback tidak valid")
+	ErrIntentNotFound   = errors.New("intent pembayaran tidak ditemukan di provider")
+)
+
+// IntentRequest adalah permintaan pembuatan intent pembayaran.
+// Reference harus unik per percobaan bayar (dipakai sebagai order_id di provider).
+type IntentRequest struct {
+	Reference   string
+	Amount      int64
+	Description string
+	ExpiresIn   time.Duration
+}
+
+// Intent adalah hasil pembuatan intent dari provider
+type Intent struct {
+	ProviderRef string
+	QRString    string
+	Status      Status
+	ExpiresAt   time.Time
+}
+
+// CallbackEvent adalah notifikasi status dari provider yang sudah diverifikasi
+type CallbackEvent struct {
+	Reference   string
+	ProviderRef string
+	Status      Status
+	Amount      int64
+}
+
+// Provider adalah kontrak penyedia pembayaran non-tunai (QRIS dinamis, dll.)
+type Provider interface {
+	// Name adalah kode provider, dipakai di URL callback
+	Name() string
+	// Method adalah payment_method yang dicatat saat order lunas
+	Method() string
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	GetStatus(ctx context.Context, providerRef string) (Status, error)
+	ParseCallback(body []byte, header http.Header) (*CallbackEvent, error)
+	Cancel(ctx context.Context, providerRef string) error
+}
+
+// Registry menyimpan provider yang aktif beserta provider default
+type Registry struct {
+	mu          sync.RWMutex
+	providers   map[string]Provider
+	defaultName string
+}
+
+// NewRegistry membuat registry kosong
+func NewRegistry() *Registry {
+	return &Registry{providers: map[string]Provider{}}
+}
+
+// Register mendaftarkan provider. Default tidak diisi otomatis; pilih lewat SetDefault.
+func (r *Registry) Register(p Provider) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers[p.Name()] = p
+}
+
+// SetDefault mengganti provider default jika sudah terdaftar
+func (r *Registry) SetDefault(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[name]; !ok {
+		return ErrProviderNotFound
+	}
+	r.defaultName = name
+	return nil
+}
+
+// Get mengambil provider berdasarkan nama; nama kosong berarti provider default
+func (r *Registry) Get(name string) (Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if name == "" {
+		name = r.defaultName
+	}
+	p, ok := r.providers[name]
+	if !ok {
+		return nil, ErrProviderNotFound
+	}
+	return p, nil
+}
+
+// Names mengembalikan daftar provider terdaftar
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default mengembalikan nama provider default
+func (r *Registry) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
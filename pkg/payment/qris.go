@@ -0,0 +1,67 @@
+package payment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QRISMerchant adalah data merchant untuk membentuk payload QRIS (EMVCo MPM)
+type QRISMerchant struct {
+	MerchantID   string
+	MerchantName string
+	City         string
+	PostalCode   string
+}
+
+// BuildDynamicQRIS membentuk payload QRIS dinamis dengan nominal dan referensi.
+// Format mengikuti EMVCo Merchant Presented Mode dengan CRC16-CCITT di tag 63.
+func BuildDynamicQRIS(m QRISMerchant, amount int64, reference string) string {
+	var sb strings.Builder
+	sb.WriteString(emvTag("00", "01"))
+	sb.WriteString(emvTag("01", "12")) // 12 = dinamis
+	sb.WriteString(emvTag("26",
+		emvTag("00", "ID.CO.QRIS.WWW")+
+			emvTag("01", m.MerchantID)+
+			emvTag("03", "UMI")))
+	sb.WriteString(emvTag("52", "5812"))
+	sb.WriteString(emvTag("53", "360"))
+	sb.WriteString(emvTag("54", fmt.Sprintf("%d", amount)))
+	sb.WriteString(emvTag("58", "ID"))
+	sb.WriteString(emvTag("59", truncate(m.MerchantName, 25)))
+	sb.WriteString(emvTag("60", truncate(m.City, 15)))
+	if m.PostalCode != "" {
+		sb.WriteString(emvTag("61", m.PostalCode))
+	}
+	if reference != "" {
+		sb.WriteString(emvTag("62", emvTag("05", truncate(reference, 25))))
+	}
+	sb.WriteString("6304")
+	payload := sb.String()
+	return payload + fmt.Sprintf("%04X", crc16CCITT([]byte(payload)))
+}
+
+func emvTag(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
+
+func crc16CCITT(data []byte) uint16 {
+	crc := uint16(0xFFFF)
+	for _, b := range data {
+		crc ^= uint16(b) << 8
+		for i := 0; i < 8; i++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
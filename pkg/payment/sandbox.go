@@ -0,0 +1,172 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SandboxSignatureHeader adalah header HMAC-SHA256 untuk callback sandbox
+const SandboxSignatureHeader = "X-Sandbox-Signature"
+
+type sandboxIntent struct {
+	reference string
+	amount    int64
+	status    Status
+	expiresAt time.Time
+}
+
+// SandboxProvider adalah provider QRIS tiruan untuk pengujian lokal.
+// Intent disimpan di memori dan dilunasi lewat Simulate.
+type SandboxProvider struct {
+	mu       sync.Mutex
+	secret   string
+	merchant QRISMerchant
+	intents  map[string]*sandboxIntent
+}
+
+// NewSandboxProvider membuat sandbox provider; secret dipakai untuk
+// menandatangani dan memverifikasi callback. Secret kosong menolak semua callback.
+func NewSandboxProvider(secret string) *SandboxProvider {
+	return &SandboxProvider{
+		secret: secret,
+		merchant: QRISMerchant{
+			MerchantID:   "ID1020000000001",
+			MerchantName: "POS SANDBOX",
+			City:         "JAKARTA",
+		},
+		intents: map[string]*sandboxIntent{},
+	}
+}
+
+func (p *SandboxProvider) Name() string   { return "sandbox" }
+func (p *SandboxProvider) Method() string { return "qris" }
+
+func (p *SandboxProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("nominal intent harus lebih dari 0")
+	}
+	expiresIn := req.ExpiresIn
+	if expiresIn <= 0 {
+		expiresIn = 15 * time.Minute
+	}
+	providerRef := "SBX-" + req.Reference
+	expiresAt := time.Now().Add(expiresIn)
+
+	p.mu.Lock()
+	p.intents[providerRef] = &sandboxIntent{
+		reference: req.Reference,
+		amount:    req.Amount,
+		status:    StatusPending,
+		expiresAt: expiresAt,
+	}
+	p.mu.Unlock()
+
+	return &Intent{
+		ProviderRef: providerRef,
+		QRString:    BuildDynamicQRIS(p.merchant, req.Amount, req.Reference),
+		Status:      StatusPending,
+		ExpiresAt:   expiresAt,
+	}, nil
+}
+
+func (p *SandboxProvider) GetStatus(ctx context.Context, providerRef string) (Status, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, ok := p.intents[providerRef]
+	if !ok {
+		return "", ErrIntentNotFound
+	}
+	if intent.status == StatusPending && time.Now().After(intent.expiresAt) {
+		intent.status = StatusExpired
+	}
+	return intent.status, nil
+}
+
+func (p *SandboxProvider) Cancel(ctx context.Context, providerRef string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	intent, ok := p.intents[providerRef]
+	if !ok {
+		return ErrIntentNotFound
+	}
+	if intent.status == StatusPending {
+		intent.status = StatusCancelled
+	}
+	return nil
+}
+
+type sandboxCallbackPayload struct {
+	Reference   string `json:"reference"`
+	ProviderRef string `json:"provider_ref"`
+	Status      string `json:"status"`
+	Amount      int64  `json:"amount"`
+}
+
+// Simulate mengubah status intent (default paid) lalu mengembalikan body
+// callback beserta signature-nya, seolah dikirim oleh provider
+func (p *SandboxProvider) Simulate(providerRef string, status Status) ([]byte, string, error) {
+	if status == "" {
+		status = StatusPaid
+	}
+	p.mu.Lock()
+	intent, ok := p.intents[providerRef]
+	if !ok {
+		p.mu.Unlock()
+		return nil, "", ErrIntentNotFound
+	}
+	if intent.status == StatusPending {
+		intent.status = status
+	}
+	payload := sandboxCallbackPayload{
+		Reference:   intent.reference,
+		ProviderRef: providerRef,
+		Status:      string(intent.status),
+		Amount:      intent.amount,
+	}
+	p.mu.Unlock()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", err
+	}
+	return body, p.sign(body), nil
+}
+
+func (p *SandboxProvider) ParseCallback(body []byte, header http.Header) (*CallbackEvent, error) {
+	if p.secret == "" {
+		return nil, ErrInvalidSignature
+	}
+	expected := p.sign(body)
+	if !hmac.Equal([]byte(expected), []byte(header.Get(SandboxSignatureHeader))) {
+		return nil, ErrInvalidSignature
+	}
+	var payload sandboxCallbackPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("payload callback tidak valid: %w", err)
+	}
+	status := Status(payload.Status)
+	switch status {
+	case StatusPending, StatusPaid, StatusExpired, StatusCancelled, StatusFailed:
+	default:
+		return nil, fmt.Errorf("status callback tidak dikenal: %s", payload.Status)
+	}
+	return &CallbackEvent{
+		Reference:   payload.Reference,
+		ProviderRef: payload.ProviderRef,
+		Status:      status,
+		Amount:      payload.Amount,
+	}, nil
+}
+
+func (p *SandboxProvider) sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
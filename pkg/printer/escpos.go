@@ -71,6 +71,47 @@ func PadLeft(text string, width int) string {
 	return RepeatChar(" ", width-len(text)) + text
 }
 
+// QRCode builds GS ( k commands to store and print a QR code (model 2,
+// error correction M). moduleSize is the dot size per module (1-16).
+func QRCode(data string, moduleSize byte) []byte {
+	if moduleSize < 1 || moduleSize > 16 {
+		moduleSize = 6
+	}
+	payload := []byte(data)
+	storeLen := len(payload) + 3
+
+	cmd := []byte{
+		0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00, // model 2
+		0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, moduleSize, // module size
+		0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31, // error correction M
+		0x1D, 0x28, 0x6B, byte(storeLen % 256), byte(storeLen / 256), 0x31, 0x50, 0x30,
+	}
+	cmd = append(cmd, payload...)
+	cmd = append(cmd, 0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30) // print
+	return cmd
+}
+
+// DrawerKick builds the ESC p pulse that opens a cash drawer connected to the
+// printer. pin is the drawer connector pin (2 or 5); onMs/offMs are the pulse
+// on/off times in milliseconds (sent in 2ms units, max 510ms).
+func DrawerKick(pin int, onMs int, offMs int) []byte {
+	connector := byte(0x00)
+	if pin == 5 {
+		connector = 0x01
+	}
+	toUnits := func(ms int) byte {
+		units := ms / 2
+		if units < 1 {
+			units = 1
+		}
+		if units > 255 {
+			units = 255
+		}
+		return byte(units)
+	}
+	return []byte{0x1B, 0x70, connector, toUnits(onMs), toUnits(offMs)}
+}
+
 // FormatRow formats a two-column row (label: value)
 func FormatRow(label, value string, width int) string {
 	// Calculate spacing needed
@@ -3,6 +3,7 @@ package printer
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -20,6 +21,8 @@ type OutletConfig struct {
 type ReceiptData struct {
 	ReceiptNumber          string
 	TableNumber            string
+	OrderType              string
+	QueueLabel             string
 	CustomerName           string
 	WaiterName             string
 	CashierName            string
@@ -28,11 +31,15 @@ type ReceiptData struct {
 	AdditionalChargesTotal int
 	AdditionalCharges      []ReceiptCharge
 	Tax                    int
+	Taxes                  []ReceiptTax
 	Total                  int
 	PaymentMethod          string
 	PaidAmount             int
+	TipAmount              int
 	ChangeAmount           int
 	DateTime               time.Time
+	QRISPayload            string
+	QRISExpiresAt          *time.Time
 }
 
 type HandoverReceiptData struct {
@@ -44,6 +51,9 @@ type HandoverReceiptData struct {
 	ClosingCard     int
 	ClosingQris     int
 	ClosingTransfer int
+	ShiftPayments   []ShiftPaymentData
+	TipTotal        int
+	CashCount       *CashCountData
 	VoidedCount     int
 	VoidedTotal     int
 	CancelledCount  int
@@ -61,6 +71,9 @@ type CloseShiftReceiptData struct {
 	ClosingCard     int
 	ClosingQris     int
 	ClosingTransfer int
+	ShiftPayments   []ShiftPaymentData
+	TipTotal        int
+	CashCount       *CashCountData
 	VoidedCount     int
 	VoidedTotal     int
 	CancelledCount  int
@@ -70,6 +83,26 @@ type CloseShiftReceiptData struct {
 	DateTime        time.Time
 }
 
+// ShiftPaymentData adalah total penjualan shift untuk satu metode pembayaran
+type ShiftPaymentData struct {
+	Name   string
+	Amount int
+}
+
+// CashCountData adalah hasil hitung laci: ekspektasi, fisik dan selisih (lebih/kurang)
+type CashCountData struct {
+	ExpectedCash  int
+	CountedCash   int
+	Variance      int
+	ApprovedBy    string
+	Denominations []CashDenominationData
+}
+
+type CashDenominationData struct {
+	Denomination int
+	Qty          int
+}
+
 type CashInReceiptData struct {
 	ReceiptNumber string
 	CashierName   string
@@ -87,17 +120,102 @@ type CashOutReceiptData struct {
 	DateTime      time.Time
 }
 
+// RefundReceiptData adalah data struk refund; Items hanya terisi untuk refund per item
+type RefundReceiptData struct {
+	ReceiptNumber string
+	OrderNumber   string
+	TableNumber   string
+	CashierName   string
+	ApprovedBy    string
+	Items         []ReceiptItem
+	RefundMethod  string
+	Reason        string
+	Amount        int
+	DateTime      time.Time
+}
+
 type CashMovementData struct {
 	Name   string
 	Amount int
 }
 
+// SalesReportData adalah data cetak X report (snapshot berjalan) atau Z report (tutup hari)
+type SalesReportData struct {
+	ReportType     string
+	ZNumber        int
+	BusinessDate   string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	PrintedBy      string
+	IsReprint      bool
+	OrdersCount    int
+	Pax            int
+	GrossSales     int
+	Discounts      int
+	Charges        int
+	Taxes          int
+	NetSales       int
+	RefundsCount   int
+	Refunds        int
+	VoidsCount     int
+	Voids          int
+	CancelledCount int
+	Cancelled      int
+	Tips           int
+	PaymentsTotal  int
+	CashVariance   int
+	DiscountLines  []ReceiptCharge
+	ChargeLines    []ReceiptCharge
+	TaxLines       []ReceiptTax
+	Payments       []ShiftPaymentData
+	Shifts         []SalesReportShiftData
+	DateTime       time.Time
+}
+
+// SalesReportShiftData adalah selisih kas satu shift; Counted false jika laci tidak dihitung
+type SalesReportShiftData struct {
+	CashierName string
+	Counted     bool
+	Variance    int
+}
+
+// TableChangeData is the kitchen slip for a table transfer, item move or un-merge
+type TableChangeData struct {
+	ChangeType      string
+	FromOrderID     string
+	ToOrderID       string
+	FromTableNumber string
+	ToTableNumber   string
+	MovedBy         string
+	Items           []ReceiptItem
+	DateTime        time.Time
+}
+
 // ReceiptItem represents a single item on the receipt
 type ReceiptItem struct {
 	Name     string
 	Quantity int
 	Price    int
 	Total    int
+	Course   string
+}
+
+// KitchenTicketData is the kitchen/bar ticket for new, fired (FIRE) or held (HOLD) items
+type KitchenTicketData struct {
+	HeaderTitle string
+	OrderNumber string
+	TableName   string
+	OrderType   string
+	QueueLabel  string
+	WaiterName  string
+	Marker      string
+	HeldCourses []string
+	// Allergies and DietaryNotes come from the customer profile attached to the order
+	CustomerName string
+	Allergies    string
+	DietaryNotes string
+	Items        []ReceiptItem
+	DateTime     time.Time
 }
 
 type ReceiptCharge struct {
@@ -105,6 +223,14 @@ type ReceiptCharge struct {
 	Amount int
 }
 
+// ReceiptTax adalah baris pajak; pajak inklusif hanya informasi karena sudah termasuk harga
+type ReceiptTax struct {
+	Name        string
+	Rate        float64
+	Amount      int
+	IsInclusive bool
+}
+
 // PrintFormatter formats receipt data into ESC/POS commands
 type PrintFormatter struct {
 	outlet    OutletConfig
@@ -132,6 +258,9 @@ func (f *PrintFormatter) FormatReceipt(data ReceiptData) []byte {
 	// Header - Outlet Info (centered)
 	f.writeHeader(buf)
 
+	// Nomor antrian order tanpa meja (dicetak besar)
+	f.writeQueueNumber(buf, data.OrderType, data.QueueLabel)
+
 	// Transaction Info (left aligned with colon separator)
 	f.writeTransactionInfo(buf, data)
 
@@ -158,9 +287,11 @@ func (f *PrintFormatter) FormatBill(data ReceiptData) []byte {
 	buf.Write(ESC_CHARSET_LATIN)
 
 	f.writeBillHeader(buf)
+	f.writeQueueNumber(buf, data.OrderType, data.QueueLabel)
 	f.writeBillTransactionInfo(buf, data)
 	f.writeItemsBill(buf, data.Items)
 	f.writeBillSummary(buf, data)
+	f.writeBillQRIS(buf, data)
 	f.writeBillFooter(buf)
 
 	buf.Write(ESC_NEWLINE)
@@ -176,6 +307,7 @@ func (f *PrintFormatter) FormatSplitReceipt(data ReceiptData) []byte {
 	buf.Write(ESC_CHARSET_LATIN)
 
 	f.writeHeader(buf)
+	f.writeQueueNumber(buf, data.OrderType, data.QueueLabel)
 	f.writeTransactionInfo(buf, data)
 	f.writeItems(buf, data.Items)
 	f.writeSummary(buf, data)
@@ -222,14 +354,7 @@ func (f *PrintFormatter) FormatHandoverReceipt(data HandoverReceiptData) []byte
 
 	buf.WriteString(FormatRow("Modal Awal", FormatNumber(data.OpeningCash), f.charLimit))
 	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("Tunai", FormatNumber(data.ClosingCash), f.charLimit))
-	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("Kartu", FormatNumber(data.ClosingCard), f.charLimit))
-	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("QRIS", FormatNumber(data.ClosingQris), f.charLimit))
-	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("Transfer", FormatNumber(data.ClosingTransfer), f.charLimit))
-	buf.Write(ESC_NEWLINE)
+	totalSales := f.writeShiftPayments(buf, data.ShiftPayments, data.ClosingCash, data.ClosingCard, data.ClosingQris, data.ClosingTransfer)
 	buf.WriteString(FormatRow(fmt.Sprintf("VOID (%d)", data.VoidedCount), FormatNumber(data.VoidedTotal), f.charLimit))
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(FormatRow(fmt.Sprintf("Batal Transaksi (%d)", data.CancelledCount), FormatNumber(data.CancelledTotal), f.charLimit))
@@ -274,10 +399,13 @@ func (f *PrintFormatter) FormatHandoverReceipt(data HandoverReceiptData) []byte
 		buf.Write(ESC_NEWLINE)
 	}
 
-	totalSales := data.ClosingCash + data.ClosingCard + data.ClosingQris + data.ClosingTransfer
-	grandTotal := data.OpeningCash + totalSales + totalCashIn - totalCashOut
+	grandTotal := data.OpeningCash + totalSales + data.TipTotal + totalCashIn - totalCashOut
 	buf.WriteString(FormatRow("Total Penjualan", FormatNumber(totalSales), f.charLimit))
 	buf.Write(ESC_NEWLINE)
+	if data.TipTotal > 0 {
+		buf.WriteString(FormatRow("Tip", FormatNumber(data.TipTotal), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
 	buf.WriteString(FormatRow("Uang Masuk", FormatNumber(totalCashIn), f.charLimit))
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(FormatRow("Uang Keluar", FormatNumber(totalCashOut), f.charLimit))
@@ -290,6 +418,7 @@ func (f *PrintFormatter) FormatHandoverReceipt(data HandoverReceiptData) []byte
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(BuildDivider("=", f.paperSize))
 	buf.Write(ESC_NEWLINE)
+	f.writeCashCount(buf, data.CashCount)
 	buf.Write(ESC_NEWLINE)
 
 	leftWidth := f.charLimit / 2
@@ -340,14 +469,7 @@ func (f *PrintFormatter) FormatCloseShiftReceipt(data CloseShiftReceiptData) []b
 
 	buf.WriteString(FormatRow("Modal Awal", FormatNumber(data.OpeningCash), f.charLimit))
 	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("Tunai", FormatNumber(data.ClosingCash), f.charLimit))
-	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("Kartu", FormatNumber(data.ClosingCard), f.charLimit))
-	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("QRIS", FormatNumber(data.ClosingQris), f.charLimit))
-	buf.Write(ESC_NEWLINE)
-	buf.WriteString(FormatRow("Transfer", FormatNumber(data.ClosingTransfer), f.charLimit))
-	buf.Write(ESC_NEWLINE)
+	totalSales := f.writeShiftPayments(buf, data.ShiftPayments, data.ClosingCash, data.ClosingCard, data.ClosingQris, data.ClosingTransfer)
 	buf.WriteString(FormatRow(fmt.Sprintf("VOID (%d)", data.VoidedCount), FormatNumber(data.VoidedTotal), f.charLimit))
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(FormatRow(fmt.Sprintf("Batal Transaksi (%d)", data.CancelledCount), FormatNumber(data.CancelledTotal), f.charLimit))
@@ -392,10 +514,13 @@ func (f *PrintFormatter) FormatCloseShiftReceipt(data CloseShiftReceiptData) []b
 		buf.Write(ESC_NEWLINE)
 	}
 
-	totalSales := data.ClosingCash + data.ClosingCard + data.ClosingQris + data.ClosingTransfer
-	grandTotal := data.OpeningCash + totalSales + totalCashIn - totalCashOut
+	grandTotal := data.OpeningCash + totalSales + data.TipTotal + totalCashIn - totalCashOut
 	buf.WriteString(FormatRow("Total Penjualan", FormatNumber(totalSales), f.charLimit))
 	buf.Write(ESC_NEWLINE)
+	if data.TipTotal > 0 {
+		buf.WriteString(FormatRow("Tip", FormatNumber(data.TipTotal), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
 	buf.WriteString(FormatRow("Uang Masuk", FormatNumber(totalCashIn), f.charLimit))
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(FormatRow("Uang Keluar", FormatNumber(totalCashOut), f.charLimit))
@@ -408,6 +533,7 @@ func (f *PrintFormatter) FormatCloseShiftReceipt(data CloseShiftReceiptData) []b
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(BuildDivider("=", f.paperSize))
 	buf.Write(ESC_NEWLINE)
+	f.writeCashCount(buf, data.CashCount)
 
 	f.writeFooter(buf)
 
@@ -417,6 +543,134 @@ func (f *PrintFormatter) FormatCloseShiftReceipt(data CloseShiftReceiptData) []b
 	return buf.Bytes()
 }
 
+// FormatSalesReport mencetak X report / Z report: penjualan, diskon, biaya, pajak,
+// pembayaran per metode, refund/void/batal dan selisih kas tiap shift.
+func (f *PrintFormatter) FormatSalesReport(data SalesReportData) []byte {
+	buf := bytes.NewBuffer(nil)
+
+	buf.Write(ESC_INIT)
+	buf.Write(ESC_CHARSET_LATIN)
+
+	f.writeHeader(buf)
+
+	title := "X REPORT"
+	if data.ReportType == "Z" {
+		title = "Z REPORT"
+	}
+	buf.Write(ESC_ALIGN_CENTER)
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(title)
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	if data.IsReprint {
+		buf.WriteString("(CETAK ULANG)")
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.Write(ESC_ALIGN_LEFT)
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	if data.ReportType == "Z" {
+		buf.WriteString(FormatRow("No. Z", fmt.Sprintf("%04d", data.ZNumber), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(FormatRow("Tanggal Bisnis", data.BusinessDate, f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Dari", data.PeriodStart.Format("02/01/2006 15:04"), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Sampai", data.PeriodEnd.Format("02/01/2006 15:04"), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Dicetak", data.DateTime.Format("02/01/2006 15:04"), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	if data.PrintedBy != "" {
+		buf.WriteString(FormatRow("Oleh", data.PrintedBy, f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	buf.WriteString("PENJUALAN")
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Jumlah Order", strconv.Itoa(data.OrdersCount), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Jumlah Tamu", strconv.Itoa(data.Pax), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Penjualan Kotor", FormatNumber(data.GrossSales), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	for _, line := range data.DiscountLines {
+		buf.WriteString(FormatRow(line.Name, FormatNumber(line.Amount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	for _, line := range data.ChargeLines {
+		buf.WriteString(FormatRow(line.Name, FormatNumber(line.Amount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	for _, tax := range data.TaxLines {
+		label := fmt.Sprintf("%s %s%%", tax.Name, strconv.FormatFloat(tax.Rate, 'f', -1, 64))
+		if tax.IsInclusive {
+			label = "Termasuk " + label
+		}
+		buf.WriteString(FormatRow(label, FormatNumber(tax.Amount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(FormatRow("Penjualan Bersih", FormatNumber(data.NetSales), f.charLimit))
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	buf.WriteString("PEMBAYARAN")
+	buf.Write(ESC_NEWLINE)
+	for _, item := range data.Payments {
+		buf.WriteString(FormatRow(item.Name, FormatNumber(item.Amount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(FormatRow("Total Pembayaran", FormatNumber(data.PaymentsTotal), f.charLimit))
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	if data.Tips > 0 {
+		buf.WriteString(FormatRow("Tip", FormatNumber(data.Tips), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	buf.WriteString(FormatRow(fmt.Sprintf("Refund (%d)", data.RefundsCount), FormatNumber(data.Refunds), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow(fmt.Sprintf("VOID (%d)", data.VoidsCount), FormatNumber(data.Voids), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow(fmt.Sprintf("Batal Transaksi (%d)", data.CancelledCount), FormatNumber(data.Cancelled), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	if len(data.Shifts) > 0 {
+		buf.WriteString("SELISIH KAS SHIFT")
+		buf.Write(ESC_NEWLINE)
+		for _, shift := range data.Shifts {
+			value := "-"
+			if shift.Counted {
+				value = FormatNumber(shift.Variance)
+			}
+			buf.WriteString(FormatRow(shift.CashierName, value, f.charLimit))
+			buf.Write(ESC_NEWLINE)
+		}
+		buf.Write(ESC_BOLD_ON)
+		buf.WriteString(FormatRow("Total Selisih", FormatNumber(data.CashVariance), f.charLimit))
+		buf.Write(ESC_BOLD_OFF)
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(BuildDivider("=", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_CUT_PARTIAL)
+
+	return buf.Bytes()
+}
+
 func (f *PrintFormatter) FormatCashInReceipt(data CashInReceiptData) []byte {
 	buf := bytes.NewBuffer(nil)
 
@@ -533,6 +787,82 @@ func (f *PrintFormatter) FormatCashOutReceipt(data CashOutReceiptData) []byte {
 	return buf.Bytes()
 }
 
+func (f *PrintFormatter) FormatRefundReceipt(data RefundReceiptData) []byte {
+	buf := bytes.NewBuffer(nil)
+
+	buf.Write(ESC_INIT)
+	buf.Write(ESC_CHARSET_LATIN)
+
+	f.writeHeader(buf)
+
+	buf.Write(ESC_ALIGN_CENTER)
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString("STRUK REFUND")
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_ALIGN_LEFT)
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	buf.WriteString(FormatRow("No. Refund", data.ReceiptNumber, f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("No. Order", data.OrderNumber, f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Tanggal", data.DateTime.Format("02/01/2006 15:04"), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	if data.TableNumber != "" {
+		buf.WriteString(FormatRow("Meja", data.TableNumber, f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	if data.CashierName != "" {
+		buf.WriteString(FormatRow("Kasir", data.CashierName, f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	if data.ApprovedBy != "" {
+		buf.WriteString(FormatRow("Disetujui", data.ApprovedBy, f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	if len(data.Items) > 0 {
+		f.writeItems(buf, data.Items)
+	}
+
+	buf.WriteString(FormatRow("Metode", strings.ToUpper(data.RefundMethod), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	if data.Reason != "" {
+		buf.WriteString(FormatRow("Alasan", data.Reason, f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(FormatRow("TOTAL REFUND", FormatNumber(data.Amount), f.charLimit))
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(BuildDivider("=", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+
+	leftWidth := f.charLimit / 2
+	rightWidth := f.charLimit - leftWidth
+	buf.WriteString(PadRight("TTD Kasir", leftWidth) + PadLeft("Pelanggan", rightWidth))
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(PadRight(RepeatChar("_", leftWidth-2), leftWidth) + PadLeft(RepeatChar("_", rightWidth-2), rightWidth))
+	buf.Write(ESC_NEWLINE)
+
+	f.writeFooter(buf)
+
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_CUT_PARTIAL)
+
+	return buf.Bytes()
+}
+
 // writeHeader writes outlet information header
 func (f *PrintFormatter) writeHeader(buf *bytes.Buffer) {
 	// Outlet name - bold, centered
@@ -577,6 +907,47 @@ func (f *PrintFormatter) writeBillHeader(buf *bytes.Buffer) {
 }
 
 // writeTransactionInfo writes transaction details
+// OrderTypeLabel adalah nama tipe order yang dicetak di struk dan tiket dapur
+func OrderTypeLabel(orderType string) string {
+	switch orderType {
+	case "takeaway":
+		return "TAKE AWAY"
+	case "delivery":
+		return "DELIVERY"
+	case "pickup":
+		return "PICKUP"
+	case "dine_in":
+		return "DINE IN"
+	}
+	return strings.ToUpper(orderType)
+}
+
+// tableLineLabel: order tanpa meja menyimpan label antrian di kolom meja
+func tableLineLabel(queueLabel string) string {
+	if queueLabel != "" {
+		return "Antrian"
+	}
+	return "Meja"
+}
+
+// writeQueueNumber mencetak tipe order dan nomor antrian ukuran besar agar mudah dipanggil
+func (f *PrintFormatter) writeQueueNumber(buf *bytes.Buffer, orderType, queueLabel string) {
+	if queueLabel == "" {
+		return
+	}
+	buf.Write(ESC_ALIGN_CENTER)
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(OrderTypeLabel(orderType))
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_SIZE_DOUBLE)
+	buf.WriteString(queueLabel)
+	buf.Write(ESC_SIZE_NORMAL)
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_ALIGN_LEFT)
+}
+
 func (f *PrintFormatter) writeTransactionInfo(buf *bytes.Buffer, data ReceiptData) {
 	labelWidth := 11
 	formatLine := func(label, value string) string {
@@ -598,7 +969,7 @@ func (f *PrintFormatter) writeTransactionInfo(buf *bytes.Buffer, data ReceiptDat
 	buf.WriteString(formatLine("Tanggal", dateStr))
 	buf.Write(ESC_NEWLINE)
 
-	buf.WriteString(formatLine("Meja", data.TableNumber))
+	buf.WriteString(formatLine(tableLineLabel(data.QueueLabel), data.TableNumber))
 	buf.Write(ESC_NEWLINE)
 
 	if data.CustomerName != "" {
@@ -642,7 +1013,7 @@ func (f *PrintFormatter) writeBillTransactionInfo(buf *bytes.Buffer, data Receip
 	buf.WriteString(formatLine("Tanggal", dateStr))
 	buf.Write(ESC_NEWLINE)
 
-	buf.WriteString(formatLine("Meja", data.TableNumber))
+	buf.WriteString(formatLine(tableLineLabel(data.QueueLabel), data.TableNumber))
 	buf.Write(ESC_NEWLINE)
 
 	if data.WaiterName != "" {
@@ -739,11 +1110,7 @@ func (f *PrintFormatter) writeSummary(buf *bytes.Buffer, data ReceiptData) {
 	}
 
 	// Tax (jika ada)
-	if data.Tax > 0 {
-		taxStr := FormatNumber(data.Tax)
-		buf.WriteString(FormatRow("Pajak", taxStr, f.charLimit))
-		buf.Write(ESC_NEWLINE)
-	}
+	f.writeTaxLines(buf, data, false)
 
 	// Divider
 	buf.WriteString(BuildDivider("-", f.paperSize))
@@ -755,6 +1122,7 @@ func (f *PrintFormatter) writeSummary(buf *bytes.Buffer, data ReceiptData) {
 	buf.WriteString(FormatRow("TOTAL", totalStr, f.charLimit))
 	buf.Write(ESC_BOLD_OFF)
 	buf.Write(ESC_NEWLINE)
+	f.writeTaxLines(buf, data, true)
 
 	// Divider
 	buf.WriteString(BuildDivider("=", f.paperSize))
@@ -766,6 +1134,12 @@ func (f *PrintFormatter) writeSummary(buf *bytes.Buffer, data ReceiptData) {
 	buf.WriteString(FormatRow("Bayar", paidStr, f.charLimit))
 	buf.Write(ESC_NEWLINE)
 
+	// Tip tidak termasuk TOTAL penjualan
+	if data.TipAmount > 0 {
+		buf.WriteString(FormatRow("Tip", FormatNumber(data.TipAmount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+
 	changeStr := FormatNumber(data.ChangeAmount)
 	buf.WriteString(FormatRow("Kembalian", changeStr, f.charLimit))
 	buf.Write(ESC_NEWLINE)
@@ -790,11 +1164,7 @@ func (f *PrintFormatter) writeBillSummary(buf *bytes.Buffer, data ReceiptData) {
 		buf.Write(ESC_NEWLINE)
 	}
 
-	if data.Tax > 0 {
-		taxStr := FormatNumber(data.Tax)
-		buf.WriteString(FormatRow("Pajak", taxStr, f.charLimit))
-		buf.Write(ESC_NEWLINE)
-	}
+	f.writeTaxLines(buf, data, false)
 
 	buf.WriteString(BuildDivider("-", f.paperSize))
 	buf.Write(ESC_NEWLINE)
@@ -804,12 +1174,36 @@ func (f *PrintFormatter) writeBillSummary(buf *bytes.Buffer, data ReceiptData) {
 	buf.WriteString(FormatRow("TOTAL", totalStr, f.charLimit))
 	buf.Write(ESC_BOLD_OFF)
 	buf.Write(ESC_NEWLINE)
+	f.writeTaxLines(buf, data, true)
 
 	buf.WriteString(BuildDivider("=", f.paperSize))
 	buf.Write(ESC_NEWLINE)
 	buf.Write(ESC_NEWLINE)
 }
 
+// writeTaxLines menulis baris pajak eksklusif (sebelum TOTAL) atau inklusif (setelah TOTAL)
+func (f *PrintFormatter) writeTaxLines(buf *bytes.Buffer, data ReceiptData, inclusive bool) {
+	if len(data.Taxes) == 0 {
+		if !inclusive && data.Tax > 0 {
+			buf.WriteString(FormatRow("Pajak", FormatNumber(data.Tax), f.charLimit))
+			buf.Write(ESC_NEWLINE)
+		}
+		return
+	}
+
+	for _, tax := range data.Taxes {
+		if tax.IsInclusive != inclusive || tax.Amount == 0 {
+			continue
+		}
+		label := fmt.Sprintf("%s %s%%", tax.Name, strconv.FormatFloat(tax.Rate, 'f', -1, 64))
+		if inclusive {
+			label = "Termasuk " + label
+		}
+		buf.WriteString(FormatRow(label, FormatNumber(tax.Amount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+}
+
 // writeFooter writes receipt footer
 func (f *PrintFormatter) writeFooter(buf *bytes.Buffer) {
 	hasFooter := f.outlet.Footer != ""
@@ -859,6 +1253,31 @@ func (f *PrintFormatter) writeFooter(buf *bytes.Buffer) {
 	buf.Write(ESC_ALIGN_LEFT)
 }
 
+// writeBillQRIS mencetak QRIS dinamis agar tamu bisa langsung scan dari bill
+func (f *PrintFormatter) writeBillQRIS(buf *bytes.Buffer, data ReceiptData) {
+	if data.QRISPayload == "" {
+		return
+	}
+	moduleSize := byte(6)
+	if f.charLimit <= CharsPerLine58mm {
+		moduleSize = 4
+	}
+
+	buf.Write(ESC_ALIGN_CENTER)
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString("Scan QRIS untuk membayar")
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(QRCode(data.QRISPayload, moduleSize))
+	buf.Write(ESC_NEWLINE)
+	if data.QRISExpiresAt != nil {
+		buf.WriteString("Berlaku s/d " + data.QRISExpiresAt.Local().Format("15:04"))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_ALIGN_LEFT)
+}
+
 func (f *PrintFormatter) writeBillFooter(buf *bytes.Buffer) {
 	hasFooter := f.outlet.Footer != ""
 	hasSocial := f.outlet.SocialMedia != ""
@@ -910,7 +1329,8 @@ func (f *PrintFormatter) writeSplitFooter(buf *bytes.Buffer) {
 }
 
 // FormatKitchenOrder formats order for kitchen printer (simple format)
-func (f *PrintFormatter) FormatKitchenOrder(headerTitle, orderNumber, tableName, waiterName string, items []ReceiptItem, timestamp time.Time) []byte {
+// FormatKitchenOrder mencetak tiket dapur/bar; queueLabel terisi untuk order tanpa meja
+func (f *PrintFormatter) FormatKitchenOrder(data KitchenTicketData) []byte {
 	buf := bytes.NewBuffer(nil)
 
 	buf.Write(ESC_INIT)
@@ -920,11 +1340,23 @@ func (f *PrintFormatter) FormatKitchenOrder(headerTitle, orderNumber, tableName,
 	buf.Write(ESC_ALIGN_CENTER)
 	buf.Write(ESC_SIZE_DOUBLE)
 	buf.Write(ESC_BOLD_ON)
-	buf.WriteString(headerTitle)
+	buf.WriteString(data.HeaderTitle)
 	buf.Write(ESC_BOLD_OFF)
 	buf.Write(ESC_SIZE_NORMAL)
 	buf.Write(ESC_NEWLINE)
 	buf.Write(ESC_NEWLINE)
+	f.writeQueueNumber(buf, data.OrderType, data.QueueLabel)
+
+	// Marker course: FIRE = mulai masak, HOLD = tahan dulu
+	if data.Marker != "" {
+		buf.Write(ESC_SIZE_DOUBLE)
+		buf.Write(ESC_BOLD_ON)
+		buf.WriteString("*** " + data.Marker + " ***")
+		buf.Write(ESC_BOLD_OFF)
+		buf.Write(ESC_SIZE_NORMAL)
+		buf.Write(ESC_NEWLINE)
+		buf.Write(ESC_NEWLINE)
+	}
 
 	buf.Write(ESC_ALIGN_LEFT)
 	buf.WriteString(BuildDivider("=", f.paperSize))
@@ -944,24 +1376,108 @@ func (f *PrintFormatter) FormatKitchenOrder(headerTitle, orderNumber, tableName,
 		return prefix + value
 	}
 
-	buf.WriteString(formatLine("Order", orderNumber))
+	buf.WriteString(formatLine("Order", data.OrderNumber))
 	buf.Write(ESC_NEWLINE)
-	buf.WriteString(formatLine("Meja", tableName))
+	buf.WriteString(formatLine(tableLineLabel(data.QueueLabel), data.TableName))
 	buf.Write(ESC_NEWLINE)
-	if waiterName != "" {
-		buf.WriteString(formatLine("Waiter", waiterName))
+	if data.WaiterName != "" {
+		buf.WriteString(formatLine("Waiter", data.WaiterName))
 		buf.Write(ESC_NEWLINE)
 	}
-	buf.WriteString(formatLine("Waktu", timestamp.Format("15:04")))
+	buf.WriteString(formatLine("Waktu", data.DateTime.Format("15:04")))
 	buf.Write(ESC_NEWLINE)
 
 	buf.WriteString(BuildDivider("-", f.paperSize))
 	buf.Write(ESC_NEWLINE)
+	f.writeCustomerKitchenNotes(buf, data)
 	buf.Write(ESC_NEWLINE)
 
 	// Items
+	f.writeKitchenItems(buf, data.Items)
+
+	if len(data.HeldCourses) > 0 {
+		labels := make([]string, 0, len(data.HeldCourses))
+		for _, course := range data.HeldCourses {
+			labels = append(labels, CourseLabel(course))
+		}
+		buf.Write(ESC_NEWLINE)
+		buf.Write(ESC_BOLD_ON)
+		buf.WriteString("DITAHAN: " + strings.Join(labels, ", "))
+		buf.Write(ESC_BOLD_OFF)
+		buf.Write(ESC_NEWLINE)
+	}
+
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(BuildDivider("=", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+
+	// Cut
+	buf.Write(ESC_CUT_PARTIAL)
+
+	return buf.Bytes()
+}
+
+// writeCustomerKitchenNotes prints the customer's allergy (bold) and dietary notes so the
+// kitchen sees them before the items; nothing is printed when the profile has no notes.
+func (f *PrintFormatter) writeCustomerKitchenNotes(buf *bytes.Buffer, data KitchenTicketData) {
+	if data.Allergies == "" && data.DietaryNotes == "" {
+		return
+	}
+	if data.CustomerName != "" {
+		buf.WriteString("Tamu: " + data.CustomerName)
+		buf.Write(ESC_NEWLINE)
+	}
+	writeNote := func(label, note string, bold bool) {
+		if note == "" {
+			return
+		}
+		if bold {
+			buf.Write(ESC_BOLD_ON)
+		}
+		for _, line := range wrapText(label+note, f.charLimit) {
+			buf.WriteString(line)
+			buf.Write(ESC_NEWLINE)
+		}
+		if bold {
+			buf.Write(ESC_BOLD_OFF)
+		}
+	}
+	writeNote("!! ALERGI: ", data.Allergies, true)
+	writeNote("Diet: ", data.DietaryNotes, false)
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+}
+
+// CourseLabel adalah label course di tiket dapur; item tanpa course ditandai LAIN
+func CourseLabel(course string) string {
+	if course == "" {
+		return "LAIN"
+	}
+	return strings.ToUpper(course)
+}
+
+// writeKitchenItems mencetak item dapur; item ber-course dikelompokkan di bawah judul course
+func (f *PrintFormatter) writeKitchenItems(buf *bytes.Buffer, items []ReceiptItem) {
 	buf.Write(ESC_SIZE_NORMAL)
+	hasCourse := false
 	for _, item := range items {
+		if item.Course != "" {
+			hasCourse = true
+			break
+		}
+	}
+	currentCourse := "-"
+	for _, item := range items {
+		if hasCourse && item.Course != currentCourse {
+			currentCourse = item.Course
+			buf.Write(ESC_BOLD_ON)
+			buf.WriteString("-- " + CourseLabel(currentCourse) + " --")
+			buf.Write(ESC_BOLD_OFF)
+			buf.Write(ESC_NEWLINE)
+		}
 		prefix := fmt.Sprintf("%d x ", item.Quantity)
 		availableWidth := f.charLimit - len(prefix)
 		if availableWidth < 1 {
@@ -985,6 +1501,76 @@ func (f *PrintFormatter) FormatKitchenOrder(headerTitle, orderNumber, tableName,
 			buf.Write(ESC_NEWLINE)
 		}
 	}
+}
+
+// tableChangeTitles adalah judul slip per jenis perpindahan
+var tableChangeTitles = map[string]string{
+	"transfer":   "PINDAH MEJA",
+	"move_items": "PINDAH ITEM",
+	"merge":      "GABUNG MEJA",
+	"unmerge":    "PISAH MEJA",
+}
+
+// FormatTableChange mencetak slip perpindahan meja/item untuk dapur: meja asal, meja tujuan
+// (dicetak besar) dan item yang belum disajikan
+func (f *PrintFormatter) FormatTableChange(data TableChangeData) []byte {
+	buf := bytes.NewBuffer(nil)
+
+	buf.Write(ESC_INIT)
+	buf.Write(ESC_CHARSET_LATIN)
+
+	title, ok := tableChangeTitles[data.ChangeType]
+	if !ok {
+		title = "PINDAH MEJA"
+	}
+	buf.Write(ESC_ALIGN_CENTER)
+	buf.Write(ESC_SIZE_DOUBLE)
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(title)
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(data.FromTableNumber + " > " + data.ToTableNumber)
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_SIZE_NORMAL)
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+
+	buf.Write(ESC_ALIGN_LEFT)
+	buf.WriteString(BuildDivider("=", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+
+	labelWidth := 7
+	formatLine := func(label, value string) string {
+		prefix := PadRight(label, labelWidth) + " : "
+		available := f.charLimit - len(prefix)
+		if available < 1 {
+			available = 1
+		}
+		if len(value) > available {
+			value = value[:available]
+		}
+		return prefix + value
+	}
+
+	if data.FromOrderID != "" && data.FromOrderID != data.ToOrderID {
+		buf.WriteString(formatLine("Dari", data.FromOrderID))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(formatLine("Order", data.ToOrderID))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(formatLine("Meja", data.ToTableNumber))
+	buf.Write(ESC_NEWLINE)
+	if data.MovedBy != "" {
+		buf.WriteString(formatLine("Oleh", data.MovedBy))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(formatLine("Waktu", data.DateTime.Format("15:04")))
+	buf.Write(ESC_NEWLINE)
+
+	buf.WriteString(BuildDivider("-", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+	buf.Write(ESC_NEWLINE)
+
+	f.writeKitchenItems(buf, data.Items)
 
 	buf.Write(ESC_NEWLINE)
 	buf.WriteString(BuildDivider("=", f.paperSize))
@@ -993,7 +1579,6 @@ func (f *PrintFormatter) FormatKitchenOrder(headerTitle, orderNumber, tableName,
 	buf.Write(ESC_NEWLINE)
 	buf.Write(ESC_NEWLINE)
 
-	// Cut
 	buf.Write(ESC_CUT_PARTIAL)
 
 	return buf.Bytes()
@@ -1068,3 +1653,62 @@ func (f *PrintFormatter) FormatTestPrint(printerName, ipPort string) []byte {
 
 	return buf.Bytes()
 }
+
+// writeShiftPayments mencetak penjualan shift per metode pembayaran dan
+// mengembalikan total penjualan. Job lama tanpa rincian memakai 4 metode bawaan.
+func (f *PrintFormatter) writeShiftPayments(buf *bytes.Buffer, payments []ShiftPaymentData, cash, card, qris, transfer int) int {
+	if len(payments) == 0 {
+		payments = []ShiftPaymentData{
+			{Name: "Tunai", Amount: cash},
+			{Name: "Kartu", Amount: card},
+			{Name: "QRIS", Amount: qris},
+			{Name: "Transfer", Amount: transfer},
+		}
+	}
+
+	totalSales := 0
+	for _, item := range payments {
+		buf.WriteString(FormatRow(item.Name, FormatNumber(item.Amount), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+		totalSales += item.Amount
+	}
+	return totalSales
+}
+
+// writeCashCount mencetak hasil hitung laci per pecahan beserta selisih kas.
+// Selisih positif berarti uang lebih, negatif berarti uang kurang.
+func (f *PrintFormatter) writeCashCount(buf *bytes.Buffer, count *CashCountData) {
+	if count == nil {
+		return
+	}
+
+	buf.WriteString("HITUNG KAS")
+	buf.Write(ESC_NEWLINE)
+	for _, item := range count.Denominations {
+		label := fmt.Sprintf("%s x %d", FormatNumber(item.Denomination), item.Qty)
+		buf.WriteString(FormatRow(label, FormatNumber(item.Denomination*item.Qty), f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(FormatRow("Kas Fisik", FormatNumber(count.CountedCash), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+	buf.WriteString(FormatRow("Kas Seharusnya", FormatNumber(count.ExpectedCash), f.charLimit))
+	buf.Write(ESC_NEWLINE)
+
+	varianceLabel := "Selisih"
+	switch {
+	case count.Variance > 0:
+		varianceLabel = "Selisih (Lebih)"
+	case count.Variance < 0:
+		varianceLabel = "Selisih (Kurang)"
+	}
+	buf.Write(ESC_BOLD_ON)
+	buf.WriteString(FormatRow(varianceLabel, FormatNumber(count.Variance), f.charLimit))
+	buf.Write(ESC_BOLD_OFF)
+	buf.Write(ESC_NEWLINE)
+	if count.ApprovedBy != "" {
+		buf.WriteString(FormatRow("Disetujui", count.ApprovedBy, f.charLimit))
+		buf.Write(ESC_NEWLINE)
+	}
+	buf.WriteString(BuildDivider("=", f.paperSize))
+	buf.Write(ESC_NEWLINE)
+}
@@ -49,5 +49,5 @@ ORDER BY table_number;
 
 -- name: GetOccupiedTables :many
 SELECT * FROM tables
-WHERE status = 'occupied'
+WHERE status IN ('seated', 'ordered', 'bill_requested', 'paid')
 ORDER BY table_number;
@@ -1,6 +1,6 @@
 -- name: CreateTransaction :one
-INSERT INTO transactions (id, order_id, total_amount, payment_method, status, transaction_date, created_by, created_at, updated_at)
-VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+INSERT INTO transactions (id, order_id, total_amount, payment_method, status, transaction_date, created_by, shift_id, created_at, updated_at)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 RETURNING *;
 
 -- name: GetTransaction :one
@@ -27,7 +27,7 @@ CREATE TABLE IF NOT EXISTS tables (
     id TEXT PRIMARY KEY CHECK (length(id) = 26),
     table_number TEXT NOT NULL UNIQUE,
     capacity INTEGER NOT NULL DEFAULT 4 CHECK (capacity > 0),
-    status TEXT NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'occupied', 'reserved')),
+    status TEXT NOT NULL DEFAULT 'available' CHECK (status IN ('available', 'reserved', 'seated', 'ordered', 'bill_requested', 'paid', 'dirty')),
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
@@ -144,7 +144,7 @@ CREATE TABLE IF NOT EXISTS payments (
     id TEXT PRIMARY KEY CHECK (length(id) = 26),
     order_id TEXT NOT NULL,
     amount REAL NOT NULL CHECK (amount > 0),
-    payment_method TEXT NOT NULL CHECK (payment_method IN ('cash', 'card', 'qris', 'transfer')),
+    payment_method TEXT NOT NULL,
     payment_note TEXT,
     created_by TEXT NOT NULL,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -200,7 +200,9 @@ CREATE TABLE IF NOT EXISTS transactions (
     cancel_reason TEXT,
     created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    FOREIGN KEY (created_by) REFERENCES users(id)
+    shift_id TEXT,
+    FOREIGN KEY (created_by) REFERENCES users(id),
+    FOREIGN KEY (shift_id) REFERENCES cashier_shifts(id)
 );
 
 CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(transaction_date);
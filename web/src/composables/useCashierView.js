@@ -134,12 +134,15 @@ export function useCashierView() {
     return todayRevenue.value / todayTransactions.value
   })
 
+  // Meja yang minta bill ditampilkan lebih dulu
   const pendingTables = computed(() => {
-    return allTables.value.filter((table) =>
-      table.active_order &&
-      table.active_order.payment_status !== 'paid' &&
-      !table.active_order.is_merged
-    )
+    return allTables.value
+      .filter((table) =>
+        table.active_order &&
+        table.active_order.payment_status !== 'paid' &&
+        !table.active_order.is_merged
+      )
+      .sort((a, b) => (b.status === 'bill_requested') - (a.status === 'bill_requested'))
   })
 
   const filteredPendingTables = computed(() => {
@@ -841,7 +844,8 @@ export function useCashierView() {
 
   const fetchOccupiedTables = async () => {
     try {
-      const response = await api.get('/tables', { params: { page_size: 1000 } })
+      // status=occupied = semua meja yang masih ditempati tamu (seated, ordered, bill_requested, paid)
+      const response = await api.get('/tables', { params: { status: 'occupied', page_size: 1000 } })
       allTables.value = response.data.data || []
     } catch (error) {
       console.error('Failed to fetch occupied tables:', error)